@@ -20,6 +20,7 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"fmt"
+	stdlog "log"
 	"net"
 	"net/http"
 	"net/http/pprof"
@@ -28,13 +29,13 @@ import (
 	"os/signal"
 	"path"
 	"path/filepath"
+	"runtime"
 	"strconv"
 	"syscall"
 	"time"
 
 	"github.com/canonical/microcluster/v2/rest/types"
 	"github.com/canonical/microcluster/v2/state"
-	backoff "github.com/cenkalti/backoff/v4"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
@@ -58,15 +59,23 @@ import (
 
 	"maas.io/core/src/maasagent/internal/apiclient"
 	"maas.io/core/src/maasagent/internal/cache"
+	"maas.io/core/src/maasagent/internal/capability"
 	"maas.io/core/src/maasagent/internal/cluster"
 	"maas.io/core/src/maasagent/internal/dhcp"
+	"maas.io/core/src/maasagent/internal/execx"
 	"maas.io/core/src/maasagent/internal/httpproxy"
+	"maas.io/core/src/maasagent/internal/listener"
+	"maas.io/core/src/maasagent/internal/pathutil"
 	"maas.io/core/src/maasagent/internal/power"
 	"maas.io/core/src/maasagent/internal/resolver"
 	"maas.io/core/src/maasagent/internal/servicecontroller"
 	wflog "maas.io/core/src/maasagent/internal/workflow/log"
 	"maas.io/core/src/maasagent/internal/workflow/worker"
 	"maas.io/core/src/maasagent/pkg/workflow/codec"
+	"maas.io/core/src/maasgocommon/crash"
+	maaserrors "maas.io/core/src/maasgocommon/errors"
+	"maas.io/core/src/maasgocommon/redact"
+	"maas.io/core/src/maasgocommon/retry"
 )
 
 const (
@@ -95,6 +104,12 @@ type config struct {
 		ConnPoolSize int           `yaml:"connection_pool_size"`
 		DialTimeout  time.Duration `yaml:"dial_timeout"`
 		UDPPktSize   uint16        `yaml:"udp_packet_size"`
+		QueryLog     struct {
+			Enabled          bool    `yaml:"enabled"`
+			SampleRate       float64 `yaml:"sample_rate"`
+			AnonymizeClients bool    `yaml:"anonymize_clients"`
+			QNames           string  `yaml:"qnames"`
+		} `yaml:"query_log"`
 	} `yaml:"dns_resolver"`
 	Metrics struct {
 		Enabled bool `yaml:"enabled"`
@@ -102,15 +117,37 @@ type config struct {
 	Profiling struct {
 		Enabled bool `yaml:"enabled"`
 	} `yaml:"profiling"`
+	Secrets secretsConfig `yaml:"secrets"`
+}
+
+// crashes records the reports of fatal failures. Logs are teed to it after
+// secrets are masked.
+var crashes = crash.New(pathutil.DataPath("crash/maas-agent"))
+
+// recordCrash writes a report of a fatal failure.
+func recordCrash(redactor *redact.Redactor, err error) {
+	dir, recordErr := crashes.Record(redactor.String(err.Error()))
+	if dir != "" {
+		log.Info().Str("path", dir).Msg("Crash report written")
+	}
+
+	if recordErr != nil {
+		log.Warn().Err(recordErr).Msg("Cannot write crash report")
+	}
 }
 
 // setupLogger sets the global logger with the provided logLevel.
 // If logLevel provided is unknown, then INFO will be used.
-func setupLogger(logLevel string) {
+// Secrets are masked in the output of the global and the standard loggers,
+// by the returned Redactor.
+func setupLogger(logLevel string) *redact.Redactor {
+	redactor := redact.New()
+	stdlog.SetOutput(redactor.Writer(crashes.Writer(os.Stderr)))
+
 	// Use custom ConsoleWriter without TimestampFieldName, because stdout
 	// is captured with systemd-cat
 	// TODO: write directly to the journal
-	consoleWriter := zerolog.ConsoleWriter{Out: os.Stdout, NoColor: true}
+	consoleWriter := zerolog.ConsoleWriter{Out: redactor.Writer(crashes.Writer(os.Stdout)), NoColor: true}
 	consoleWriter.PartsOrder = []string{
 		zerolog.LevelFieldName,
 		zerolog.CallerFieldName,
@@ -126,13 +163,54 @@ func setupLogger(logLevel string) {
 	zerolog.SetGlobalLevel(ll)
 
 	log.Info().Msg(fmt.Sprintf("Logger is configured with log level %q", ll.String()))
+
+	return redactor
+}
+
+// logStartup logs the effective configuration, with secrets masked, and the
+// environment in a single record, to ease diagnosing misconfigurations.
+func logStartup(redactor *redact.Redactor, cfg *config) {
+	fields, err := redactor.Map(cfg)
+	if err != nil {
+		log.Warn().Err(err).Msg("Cannot log the effective configuration")
+	}
+
+	install := "deb"
+	if _, ok := os.LookupEnv("SNAP"); ok {
+		install = "snap"
+	}
+
+	// Logged whatever the configured level, like a banner.
+	log.Log().Str(zerolog.LevelFieldName, zerolog.LevelInfoValue).
+		Str("config_file", configPath()).
+		Interface("config", fields).
+		Str("install", install).
+		Str("snap_revision", os.Getenv("SNAP_REVISION")).
+		Str("go_version", runtime.Version()).
+		Msg("MAAS agent starting")
 }
 
-// getClusterCert returns certificate and CA that are used by the Agent to setup
-// mTLS. This certificate is used by Temporal Client for mTLS (when client
-// communicates with Temporal Server) and can be used by any other service where
-// secure communication to the Region Controller or other Agent is required.
-func getClusterCert() (tls.Certificate, *x509.CertPool, error) {
+// logCapabilities logs the capabilities of the host in a single record, with
+// the reason of every unavailable one.
+func logCapabilities(report capability.Report) {
+	available := zerolog.Arr()
+	unavailable := zerolog.Dict()
+
+	for _, c := range report {
+		if c.Available {
+			available.Str(string(c.Name))
+		} else {
+			unavailable.Str(string(c.Name), c.Reason)
+		}
+	}
+
+	log.Info().Array("available", available).Dict("unavailable", unavailable).
+		Msg("Host capabilities detected")
+}
+
+// getClusterCertFiles returns the cluster certificate and CA of the
+// certificates directory.
+func getClusterCertFiles() (tls.Certificate, *x509.CertPool, error) {
 	certsDir := getCertificatesDir()
 
 	cert, err := tls.LoadX509KeyPair(
@@ -171,9 +249,6 @@ func getTemporalClient(systemID string, secret []byte, cert tls.Certificate,
 		return nil, fmt.Errorf("failed setting up encryption codec: %w", err)
 	}
 
-	retry := backoff.NewExponentialBackOff()
-	retry.MaxElapsedTime = 60 * time.Second
-
 	tracingInterceptor, err := temporalotel.NewTracingInterceptor(temporalotel.TracerOptions{
 		Tracer: tracer,
 	})
@@ -181,9 +256,9 @@ func getTemporalClient(systemID string, secret []byte, cert tls.Certificate,
 		return nil, fmt.Errorf("failed setting up tracing interceptor: %w", err)
 	}
 
-	return backoff.RetryWithData(
-		func() (client.Client, error) {
-			return client.Dial(client.Options{
+	return retry.DoWithData(context.Background(),
+		func(ctx context.Context) (client.Client, error) {
+			return client.DialContext(ctx, client.Options{
 				// TODO: fallback retry if Controllers[0] is unavailable
 				HostPort:     net.JoinHostPort(endpoints[0], strconv.Itoa(defaultTemporalPort)),
 				Identity:     fmt.Sprintf("%s@agent:%d", systemID, os.Getpid()),
@@ -207,7 +282,13 @@ func getTemporalClient(systemID string, secret []byte, cert tls.Certificate,
 				},
 				MetricsHandler: metrics,
 			})
-		}, retry,
+		},
+		retry.WithMaxElapsedTime(60*time.Second),
+		// Rejected credentials won't be accepted on the next attempt either.
+		retry.WithRetryIf(maaserrors.Retryable),
+		retry.WithNotify(func(a retry.Attempt) {
+			log.Warn().Err(a.Err).Msgf("Failed to connect to Temporal, retrying in %s", a.Delay)
+		}),
 	)
 }
 
@@ -216,12 +297,7 @@ func getTemporalClient(systemID string, secret []byte, cert tls.Certificate,
 // should be changed when MAAS Agent will be a standalone service, not managed
 // by the Rack Controller.
 func getConfig() (*config, error) {
-	fname := os.Getenv("MAAS_AGENT_CONFIG")
-	if fname == "" {
-		fname = "/etc/maas/agent.yaml"
-	}
-
-	data, err := os.ReadFile(filepath.Clean(fname))
+	data, err := os.ReadFile(filepath.Clean(configPath()))
 	if err != nil {
 		return nil, fmt.Errorf("configuration error: %w", err)
 	}
@@ -236,6 +312,15 @@ func getConfig() (*config, error) {
 	return cfg, nil
 }
 
+// configPath returns the path of the MAAS Agent configuration file.
+func configPath() string {
+	if fname := os.Getenv("MAAS_AGENT_CONFIG"); fname != "" {
+		return fname
+	}
+
+	return "/etc/maas/agent.yaml"
+}
+
 func getOrCreateDir(path string) (string, error) {
 	_, err := os.Stat(path)
 	if os.IsNotExist(err) {
@@ -401,7 +486,14 @@ func Run() int {
 		return 1
 	}
 
-	setupLogger(cfg.LogLevel)
+	redactor := setupLogger(cfg.LogLevel)
+	logStartup(redactor, cfg)
+
+	if err := crashes.CaptureRuntimeCrashes(); err != nil {
+		log.Warn().Err(err).Msg("Runtime crashes won't be recorded")
+	}
+
+	defer crashes.Recover()
 
 	var meterProvider metric.MeterProvider
 
@@ -431,13 +523,17 @@ func Run() int {
 		tracerProvider = tracenoop.NewTracerProvider()
 	}
 
-	cert, ca, err := getClusterCert()
+	secretsProvider := newSecretsProvider(cfg.Secrets)
+
+	cert, ca, err := getClusterCert(context.Background(), secretsProvider)
 	if err != nil {
 		log.Error().Err(err).Msg("Cannot fetch cluster certificate")
 		return 1
 	}
 
-	temporalClient, err := getTemporalClient(cfg.SystemID, []byte(cfg.Secret),
+	rpcSecret := getRPCSecret(context.Background(), secretsProvider, cfg.Secret)
+
+	temporalClient, err := getTemporalClient(cfg.SystemID, []byte(rpcSecret),
 		cert, ca, cfg.Controllers,
 		temporalotel.NewMetricsHandler(
 			temporalotel.MetricsHandlerOptions{
@@ -500,21 +596,48 @@ func Run() int {
 		return 1
 	}
 
-	resolverHandler := resolver.NewRecursiveHandler(
-		resolverCache,
+	resolverOptions := []resolver.RecursiveHandlerOption{
 		resolver.WithConnPoolSize(cfg.DNSResolver.ConnPoolSize),
 		resolver.WithDialTimeout(cfg.DNSResolver.DialTimeout),
 		resolver.WithUDPSize(cfg.DNSResolver.UDPPktSize),
 		resolver.WithHandlerMetrics(meterProvider.Meter("resolver")),
-	)
+	}
+
+	if queryLogCfg := cfg.DNSResolver.QueryLog; queryLogCfg.Enabled {
+		queryLog, err := resolver.NewQueryLog(
+			resolver.WithSampleRate(queryLogCfg.SampleRate),
+			resolver.WithClientAnonymization(queryLogCfg.AnonymizeClients),
+			resolver.WithQNameAnonymization(resolver.QNameAnonymization(queryLogCfg.QNames)),
+		)
+		if err != nil {
+			log.Error().Err(err).Msg("Resolver query log initialisation error")
+			return 1
+		}
+
+		resolverOptions = append(resolverOptions, resolver.WithQueryLog(queryLog))
+	}
+
+	resolverHandler := resolver.NewRecursiveHandler(resolverCache, resolverOptions...)
 
-	powerService := power.NewPowerService(cfg.SystemID, &workerPool)
+	capabilities := capability.Detect(context.Background(), capability.DefaultProbes())
+	logCapabilities(capabilities)
+
+	powerService := power.NewPowerService(cfg.SystemID, &workerPool,
+		power.WithSecrets(secretsProvider),
+	)
 	httpProxyService := httpproxy.NewHTTPProxyService(runDir, httpProxyCache)
-	resolverService := resolver.NewResolverService(resolverHandler)
+	// Rebinds services to their addresses as netplan configurations change.
+	listeners := listener.NewManager()
+
+	resolverService := resolver.NewResolverService(resolverHandler,
+		resolver.WithListenerManager(listeners),
+	)
 
 	var (
 		clusterService *cluster.ClusterService
 		dhcpService    *dhcp.DHCPService
+		// dhcpRequires lists the capabilities the DHCP service needs.
+		dhcpRequires []capability.Name
 	)
 
 	if os.Getenv("MAAS_INTERNAL_DHCP") != "1" {
@@ -529,6 +652,7 @@ func Run() int {
 		dhcpService = dhcp.NewDHCPService(cfg.SystemID, controllerV4, controllerV6, false, dhcp.WithAPIClient(apiClient))
 	} else {
 		dhcpService = dhcp.NewDHCPService(cfg.SystemID, nil, nil, true)
+		dhcpRequires = []capability.Name{capability.RawSockets}
 
 		// using anonymous functions for hooks to easily allow the addition of logic for additional clustered
 		// service
@@ -569,19 +693,14 @@ func Run() int {
 		worker.WithConfigurator(powerService),
 		worker.WithConfigurator(httpProxyService),
 		worker.WithConfigurator(resolverService),
-		worker.WithConfigurator(dhcpService),
-	}
-
-	if dhcpService != nil {
-		workerPoolOptions = append(workerPoolOptions, worker.WithConfigurator(dhcpService))
+		worker.WithConfigurator(capability.Gate(dhcpService, capabilities, dhcpRequires...)),
 	}
 
 	workerPool = *worker.NewWorkerPool(cfg.SystemID, temporalClient, workerPoolOptions...)
 
-	workerPoolBackoff := backoff.NewExponentialBackOff()
-	workerPoolBackoff.MaxElapsedTime = 60 * time.Second
-
-	err = backoff.Retry(workerPool.Start, workerPoolBackoff)
+	err = retry.Do(context.Background(), func(context.Context) error {
+		return workerPool.Start()
+	}, retry.WithMaxElapsedTime(60*time.Second))
 	if err != nil {
 		log.Error().Err(err).Msg("Temporal worker pool failure")
 		return 1
@@ -590,6 +709,12 @@ func Run() int {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	go func() {
+		if err := listeners.Run(ctx); err != nil {
+			log.Error().Err(err).Msg("Listener manager failure")
+		}
+	}()
+
 	// NOTE: Signal Region Controller that Agent has started.
 	// This should trigger configuration workflows execution.
 	// Region controller will start configuration workflows based on certain
@@ -598,7 +723,8 @@ func Run() int {
 	// Once Region can detect that Agent was reconnected or restarted via
 	// Temporal server API, we should no longer need this.
 	type configureAgentParam struct {
-		SystemID string `json:"system_id"`
+		SystemID     string            `json:"system_id"`
+		Capabilities capability.Report `json:"capabilities"`
 	}
 
 	workflowOptions := client.StartWorkflowOptions{
@@ -611,7 +737,7 @@ func Run() int {
 	}
 
 	workflowRun, err := temporalClient.ExecuteWorkflow(ctx, workflowOptions,
-		"configure-agent", configureAgentParam{SystemID: cfg.SystemID},
+		"configure-agent", configureAgentParam{SystemID: cfg.SystemID, Capabilities: capabilities},
 	)
 	if err != nil {
 		log.Err(err).Msg("Failed to execute configure-agent workflow")
@@ -645,6 +771,8 @@ func Run() int {
 	select {
 	case err := <-fatal:
 		log.Err(err).Msg("Service failure")
+		recordCrash(redactor, err)
+
 		return 1
 	case <-sigs:
 		return 0
@@ -652,5 +780,8 @@ func Run() int {
 }
 
 func main() {
+	// Sandboxed helpers are run through the agent binary.
+	execx.Init()
+
 	os.Exit(Run())
 }
@@ -0,0 +1,148 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+	"maas.io/core/src/maasgocommon/secrets"
+)
+
+// Paths of the secrets regiond stores, in Vault or in the database.
+const (
+	rpcSharedSecretPath   = "global/rpc-shared"
+	clusterCertSecretPath = "global/cluster-certificate"
+)
+
+// secretsConfig lists the providers secrets are read from, in this order,
+// before the configuration file and the certificates directory.
+type secretsConfig struct {
+	// EnvPrefix is the prefix of the environment variables holding secrets
+	// (e.g. MAAS_SECRET_ for MAAS_SECRET_GLOBAL_RPC_SHARED).
+	EnvPrefix string `yaml:"env_prefix"`
+	// Dir is a directory of files holding secrets, e.g. the credentials
+	// directory of the systemd service.
+	Dir   string `yaml:"dir"`
+	Vault struct {
+		URL          string `yaml:"url"`
+		SecretsMount string `yaml:"secrets_mount"`
+		SecretsPath  string `yaml:"secrets_path"`
+		AppRoleID    string `yaml:"approle_id"`
+		SecretID     string `yaml:"secret_id"`
+	} `yaml:"vault"`
+}
+
+// newSecretsProvider returns the provider of the configured secret
+// sources, or nil if there are none.
+func newSecretsProvider(cfg secretsConfig) secrets.Provider {
+	var chain secrets.Chain
+
+	if cfg.EnvPrefix != "" {
+		chain = append(chain, secrets.NewEnv(cfg.EnvPrefix))
+	}
+
+	if cfg.Dir != "" {
+		chain = append(chain, secrets.NewFile(cfg.Dir))
+	}
+
+	if vault := cfg.Vault; vault.URL != "" {
+		chain = append(chain, secrets.NewVault(vault.URL, vault.AppRoleID, vault.SecretID,
+			secrets.WithMount(vault.SecretsMount),
+			secrets.WithBasePath(vault.SecretsPath),
+		))
+	}
+
+	if len(chain) == 0 {
+		return nil
+	}
+
+	return secrets.NewCache(chain)
+}
+
+// getRPCSecret returns the secret shared with the region controllers, which
+// encrypts workflow payloads. As for regiond, the one of the configuration
+// file is used if the provider doesn't have it or can't be reached.
+func getRPCSecret(ctx context.Context, provider secrets.Provider, fallback string) string {
+	if provider == nil {
+		return fallback
+	}
+
+	secret, err := provider.Get(ctx, rpcSharedSecretPath)
+	if errors.Is(err, secrets.ErrNotFound) {
+		return fallback
+	} else if err != nil {
+		log.Warn().Err(err).Msg("Cannot fetch the RPC shared secret, using the configuration file")
+		return fallback
+	}
+
+	value, err := secret.Value()
+	if err != nil {
+		log.Warn().Err(err).Msg("Invalid RPC shared secret, using the configuration file")
+		return fallback
+	}
+
+	return value
+}
+
+// getClusterCert returns the certificate and CA that are used by the Agent
+// to setup mTLS, from the provider or else from the certificates directory.
+func getClusterCert(ctx context.Context, provider secrets.Provider) (tls.Certificate, *x509.CertPool, error) {
+	if provider == nil {
+		return getClusterCertFiles()
+	}
+
+	secret, err := provider.Get(ctx, clusterCertSecretPath)
+	if errors.Is(err, secrets.ErrNotFound) {
+		return getClusterCertFiles()
+	} else if err != nil {
+		log.Warn().Err(err).Msg("Cannot fetch the cluster certificate, using the certificates directory")
+		return getClusterCertFiles()
+	}
+
+	return parseClusterCert(secret)
+}
+
+// parseClusterCert returns the certificate and CA of the cluster certificate
+// secret, with the PEM fields regiond stores it with.
+func parseClusterCert(secret *secrets.Secret) (tls.Certificate, *x509.CertPool, error) {
+	fields := make(map[string]string, 3)
+
+	for _, name := range []string{"cert", "key", "cacerts"} {
+		value, err := secret.Field(name)
+		if err != nil {
+			return tls.Certificate{}, nil, fmt.Errorf("incomplete cluster certificate: %w", err)
+		}
+
+		fields[name] = value
+	}
+
+	cert, err := tls.X509KeyPair([]byte(fields["cert"]), []byte(fields["key"]))
+	if err != nil {
+		return cert, nil, fmt.Errorf("invalid cluster certificate: %w", err)
+	}
+
+	ca := x509.NewCertPool()
+	if !ca.AppendCertsFromPEM([]byte(fields["cacerts"])) {
+		return cert, nil, errors.New("invalid cluster certificate: no CA certificate")
+	}
+
+	return cert, ca, nil
+}
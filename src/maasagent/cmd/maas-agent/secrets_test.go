@@ -0,0 +1,171 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"maps"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maas.io/core/src/maasagent/internal/testing/cert"
+	"maas.io/core/src/maasgocommon/secrets"
+)
+
+// writeSecret writes the secret at path in dir, as the File provider reads
+// it.
+func writeSecret(t *testing.T, dir, path string, fields map[string]string) {
+	t.Helper()
+
+	name := filepath.Join(dir, filepath.FromSlash(path))
+	require.NoError(t, os.MkdirAll(filepath.Dir(name), 0o700))
+
+	data, err := json.Marshal(fields)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(name+".json", data, 0o600))
+}
+
+// clusterCertFields returns the fields of a cluster certificate secret, as
+// regiond stores it.
+func clusterCertFields(t *testing.T) (tls.Certificate, map[string]string) {
+	t.Helper()
+
+	ca := cert.GenerateTestCA(t)
+	c := cert.GenerateTestCertificate(t, cert.WithCA(ca))
+
+	key, err := x509.MarshalPKCS8PrivateKey(c.PrivateKey)
+	require.NoError(t, err)
+
+	return c, map[string]string{
+		"cert":    string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.Certificate[0]})),
+		"key":     string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: key})),
+		"cacerts": string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.Certificate[0]})),
+	}
+}
+
+func TestNewSecretsProvider(t *testing.T) {
+	assert.Nil(t, newSecretsProvider(secretsConfig{}))
+
+	dir := t.TempDir()
+	writeSecret(t, dir, rpcSharedSecretPath, map[string]string{secrets.ValueField: "from-dir"})
+	writeSecret(t, dir, "bmc/1/power-parameters", map[string]string{"power_pass": "s3cret"})
+
+	t.Setenv("MAAS_SECRET_GLOBAL_RPC_SHARED", "from-env")
+
+	provider := newSecretsProvider(secretsConfig{EnvPrefix: "MAAS_SECRET_", Dir: dir})
+	require.NotNil(t, provider)
+
+	secret, err := provider.Get(context.Background(), rpcSharedSecretPath)
+	require.NoError(t, err)
+
+	value, err := secret.Value()
+	require.NoError(t, err)
+	assert.Equal(t, "from-env", value, "the environment comes first")
+
+	secret, err = provider.Get(context.Background(), "bmc/1/power-parameters")
+	require.NoError(t, err)
+
+	pass, err := secret.Field("power_pass")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", pass)
+}
+
+func TestGetRPCSecret(t *testing.T) {
+	dir := t.TempDir()
+	writeSecret(t, dir, rpcSharedSecretPath, map[string]string{secrets.ValueField: "shared"})
+
+	testcases := map[string]struct {
+		provider secrets.Provider
+		out      string
+	}{
+		"no provider": {
+			out: "configured",
+		},
+		"from the provider": {
+			provider: secrets.NewFile(dir),
+			out:      "shared",
+		},
+		"not found": {
+			provider: secrets.NewFile(t.TempDir()),
+			out:      "configured",
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.out, getRPCSecret(context.Background(), tc.provider, "configured"))
+		})
+	}
+}
+
+func TestGetClusterCert(t *testing.T) {
+	dir := t.TempDir()
+	expected, fields := clusterCertFields(t)
+	writeSecret(t, dir, clusterCertSecretPath, fields)
+
+	c, ca, err := getClusterCert(context.Background(), secrets.NewFile(dir))
+	require.NoError(t, err)
+	assert.Equal(t, expected.Certificate, c.Certificate)
+	assert.NotNil(t, ca)
+}
+
+func TestParseClusterCert(t *testing.T) {
+	_, fields := clusterCertFields(t)
+
+	testcases := map[string]struct {
+		in  func(map[string]string)
+		err string
+	}{
+		"valid": {
+			in: func(map[string]string) {},
+		},
+		"no key": {
+			in:  func(f map[string]string) { delete(f, "key") },
+			err: "incomplete cluster certificate",
+		},
+		"no CA": {
+			in:  func(f map[string]string) { f["cacerts"] = "" },
+			err: "no CA certificate",
+		},
+		"mismatched key": {
+			in:  func(f map[string]string) { f["cert"] = f["cacerts"] },
+			err: "invalid cluster certificate",
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			f := maps.Clone(fields)
+			tc.in(f)
+
+			_, ca, err := parseClusterCert(&secrets.Secret{Fields: f})
+			if tc.err != "" {
+				assert.ErrorContains(t, err, tc.err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.NotNil(t, ca)
+		})
+	}
+}
@@ -39,6 +39,7 @@ import (
 	"maas.io/core/src/maasagent/internal/apiclient"
 	"maas.io/core/src/maasagent/internal/cluster"
 	"maas.io/core/src/maasagent/internal/dhcp"
+	"maas.io/core/src/maasgocommon/redact"
 )
 
 var (
@@ -74,7 +75,7 @@ func setupLogger(lvl, dst string, color bool) (func() error, error) {
 		cleanup = func() error { return nil }
 	}
 
-	writer := zerolog.ConsoleWriter{Out: out, NoColor: !color}
+	writer := zerolog.ConsoleWriter{Out: redact.New().Writer(out), NoColor: !color}
 	writer.PartsOrder = []string{
 		zerolog.LevelFieldName,
 		zerolog.CallerFieldName,
@@ -26,13 +26,16 @@ import (
 	"github.com/rs/zerolog/log"
 	"golang.org/x/sync/errgroup"
 
+	"maas.io/core/src/maasagent/internal/localstore"
 	"maas.io/core/src/maasagent/internal/netmon"
+	"maas.io/core/src/maasagent/internal/pathutil"
+	"maas.io/core/src/maasgocommon/redact"
 )
 
 func Run() int {
 	zerolog.SetGlobalLevel(zerolog.InfoLevel)
 
-	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
+	log.Logger = log.Output(zerolog.ConsoleWriter{Out: redact.New().Writer(os.Stderr)})
 
 	if envLogLevel, ok := os.LookupEnv("LOG_LEVEL"); ok {
 		if logLevel, err := zerolog.ParseLevel(envLogLevel); err != nil {
@@ -60,7 +63,22 @@ func Run() int {
 	g, ctx := errgroup.WithContext(ctx)
 	g.SetLimit(2)
 
-	svc := netmon.NewService(iface)
+	var options []netmon.ServiceOption
+
+	store, err := localstore.Open(ctx, pathutil.DataPath(netmon.StoreFile))
+	if err != nil {
+		log.Warn().Err(err).Msg("Discovered bindings will not be kept across restarts")
+	} else {
+		defer func() {
+			if err := store.Close(); err != nil {
+				log.Warn().Err(err).Msg("Failed to close discovery store")
+			}
+		}()
+
+		options = append(options, netmon.WithStore(store))
+	}
+
+	svc := netmon.NewService(iface, options...)
 
 	g.Go(func() error {
 		return svc.Start(ctx, resultC)
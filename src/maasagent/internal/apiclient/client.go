@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"strings"
 )
 
 type APIClient struct {
@@ -39,13 +40,21 @@ func NewAPIClient(baseURL *url.URL, httpClient *http.Client) *APIClient {
 }
 
 // Request is a generic method for making HTTP requests to the internal MAAS API.
+// path may include a query string.
 func (c *APIClient) Request(ctx context.Context, method, path string,
 	body []byte) (*http.Response, error) {
+	// JoinPath would escape the query string.
+	path, query, hasQuery := strings.Cut(path, "?")
+
 	url, err := url.JoinPath(c.baseURL.String(), path)
 	if err != nil {
 		return nil, fmt.Errorf("wrong URL path: %s", path)
 	}
 
+	if hasQuery {
+		url += "?" + query
+	}
+
 	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewBuffer(body))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
@@ -0,0 +1,171 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package capability probes which capabilities the agent can provide on the
+// host it runs on, such as opening raw sockets or accessing a local BMC.
+//
+// Probes run once at startup. Services that depend on a missing capability
+// are disabled with Gate instead of failing the first time they are used, and
+// the Report is sent to the region so that it knows what the agent provides.
+package capability
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// Name identifies a capability.
+type Name string
+
+const (
+	// RawSockets is the ability to open AF_PACKET raw sockets, which the
+	// internal DHCP server needs to reply to clients without an address.
+	RawSockets Name = "raw-sockets"
+	// TFTP is the ability to serve TFTP on the standard port.
+	TFTP Name = "tftp"
+	// KVM is access to hardware virtualisation through /dev/kvm.
+	KVM Name = "kvm"
+	// IPMI is access to the BMC of the host through an IPMI device.
+	IPMI Name = "ipmi"
+)
+
+var (
+	kvmDevices  = []string{"/dev/kvm"}
+	ipmiDevices = []string{"/dev/ipmi0", "/dev/ipmi/0", "/dev/ipmidev/0"}
+)
+
+// Probe checks whether a capability is available. Check returns an error
+// explaining why the capability is unavailable.
+type Probe struct {
+	Name  Name
+	Check func(ctx context.Context) error
+}
+
+// DefaultProbes returns the probes of all the capabilities known to the agent.
+func DefaultProbes() []Probe {
+	return []Probe{
+		{Name: RawSockets, Check: probeRawSockets},
+		{Name: TFTP, Check: probeUDPPort(69)},
+		{Name: KVM, Check: probeDevice(kvmDevices...)},
+		{Name: IPMI, Check: probeDevice(ipmiDevices...)},
+	}
+}
+
+// Status is the result of probing a capability.
+type Status struct {
+	Name      Name   `json:"name"`
+	Available bool   `json:"available"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// Report is the result of probing capabilities, in probe order.
+type Report []Status
+
+// Detect runs the probes and returns their results.
+func Detect(ctx context.Context, probes []Probe) Report {
+	report := make(Report, 0, len(probes))
+
+	for _, p := range probes {
+		status := Status{Name: p.Name, Available: true}
+
+		if err := p.Check(ctx); err != nil {
+			status.Available = false
+			status.Reason = err.Error()
+		}
+
+		report = append(report, status)
+	}
+
+	return report
+}
+
+// Available returns true if the capability was probed and is available.
+func (r Report) Available(name Name) bool {
+	for _, s := range r {
+		if s.Name == name {
+			return s.Available
+		}
+	}
+
+	return false
+}
+
+// Missing returns the capabilities that are not available, out of names.
+func (r Report) Missing(names ...Name) []Name {
+	var missing []Name
+
+	for _, name := range names {
+		if !r.Available(name) {
+			missing = append(missing, name)
+		}
+	}
+
+	return missing
+}
+
+func probeRawSockets(context.Context) error {
+	fd, err := unix.Socket(unix.AF_PACKET, unix.SOCK_RAW|unix.SOCK_CLOEXEC, 0)
+	if err != nil {
+		return fmt.Errorf("cannot open a raw socket: %w", err)
+	}
+
+	return unix.Close(fd)
+}
+
+func probeUDPPort(port int) func(context.Context) error {
+	return func(ctx context.Context) error {
+		var lc net.ListenConfig
+
+		conn, err := lc.ListenPacket(ctx, "udp", fmt.Sprintf(":%d", port))
+		if err != nil {
+			return fmt.Errorf("cannot bind UDP port %d: %w", port, err)
+		}
+
+		return conn.Close()
+	}
+}
+
+// probeDevice checks that one of the device nodes can be opened for reading
+// and writing.
+func probeDevice(paths ...string) func(context.Context) error {
+	return func(context.Context) error {
+		var errs []error
+
+		for _, path := range paths {
+			f, err := os.OpenFile(path, os.O_RDWR, 0)
+			if err == nil {
+				return f.Close()
+			}
+
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+
+			errs = append(errs, err)
+		}
+
+		if len(errs) > 0 {
+			return errors.Join(errs...)
+		}
+
+		return fmt.Errorf("no device found (%s)", strings.Join(paths, ", "))
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package capability
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/testsuite"
+)
+
+func probe(name Name, err error) Probe {
+	return Probe{Name: name, Check: func(context.Context) error { return err }}
+}
+
+func TestDetect(t *testing.T) {
+	report := Detect(context.Background(), []Probe{
+		probe(RawSockets, nil),
+		probe(KVM, errors.New("no device found")),
+	})
+
+	assert.Equal(t, Report{
+		{Name: RawSockets, Available: true},
+		{Name: KVM, Available: false, Reason: "no device found"},
+	}, report)
+
+	assert.True(t, report.Available(RawSockets))
+	assert.False(t, report.Available(KVM))
+	assert.False(t, report.Available(IPMI), "capabilities not probed are unavailable")
+	assert.Equal(t, []Name{KVM, IPMI}, report.Missing(RawSockets, KVM, IPMI))
+	assert.Empty(t, report.Missing(RawSockets))
+}
+
+func TestProbeDevice(t *testing.T) {
+	dir := t.TempDir()
+	missing := filepath.Join(dir, "missing")
+	device := filepath.Join(dir, "device")
+
+	require.NoError(t, os.WriteFile(device, nil, 0o600))
+
+	testcases := map[string]struct {
+		paths []string
+		err   string
+	}{
+		"first": {
+			paths: []string{device, missing},
+		},
+		"fallback": {
+			paths: []string{missing, device},
+		},
+		"none": {
+			paths: []string{missing},
+			err:   "no device found (" + missing + ")",
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			err := probeDevice(tc.paths...)(context.Background())
+			if tc.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.err)
+			}
+		})
+	}
+}
+
+func TestProbeDeviceDenied(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root can open any file")
+	}
+
+	device := filepath.Join(t.TempDir(), "device")
+	require.NoError(t, os.WriteFile(device, nil, 0o400))
+
+	err := probeDevice(device)(context.Background())
+	assert.ErrorIs(t, err, os.ErrPermission)
+}
+
+func TestProbeUDPPortInUse(t *testing.T) {
+	conn, err := net.ListenPacket("udp", ":0")
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = conn.Close() }) //nolint:errcheck // test
+
+	port := conn.LocalAddr().(*net.UDPAddr).Port
+
+	err = probeUDPPort(port)(context.Background())
+	assert.ErrorContains(t, err, fmt.Sprintf("cannot bind UDP port %d", port))
+}
+
+type fakeConfigurator struct{}
+
+func (fakeConfigurator) ConfigurationWorkflows() map[string]any {
+	return map[string]any{"configure-fake-service": func() error { return nil }}
+}
+
+func (fakeConfigurator) ConfigurationActivities() map[string]any {
+	return map[string]any{"apply-fake-config": func() error { return nil }}
+}
+
+func TestGate(t *testing.T) {
+	report := Report{
+		{Name: RawSockets, Available: true},
+		{Name: KVM, Available: false, Reason: "no device found"},
+	}
+
+	t.Run("available", func(t *testing.T) {
+		c := fakeConfigurator{}
+		assert.Equal(t, c, Gate(c, report, RawSockets))
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		c := Gate(fakeConfigurator{}, report, RawSockets, KVM)
+
+		assert.Empty(t, c.ConfigurationActivities())
+
+		workflows := c.ConfigurationWorkflows()
+		require.Contains(t, workflows, "configure-fake-service")
+
+		testSuite := &testsuite.WorkflowTestSuite{}
+		env := testSuite.NewTestWorkflowEnvironment()
+		env.RegisterWorkflow(workflows["configure-fake-service"])
+
+		// Parameters of the original workflow are ignored.
+		env.ExecuteWorkflow(workflows["configure-fake-service"], map[string]any{"enabled": true})
+
+		require.True(t, env.IsWorkflowCompleted())
+		assert.NoError(t, env.GetWorkflowError())
+	})
+}
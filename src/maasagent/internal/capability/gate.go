@@ -0,0 +1,70 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package capability
+
+import (
+	tworkflow "go.temporal.io/sdk/workflow"
+	"maas.io/core/src/maasagent/internal/workflow/log/tag"
+	"maas.io/core/src/maasagent/internal/workflow/worker"
+)
+
+// disabledConfigurator replaces a service that cannot run on this host.
+type disabledConfigurator struct {
+	workflows []string
+	missing   []Name
+}
+
+// Gate returns c if all of the required capabilities are available.
+// Otherwise it returns a Configurator that registers none of the activities
+// of c, and replaces its configuration workflows by workflows that only log
+// that the service is disabled. The region configures every service of the
+// agent, so the workflows must still exist and succeed.
+func Gate(c worker.Configurator, report Report, required ...Name) worker.Configurator {
+	missing := report.Missing(required...)
+	if len(missing) == 0 {
+		return c
+	}
+
+	workflows := make([]string, 0, len(c.ConfigurationWorkflows()))
+	for name := range c.ConfigurationWorkflows() {
+		workflows = append(workflows, name)
+	}
+
+	return &disabledConfigurator{workflows: workflows, missing: missing}
+}
+
+func (c *disabledConfigurator) ConfigurationWorkflows() map[string]any {
+	workflows := make(map[string]any, len(c.workflows))
+	for _, name := range c.workflows {
+		workflows[name] = c.configure
+	}
+
+	return workflows
+}
+
+func (c *disabledConfigurator) ConfigurationActivities() map[string]any {
+	return map[string]any{}
+}
+
+// configure ignores the parameter of the workflow it replaces.
+func (c *disabledConfigurator) configure(ctx tworkflow.Context, _ any) error {
+	tworkflow.GetLogger(ctx).Warn("Service is disabled on this host",
+		tag.Builder().
+			KV("workflow", tworkflow.GetInfo(ctx).WorkflowType.Name).
+			KV("missing_capabilities", c.missing))
+
+	return nil
+}
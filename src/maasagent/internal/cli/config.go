@@ -0,0 +1,119 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"maas.io/core/src/maasagent/internal/daemon"
+	"maas.io/core/src/maasagent/internal/pathutil"
+)
+
+func configCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "config",
+		Short:        "Inspect or validate the MAAS agent configuration.",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(configSchemaCmd())
+	cmd.AddCommand(configValidateCmd())
+
+	return cmd
+}
+
+func configSchemaCmd() *cobra.Command {
+	var format string
+
+	cmd := &cobra.Command{
+		Use:          "schema",
+		Short:        "Print the configuration schema.",
+		Example:      "maas-agent config schema --format markdown",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			schema := daemon.ConfigSchema()
+
+			switch format {
+			case "json":
+				data, err := schema.JSON()
+				if err != nil {
+					return err
+				}
+
+				fmt.Fprintln(cmd.OutOrStdout(), string(data))
+
+				return nil
+			case "markdown":
+				return schema.Markdown(cmd.OutOrStdout())
+			default:
+				return fmt.Errorf("unsupported format %q (json, markdown)", format)
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", "json",
+		"Output format: json (JSON Schema) or markdown (reference documentation)")
+
+	return cmd
+}
+
+func configValidateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "validate [file]",
+		Short:        "Validate a configuration file without applying it.",
+		Example:      "maas-agent config validate /tmp/agent.yaml",
+		Args:         cobra.MaximumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file := pathutil.ConfigPath("agent.yaml")
+			if len(args) == 1 {
+				file = args[0]
+			}
+
+			data, err := os.ReadFile(filepath.Clean(file))
+			if err != nil {
+				return fmt.Errorf("reading config: %w", err)
+			}
+
+			if err := daemon.ValidateConfig(data); err != nil {
+				var joined interface{ Unwrap() []error }
+				if errors.As(err, &joined) {
+					for _, e := range joined.Unwrap() {
+						fmt.Fprintln(cmd.ErrOrStderr(), e)
+					}
+				} else {
+					fmt.Fprintln(cmd.ErrOrStderr(), err)
+				}
+
+				return fmt.Errorf("%s is not valid", file)
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "%s is valid\n", file)
+
+			return nil
+		},
+	}
+
+	return cmd
+}
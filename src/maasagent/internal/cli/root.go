@@ -40,8 +40,10 @@ func RootCmd(ctx context.Context) *cobra.Command {
 	cmd.PersistentFlags().BoolP("help", "h", false,
 		"Help information about a command")
 
+	cmd.AddCommand(configCmd())
 	cmd.AddCommand(initCmd(ctx))
 	cmd.AddCommand(startCmd(ctx))
+	cmd.AddCommand(stateCmd(ctx))
 
 	cmd.InitDefaultHelpCmd()
 
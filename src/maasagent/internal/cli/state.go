@@ -0,0 +1,179 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"maas.io/core/src/maasagent/internal/daemon"
+	"maas.io/core/src/maasagent/internal/netmon"
+	"maas.io/core/src/maasagent/internal/pathutil"
+)
+
+func stateCmd(ctx context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "state",
+		Short: "Export or import the MAAS agent local state.",
+		Long: "Export or import the MAAS agent local state (discovery history, " +
+			"credentials, image cache manifest), e.g. when replacing a rack controller.",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(stateExportCmd(ctx))
+	cmd.AddCommand(stateImportCmd(ctx))
+
+	return cmd
+}
+
+func stateExportCmd(ctx context.Context) *cobra.Command {
+	var passphraseFile string
+
+	cmd := &cobra.Command{
+		Use:          "export <archive>",
+		Short:        "Export the MAAS agent local state.",
+		Example:      "maas-agent state export --passphrase-file - state.jsonl",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			passphrase, err := readPassphrase(passphraseFile)
+			if err != nil {
+				return err
+			}
+
+			summary, err := daemon.New().ExportState(ctx, daemon.StateOptions{
+				ConfigFile:    pathutil.ConfigPath("agent.yaml"),
+				StoreFile:     pathutil.DataPath("state.db"),
+				DiscoveryFile: pathutil.DataPath(netmon.StoreFile),
+				Archive:       filepath.Clean(args[0]),
+				Passphrase:    passphrase,
+			})
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+
+			fmt.Fprintf(out, "Exported %d entries and a manifest of %d image cache files.\n",
+				summary.Entries, summary.CacheFiles)
+
+			if passphrase == nil {
+				fmt.Fprintln(out, "Credentials were not exported, use --passphrase-file to include them.")
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&passphraseFile, "passphrase-file", "",
+		"File containing the passphrase protecting credentials (use '-' to read from stdin)")
+
+	return cmd
+}
+
+func stateImportCmd(ctx context.Context) *cobra.Command {
+	var (
+		passphraseFile string
+		replace        bool
+	)
+
+	cmd := &cobra.Command{
+		Use:          "import <archive>",
+		Short:        "Import the MAAS agent local state.",
+		Example:      "maas-agent state import --passphrase-file - state.jsonl",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			passphrase, err := readPassphrase(passphraseFile)
+			if err != nil {
+				return err
+			}
+
+			summary, err := daemon.New().ImportState(ctx, daemon.StateOptions{
+				ConfigFile:    pathutil.ConfigPath("agent.yaml"),
+				StoreFile:     pathutil.DataPath("state.db"),
+				DiscoveryFile: pathutil.DataPath(netmon.StoreFile),
+				Archive:       filepath.Clean(args[0]),
+				Passphrase:    passphrase,
+				Replace:       replace,
+			})
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+
+			fmt.Fprintf(out, "Imported %d entries.\n", summary.Entries)
+
+			if summary.Identity {
+				fmt.Fprintln(out, "Restored the agent identity, restart the agent to use it.")
+			}
+
+			if len(summary.MissingCacheFiles) > 0 {
+				fmt.Fprintf(out, "%d of %d image cache files are missing and will be synced again:\n",
+					len(summary.MissingCacheFiles), summary.CacheFiles)
+
+				for _, path := range summary.MissingCacheFiles {
+					fmt.Fprintf(out, "  %s\n", path)
+				}
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&passphraseFile, "passphrase-file", "",
+		"File containing the passphrase protecting credentials (use '-' to read from stdin)")
+	cmd.Flags().BoolVar(&replace, "replace", false,
+		"Replace existing entries instead of merging them")
+
+	return cmd
+}
+
+func readPassphrase(path string) ([]byte, error) {
+	var (
+		data []byte
+		err  error
+	)
+
+	switch path {
+	case "":
+		return nil, nil
+	case "-":
+		data, err = io.ReadAll(os.Stdin)
+	default:
+		data, err = os.ReadFile(filepath.Clean(path))
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read passphrase: %w", err)
+	}
+
+	passphrase := bytes.TrimSpace(data)
+	if len(passphrase) == 0 {
+		return nil, fmt.Errorf("passphrase must not be empty")
+	}
+
+	return passphrase, nil
+}
@@ -33,8 +33,20 @@ type Client struct {
 	httpClient *http.Client
 }
 
-func New(baseURL *url.URL, tlsConfig *tls.Config) *Client {
-	return &Client{
+// Option allows to set additional Client options
+type Option func(*Client)
+
+// WithTransport replaces the transport used to reach the controller.
+// tlsConfig passed to New is not used in this case. This is mostly useful
+// in tests, e.g. to replay recorded interactions.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(c *Client) {
+		c.httpClient.Transport = transport
+	}
+}
+
+func New(baseURL *url.URL, tlsConfig *tls.Config, options ...Option) *Client {
+	c := &Client{
 		apiURL: baseURL.JoinPath(apiURL),
 		httpClient: &http.Client{
 			Transport: &http.Transport{
@@ -42,6 +54,12 @@ func New(baseURL *url.URL, tlsConfig *tls.Config) *Client {
 			},
 		},
 	}
+
+	for _, opt := range options {
+		opt(c)
+	}
+
+	return c
 }
 
 func (c *Client) request(ctx context.Context, method, path string,
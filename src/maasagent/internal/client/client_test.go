@@ -0,0 +1,56 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package client
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maas.io/core/src/maasagent/internal/testing/vcr"
+)
+
+func newReplayClient(t *testing.T, cassette string) *Client {
+	t.Helper()
+
+	recorder, err := vcr.New(cassette, vcr.WithRedactedFields("encryption_key"))
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, recorder.Stop())
+	})
+
+	u, err := url.Parse("https://10.0.0.1:5443")
+	require.NoError(t, err)
+
+	return New(u, nil, WithTransport(recorder))
+}
+
+func TestClientEnrollAndGetConfig(t *testing.T) {
+	c := newReplayClient(t, "testdata/enroll.json")
+
+	enrollResp, err := c.Enroll(t.Context(), EnrollRequest{Secret: "secret"})
+	require.NoError(t, err)
+	assert.Equal(t, "-----BEGIN CERTIFICATE-----", enrollResp.Certificate)
+
+	configResp, err := c.GetConfig(t.Context(), "7b9c7c1e-5c3f-4c26-9a5e-0c1f3c1f9d55")
+	require.NoError(t, err)
+	assert.Equal(t, vcr.Redacted, configResp.Temporal.EncryptionKey)
+
+	_, err = c.GetConfig(t.Context(), "unknown")
+	assert.ErrorContains(t, err, "status 404")
+}
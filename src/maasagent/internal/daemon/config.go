@@ -17,11 +17,16 @@ package daemon
 
 import (
 	"bytes"
+	"crypto/hkdf"
+	"crypto/sha256"
 	"embed"
+	"errors"
 	"fmt"
 	"math"
 	"net/url"
+	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"text/template"
 	"time"
@@ -30,6 +35,8 @@ import (
 	"github.com/spf13/afero"
 	"gopkg.in/yaml.v3"
 	"maas.io/core/src/maasagent/internal/atomicfile"
+	"maas.io/core/src/maasgocommon/configschema"
+	"maas.io/core/src/maasgocommon/redact"
 )
 
 const configTemplateName = "config.yaml.tmpl"
@@ -94,21 +101,22 @@ type Config struct {
 	// Unmarshalled via rawConfig
 	ControllerURL *url.URL            `yaml:"-"`
 	TLS           TLSConfig           `yaml:"tls"`
+	State         StateConfig         `yaml:"state,omitempty"`
 	Observability ObservabilityConfig `yaml:"observability"`
 	Services      Services            `yaml:"services"`
 }
 
 // TLSConfig holds certificate and key file locations.
 type TLSConfig struct {
-	KeyFile  string `yaml:"key_file"`
-	CertFile string `yaml:"cert_file"`
-	CAFile   string `yaml:"ca_file"`
+	KeyFile  string `yaml:"key_file" doc:"Path to the agent private key." schema:"required"`
+	CertFile string `yaml:"cert_file" doc:"Path to the agent certificate." schema:"required"`
+	CAFile   string `yaml:"ca_file" doc:"Path to the CA certificate of the controller." schema:"required"`
 }
 
 // Services aggregates configuration for all services provided by the agent.
 type Services struct {
-	HTTPProxy HTTPProxyConfig `yaml:"http_proxy"`
-	DNS       DNSConfig       `yaml:"dns"`
+	HTTPProxy HTTPProxyConfig `yaml:"http_proxy" doc:"HTTP proxy used for caching OS packages and images."`
+	DNS       DNSConfig       `yaml:"dns" doc:"DNS resolver for local name resolution and forwarding."`
 }
 
 // ObservabilityConfig holds configuration for logging, tracing, metrics,
@@ -131,7 +139,95 @@ const (
 // LoggingConfig holds the configuration for agent logging.
 type LoggingConfig struct {
 	// Level defines the minimum logging severity level (debug, info, warn, error).
-	Level LogLevel `yaml:"level"`
+	Level LogLevel `yaml:"level" doc:"Minimum logging severity level." schema:"enum=debug|info|warn|error,default=error"`
+	// Redact masks secrets in logs, in addition to the built-in ones
+	// (passwords, tokens, credentials in URLs...).
+	Redact RedactConfig `yaml:"redact"`
+}
+
+// RedactConfig lists additional secrets masked in logs.
+type RedactConfig struct {
+	Fields   []string `yaml:"fields,omitempty" doc:"Names, or parts of names, of fields whose values are masked in logs."`
+	Patterns []string `yaml:"patterns,omitempty" doc:"Regular expressions whose matches are masked in logs."`
+}
+
+// redactor returns the Redactor masking the built-in and configured secrets.
+func (c RedactConfig) redactor() (*redact.Redactor, error) {
+	patterns := make([]*regexp.Regexp, 0, len(c.Patterns))
+
+	for _, p := range c.Patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redact pattern %q: %w", p, err)
+		}
+
+		patterns = append(patterns, re)
+	}
+
+	return redact.New(redact.WithFields(c.Fields...), redact.WithPatterns(patterns...)), nil
+}
+
+// StateConfig holds settings of the state the agent keeps on disk.
+type StateConfig struct {
+	Encryption StateEncryptionConfig `yaml:"encryption,omitempty"`
+}
+
+// Sources of the key encrypting local state at rest.
+const (
+	StateKeyNone       = "none"
+	StateKeySecret     = "secret"
+	StateKeyCredential = "credential"
+
+	defaultStateKeyCredential = "maas-agent-state"
+	stateKeyLength            = 32
+	minStateSecretLength      = 16
+)
+
+// StateEncryptionConfig specifies how local state (the state store and the
+// DHCP notification journal) is encrypted at rest.
+type StateEncryptionConfig struct {
+	KeySource  string `yaml:"key_source,omitempty" doc:"Secret the encryption key of local state is derived from: none, the secret shared with the region, or a systemd credential, which can be sealed with the TPM." schema:"enum=none|secret|credential,default=none"`
+	Credential string `yaml:"credential,omitempty" doc:"Name of the systemd credential holding the secret, with the credential key source." schema:"default=maas-agent-state"`
+}
+
+// key derives the key encrypting the local state used for purpose. It
+// returns nil if local state is not encrypted.
+func (c StateEncryptionConfig) key(fs afero.Fs, purpose string) ([]byte, error) {
+	var path string
+
+	switch c.KeySource {
+	case "", StateKeyNone:
+		return nil, nil
+	case StateKeySecret:
+		path = filepath.Join(rackdCommonDir(), "secret")
+	case StateKeyCredential:
+		dir := os.Getenv("CREDENTIALS_DIRECTORY")
+		if dir == "" {
+			return nil, errors.New("state encryption: CREDENTIALS_DIRECTORY is not set")
+		}
+
+		name := c.Credential
+		if name == "" {
+			name = defaultStateKeyCredential
+		}
+
+		path = filepath.Join(dir, name)
+	default:
+		return nil, fmt.Errorf("state encryption: unknown key source %q", c.KeySource)
+	}
+
+	secret, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return nil, fmt.Errorf("state encryption: reading secret: %w", err)
+	}
+
+	secret = bytes.TrimSpace(secret)
+	if len(secret) < minStateSecretLength {
+		return nil, fmt.Errorf("state encryption: secret in %s is shorter than %d bytes",
+			path, minStateSecretLength)
+	}
+
+	return hkdf.Key(sha256.New, secret, nil, "maas-agent "+purpose, stateKeyLength)
 }
 
 // HTTPProxyConfig contains configuration for the HTTP proxy service.
@@ -141,30 +237,39 @@ type HTTPProxyConfig struct {
 
 // HTTPProxyCache specifies cache settings for the HTTP proxy service.
 type HTTPProxyCache struct {
-	Dir  string          `yaml:"dir"`
-	Size ByteSize[int64] `yaml:"size"`
+	Dir  string          `yaml:"dir" doc:"Directory where cached content is stored."`
+	Size ByteSize[int64] `yaml:"size" doc:"Maximum disk space used by the proxy cache." schema:"default=20GB"`
 }
 
 // DNSConfig contains configuration for the agent's DNS resolver service.
 type DNSConfig struct {
 	Cache          DNSCache      `yaml:"cache"`
-	DialTimeout    time.Duration `yaml:"dial_timeout"`
-	ConnectionPool int           `yaml:"connection_pool"`
+	DialTimeout    time.Duration `yaml:"dial_timeout" doc:"Maximum time to wait for upstream DNS servers to respond." schema:"default=5s"`
+	ConnectionPool int           `yaml:"connection_pool" doc:"Number of persistent connections kept open to upstream DNS servers." schema:"min=1,default=5"`
+	QueryLog       DNSQueryLog   `yaml:"query_log"`
+}
+
+// DNSQueryLog specifies logging of the queries served by the DNS resolver.
+type DNSQueryLog struct {
+	Enabled          bool    `yaml:"enabled" doc:"Log the client, name, type, response code and latency of DNS queries." schema:"default=false"`
+	SampleRate       float64 `yaml:"sample_rate" doc:"Fraction of the queries logged." schema:"min=0,max=1,default=1"`
+	AnonymizeClients bool    `yaml:"anonymize_clients" doc:"Log the /24 (IPv4) or /48 (IPv6) network of clients instead of their address." schema:"default=false"`
+	QNames           string  `yaml:"qnames,omitempty" doc:"How query names are logged: as is, hashed, or truncated to their last two labels." schema:"enum=plain|hash|truncate,default=plain"`
 }
 
 // DNSCache specifies cache sizing for DNS results.
 type DNSCache struct {
-	Size ByteSize[int64] `yaml:"size"`
+	Size ByteSize[int64] `yaml:"size" doc:"Memory limit for the DNS cache." schema:"default=50MB"`
 }
 
 // MetricsConfig enables or disables metrics collection for the agent.
 type MetricsConfig struct {
-	Enabled bool `yaml:"enabled"`
+	Enabled bool `yaml:"enabled" doc:"Expose a Prometheus-compatible endpoint at /metrics." schema:"default=false"`
 }
 
 // ProfilingConfig enables or disables runtime profiling for the agent.
 type ProfilingConfig struct {
-	Enabled bool `yaml:"enabled"`
+	Enabled bool `yaml:"enabled" doc:"Expose Go pprof data at /debug/pprof." schema:"default=false"`
 }
 
 type Integeric interface {
@@ -184,6 +289,24 @@ func (x ByteSize[T]) String() string {
 	return strings.ReplaceAll(humanize.Bytes(uint64(x.Bytes)), " ", "")
 }
 
+// JSONSchema implements the configschema.Describer interface.
+func (ByteSize[T]) JSONSchema() *configschema.Schema {
+	return &configschema.Schema{
+		Type:    "string",
+		Pattern: `^[0-9]+(\.[0-9]+)? *([kKmMgGtTpPeE][iI]?)?[bB]?$`,
+	}
+}
+
+// MarshalYAML implements the yaml.Marshaler interface, the reverse of
+// UnmarshalYAML.
+func (x ByteSize[T]) MarshalYAML() (any, error) {
+	if x.Raw != "" {
+		return x.Raw, nil
+	}
+
+	return x.String(), nil
+}
+
 // UnmarshalYAML implements the yaml.Unmarshaler interface.
 // It parses a human-readable byte size string (e.g., "20GB", "512MB")
 // and sets the value of the receiver.
@@ -216,11 +339,42 @@ func (x *ByteSize[T]) UnmarshalYAML(value *yaml.Node) error {
 // For example Controller is a string instead of *url.URL.
 type rawConfig struct {
 	TLS           TLSConfig           `yaml:"tls"`
-	Controller    string              `yaml:"controller"`
+	State         StateConfig         `yaml:"state,omitempty"`
+	Controller    string              `yaml:"controller" doc:"The base URL of the MAAS controller." schema:"required"`
 	Observability ObservabilityConfig `yaml:"observability"`
 	Services      Services            `yaml:"services"`
 }
 
+// JSONSchema implements the configschema.Describer interface.
+// Config is described by rawConfig, which is what the file contains.
+func (Config) JSONSchema() *configschema.Schema {
+	return configschema.Generate(rawConfig{}, "MAAS agent configuration")
+}
+
+// ConfigSchema returns the JSON Schema of the agent configuration file.
+func ConfigSchema() *configschema.Schema {
+	return Config{}.JSONSchema()
+}
+
+// ValidateConfig checks the agent configuration file content against
+// the schema and then parses it, as loadConfig would.
+func ValidateConfig(data []byte) error {
+	if err := ConfigSchema().ValidateYAML(data); err != nil {
+		return err
+	}
+
+	cfg := &Config{}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return fmt.Errorf("parse config: %w", err)
+	}
+
+	if _, err := cfg.Observability.Logging.Redact.redactor(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // UnmarshalYAML implements the yaml.Unmarshaler interface for Config.
 // It parses YAML data into a Config, converting the URL string to a url.URL.
 func (c *Config) UnmarshalYAML(value *yaml.Node) error {
@@ -239,10 +393,28 @@ func (c *Config) UnmarshalYAML(value *yaml.Node) error {
 	c.Services = t.Services
 	c.Observability = t.Observability
 	c.TLS = t.TLS
+	c.State = t.State
 
 	return nil
 }
 
+// MarshalYAML implements the yaml.Marshaler interface for Config, the
+// reverse of UnmarshalYAML.
+func (c Config) MarshalYAML() (any, error) {
+	t := rawConfig{
+		TLS:           c.TLS,
+		Observability: c.Observability,
+		Services:      c.Services,
+		State:         c.State,
+	}
+
+	if c.ControllerURL != nil {
+		t.Controller = c.ControllerURL.String()
+	}
+
+	return t, nil
+}
+
 // DynamicConfig is something that users cannot set via configuration file.
 // It is fetched from the controller.
 type DynamicConfig struct {
@@ -16,13 +16,19 @@
 package daemon
 
 import (
+	"bytes"
 	_ "embed"
+	"errors"
 	"fmt"
+	stdlog "log"
 	"net/url"
+	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/rs/zerolog"
 	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"gopkg.in/yaml.v3"
 )
@@ -116,3 +122,164 @@ func TestConfig(t *testing.T) {
 	require.Equal(t, expected, cfg)
 	require.Equal(t, loaded, cfg)
 }
+
+func TestConfigMarshal(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	cfg, err := generateConfig(fs, "config.yaml", configOptions{
+		ControllerURL: "https://maas.internal:5242",
+		CacheDir:      "/cache",
+		CertDir:       "/certificates",
+	})
+	require.NoError(t, err)
+
+	data, err := yaml.Marshal(cfg)
+	require.NoError(t, err)
+
+	require.NoError(t, ValidateConfig(data))
+
+	loaded := &Config{}
+	require.NoError(t, yaml.Unmarshal(data, loaded))
+	require.Equal(t, cfg, loaded)
+}
+
+func TestValidateConfig(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	path := "config.yaml"
+
+	_, err := generateConfig(fs, path, configOptions{
+		ControllerURL: "https://maas.internal:5242",
+		CacheDir:      "/cache",
+		CertDir:       "/certificates",
+	})
+	require.NoError(t, err)
+
+	data, err := afero.ReadFile(fs, path)
+	require.NoError(t, err)
+
+	// Generated config must always be valid
+	require.NoError(t, ValidateConfig(data))
+
+	testcases := map[string]struct {
+		in  string
+		err string
+	}{
+		"missing controller": {
+			in:  "tls: {key_file: a, cert_file: b, ca_file: c}",
+			err: "controller: is required",
+		},
+		"invalid log level": {
+			in: "controller: https://maas.internal:5242\n" +
+				"tls: {key_file: a, cert_file: b, ca_file: c}\n" +
+				"observability: {logging: {level: trace}}",
+			err: "observability.logging.level: must be one of [debug info warn error]",
+		},
+		"invalid cache size": {
+			in: "controller: https://maas.internal:5242\n" +
+				"tls: {key_file: a, cert_file: b, ca_file: c}\n" +
+				"services: {http_proxy: {cache: {size: lots}}}",
+			err: "services.http_proxy.cache.size",
+		},
+		"invalid redact pattern": {
+			in: "controller: https://maas.internal:5242\n" +
+				"tls: {key_file: a, cert_file: b, ca_file: c}\n" +
+				"observability: {logging: {redact: {patterns: ['sk-[a-z']}}}",
+			err: `invalid redact pattern "sk-[a-z"`,
+		},
+		"invalid state key source": {
+			in: "controller: https://maas.internal:5242\n" +
+				"tls: {key_file: a, cert_file: b, ca_file: c}\n" +
+				"state: {encryption: {key_source: tpm}}",
+			err: "state.encryption.key_source: must be one of [none secret credential]",
+		},
+		"unknown property": {
+			in: "controller: https://maas.internal:5242\n" +
+				"tls: {key_file: a, cert_file: b, ca_file: c}\n" +
+				"service: {}",
+			err: "service: unknown property",
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			require.ErrorContains(t, ValidateConfig([]byte(tc.in)), tc.err)
+		})
+	}
+}
+
+func TestStateEncryptionKey(t *testing.T) {
+	const secret = "0123456789abcdef0123456789abcdef"
+
+	credentials := t.TempDir()
+	t.Setenv("CREDENTIALS_DIRECTORY", credentials)
+	t.Setenv("SNAP_COMMON", "")
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, filepath.Join(rackdCommonDir(), "secret"),
+		[]byte(secret+"\n"), 0o640))
+	require.NoError(t, afero.WriteFile(fs, filepath.Join(credentials, "maas-agent-state"),
+		[]byte(secret), 0o600))
+	require.NoError(t, afero.WriteFile(fs, filepath.Join(credentials, "short"),
+		[]byte("short"), 0o600))
+
+	key, err := StateEncryptionConfig{}.key(fs, storeKeyPurpose)
+	require.NoError(t, err)
+	require.Nil(t, key)
+
+	fromSecret, err := StateEncryptionConfig{KeySource: StateKeySecret}.key(fs, storeKeyPurpose)
+	require.NoError(t, err)
+	require.Len(t, fromSecret, 32)
+
+	// The trailing newline of the secret file is not part of the secret.
+	fromCredential, err := StateEncryptionConfig{KeySource: StateKeyCredential}.key(fs, storeKeyPurpose)
+	require.NoError(t, err)
+	require.Equal(t, fromSecret, fromCredential)
+
+	journalKey, err := StateEncryptionConfig{KeySource: StateKeySecret}.key(fs, dhcpJournalKeyPurpose)
+	require.NoError(t, err)
+	require.NotEqual(t, fromSecret, journalKey)
+
+	_, err = StateEncryptionConfig{KeySource: StateKeyCredential, Credential: "short"}.key(fs, storeKeyPurpose)
+	require.ErrorContains(t, err, "shorter than")
+
+	_, err = StateEncryptionConfig{KeySource: StateKeyCredential, Credential: "missing"}.key(fs, storeKeyPurpose)
+	require.ErrorContains(t, err, "reading secret")
+
+	t.Setenv("CREDENTIALS_DIRECTORY", "")
+
+	_, err = StateEncryptionConfig{KeySource: StateKeyCredential}.key(fs, storeKeyPurpose)
+	require.ErrorContains(t, err, "CREDENTIALS_DIRECTORY is not set")
+}
+
+// TestRedactConfigLoggers checks that secrets never reach the output of
+// zerolog loggers writing through the configured Redactor.
+func TestRedactConfigLoggers(t *testing.T) {
+	const secret = "s3cr3t-value"
+
+	redactor, err := RedactConfig{Fields: []string{"ipmi_k_g"}}.redactor()
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+
+	w := redactor.Writer(&buf)
+
+	console := zerolog.New(zerolog.ConsoleWriter{Out: w, NoColor: true})
+	console.Info().Str("power_pass", secret).Msg("power on")
+	console.Info().Str("database_pass", secret).Str("token", secret).Send()
+	console.Info().Str("ipmi_k_g", secret).Send()
+	console.Error().Err(fmt.Errorf("dial postgres://maas:%s@db/maasdb: %w", secret,
+		errors.New("refused"))).Msg("connect")
+	console.Info().Interface("params", map[string]string{"power_pass": secret}).Msg("query")
+	console.Info().Msgf("Authorization: Bearer %s", secret)
+
+	jsonLogger := zerolog.New(w)
+	jsonLogger.Info().Str("secret", secret).Msg("json output")
+
+	// Third-party libraries often log with the standard library logger.
+	stdlog.New(w, "", 0).Printf("connecting with password=%s", secret)
+
+	out := buf.String()
+	require.NotEmpty(t, out)
+	assert.NotContains(t, out, secret)
+	assert.Contains(t, out, "power on")
+}
@@ -21,13 +21,14 @@ import (
 	"crypto/tls"
 	"errors"
 	"fmt"
+	stdlog "log"
 	"net"
 	"net/http"
 	"net/http/pprof"
 	"net/url"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"runtime"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -46,8 +47,12 @@ import (
 	"maas.io/core/src/maasagent/internal/atomicfile"
 	"maas.io/core/src/maasagent/internal/certutil"
 	"maas.io/core/src/maasagent/internal/client"
+	"maas.io/core/src/maasagent/internal/events"
+	"maas.io/core/src/maasagent/internal/execx"
 	"maas.io/core/src/maasagent/internal/pathutil"
+	"maas.io/core/src/maasagent/internal/reconcile"
 	"maas.io/core/src/maasagent/internal/token"
+	"maas.io/core/src/maasgocommon/redact"
 )
 
 type enroller interface {
@@ -75,6 +80,11 @@ type Daemon struct {
 
 	mux    *http.ServeMux
 	server *http.Server
+
+	events *events.Bus
+	// reconciler converges the modules on the desired state pushed by
+	// the region.
+	reconciler *reconcile.Controller
 }
 
 func New() *Daemon {
@@ -90,8 +100,14 @@ func New() *Daemon {
 		tracerProvider: tracenoop.NewTracerProvider(),
 		meterProvider:  metricnoop.NewMeterProvider(),
 		mux:            http.NewServeMux(),
+		events:         events.NewBus(),
 	}
 
+	d.reconciler = reconcile.NewController(reconcile.WithEvents(d.events))
+
+	d.mux.Handle("/events", events.Handler(d.events))
+	d.mux.Handle("/modules", reconcile.Handler(d.reconciler))
+
 	return d
 }
 
@@ -195,7 +211,12 @@ func (d *Daemon) Start(ctx context.Context, args DaemonArgs) error {
 		return fmt.Errorf("loading config: %w", err)
 	}
 
-	d.setupLogger()
+	redactor, err := d.setupLogger()
+	if err != nil {
+		return fmt.Errorf("configure logging: %w", err)
+	}
+
+	d.logStartup(redactor, args)
 
 	if err := d.setupObservability(ctx); err != nil {
 		return fmt.Errorf("configure observability: %w", err)
@@ -245,7 +266,7 @@ func (d *Daemon) Start(ctx context.Context, args DaemonArgs) error {
 	//     it will start rackd, which would then start the agent
 	// TODO: Remove once Python based rackd is obsolete.
 	if !args.Supervised && dynCfg.SystemID == "" {
-		if err := startRackd(d.fs, rackdConfig{
+		if err := startRackd(ctx, d.fs, rackdConfig{
 			AgentUUID: id,
 			RPCSecret: dynCfg.RPCSecret,
 			MAASURL:   dynCfg.MAASURL,
@@ -256,6 +277,9 @@ func (d *Daemon) Start(ctx context.Context, args DaemonArgs) error {
 		return nil
 	}
 
+	// Served before services start, for event subscribers to see them.
+	g.Go(d.startHTTPServer)
+
 	// Initialization of services is extracted, so it will be easier to apply
 	// refactoring and changes to certain things which appeared to be not the
 	// best design decision.
@@ -263,8 +287,6 @@ func (d *Daemon) Start(ctx context.Context, args DaemonArgs) error {
 		return fmt.Errorf("starting services: %w", err)
 	}
 
-	g.Go(d.startHTTPServer)
-
 	// TODO: Implement graceful shutdown in case of an error
 	return g.Wait()
 }
@@ -313,10 +335,20 @@ func (d *Daemon) startHTTPServer() error {
 
 // setupLogger sets the global logger with the provided logLevel.
 // If logLevel provided is unknown, then INFO will be used.
-func (d *Daemon) setupLogger() {
+// Secrets are masked in the output of the global and the standard loggers,
+// by the returned Redactor.
+func (d *Daemon) setupLogger() (*redact.Redactor, error) {
+	redactor, err := d.cfg.Observability.Logging.Redact.redactor()
+	if err != nil {
+		return nil, err
+	}
+
+	// Some dependencies log with the standard library logger.
+	stdlog.SetOutput(redactor.Writer(os.Stderr))
+
 	// Use custom ConsoleWriter without TimestampFieldName, because stdout
 	// is captured with systemd-cat
-	consoleWriter := zerolog.ConsoleWriter{Out: os.Stdout, NoColor: true}
+	consoleWriter := zerolog.ConsoleWriter{Out: redactor.Writer(os.Stdout), NoColor: true}
 	consoleWriter.PartsOrder = []string{
 		zerolog.LevelFieldName,
 		zerolog.CallerFieldName,
@@ -335,6 +367,36 @@ func (d *Daemon) setupLogger() {
 	// TODO: switch to slog and return logger to inject as a dependency.
 
 	log.Info().Msg(fmt.Sprintf("Logger is configured with log level %q", ll.String()))
+
+	return redactor, nil
+}
+
+// logStartup logs the effective configuration, with secrets masked, and the
+// environment in a single record, to ease diagnosing misconfigurations.
+func (d *Daemon) logStartup(redactor *redact.Redactor, args DaemonArgs) {
+	cfg, err := redactor.Map(d.cfg)
+	if err != nil {
+		log.Warn().Err(err).Msg("Cannot log the effective configuration")
+	}
+
+	// Logged whatever the configured level, like a banner.
+	log.Log().Str(zerolog.LevelFieldName, zerolog.LevelInfoValue).
+		Str("config_file", args.ConfigFile).
+		Interface("config", cfg).
+		Str("install", installType()).
+		Str("snap_revision", os.Getenv("SNAP_REVISION")).
+		Bool("supervised", args.Supervised).
+		Str("go_version", runtime.Version()).
+		Msg("MAAS agent starting")
+}
+
+// installType returns how the agent is installed, "snap" or "deb".
+func installType() string {
+	if _, ok := os.LookupEnv("SNAP"); ok {
+		return "snap"
+	}
+
+	return "deb"
 }
 
 // setupObservability initializes metrics, tracing, and profiling based on cfg.
@@ -404,6 +466,8 @@ func (d *Daemon) setupProfiler() error {
 
 // Stop gracefully shuts down the daemon.
 func (d *Daemon) Stop(ctx context.Context) error {
+	d.events.Publish(events.Event{Type: events.AgentStopping})
+
 	// TODO: All the services should be considered here.
 	if d.server == nil {
 		return nil
@@ -474,12 +538,12 @@ type rackdConfig struct {
 // This function would be no longer required once twisted RPC is gone,
 // and rackd is no longer the supervisor of the agent.
 // TODO: Remove once Python based rackd is obsolete.
-func startRackd(fs afero.Fs, cfg rackdConfig) error {
+func startRackd(ctx context.Context, fs afero.Fs, cfg rackdConfig) error {
 	if err := writeRackdConfig(fs, cfg); err != nil {
 		return err
 	}
 
-	if err := restartRackd(); err != nil {
+	if err := restartRackd(ctx, execx.New()); err != nil {
 		return err
 	}
 
@@ -498,10 +562,7 @@ func writeRackdConfig(fs afero.Fs, cfg rackdConfig) error {
 		}
 	}
 
-	commonDir := "/var/lib/maas"
-	if dir := os.Getenv("SNAP_COMMON"); dir != "" {
-		commonDir = filepath.Join(filepath.Clean(dir), "maas")
-	}
+	commonDir := rackdCommonDir()
 
 	dataDir := "/etc/maas"
 	if dir := os.Getenv("SNAP_DATA"); dir != "" {
@@ -540,36 +601,52 @@ func writeRackdConfig(fs afero.Fs, cfg rackdConfig) error {
 	return nil
 }
 
+// rackdCommonDir returns the directory holding the files rackd shares with
+// the agent, such as the secret shared with the region.
+// TODO: Remove once Python based rackd is obsolete.
+func rackdCommonDir() string {
+	if dir := os.Getenv("SNAP_COMMON"); dir != "" {
+		return filepath.Join(filepath.Clean(dir), "maas")
+	}
+
+	return "/var/lib/maas"
+}
+
 // restartRackd transitions the system from the agent to the legacy rackd service.
 //
 // This method is intended for backward compatibility during the transition
 // from rackd-supervised agents to standalone operation.
 // TODO: Remove once Python based rackd is obsolete.
-func restartRackd() error {
+func restartRackd(ctx context.Context, runner execx.Runner) error {
 	if snap := os.Getenv("SNAP"); snap != "" {
 		snap = filepath.Clean(snap)
 
-		return runAll(
-			exec.Command("snapctl", "stop", "maas.pebble"),
-			//nolint:gosec // G204 previous .Clean and .Join should be enough
-			exec.Command(filepath.Join(snap, "bin/reconfigure-pebble")),
-			exec.Command("snapctl", "start", "maas.pebble"),
+		return runAll(ctx, runner,
+			execx.Command("snapctl", "stop", "maas.pebble"),
+			execx.Command(filepath.Join(snap, "bin/reconfigure-pebble")),
+			execx.Command("snapctl", "start", "maas.pebble"),
 		)
 	}
 
-	return runAll(
-		exec.Command("systemctl", "stop", "maas-rackd"),
-		exec.Command("systemctl", "enable", "maas-rackd"),
-		exec.Command("systemctl", "start", "maas-rackd"),
+	return runAll(ctx, runner,
+		execx.Command("systemctl", "stop", "maas-rackd"),
+		execx.Command("systemctl", "enable", "maas-rackd"),
+		execx.Command("systemctl", "start", "maas-rackd"),
 	)
 }
 
 // runAll executes a sequence of commands and stops at the first failure.
-func runAll(cmds ...*exec.Cmd) error {
+func runAll(ctx context.Context, runner execx.Runner, cmds ...*execx.Cmd) error {
 	for _, cmd := range cmds {
-		if out, err := cmd.CombinedOutput(); err != nil {
-			return fmt.Errorf("command %q failed: %s: %w", cmd.String(),
-				string(bytes.TrimSpace(out)), err)
+		cmd.Combined = true
+
+		if res, err := runner.Run(ctx, cmd); err != nil {
+			var out []byte
+			if res != nil {
+				out = bytes.TrimSpace(res.Stdout)
+			}
+
+			return fmt.Errorf("command %q failed: %s: %w", cmd.String(), out, err)
 		}
 	}
 
@@ -23,7 +23,6 @@ import (
 	"os"
 	"time"
 
-	"github.com/cenkalti/backoff/v4"
 	"go.opentelemetry.io/otel/metric"
 	temporalenum "go.temporal.io/api/enums/v1"
 	temporalclient "go.temporal.io/sdk/client"
@@ -34,6 +33,7 @@ import (
 	"maas.io/core/src/maasagent/internal/client"
 	"maas.io/core/src/maasagent/internal/cluster"
 	"maas.io/core/src/maasagent/internal/dhcp"
+	"maas.io/core/src/maasagent/internal/events"
 	"maas.io/core/src/maasagent/internal/httpproxy"
 	"maas.io/core/src/maasagent/internal/pathutil"
 	"maas.io/core/src/maasagent/internal/power"
@@ -41,6 +41,7 @@ import (
 	"maas.io/core/src/maasagent/internal/servicecontroller"
 	"maas.io/core/src/maasagent/internal/temporal"
 	"maas.io/core/src/maasagent/internal/workflow/worker"
+	"maas.io/core/src/maasgocommon/retry"
 )
 
 type powerSvcConfig struct {
@@ -82,12 +83,26 @@ func newResolverService(cfg resolverSvcConfig) (*resolver.ResolverService, error
 		return nil, err
 	}
 
-	resolverHandler := resolver.NewRecursiveHandler(
-		resolverCache,
+	options := []resolver.RecursiveHandlerOption{
 		resolver.WithConnPoolSize(cfg.ConnectionPool),
 		resolver.WithDialTimeout(cfg.DialTimeout),
 		resolver.WithHandlerMetrics(cfg.meter),
-	)
+	}
+
+	if cfg.QueryLog.Enabled {
+		queryLog, err := resolver.NewQueryLog(
+			resolver.WithSampleRate(cfg.QueryLog.SampleRate),
+			resolver.WithClientAnonymization(cfg.QueryLog.AnonymizeClients),
+			resolver.WithQNameAnonymization(resolver.QNameAnonymization(cfg.QueryLog.QNames)),
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		options = append(options, resolver.WithQueryLog(queryLog))
+	}
+
+	resolverHandler := resolver.NewRecursiveHandler(resolverCache, options...)
 
 	return resolver.NewResolverService(resolverHandler), nil
 }
@@ -117,6 +132,8 @@ type dhcpSvcConfig struct {
 	agentUUID  string
 	// TODO: systemID should be removed.
 	systemID string
+	// journalKey encrypts the DHCP notification journal, if set.
+	journalKey []byte
 }
 
 func newDHCPService(c dhcpSvcConfig) (*dhcp.DHCPService, error) {
@@ -145,11 +162,16 @@ func newExternalDHCPService(c dhcpSvcConfig) (*dhcp.DHCPService, error) {
 		return nil, fmt.Errorf("DHCP v6 controller initialization failed: %w", err)
 	}
 
+	options := []dhcp.DHCPServiceOption{dhcp.WithAPIClient(c.apiClient)}
+	if c.journalKey != nil {
+		options = append(options, dhcp.WithJournalEncryptionKey(c.journalKey))
+	}
+
 	// TODO: systemID should not be used. Consider switching to agentUUID
 	dhcpService := dhcp.NewDHCPService(
 		c.systemID,
 		controllerV4, controllerV6, false,
-		dhcp.WithAPIClient(c.apiClient))
+		options...)
 
 	return dhcpService, nil
 }
@@ -220,12 +242,18 @@ func (d *Daemon) startServices(ctx context.Context, g *errgroup.Group) error {
 			},
 		})
 
+	journalKey, err := d.cfg.State.Encryption.key(d.fs, dhcpJournalKeyPurpose)
+	if err != nil {
+		return fmt.Errorf("failed to initialize dhcp service: %w", err)
+	}
+
 	dhcpService, err := newDHCPService(dhcpSvcConfig{
 		agentUUID:  id,
 		systemID:   d.dynCfg.SystemID,
 		clusterSvc: clusterService,
 		apiClient:  apiClient,
 		meter:      d.meterProvider.Meter("dhcp"),
+		journalKey: journalKey,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to initialize dhcp service: %w", err)
@@ -237,6 +265,7 @@ func (d *Daemon) startServices(ctx context.Context, g *errgroup.Group) error {
 		httpProxyService,
 		resolverService,
 		dhcpService,
+		d.reconciler,
 	}
 
 	workerPoolOptions := []worker.WorkerPoolOption{
@@ -263,17 +292,25 @@ func (d *Daemon) startServices(ctx context.Context, g *errgroup.Group) error {
 
 	workerPool = *worker.NewWorkerPool(d.dynCfg.SystemID, temporalClient,
 		workerPoolOptions...)
-	workerPoolBackoff := backoff.NewExponentialBackOff()
-	workerPoolBackoff.MaxElapsedTime = 60 * time.Second
-
-	if err := backoff.Retry(workerPool.Start, workerPoolBackoff); err != nil {
+	if err := retry.Do(ctx, func(context.Context) error {
+		return workerPool.Start()
+	}, retry.WithMaxElapsedTime(60*time.Second)); err != nil {
 		return fmt.Errorf("failed to initialize worker pool: %w", err)
 	}
+
+	for _, name := range []string{"cluster", "power", "http_proxy", "resolver", "dhcp"} {
+		d.events.Publish(events.Event{Type: events.ServiceStarted, Service: name})
+	}
+
 	// TODO: Add support for cancellation context
-	g.Go(workerPool.Error)
-	g.Go(clusterService.Error)
-	g.Go(httpProxyService.Error)
-	g.Go(dhcpService.Error)
+	g.Go(d.publishFailure("worker_pool", workerPool.Error))
+	g.Go(d.publishFailure("cluster", clusterService.Error))
+	g.Go(d.publishFailure("http_proxy", httpProxyService.Error))
+	g.Go(d.publishFailure("dhcp", dhcpService.Error))
+	g.Go(func() error {
+		d.reconciler.Run(ctx)
+		return nil
+	})
 	// Region controller will start configuration workflows based on certain
 	// events, however this explicit call from the agent is used to cover
 	// situations when agent is (re)started and has a clean state.
@@ -303,5 +340,20 @@ func (d *Daemon) startServices(ctx context.Context, g *errgroup.Group) error {
 		return fmt.Errorf("configure-agent workflow failed: %w", err)
 	}
 
+	d.events.Publish(events.Event{Type: events.ConfigApplied})
+
 	return nil
 }
+
+// publishFailure returns a function waiting for the error of a service,
+// publishing it as an event of the service name.
+func (d *Daemon) publishFailure(name string, wait func() error) func() error {
+	return func() error {
+		err := wait()
+		if err != nil {
+			d.events.Publish(events.Event{Type: events.ServiceFailed, Service: name, Message: err.Error()})
+		}
+
+		return err
+	}
+}
@@ -0,0 +1,351 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package daemon
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/afero"
+	"maas.io/core/src/maasagent/internal/atomicfile"
+	"maas.io/core/src/maasagent/internal/certutil"
+	"maas.io/core/src/maasagent/internal/localstore"
+)
+
+// Purposes of the keys derived from the state encryption secret.
+const (
+	storeKeyPurpose       = "state store"
+	dhcpJournalKeyPurpose = "dhcp notification journal"
+)
+
+// identityKey is the key of the agent identity in the credentials bucket.
+const identityKey = "identity"
+
+// identity is the TLS identity of the agent, exported as credentials so
+// that the replacement agent doesn't need enrolling again.
+type identity struct {
+	Key  string `json:"key"`
+	Cert string `json:"cert"`
+	CA   string `json:"ca"`
+}
+
+var credentials = localstore.NewBucket[identity](localstore.BucketCredentials)
+
+// StateOptions holds parameters used to export or import agent local state.
+type StateOptions struct {
+	ConfigFile string
+	StoreFile  string
+	// DiscoveryFile is the store of the bindings discovered by maas-netmon.
+	DiscoveryFile string
+	Archive       string
+	// Passphrase protects credentials inside the archive.
+	// Credentials are not exported when it is empty.
+	Passphrase []byte
+	// Replace existing entries instead of merging them on import.
+	Replace bool
+}
+
+// StateSummary describes the outcome of a state export or import.
+type StateSummary struct {
+	Entries int
+	// CacheFiles is the number of image cache files listed in the manifest.
+	CacheFiles int
+	// MissingCacheFiles lists image cache files from the manifest,
+	// that are not present (or differ in size) on this host after import.
+	MissingCacheFiles []string
+	// Identity tells whether the agent identity was exported or imported.
+	Identity bool
+}
+
+// ExportState writes the agent local state together with a manifest of
+// the image cache, so they can be moved to replacement hardware.
+func (d *Daemon) ExportState(ctx context.Context, opts StateOptions) (*StateSummary, error) {
+	cfg, err := loadConfig(d.fs, opts.ConfigFile)
+	if err != nil {
+		return nil, fmt.Errorf("loading config: %w", err)
+	}
+
+	manifest, err := localstore.BuildManifest(d.cacheFS(cfg))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("building image cache manifest: %w", err)
+	}
+
+	store, err := d.openStore(ctx, cfg, opts.StoreFile)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err := store.Close(); err != nil {
+			log.Warn().Err(err).Msg("Failed to close local store")
+		}
+	}()
+
+	// The discovery history and the identity are only kept in the store
+	// while they are exported.
+	defer func() {
+		if err := clearBuckets(ctx, store, localstore.BucketDiscovery, localstore.BucketCredentials); err != nil {
+			log.Warn().Err(err).Msg("Failed to clear exported state from the local store")
+		}
+	}()
+
+	if err := d.exportDiscovery(ctx, store, opts.DiscoveryFile); err != nil {
+		return nil, err
+	}
+
+	summary := &StateSummary{CacheFiles: len(manifest)}
+
+	// Credentials are only exported with a passphrase.
+	if opts.Passphrase != nil {
+		if err := d.storeIdentity(ctx, store, cfg); err != nil {
+			return nil, err
+		}
+
+		summary.Identity = true
+	}
+
+	f, err := d.fs.OpenFile(opts.Archive, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("creating archive: %w", err)
+	}
+
+	summary.Entries, err = store.Export(ctx, f, localstore.ExportOptions{
+		Passphrase: opts.Passphrase,
+		Manifest:   manifest,
+	})
+	if err != nil {
+		return nil, errors.Join(err, f.Close())
+	}
+
+	if err := f.Close(); err != nil {
+		return nil, fmt.Errorf("writing archive: %w", err)
+	}
+
+	return summary, nil
+}
+
+// ImportState loads agent local state exported with ExportState and
+// verifies that the image cache was copied as well.
+func (d *Daemon) ImportState(ctx context.Context, opts StateOptions) (*StateSummary, error) {
+	cfg, err := loadConfig(d.fs, opts.ConfigFile)
+	if err != nil {
+		return nil, fmt.Errorf("loading config: %w", err)
+	}
+
+	store, err := d.openStore(ctx, cfg, opts.StoreFile)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err := store.Close(); err != nil {
+			log.Warn().Err(err).Msg("Failed to close local store")
+		}
+	}()
+
+	f, err := d.fs.Open(opts.Archive)
+	if err != nil {
+		return nil, fmt.Errorf("opening archive: %w", err)
+	}
+
+	defer f.Close() //nolint:errcheck // file is opened for reading only
+
+	defer func() {
+		if err := clearBuckets(ctx, store, localstore.BucketDiscovery, localstore.BucketCredentials); err != nil {
+			log.Warn().Err(err).Msg("Failed to clear imported state from the local store")
+		}
+	}()
+
+	result, err := store.Import(ctx, f, localstore.ImportOptions{
+		Passphrase: opts.Passphrase,
+		Replace:    opts.Replace,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := d.importDiscovery(ctx, store, opts.DiscoveryFile, opts.Replace); err != nil {
+		return nil, err
+	}
+
+	restored, err := d.restoreIdentity(ctx, store, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	mismatched, err := localstore.VerifyManifest(d.cacheFS(cfg), result.Manifest)
+	if err != nil {
+		return nil, fmt.Errorf("verifying image cache: %w", err)
+	}
+
+	summary := &StateSummary{Entries: result.Entries, CacheFiles: len(result.Manifest), Identity: restored}
+	for _, entry := range mismatched {
+		summary.MissingCacheFiles = append(summary.MissingCacheFiles, entry.Path)
+	}
+
+	return summary, nil
+}
+
+// openStore opens the local state store, encrypted as configured.
+func (d *Daemon) openStore(ctx context.Context, cfg *Config, path string) (*localstore.Store, error) {
+	key, err := cfg.State.Encryption.key(d.fs, storeKeyPurpose)
+	if err != nil {
+		return nil, err
+	}
+
+	var options []localstore.Option
+
+	if key != nil {
+		options = append(options, localstore.WithEncryptionKey(key))
+	}
+
+	return localstore.Open(ctx, path, options...)
+}
+
+// exportDiscovery copies the discovery history from the store of
+// maas-netmon at path into store.
+func (d *Daemon) exportDiscovery(ctx context.Context, store *localstore.Store, path string) error {
+	if path == "" {
+		return nil
+	}
+
+	// maas-netmon never ran, there is no history.
+	if _, err := d.fs.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	return withDiscoveryStore(ctx, path, func(discovery *localstore.Store) error {
+		_, err := localstore.CopyBucket(ctx, store, discovery, localstore.BucketDiscovery, true)
+		return err
+	})
+}
+
+// importDiscovery copies the discovery history from store into the store
+// of maas-netmon at path, replacing its history if replace is set.
+func (d *Daemon) importDiscovery(ctx context.Context, store *localstore.Store, path string, replace bool) error {
+	if path == "" {
+		return nil
+	}
+
+	return withDiscoveryStore(ctx, path, func(discovery *localstore.Store) error {
+		_, err := localstore.CopyBucket(ctx, discovery, store, localstore.BucketDiscovery, replace)
+		return err
+	})
+}
+
+// withDiscoveryStore calls fn with the store of maas-netmon at path.
+func withDiscoveryStore(ctx context.Context, path string, fn func(*localstore.Store) error) error {
+	store, err := localstore.Open(ctx, path)
+	if err != nil {
+		return fmt.Errorf("opening %s: %w", path, err)
+	}
+
+	if err := fn(store); err != nil {
+		return errors.Join(fmt.Errorf("copying discovery history: %w", err), store.Close())
+	}
+
+	return store.Close()
+}
+
+// storeIdentity puts the TLS identity of the agent in the credentials
+// bucket of store.
+func (d *Daemon) storeIdentity(ctx context.Context, store *localstore.Store, cfg *Config) error {
+	var id identity
+
+	for _, f := range []struct {
+		dst  *string
+		path string
+	}{
+		{&id.Key, cfg.TLS.KeyFile},
+		{&id.Cert, cfg.TLS.CertFile},
+		{&id.CA, cfg.TLS.CAFile},
+	} {
+		data, err := afero.ReadFile(d.fs, f.path)
+		if err != nil {
+			return fmt.Errorf("reading agent identity: %w", err)
+		}
+
+		*f.dst = string(data)
+	}
+
+	err := store.Update(ctx, func(tx *localstore.Tx) error {
+		return credentials.Put(tx, identityKey, id)
+	})
+	if err != nil {
+		return fmt.Errorf("storing agent identity: %w", err)
+	}
+
+	return nil
+}
+
+// restoreIdentity writes the TLS identity of the credentials bucket of
+// store to the files of the configuration, and tells whether there was one.
+func (d *Daemon) restoreIdentity(ctx context.Context, store *localstore.Store, cfg *Config) (bool, error) {
+	var id identity
+
+	err := store.View(ctx, func(tx *localstore.Tx) error {
+		var err error
+
+		id, err = credentials.Get(tx, identityKey)
+
+		return err
+	})
+	if errors.Is(err, localstore.ErrNotFound) {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("reading agent identity: %w", err)
+	}
+
+	if _, err := tls.X509KeyPair([]byte(id.Cert), []byte(id.Key)); err != nil {
+		return false, fmt.Errorf("invalid agent identity: %w", err)
+	}
+
+	if err := certutil.WriteCertificatePEM(d.fs, cfg.TLS.CAFile, []byte(id.CA)); err != nil {
+		return false, err
+	}
+
+	if err := certutil.WriteCertificatePEM(d.fs, cfg.TLS.CertFile, []byte(id.Cert)); err != nil {
+		return false, err
+	}
+
+	if err := atomicfile.WriteFileWithFs(d.fs, cfg.TLS.KeyFile, []byte(id.Key), 0o600); err != nil {
+		return false, fmt.Errorf("failed to write key to %s: %w", cfg.TLS.KeyFile, err)
+	}
+
+	return true, nil
+}
+
+// clearBuckets removes the entries of the named buckets of store.
+func clearBuckets(ctx context.Context, store *localstore.Store, names ...string) error {
+	return store.Update(ctx, func(tx *localstore.Tx) error {
+		for _, name := range names {
+			if err := localstore.NewBucket[struct{}](name).Clear(tx); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (d *Daemon) cacheFS(cfg *Config) fs.FS {
+	return afero.NewIOFS(afero.NewBasePathFs(d.fs, cfg.Services.HTTPProxy.Cache.Dir))
+}
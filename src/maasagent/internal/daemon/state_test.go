@@ -0,0 +1,164 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package daemon
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maas.io/core/src/maasagent/internal/certutil"
+	"maas.io/core/src/maasagent/internal/localstore"
+	certtest "maas.io/core/src/maasagent/internal/testing/cert"
+)
+
+// binding is a discovery history entry, as maas-netmon stores it.
+type binding struct {
+	IP  string `json:"ip"`
+	MAC string `json:"mac"`
+}
+
+var bindings = localstore.NewBucket[binding](localstore.BucketDiscovery)
+
+// writeStateConfig writes the configuration of an agent with its files
+// in dir and returns its path.
+func writeStateConfig(t *testing.T, dir string) string {
+	t.Helper()
+
+	cfg := fmt.Sprintf(`controller: https://maas.internal
+tls:
+  key_file: %[1]s/agent.key
+  cert_file: %[1]s/agent.crt
+  ca_file: %[1]s/ca.pem
+services:
+  http_proxy:
+    cache:
+      dir: %[1]s/cache
+`, dir)
+
+	path := filepath.Join(dir, "agent.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(cfg), 0o600))
+
+	return path
+}
+
+func TestExportImportState(t *testing.T) {
+	fs := afero.NewOsFs()
+
+	crt := certtest.GenerateTestCertificate(t)
+	ca := certtest.GenerateTestCA(t)
+
+	// The agent being replaced, which maas-netmon ran on.
+	src := t.TempDir()
+	srcOpts := StateOptions{
+		ConfigFile:    writeStateConfig(t, src),
+		StoreFile:     filepath.Join(src, "state.db"),
+		DiscoveryFile: filepath.Join(src, "discovery.db"),
+		Archive:       filepath.Join(t.TempDir(), "state.jsonl"),
+		Passphrase:    []byte("passphrase"),
+	}
+
+	require.NoError(t, certutil.WriteCertificate(fs, filepath.Join(src, "agent.crt"), crt))
+	require.NoError(t, certutil.WritePrivateKey(fs, filepath.Join(src, "agent.key"), crt.PrivateKey))
+	require.NoError(t, certutil.WriteCertificate(fs, filepath.Join(src, "ca.pem"), ca))
+
+	discovery, err := localstore.Open(t.Context(), srcOpts.DiscoveryFile)
+	require.NoError(t, err)
+	require.NoError(t, discovery.Update(t.Context(), func(tx *localstore.Tx) error {
+		return bindings.Put(tx, "eth0/0_10.0.0.1", binding{IP: "10.0.0.1", MAC: "00:16:3e:00:00:01"})
+	}))
+	require.NoError(t, discovery.Close())
+
+	d := New()
+
+	summary, err := d.ExportState(t.Context(), srcOpts)
+	require.NoError(t, err)
+	assert.Equal(t, 2, summary.Entries)
+	assert.True(t, summary.Identity)
+
+	// The replacement agent, which has neither history nor identity.
+	dst := t.TempDir()
+	dstOpts := StateOptions{
+		ConfigFile:    writeStateConfig(t, dst),
+		StoreFile:     filepath.Join(dst, "state.db"),
+		DiscoveryFile: filepath.Join(dst, "discovery.db"),
+		Archive:       srcOpts.Archive,
+		Passphrase:    srcOpts.Passphrase,
+	}
+
+	summary, err = d.ImportState(t.Context(), dstOpts)
+	require.NoError(t, err)
+	assert.Equal(t, 2, summary.Entries)
+	assert.True(t, summary.Identity)
+
+	discovery, err = localstore.Open(t.Context(), dstOpts.DiscoveryFile)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { require.NoError(t, discovery.Close()) })
+
+	require.NoError(t, discovery.View(t.Context(), func(tx *localstore.Tx) error {
+		b, err := bindings.Get(tx, "eth0/0_10.0.0.1")
+		assert.Equal(t, binding{IP: "10.0.0.1", MAC: "00:16:3e:00:00:01"}, b)
+
+		return err
+	}))
+
+	for _, name := range []string{"agent.crt", "agent.key", "ca.pem"} {
+		expected, err := os.ReadFile(filepath.Join(src, name))
+		require.NoError(t, err)
+
+		actual, err := os.ReadFile(filepath.Join(dst, name))
+		require.NoError(t, err)
+		assert.Equal(t, expected, actual, name)
+	}
+
+	// Neither is kept in the state store of either agent.
+	for _, path := range []string{srcOpts.StoreFile, dstOpts.StoreFile} {
+		store, err := localstore.Open(t.Context(), path)
+		require.NoError(t, err)
+
+		require.NoError(t, store.View(t.Context(), func(tx *localstore.Tx) error {
+			_, err := credentials.Get(tx, identityKey)
+			assert.ErrorIs(t, err, localstore.ErrNotFound)
+
+			_, err = bindings.Get(tx, "eth0/0_10.0.0.1")
+			assert.ErrorIs(t, err, localstore.ErrNotFound)
+
+			return nil
+		}))
+		require.NoError(t, store.Close())
+	}
+}
+
+func TestExportStateWithoutPassphrase(t *testing.T) {
+	dir := t.TempDir()
+	opts := StateOptions{
+		ConfigFile:    writeStateConfig(t, dir),
+		StoreFile:     filepath.Join(dir, "state.db"),
+		DiscoveryFile: filepath.Join(dir, "discovery.db"),
+		Archive:       filepath.Join(dir, "state.jsonl"),
+	}
+
+	// Neither the identity files nor the discovery store are read.
+	summary, err := New().ExportState(t.Context(), opts)
+	require.NoError(t, err)
+	assert.Equal(t, 0, summary.Entries)
+	assert.False(t, summary.Identity)
+}
@@ -0,0 +1,144 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package delta applies the binary deltas the region sends instead of full
+// configuration files, when the agent reports the hash of the configuration
+// it already has.
+//
+// Bases and targets are addressed by their SHA-256. A delta is encoded as:
+//
+//	uvarint base length
+//	uvarint target length
+//	ops until the end of the delta:
+//		0x01 uvarint offset, uvarint length   copy a range of the base
+//		0x02 uvarint length, bytes            insert literal bytes
+//
+// The region side lives in maascommon/utils/delta.py.
+package delta
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+const (
+	opCopy   = 0x01
+	opInsert = 0x02
+)
+
+// ErrMalformed is returned when a delta can't be applied to a base.
+var ErrMalformed = errors.New("malformed delta")
+
+// Hash returns the address of data, as used by the region for bases and
+// targets.
+func Hash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+type reader struct {
+	data []byte
+	pos  int
+}
+
+func (r *reader) uvarint() (uint64, error) {
+	v, n := binary.Uvarint(r.data[r.pos:])
+	if n <= 0 {
+		return 0, fmt.Errorf("%w: bad varint at offset %d", ErrMalformed, r.pos)
+	}
+
+	r.pos += n
+
+	return v, nil
+}
+
+// Apply rebuilds the target of delta from base.
+func Apply(base, delta []byte) ([]byte, error) {
+	r := &reader{data: delta}
+
+	baseLen, err := r.uvarint()
+	if err != nil {
+		return nil, err
+	}
+
+	targetLen, err := r.uvarint()
+	if err != nil {
+		return nil, err
+	}
+
+	if baseLen != uint64(len(base)) {
+		return nil, fmt.Errorf("%w: expects a %d bytes base, got %d",
+			ErrMalformed, baseLen, len(base))
+	}
+
+	// Don't trust the target length for the allocation, configurations
+	// usually are about the size of their base.
+	out := make([]byte, 0, min(targetLen, uint64(len(base)+len(delta))))
+
+	for r.pos < len(delta) {
+		op := delta[r.pos]
+		r.pos++
+
+		switch op {
+		case opCopy:
+			offset, err := r.uvarint()
+			if err != nil {
+				return nil, err
+			}
+
+			length, err := r.uvarint()
+			if err != nil {
+				return nil, err
+			}
+
+			if offset > baseLen || length > baseLen-offset {
+				return nil, fmt.Errorf("%w: copy past the end of the base", ErrMalformed)
+			}
+
+			out = append(out, base[offset:offset+length]...)
+		case opInsert:
+			length, err := r.uvarint()
+			if err != nil {
+				return nil, err
+			}
+
+			if length > uint64(len(delta)-r.pos) {
+				return nil, fmt.Errorf("%w: insert past the end of the delta", ErrMalformed)
+			}
+
+			n := int(length) //nolint:gosec // bounded by len(delta)
+
+			out = append(out, delta[r.pos:r.pos+n]...)
+			r.pos += n
+		default:
+			return nil, fmt.Errorf("%w: unknown op %#x", ErrMalformed, op)
+		}
+
+		if uint64(len(out)) > targetLen {
+			return nil, fmt.Errorf("%w: produces more than its %d bytes target",
+				ErrMalformed, targetLen)
+		}
+	}
+
+	if uint64(len(out)) != targetLen {
+		return nil, fmt.Errorf("%w: produced %d bytes, expected %d",
+			ErrMalformed, len(out), targetLen)
+	}
+
+	return out, nil
+}
@@ -0,0 +1,106 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package delta
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHash(t *testing.T) {
+	assert.Equal(t, "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad", Hash([]byte("abc")))
+}
+
+func TestApply(t *testing.T) {
+	// The first vectors are also checked against make_delta in
+	// tests/maascommon/utils/test_delta.py.
+	testcases := map[string]struct {
+		base  string
+		delta []byte
+		out   string
+	}{
+		"empty": {
+			delta: []byte{0, 0},
+		},
+		"replace line": {
+			base:  "a\nb\n",
+			delta: []byte{4, 4, opCopy, 0, 2, opInsert, 2, 'c', '\n'},
+			out:   "a\nc\n",
+		},
+		"multi-byte varints": {
+			base:  string(make([]byte, 300)),
+			delta: []byte{0xac, 0x02, 0xac, 0x02, opCopy, 0, 0xac, 0x02},
+			out:   string(make([]byte, 300)),
+		},
+		"copies out of order": {
+			base:  "abcdef",
+			delta: []byte{6, 7, opCopy, 3, 3, opInsert, 1, '-', opCopy, 0, 3},
+			out:   "def-abc",
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			out, err := Apply([]byte(tc.base), tc.delta)
+			require.NoError(t, err)
+			assert.Equal(t, tc.out, string(out))
+		})
+	}
+}
+
+func TestApplyMalformed(t *testing.T) {
+	testcases := map[string][]byte{
+		"missing header":   {},
+		"base length":      {5, 1},
+		"copy past base":   {1, 1, opCopy, 0, 2},
+		"copy overflow":    {1, 1, opCopy, 1, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01},
+		"insert past end":  {1, 3, opInsert, 5, 1},
+		"unknown op":       {1, 1, 0x7f},
+		"short target":     {1, 2, opCopy, 0, 1},
+		"long target":      {1, 1, opCopy, 0, 1, opCopy, 0, 1},
+		"truncated varint": {1, 1, opCopy, 0x80},
+	}
+
+	for name, delta := range testcases {
+		t.Run(name, func(t *testing.T) {
+			_, err := Apply([]byte("a"), delta)
+			assert.ErrorIs(t, err, ErrMalformed)
+		})
+	}
+}
+
+func FuzzApply(f *testing.F) {
+	f.Add([]byte("a\nb\n"), []byte{4, 4, opCopy, 0, 2, opInsert, 2, 'c', '\n'})
+	f.Add([]byte("abcdef"), []byte{6, 7, opCopy, 3, 3, opInsert, 1, '-', opCopy, 0, 3})
+
+	f.Fuzz(func(t *testing.T, base, delta []byte) {
+		out, err := Apply(base, delta)
+		if err != nil {
+			return
+		}
+
+		// A delta that applies produces exactly its target length.
+		r := &reader{data: delta}
+		_, err = r.uvarint()
+		require.NoError(t, err)
+
+		targetLen, err := r.uvarint()
+		require.NoError(t, err)
+		assert.Equal(t, targetLen, uint64(len(out)))
+	})
+}
@@ -29,10 +29,11 @@ import (
 	"os"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/insomniacslk/dhcp/dhcpv4"
 	"github.com/rs/zerolog/log"
+
+	"maas.io/core/src/maasgocommon/clock"
 )
 
 const (
@@ -117,16 +118,18 @@ type Allocator4 interface {
 }
 
 type dqliteAllocator4 struct {
+	clock    clock.Clock
 	hostname string // attached to the allocator to avoid calling os.Hostname() on every DISCOVER
 }
 
-func newDQLiteAllocator4() (*dqliteAllocator4, error) {
+func newDQLiteAllocator4(clk clock.Clock) (*dqliteAllocator4, error) {
 	hn, err := os.Hostname()
 	if err != nil {
 		return nil, err
 	}
 
 	return &dqliteAllocator4{
+		clock:    clk,
 		hostname: hn,
 	}, nil
 }
@@ -394,7 +397,7 @@ func (d *dqliteAllocator4) setIPRangeFull(ctx context.Context, tx *sql.Tx, ipran
 }
 
 func (d *dqliteAllocator4) createOfferedLease(ctx context.Context, tx *sql.Tx, offer *Offer, mac net.HardwareAddr, iprangeID int) (*Lease, error) {
-	nowEpoch := int(time.Now().Unix())
+	nowEpoch := int(d.clock.Now().Unix())
 	lease := &Lease{
 		IP:         offer.IP,
 		MACAddress: mac,
@@ -456,7 +459,7 @@ func (d *dqliteAllocator4) ACKLease(ctx context.Context, tx *sql.Tx, ip net.IP,
 		return nil, err
 	}
 
-	now := time.Now().Unix()
+	now := d.clock.Now().Unix()
 
 	_, err = tx.ExecContext(ctx, updateLeaseStateByIDStmt, LeaseStateAcked, now, lease.ID)
 	if err != nil {
@@ -475,7 +478,7 @@ func (d *dqliteAllocator4) NACKLease(ctx context.Context, tx *sql.Tx, ip net.IP,
 }
 
 func (d *dqliteAllocator4) UpdateForRenewal(ctx context.Context, tx *sql.Tx, ip net.IP, mac net.HardwareAddr) error {
-	now := time.Now().Unix()
+	now := d.clock.Now().Unix()
 
 	result, err := tx.ExecContext(
 		ctx,
@@ -517,7 +520,7 @@ func (d *dqliteAllocator4) Release(ctx context.Context, tx *sql.Tx, ifaceIdx int
 		return err
 	}
 
-	now := time.Now().Unix()
+	now := d.clock.Now().Unix()
 
 	if lease.State == LeaseStateAcked {
 		_, err = tx.ExecContext(ctx, createExpirationStmt, lease.IP.String(), lease.MACAddress.String(), nil, now)
@@ -535,7 +538,7 @@ func (d *dqliteAllocator4) Release(ctx context.Context, tx *sql.Tx, ifaceIdx int
 // marks the IP in use by an unknown entity, disallowing it to be allocated for a lease, for some time.
 // This lease does not get reported back to the region and only serves to be used in allocation.
 func (d *dqliteAllocator4) MarkConflicted(ctx context.Context, tx *sql.Tx, ip net.IP) error {
-	now := time.Now().Unix()
+	now := d.clock.Now().Unix()
 	_, err := tx.ExecContext(ctx, createLeaseForConflict, ip.String(), now, now)
 
 	return err
@@ -28,6 +28,7 @@ import (
 	"github.com/rs/zerolog/log"
 
 	"maas.io/core/src/maasagent/internal/dhcpd"
+	"maas.io/core/src/maasgocommon/clock"
 )
 
 const (
@@ -40,13 +41,13 @@ const (
 type ExpirationHandler struct {
 	clusterState  state.State
 	leaseReporter LeaseReporter
-	tick          *time.Ticker
+	tick          clock.Ticker
 	stateLock     sync.RWMutex
 }
 
-func newExpirationHandler(sweepInterval time.Duration) *ExpirationHandler {
+func newExpirationHandler(sweepInterval time.Duration, clk clock.Clock) *ExpirationHandler {
 	return &ExpirationHandler{
-		tick: time.NewTicker(sweepInterval),
+		tick: clk.NewTicker(sweepInterval),
 	}
 }
 
@@ -57,7 +58,7 @@ func (e *ExpirationHandler) Start(ctx context.Context) error {
 		select {
 		case <-ctx.Done():
 			return nil
-		case ts := <-e.tick.C:
+		case ts := <-e.tick.C():
 			err := func() error {
 				e.stateLock.RLock()
 				defer e.stateLock.RUnlock()
@@ -76,7 +76,7 @@ var optionMarshalers = map[OptionType]optionMarshaler{
 			return nil, err
 		}
 
-		if n > int(math.MaxUint8) {
+		if n < 0 || n > int(math.MaxUint8) {
 			return nil, ErrInvalidOptionValue
 		}
 
@@ -88,7 +88,7 @@ var optionMarshalers = map[OptionType]optionMarshaler{
 			return nil, err
 		}
 
-		if n > int(math.MaxUint16) {
+		if n < 0 || n > int(math.MaxUint16) {
 			return nil, ErrInvalidOptionValue
 		}
 
@@ -104,7 +104,7 @@ var optionMarshalers = map[OptionType]optionMarshaler{
 			return nil, err
 		}
 
-		if n > int(math.MaxUint32) {
+		if n < 0 || n > int(math.MaxUint32) {
 			return nil, ErrInvalidOptionValue
 		}
 
@@ -115,14 +115,14 @@ var optionMarshalers = map[OptionType]optionMarshaler{
 		return buf, nil
 	},
 	OptionTypeUint64: func(s string) ([]byte, error) {
-		n, err := strconv.Atoi(s)
+		n, err := strconv.ParseUint(s, 10, 64)
 		if err != nil {
 			return nil, err
 		}
 
 		buf := make([]byte, 8)
 
-		binary.BigEndian.PutUint64(buf, uint64(n))
+		binary.BigEndian.PutUint64(buf, n)
 
 		return buf, nil
 	},
@@ -35,6 +35,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"maas.io/core/src/maasagent/internal/cluster"
 	"maas.io/core/src/maasagent/internal/dhcpd"
+	"maas.io/core/src/maasgocommon/clock"
 )
 
 type MockLeaseReporter struct {
@@ -318,7 +319,7 @@ func TestDORAHandlerServeDHCP4(t *testing.T) {
 							return nil
 						}
 
-						allocator, err := newDQLiteAllocator4()
+						allocator, err := newDQLiteAllocator4(clock.New())
 						if err != nil {
 							errChan <- err
 							return nil
@@ -400,6 +401,11 @@ func TestOptionMarshalers(t *testing.T) {
 			inValue: "300",
 			err:     ErrInvalidOptionValue,
 		},
+		"uint8 negative": {
+			inType:  OptionTypeUint8,
+			inValue: "-1",
+			err:     ErrInvalidOptionValue,
+		},
 		"uint16": {
 			inType:  OptionTypeUint16,
 			inValue: "300",
@@ -510,3 +516,39 @@ func TestOptionMarshalers(t *testing.T) {
 		})
 	}
 }
+
+func FuzzOptionMarshalers(f *testing.F) {
+	f.Add(uint16(dhcpv4.OptionInterfaceMTU), "1500")
+	f.Add(uint16(dhcpv4.OptionRouter), "10.0.0.1")
+	f.Add(uint16(dhcpv4.OptionDomainNameServer), "10.0.0.1, 10.0.0.2")
+	f.Add(uint16(dhcpv4.OptionDNSDomainSearchList), "maas,example.com")
+	f.Add(uint16(dhcpv4.OptionSubnetMask), "ffffff00")
+
+	// Fixed size encodings, other types vary with the value.
+	sizes := map[OptionType]int{
+		OptionTypeUint8:  1,
+		OptionTypeUint16: 2,
+		OptionTypeUint32: 4,
+		OptionTypeUint64: 8,
+		OptionTypeIPv4:   4,
+		OptionTypeIPv6:   16,
+	}
+
+	f.Fuzz(func(t *testing.T, code uint16, value string) {
+		optType := getDHCPv4OptionType(code)
+
+		marshaler, ok := optionMarshalers[optType]
+		if !ok {
+			return
+		}
+
+		out, err := marshaler(value)
+		if err != nil {
+			return
+		}
+
+		if size, ok := sizes[optType]; ok {
+			assert.Len(t, out, size)
+		}
+	})
+}
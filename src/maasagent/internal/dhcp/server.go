@@ -23,6 +23,7 @@ import (
 	"net"
 	"os"
 	"runtime"
+	"slices"
 	"sync"
 
 	"github.com/cilium/ebpf/link"
@@ -37,6 +38,10 @@ import (
 
 const (
 	maxDHCPPktSize = 1500
+	// xdpHeaderLen is the length of the metadata the XDP program puts
+	// before a DHCP packet: interface index, source MAC, port, IPv4 and
+	// IPv6 addresses.
+	xdpHeaderLen = 4 + 6 + 2 + 4 + 16
 )
 
 var bufPool = &sync.Pool{
@@ -46,8 +51,9 @@ var bufPool = &sync.Pool{
 }
 
 var (
-	ErrInvalidBuffer = errors.New("invalid buffer received from pool")
-	ErrNoSocketFound = errors.New("no DHCP socket found for IP version and interface")
+	ErrInvalidBuffer  = errors.New("invalid buffer received from pool")
+	ErrNoSocketFound  = errors.New("no DHCP socket found for IP version and interface")
+	ErrShortXDPSample = errors.New("XDP sample is shorter than its header")
 )
 
 type Handler4 interface {
@@ -265,60 +271,14 @@ func (s *Server) serveXDP(ctx context.Context) error {
 			go func() {
 				defer s.inflight.Release(1)
 
-				var (
-					msg Message
-					idx int
-				)
-
 				log.Debug().Msg("received DHCP packet via XDP")
 
-				n, err := binary.Decode(pkt.RawSample[idx:idx+4], binary.LittleEndian, &msg.IfaceIdx)
-				if err != nil {
-					readErrs <- fmt.Errorf("error reading interface info: %w", err)
-					return
-				}
-
-				idx += n
-
-				copy(msg.SrcMAC, pkt.RawSample[idx:idx+6])
-				idx += 6
-
-				n, err = binary.Decode(pkt.RawSample[idx:idx+2], binary.LittleEndian, &msg.SrcPort)
+				msg, err := parseXDPSample(pkt.RawSample)
 				if err != nil {
-					readErrs <- fmt.Errorf("error reading port info: %w", err)
+					log.Err(err).Msg("error parsing DHCP packet")
 					return
 				}
 
-				idx += n
-
-				ip4 := make(net.IP, 4)
-				copy(ip4, pkt.RawSample[idx:idx+4])
-
-				idx += 4
-
-				ip6 := make(net.IP, 16)
-				copy(ip6, pkt.RawSample[idx:idx+16])
-
-				idx += 16
-
-				if !ip4.IsUnspecified() {
-					msg.SrcIP = ip4
-
-					msg.Pkt4, err = dhcpv4.FromBytes(pkt.RawSample[idx:])
-					if err != nil {
-						readErrs <- fmt.Errorf("error parsing DHCPv4 packet: %w", err)
-						return
-					}
-				} else {
-					msg.SrcIP = ip6
-
-					msg.Pkt6, err = dhcpv6.FromBytes(pkt.RawSample[idx:])
-					if err != nil {
-						readErrs <- fmt.Errorf("error parsing DHCPv6 packet: %w", err)
-						return
-					}
-				}
-
 				if msg.Pkt4 != nil {
 					err = s.handler4.ServeDHCPv4(ctx, msg)
 				} else {
@@ -344,6 +304,43 @@ func (s *Server) serveXDP(ctx context.Context) error {
 	}
 }
 
+// parseXDPSample parses a DHCP packet queued by the XDP program. Samples
+// hold packets from the network as is, nothing in them is trusted.
+func parseXDPSample(sample []byte) (Message, error) {
+	var msg Message
+
+	if len(sample) < xdpHeaderLen {
+		return msg, fmt.Errorf("%w: %d bytes", ErrShortXDPSample, len(sample))
+	}
+
+	msg.IfaceIdx = binary.LittleEndian.Uint32(sample[0:4])
+	msg.SrcMAC = slices.Clone(net.HardwareAddr(sample[4:10]))
+	msg.SrcPort = binary.LittleEndian.Uint16(sample[10:12])
+	ip4 := slices.Clone(net.IP(sample[12:16]))
+	ip6 := slices.Clone(net.IP(sample[16:32]))
+	payload := sample[xdpHeaderLen:]
+
+	var err error
+
+	if !ip4.IsUnspecified() {
+		msg.SrcIP = ip4
+
+		msg.Pkt4, err = dhcpv4.FromBytes(payload)
+		if err != nil {
+			return msg, fmt.Errorf("error parsing DHCPv4 packet: %w", err)
+		}
+	} else {
+		msg.SrcIP = ip6
+
+		msg.Pkt6, err = dhcpv6.FromBytes(payload)
+		if err != nil {
+			return msg, fmt.Errorf("error parsing DHCPv6 packet: %w", err)
+		}
+	}
+
+	return msg, nil
+}
+
 func (s *Server) serveSockets(ctx context.Context) error {
 	msgs := make(chan Message, len(s.sockets))
 	errChan := make(chan error)
@@ -17,6 +17,7 @@ package dhcp
 
 import (
 	"context"
+	"encoding/binary"
 	"net"
 	"sync"
 	"testing"
@@ -24,6 +25,8 @@ import (
 
 	"github.com/insomniacslk/dhcp/dhcpv4"
 	"github.com/insomniacslk/dhcp/dhcpv6"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"maas.io/core/src/maasagent/internal/dhcp/xdp"
 )
 
@@ -354,3 +357,66 @@ func BenchmarkRawSocketDhcpServerThroughput(b *testing.B) {
 		wg.Wait()
 	}
 }
+
+// xdpSample returns a sample as queued by the XDP program.
+func xdpSample(ifaceIdx uint32, mac net.HardwareAddr, port uint16, ip net.IP, pkt []byte) []byte {
+	sample := binary.LittleEndian.AppendUint32(nil, ifaceIdx)
+	sample = append(sample, mac...)
+	sample = binary.LittleEndian.AppendUint16(sample, port)
+
+	ip4, ip6 := make(net.IP, 4), make(net.IP, 16)
+	if v4 := ip.To4(); v4 != nil {
+		copy(ip4, v4)
+	} else {
+		copy(ip6, ip)
+	}
+
+	sample = append(sample, ip4...)
+	sample = append(sample, ip6...)
+
+	return append(sample, pkt...)
+}
+
+func TestParseXDPSample(t *testing.T) {
+	mac := net.HardwareAddr{0x00, 0x16, 0x3e, 0x01, 0x02, 0x03}
+
+	discover, err := dhcpv4.NewDiscovery(mac)
+	require.NoError(t, err)
+
+	msg, err := parseXDPSample(xdpSample(2, mac, 68, net.ParseIP("10.0.0.2"), discover.ToBytes()))
+	require.NoError(t, err)
+
+	assert.Equal(t, uint32(2), msg.IfaceIdx)
+	assert.Equal(t, mac, msg.SrcMAC)
+	assert.Equal(t, uint16(68), msg.SrcPort)
+	assert.Equal(t, net.ParseIP("10.0.0.2").To4(), msg.SrcIP)
+	assert.Equal(t, discover.TransactionID, msg.Pkt4.TransactionID)
+	assert.Nil(t, msg.Pkt6)
+
+	_, err = parseXDPSample(xdpSample(2, mac, 68, net.ParseIP("10.0.0.2"), nil)[:xdpHeaderLen-1])
+	assert.ErrorIs(t, err, ErrShortXDPSample)
+}
+
+func FuzzParseXDPSample(f *testing.F) {
+	mac := net.HardwareAddr{0x00, 0x16, 0x3e, 0x01, 0x02, 0x03}
+
+	discover, err := dhcpv4.NewDiscovery(mac)
+	require.NoError(f, err)
+
+	solicit, err := dhcpv6.NewSolicit(mac)
+	require.NoError(f, err)
+
+	f.Add(xdpSample(2, mac, 68, net.ParseIP("10.0.0.2"), discover.ToBytes()))
+	f.Add(xdpSample(2, mac, 546, net.ParseIP("fe80::216:3eff:fe01:203"), solicit.ToBytes()))
+	f.Add([]byte{})
+
+	f.Fuzz(func(t *testing.T, sample []byte) {
+		msg, err := parseXDPSample(sample)
+		if err != nil {
+			return
+		}
+
+		// Exactly one packet is set, for the handler of its version.
+		assert.NotEqual(t, msg.Pkt4 == nil, msg.Pkt6 == nil)
+	})
+}
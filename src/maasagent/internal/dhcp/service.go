@@ -16,6 +16,7 @@
 package dhcp
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
 	"encoding/base64"
@@ -26,8 +27,8 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"net/url"
 	"os"
-	"os/exec"
 	"strconv"
 	"strings"
 	"sync"
@@ -36,7 +37,6 @@ import (
 	"time"
 
 	"github.com/canonical/microcluster/v2/state"
-	"github.com/cenkalti/backoff/v4"
 	"github.com/insomniacslk/dhcp/dhcpv4"
 	"github.com/rs/zerolog/log"
 	"go.temporal.io/api/enums/v1"
@@ -44,19 +44,26 @@ import (
 	tworkflow "go.temporal.io/sdk/workflow"
 	"maas.io/core/src/maasagent/internal/apiclient"
 	"maas.io/core/src/maasagent/internal/atomicfile"
+	"maas.io/core/src/maasagent/internal/delta"
 	"maas.io/core/src/maasagent/internal/dhcp/xdp"
 	"maas.io/core/src/maasagent/internal/dhcpd"
 	"maas.io/core/src/maasagent/internal/dhcpd/omapi"
+	"maas.io/core/src/maasagent/internal/execx"
+	"maas.io/core/src/maasagent/internal/journal"
 	"maas.io/core/src/maasagent/internal/pathutil"
 	"maas.io/core/src/maasagent/internal/servicecontroller"
 	"maas.io/core/src/maasagent/internal/workflow"
 	"maas.io/core/src/maasagent/internal/workflow/log/tag"
+	"maas.io/core/src/maasgocommon/clock"
+	maaserrors "maas.io/core/src/maasgocommon/errors"
+	"maas.io/core/src/maasgocommon/retry"
 )
 
 const (
 	dhcpdOMAPIV4Endpoint        = "localhost:7911"
 	dhcpdOMAPIV6Endpoint        = "localhost:7912"
 	dhcpdNotificationSocketName = "dhcpd.sock"
+	dhcpdNotificationJournalDir = "dhcpd-notifications"
 	flushInterval               = 5 * time.Second
 	expirationInterval          = time.Second
 )
@@ -66,6 +73,8 @@ var (
 	ErrV6NotActive               = errors.New("dhcpd6 is not active and cannot configure IPv6 hosts")
 	ErrFailedToPostNotifications = errors.New("error processing lease notifications")
 	ErrClusterStateNotSet        = errors.New("no cluster initialized")
+
+	errConfigDelta = errors.New("failed to apply DHCP configuration delta")
 )
 
 var writeConfigFile func(path string, data []byte, mode os.FileMode) error
@@ -77,45 +86,21 @@ var writeConfigFileDeb = func(path string, data []byte, mode os.FileMode) error
 	fileName := path
 	modeStr := fmt.Sprintf("%d", mode)
 
-	// create the command that runs maas-write-file
+	// run maas-write-file, feeding it the data on stdin
 	// #nosec G204: the inputs are sanitized and validated by `maas-write-file`
-	cmd := exec.Command("sudo", scriptPath, fileName, modeStr)
-
-	// feed the script by piping the data to stdin
-	stdin, err := cmd.StdinPipe()
-	if err != nil {
-		return err
-	}
-
-	// start script
-	if err = cmd.Start(); err != nil {
-		return err
-	}
-
-	// write to stdin
-	if err = func() error {
-		defer func() {
-			if closeErr := stdin.Close(); closeErr != nil {
-				err = closeErr
-			}
-		}()
-		_, err = stdin.Write(data)
-		return err
-	}(); err != nil {
-		return err
-	}
-
-	// wait for the script to finish
-	if err = cmd.Wait(); err != nil {
-		return err
-	}
+	_, err := execx.New().Run(context.Background(), &execx.Cmd{
+		Name:  "sudo",
+		Args:  []string{scriptPath, fileName, modeStr},
+		Stdin: bytes.NewReader(data),
+	})
 
-	return nil
+	return err
 }
 
 // DHCPService is a service that is responsible for setting up DHCP on MAAS Agent.
 type DHCPService struct {
 	notificationSock   net.Conn
+	leaseJournal       *journal.Journal
 	controllerV6       servicecontroller.Controller
 	controllerV4       servicecontroller.Controller
 	clusterState       state.State
@@ -129,12 +114,16 @@ type DHCPService struct {
 	omapiClientFactory omapiClientFactory
 	serverStart        func(context.Context, LeaseReporter) error
 	stateLock          *sync.RWMutex
+	configLock         *sync.Mutex
+	lastConfig         map[string][]byte
 	client             *apiclient.APIClient
+	clock              clock.Clock
 	runningV4          *atomic.Bool
 	fatal              chan error
 	running            *atomic.Bool
 	systemID           string
 	activeInterfaces   []string
+	journalKey         []byte
 	internal           bool
 }
 
@@ -172,9 +161,11 @@ func NewDHCPService(
 		dataPathFactory: pathutil.MAASDataPath,
 		internal:        internal,
 		stateLock:       &sync.RWMutex{},
+		configLock:      &sync.Mutex{},
 		runningV4:       &atomic.Bool{},
 		runningV6:       &atomic.Bool{},
 		running:         &atomic.Bool{},
+		clock:           clock.New(),
 	}
 
 	s.serverStart = s.startInternalServer
@@ -213,6 +204,22 @@ func WithDataPathFactory(factory dataPathFactory) DHCPServiceOption {
 	}
 }
 
+// WithClock sets the clock used for lease times and expiration sweeps, e.g.
+// a fake one in tests.
+func WithClock(clk clock.Clock) DHCPServiceOption {
+	return func(s *DHCPService) {
+		s.clock = clk
+	}
+}
+
+// WithJournalEncryptionKey encrypts the DHCP notification journal at rest
+// with the given 32 bytes key.
+func WithJournalEncryptionKey(key []byte) DHCPServiceOption {
+	return func(s *DHCPService) {
+		s.journalKey = key
+	}
+}
+
 func WithServerStart(fn func(context.Context, LeaseReporter) error) DHCPServiceOption {
 	return func(s *DHCPService) {
 		s.serverStart = fn
@@ -220,27 +227,24 @@ func WithServerStart(fn func(context.Context, LeaseReporter) error) DHCPServiceO
 }
 
 func queueFlush(c *apiclient.APIClient, interval time.Duration) func(context.Context, []*dhcpd.Notification) error {
-	retry := backoff.NewExponentialBackOff()
-	retry.MaxElapsedTime = interval
-
 	return func(ctx context.Context, n []*dhcpd.Notification) error {
 		body, err := json.Marshal(n)
 		if err != nil {
 			return err
 		}
 
-		return backoff.Retry(func() error {
+		return retry.Do(ctx, func(ctx context.Context) error {
 			resp, err := c.Request(ctx, http.MethodPost, "/leases", body)
 			if err != nil {
 				return err
 			}
 
 			if resp.StatusCode < 200 || resp.StatusCode >= 400 {
-				return ErrFailedToPostNotifications
+				return maaserrors.Wrap(maaserrors.FromHTTPStatus(resp.StatusCode), ErrFailedToPostNotifications)
 			}
 
 			return nil
-		}, retry)
+		}, retry.WithMaxElapsedTime(interval), retry.WithRetryIf(maaserrors.Retryable))
 	}
 }
 
@@ -374,7 +378,7 @@ func (s *DHCPService) OnNewMember(ctx context.Context, st state.State) error {
 func (s *DHCPService) startInternalServer(ctx context.Context, lr LeaseReporter) error {
 	log.Info().Msg("STARTING INTERNAL DHCP SERVER")
 
-	allocator4, err := newDQLiteAllocator4()
+	allocator4, err := newDQLiteAllocator4(s.clock)
 	if err != nil {
 		return fmt.Errorf("error initializing allocator: %w", err)
 	}
@@ -399,7 +403,7 @@ func (s *DHCPService) startInternalServer(ctx context.Context, lr LeaseReporter)
 		return fmt.Errorf("error initializing dhcp server: %w", err)
 	}
 
-	s.expirationHandler = newExpirationHandler(expirationInterval)
+	s.expirationHandler = newExpirationHandler(expirationInterval, s.clock)
 
 	go func() {
 		err := s.server.Serve(ctx)
@@ -444,8 +448,26 @@ func (s *DHCPService) start() error {
 		return fmt.Errorf("failed to change dhcp notification socket permissions: %w", err)
 	}
 
+	listenerOptions := []dhcpd.NotificationListenerOption{dhcpd.WithInterval(flushInterval)}
+
+	// Without the journal notifications are still reported, they are only
+	// lost if the agent stops before reporting them.
+	var journalOptions []journal.Option
+
+	if s.journalKey != nil {
+		journalOptions = append(journalOptions, journal.WithEncryptionKey(s.journalKey))
+	}
+
+	s.leaseJournal, err = journal.Open(s.dataPathFactory(dhcpdNotificationJournalDir),
+		journalOptions...)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to open DHCP notification journal")
+	} else {
+		listenerOptions = append(listenerOptions, dhcpd.WithJournal(s.leaseJournal))
+	}
+
 	notificationListener := dhcpd.NewNotificationListener(s.notificationSock,
-		queueFlush(s.client, flushInterval), dhcpd.WithInterval(flushInterval))
+		queueFlush(s.client, flushInterval), listenerOptions...)
 
 	var ctx context.Context
 
@@ -492,6 +514,15 @@ func (s *DHCPService) stop(ctx context.Context) error {
 		}
 	}
 
+	if s.leaseJournal != nil {
+		err := s.leaseJournal.Close()
+		if err != nil {
+			return fmt.Errorf("error closing notification journal: %w", err)
+		}
+
+		s.leaseJournal = nil
+	}
+
 	s.running.Store(false)
 
 	return nil
@@ -646,65 +677,109 @@ func (s *DHCPService) configureViaOMAPI(ctx context.Context, param ApplyConfigVi
 // in base64 format. The structure includes configuration and interface details
 // for both DHCPv4 and DHCPv6.
 type dhcpConfig struct {
-	DHCPv4Config     string `json:"dhcpd"`
-	DHCPv4Interfaces string `json:"dhcpd_interfaces"`
-	DHCPv6Interfaces string `json:"dhcpd6_interfaces"`
-	DHCPv6Config     string `json:"dhcpd6"`
+	// Deltas replace the value of the keys they are set for, built against
+	// the base the agent reported having.
+	Deltas map[string]dhcpConfigDelta `json:"deltas"`
+	// Hashes are the SHA-256 of the decoded value of each key.
+	Hashes           map[string]string `json:"hashes"`
+	DHCPv4Config     string            `json:"dhcpd"`
+	DHCPv4Interfaces string            `json:"dhcpd_interfaces"`
+	DHCPv6Interfaces string            `json:"dhcpd6_interfaces"`
+	DHCPv6Config     string            `json:"dhcpd6"`
 }
 
-// configureViaFile registered as a Temporal Activity that is invoked during the
-// DHCP configuration workflow. This activity is used when the configuration must
-// be applied via a file, which requires restarting the dhcpd daemon.
-func (s *DHCPService) configureViaFile(ctx context.Context) error {
-	config, err := s.getConfig(ctx)
-	if err != nil {
-		return err
-	}
+type dhcpConfigDelta struct {
+	Base  string `json:"base"`
+	Delta string `json:"delta"`
+}
 
-	files := map[string]string{
-		"dhcpd.conf":        config.DHCPv4Config,
-		"dhcpd-interfaces":  config.DHCPv4Interfaces,
-		"dhcpd6.conf":       config.DHCPv6Config,
-		"dhcpd6-interfaces": config.DHCPv6Interfaces,
+// dhcpConfigFiles maps the keys of the DHCP configuration to the files they
+// are written to.
+var dhcpConfigFiles = map[string]string{
+	"dhcpd":             "dhcpd.conf",
+	"dhcpd_interfaces":  "dhcpd-interfaces",
+	"dhcpd6":            "dhcpd6.conf",
+	"dhcpd6_interfaces": "dhcpd6-interfaces",
+}
+
+func (c *dhcpConfig) values() map[string]string {
+	return map[string]string{
+		"dhcpd":             c.DHCPv4Config,
+		"dhcpd_interfaces":  c.DHCPv4Interfaces,
+		"dhcpd6":            c.DHCPv6Config,
+		"dhcpd6_interfaces": c.DHCPv6Interfaces,
 	}
+}
 
-	mode := os.FileMode(0o640)
+// decode returns the content of each key, applying deltas to last.
+func (c *dhcpConfig) decode(last map[string][]byte) (map[string][]byte, error) {
+	files := make(map[string][]byte, len(dhcpConfigFiles))
 
-	v4 := []bool{false, false}
-	v6 := []bool{false, false}
+	for key, value := range c.values() {
+		var (
+			data []byte
+			err  error
+		)
 
-	for file, config := range files {
-		data, err := base64.StdEncoding.DecodeString(config)
-		if err != nil {
-			return err
-		}
+		if d, ok := c.Deltas[key]; ok {
+			base, ok := last[key]
+			if !ok || delta.Hash(base) != d.Base {
+				return nil, fmt.Errorf("%w: unknown base for %s", errConfigDelta, key)
+			}
 
-		hasData := len(data) != 0
+			raw, err := base64.StdEncoding.DecodeString(d.Delta)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %w", errConfigDelta, err)
+			}
 
-		if file == "dhcpd.conf" {
-			v4[0] = hasData
+			data, err = delta.Apply(base, raw)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %w", errConfigDelta, err)
+			}
+		} else {
+			data, err = base64.StdEncoding.DecodeString(value)
+			if err != nil {
+				return nil, err
+			}
 		}
 
-		if file == "dhcpd-interfaces" {
-			v4[1] = hasData
+		if want, ok := c.Hashes[key]; ok && delta.Hash(data) != want {
+			return nil, fmt.Errorf("%w: %s does not match its hash", errConfigDelta, key)
 		}
 
-		if file == "dhcpd6.conf" {
-			v6[0] = hasData
-		}
+		files[key] = data
+	}
 
-		if file == "dhcpd6-interfaces" {
-			v6[1] = hasData
-		}
+	return files, nil
+}
+
+// configureViaFile registered as a Temporal Activity that is invoked during the
+// DHCP configuration workflow. This activity is used when the configuration must
+// be applied via a file, which requires restarting the dhcpd daemon.
+func (s *DHCPService) configureViaFile(ctx context.Context) error {
+	s.configLock.Lock()
+	defer s.configLock.Unlock()
+
+	files, err := s.getConfigFiles(ctx)
+	if err != nil {
+		return err
+	}
 
-		path := s.dataPathFactory(file)
+	mode := os.FileMode(0o640)
+
+	for key, data := range files {
+		path := s.dataPathFactory(dhcpConfigFiles[key])
 		if err := writeConfigFile(path, data, mode); err != nil {
+			// The files on disk now differ from the last configuration.
+			s.lastConfig = nil
 			return err
 		}
 	}
 
-	runningV4 := v4[0] && v4[1]
-	runningV6 := v6[0] && v6[1]
+	s.lastConfig = files
+
+	runningV4 := len(files["dhcpd"]) != 0 && len(files["dhcpd_interfaces"]) != 0
+	runningV6 := len(files["dhcpd6"]) != 0 && len(files["dhcpd6_interfaces"]) != 0
 
 	s.runningV4.Store(runningV4)
 	s.runningV6.Store(runningV6)
@@ -713,6 +788,36 @@ func (s *DHCPService) configureViaFile(ctx context.Context) error {
 	return nil
 }
 
+// getConfigFiles fetches and decodes the DHCP configuration. Large
+// configurations only change a little at a time, so the region is asked for
+// deltas against the configuration last written. The full configuration is
+// fetched if a delta can't be applied. Must be called with configLock held.
+func (s *DHCPService) getConfigFiles(ctx context.Context) (map[string][]byte, error) {
+	bases := make(map[string]string, len(s.lastConfig))
+	for key, data := range s.lastConfig {
+		bases[key] = delta.Hash(data)
+	}
+
+	config, err := s.getConfig(ctx, bases)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := config.decode(s.lastConfig)
+	if err == nil || !errors.Is(err, errConfigDelta) {
+		return files, err
+	}
+
+	log.Warn().Err(err).Msg("Fetching full DHCP configuration")
+
+	config, err = s.getConfig(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return config.decode(nil)
+}
+
 type VLANData struct {
 	ID            int `json:"id"`
 	VID           int `json:"vid"`
@@ -1048,11 +1153,22 @@ func (s *DHCPService) restartService(ctx context.Context) error {
 
 // getConfig retrieves the DHCP configuration from the Region Controller by
 // sending a GET request to the relevant endpoint based on the systemID.
-func (s *DHCPService) getConfig(ctx context.Context) (*dhcpConfig, error) {
+// bases maps configuration keys to the hash of the content the agent has, for
+// the region to send deltas against.
+func (s *DHCPService) getConfig(ctx context.Context, bases map[string]string) (*dhcpConfig, error) {
 	var config dhcpConfig
 
 	path := fmt.Sprintf("/agents/%s/services/dhcp/config", s.systemID)
 
+	if len(bases) > 0 {
+		query := url.Values{}
+		for key, hash := range bases {
+			query.Set("base_"+key, hash)
+		}
+
+		path += "?" + query.Encode()
+	}
+
 	resp, err := s.client.Request(ctx, http.MethodGet, path, nil)
 	if err != nil {
 		return nil, err
@@ -1062,7 +1178,8 @@ func (s *DHCPService) getConfig(ctx context.Context) (*dhcpConfig, error) {
 	defer resp.Body.Close()
 
 	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
-		return nil, fmt.Errorf("unexpected status code fetching DHCP config: %d", resp.StatusCode)
+		return nil, maaserrors.Errorf(maaserrors.FromHTTPStatus(resp.StatusCode),
+			"unexpected status code fetching DHCP config: %d", resp.StatusCode)
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -46,6 +46,7 @@ import (
 	tworkflow "go.temporal.io/sdk/workflow"
 	"maas.io/core/src/maasagent/internal/apiclient"
 	"maas.io/core/src/maasagent/internal/cluster"
+	"maas.io/core/src/maasagent/internal/delta"
 	"maas.io/core/src/maasagent/internal/dhcpd"
 	"maas.io/core/src/maasagent/internal/dhcpd/omapi"
 	"maas.io/core/src/maasagent/internal/servicecontroller"
@@ -443,6 +444,97 @@ func (s *DHCPServiceTestSuite) TestConfigureViaFile() {
 	}
 }
 
+// TestConfigureViaFileDelta ensures that deltas sent by the region are applied
+// to the configuration last written.
+func (s *DHCPServiceTestSuite) TestConfigureViaFileDelta() {
+	base := []byte("configuration_v4")
+	target := []byte("configuration_v4\nmore")
+
+	var queries []url.Values
+
+	s.configHTTPServer.Config.Handler = http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		queries = append(queries, req.URL.Query())
+
+		config := map[string]any{
+			"dhcpd":             base64.StdEncoding.EncodeToString(base),
+			"dhcpd_interfaces":  "aW50ZXJmYWNlc192NA==",
+			"dhcpd6":            "",
+			"dhcpd6_interfaces": "",
+			"hashes":            map[string]string{"dhcpd": delta.Hash(base)},
+		}
+
+		if req.URL.Query().Get("base_dhcpd") != "" {
+			// Copy the 16 bytes of the base and insert "\nmore".
+			d := append([]byte{16, 21, 0x01, 0, 16, 0x02, 5}, "\nmore"...)
+			config["dhcpd"] = ""
+			config["deltas"] = map[string]any{
+				"dhcpd": map[string]string{
+					"base":  delta.Hash(base),
+					"delta": base64.StdEncoding.EncodeToString(d),
+				},
+			}
+			config["hashes"] = map[string]string{"dhcpd": delta.Hash(target)}
+		}
+
+		data, err := json.Marshal(config)
+		s.NoError(err)
+
+		_, err = rw.Write(data)
+		s.NoError(err)
+	})
+
+	_, err := s.activityEnv.ExecuteActivity("configure-dhcp-via-file")
+	s.Require().NoError(err)
+
+	_, err = s.activityEnv.ExecuteActivity("configure-dhcp-via-file")
+	s.Require().NoError(err)
+
+	s.Require().Len(queries, 2)
+	s.Empty(queries[0])
+	s.Equal(delta.Hash(base), queries[1].Get("base_dhcpd"))
+	s.Equal(delta.Hash([]byte("interfaces_v4")), queries[1].Get("base_dhcpd_interfaces"))
+
+	data, err := os.ReadFile(s.svc.dataPathFactory("dhcpd.conf"))
+	s.Require().NoError(err)
+	s.Equal(target, data)
+	s.True(s.svc.runningV4.Load())
+}
+
+// TestConfigureViaFileDeltaFallback ensures that the full configuration is
+// fetched when a delta doesn't apply.
+func (s *DHCPServiceTestSuite) TestConfigureViaFileDeltaFallback() {
+	var queries []url.Values
+
+	s.configHTTPServer.Config.Handler = http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		queries = append(queries, req.URL.Query())
+
+		config := `{"dhcpd": "Y29uZmlndXJhdGlvbl92NA==", "dhcpd_interfaces": "aW50ZXJmYWNlc192NA=="}`
+		if req.URL.Query().Get("base_dhcpd") != "" {
+			// A delta producing something else than its hash.
+			config = `{"dhcpd": "", "dhcpd_interfaces": "aW50ZXJmYWNlc192NA==",
+				"deltas": {"dhcpd": {"base": "` + delta.Hash([]byte("configuration_v4")) + `", "delta": "EAEBAAE="}},
+				"hashes": {"dhcpd": "` + delta.Hash([]byte("other")) + `"}}`
+		}
+
+		_, err := rw.Write([]byte(config))
+		s.NoError(err)
+	})
+
+	_, err := s.activityEnv.ExecuteActivity("configure-dhcp-via-file")
+	s.Require().NoError(err)
+
+	_, err = s.activityEnv.ExecuteActivity("configure-dhcp-via-file")
+	s.Require().NoError(err)
+
+	s.Require().Len(queries, 3)
+	s.NotEmpty(queries[1])
+	s.Empty(queries[2])
+
+	data, err := os.ReadFile(s.svc.dataPathFactory("dhcpd.conf"))
+	s.Require().NoError(err)
+	s.Equal([]byte("configuration_v4"), data)
+}
+
 func TestHostMarshalJSON(t *testing.T) {
 	h := Host{
 		Hostname: "localhost",
@@ -531,7 +623,7 @@ func (s *DHCPServiceTestSuite) ReturnsConfigOnSuccessfulRequest() {
 		"dhcpd6_interfaces": "cjAwdGEncyBlZ2ch"
 	}`)
 
-	config, err := s.svc.getConfig(s.T().Context())
+	config, err := s.svc.getConfig(s.T().Context(), nil)
 
 	s.NoError(err)
 	s.Equal("Y29uZmlndXJhdGlvbl92NA==", config.DHCPv4Config)
@@ -543,7 +635,7 @@ func (s *DHCPServiceTestSuite) ReturnsConfigOnSuccessfulRequest() {
 func (s *DHCPServiceTestSuite) ReturnsErrorOnRequestFailure() {
 	s.configHTTPServer.Close() // server unavailable
 
-	config, err := s.svc.getConfig(s.T().Context())
+	config, err := s.svc.getConfig(s.T().Context(), nil)
 
 	s.Nil(config)
 	s.Error(err)
@@ -554,7 +646,7 @@ func (s *DHCPServiceTestSuite) ReturnsErrorOnNon200StatusCode() {
 		rw.WriteHeader(http.StatusInternalServerError)
 	})
 
-	config, err := s.svc.getConfig(s.T().Context())
+	config, err := s.svc.getConfig(s.T().Context(), nil)
 
 	s.Nil(config)
 	s.Error(err)
@@ -564,7 +656,7 @@ func (s *DHCPServiceTestSuite) ReturnsErrorOnNon200StatusCode() {
 func (s *DHCPServiceTestSuite) ReturnsErrorOnInvalidJSONResponse() {
 	s.configAPIResponse = []byte(`invalid-json`)
 
-	config, err := s.svc.getConfig(s.T().Context())
+	config, err := s.svc.getConfig(s.T().Context(), nil)
 
 	s.Nil(config)
 	s.Error(err)
@@ -690,6 +782,24 @@ func TestQueueFlush(t *testing.T) {
 			),
 			interval: time.Second,
 		},
+		"rejected status": {
+			err: ErrFailedToPostNotifications,
+			//nolint:staticcheck // TODO: migrate to new client
+			apiClient: apiclient.NewAPIClient(
+				dummyURL,
+				&http.Client{
+					Transport: &mockRoundTripper{
+						Responses: []*http.Response{
+							{
+								StatusCode: http.StatusBadRequest,
+							},
+						},
+					},
+				},
+			),
+			// Not retried, so it fails well before the interval.
+			interval: time.Hour,
+		},
 	}
 
 	for name, tc := range testcases {
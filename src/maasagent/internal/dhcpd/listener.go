@@ -28,6 +28,7 @@ import (
 
 	"github.com/canonical/microcluster/v2/state"
 	"github.com/rs/zerolog/log"
+	"maas.io/core/src/maasagent/internal/journal"
 )
 
 const (
@@ -46,11 +47,15 @@ type Notification struct {
 	IP        string `json:"ip"`
 	Timestamp int64  `json:"timestamp"`
 	LeaseTime int64  `json:"lease_time"`
+	// seq is the journal record of the notification, if journaled is set.
+	seq       uint64
+	journaled bool
 }
 
 type NotificationListener struct {
 	conn         net.Conn
 	clusterState state.State
+	journal      *journal.Journal
 	queue        *NotificationQueue
 	buf          chan *Notification
 	pool         *sync.Pool
@@ -91,6 +96,13 @@ func WithInterval(d time.Duration) NotificationListenerOption {
 	return func(l *NotificationListener) { l.interval = d }
 }
 
+// WithJournal persists queued notifications in j, so that notifications not
+// yet reported to the region survive an agent restart or a power loss.
+// Journaled notifications are queued again when Listen starts.
+func WithJournal(j *journal.Journal) NotificationListenerOption {
+	return func(l *NotificationListener) { l.journal = j }
+}
+
 func (l *NotificationListener) Listen(ctx context.Context) {
 	internalDHCPEnabled := os.Getenv("MAAS_DHCP_INTERNAL") == "1"
 
@@ -101,6 +113,12 @@ func (l *NotificationListener) Listen(ctx context.Context) {
 		}
 	}
 
+	if l.journal != nil {
+		if err := l.replayJournal(); err != nil {
+			log.Err(err).Msg("Failed to replay DHCP notification journal")
+		}
+	}
+
 	go l.read(ctx)
 
 	interval := 5 * time.Second
@@ -116,6 +134,7 @@ func (l *NotificationListener) Listen(ctx context.Context) {
 		case <-ctx.Done():
 			return
 		case notification := <-l.buf:
+			l.appendJournal(notification)
 			heap.Push(l.queue, notification)
 		case <-ticker.C:
 			if os.Getenv("MAAS_INTERNAL_DHCP") != "1" {
@@ -205,9 +224,13 @@ func (l *NotificationListener) syncWithoutDB(ctx context.Context) error {
 		for _, n := range copied {
 			heap.Push(l.queue, n)
 		}
+
+		return err
 	}
 
-	return err
+	l.trimJournal()
+
+	return nil
 }
 
 func (l *NotificationListener) syncWithDB(ctx context.Context, tx *sql.Tx) error {
@@ -280,9 +303,77 @@ func (l *NotificationListener) syncWithDB(ctx context.Context, tx *sql.Tx) error
 	err := l.fn(ctx, copied)
 	if err != nil {
 		errs = append(errs, err) // still append to errs for deferred check
+		return err               // should be the only error occurred if execution reached here
 	}
 
-	return err // should be the only error occurred if execution reached here
+	l.trimJournal()
+
+	return nil
+}
+
+// replayJournal queues the notifications left in the journal by a previous
+// run of the agent.
+func (l *NotificationListener) replayJournal() error {
+	return l.journal.Replay(func(seq uint64, data []byte) error {
+		notification := &Notification{}
+
+		if err := json.Unmarshal(data, notification); err != nil {
+			// The record passed its checksum, it can only be dropped.
+			log.Warn().Err(err).Uint64("seq", seq).Msg("Malformed journaled DHCP notification")
+			return nil
+		}
+
+		notification.seq = seq
+		notification.journaled = true
+
+		heap.Push(l.queue, notification)
+
+		return nil
+	})
+}
+
+// appendJournal persists a notification before it is queued. A notification
+// that can't be journaled is still queued, it is only lost on a crash.
+func (l *NotificationListener) appendJournal(notification *Notification) {
+	if l.journal == nil {
+		return
+	}
+
+	data, err := json.Marshal(notification)
+	if err != nil {
+		log.Err(err).Msg("Failed to journal DHCP notification")
+		return
+	}
+
+	seq, err := l.journal.Append(data)
+	if err != nil {
+		log.Err(err).Msg("Failed to journal DHCP notification")
+		return
+	}
+
+	notification.seq = seq
+	notification.journaled = true
+}
+
+// trimJournal acknowledges journaled notifications that have been reported.
+// Every journaled notification is queued right after being appended, so
+// those still queued are the only ones left to report.
+func (l *NotificationListener) trimJournal() {
+	if l.journal == nil {
+		return
+	}
+
+	before := l.journal.Next()
+
+	for _, notification := range *l.queue {
+		if notification.journaled && notification.seq < before {
+			before = notification.seq
+		}
+	}
+
+	if err := l.journal.Trim(before); err != nil {
+		log.Err(err).Msg("Failed to trim DHCP notification journal")
+	}
 }
 
 func ipStringAddressFamily(ipStr string) string {
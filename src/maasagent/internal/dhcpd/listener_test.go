@@ -29,6 +29,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"maas.io/core/src/maasagent/internal/journal"
 	testdb "maas.io/core/src/maasagent/internal/testing/db"
 )
 
@@ -789,3 +790,88 @@ func TestSyncWithoutDB(t *testing.T) {
 		})
 	}
 }
+
+func TestSyncWithoutDBJournal(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	j, err := journal.Open(dir)
+	require.NoError(t, err)
+
+	var (
+		sent      []*Notification
+		returnErr error
+	)
+
+	fn := func(ctx context.Context, notifications []*Notification) error {
+		if returnErr != nil {
+			return returnErr
+		}
+
+		sent = append(sent, notifications...)
+
+		return nil
+	}
+
+	nl := NewNotificationListener(nil, fn, WithJournal(j))
+
+	notifications := []*Notification{
+		{
+			Action:    "commit",
+			IP:        "10.0.0.1",
+			MAC:       "00:11:22:33:44:55",
+			IPFamily:  "ipv4",
+			Timestamp: time.Date(2025, 10, 22, 0, 0, 0, 0, time.UTC).Unix(),
+			LeaseTime: 300,
+		},
+		{
+			Action:    "expiry",
+			IP:        "10.0.0.2",
+			MAC:       "00:11:22:33:44:66",
+			IPFamily:  "ipv4",
+			Timestamp: time.Date(2025, 10, 22, 0, 0, 5, 0, time.UTC).Unix(),
+		},
+		{
+			// Too recent to be sent.
+			Action:    "commit",
+			IP:        "10.0.0.3",
+			MAC:       "00:11:22:33:44:77",
+			IPFamily:  "ipv4",
+			Timestamp: time.Now().UTC().Unix() + 60,
+			LeaseTime: 300,
+		},
+	}
+
+	for _, n := range notifications {
+		nl.appendJournal(n)
+		heap.Push(nl.queue, n)
+	}
+
+	assert.Equal(t, uint64(3), j.Pending())
+
+	// Notifications that failed to be sent stay journaled.
+	returnErr = assert.AnError
+	require.Error(t, nl.syncWithoutDB(ctx))
+	assert.Equal(t, uint64(3), j.Pending())
+
+	returnErr = nil
+	require.NoError(t, nl.syncWithoutDB(ctx))
+	assert.Len(t, sent, 2)
+	assert.Equal(t, uint64(1), j.Pending())
+
+	// After a restart, only the notification that wasn't sent is queued.
+	require.NoError(t, j.Close())
+
+	j, err = journal.Open(dir)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { assert.NoError(t, j.Close()) })
+
+	nl = NewNotificationListener(nil, fn, WithJournal(j))
+	require.NoError(t, nl.replayJournal())
+	require.Equal(t, 1, nl.queue.Len())
+
+	replayed, ok := heap.Pop(nl.queue).(*Notification)
+	require.True(t, ok)
+	assert.Equal(t, notifications[2], replayed)
+}
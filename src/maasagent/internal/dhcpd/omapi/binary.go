@@ -131,26 +131,50 @@ func (er *errReader) readBytes(v []byte) {
 	er.err = binary.Read(er.r, binary.BigEndian, v)
 }
 
+// readBytesN reads n bytes. n comes from the message itself, so it is
+// checked against what is left to read before anything is allocated.
+func (er *errReader) readBytesN(n int) []byte {
+	if er.err != nil {
+		return nil
+	}
+
+	if n < 0 {
+		er.err = ErrMalformedMessage
+		return nil
+	}
+
+	if r, ok := er.r.(interface{ Len() int }); ok && n > r.Len() {
+		er.err = io.ErrUnexpectedEOF
+		return nil
+	}
+
+	v := make([]byte, n)
+	er.readBytes(v)
+
+	return v
+}
+
 func (er *errReader) readMap(data map[string][]byte) {
 	var (
 		keylen   int16
 		valuelen int32
 	)
 
-	for {
+	for er.err == nil {
 		er.readInt16(&keylen)
 
-		if keylen == 0 {
-			break
+		if er.err != nil || keylen == 0 {
+			return
 		}
 
-		key := make([]byte, keylen)
-		er.readBytes(key)
+		key := er.readBytesN(int(keylen))
 
 		er.readInt32(&valuelen)
-		value := make([]byte, valuelen)
-		er.readBytes(value)
-		data[string(key)] = value
+		value := er.readBytesN(int(valuelen))
+
+		if er.err == nil {
+			data[string(key)] = value
+		}
 	}
 }
 
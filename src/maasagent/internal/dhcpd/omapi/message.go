@@ -17,10 +17,14 @@ package omapi
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"math/rand"
 )
 
+// ErrMalformedMessage is returned when a message has negative lengths.
+var ErrMalformedMessage = errors.New("malformed OMAPI message")
+
 // Opcode indicates the type of operation being requested or performed
 type Opcode uint32
 
@@ -127,6 +131,14 @@ func (m *Message) UnmarshalBinary(b []byte) error {
 
 	var authlen uint32
 
+	if m.Message == nil {
+		m.Message = make(map[string][]byte)
+	}
+
+	if m.Object == nil {
+		m.Object = make(map[string][]byte)
+	}
+
 	reader.readUint32(&m.AuthID)
 	reader.readUint32(&authlen)
 	reader.readUint32((*uint32)(&m.Operation))
@@ -136,9 +148,7 @@ func (m *Message) UnmarshalBinary(b []byte) error {
 	reader.readMap(m.Message)
 	reader.readMap(m.Object)
 
-	signature := make([]byte, authlen)
-	reader.readBytes(signature)
-	m.Signature = signature
+	m.Signature = reader.readBytesN(int(authlen))
 
 	return reader.err
 }
@@ -76,3 +76,37 @@ func TestMessageUnmarshal(t *testing.T) {
 	assert.Equal(t, []byte("hmac-md5.SIG-ALG.REG.INT."), m.Object["algorithm"])
 	assert.Equal(t, []byte("omapi_key"), m.Object["name"])
 }
+
+func FuzzMessageUnmarshalBinary(f *testing.F) {
+	// Open message sent during authentication setup, see
+	// TestMessageUnmarshal.
+	f.Add([]byte{
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00,
+		0x2b, 0x16, 0xc3, 0xb8, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x04, 0x74, 0x79, 0x70, 0x65, 0x00, 0x00,
+		0x00, 0x0d, 0x61, 0x75, 0x74, 0x68, 0x65, 0x6e,
+		0x74, 0x69, 0x63, 0x61, 0x74, 0x6f, 0x72, 0x00,
+		0x00, 0x00, 0x00,
+	})
+	// Lengths larger than the message.
+	f.Add([]byte{
+		0x00, 0x00, 0x00, 0x00, 0xff, 0xff, 0xff, 0xff,
+		0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x7f, 0xff,
+	})
+
+	f.Fuzz(func(t *testing.T, in []byte) {
+		m := &Message{}
+		if err := m.UnmarshalBinary(in); err != nil {
+			return
+		}
+
+		// Whatever was decoded can be encoded again.
+		m.signed = len(m.Signature) > 0
+
+		_, err := m.MarshalBinary()
+		assert.NoError(t, err)
+	})
+}
@@ -94,3 +94,17 @@ func TestUnmarshal(t *testing.T) {
 		})
 	}
 }
+
+func FuzzARPPacketUnmarshalBinary(f *testing.F) {
+	f.Add([]byte{
+		0x00, 0x01, 0x08, 0x00, 0x06, 0x04, 0x00, 0x01, 0x84, 0x39, 0xc0, 0x0b, 0x22, 0x25,
+		0xc0, 0xa8, 0x0a, 0x1a, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xc0, 0xa8, 0x0a, 0x19,
+	})
+	// Hardware and protocol address lengths not matching the payload.
+	f.Add([]byte{0x00, 0x01, 0x08, 0x00, 0xff, 0xff, 0x00, 0x01})
+
+	f.Fuzz(func(t *testing.T, in []byte) {
+		pkt := &ARPPacket{}
+		_ = pkt.UnmarshalBinary(in) //nolint:errcheck // only panics matter
+	})
+}
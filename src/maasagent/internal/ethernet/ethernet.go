@@ -101,6 +101,10 @@ type EthernetFrame struct {
 func (e *EthernetFrame) ExtractARPPacket() (*ARPPacket, error) {
 	var buf []byte
 	if e.EthernetType == EthernetTypeVLAN {
+		if len(e.Payload) < 4 {
+			return nil, ErrMalformedVLAN
+		}
+
 		buf = e.Payload[4:]
 	} else {
 		buf = e.Payload
@@ -125,7 +129,7 @@ func (e *EthernetFrame) ExtractVLAN() (*VLAN, error) {
 
 	v := &VLAN{}
 
-	err := v.UnmarshalBinary(e.Payload[0:4])
+	err := v.UnmarshalBinary(e.Payload)
 	if err != nil {
 		return nil, err
 	}
@@ -314,3 +314,35 @@ func TestEthernetFrameMarshalBinary(t *testing.T) {
 		})
 	}
 }
+
+func FuzzEthernetFrameUnmarshalBinary(f *testing.F) {
+	f.Add([]byte{
+		0x24, 0x4b, 0xfe, 0xe1, 0xea, 0x26, 0x80, 0x61, 0x5f, 0x08, 0xfc, 0x16, 0x08, 0x06, 0x00, 0x01,
+		0x08, 0x00, 0x06, 0x04, 0x00, 0x02, 0x80, 0x61, 0x5f, 0x08, 0xfc, 0x16, 0xc0, 0xa8, 0x01, 0x6c,
+		0x24, 0x4b, 0xfe, 0xe1, 0xea, 0x26, 0xc0, 0xa8, 0x01, 0x50,
+	})
+	f.Add([]byte{
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x84, 0x39, 0xc0, 0x0b, 0x22, 0x25, 0x81, 0x00, 0x00, 0x02,
+		0x08, 0x06, 0x00, 0x01, 0x08, 0x00, 0x06, 0x04, 0x00, 0x01, 0x84, 0x39, 0xc0, 0x0b, 0x22, 0x25,
+		0xc0, 0xa8, 0x0a, 0x1a, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xc0, 0xa8, 0x0a, 0x19,
+	})
+	f.Add([]byte{})
+
+	f.Fuzz(func(t *testing.T, in []byte) {
+		frame := &EthernetFrame{}
+		if err := frame.UnmarshalBinary(in); err != nil {
+			return
+		}
+
+		// Frames are decoded the same way netmon does: the VLAN tag
+		// first, if any, then the ARP packet. Errors are fine, panics
+		// are not.
+		if frame.EthernetType == EthernetTypeVLAN {
+			if _, err := frame.ExtractVLAN(); err != nil {
+				return
+			}
+		}
+
+		_, _ = frame.ExtractARPPacket() //nolint:errcheck // only panics matter
+	})
+}
@@ -0,0 +1,206 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package events publishes what happens in the agent to local subscribers,
+// such as the installer or charm hooks, so they can wait for the agent to
+// reach a state instead of tailing its logs.
+//
+// Events are served as JSON lines on the agent HTTP socket:
+//
+//	curl --unix-socket /run/maas/agent-http.sock \
+//	    'http://agent/events?type=config.applied'
+//
+// A subscriber that doesn't keep up is disconnected rather than slowing
+// down the agent, and may subscribe again. Events published while nobody
+// is subscribed are not kept.
+package events
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"maas.io/core/src/maasgocommon/clock"
+)
+
+// defaultBuffer is the number of events a subscriber may lag behind.
+const defaultBuffer = 64
+
+// Type identifies what happened.
+type Type string
+
+const (
+	// ServiceStarted is published when the workers of a service are running.
+	ServiceStarted Type = "service.started"
+	// ServiceFailed is published when a service stops on an error.
+	ServiceFailed Type = "service.failed"
+	// ConfigApplied is published when the region finished configuring the
+	// agent, after it (re)started.
+	ConfigApplied Type = "config.applied"
+	// AgentStopping is published when the agent starts shutting down.
+	AgentStopping Type = "agent.stopping"
+	// ModuleStatus is published when a status condition of a module
+	// reconciled with the desired state pushed by the region changes.
+	ModuleStatus Type = "module.status"
+	// StreamLagged ends the stream served by Handler to a subscriber that
+	// lagged too far behind, it is never published.
+	StreamLagged Type = "events.lagged"
+)
+
+// Event is something that happened in the agent.
+type Event struct {
+	Time time.Time `json:"time"`
+	Type Type      `json:"type"`
+	// Service is the name of the service concerned, if any.
+	Service string `json:"service,omitempty"`
+	// Message details the event, e.g. the error of ServiceFailed.
+	Message string `json:"message,omitempty"`
+}
+
+// Filter selects events. Empty fields match everything.
+type Filter struct {
+	Types    []Type
+	Services []string
+}
+
+// Match reports whether e is selected by f.
+func (f Filter) Match(e Event) bool {
+	return (len(f.Types) == 0 || slices.Contains(f.Types, e.Type)) &&
+		(len(f.Services) == 0 || slices.Contains(f.Services, e.Service))
+}
+
+// Bus delivers published events to the subscribers. The zero value is not
+// usable, use NewBus.
+type Bus struct {
+	clock       clock.Clock
+	subscribers map[*Subscription]struct{}
+	buffer      int
+	mu          sync.Mutex
+}
+
+// Option configures a Bus.
+type Option func(*Bus)
+
+// WithBuffer sets the number of events a subscriber may lag behind before
+// being disconnected.
+func WithBuffer(n int) Option {
+	return func(b *Bus) {
+		if n > 0 {
+			b.buffer = n
+		}
+	}
+}
+
+// WithClock sets the clock timestamping events.
+func WithClock(clk clock.Clock) Option {
+	return func(b *Bus) {
+		b.clock = clk
+	}
+}
+
+// NewBus returns a Bus without subscribers.
+func NewBus(opts ...Option) *Bus {
+	b := &Bus{
+		clock:       clock.New(),
+		subscribers: make(map[*Subscription]struct{}),
+		buffer:      defaultBuffer,
+	}
+
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	return b
+}
+
+// Subscription is the stream of events selected by a filter.
+type Subscription struct {
+	bus    *Bus
+	events chan Event
+	filter Filter
+	// lagged is set when events were dropped for the subscriber.
+	lagged bool
+}
+
+// Events returns the events of the subscription. The channel is closed
+// when the subscription is cancelled or lagged too far behind.
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Lagged reports whether the subscription ended because events had to be
+// dropped. It is only meaningful once Events is closed.
+func (s *Subscription) Lagged() bool {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+
+	return s.lagged
+}
+
+// Cancel ends the subscription. It may be called several times.
+func (s *Subscription) Cancel() {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+
+	s.bus.remove(s)
+}
+
+// Subscribe returns a subscription to the events matching f, published
+// from now on.
+func (b *Bus) Subscribe(f Filter) *Subscription {
+	s := &Subscription{
+		bus:    b,
+		events: make(chan Event, b.buffer),
+		filter: f,
+	}
+
+	b.mu.Lock()
+	b.subscribers[s] = struct{}{}
+	b.mu.Unlock()
+
+	return s
+}
+
+// Publish timestamps e, unless it already is, and delivers it to the
+// matching subscribers. It never blocks.
+func (b *Bus) Publish(e Event) {
+	if e.Time.IsZero() {
+		e.Time = b.clock.Now()
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for s := range b.subscribers {
+		if !s.filter.Match(e) {
+			continue
+		}
+
+		select {
+		case s.events <- e:
+		default:
+			s.lagged = true
+			b.remove(s)
+		}
+	}
+}
+
+// remove must be called with b.mu held.
+func (b *Bus) remove(s *Subscription) {
+	if _, ok := b.subscribers[s]; ok {
+		delete(b.subscribers, s)
+		close(s.events)
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package events
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"maas.io/core/src/maasgocommon/clock"
+)
+
+var epoch = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestFilterMatch(t *testing.T) {
+	event := Event{Type: ServiceFailed, Service: "dhcp"}
+
+	testcases := map[string]struct {
+		filter Filter
+		match  bool
+	}{
+		"empty": {
+			match: true,
+		},
+		"type": {
+			filter: Filter{Types: []Type{ServiceStarted, ServiceFailed}},
+			match:  true,
+		},
+		"other type": {
+			filter: Filter{Types: []Type{ConfigApplied}},
+		},
+		"service": {
+			filter: Filter{Services: []string{"dhcp"}},
+			match:  true,
+		},
+		"type and other service": {
+			filter: Filter{Types: []Type{ServiceFailed}, Services: []string{"power"}},
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.match, tc.filter.Match(event))
+		})
+	}
+}
+
+func TestBusPublish(t *testing.T) {
+	bus := NewBus(WithClock(clock.NewFake(epoch)))
+
+	all := bus.Subscribe(Filter{})
+	failures := bus.Subscribe(Filter{Types: []Type{ServiceFailed}})
+
+	bus.Publish(Event{Type: ServiceStarted, Service: "dhcp"})
+	bus.Publish(Event{Type: ServiceFailed, Service: "dhcp", Message: "exited"})
+
+	assert.Equal(t, Event{Time: epoch, Type: ServiceStarted, Service: "dhcp"}, <-all.Events())
+	assert.Equal(t, ServiceFailed, (<-all.Events()).Type)
+	assert.Equal(t, Event{Time: epoch, Type: ServiceFailed, Service: "dhcp", Message: "exited"},
+		<-failures.Events())
+
+	all.Cancel()
+	all.Cancel()
+
+	_, ok := <-all.Events()
+	assert.False(t, ok)
+	assert.False(t, all.Lagged())
+
+	// Cancelled subscriptions no longer receive events.
+	bus.Publish(Event{Type: ServiceFailed})
+	assert.Len(t, failures.Events(), 1)
+}
+
+func TestBusLagging(t *testing.T) {
+	bus := NewBus(WithBuffer(2))
+	sub := bus.Subscribe(Filter{})
+
+	for range 3 {
+		bus.Publish(Event{Type: ConfigApplied})
+	}
+
+	received := 0
+	for range sub.Events() {
+		received++
+	}
+
+	assert.Equal(t, 2, received)
+	assert.True(t, sub.Lagged())
+}
@@ -0,0 +1,98 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package events
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// Handler serves the events of b as JSON lines, one event per line, until
+// the client disconnects. The type and service query parameters filter
+// events, each taking comma-separated values:
+//
+//	GET /events?type=service.started,service.failed&service=dhcp
+//
+// When the subscriber lags too far behind, a last StreamLagged event is
+// sent before the stream ends.
+func Handler(b *Bus) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+
+			return
+		}
+
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+			return
+		}
+
+		query := r.URL.Query()
+
+		var f Filter
+
+		for _, t := range splitList(query.Get("type")) {
+			f.Types = append(f.Types, Type(t))
+		}
+
+		f.Services = splitList(query.Get("service"))
+
+		sub := b.Subscribe(f)
+		defer sub.Cancel()
+
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.WriteHeader(http.StatusOK)
+		// Let the client know it is subscribed before the first event.
+		flusher.Flush()
+
+		enc := json.NewEncoder(w)
+
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case e, ok := <-sub.Events():
+				if !ok {
+					if sub.Lagged() {
+						//nolint:errcheck // the stream ends anyway
+						_ = enc.Encode(Event{Time: b.clock.Now(), Type: StreamLagged})
+					}
+
+					return
+				}
+
+				if err := enc.Encode(e); err != nil {
+					return
+				}
+
+				flusher.Flush()
+			}
+		}
+	})
+}
+
+func splitList(s string) []string {
+	if s == "" {
+		return nil
+	}
+
+	return strings.Split(s, ",")
+}
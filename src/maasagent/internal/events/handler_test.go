@@ -0,0 +1,109 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package events
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"maas.io/core/src/maasgocommon/clock"
+)
+
+// subscribe streams the events of srv at path, returning a function
+// reading the next one.
+func subscribe(t *testing.T, srv *httptest.Server, path string) func() Event {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+path, nil)
+	require.NoError(t, err)
+
+	resp, err := srv.Client().Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/x-ndjson", resp.Header.Get("Content-Type"))
+
+	scanner := bufio.NewScanner(resp.Body)
+
+	return func() Event {
+		t.Helper()
+
+		require.True(t, scanner.Scan(), "stream ended: %v", scanner.Err())
+
+		var e Event
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &e))
+
+		return e
+	}
+}
+
+func TestHandler(t *testing.T) {
+	bus := NewBus(WithClock(clock.NewFake(epoch)))
+	srv := httptest.NewServer(Handler(bus))
+	t.Cleanup(srv.Close)
+
+	next := subscribe(t, srv, "/events?type=service.started,service.failed&service=dhcp")
+
+	bus.Publish(Event{Type: ServiceStarted, Service: "power"})
+	bus.Publish(Event{Type: ConfigApplied})
+	bus.Publish(Event{Type: ServiceStarted, Service: "dhcp"})
+	bus.Publish(Event{Type: ServiceFailed, Service: "dhcp", Message: "exited"})
+
+	assert.Equal(t, Event{Time: epoch, Type: ServiceStarted, Service: "dhcp"}, next())
+	assert.Equal(t, Event{Time: epoch, Type: ServiceFailed, Service: "dhcp", Message: "exited"}, next())
+}
+
+func TestHandlerLagging(t *testing.T) {
+	bus := NewBus(WithBuffer(1), WithClock(clock.NewFake(epoch)))
+	srv := httptest.NewServer(Handler(bus))
+	t.Cleanup(srv.Close)
+
+	next := subscribe(t, srv, "/events")
+
+	// The handler forwards events as they come, publish until it lags.
+	for subscribed := true; subscribed; {
+		bus.Publish(Event{Type: ConfigApplied})
+
+		bus.mu.Lock()
+		subscribed = len(bus.subscribers) > 0
+		bus.mu.Unlock()
+	}
+
+	for {
+		if e := next(); e.Type == StreamLagged {
+			break
+		}
+	}
+}
+
+func TestHandlerMethodNotAllowed(t *testing.T) {
+	rec := httptest.NewRecorder()
+	Handler(NewBus()).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/events", nil))
+
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	assert.Equal(t, http.MethodGet, rec.Header().Get("Allow"))
+}
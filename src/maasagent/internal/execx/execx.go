@@ -0,0 +1,258 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package execx runs external tools (systemctl, maas-power, ipmitool, etc.)
+// behind the Runner interface, so that callers can be tested without
+// executing anything. Commands are bound by a timeout and their captured
+// output by a size limit.
+package execx
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultTimeout bounds commands without a timeout of their own.
+	DefaultTimeout = 5 * time.Minute
+	// DefaultMaxOutput is the number of bytes kept from each of stdout and
+	// stderr.
+	DefaultMaxOutput = 1 << 20
+
+	// waitDelay is how long to wait for the output pipes to close after the
+	// command was killed, e.g. when it left children behind.
+	waitDelay = 5 * time.Second
+)
+
+// Runner runs external commands.
+type Runner interface {
+	// Run runs cmd to completion. A command exiting with a non-zero status
+	// returns a result along with an *ExitError.
+	Run(ctx context.Context, cmd *Cmd) (*Result, error)
+	// LookPath searches for an executable in the directories of PATH.
+	LookPath(file string) (string, error)
+}
+
+// Cmd describes a command to run.
+type Cmd struct {
+	// Stdin is the input of the command, none if nil.
+	Stdin io.Reader
+	Name  string
+	Args  []string
+	// Env is the environment of the command, the one of the current
+	// process if nil.
+	Env []string
+	// Timeout overrides the timeout of the runner when set.
+	Timeout time.Duration
+	// Sandbox restricts the privileges of the command when set.
+	Sandbox *Sandbox
+	// Combined captures stderr along with stdout, in the order they are
+	// written, as exec.Cmd.CombinedOutput does.
+	Combined bool
+}
+
+// Command returns a Cmd running name with args.
+func Command(name string, args ...string) *Cmd {
+	return &Cmd{Name: name, Args: args}
+}
+
+// String returns the command line of c.
+func (c *Cmd) String() string {
+	return strings.Join(append([]string{c.Name}, c.Args...), " ")
+}
+
+// Result holds the outcome of a command.
+type Result struct {
+	Stdout []byte
+	Stderr []byte
+	// ExitCode is the exit status of the command, -1 if it was killed.
+	ExitCode int
+	// Truncated reports whether output beyond the size limit was dropped.
+	Truncated bool
+}
+
+// ExitError is returned when a command exits with a non-zero status, or is
+// killed.
+type ExitError struct {
+	Err    error
+	Cmd    string
+	Stderr string
+}
+
+func (e *ExitError) Error() string {
+	if e.Stderr == "" {
+		return fmt.Sprintf("command %q failed: %v", e.Cmd, e.Err)
+	}
+
+	return fmt.Sprintf("command %q failed: %v: %s", e.Cmd, e.Err, e.Stderr)
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// OSRunner runs commands with os/exec.
+type OSRunner struct {
+	timeout   time.Duration
+	maxOutput int
+}
+
+// Option configures an OSRunner.
+type Option func(*OSRunner)
+
+// WithTimeout sets the timeout of commands not setting their own.
+// DefaultTimeout is used if not set.
+func WithTimeout(timeout time.Duration) Option {
+	return func(r *OSRunner) {
+		r.timeout = timeout
+	}
+}
+
+// WithMaxOutput sets the number of bytes kept from each of stdout and
+// stderr. DefaultMaxOutput is used if not set.
+func WithMaxOutput(n int) Option {
+	return func(r *OSRunner) {
+		r.maxOutput = n
+	}
+}
+
+// New returns a Runner executing commands on the host.
+func New(options ...Option) *OSRunner {
+	r := &OSRunner{
+		timeout:   DefaultTimeout,
+		maxOutput: DefaultMaxOutput,
+	}
+
+	for _, opt := range options {
+		opt(r)
+	}
+
+	return r
+}
+
+// Run implements Runner.
+func (r *OSRunner) Run(ctx context.Context, cmd *Cmd) (*Result, error) {
+	timeout := r.timeout
+	if cmd.Timeout > 0 {
+		timeout = cmd.Timeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	//nolint:gosec // G204 running the given command is the purpose of Run
+	c := exec.CommandContext(ctx, cmd.Name, cmd.Args...)
+	c.Env = cmd.Env
+
+	if cmd.Sandbox != nil {
+		path, args, env, attr, err := cmd.Sandbox.command(cmd)
+		if err != nil {
+			return nil, err
+		}
+
+		c = exec.CommandContext(ctx, path)
+		c.Args = args
+		c.Env = env
+		c.SysProcAttr = attr
+	}
+
+	c.Stdin = cmd.Stdin
+	c.WaitDelay = waitDelay
+
+	stdout := &limitedBuffer{max: r.maxOutput}
+	stderr := stdout
+
+	if !cmd.Combined {
+		stderr = &limitedBuffer{max: r.maxOutput}
+	}
+
+	c.Stdout = stdout
+	c.Stderr = stderr
+
+	err := c.Run()
+
+	res := &Result{
+		Stdout:    stdout.Bytes(),
+		ExitCode:  c.ProcessState.ExitCode(),
+		Truncated: stdout.truncated || stderr.truncated,
+	}
+
+	if !cmd.Combined {
+		res.Stderr = stderr.Bytes()
+	}
+
+	if err == nil {
+		return res, nil
+	}
+
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		err = fmt.Errorf("%w (%w)", err, ctxErr)
+	}
+
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) && !errors.Is(err, context.DeadlineExceeded) &&
+		!errors.Is(err, context.Canceled) {
+		// The command could not be started.
+		return nil, err
+	}
+
+	return res, &ExitError{
+		Cmd:    cmd.String(),
+		Err:    err,
+		Stderr: string(bytes.TrimSpace(res.Stderr)),
+	}
+}
+
+// LookPath implements Runner.
+func (r *OSRunner) LookPath(file string) (string, error) {
+	return exec.LookPath(file)
+}
+
+// limitedBuffer keeps the first max bytes written to it and discards the
+// rest, without failing writes so that the command isn't killed by SIGPIPE.
+type limitedBuffer struct {
+	buf       bytes.Buffer
+	mu        sync.Mutex
+	max       int
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if room := b.max - b.buf.Len(); len(p) > room {
+		b.truncated = true
+		b.buf.Write(p[:max(room, 0)])
+	} else {
+		b.buf.Write(p)
+	}
+
+	return len(p), nil
+}
+
+func (b *limitedBuffer) Bytes() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Bytes()
+}
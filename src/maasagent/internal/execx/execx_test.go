@@ -0,0 +1,117 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package execx
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	testcases := map[string]struct {
+		cmd    *Cmd
+		runner *OSRunner
+		result *Result
+		err    string
+	}{
+		"stdout and stderr": {
+			cmd:    Command("sh", "-c", "echo out; echo err >&2"),
+			runner: New(),
+			result: &Result{Stdout: []byte("out\n"), Stderr: []byte("err\n")},
+		},
+		"combined": {
+			cmd:    &Cmd{Name: "sh", Args: []string{"-c", "echo out; echo err >&2"}, Combined: true},
+			runner: New(),
+			result: &Result{Stdout: []byte("out\nerr\n")},
+		},
+		"stdin": {
+			cmd:    &Cmd{Name: "cat", Stdin: strings.NewReader("data")},
+			runner: New(),
+			result: &Result{Stdout: []byte("data")},
+		},
+		"environment": {
+			cmd:    &Cmd{Name: "sh", Args: []string{"-c", "echo $FOO"}, Env: []string{"FOO=bar"}},
+			runner: New(),
+			result: &Result{Stdout: []byte("bar\n")},
+		},
+		"truncated": {
+			cmd:    Command("sh", "-c", "echo 0123456789"),
+			runner: New(WithMaxOutput(4)),
+			result: &Result{Stdout: []byte("0123"), Stderr: []byte{}, Truncated: true},
+		},
+		"exit status": {
+			cmd:    Command("sh", "-c", "echo failure >&2; exit 3"),
+			runner: New(),
+			result: &Result{Stderr: []byte("failure\n"), ExitCode: 3},
+			err:    `command "sh -c echo failure >&2; exit 3" failed: exit status 3: failure`,
+		},
+		"runner timeout": {
+			cmd:    Command("sleep", "10"),
+			runner: New(WithTimeout(10 * time.Millisecond)),
+			result: &Result{ExitCode: -1},
+			err:    "context deadline exceeded",
+		},
+		"command timeout": {
+			cmd:    &Cmd{Name: "sleep", Args: []string{"10"}, Timeout: 10 * time.Millisecond},
+			runner: New(),
+			result: &Result{ExitCode: -1},
+			err:    "context deadline exceeded",
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			res, err := tc.runner.Run(t.Context(), tc.cmd)
+			if tc.err != "" {
+				var exitErr *ExitError
+
+				require.ErrorAs(t, err, &exitErr)
+				assert.ErrorContains(t, err, tc.err)
+			} else {
+				require.NoError(t, err)
+			}
+
+			assert.Equal(t, tc.result.ExitCode, res.ExitCode)
+			assert.Equal(t, tc.result.Truncated, res.Truncated)
+			assert.Equal(t, string(tc.result.Stdout), string(res.Stdout))
+			assert.Equal(t, string(tc.result.Stderr), string(res.Stderr))
+		})
+	}
+}
+
+func TestRunNotFound(t *testing.T) {
+	res, err := New().Run(t.Context(), Command("maas-no-such-command"))
+	assert.Nil(t, res)
+	assert.ErrorIs(t, err, exec.ErrNotFound)
+
+	var exitErr *ExitError
+	assert.False(t, errors.As(err, &exitErr))
+}
+
+func TestRunCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	_, err := New().Run(ctx, Command("true"))
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,287 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package execx
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/net/bpf"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// sandboxArg0 is the name the process is re-executed under to apply
+	// the sandbox before executing the actual command, see Init.
+	sandboxArg0 = "maas-sandbox-exec"
+	// sandboxSeccompEnv passes the syscalls denied by the seccomp filter to
+	// the re-executed process, as comma separated numbers.
+	sandboxSeccompEnv = "MAAS_SANDBOX_SECCOMP"
+	// sandboxExitCode is returned when the sandbox can't be set up, like
+	// shells do for commands that can't be executed.
+	sandboxExitCode = 126
+
+	defaultPath = "PATH=/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"
+
+	// x32ABI is set on the numbers of syscalls of the x32 ABI of amd64,
+	// which would otherwise bypass the filter.
+	x32ABI = 0x40000000
+)
+
+// Sandbox runs a command with the least privileges, so that a compromised
+// helper (ipmitool, smartctl, etc.) can't read the secrets of the agent or
+// reach its control socket:
+//
+//   - as a dedicated user, without supplementary groups,
+//   - with no_new_privs set, so that setuid binaries like sudo don't grant
+//     privileges back,
+//   - with Cmd.Env and a default PATH as its only environment, rather than
+//     the one of the agent,
+//   - optionally with a seccomp filter denying syscalls.
+//
+// Sandboxing re-executes the current binary, whose main function must call
+// Init first.
+type Sandbox struct {
+	// Seccomp is the seccomp profile of the command, none if nil.
+	Seccomp *SeccompProfile
+	// User is the name of the user the command runs as, the current one if
+	// empty. Switching user requires the agent to run as root.
+	User string
+}
+
+// SeccompProfile lists the syscalls a command is denied. Denied syscalls
+// fail with EPERM.
+type SeccompProfile struct {
+	Deny []uint32
+}
+
+// DefaultSeccompProfile denies the syscalls external helpers never need and
+// could be used to escape the sandbox or tamper with the host: tracing other
+// processes, reading their memory, namespaces, mounts, kernel modules, BPF
+// programs and the kernel keyring.
+var DefaultSeccompProfile = &SeccompProfile{
+	Deny: []uint32{
+		unix.SYS_PTRACE,
+		unix.SYS_PROCESS_VM_READV,
+		unix.SYS_PROCESS_VM_WRITEV,
+		unix.SYS_UNSHARE,
+		unix.SYS_SETNS,
+		unix.SYS_MOUNT,
+		unix.SYS_UMOUNT2,
+		unix.SYS_PIVOT_ROOT,
+		unix.SYS_INIT_MODULE,
+		unix.SYS_FINIT_MODULE,
+		unix.SYS_DELETE_MODULE,
+		unix.SYS_KEXEC_LOAD,
+		unix.SYS_BPF,
+		unix.SYS_PERF_EVENT_OPEN,
+		unix.SYS_KEYCTL,
+		unix.SYS_ADD_KEY,
+		unix.SYS_REQUEST_KEY,
+		unix.SYS_SWAPON,
+		unix.SYS_SWAPOFF,
+		unix.SYS_REBOOT,
+	},
+}
+
+// command returns the path, arguments, environment and attributes of the
+// process running cmd in the sandbox: the current binary, re-executed as
+// sandboxArg0.
+func (s *Sandbox) command(cmd *Cmd) (string, []string, []string, *syscall.SysProcAttr, error) {
+	self, err := os.Executable()
+	if err != nil {
+		return "", nil, nil, nil, fmt.Errorf("failed to find the sandbox executable: %w", err)
+	}
+
+	env := slices.Clone(cmd.Env)
+	if !slices.ContainsFunc(env, func(v string) bool { return strings.HasPrefix(v, "PATH=") }) {
+		env = append(env, defaultPath)
+	}
+
+	if s.Seccomp != nil {
+		deny := make([]string, 0, len(s.Seccomp.Deny))
+		for _, nr := range s.Seccomp.Deny {
+			deny = append(deny, strconv.FormatUint(uint64(nr), 10))
+		}
+
+		env = append(env, sandboxSeccompEnv+"="+strings.Join(deny, ","))
+	}
+
+	attr := &syscall.SysProcAttr{Pdeathsig: syscall.SIGKILL}
+
+	if s.User != "" {
+		u, err := user.Lookup(s.User)
+		if err != nil {
+			return "", nil, nil, nil, fmt.Errorf("failed to look up sandbox user: %w", err)
+		}
+
+		uid, err := strconv.ParseUint(u.Uid, 10, 32)
+		if err != nil {
+			return "", nil, nil, nil, fmt.Errorf("invalid uid of user %q: %w", s.User, err)
+		}
+
+		gid, err := strconv.ParseUint(u.Gid, 10, 32)
+		if err != nil {
+			return "", nil, nil, nil, fmt.Errorf("invalid gid of user %q: %w", s.User, err)
+		}
+
+		attr.Credential = &syscall.Credential{
+			Uid:    uint32(uid),
+			Gid:    uint32(gid),
+			Groups: []uint32{},
+		}
+	}
+
+	args := append([]string{sandboxArg0, cmd.Name}, cmd.Args...)
+
+	return self, args, env, attr, nil
+}
+
+// Init executes the sandboxed command when the process is the sandbox
+// re-executed by a Runner, and never returns in that case. Binaries running
+// sandboxed commands must call it first in main.
+func Init() {
+	if len(os.Args) < 2 || os.Args[0] != sandboxArg0 {
+		return
+	}
+
+	err := sandboxExec(os.Args[1], os.Args[2:])
+	fmt.Fprintf(os.Stderr, "%s: %v\n", sandboxArg0, err)
+	os.Exit(sandboxExitCode)
+}
+
+// sandboxExec restricts the process and executes name. Both no_new_privs
+// and seccomp filters are attributes of the calling thread, kept by exec.
+func sandboxExec(name string, args []string) error {
+	runtime.LockOSThread()
+
+	var deny []uint32
+
+	if value, ok := os.LookupEnv(sandboxSeccompEnv); ok {
+		for _, s := range strings.Split(value, ",") {
+			nr, err := strconv.ParseUint(s, 10, 32)
+			if err != nil {
+				return fmt.Errorf("invalid seccomp profile %q", value)
+			}
+
+			deny = append(deny, uint32(nr))
+		}
+
+		if err := os.Unsetenv(sandboxSeccompEnv); err != nil {
+			return err
+		}
+	}
+
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return err
+	}
+
+	if err := unix.Prctl(unix.PR_SET_NO_NEW_PRIVS, 1, 0, 0, 0); err != nil {
+		return fmt.Errorf("failed to set no_new_privs: %w", err)
+	}
+
+	if deny != nil {
+		filter, err := seccompFilter(deny)
+		if err != nil {
+			return err
+		}
+
+		prog := unix.SockFprog{Len: uint16(len(filter)), Filter: &filter[0]}
+
+		//nolint:gosec // G103 the kernel reads the filter while prctl runs
+		if err := unix.Prctl(unix.PR_SET_SECCOMP, unix.SECCOMP_MODE_FILTER,
+			uintptr(unsafe.Pointer(&prog)), 0, 0); err != nil {
+			return fmt.Errorf("failed to load seccomp filter: %w", err)
+		}
+	}
+
+	//nolint:gosec // G204 executing the sandboxed command is the purpose
+	return unix.Exec(path, append([]string{name}, args...), os.Environ())
+}
+
+// seccompFilter returns a filter making the denied syscalls fail with
+// EPERM, and killing processes making syscalls of another architecture.
+func seccompFilter(deny []uint32) ([]unix.SockFilter, error) {
+	arch, err := auditArch()
+	if err != nil {
+		return nil, err
+	}
+
+	// Jumps are relative and limited to 255 instructions.
+	n := len(deny)
+	if n > 254 {
+		return nil, errors.New("too many denied syscalls")
+	}
+
+	// Offsets of struct seccomp_data.
+	program := []bpf.Instruction{
+		bpf.LoadAbsolute{Off: 4, Size: 4}, // arch
+		bpf.JumpIf{Cond: bpf.JumpEqual, Val: arch, SkipTrue: 1},
+		bpf.RetConstant{Val: unix.SECCOMP_RET_KILL_PROCESS},
+		bpf.LoadAbsolute{Off: 0, Size: 4}, // nr
+		bpf.JumpIf{Cond: bpf.JumpGreaterOrEqual, Val: x32ABI, SkipTrue: uint8(n + 1)},
+	}
+
+	for i, nr := range deny {
+		program = append(program, bpf.JumpIf{Cond: bpf.JumpEqual, Val: nr, SkipTrue: uint8(n - i)})
+	}
+
+	program = append(program,
+		bpf.RetConstant{Val: unix.SECCOMP_RET_ALLOW},
+		bpf.RetConstant{Val: unix.SECCOMP_RET_ERRNO | uint32(unix.EPERM)},
+	)
+
+	raw, err := bpf.Assemble(program)
+	if err != nil {
+		return nil, fmt.Errorf("invalid seccomp filter: %w", err)
+	}
+
+	filter := make([]unix.SockFilter, 0, len(raw))
+	for _, ins := range raw {
+		filter = append(filter, unix.SockFilter{Code: ins.Op, Jt: ins.Jt, Jf: ins.Jf, K: ins.K})
+	}
+
+	return filter, nil
+}
+
+// auditArch returns the architecture of the syscalls of the process, as
+// reported to seccomp filters.
+func auditArch() (uint32, error) {
+	switch runtime.GOARCH {
+	case "amd64":
+		return unix.AUDIT_ARCH_X86_64, nil
+	case "arm64":
+		return unix.AUDIT_ARCH_AARCH64, nil
+	case "ppc64le":
+		return unix.AUDIT_ARCH_PPC64LE, nil
+	case "s390x":
+		return unix.AUDIT_ARCH_S390X, nil
+	case "riscv64":
+		return unix.AUDIT_ARCH_RISCV64, nil
+	}
+
+	return 0, fmt.Errorf("seccomp filters are not supported on %s", runtime.GOARCH)
+}
@@ -0,0 +1,143 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package execx
+
+import (
+	"encoding/binary"
+	"os"
+	"os/user"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/bpf"
+	"golang.org/x/sys/unix"
+)
+
+func TestMain(m *testing.M) {
+	// Sandboxed commands re-execute the test binary.
+	Init()
+
+	os.Exit(m.Run())
+}
+
+func TestSandboxEnvironment(t *testing.T) {
+	t.Setenv("MAAS_SECRET", "secret")
+
+	res, err := New().Run(t.Context(), &Cmd{
+		Name:    "sh",
+		Args:    []string{"-c", `echo "$MAAS_SECRET|$FOO|$PATH"; grep NoNewPrivs /proc/self/status`},
+		Env:     []string{"FOO=bar"},
+		Sandbox: &Sandbox{},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "|bar|"+strings.TrimPrefix(defaultPath, "PATH=")+"\nNoNewPrivs:\t1\n",
+		string(res.Stdout))
+}
+
+func TestSandboxSeccomp(t *testing.T) {
+	if _, err := auditArch(); err != nil {
+		t.Skip(err)
+	}
+
+	res, err := New().Run(t.Context(), &Cmd{
+		Name:    "sh",
+		Args:    []string{"-c", "grep Seccomp: /proc/self/status"},
+		Sandbox: &Sandbox{Seccomp: DefaultSeccompProfile},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Seccomp:\t2\n", string(res.Stdout))
+
+	res, err = New().Run(t.Context(), &Cmd{
+		Name:    "unshare",
+		Args:    []string{"--user", "true"},
+		Sandbox: &Sandbox{Seccomp: DefaultSeccompProfile},
+	})
+	require.Error(t, err)
+	assert.Contains(t, string(res.Stderr), "Operation not permitted")
+}
+
+func TestSandboxUser(t *testing.T) {
+	nobody, err := user.Lookup("nobody")
+	if err != nil {
+		t.Skip(err)
+	}
+
+	_, args, _, attr, err := (&Sandbox{User: "nobody"}).command(Command("id", "-u"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{sandboxArg0, "id", "-u"}, args)
+	require.NotNil(t, attr.Credential)
+	assert.Equal(t, nobody.Uid, strconv.FormatUint(uint64(attr.Credential.Uid), 10))
+	assert.Equal(t, nobody.Gid, strconv.FormatUint(uint64(attr.Credential.Gid), 10))
+	assert.Empty(t, attr.Credential.Groups)
+
+	_, _, _, _, err = (&Sandbox{User: "maas-no-such-user"}).command(Command("id"))
+	assert.ErrorContains(t, err, "failed to look up sandbox user")
+}
+
+func TestSandboxCommandNotFound(t *testing.T) {
+	res, err := New().Run(t.Context(), &Cmd{Name: "maas-no-such-command", Sandbox: &Sandbox{}})
+	require.Error(t, err)
+	assert.Equal(t, sandboxExitCode, res.ExitCode)
+	assert.Contains(t, string(res.Stderr), "executable file not found")
+}
+
+func TestSeccompFilter(t *testing.T) {
+	arch, err := auditArch()
+	if err != nil {
+		t.Skip(err)
+	}
+
+	filter, err := seccompFilter([]uint32{unix.SYS_PTRACE, unix.SYS_MOUNT})
+	require.NoError(t, err)
+
+	raw := make([]bpf.RawInstruction, 0, len(filter))
+	for _, f := range filter {
+		raw = append(raw, bpf.RawInstruction{Op: f.Code, Jt: f.Jt, Jf: f.Jf, K: f.K})
+	}
+
+	program, ok := bpf.Disassemble(raw)
+	require.True(t, ok)
+
+	vm, err := bpf.NewVM(program)
+	require.NoError(t, err)
+
+	testcases := map[string]struct {
+		nr   uint32
+		arch uint32
+		ret  uint32
+	}{
+		"allowed":            {nr: unix.SYS_READ, arch: arch, ret: unix.SECCOMP_RET_ALLOW},
+		"denied":             {nr: unix.SYS_MOUNT, arch: arch, ret: unix.SECCOMP_RET_ERRNO | uint32(unix.EPERM)},
+		"x32 ABI":            {nr: x32ABI | unix.SYS_READ, arch: arch, ret: unix.SECCOMP_RET_ERRNO | uint32(unix.EPERM)},
+		"other architecture": {nr: unix.SYS_READ, arch: arch + 1, ret: unix.SECCOMP_RET_KILL_PROCESS},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			// The VM loads are big endian, unlike those of seccomp.
+			data := make([]byte, 16)
+			binary.BigEndian.PutUint32(data[0:], tc.nr)
+			binary.BigEndian.PutUint32(data[4:], tc.arch)
+
+			ret, err := vm.Run(data)
+			require.NoError(t, err)
+			assert.Equal(t, tc.ret, uint32(ret))
+		})
+	}
+}
@@ -0,0 +1,730 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package journal implements a crash-safe, append-only write-ahead log for
+// reports the agent queues while the region is unreachable.
+//
+// Each record is framed as a little-endian uint32 payload length, a CRC-32C
+// of the payload and the payload itself. Append returns only once the record
+// has been fsync'd, so an acknowledged record survives a crash or power loss.
+// Records are stored in segment files named after the sequence number of
+// their first record, and a new segment is started once the current one
+// reaches the configured size.
+//
+// Open recovers the journal: a torn tail, i.e. a record that was being
+// written when the process died, is truncated from the last segment.
+// Consumers acknowledge processed records with Trim, which persists a
+// checkpoint and removes segments that only hold acknowledged records.
+//
+// With an encryption key, payloads are sealed with AES-256-GCM and bound to
+// their sequence number. The high bit of the length marks sealed records, so
+// records written before encryption was enabled are still replayed.
+package journal
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"maas.io/core/src/maasagent/internal/atomicfile"
+)
+
+const (
+	// headerLen is the size of the length and checksum preceding a payload.
+	headerLen = 8
+	// sealedFlag is set in the length of sealed records.
+	sealedFlag = 1 << 31
+	// sealOverhead is the size of the nonce and tag added by sealing.
+	sealOverhead = 12 + 16
+	keyLen       = 32
+
+	segmentExt           = ".wal"
+	checkpointName       = "checkpoint"
+	defaultSegmentSize   = 4 << 20
+	defaultMaxRecordSize = 1 << 20
+)
+
+var (
+	// ErrCorrupt is returned by Open when a record other than the tail of
+	// the last segment is damaged, or segments are missing.
+	ErrCorrupt = errors.New("journal is corrupt")
+	// ErrClosed is returned when using a closed journal.
+	ErrClosed = errors.New("journal is closed")
+	// ErrInvalidRecord is returned by Append for empty or oversized records.
+	ErrInvalidRecord = errors.New("invalid journal record")
+	// ErrInvalidKey is returned by Open for a key that is not 32 bytes long,
+	// and by Replay when a sealed record can't be opened with the key.
+	ErrInvalidKey = errors.New("invalid journal encryption key")
+	// ErrEncrypted is returned by Replay for sealed records when the
+	// journal was opened without a key.
+	ErrEncrypted = errors.New("journal record is encrypted")
+
+	// errTorn is returned by scanSegment when a record is incomplete or
+	// fails its checksum.
+	errTorn = errors.New("torn record")
+
+	castagnoli = crc32.MakeTable(crc32.Castagnoli)
+)
+
+type segment struct {
+	path string
+	// base is the sequence number of the first record in the segment.
+	base uint64
+	// count is the number of records in the segment.
+	count uint64
+}
+
+// Journal is an append-only log of records identified by sequence numbers.
+// It is safe for concurrent use.
+type Journal struct {
+	f    *os.File
+	err  error
+	aead cipher.AEAD
+	dir  string
+	key  []byte
+	segs []segment
+	// size is the size of the segment being appended to.
+	size int64
+	// first is the sequence number of the first unacknowledged record.
+	first uint64
+	// next is the sequence number assigned to the next appended record.
+	next          uint64
+	segmentSize   int64
+	maxRecordSize int
+	// write writes a framed record to the segment. Torture tests replace it
+	// to split records, so that the process is killed in the middle of one.
+	write  func(f *os.File, rec []byte) error
+	mu     sync.Mutex
+	closed bool
+}
+
+// Option configures a Journal.
+type Option func(*Journal)
+
+// WithSegmentSize sets the size after which a new segment is started.
+// (default: 4MiB)
+func WithSegmentSize(n int64) Option {
+	return func(j *Journal) { j.segmentSize = n }
+}
+
+// WithMaxRecordSize sets the maximum payload size of a record. Larger
+// lengths found during recovery are treated as a torn record.
+// (default: 1MiB)
+func WithMaxRecordSize(n int) Option {
+	return func(j *Journal) { j.maxRecordSize = n }
+}
+
+// WithEncryptionKey seals the payloads of appended records with the given
+// 32 bytes key, which is also needed to replay them.
+func WithEncryptionKey(key []byte) Option {
+	return func(j *Journal) { j.key = key }
+}
+
+// Open opens the journal stored in dir, creating it if necessary, and
+// recovers it after an unclean shutdown.
+func Open(dir string, options ...Option) (*Journal, error) {
+	j := &Journal{
+		dir:           dir,
+		segmentSize:   defaultSegmentSize,
+		maxRecordSize: defaultMaxRecordSize,
+		write:         writeRecord,
+	}
+
+	for _, opt := range options {
+		opt(j)
+	}
+
+	if j.key != nil {
+		if len(j.key) != keyLen {
+			return nil, fmt.Errorf("%w: %d bytes", ErrInvalidKey, len(j.key))
+		}
+
+		block, err := aes.NewCipher(j.key)
+		if err != nil {
+			return nil, err
+		}
+
+		if j.aead, err = cipher.NewGCM(block); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("create journal: %w", err)
+	}
+
+	if err := j.recover(); err != nil {
+		return nil, err
+	}
+
+	last := j.segs[len(j.segs)-1]
+
+	//nolint:gosec // path is built from the journal directory
+	f, err := os.OpenFile(last.path, os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("open journal segment: %w", err)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, errors.Join(fmt.Errorf("open journal segment: %w", err), f.Close())
+	}
+
+	j.f = f
+	j.size = info.Size()
+
+	return j, nil
+}
+
+func (j *Journal) recover() error {
+	first, err := j.readCheckpoint()
+	if err != nil {
+		return err
+	}
+
+	segs, err := j.listSegments()
+	if err != nil {
+		return err
+	}
+
+	if len(segs) == 0 {
+		seg, err := j.createSegment(first)
+		if err != nil {
+			return err
+		}
+
+		j.segs = []segment{seg}
+		j.first, j.next = first, first
+
+		return nil
+	}
+
+	for i := range segs {
+		isLast := i == len(segs)-1
+
+		count, valid, err := scanSegment(segs[i].path, j.maxRecordSize, nil)
+
+		switch {
+		case errors.Is(err, errTorn) && isLast:
+			if err := truncate(segs[i].path, valid); err != nil {
+				return err
+			}
+		case errors.Is(err, errTorn):
+			return fmt.Errorf("%w: %s: damaged record at offset %d",
+				ErrCorrupt, segs[i].path, valid)
+		case err != nil:
+			return err
+		}
+
+		segs[i].count = count
+
+		if !isLast && segs[i].base+count != segs[i+1].base {
+			return fmt.Errorf("%w: %s: expected next segment to start at %d, got %d",
+				ErrCorrupt, segs[i].path, segs[i].base+count, segs[i+1].base)
+		}
+	}
+
+	last := segs[len(segs)-1]
+	next := last.base + last.count
+
+	if first > next {
+		return fmt.Errorf("%w: checkpoint %d is past the last record %d",
+			ErrCorrupt, first, next)
+	}
+
+	j.segs, j.first, j.next = segs, first, next
+
+	// A crash during Trim may leave acknowledged segments behind.
+	return j.removeAcknowledged()
+}
+
+func (j *Journal) readCheckpoint() (uint64, error) {
+	data, err := os.ReadFile(filepath.Join(j.dir, checkpointName))
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+
+	if err != nil {
+		return 0, fmt.Errorf("read journal checkpoint: %w", err)
+	}
+
+	// The checkpoint is replaced atomically, so it is either whole or absent.
+	if len(data) != 8 {
+		return 0, fmt.Errorf("%w: checkpoint is %d bytes long", ErrCorrupt, len(data))
+	}
+
+	return binary.LittleEndian.Uint64(data), nil
+}
+
+func (j *Journal) listSegments() ([]segment, error) {
+	entries, err := os.ReadDir(j.dir)
+	if err != nil {
+		return nil, fmt.Errorf("read journal: %w", err)
+	}
+
+	var segs []segment
+
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), segmentExt)
+		if !ok || !entry.Type().IsRegular() {
+			continue
+		}
+
+		base, err := strconv.ParseUint(name, 10, 64)
+		if err != nil {
+			continue
+		}
+
+		segs = append(segs, segment{path: filepath.Join(j.dir, entry.Name()), base: base})
+	}
+
+	slices.SortFunc(segs, func(a, b segment) int {
+		switch {
+		case a.base < b.base:
+			return -1
+		case a.base > b.base:
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	return segs, nil
+}
+
+func (j *Journal) segmentPath(base uint64) string {
+	return filepath.Join(j.dir, fmt.Sprintf("%020d%s", base, segmentExt))
+}
+
+func (j *Journal) createSegment(base uint64) (segment, error) {
+	path := j.segmentPath(base)
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600) //nolint:gosec // path is trusted
+	if err != nil {
+		return segment{}, fmt.Errorf("create journal segment: %w", err)
+	}
+
+	if err := f.Close(); err != nil {
+		return segment{}, fmt.Errorf("create journal segment: %w", err)
+	}
+
+	if err := syncDir(j.dir); err != nil {
+		return segment{}, err
+	}
+
+	return segment{path: path, base: base}, nil
+}
+
+// Append writes data as a new record and returns its sequence number once
+// the record is on stable storage.
+func (j *Journal) Append(data []byte) (uint64, error) {
+	if len(data) == 0 || len(data) > j.maxRecordSize {
+		return 0, fmt.Errorf("%w: %d bytes", ErrInvalidRecord, len(data))
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.closed {
+		return 0, ErrClosed
+	}
+
+	if j.err != nil {
+		return 0, j.err
+	}
+
+	payloadLen := len(data)
+	if j.aead != nil {
+		payloadLen += sealOverhead
+	}
+
+	recLen := int64(headerLen + payloadLen)
+
+	if j.size > 0 && j.size+recLen > j.segmentSize {
+		if err := j.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	rec := make([]byte, headerLen, recLen)
+	length := uint32(payloadLen) //nolint:gosec // bounded by maxRecordSize
+
+	if j.aead != nil {
+		var err error
+
+		length |= sealedFlag
+
+		if rec, err = j.seal(rec, j.next, data); err != nil {
+			return 0, fmt.Errorf("seal journal record: %w", err)
+		}
+	} else {
+		rec = append(rec, data...)
+	}
+
+	binary.LittleEndian.PutUint32(rec[0:4], length)
+	binary.LittleEndian.PutUint32(rec[4:8], crc32.Checksum(rec[headerLen:], castagnoli))
+
+	if err := j.write(j.f, rec); err != nil {
+		// Drop whatever made it to the file, so the next record is not
+		// appended after a partial one.
+		if tErr := j.f.Truncate(j.size); tErr != nil {
+			j.err = fmt.Errorf("journal segment is unusable: %w", tErr)
+		}
+
+		return 0, fmt.Errorf("append journal record: %w", err)
+	}
+
+	if err := j.f.Sync(); err != nil {
+		// After a failed fsync the state of the page cache is unknown.
+		j.err = fmt.Errorf("journal segment is unusable: %w", err)
+
+		return 0, fmt.Errorf("sync journal record: %w", err)
+	}
+
+	seq := j.next
+
+	j.next++
+	j.size += recLen
+	j.segs[len(j.segs)-1].count++
+
+	return seq, nil
+}
+
+// seal appends the sealed payload of the record seq to dst.
+func (j *Journal) seal(dst []byte, seq uint64, data []byte) ([]byte, error) {
+	nonce := make([]byte, j.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	dst = append(dst, nonce...)
+
+	return j.aead.Seal(dst, nonce, data, binary.LittleEndian.AppendUint64(nil, seq)), nil
+}
+
+// open returns the data of the record seq, read from payload.
+func (j *Journal) open(seq uint64, payload []byte, sealed bool) ([]byte, error) {
+	if !sealed {
+		return payload, nil
+	}
+
+	if j.aead == nil {
+		return nil, fmt.Errorf("%w: record %d", ErrEncrypted, seq)
+	}
+
+	nonce, ciphertext := payload[:j.aead.NonceSize()], payload[j.aead.NonceSize():]
+
+	data, err := j.aead.Open(ciphertext[:0], nonce, ciphertext,
+		binary.LittleEndian.AppendUint64(nil, seq))
+	if err != nil {
+		return nil, fmt.Errorf("%w: record %d can't be opened", ErrInvalidKey, seq)
+	}
+
+	return data, nil
+}
+
+func writeRecord(f *os.File, rec []byte) error {
+	_, err := f.Write(rec)
+	return err
+}
+
+func (j *Journal) rotate() error {
+	// Records are already synced, the error is only kept to stop further
+	// appends if the next segment can't be created.
+	err := j.f.Close()
+	j.f = nil
+
+	if err != nil {
+		j.err = fmt.Errorf("close journal segment: %w", err)
+		return j.err
+	}
+
+	seg, err := j.createSegment(j.next)
+	if err != nil {
+		j.err = err
+		return err
+	}
+
+	//nolint:gosec // path is built from the journal directory
+	f, err := os.OpenFile(seg.path, os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		j.err = fmt.Errorf("open journal segment: %w", err)
+		return j.err
+	}
+
+	j.f = f
+	j.size = 0
+	j.segs = append(j.segs, seg)
+
+	return nil
+}
+
+// Replay calls fn for every unacknowledged record in order. Replay stops and
+// returns the error if fn returns one. data is only valid until fn returns.
+func (j *Journal) Replay(fn func(seq uint64, data []byte) error) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.closed {
+		return ErrClosed
+	}
+
+	for _, seg := range j.segs {
+		if seg.base+seg.count <= j.first {
+			continue
+		}
+
+		seq := seg.base
+
+		_, _, err := scanSegment(seg.path, j.maxRecordSize, func(payload []byte, sealed bool) error {
+			defer func() { seq++ }()
+
+			if seq < j.first || seq >= seg.base+seg.count {
+				return nil
+			}
+
+			data, err := j.open(seq, payload, sealed)
+			if err != nil {
+				return err
+			}
+
+			return fn(seq, data)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Trim acknowledges every record with a sequence number lower than before.
+// Acknowledged records are not replayed, even after a restart.
+func (j *Journal) Trim(before uint64) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.closed {
+		return ErrClosed
+	}
+
+	if before > j.next {
+		return fmt.Errorf("trim journal: record %d has not been appended", before)
+	}
+
+	if before <= j.first {
+		return nil
+	}
+
+	var buf [8]byte
+
+	binary.LittleEndian.PutUint64(buf[:], before)
+
+	if err := atomicfile.WriteFile(filepath.Join(j.dir, checkpointName),
+		buf[:], 0o600); err != nil {
+		return fmt.Errorf("write journal checkpoint: %w", err)
+	}
+
+	if err := syncDir(j.dir); err != nil {
+		return err
+	}
+
+	j.first = before
+
+	return j.removeAcknowledged()
+}
+
+// removeAcknowledged removes segments that only contain acknowledged records.
+// The segment being appended to is always kept.
+func (j *Journal) removeAcknowledged() error {
+	var removed int
+
+	for _, seg := range j.segs[:len(j.segs)-1] {
+		if seg.base+seg.count > j.first {
+			break
+		}
+
+		if err := os.Remove(seg.path); err != nil {
+			return fmt.Errorf("remove journal segment: %w", err)
+		}
+
+		removed++
+	}
+
+	if removed == 0 {
+		return nil
+	}
+
+	j.segs = slices.Delete(j.segs, 0, removed)
+
+	return syncDir(j.dir)
+}
+
+// Next returns the sequence number the next appended record will get.
+func (j *Journal) Next() uint64 {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.next
+}
+
+// Pending returns the number of unacknowledged records.
+func (j *Journal) Pending() uint64 {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.next - j.first
+}
+
+// Close closes the journal. Records are already on stable storage, so Close
+// does not need to sync.
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.closed {
+		return ErrClosed
+	}
+
+	j.closed = true
+
+	if j.f == nil {
+		return nil
+	}
+
+	err := j.f.Close()
+	j.f = nil
+
+	return err
+}
+
+// scanSegment reads the records of the segment at path, calling fn (if not
+// nil) with each payload and whether it is sealed. It returns the number of valid records and the
+// offset following the last one. errTorn is returned along with them when
+// the segment ends with an incomplete or damaged record.
+func scanSegment(path string, maxRecordSize int, fn func([]byte, bool) error) (uint64, int64, error) {
+	f, err := os.Open(path) //nolint:gosec // path is built from the journal directory
+	if err != nil {
+		return 0, 0, fmt.Errorf("open journal segment: %w", err)
+	}
+
+	defer f.Close() //nolint:errcheck // read only
+
+	var (
+		r      = bufio.NewReader(f)
+		header [headerLen]byte
+		buf    []byte
+		count  uint64
+		offset int64
+	)
+
+	for {
+		if _, err := io.ReadFull(r, header[:]); err != nil {
+			if errors.Is(err, io.EOF) {
+				return count, offset, nil
+			}
+
+			if errors.Is(err, io.ErrUnexpectedEOF) {
+				return count, offset, errTorn
+			}
+
+			return count, offset, fmt.Errorf("read journal segment: %w", err)
+		}
+
+		n := binary.LittleEndian.Uint32(header[0:4])
+		sum := binary.LittleEndian.Uint32(header[4:8])
+
+		sealed := n&sealedFlag != 0
+		n &^= sealedFlag
+
+		maxLen := int64(maxRecordSize)
+		if sealed {
+			maxLen += sealOverhead
+		}
+
+		// Empty records are never written, a zero length is what a
+		// preallocated but unwritten tail looks like. Sealed records hold
+		// at least a byte of data.
+		if n == 0 || int64(n) > maxLen || (sealed && n <= sealOverhead) {
+			return count, offset, errTorn
+		}
+
+		buf = slices.Grow(buf[:0], int(n))[:n]
+
+		if _, err := io.ReadFull(r, buf); err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				return count, offset, errTorn
+			}
+
+			return count, offset, fmt.Errorf("read journal segment: %w", err)
+		}
+
+		if crc32.Checksum(buf, castagnoli) != sum {
+			return count, offset, errTorn
+		}
+
+		if fn != nil {
+			if err := fn(buf, sealed); err != nil {
+				return count, offset, err
+			}
+		}
+
+		count++
+		offset += headerLen + int64(n)
+	}
+}
+
+func truncate(path string, size int64) error {
+	f, err := os.OpenFile(path, os.O_WRONLY, 0o600) //nolint:gosec // path is built from the journal directory
+	if err != nil {
+		return fmt.Errorf("truncate journal segment: %w", err)
+	}
+
+	if err := f.Truncate(size); err != nil {
+		return errors.Join(fmt.Errorf("truncate journal segment: %w", err), f.Close())
+	}
+
+	if err := f.Sync(); err != nil {
+		return errors.Join(fmt.Errorf("truncate journal segment: %w", err), f.Close())
+	}
+
+	return f.Close()
+}
+
+// syncDir makes file creation, removal and renames in dir durable.
+func syncDir(dir string) error {
+	d, err := os.Open(dir) //nolint:gosec // journal directory
+	if err != nil {
+		return fmt.Errorf("sync journal directory: %w", err)
+	}
+
+	if err := d.Sync(); err != nil {
+		return errors.Join(fmt.Errorf("sync journal directory: %w", err), d.Close())
+	}
+
+	return d.Close()
+}
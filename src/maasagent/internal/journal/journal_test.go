@@ -0,0 +1,487 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package journal
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func openJournal(t *testing.T, dir string, options ...Option) *Journal {
+	t.Helper()
+
+	j, err := Open(dir, options...)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = j.Close() }) //nolint:errcheck // may be closed by the test
+
+	return j
+}
+
+type record struct {
+	seq  uint64
+	data string
+}
+
+func replayAll(t *testing.T, j *Journal) []record {
+	t.Helper()
+
+	var records []record
+
+	require.NoError(t, j.Replay(func(seq uint64, data []byte) error {
+		records = append(records, record{seq: seq, data: string(data)})
+		return nil
+	}))
+
+	return records
+}
+
+func appendAll(t *testing.T, j *Journal, data ...string) {
+	t.Helper()
+
+	for _, d := range data {
+		_, err := j.Append([]byte(d))
+		require.NoError(t, err)
+	}
+}
+
+func segmentFiles(t *testing.T, dir string) []string {
+	t.Helper()
+
+	files, err := filepath.Glob(filepath.Join(dir, "*"+segmentExt))
+	require.NoError(t, err)
+
+	return files
+}
+
+func TestAppendReplay(t *testing.T) {
+	dir := t.TempDir()
+	j := openJournal(t, dir)
+
+	for i, data := range []string{"a", "bb", "ccc"} {
+		seq, err := j.Append([]byte(data))
+		require.NoError(t, err)
+		assert.Equal(t, uint64(i), seq)
+	}
+
+	want := []record{{0, "a"}, {1, "bb"}, {2, "ccc"}}
+	assert.Equal(t, want, replayAll(t, j))
+
+	require.NoError(t, j.Close())
+
+	j = openJournal(t, dir)
+	assert.Equal(t, want, replayAll(t, j))
+	assert.Equal(t, uint64(3), j.Next())
+	assert.Equal(t, uint64(3), j.Pending())
+
+	seq, err := j.Append([]byte("dddd"))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), seq)
+}
+
+func TestAppendInvalid(t *testing.T) {
+	j := openJournal(t, t.TempDir(), WithMaxRecordSize(4))
+
+	_, err := j.Append(nil)
+	assert.ErrorIs(t, err, ErrInvalidRecord)
+
+	_, err = j.Append([]byte("12345"))
+	assert.ErrorIs(t, err, ErrInvalidRecord)
+
+	require.NoError(t, j.Close())
+
+	_, err = j.Append([]byte("1"))
+	assert.ErrorIs(t, err, ErrClosed)
+	assert.ErrorIs(t, j.Close(), ErrClosed)
+}
+
+func TestEncryption(t *testing.T) {
+	dir := t.TempDir()
+	key := bytes.Repeat([]byte{1}, 32)
+
+	// Records written before encryption was enabled are still replayed.
+	j := openJournal(t, dir)
+	appendAll(t, j, "plain")
+	require.NoError(t, j.Close())
+
+	j = openJournal(t, dir, WithEncryptionKey(key), WithMaxRecordSize(6))
+	appendAll(t, j, "secret")
+
+	want := []record{{0, "plain"}, {1, "secret"}}
+	assert.Equal(t, want, replayAll(t, j))
+	require.NoError(t, j.Close())
+
+	for _, file := range segmentFiles(t, dir) {
+		data, err := os.ReadFile(file) //nolint:gosec // test file
+		require.NoError(t, err)
+		assert.NotContains(t, string(data), "secret")
+	}
+
+	j = openJournal(t, dir, WithEncryptionKey(key), WithMaxRecordSize(6))
+	assert.Equal(t, want, replayAll(t, j))
+	require.NoError(t, j.Close())
+
+	j = openJournal(t, dir)
+	assert.ErrorIs(t, j.Replay(func(uint64, []byte) error { return nil }), ErrEncrypted)
+	require.NoError(t, j.Close())
+
+	j = openJournal(t, dir, WithEncryptionKey(bytes.Repeat([]byte{2}, 32)))
+	assert.ErrorIs(t, j.Replay(func(uint64, []byte) error { return nil }), ErrInvalidKey)
+}
+
+func TestEncryptionInvalidKey(t *testing.T) {
+	_, err := Open(t.TempDir(), WithEncryptionKey([]byte("short")))
+	assert.ErrorIs(t, err, ErrInvalidKey)
+}
+
+func TestRotation(t *testing.T) {
+	dir := t.TempDir()
+	// Two 8 byte records per segment.
+	j := openJournal(t, dir, WithSegmentSize(2*(headerLen+8)))
+
+	var want []record
+
+	for i := range 7 {
+		data := fmt.Sprintf("record%02d", i)
+		want = append(want, record{uint64(i), data})
+		appendAll(t, j, data)
+	}
+
+	assert.Len(t, segmentFiles(t, dir), 4)
+	assert.Equal(t, want, replayAll(t, j))
+
+	require.NoError(t, j.Close())
+
+	j = openJournal(t, dir, WithSegmentSize(2*(headerLen+8)))
+	assert.Equal(t, want, replayAll(t, j))
+	assert.Equal(t, uint64(7), j.Next())
+}
+
+func TestTrim(t *testing.T) {
+	dir := t.TempDir()
+	j := openJournal(t, dir, WithSegmentSize(2*(headerLen+1)))
+
+	appendAll(t, j, "a", "b", "c", "d", "e")
+	require.Len(t, segmentFiles(t, dir), 3)
+
+	require.NoError(t, j.Trim(3))
+	assert.Equal(t, []record{{3, "d"}, {4, "e"}}, replayAll(t, j))
+	assert.Equal(t, uint64(2), j.Pending())
+	// The segment holding a, b is gone, the one holding c, d is still needed.
+	assert.Len(t, segmentFiles(t, dir), 2)
+
+	// Trimming is idempotent.
+	require.NoError(t, j.Trim(1))
+	assert.Equal(t, uint64(2), j.Pending())
+
+	assert.Error(t, j.Trim(6))
+
+	require.NoError(t, j.Close())
+
+	j = openJournal(t, dir, WithSegmentSize(2*(headerLen+1)))
+	assert.Equal(t, []record{{3, "d"}, {4, "e"}}, replayAll(t, j))
+
+	// Acknowledging everything keeps the current segment to append to.
+	require.NoError(t, j.Trim(5))
+	assert.Empty(t, replayAll(t, j))
+	assert.Len(t, segmentFiles(t, dir), 1)
+
+	require.NoError(t, j.Close())
+
+	j = openJournal(t, dir, WithSegmentSize(2*(headerLen+1)))
+	assert.Empty(t, replayAll(t, j))
+
+	seq, err := j.Append([]byte("f"))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(5), seq)
+}
+
+func TestRecoverTornTail(t *testing.T) {
+	testcases := map[string]struct {
+		damage func(data []byte) []byte
+		// lost is true if the damage hits the last record.
+		lost bool
+	}{
+		"partial header": {
+			damage: func(data []byte) []byte { return append(data, 3, 0, 0) },
+		},
+		"partial payload": {
+			damage: func(data []byte) []byte { return data[:len(data)-1] },
+			lost:   true,
+		},
+		"checksum mismatch": {
+			damage: func(data []byte) []byte {
+				data[len(data)-1] ^= 0xff
+				return data
+			},
+			lost: true,
+		},
+		"zero filled tail": {
+			damage: func(data []byte) []byte { return append(data, make([]byte, 64)...) },
+		},
+		"oversized length": {
+			damage: func(data []byte) []byte {
+				return append(data, 0xff, 0xff, 0xff, 0x7f, 0, 0, 0, 0)
+			},
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			j := openJournal(t, dir)
+
+			appendAll(t, j, "first", "second", "third")
+			require.NoError(t, j.Close())
+
+			segs := segmentFiles(t, dir)
+			require.Len(t, segs, 1)
+
+			data, err := os.ReadFile(segs[0])
+			require.NoError(t, err)
+
+			intact := len(data)
+			if tc.lost {
+				intact -= headerLen + len("third")
+			}
+
+			require.NoError(t, os.WriteFile(segs[0], tc.damage(data), 0o600))
+
+			j = openJournal(t, dir)
+
+			want := []record{{0, "first"}, {1, "second"}, {2, "third"}}
+			if tc.lost {
+				want = want[:2]
+			}
+
+			records := replayAll(t, j)
+			assert.Equal(t, want, records)
+
+			info, err := os.Stat(segs[0])
+			require.NoError(t, err)
+			assert.Equal(t, int64(intact), info.Size())
+
+			// Appending after recovery continues from the last intact record.
+			seq, err := j.Append([]byte("fourth"))
+			require.NoError(t, err)
+			assert.Equal(t, uint64(len(records)), seq)
+
+			records = replayAll(t, j)
+			assert.Equal(t, record{seq, "fourth"}, records[len(records)-1])
+		})
+	}
+}
+
+func TestRecoverCorruptSegment(t *testing.T) {
+	dir := t.TempDir()
+	j := openJournal(t, dir, WithSegmentSize(2*(headerLen+1)))
+
+	appendAll(t, j, "a", "b", "c")
+	require.NoError(t, j.Close())
+
+	segs := segmentFiles(t, dir)
+	require.Len(t, segs, 2)
+
+	data, err := os.ReadFile(segs[0])
+	require.NoError(t, err)
+
+	data[len(data)-1] ^= 0xff
+	require.NoError(t, os.WriteFile(segs[0], data, 0o600))
+
+	_, err = Open(dir)
+	assert.ErrorIs(t, err, ErrCorrupt)
+}
+
+func TestRecoverMissingSegment(t *testing.T) {
+	dir := t.TempDir()
+	j := openJournal(t, dir, WithSegmentSize(2*(headerLen+1)))
+
+	appendAll(t, j, "a", "b", "c", "d", "e")
+	require.NoError(t, j.Close())
+
+	segs := segmentFiles(t, dir)
+	require.Len(t, segs, 3)
+	require.NoError(t, os.Remove(segs[1]))
+
+	_, err := Open(dir)
+	assert.ErrorIs(t, err, ErrCorrupt)
+}
+
+const (
+	tortureDirEnv     = "TEST_JOURNAL_TORTURE_DIR"
+	tortureSegmentLen = 1 << 10
+)
+
+// tortureRecord returns the payload of the record with the given sequence
+// number, sized so that records of various lengths straddle segment ends.
+func tortureRecord(seq uint64) []byte {
+	prefix := strconv.FormatUint(seq, 10) + ":"
+	return append([]byte(prefix), bytes.Repeat([]byte{byte(seq)}, int(seq%97)+1)...)
+}
+
+// TestTortureChild is run by TestTorture in a separate process, which is
+// killed while it appends records. Each line written to stdout reports an
+// appended ("a <seq>") or trimmed ("t <before>") record.
+func TestTortureChild(t *testing.T) {
+	dir := os.Getenv(tortureDirEnv)
+	if dir == "" {
+		t.Skip("run by TestTorture")
+	}
+
+	j, err := Open(dir, WithSegmentSize(tortureSegmentLen))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "open: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Write records a few bytes at a time, so that the process is likely to
+	// be killed half way through one.
+	j.write = func(f *os.File, rec []byte) error {
+		for chunk := range slices.Chunk(rec, 16) {
+			if _, err := f.Write(chunk); err != nil {
+				return err
+			}
+
+			time.Sleep(50 * time.Microsecond)
+		}
+
+		return nil
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+
+	for {
+		seq, err := j.Append(tortureRecord(j.Next()))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "append: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Fprintf(out, "a %d\n", seq)
+
+		if seq%50 == 49 {
+			// Keep the last few records unacknowledged.
+			before := seq - 10
+			if err := j.Trim(before); err != nil {
+				fmt.Fprintf(os.Stderr, "trim: %v\n", err)
+				os.Exit(1)
+			}
+
+			fmt.Fprintf(out, "t %d\n", before)
+		}
+
+		if err := out.Flush(); err != nil {
+			os.Exit(1)
+		}
+	}
+}
+
+// TestTorture repeatedly kills a process appending to a journal at random
+// points and checks that every record it reported as appended is recovered
+// intact, and that recovery leaves a journal that can be appended to.
+func TestTorture(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping torture test in short mode")
+	}
+
+	dir := t.TempDir()
+
+	var appended, trimmed uint64
+
+	for range 25 {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestTortureChild$") //nolint:gosec // test binary
+		cmd.Env = append(os.Environ(), tortureDirEnv+"="+dir)
+		cmd.Stderr = os.Stderr
+
+		stdout, err := cmd.StdoutPipe()
+		require.NoError(t, err)
+		require.NoError(t, cmd.Start())
+
+		timer := time.AfterFunc(time.Duration(50+rand.IntN(100))*time.Millisecond, func() {
+			_ = cmd.Process.Signal(syscall.SIGKILL) //nolint:errcheck // may have exited
+		})
+
+		scanner := bufio.NewScanner(stdout)
+		for scanner.Scan() {
+			op, value, ok := strings.Cut(scanner.Text(), " ")
+			require.True(t, ok)
+
+			n, err := strconv.ParseUint(value, 10, 64)
+			require.NoError(t, err)
+
+			switch op {
+			case "a":
+				appended = max(appended, n+1)
+			case "t":
+				trimmed = max(trimmed, n)
+			}
+		}
+
+		timer.Stop()
+
+		err = cmd.Wait()
+
+		var exitErr *exec.ExitError
+		require.ErrorAs(t, err, &exitErr, "child must be killed")
+
+		status, ok := exitErr.Sys().(syscall.WaitStatus)
+		require.True(t, ok)
+		require.True(t, status.Signaled(), "child failed: %v", err)
+
+		j, err := Open(dir, WithSegmentSize(tortureSegmentLen))
+		require.NoError(t, err)
+
+		next := j.Next()
+		// The record being written when the process was killed may have
+		// made it to disk before it was reported.
+		require.GreaterOrEqual(t, next, appended)
+		require.LessOrEqual(t, next, appended+1)
+
+		first := next - j.Pending()
+		require.GreaterOrEqual(t, first, trimmed)
+
+		want := first
+
+		require.NoError(t, j.Replay(func(seq uint64, data []byte) error {
+			require.Equal(t, want, seq)
+			require.Equal(t, tortureRecord(seq), data)
+
+			want++
+
+			return nil
+		}))
+		require.Equal(t, next, want)
+		require.NoError(t, j.Close())
+
+		appended = next
+	}
+
+	t.Logf("recovered %d records", appended)
+}
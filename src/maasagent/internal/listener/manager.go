@@ -0,0 +1,332 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package listener binds services to the host addresses they are configured
+// for, and rebinds them as addresses appear and disappear.
+//
+// The addresses the region asks a service to listen on, e.g. per VLAN, may
+// not exist yet when the service is configured, and change whenever netplan
+// configurations are applied. The Manager starts a listener on each wanted
+// address once it exists, and closes it when the address goes away, without
+// restarting the service or the agent.
+package listener
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/netip"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"maas.io/core/src/maasgocommon/clock"
+)
+
+// defaultPollInterval is how often addresses are rescanned, in case a
+// change notification was missed or notifications aren't available.
+const defaultPollInterval = 30 * time.Second
+
+// StartFunc binds addr and serves on it in the background, until the
+// returned Closer is closed. Link-local IPv6 addresses have the zone of
+// their interface.
+type StartFunc func(addr netip.Addr) (io.Closer, error)
+
+type service struct {
+	start StartFunc
+	addrs []netip.Addr
+	// bound holds the listeners by address, without zone.
+	bound map[netip.Addr]io.Closer
+}
+
+// Manager keeps services bound to the wanted addresses that exist on the
+// host.
+type Manager struct {
+	clock    clock.Clock
+	addrs    func() ([]netip.Addr, error)
+	watch    func(ctx context.Context) (<-chan struct{}, error)
+	services map[string]*service
+	interval time.Duration
+	mu       sync.Mutex
+}
+
+// Option configures a Manager.
+type Option func(*Manager)
+
+// WithClock sets the clock driving address polling.
+func WithClock(clk clock.Clock) Option {
+	return func(m *Manager) {
+		m.clock = clk
+	}
+}
+
+// WithPollInterval sets how often addresses are rescanned.
+func WithPollInterval(d time.Duration) Option {
+	return func(m *Manager) {
+		m.interval = d
+	}
+}
+
+// WithAddrSource sets the function listing the addresses of the host.
+func WithAddrSource(addrs func() ([]netip.Addr, error)) Option {
+	return func(m *Manager) {
+		m.addrs = addrs
+	}
+}
+
+// WithWatcher sets the function returning a channel notified on address
+// changes.
+func WithWatcher(watch func(ctx context.Context) (<-chan struct{}, error)) Option {
+	return func(m *Manager) {
+		m.watch = watch
+	}
+}
+
+// NewManager returns a Manager watching the addresses of the host.
+func NewManager(options ...Option) *Manager {
+	m := &Manager{
+		clock:    clock.New(),
+		addrs:    hostAddrs,
+		watch:    watchAddrs,
+		services: make(map[string]*service),
+		interval: defaultPollInterval,
+	}
+
+	for _, opt := range options {
+		opt(m)
+	}
+
+	return m
+}
+
+// Bind starts listeners of the service name on addrs that exist on the
+// host, and on the others once they appear. It replaces the addresses of a
+// previous Bind of name, closing the listeners no longer wanted. Addresses
+// that fail to bind are retried on the next address change.
+func (m *Manager) Bind(name string, start StartFunc, addrs []netip.Addr) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, ok := m.services[name]
+	if !ok {
+		s = &service{bound: make(map[netip.Addr]io.Closer)}
+		m.services[name] = s
+	}
+
+	s.start = start
+	s.addrs = make([]netip.Addr, 0, len(addrs))
+
+	for _, addr := range addrs {
+		addr = addr.Unmap().WithZone("")
+		if !slices.Contains(s.addrs, addr) {
+			s.addrs = append(s.addrs, addr)
+		}
+	}
+
+	host, err := m.hostAddrs()
+	if err != nil {
+		return err
+	}
+
+	return m.sync(name, s, host)
+}
+
+// Unbind closes the listeners of the service name and forgets it.
+func (m *Manager) Unbind(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, ok := m.services[name]
+	if !ok {
+		return nil
+	}
+
+	delete(m.services, name)
+
+	s.addrs = nil
+
+	return m.sync(name, s, nil)
+}
+
+// Bound returns the addresses the service name is listening on, sorted.
+func (m *Manager) Bound(name string) []netip.Addr {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, ok := m.services[name]
+	if !ok {
+		return nil
+	}
+
+	addrs := make([]netip.Addr, 0, len(s.bound))
+	for addr := range s.bound {
+		addrs = append(addrs, addr)
+	}
+
+	slices.SortFunc(addrs, netip.Addr.Compare)
+
+	return addrs
+}
+
+// Sync rebinds every service to the addresses currently on the host.
+func (m *Manager) Sync() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	host, err := m.hostAddrs()
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+
+	for name, s := range m.services {
+		errs = append(errs, m.sync(name, s, host))
+	}
+
+	return errors.Join(errs...)
+}
+
+// Run rebinds services whenever host addresses change, until ctx is done.
+func (m *Manager) Run(ctx context.Context) error {
+	changes, err := m.watch(ctx)
+	if err != nil {
+		log.Warn().Err(err).Msg("Cannot watch address changes, polling instead")
+	}
+
+	ticker := m.clock.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case _, ok := <-changes:
+			if !ok {
+				// Receiving from a nil channel blocks, leaving polling.
+				changes = nil
+				continue
+			}
+		case <-ticker.C():
+		}
+
+		if err := m.Sync(); err != nil {
+			log.Warn().Err(err).Msg("Failed to rebind listeners")
+		}
+	}
+}
+
+// hostAddrs returns the addresses of the host by address without zone.
+func (m *Manager) hostAddrs() (map[netip.Addr]netip.Addr, error) {
+	addrs, err := m.addrs()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list host addresses: %w", err)
+	}
+
+	host := make(map[netip.Addr]netip.Addr, len(addrs))
+	for _, addr := range addrs {
+		host[addr.WithZone("")] = addr
+	}
+
+	return host, nil
+}
+
+// sync closes the listeners of s on addresses no longer wanted or gone, and
+// starts the missing ones. m.mu must be held.
+func (m *Manager) sync(name string, s *service, host map[netip.Addr]netip.Addr) error {
+	var errs []error
+
+	for addr, l := range s.bound {
+		if _, ok := host[addr]; ok && slices.Contains(s.addrs, addr) {
+			continue
+		}
+
+		delete(s.bound, addr)
+
+		if err := l.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: failed to close listener on %s: %w", name, addr, err))
+		}
+
+		log.Info().Str("service", name).Stringer("addr", addr).Msg("Listener closed")
+	}
+
+	for _, addr := range s.addrs {
+		if _, ok := s.bound[addr]; ok {
+			continue
+		}
+
+		zoned, ok := host[addr]
+		if !ok {
+			continue
+		}
+
+		l, err := s.start(zoned)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: failed to listen on %s: %w", name, zoned, err))
+			continue
+		}
+
+		s.bound[addr] = l
+
+		log.Info().Str("service", name).Stringer("addr", zoned).Msg("Listener started")
+	}
+
+	return errors.Join(errs...)
+}
+
+// hostAddrs returns the addresses of the interfaces that are up.
+func hostAddrs() ([]netip.Addr, error) {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return nil, err
+	}
+
+	var addrs []netip.Addr
+
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagUp == 0 {
+			continue
+		}
+
+		ifaceAddrs, err := iface.Addrs()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, a := range ifaceAddrs {
+			ipNet, ok := a.(*net.IPNet)
+			if !ok {
+				continue
+			}
+
+			addr, ok := netip.AddrFromSlice(ipNet.IP)
+			if !ok {
+				continue
+			}
+
+			addr = addr.Unmap()
+			if addr.Is6() && addr.IsLinkLocalUnicast() {
+				addr = addr.WithZone(iface.Name)
+			}
+
+			addrs = append(addrs, addr)
+		}
+	}
+
+	return addrs, nil
+}
@@ -0,0 +1,207 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package listener
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/netip"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maas.io/core/src/maasgocommon/clock"
+)
+
+// fakeHost holds the addresses of a fake host and the listeners started on
+// them.
+type fakeHost struct {
+	addrs  []netip.Addr
+	open   map[netip.Addr]bool
+	failOn netip.Addr
+	mu     sync.Mutex
+}
+
+func newFakeHost(addrs ...string) *fakeHost {
+	h := &fakeHost{open: map[netip.Addr]bool{}}
+	h.set(addrs...)
+
+	return h
+}
+
+func (h *fakeHost) set(addrs ...string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.addrs = nil
+	for _, a := range addrs {
+		h.addrs = append(h.addrs, netip.MustParseAddr(a))
+	}
+}
+
+func (h *fakeHost) list() ([]netip.Addr, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.addrs, nil
+}
+
+type closerFunc func() error
+
+func (f closerFunc) Close() error { return f() }
+
+func (h *fakeHost) start(addr netip.Addr) (io.Closer, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if addr == h.failOn {
+		return nil, errors.New("address in use")
+	}
+
+	h.open[addr] = true
+
+	return closerFunc(func() error {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+
+		delete(h.open, addr)
+
+		return nil
+	}), nil
+}
+
+func (h *fakeHost) opened() []netip.Addr {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var addrs []netip.Addr
+	for addr := range h.open {
+		addrs = append(addrs, addr)
+	}
+
+	return addrs
+}
+
+func addrs(values ...string) []netip.Addr {
+	var result []netip.Addr
+	for _, v := range values {
+		result = append(result, netip.MustParseAddr(v))
+	}
+
+	return result
+}
+
+func TestBind(t *testing.T) {
+	host := newFakeHost("10.0.0.1", "fe80::1%eth0")
+	m := NewManager(WithAddrSource(host.list))
+
+	// 10.0.1.1 doesn't exist yet.
+	err := m.Bind("dns", host.start, addrs("10.0.0.1", "10.0.1.1", "fe80::1", "::ffff:10.0.0.1"))
+	require.NoError(t, err)
+
+	assert.Equal(t, addrs("10.0.0.1", "fe80::1"), m.Bound("dns"))
+	assert.ElementsMatch(t, addrs("10.0.0.1", "fe80::1%eth0"), host.opened(),
+		"link-local addresses are bound with their zone")
+
+	host.set("10.0.1.1", "fe80::1%eth0")
+	require.NoError(t, m.Sync())
+
+	assert.Equal(t, addrs("10.0.1.1", "fe80::1"), m.Bound("dns"))
+	assert.ElementsMatch(t, addrs("10.0.1.1", "fe80::1%eth0"), host.opened())
+
+	// Rebinding replaces the wanted addresses.
+	require.NoError(t, m.Bind("dns", host.start, addrs("10.0.1.1")))
+	assert.Equal(t, addrs("10.0.1.1"), m.Bound("dns"))
+	assert.ElementsMatch(t, addrs("10.0.1.1"), host.opened())
+
+	require.NoError(t, m.Unbind("dns"))
+	assert.Empty(t, m.Bound("dns"))
+	assert.Empty(t, host.opened())
+	assert.NoError(t, m.Unbind("dns"))
+}
+
+func TestBindRetriesFailures(t *testing.T) {
+	host := newFakeHost("10.0.0.1", "10.0.0.2")
+	host.failOn = netip.MustParseAddr("10.0.0.2")
+
+	m := NewManager(WithAddrSource(host.list))
+
+	err := m.Bind("dns", host.start, addrs("10.0.0.1", "10.0.0.2"))
+	assert.ErrorContains(t, err, "dns: failed to listen on 10.0.0.2: address in use")
+	assert.Equal(t, addrs("10.0.0.1"), m.Bound("dns"))
+
+	host.failOn = netip.Addr{}
+
+	require.NoError(t, m.Sync())
+	assert.Equal(t, addrs("10.0.0.1", "10.0.0.2"), m.Bound("dns"))
+}
+
+func TestRun(t *testing.T) {
+	host := newFakeHost()
+	clk := clock.NewFake(time.Now())
+	changes := make(chan struct{})
+
+	m := NewManager(
+		WithAddrSource(host.list),
+		WithClock(clk),
+		WithWatcher(func(context.Context) (<-chan struct{}, error) { return changes, nil }),
+	)
+
+	require.NoError(t, m.Bind("dns", host.start, addrs("10.0.0.1")))
+	assert.Empty(t, m.Bound("dns"))
+
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan error)
+
+	go func() { done <- m.Run(ctx) }()
+
+	host.set("10.0.0.1")
+	changes <- struct{}{}
+
+	assert.Eventually(t, func() bool { return len(m.Bound("dns")) == 1 },
+		time.Second, 10*time.Millisecond)
+
+	// Polling catches changes without notifications.
+	close(changes)
+	host.set()
+
+	require.NoError(t, clk.BlockUntil(ctx, 1))
+	clk.Advance(defaultPollInterval)
+
+	assert.Eventually(t, func() bool { return len(m.Bound("dns")) == 0 },
+		time.Second, 10*time.Millisecond)
+
+	cancel()
+	assert.NoError(t, <-done)
+}
+
+func TestWatchAddrsStops(t *testing.T) {
+	ctx, cancel := context.WithCancel(t.Context())
+
+	changes, err := watchAddrs(ctx)
+	if err != nil {
+		t.Skipf("netlink is not available: %v", err)
+	}
+
+	cancel()
+
+	for range changes {
+		// Drained until the socket is closed.
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package listener
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// watchAddrs returns a channel notified whenever an address is added to or
+// removed from an interface, until ctx is done. Notifications are coalesced.
+func watchAddrs(ctx context.Context) (<-chan struct{}, error) {
+	fd, err := unix.Socket(unix.AF_NETLINK, unix.SOCK_RAW|unix.SOCK_CLOEXEC|unix.SOCK_NONBLOCK,
+		unix.NETLINK_ROUTE)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open netlink socket: %w", err)
+	}
+
+	err = unix.Bind(fd, &unix.SockaddrNetlink{
+		Family: unix.AF_NETLINK,
+		Groups: unix.RTMGRP_IPV4_IFADDR | unix.RTMGRP_IPV6_IFADDR,
+	})
+	if err != nil {
+		return nil, errors.Join(fmt.Errorf("failed to subscribe to address changes: %w", err),
+			unix.Close(fd))
+	}
+
+	// A non-blocking file uses the runtime poller, closing it unblocks Read.
+	f := os.NewFile(uintptr(fd), "netlink")
+	changes := make(chan struct{}, 1)
+
+	go func() {
+		<-ctx.Done()
+		f.Close() //nolint:errcheck // nothing is written
+	}()
+
+	go func() {
+		defer close(changes)
+
+		buf := make([]byte, os.Getpagesize())
+
+		for {
+			// Only the fact that something changed matters, messages aren't
+			// parsed. ENOBUFS means that messages were dropped.
+			if _, err := f.Read(buf); err != nil && !errors.Is(err, unix.ENOBUFS) {
+				return
+			}
+
+			select {
+			case changes <- struct{}{}:
+			default:
+			}
+		}
+	}()
+
+	return changes, nil
+}
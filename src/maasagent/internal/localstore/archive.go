@@ -0,0 +1,326 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package localstore
+
+import (
+	"bufio"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+)
+
+const (
+	archiveFormat     = "maas-agent-state"
+	archiveVersion    = 1
+	archiveKDFRounds  = 600000
+	archiveKeyLength  = 32
+	archiveSaltLength = 16
+)
+
+var (
+	// ErrPassphraseRequired is returned when an archive contains sealed
+	// records, but no passphrase was provided.
+	ErrPassphraseRequired = errors.New("passphrase is required to import sealed records")
+	// ErrInvalidArchive is returned when the archive cannot be imported.
+	ErrInvalidArchive = errors.New("invalid state archive")
+)
+
+// ManifestEntry describes a file which is not part of the archive, but is
+// expected to be copied alongside it (e.g. cached boot images).
+type ManifestEntry struct {
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+}
+
+// ExportOptions controls what is written by Export.
+type ExportOptions struct {
+	// Buckets to export. All buckets are exported when empty.
+	Buckets []string
+	// Passphrase used to seal records of BucketCredentials.
+	// Credentials are omitted from the archive when no passphrase is set.
+	Passphrase []byte
+	// Manifest is stored as is and returned by Import.
+	Manifest []ManifestEntry
+}
+
+// ImportOptions controls how Import merges the archive into the store.
+type ImportOptions struct {
+	// Passphrase used to open sealed records.
+	Passphrase []byte
+	// Replace removes existing entries of every imported bucket first,
+	// otherwise imported entries are merged into existing ones.
+	Replace bool
+}
+
+// ImportResult summarizes what has been imported.
+type ImportResult struct {
+	Entries  int
+	Manifest []ManifestEntry
+}
+
+type archiveHeader struct {
+	Format    string    `json:"format"`
+	Version   int       `json:"version"`
+	Schema    int       `json:"schema"`
+	CreatedAt time.Time `json:"created_at"`
+	Salt      []byte    `json:"salt,omitempty"`
+}
+
+type archiveRecord struct {
+	Bucket   string          `json:"bucket,omitempty"`
+	Key      string          `json:"key,omitempty"`
+	Value    json.RawMessage `json:"value,omitempty"`
+	Sealed   []byte          `json:"sealed,omitempty"`
+	Manifest *ManifestEntry  `json:"manifest,omitempty"`
+}
+
+// Export writes a versioned JSON lines archive of the store content to w and
+// returns the number of exported entries.
+func (s *Store) Export(ctx context.Context, w io.Writer, opts ExportOptions) (int, error) {
+	schema, err := s.Version(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	header := archiveHeader{
+		Format:    archiveFormat,
+		Version:   archiveVersion,
+		Schema:    schema,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	var aead cipher.AEAD
+
+	if len(opts.Passphrase) > 0 {
+		header.Salt = make([]byte, archiveSaltLength)
+		if _, err := rand.Read(header.Salt); err != nil {
+			return 0, err
+		}
+
+		if aead, err = archiveCipher(opts.Passphrase, header.Salt); err != nil {
+			return 0, err
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+
+	if err := enc.Encode(header); err != nil {
+		return 0, err
+	}
+
+	var count int
+
+	err = s.View(ctx, func(tx *Tx) error {
+		rows, err := tx.tx.QueryContext(ctx,
+			"SELECT bucket, key, value FROM bucket_entry ORDER BY bucket, key")
+		if err != nil {
+			return err
+		}
+
+		defer rows.Close() //nolint:errcheck // rows.Err() is checked
+
+		for rows.Next() {
+			var rec archiveRecord
+
+			if err := rows.Scan(&rec.Bucket, &rec.Key, &rec.Value); err != nil {
+				return err
+			}
+
+			if rec.Value, err = tx.decode(rec.Bucket, rec.Key, rec.Value); err != nil {
+				return err
+			}
+
+			if len(opts.Buckets) > 0 && !slices.Contains(opts.Buckets, rec.Bucket) {
+				continue
+			}
+
+			if rec.Bucket == BucketCredentials {
+				if aead == nil {
+					continue
+				}
+
+				if rec.Sealed, err = seal(aead, rec.Bucket, rec.Key, rec.Value); err != nil {
+					return err
+				}
+
+				rec.Value = nil
+			}
+
+			if err := enc.Encode(rec); err != nil {
+				return err
+			}
+
+			count++
+		}
+
+		return rows.Err()
+	})
+	if err != nil {
+		return 0, fmt.Errorf("export: %w", err)
+	}
+
+	for i := range opts.Manifest {
+		if err := enc.Encode(archiveRecord{Manifest: &opts.Manifest[i]}); err != nil {
+			return 0, fmt.Errorf("export: %w", err)
+		}
+	}
+
+	return count, bw.Flush()
+}
+
+// Import reads an archive produced by Export and writes its entries to the
+// store within a single transaction.
+func (s *Store) Import(ctx context.Context, r io.Reader, opts ImportOptions) (*ImportResult, error) {
+	dec := json.NewDecoder(bufio.NewReader(r))
+
+	var header archiveHeader
+
+	if err := dec.Decode(&header); err != nil {
+		return nil, fmt.Errorf("%w: header: %w", ErrInvalidArchive, err)
+	}
+
+	if header.Format != archiveFormat || header.Version != archiveVersion {
+		return nil, fmt.Errorf("%w: unsupported format %q version %d",
+			ErrInvalidArchive, header.Format, header.Version)
+	}
+
+	schema, err := s.Version(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if header.Schema > schema {
+		return nil, fmt.Errorf("%w: archive schema version %d is newer than %d",
+			ErrInvalidArchive, header.Schema, schema)
+	}
+
+	var aead cipher.AEAD
+
+	if len(opts.Passphrase) > 0 && len(header.Salt) > 0 {
+		if aead, err = archiveCipher(opts.Passphrase, header.Salt); err != nil {
+			return nil, err
+		}
+	}
+
+	result := &ImportResult{}
+
+	err = s.Update(ctx, func(tx *Tx) error {
+		cleared := make(map[string]struct{})
+
+		for line := 2; ; line++ {
+			var rec archiveRecord
+
+			err := dec.Decode(&rec)
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+
+			if err != nil {
+				return fmt.Errorf("%w: record %d: %w", ErrInvalidArchive, line, err)
+			}
+
+			if rec.Manifest != nil {
+				result.Manifest = append(result.Manifest, *rec.Manifest)
+				continue
+			}
+
+			if len(rec.Sealed) > 0 {
+				if aead == nil {
+					return ErrPassphraseRequired
+				}
+
+				if rec.Value, err = unseal(aead, rec.Bucket, rec.Key, rec.Sealed); err != nil {
+					return fmt.Errorf("record %d: %w", line, err)
+				}
+			}
+
+			if rec.Bucket == "" || rec.Key == "" || !json.Valid(rec.Value) {
+				return fmt.Errorf("%w: record %d is malformed", ErrInvalidArchive, line)
+			}
+
+			raw := NewBucket[json.RawMessage](rec.Bucket)
+
+			if _, ok := cleared[rec.Bucket]; opts.Replace && !ok {
+				if err := raw.Clear(tx); err != nil {
+					return err
+				}
+
+				cleared[rec.Bucket] = struct{}{}
+			}
+
+			if err := raw.Put(tx, rec.Key, rec.Value); err != nil {
+				return err
+			}
+
+			result.Entries++
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("import: %w", err)
+	}
+
+	return result, nil
+}
+
+func archiveCipher(passphrase, salt []byte) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, string(passphrase), salt,
+		archiveKDFRounds, archiveKeyLength)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts value binding it to the bucket and key it belongs to.
+func seal(aead cipher.AEAD, bucket, key string, value []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(nonce, nonce, value, []byte(bucket+"/"+key)), nil
+}
+
+func unseal(aead cipher.AEAD, bucket, key string, sealed []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, ErrInvalidArchive
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+
+	value, err := aead.Open(nil, nonce, ciphertext, []byte(bucket+"/"+key))
+	if err != nil {
+		return nil, fmt.Errorf("cannot open sealed record (wrong passphrase?): %w", err)
+	}
+
+	return value, nil
+}
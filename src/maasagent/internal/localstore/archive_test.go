@@ -0,0 +1,161 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package localstore
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func populate(t *testing.T, s *Store) {
+	t.Helper()
+
+	require.NoError(t, s.Update(context.Background(), func(tx *Tx) error {
+		if err := NewBucket[string](BucketDiscovery).Put(tx, "00:16:3e:00:00:01", "2026-01-01"); err != nil {
+			return err
+		}
+
+		return NewBucket[string](BucketCredentials).Put(tx, "bmc", "secret")
+	}))
+}
+
+func TestExportImport(t *testing.T) {
+	ctx := context.Background()
+	passphrase := []byte("passphrase")
+	manifest := []ManifestEntry{{Path: "images/ubuntu/noble", Size: 42}}
+
+	src, _ := openStore(t)
+	populate(t, src)
+
+	var buf bytes.Buffer
+
+	n, err := src.Export(ctx, &buf, ExportOptions{Passphrase: passphrase, Manifest: manifest})
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.NotContains(t, buf.String(), "secret")
+
+	dst, _ := openStore(t)
+
+	result, err := dst.Import(ctx, bytes.NewReader(buf.Bytes()),
+		ImportOptions{Passphrase: passphrase})
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Entries)
+	assert.Equal(t, manifest, result.Manifest)
+
+	require.NoError(t, dst.View(ctx, func(tx *Tx) error {
+		v, err := NewBucket[string](BucketCredentials).Get(tx, "bmc")
+		require.NoError(t, err)
+		assert.Equal(t, "secret", v)
+
+		return nil
+	}))
+
+	_, err = dst.Import(ctx, bytes.NewReader(buf.Bytes()), ImportOptions{})
+	assert.ErrorIs(t, err, ErrPassphraseRequired)
+
+	_, err = dst.Import(ctx, bytes.NewReader(buf.Bytes()),
+		ImportOptions{Passphrase: []byte("wrong")})
+	assert.ErrorContains(t, err, "wrong passphrase")
+}
+
+func TestExportWithoutPassphraseOmitsCredentials(t *testing.T) {
+	ctx := context.Background()
+
+	s, _ := openStore(t)
+	populate(t, s)
+
+	var buf bytes.Buffer
+
+	n, err := s.Export(ctx, &buf, ExportOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.NotContains(t, buf.String(), BucketCredentials)
+}
+
+func TestImportReplace(t *testing.T) {
+	ctx := context.Background()
+	discovery := NewBucket[string](BucketDiscovery)
+
+	src, _ := openStore(t)
+	populate(t, src)
+
+	var buf bytes.Buffer
+
+	_, err := src.Export(ctx, &buf, ExportOptions{Buckets: []string{BucketDiscovery}})
+	require.NoError(t, err)
+
+	dst, _ := openStore(t)
+	require.NoError(t, dst.Update(ctx, func(tx *Tx) error {
+		return discovery.Put(tx, "00:16:3e:00:00:02", "2025-01-01")
+	}))
+
+	_, err = dst.Import(ctx, bytes.NewReader(buf.Bytes()), ImportOptions{Replace: true})
+	require.NoError(t, err)
+
+	require.NoError(t, dst.View(ctx, func(tx *Tx) error {
+		_, err := discovery.Get(tx, "00:16:3e:00:00:02")
+		assert.ErrorIs(t, err, ErrNotFound)
+
+		_, err = discovery.Get(tx, "00:16:3e:00:00:01")
+		assert.NoError(t, err)
+
+		return nil
+	}))
+}
+
+func TestImportInvalidArchive(t *testing.T) {
+	ctx := context.Background()
+	s, _ := openStore(t)
+
+	testcases := map[string]string{
+		"empty":          "",
+		"unknown format": `{"format":"other","version":1}`,
+		"newer schema":   `{"format":"maas-agent-state","version":1,"schema":1000}`,
+		"malformed record": `{"format":"maas-agent-state","version":1,"schema":1}
+{"bucket":"leases"}`,
+	}
+
+	for name, in := range testcases {
+		t.Run(name, func(t *testing.T) {
+			_, err := s.Import(ctx, strings.NewReader(in), ImportOptions{})
+			assert.ErrorIs(t, err, ErrInvalidArchive)
+		})
+	}
+}
+
+func TestManifest(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a":     &fstest.MapFile{Data: []byte("aaa")},
+		"dir/b": &fstest.MapFile{Data: []byte("b")},
+	}
+
+	manifest, err := BuildManifest(fsys)
+	require.NoError(t, err)
+	assert.Equal(t, []ManifestEntry{{Path: "a", Size: 3}, {Path: "dir/b", Size: 1}}, manifest)
+
+	delete(fsys, "a")
+	fsys["dir/b"] = &fstest.MapFile{Data: []byte("bb")}
+
+	mismatched, err := VerifyManifest(fsys, manifest)
+	require.NoError(t, err)
+	assert.Equal(t, manifest, mismatched)
+}
@@ -0,0 +1,202 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package localstore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Bucket provides typed access to values stored under a bucket name.
+// Values are encoded as JSON.
+type Bucket[T any] struct {
+	name string
+}
+
+// NewBucket returns a typed handle to the bucket with the given name.
+func NewBucket[T any](name string) Bucket[T] {
+	return Bucket[T]{name: name}
+}
+
+// Name returns the bucket name.
+func (b Bucket[T]) Name() string {
+	return b.name
+}
+
+// Get returns the value stored under key or ErrNotFound.
+func (b Bucket[T]) Get(tx *Tx, key string) (T, error) {
+	var (
+		value T
+		data  []byte
+	)
+
+	err := tx.tx.QueryRowContext(tx.ctx,
+		"SELECT value FROM bucket_entry WHERE bucket = ? AND key = ?",
+		b.name, key).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return value, ErrNotFound
+	}
+
+	if err != nil {
+		return value, fmt.Errorf("get %s/%s: %w", b.name, key, err)
+	}
+
+	if data, err = tx.decode(b.name, key, data); err != nil {
+		return value, err
+	}
+
+	if err := json.Unmarshal(data, &value); err != nil {
+		return value, fmt.Errorf("decode %s/%s: %w", b.name, key, err)
+	}
+
+	return value, nil
+}
+
+// Put stores value under key, replacing any existing value.
+func (b Bucket[T]) Put(tx *Tx, key string, value T) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("encode %s/%s: %w", b.name, key, err)
+	}
+
+	if data, err = tx.encode(b.name, key, data); err != nil {
+		return err
+	}
+
+	_, err = tx.tx.ExecContext(tx.ctx,
+		`INSERT INTO bucket_entry (bucket, key, value) VALUES (?, ?, ?)
+		ON CONFLICT (bucket, key) DO UPDATE
+		SET value = excluded.value, updated_at = unixepoch()`,
+		b.name, key, data)
+	if err != nil {
+		return fmt.Errorf("put %s/%s: %w", b.name, key, err)
+	}
+
+	return nil
+}
+
+// Delete removes key from the bucket. Deleting a missing key is not an error.
+func (b Bucket[T]) Delete(tx *Tx, key string) error {
+	if _, err := tx.tx.ExecContext(tx.ctx,
+		"DELETE FROM bucket_entry WHERE bucket = ? AND key = ?",
+		b.name, key); err != nil {
+		return fmt.Errorf("delete %s/%s: %w", b.name, key, err)
+	}
+
+	return nil
+}
+
+// Clear removes all keys from the bucket.
+func (b Bucket[T]) Clear(tx *Tx) error {
+	if _, err := tx.tx.ExecContext(tx.ctx,
+		"DELETE FROM bucket_entry WHERE bucket = ?", b.name); err != nil {
+		return fmt.Errorf("clear %s: %w", b.name, err)
+	}
+
+	return nil
+}
+
+// ForEach calls fn for every entry of the bucket in key order.
+// Iteration stops at the first error returned by fn.
+//
+//nolint:nonamedreturns // named return is needed for cleanup
+func (b Bucket[T]) ForEach(tx *Tx, fn func(key string, value T) error) (err error) {
+	rows, err := tx.tx.QueryContext(tx.ctx,
+		"SELECT key, value FROM bucket_entry WHERE bucket = ? ORDER BY key",
+		b.name)
+	if err != nil {
+		return fmt.Errorf("list %s: %w", b.name, err)
+	}
+
+	defer func() {
+		err = errors.Join(err, rows.Close())
+	}()
+
+	for rows.Next() {
+		var (
+			key   string
+			data  []byte
+			value T
+		)
+
+		if err := rows.Scan(&key, &data); err != nil {
+			return fmt.Errorf("list %s: %w", b.name, err)
+		}
+
+		if data, err = tx.decode(b.name, key, data); err != nil {
+			return err
+		}
+
+		if err := json.Unmarshal(data, &value); err != nil {
+			return fmt.Errorf("decode %s/%s: %w", b.name, key, err)
+		}
+
+		if err := fn(key, value); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// CopyBucket copies the entries of the bucket name from src to dst, e.g.
+// from the store of another process, and returns their number. Existing
+// entries of dst are replaced if replace is set, merged otherwise.
+func CopyBucket(ctx context.Context, dst, src *Store, name string, replace bool) (int, error) {
+	raw := NewBucket[json.RawMessage](name)
+
+	type entry struct {
+		key   string
+		value json.RawMessage
+	}
+
+	var entries []entry
+
+	err := src.View(ctx, func(tx *Tx) error {
+		return raw.ForEach(tx, func(key string, value json.RawMessage) error {
+			entries = append(entries, entry{key: key, value: value})
+
+			return nil
+		})
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	err = dst.Update(ctx, func(tx *Tx) error {
+		if replace {
+			if err := raw.Clear(tx); err != nil {
+				return err
+			}
+		}
+
+		for _, e := range entries {
+			if err := raw.Put(tx, e.key, e.value); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return len(entries), nil
+}
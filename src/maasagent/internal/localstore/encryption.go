@@ -0,0 +1,195 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package localstore
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// sealedPrefix starts the values encrypted at rest. JSON documents never
+// start with a NUL byte, which tells them apart from values written before
+// encryption was enabled.
+const (
+	sealedPrefix = 0x00
+	keyLength    = 32
+)
+
+var (
+	// ErrEncrypted is returned when the store holds encrypted values, but
+	// it was opened without a key.
+	ErrEncrypted = errors.New("store is encrypted, a key is required")
+	// ErrInvalidKey is returned for a key that is not 32 bytes long, or
+	// doesn't open the values of the store.
+	ErrInvalidKey = errors.New("invalid store encryption key")
+)
+
+// Option configures a Store.
+type Option func(*storeOptions)
+
+type storeOptions struct {
+	key []byte
+}
+
+// WithEncryptionKey encrypts the values of the store at rest with the given
+// 32 bytes key, using AES-256-GCM. Values are bound to their bucket and key.
+//
+// Opening an unencrypted store with a key encrypts its values, and deleted
+// content is overwritten from then on.
+func WithEncryptionKey(key []byte) Option {
+	return func(o *storeOptions) { o.key = key }
+}
+
+func newCipher(key []byte) (cipher.AEAD, error) {
+	if len(key) != keyLength {
+		return nil, fmt.Errorf("%w: %d bytes", ErrInvalidKey, len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// encode returns the value stored for data under bucket and key.
+func (tx *Tx) encode(bucket, key string, data []byte) ([]byte, error) {
+	if tx.aead == nil {
+		return data, nil
+	}
+
+	sealed, err := seal(tx.aead, bucket, key, data)
+	if err != nil {
+		return nil, fmt.Errorf("encrypt %s/%s: %w", bucket, key, err)
+	}
+
+	return append([]byte{sealedPrefix}, sealed...), nil
+}
+
+// decode returns the data of a value stored under bucket and key.
+func (tx *Tx) decode(bucket, key string, value []byte) ([]byte, error) {
+	if len(value) == 0 || value[0] != sealedPrefix {
+		return value, nil
+	}
+
+	if tx.aead == nil {
+		return nil, ErrEncrypted
+	}
+
+	value = value[1:]
+	if len(value) < tx.aead.NonceSize() {
+		return nil, fmt.Errorf("decrypt %s/%s: value is truncated", bucket, key)
+	}
+
+	nonce, ciphertext := value[:tx.aead.NonceSize()], value[tx.aead.NonceSize():]
+
+	data, err := tx.aead.Open(nil, nonce, ciphertext, []byte(bucket+"/"+key))
+	if err != nil {
+		return nil, fmt.Errorf("%w: can't decrypt %s/%s", ErrInvalidKey, bucket, key)
+	}
+
+	return data, nil
+}
+
+// checkEncryption makes sure the values of the store can be read with the
+// key it was opened with, and encrypts the values stored in clear if there
+// is a key.
+func (s *Store) checkEncryption(ctx context.Context) error {
+	var sealed int
+
+	err := s.Update(ctx, func(tx *Tx) error {
+		var (
+			bucket, key string
+			value       []byte
+		)
+
+		err := tx.tx.QueryRowContext(ctx,
+			`SELECT bucket, key, value FROM bucket_entry
+			WHERE substr(value, 1, 1) = x'00' LIMIT 1`).Scan(&bucket, &key, &value)
+		if err == nil {
+			// Fail early with a store encrypted with another key, or none.
+			if _, err := tx.decode(bucket, key, value); err != nil {
+				return err
+			}
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("check encryption: %w", err)
+		}
+
+		if tx.aead == nil {
+			return nil
+		}
+
+		sealed, err = tx.sealPlaintext(ctx)
+
+		return err
+	})
+	if err != nil || sealed == 0 {
+		return err
+	}
+
+	// Drop the values in clear left in free pages and the write-ahead log.
+	return s.Compact(ctx)
+}
+
+func (tx *Tx) sealPlaintext(ctx context.Context) (int, error) {
+	rows, err := tx.tx.QueryContext(ctx,
+		`SELECT bucket, key, value FROM bucket_entry
+		WHERE substr(value, 1, 1) != x'00'`)
+	if err != nil {
+		return 0, fmt.Errorf("encrypt store: %w", err)
+	}
+
+	type entry struct {
+		bucket, key string
+		value       []byte
+	}
+
+	var entries []entry
+
+	for rows.Next() {
+		var e entry
+
+		if err := rows.Scan(&e.bucket, &e.key, &e.value); err != nil {
+			return 0, errors.Join(fmt.Errorf("encrypt store: %w", err), rows.Close())
+		}
+
+		entries = append(entries, e)
+	}
+
+	if err := errors.Join(rows.Err(), rows.Close()); err != nil {
+		return 0, fmt.Errorf("encrypt store: %w", err)
+	}
+
+	for _, e := range entries {
+		value, err := tx.encode(e.bucket, e.key, e.value)
+		if err != nil {
+			return 0, err
+		}
+
+		if _, err := tx.tx.ExecContext(ctx,
+			"UPDATE bucket_entry SET value = ? WHERE bucket = ? AND key = ?",
+			value, e.bucket, e.key); err != nil {
+			return 0, fmt.Errorf("encrypt %s/%s: %w", e.bucket, e.key, err)
+		}
+	}
+
+	return len(entries), nil
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package localstore
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryption(t *testing.T) {
+	ctx := context.Background()
+	key := bytes.Repeat([]byte{1}, 32)
+	path := filepath.Join(t.TempDir(), "agent.db")
+
+	s, err := Open(ctx, path)
+	require.NoError(t, err)
+	populate(t, s)
+	require.NoError(t, s.Close())
+
+	// Values stored in clear are encrypted when a key is set.
+	s, err = Open(ctx, path, WithEncryptionKey(key))
+	require.NoError(t, err)
+
+	require.NoError(t, s.Update(ctx, func(tx *Tx) error {
+		return NewBucket[string]("events").Put(tx, "0001", "power-on")
+	}))
+
+	require.NoError(t, s.View(ctx, func(tx *Tx) error {
+		v, err := NewBucket[string](BucketCredentials).Get(tx, "bmc")
+		require.NoError(t, err)
+		assert.Equal(t, "secret", v)
+
+		var events []string
+
+		require.NoError(t, NewBucket[string]("events").ForEach(tx,
+			func(_ string, v string) error {
+				events = append(events, v)
+				return nil
+			}))
+		assert.Equal(t, []string{"power-on"}, events)
+
+		return nil
+	}))
+
+	var buf bytes.Buffer
+
+	_, err = s.Export(ctx, &buf, ExportOptions{})
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "2026-01-01")
+
+	require.NoError(t, s.Close())
+
+	data, err := os.ReadFile(path) //nolint:gosec // test file
+	require.NoError(t, err)
+
+	for _, clear := range []string{"secret", "2026-01-01", "power-on"} {
+		assert.NotContains(t, string(data), clear)
+	}
+
+	_, err = Open(ctx, path)
+	assert.ErrorIs(t, err, ErrEncrypted)
+
+	_, err = Open(ctx, path, WithEncryptionKey(bytes.Repeat([]byte{2}, 32)))
+	assert.ErrorIs(t, err, ErrInvalidKey)
+
+	_, err = Open(ctx, path, WithEncryptionKey([]byte("short")))
+	assert.ErrorIs(t, err, ErrInvalidKey)
+}
@@ -0,0 +1,71 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package localstore
+
+import (
+	"errors"
+	"io/fs"
+)
+
+// BuildManifest lists all regular files of fsys with their sizes.
+func BuildManifest(fsys fs.FS) ([]ManifestEntry, error) {
+	var entries []ManifestEntry
+
+	err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		entries = append(entries, ManifestEntry{Path: path, Size: info.Size()})
+
+		return nil
+	})
+
+	return entries, err
+}
+
+// VerifyManifest returns entries of the manifest that are missing in fsys or
+// whose size does not match.
+func VerifyManifest(fsys fs.FS, manifest []ManifestEntry) ([]ManifestEntry, error) {
+	var mismatched []ManifestEntry
+
+	for _, entry := range manifest {
+		info, err := fs.Stat(fsys, entry.Path)
+		if errors.Is(err, fs.ErrNotExist) {
+			mismatched = append(mismatched, entry)
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if info.Size() != entry.Size {
+			mismatched = append(mismatched, entry)
+		}
+	}
+
+	return mismatched, nil
+}
@@ -0,0 +1,217 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package localstore provides a small transactional key-value store used by
+// agent modules to persist local state (discovery history, credentials)
+// across restarts.
+//
+// Values are grouped in named buckets and stored in a SQLite database.
+// The schema is versioned and upgraded automatically when the store is opened.
+// Values can be encrypted at rest, see WithEncryptionKey.
+package localstore
+
+import (
+	"context"
+	"crypto/cipher"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// Well-known buckets used by agent modules.
+const (
+	BucketDiscovery     = "discovery"
+	BucketCredentials   = "credentials"
+	sqliteDriverName    = "sqlite3"
+	sqliteConnectParams = "?_journal_mode=WAL&_busy_timeout=5000&_foreign_keys=on&_txlock=immediate"
+	// sqliteSecureDelete overwrites deleted content of encrypted stores.
+	sqliteSecureDelete = "&_secure_delete=on"
+)
+
+// ErrNotFound is returned when a key does not exist in a bucket.
+var ErrNotFound = errors.New("key not found")
+
+// migrations contains schema changes applied in order. The index of each
+// statement + 1 is the schema version stored in PRAGMA user_version.
+// Never modify existing entries, always append new ones.
+var migrations = []string{
+	`CREATE TABLE bucket_entry (
+		bucket     TEXT    NOT NULL,
+		key        TEXT    NOT NULL,
+		value      BLOB    NOT NULL,
+		updated_at INTEGER NOT NULL DEFAULT (unixepoch()),
+		PRIMARY KEY (bucket, key)
+	) WITHOUT ROWID`,
+}
+
+// Store is a transactional local state store.
+type Store struct {
+	db   *sql.DB
+	aead cipher.AEAD
+}
+
+// Open opens (creating if necessary) the store at the given path and brings
+// its schema up to date.
+func Open(ctx context.Context, path string, options ...Option) (*Store, error) {
+	var opts storeOptions
+
+	for _, opt := range options {
+		opt(&opts)
+	}
+
+	params := sqliteConnectParams
+
+	var aead cipher.AEAD
+
+	if opts.key != nil {
+		var err error
+
+		if aead, err = newCipher(opts.key); err != nil {
+			return nil, err
+		}
+
+		params += sqliteSecureDelete
+	}
+
+	// Credentials are kept in the store, make sure it is not world readable.
+	f, err := os.OpenFile(path, os.O_RDONLY|os.O_CREATE, 0o600) //nolint:gosec // path is trusted
+	if err != nil {
+		return nil, fmt.Errorf("create store: %w", err)
+	}
+
+	if err := f.Close(); err != nil {
+		return nil, fmt.Errorf("create store: %w", err)
+	}
+
+	db, err := sql.Open(sqliteDriverName, "file:"+path+params)
+	if err != nil {
+		return nil, fmt.Errorf("open store: %w", err)
+	}
+
+	// SQLite allows only a single writer.
+	db.SetMaxOpenConns(1)
+
+	s := &Store{db: db, aead: aead}
+
+	if err := s.migrate(ctx); err != nil {
+		return nil, errors.Join(err, db.Close())
+	}
+
+	if err := s.checkEncryption(ctx); err != nil {
+		return nil, errors.Join(err, db.Close())
+	}
+
+	return s, nil
+}
+
+// Close closes the underlying database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Version returns the current schema version.
+func (s *Store) Version(ctx context.Context) (int, error) {
+	var version int
+
+	if err := s.db.QueryRowContext(ctx, "PRAGMA user_version").
+		Scan(&version); err != nil {
+		return 0, fmt.Errorf("read schema version: %w", err)
+	}
+
+	return version, nil
+}
+
+// migrate brings the schema up to date. The version is read within the
+// transaction, as other processes may open the store at the same time.
+func (s *Store) migrate(ctx context.Context) error {
+	return s.Update(ctx, func(tx *Tx) error {
+		var version int
+
+		if err := tx.tx.QueryRowContext(ctx, "PRAGMA user_version").
+			Scan(&version); err != nil {
+			return fmt.Errorf("read schema version: %w", err)
+		}
+
+		if version > len(migrations) {
+			return fmt.Errorf("store schema version %d is newer than supported %d",
+				version, len(migrations))
+		}
+
+		for i := version; i < len(migrations); i++ {
+			if _, err := tx.tx.ExecContext(ctx, migrations[i]); err != nil {
+				return fmt.Errorf("apply schema version %d: %w", i+1, err)
+			}
+		}
+
+		// PRAGMA does not support placeholders
+		_, err := tx.tx.ExecContext(ctx,
+			fmt.Sprintf("PRAGMA user_version = %d", len(migrations)))
+
+		return err
+	})
+}
+
+// Compact reclaims space left by deleted entries and truncates the
+// write-ahead log.
+func (s *Store) Compact(ctx context.Context) error {
+	if _, err := s.db.ExecContext(ctx, "VACUUM"); err != nil {
+		return fmt.Errorf("vacuum: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, "PRAGMA wal_checkpoint(TRUNCATE)"); err != nil {
+		return fmt.Errorf("checkpoint: %w", err)
+	}
+
+	return nil
+}
+
+// Tx is a store transaction.
+type Tx struct {
+	ctx  context.Context
+	tx   *sql.Tx
+	aead cipher.AEAD
+}
+
+// Update executes fn within a read-write transaction. The transaction is
+// committed if fn returns nil and rolled back otherwise.
+func (s *Store) Update(ctx context.Context, fn func(*Tx) error) error {
+	return s.transaction(ctx, false, fn)
+}
+
+// View executes fn within a read-only transaction.
+func (s *Store) View(ctx context.Context, fn func(*Tx) error) error {
+	return s.transaction(ctx, true, fn)
+}
+
+func (s *Store) transaction(ctx context.Context, readOnly bool,
+	fn func(*Tx) error) error {
+	sqlTx, err := s.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: readOnly})
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+
+	if err := fn(&Tx{ctx: ctx, tx: sqlTx, aead: s.aead}); err != nil {
+		return errors.Join(err, sqlTx.Rollback())
+	}
+
+	if err := sqlTx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,247 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package localstore
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type lease struct {
+	IP  string `json:"ip"`
+	MAC string `json:"mac"`
+}
+
+func openStore(t *testing.T) (*Store, string) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "agent.db")
+
+	s, err := Open(context.Background(), path)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { assert.NoError(t, s.Close()) })
+
+	return s, path
+}
+
+func TestOpen(t *testing.T) {
+	ctx := context.Background()
+	s, path := openStore(t)
+
+	version, err := s.Version(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, len(migrations), version)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+}
+
+func TestOpenConcurrently(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "agent.db")
+
+	// Like the maas-netmon processes of every interface, at boot.
+	stores := make([]*Store, 4)
+	errs := make([]error, len(stores))
+
+	var wg sync.WaitGroup
+
+	for i := range stores {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			stores[i], errs[i] = Open(ctx, path)
+		}()
+	}
+
+	wg.Wait()
+
+	for i, s := range stores {
+		require.NoError(t, errs[i])
+
+		version, err := s.Version(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, len(migrations), version)
+		assert.NoError(t, s.Close())
+	}
+}
+
+func TestOpenNewerSchema(t *testing.T) {
+	ctx := context.Background()
+	s, path := openStore(t)
+
+	_, err := s.db.ExecContext(ctx, "PRAGMA user_version = 1000")
+	require.NoError(t, err)
+
+	_, err = Open(ctx, path)
+	assert.ErrorContains(t, err, "newer than supported")
+}
+
+func TestBucket(t *testing.T) {
+	ctx := context.Background()
+	s, _ := openStore(t)
+
+	leases := NewBucket[lease]("leases")
+	other := NewBucket[lease](BucketDiscovery)
+
+	require.NoError(t, s.Update(ctx, func(tx *Tx) error {
+		if err := leases.Put(tx, "10.0.0.2", lease{IP: "10.0.0.2", MAC: "00:16:3e:00:00:02"}); err != nil {
+			return err
+		}
+
+		if err := leases.Put(tx, "10.0.0.1", lease{IP: "10.0.0.1", MAC: "00:16:3e:00:00:01"}); err != nil {
+			return err
+		}
+
+		return other.Put(tx, "10.0.0.1", lease{IP: "10.0.0.1"})
+	}))
+
+	require.NoError(t, s.View(ctx, func(tx *Tx) error {
+		l, err := leases.Get(tx, "10.0.0.1")
+		require.NoError(t, err)
+		assert.Equal(t, "00:16:3e:00:00:01", l.MAC)
+
+		_, err = leases.Get(tx, "10.0.0.3")
+		assert.ErrorIs(t, err, ErrNotFound)
+
+		var keys []string
+
+		require.NoError(t, leases.ForEach(tx, func(key string, _ lease) error {
+			keys = append(keys, key)
+			return nil
+		}))
+		assert.Equal(t, []string{"10.0.0.1", "10.0.0.2"}, keys)
+
+		return nil
+	}))
+
+	require.NoError(t, s.Update(ctx, func(tx *Tx) error {
+		if err := leases.Delete(tx, "10.0.0.1"); err != nil {
+			return err
+		}
+
+		return leases.Clear(tx)
+	}))
+
+	require.NoError(t, s.View(ctx, func(tx *Tx) error {
+		_, err := leases.Get(tx, "10.0.0.2")
+		assert.ErrorIs(t, err, ErrNotFound)
+
+		_, err = other.Get(tx, "10.0.0.1")
+		assert.NoError(t, err)
+
+		return nil
+	}))
+}
+
+func TestCopyBucket(t *testing.T) {
+	ctx := context.Background()
+	discovery := NewBucket[string](BucketDiscovery)
+
+	testcases := map[string]struct {
+		replace bool
+		out     map[string]string
+	}{
+		"merge": {
+			out: map[string]string{"eth0/a": "src", "eth0/b": "src", "eth0/c": "dst"},
+		},
+		"replace": {
+			replace: true,
+			out:     map[string]string{"eth0/a": "src", "eth0/b": "src"},
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			src, _ := openStore(t)
+			dst, _ := openStore(t)
+
+			require.NoError(t, src.Update(ctx, func(tx *Tx) error {
+				return errors.Join(
+					discovery.Put(tx, "eth0/a", "src"),
+					discovery.Put(tx, "eth0/b", "src"),
+					NewBucket[string](BucketCredentials).Put(tx, "bmc", "secret"),
+				)
+			}))
+
+			require.NoError(t, dst.Update(ctx, func(tx *Tx) error {
+				return errors.Join(
+					discovery.Put(tx, "eth0/a", "dst"),
+					discovery.Put(tx, "eth0/c", "dst"),
+				)
+			}))
+
+			n, err := CopyBucket(ctx, dst, src, BucketDiscovery, tc.replace)
+			require.NoError(t, err)
+			assert.Equal(t, 2, n)
+
+			got := make(map[string]string)
+
+			require.NoError(t, dst.View(ctx, func(tx *Tx) error {
+				_, err := NewBucket[string](BucketCredentials).Get(tx, "bmc")
+				assert.ErrorIs(t, err, ErrNotFound, "other buckets are not copied")
+
+				return discovery.ForEach(tx, func(key, value string) error {
+					got[key] = value
+					return nil
+				})
+			}))
+			assert.Equal(t, tc.out, got)
+		})
+	}
+}
+
+func TestUpdateRollback(t *testing.T) {
+	ctx := context.Background()
+	s, _ := openStore(t)
+
+	events := NewBucket[string]("events")
+	errAbort := errors.New("abort")
+
+	err := s.Update(ctx, func(tx *Tx) error {
+		if err := events.Put(tx, "0001", "power-on"); err != nil {
+			return err
+		}
+
+		return errAbort
+	})
+	assert.ErrorIs(t, err, errAbort)
+
+	require.NoError(t, s.View(ctx, func(tx *Tx) error {
+		_, err := events.Get(tx, "0001")
+		assert.ErrorIs(t, err, ErrNotFound)
+
+		return nil
+	}))
+}
+
+func TestCompact(t *testing.T) {
+	ctx := context.Background()
+	s, _ := openStore(t)
+
+	assert.NoError(t, s.Compact(ctx))
+}
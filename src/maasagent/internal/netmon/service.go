@@ -19,15 +19,18 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"net"
 	"net/netip"
 	"strconv"
+	"strings"
 	"time"
 
 	pcap "github.com/packetcap/go-pcap"
 	"github.com/rs/zerolog/log"
 
 	"maas.io/core/src/maasagent/internal/ethernet"
+	"maas.io/core/src/maasagent/internal/localstore"
 )
 
 const (
@@ -36,12 +39,21 @@ const (
 	seenAgainThreshold time.Duration = 600 * time.Second
 )
 
+// StoreFile is the store of the observed bindings, shared by the maas-netmon
+// processes of every interface. Bindings are observed on the wire, the store
+// is not encrypted.
+const StoreFile = "discovery.db"
+
 var (
 	// ErrEmptyPacket is returned when a packet of 0 bytes has been received
 	ErrEmptyPacket = errors.New("received an empty packet")
 	// ErrPacketCaptureClosed is returned when the packet capture channel
 	// has been closed unexpectedly
 	ErrPacketCaptureClosed = errors.New("packet capture channel closed")
+
+	// discovery holds the last observed binding of every IP, keyed by
+	// interface, VLAN and IP, e.g. eth0/2_192.168.10.26.
+	discovery = localstore.NewBucket[storedBinding](localstore.BucketDiscovery)
 )
 
 // Binding represents the binding between an IP address and MAC address
@@ -74,34 +86,107 @@ type Result struct {
 	Event Event `json:"event"`
 }
 
+// storedBinding is a Binding as kept in the store.
+type storedBinding struct {
+	VID  *uint16 `json:"vid,omitempty"`
+	IP   string  `json:"ip"`
+	MAC  string  `json:"mac"`
+	Time int64   `json:"time"`
+}
+
 // Service is responsible for starting packet capture and
 // converting observed ARP packets into discovered Results
 type Service struct {
 	bindings map[string]Binding
+	store    *localstore.Store
 	iface    string
 }
 
+// ServiceOption allows to set additional Service options
+type ServiceOption func(*Service)
+
 // NewService returns a pointer to a Service. It
 // takes the desired interface to observe's name as an argument
-func NewService(iface string) *Service {
-	return &Service{
+func NewService(iface string, options ...ServiceOption) *Service {
+	s := &Service{
 		iface:    iface,
 		bindings: make(map[string]Binding),
 	}
-}
 
-func (s *Service) updateBindings(pkt *ethernet.ARPPacket, vid *uint16, timestamp time.Time) []Result {
-	var res []Result
+	for _, opt := range options {
+		opt(s)
+	}
 
-	if timestamp.IsZero() {
-		timestamp = time.Now()
+	return s
+}
+
+// WithStore keeps the observed bindings in store, so that bindings seen
+// before a restart are not reported as new again (default: none)
+func WithStore(store *localstore.Store) ServiceOption {
+	return func(s *Service) {
+		s.store = store
 	}
+}
 
+// bindingKey returns the key of the binding of ip on the VLAN vid.
+func bindingKey(vid *uint16, ip string) string {
 	var vidLabel int
 	if vid != nil {
 		vidLabel = int(*vid)
 	}
 
+	return strconv.Itoa(vidLabel) + "_" + ip
+}
+
+// loadBindings restores the bindings of the interface kept in the store.
+func (s *Service) loadBindings(ctx context.Context) error {
+	prefix := s.iface + "/"
+
+	return s.store.View(ctx, func(tx *localstore.Tx) error {
+		return discovery.ForEach(tx, func(key string, b storedBinding) error {
+			key, ok := strings.CutPrefix(key, prefix)
+			if !ok {
+				return nil
+			}
+
+			ip, err := netip.ParseAddr(b.IP)
+			if err != nil {
+				return fmt.Errorf("binding %s: %w", key, err)
+			}
+
+			mac, err := net.ParseMAC(b.MAC)
+			if err != nil {
+				return fmt.Errorf("binding %s: %w", key, err)
+			}
+
+			s.bindings[key] = Binding{IP: ip, MAC: mac, VID: b.VID, Time: time.Unix(b.Time, 0)}
+
+			return nil
+		})
+	})
+}
+
+// saveBindings keeps the bindings reported by results in the store.
+func (s *Service) saveBindings(ctx context.Context, results []Result) error {
+	return s.store.Update(ctx, func(tx *localstore.Tx) error {
+		for _, r := range results {
+			binding := storedBinding{IP: r.IP, MAC: r.MAC, VID: r.VID, Time: r.Time}
+			if err := discovery.Put(tx, s.iface+"/"+bindingKey(r.VID, r.IP), binding); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (s *Service) updateBindings(pkt *ethernet.ARPPacket, vid *uint16, timestamp time.Time) []Result {
+	var res []Result
+
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+
 	discoveredBindings := []Binding{
 		{
 			IP:   pkt.SendIPAddr,
@@ -120,10 +205,8 @@ func (s *Service) updateBindings(pkt *ethernet.ARPPacket, vid *uint16, timestamp
 		})
 	}
 
-	prefix := strconv.Itoa(vidLabel) + "_"
-
 	for _, discoveredBinding := range discoveredBindings {
-		key := prefix + discoveredBinding.IP.String()
+		key := bindingKey(vid, discoveredBinding.IP.String())
 
 		binding, ok := s.bindings[key]
 		if !ok {
@@ -231,6 +314,21 @@ func (s *Service) handlePacket(pkt pcap.Packet) ([]Result, error) {
 	return s.updateBindings(arpPkt, vid, pkt.Info.Timestamp), nil
 }
 
+// observe returns the results of pkt, keeping the bindings they report in
+// the store. The bindings are still reported if they cannot be stored.
+func (s *Service) observe(ctx context.Context, pkt pcap.Packet) ([]Result, error) {
+	res, err := s.handlePacket(pkt)
+	if err != nil || len(res) == 0 || s.store == nil {
+		return res, err
+	}
+
+	if err := s.saveBindings(ctx, res); err != nil {
+		log.Warn().Err(err).Msg("Failed to store discovered bindings")
+	}
+
+	return res, nil
+}
+
 func isRecoverableError(err error) bool {
 	return errors.Is(
 		err,
@@ -241,6 +339,12 @@ func isRecoverableError(err error) bool {
 func (s *Service) Start(ctx context.Context, resultC chan<- Result) error {
 	defer close(resultC)
 
+	if s.store != nil {
+		if err := s.loadBindings(ctx); err != nil {
+			log.Warn().Err(err).Msg("Failed to restore discovered bindings")
+		}
+	}
+
 	hndlr, err := pcap.OpenLive(s.iface, snapLen, false, timeout, true)
 	if err != nil {
 		return err
@@ -265,7 +369,7 @@ func (s *Service) Start(ctx context.Context, resultC chan<- Result) error {
 				return ErrPacketCaptureClosed
 			}
 
-			res, err := s.handlePacket(pkt)
+			res, err := s.observe(ctx, pkt)
 			if err != nil {
 				if isRecoverableError(err) {
 					log.Error().Err(err).Send()
@@ -16,6 +16,8 @@
 package netmon
 
 import (
+	"bytes"
+	"context"
 	"net"
 	"net/netip"
 	"testing"
@@ -24,8 +26,10 @@ import (
 	"github.com/google/gopacket"
 	pcap "github.com/packetcap/go-pcap"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"maas.io/core/src/maasagent/internal/ethernet"
+	"maas.io/core/src/maasagent/internal/localstore"
 )
 
 func uint16Pointer(v uint16) *uint16 {
@@ -357,3 +361,176 @@ func TestServiceHandlePacket(t *testing.T) {
 		})
 	}
 }
+
+// requestPacket is an ARP request of 84:39:c0:0b:22:25 for 192.168.10.26,
+// on the VLAN 2, generated from tcpdump.
+var requestPacket = []byte{
+	0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x84, 0x39, 0xc0, 0x0b, 0x22, 0x25, 0x81, 0x00, 0x00, 0x02,
+	0x08, 0x06, 0x00, 0x01, 0x08, 0x00, 0x06, 0x04, 0x00, 0x01, 0x84, 0x39, 0xc0, 0x0b, 0x22, 0x25,
+	0xc0, 0xa8, 0x0a, 0x1a, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xc0, 0xa8, 0x0a, 0x19, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+}
+
+func TestServiceStore(t *testing.T) {
+	ctx := context.Background()
+
+	store, err := localstore.Open(ctx, t.TempDir()+"/discovery.db")
+	require.NoError(t, err)
+
+	t.Cleanup(func() { assert.NoError(t, store.Close()) })
+
+	timestamp := time.Unix(1767225600, 0)
+	pkt := func(d time.Duration) pcap.Packet {
+		return pcap.Packet{B: requestPacket, Info: gopacket.CaptureInfo{Timestamp: timestamp.Add(d)}}
+	}
+
+	svc := NewService("eth0", WithStore(store))
+	require.NoError(t, svc.loadBindings(ctx))
+
+	res, err := svc.observe(ctx, pkt(0))
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	assert.Equal(t, EventNew, res[0].Event)
+
+	var stored storedBinding
+
+	require.NoError(t, store.View(ctx, func(tx *localstore.Tx) error {
+		stored, err = discovery.Get(tx, "eth0/2_192.168.10.26")
+		return err
+	}))
+	assert.Equal(t, storedBinding{IP: "192.168.10.26", MAC: "84:39:c0:0b:22:25", VID: uint16Pointer(2),
+		Time: timestamp.Unix()}, stored)
+
+	testcases := map[string]struct {
+		iface string
+		after time.Duration
+		out   []Event
+	}{
+		"seen before the restart": {
+			iface: "eth0",
+			after: time.Minute,
+		},
+		"seen again after the restart": {
+			iface: "eth0",
+			after: seenAgainThreshold,
+			out:   []Event{EventRefreshed},
+		},
+		"seen on another interface": {
+			iface: "eth1",
+			after: time.Minute,
+			out:   []Event{EventNew},
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			restarted := NewService(tc.iface, WithStore(store))
+			require.NoError(t, restarted.loadBindings(ctx))
+
+			res, err := restarted.handlePacket(pkt(tc.after))
+			require.NoError(t, err)
+
+			var events []Event
+			for _, r := range res {
+				events = append(events, r.Event)
+			}
+
+			assert.Equal(t, tc.out, events)
+		})
+	}
+}
+
+// TestServiceStateExport checks that the bindings are kept when the state
+// of the agent is moved to another host, as maas-agent state export and
+// import do.
+func TestServiceStateExport(t *testing.T) {
+	ctx := context.Background()
+
+	open := func(name string) *localstore.Store {
+		store, err := localstore.Open(ctx, t.TempDir()+"/"+name)
+		require.NoError(t, err)
+
+		t.Cleanup(func() { assert.NoError(t, store.Close()) })
+
+		return store
+	}
+
+	timestamp := time.Unix(1767225600, 0)
+
+	src := open(StoreFile)
+
+	svc := NewService("eth0", WithStore(src))
+	require.NoError(t, svc.loadBindings(ctx))
+
+	_, err := svc.observe(ctx, pcap.Packet{B: requestPacket, Info: gopacket.CaptureInfo{Timestamp: timestamp}})
+	require.NoError(t, err)
+
+	// Export from the host being replaced.
+	srcState := open("state.db")
+
+	n, err := localstore.CopyBucket(ctx, srcState, src, localstore.BucketDiscovery, true)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	var archive bytes.Buffer
+
+	_, err = srcState.Export(ctx, &archive, localstore.ExportOptions{})
+	require.NoError(t, err)
+
+	// Import on the replacement host.
+	dstState := open("state.db")
+
+	_, err = dstState.Import(ctx, &archive, localstore.ImportOptions{})
+	require.NoError(t, err)
+
+	dst := open(StoreFile)
+
+	_, err = localstore.CopyBucket(ctx, dst, dstState, localstore.BucketDiscovery, false)
+	require.NoError(t, err)
+
+	restarted := NewService("eth0", WithStore(dst))
+	require.NoError(t, restarted.loadBindings(ctx))
+
+	res, err := restarted.handlePacket(pcap.Packet{B: requestPacket,
+		Info: gopacket.CaptureInfo{Timestamp: timestamp.Add(time.Minute)}})
+	require.NoError(t, err)
+	assert.Empty(t, res, "the binding was discovered on the replaced host")
+}
+
+func FuzzServiceHandlePacket(f *testing.F) {
+	// generated from tcpdump
+	f.Add([]byte{
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x84, 0x39, 0xc0, 0x0b, 0x22, 0x25, 0x81, 0x00, 0x00, 0x02,
+		0x08, 0x06, 0x00, 0x01, 0x08, 0x00, 0x06, 0x04, 0x00, 0x01, 0x84, 0x39, 0xc0, 0x0b, 0x22, 0x25,
+		0xc0, 0xa8, 0x0a, 0x1a, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xc0, 0xa8, 0x0a, 0x19, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	})
+	f.Add([]byte{
+		0x24, 0x4b, 0xfe, 0xe1, 0xea, 0x26, 0x80, 0x61, 0x5f, 0x08, 0xfc, 0x16, 0x08, 0x06, 0x00, 0x01,
+		0x08, 0x00, 0x06, 0x04, 0x00, 0x02, 0x80, 0x61, 0x5f, 0x08, 0xfc, 0x16, 0xc0, 0xa8, 0x01, 0x6c,
+		0x24, 0x4b, 0xfe, 0xe1, 0xea, 0x26, 0xc0, 0xa8, 0x01, 0x50,
+	})
+
+	// The service is shared between inputs, like it is between the
+	// packets of a capture.
+	svc := NewService("eth0")
+	timestamp := time.Now()
+
+	f.Fuzz(func(t *testing.T, in []byte) {
+		timestamp = timestamp.Add(time.Second)
+
+		results, err := svc.handlePacket(pcap.Packet{B: in, Info: gopacket.CaptureInfo{Timestamp: timestamp}})
+		if err != nil {
+			assert.Empty(t, results)
+			return
+		}
+
+		for _, res := range results {
+			_, err := netip.ParseAddr(res.IP)
+			assert.NoError(t, err)
+
+			_, err = net.ParseMAC(res.MAC)
+			assert.NoError(t, err)
+		}
+	})
+}
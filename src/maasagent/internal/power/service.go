@@ -16,13 +16,12 @@
 package power
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
 	"os"
-	"os/exec"
 	"reflect"
 	"strings"
 	"time"
@@ -30,9 +29,11 @@ import (
 	"go.temporal.io/sdk/activity"
 	tworker "go.temporal.io/sdk/worker"
 	tworkflow "go.temporal.io/sdk/workflow"
+	"maas.io/core/src/maasagent/internal/execx"
 	"maas.io/core/src/maasagent/internal/workflow"
 	"maas.io/core/src/maasagent/internal/workflow/log/tag"
 	"maas.io/core/src/maasagent/internal/workflow/worker"
+	"maas.io/core/src/maasgocommon/secrets"
 )
 
 const powerServiceWorkerPoolGroup = "power-service"
@@ -42,37 +43,48 @@ var (
 	// and the machine is found in an incorrect power state
 	ErrWrongPowerState = errors.New("BMC is in the wrong power state")
 
-	// procFactory holds the implementation of a function that will return a
-	// command that can be called with Run(). This is used to inject the actual
-	// `os/exec`-based implementation within `configure()`, or a mocked
-	// implementation for unit testing.
-	procFactory powerProcFactory
-
-	// pathFactory holds the implementation of a function that will return a
-	// command that attempts to resolve the full path of a program binary.
-	// This is used to inject the actual `os/exec`-based implementation within
-	// `configure()`, or a mocked implementation for unit testing.
-	pathFactory osPathFactory
-)
-
-type powerProc interface {
-	Run() error
-}
+	// runner runs the MAAS power CLI, replaced by a recorder in unit tests.
+	runner execx.Runner = execx.New()
 
-type powerProcFactory func(ctx context.Context, stdout, stderr *bytes.Buffer, name string, arg ...string) powerProc
+	// powerSandbox runs the MAAS power CLI, which is given the BMC
+	// credentials, with no_new_privs and without the syscalls it could use
+	// to tamper with the agent or the host.
+	powerSandbox = &execx.Sandbox{Seccomp: execx.DefaultSeccompProfile}
 
-type osPathFactory func(file string) (string, error)
+	// powerEnvPrefixes are the environment variables passed to the MAAS
+	// power CLI, which it needs to run from the snap or the deb package.
+	powerEnvPrefixes = []string{"PATH=", "LANG=", "LC_", "SNAP", "PYTHON"}
+)
 
 // PowerService is a service that knows how to reach BMC to perform power
 // operations. Invocation of this service normally should happen via Temporal.
 type PowerService struct {
-	pool *worker.WorkerPool
+	pool    *worker.WorkerPool
+	secrets secrets.Provider
 }
 
-func NewPowerService(systemID string, pool *worker.WorkerPool) *PowerService {
-	return &PowerService{
+// PowerServiceOption allows to set additional PowerService options
+type PowerServiceOption func(*PowerService)
+
+func NewPowerService(systemID string, pool *worker.WorkerPool,
+	options ...PowerServiceOption) *PowerService {
+	s := &PowerService{
 		pool: pool,
 	}
+
+	for _, opt := range options {
+		opt(s)
+	}
+
+	return s
+}
+
+// WithSecrets sets the provider of the BMC credentials referenced by
+// SecretPath (default: none, only credentials in DriverOpts are used)
+func WithSecrets(provider secrets.Provider) PowerServiceOption {
+	return func(s *PowerService) {
+		s.secrets = provider
+	}
 }
 
 func (s *PowerService) ConfigurationWorkflows() map[string]any {
@@ -120,19 +132,6 @@ func (s *PowerService) configure(ctx tworkflow.Context, systemID string) error {
 		return err
 	}
 
-	procFactory = func(ctx context.Context, stdout, stderr *bytes.Buffer, name string, arg ...string) powerProc {
-		cmd := exec.CommandContext(ctx, name, arg...)
-		cmd.Stdout = stdout
-		cmd.Stderr = stderr
-
-		return cmd
-	}
-
-	//nolint:gocritic // The 'unlambda' check fails if enabled - this interface-based implementation is needed for dependency injection and unit testing
-	pathFactory = func(file string) (string, error) {
-		return exec.LookPath(file)
-	}
-
 	activities := map[string]any{
 		"power-on":       s.PowerOn,
 		"power-off":      s.PowerOff,
@@ -177,7 +176,11 @@ func (s *PowerService) configure(ctx tworkflow.Context, systemID string) error {
 type PowerParam struct {
 	DriverOpts map[string]any `json:"driver_opts"`
 	DriverType string         `json:"driver_type"`
-	IsDPU      bool           `json:"is_dpu"`
+	// SecretPath is the path of the secret holding the BMC credentials
+	// (e.g. bmc/<id>/power-parameters), whose fields are added to
+	// DriverOpts, so that they are not part of the workflow history.
+	SecretPath string `json:"secret_path,omitempty"`
+	IsDPU      bool   `json:"is_dpu"`
 }
 
 // PowerOnParam is the activity parameter for power management of a host
@@ -231,7 +234,12 @@ type PowerResetResult struct {
 }
 
 func (s *PowerService) PowerOn(ctx context.Context, param PowerOnParam) (*PowerOnResult, error) {
-	out, err := powerCommand(ctx, "on", param.IsDPU, param.DriverType, param.DriverOpts)
+	opts, err := s.driverOpts(ctx, param.PowerParam)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := powerCommand(ctx, "on", param.IsDPU, param.DriverType, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -245,7 +253,12 @@ func (s *PowerService) PowerOn(ctx context.Context, param PowerOnParam) (*PowerO
 	return &PowerOnResult{State: out}, nil
 }
 func (s *PowerService) PowerOff(ctx context.Context, param PowerOffParam) (*PowerOffResult, error) {
-	out, err := powerCommand(ctx, "off", param.IsDPU, param.DriverType, param.DriverOpts)
+	opts, err := s.driverOpts(ctx, param.PowerParam)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := powerCommand(ctx, "off", param.IsDPU, param.DriverType, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -259,7 +272,12 @@ func (s *PowerService) PowerOff(ctx context.Context, param PowerOffParam) (*Powe
 	return &PowerOffResult{State: out}, nil
 }
 func (s *PowerService) PowerCycle(ctx context.Context, param PowerCycleParam) (*PowerCycleResult, error) {
-	out, err := powerCommand(ctx, "cycle", param.IsDPU, param.DriverType, param.DriverOpts)
+	opts, err := s.driverOpts(ctx, param.PowerParam)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := powerCommand(ctx, "cycle", param.IsDPU, param.DriverType, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -274,7 +292,12 @@ func (s *PowerService) PowerCycle(ctx context.Context, param PowerCycleParam) (*
 }
 
 func (s *PowerService) PowerQuery(ctx context.Context, param PowerQueryParam) (*PowerQueryResult, error) {
-	out, err := powerCommand(ctx, "status", param.IsDPU, param.DriverType, param.DriverOpts)
+	opts, err := s.driverOpts(ctx, param.PowerParam)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := powerCommand(ctx, "status", param.IsDPU, param.DriverType, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -285,7 +308,12 @@ func (s *PowerService) PowerQuery(ctx context.Context, param PowerQueryParam) (*
 }
 
 func (s *PowerService) PowerReset(ctx context.Context, param PowerResetParam) (*PowerResetResult, error) {
-	out, err := powerCommand(ctx, "reset", param.IsDPU, param.DriverType, param.DriverOpts)
+	opts, err := s.driverOpts(ctx, param.PowerParam)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := powerCommand(ctx, "reset", param.IsDPU, param.DriverType, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -310,15 +338,46 @@ func (s *PowerService) SetBootOrder(ctx context.Context, param SetBootOrderParam
 
 	log.Info("setting boot order of " + param.SystemID)
 
-	_, err := powerCommand(ctx, "set-boot-order", false, param.PowerParams.DriverType, param.PowerParams.DriverOpts)
+	opts, err := s.driverOpts(ctx, param.PowerParams)
+	if err != nil {
+		return err
+	}
+
+	_, err = powerCommand(ctx, "set-boot-order", false, param.PowerParams.DriverType, opts)
 
 	return err
 }
 
+// driverOpts returns the driver options of param, with the fields of the
+// secret at its SecretPath, which take precedence.
+func (s *PowerService) driverOpts(ctx context.Context, param PowerParam) (map[string]any, error) {
+	if param.SecretPath == "" {
+		return param.DriverOpts, nil
+	}
+
+	if s.secrets == nil {
+		return nil, fmt.Errorf("no secret provider to read BMC credentials from %s", param.SecretPath)
+	}
+
+	secret, err := s.secrets.Get(ctx, param.SecretPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read BMC credentials: %w", err)
+	}
+
+	opts := make(map[string]any, len(param.DriverOpts)+len(secret.Fields))
+	maps.Copy(opts, param.DriverOpts)
+
+	for k, v := range secret.Fields {
+		opts[k] = v
+	}
+
+	return opts, nil
+}
+
 func powerCommand(ctx context.Context, action string, isDPU bool, driver string, opts map[string]any, bootOrder ...map[string]any) (string, error) {
 	log := activity.GetLogger(ctx)
 
-	maasPowerCLI, err := pathFactory(powerCLIExecutableName())
+	maasPowerCLI, err := runner.LookPath(powerCLIExecutableName())
 	if err != nil {
 		log.Error("MAAS power CLI executable path lookup failure",
 			tag.Builder().Error(err).KeyVals...)
@@ -359,19 +418,19 @@ func powerCommand(ctx context.Context, action string, isDPU bool, driver string,
 
 	log.Debug("Executing MAAS power CLI", tag.Builder().KV("args", args).KeyVals...)
 
-	var stdout, stderr bytes.Buffer
-
-	cmd := procFactory(ctx, &stdout, &stderr, maasPowerCLI, args...)
+	cmd := execx.Command(maasPowerCLI, args...)
+	cmd.Env = powerEnv()
+	cmd.Sandbox = powerSandbox
 
-	err = cmd.Run()
+	res, err := runner.Run(ctx, cmd)
 	if err != nil {
 		t := tag.Builder().Error(err)
-		if stdout.String() != "" {
-			t = t.KV("stdout", stdout.String())
+		if res != nil && len(res.Stdout) > 0 {
+			t = t.KV("stdout", string(res.Stdout))
 		}
 
-		if stderr.String() != "" {
-			t = t.KV("stderr", stderr.String())
+		if res != nil && len(res.Stderr) > 0 {
+			t = t.KV("stderr", string(res.Stderr))
 		}
 
 		log.Error("Error executing power command", t.KeyVals...)
@@ -379,7 +438,7 @@ func powerCommand(ctx context.Context, action string, isDPU bool, driver string,
 		return "", err
 	}
 
-	return stdout.String(), nil
+	return string(res.Stdout), nil
 }
 
 // powerCLIExecutableName returns correct MAAS Power CLI executable name
@@ -392,6 +451,23 @@ func powerCLIExecutableName() string {
 	return "maas-power"
 }
 
+// powerEnv returns the environment of the MAAS power CLI: the variables of
+// the agent matching powerEnvPrefixes, and none of its secrets.
+func powerEnv() []string {
+	var env []string
+
+	for _, v := range os.Environ() {
+		for _, prefix := range powerEnvPrefixes {
+			if strings.HasPrefix(v, prefix) {
+				env = append(env, v)
+				break
+			}
+		}
+	}
+
+	return env
+}
+
 func fmtPowerOpts(opts map[string]any) []string {
 	var res []string
 
@@ -16,25 +16,20 @@
 package power
 
 import (
-	"bytes"
 	"context"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.temporal.io/sdk/testsuite"
+	"maas.io/core/src/maasagent/internal/execx"
+	"maas.io/core/src/maasagent/internal/testing/exectest"
+	"maas.io/core/src/maasgocommon/secrets"
 )
 
 const expectedMAASCLIName = "maas.power"
 
-type testPowerProc struct {
-	name string
-	arg  []string
-}
-
-func (t testPowerProc) Run() error {
-	return nil
-}
-
 func TestFmtPowerOpts(t *testing.T) {
 	testcases := map[string]struct {
 		in  map[string]any
@@ -82,6 +77,120 @@ func TestFmtPowerOpts(t *testing.T) {
 	}
 }
 
+func TestPowerCommandSandbox(t *testing.T) {
+	t.Setenv("SNAP", "/snap/maas/current")
+	t.Setenv("MAAS_AGENT_SECRET", "secret")
+
+	recorder := exectest.NewRecorder().RespondOutput("on")
+	runner = recorder
+
+	ps := PowerService{}
+
+	testSuite := &testsuite.WorkflowTestSuite{}
+	env := testSuite.NewTestActivityEnvironment()
+	env.RegisterActivity(ps.PowerQuery)
+
+	_, err := env.ExecuteActivity(ps.PowerQuery, PowerQueryParam{
+		PowerParam: PowerParam{
+			DriverOpts: map[string]any{"power_address": "0.0.0.0", "power_pass": "maas"},
+			DriverType: "ipmi",
+		},
+	})
+	require.NoError(t, err)
+
+	require.Len(t, recorder.Calls(), 1)
+
+	call := recorder.Calls()[0]
+	require.NotNil(t, call.Sandbox, "the power CLI must be sandboxed")
+	assert.Same(t, execx.DefaultSeccompProfile, call.Sandbox.Seccomp)
+	assert.Contains(t, call.Env, "SNAP=/snap/maas/current")
+	assert.NotContains(t, call.Env, "MAAS_AGENT_SECRET=secret")
+}
+
+// secretsStub is a secrets.Provider with the secrets of a map.
+type secretsStub map[string]map[string]string
+
+func (s secretsStub) Get(_ context.Context, path string) (*secrets.Secret, error) {
+	fields, ok := s[path]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", path, secrets.ErrNotFound)
+	}
+
+	return &secrets.Secret{Fields: fields}, nil
+}
+
+func TestPowerCommandSecretPath(t *testing.T) {
+	provider := secretsStub{
+		"bmc/1/power-parameters": {"power_user": "admin", "power_pass": "s3cret"},
+	}
+
+	testcases := map[string]struct {
+		provider secrets.Provider
+		in       PowerParam
+		out      map[string]any
+		err      error
+	}{
+		"no secret path": {
+			in: PowerParam{
+				DriverOpts: map[string]any{"power_address": "0.0.0.0", "power_pass": "maas"},
+			},
+			out: map[string]any{"power_address": "0.0.0.0", "power_pass": "maas"},
+		},
+		"credentials from the secret": {
+			provider: provider,
+			in: PowerParam{
+				DriverOpts: map[string]any{"power_address": "0.0.0.0", "power_pass": "maas"},
+				SecretPath: "bmc/1/power-parameters",
+			},
+			out: map[string]any{
+				"power_address": "0.0.0.0",
+				"power_user":    "admin",
+				"power_pass":    "s3cret",
+			},
+		},
+		"secret not found": {
+			provider: provider,
+			in:       PowerParam{SecretPath: "bmc/2/power-parameters"},
+			err:      secrets.ErrNotFound,
+		},
+		"no provider": {
+			in: PowerParam{SecretPath: "bmc/1/power-parameters"},
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			recorder := exectest.NewRecorder().RespondOutput("on")
+			runner = recorder
+
+			ps := NewPowerService("abc", nil, WithSecrets(tc.provider))
+
+			testSuite := &testsuite.WorkflowTestSuite{}
+			env := testSuite.NewTestActivityEnvironment()
+			env.RegisterActivity(ps.PowerQuery)
+
+			tc.in.DriverType = "ipmi"
+
+			_, err := env.ExecuteActivity(ps.PowerQuery, PowerQueryParam{PowerParam: tc.in})
+			if tc.out == nil {
+				require.Error(t, err)
+				assert.Empty(t, recorder.Calls(), "the power CLI must not run without credentials")
+
+				if tc.err != nil {
+					assert.ErrorContains(t, err, tc.err.Error())
+				}
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Len(t, recorder.Calls(), 1)
+			assert.ElementsMatch(t, append([]string{"status", "ipmi"}, fmtPowerOpts(tc.out)...),
+				recorder.Calls()[0].Args)
+		})
+	}
+}
+
 func TestPowerOn(t *testing.T) {
 	// Setup a redfish power on activity input
 	param := PowerOnParam{
@@ -102,23 +211,9 @@ func TestPowerOn(t *testing.T) {
 		State: "on",
 	}
 
-	// Override the factories defined in service.go with mocks
-	var mockedPowerProc testPowerProc
-
-	procFactory = func(_ context.Context, stdout, _ *bytes.Buffer, name string, arg ...string) powerProc {
-		mockedPowerProc = testPowerProc{
-			name: name,
-			arg:  arg,
-		}
-
-		stdout.WriteString("on")
-
-		return mockedPowerProc
-	}
-
-	pathFactory = func(_ string) (string, error) {
-		return expectedMAASCLIName, nil
-	}
+	// Override the runner defined in service.go with a recorder
+	recorder := exectest.NewRecorder().RespondOutput("on")
+	runner = recorder
 
 	ps := PowerService{}
 
@@ -131,8 +226,9 @@ func TestPowerOn(t *testing.T) {
 	val, err := env.ExecuteActivity(ps.PowerOn, param)
 
 	// Ensure the powerCommand was called correctly
-	assert.Equal(t, expectedMAASCLIName, mockedPowerProc.name)
-	assert.ElementsMatch(t, expectedArgs, mockedPowerProc.arg)
+	require.Len(t, recorder.Calls(), 1)
+	assert.Equal(t, expectedMAASCLIName, recorder.Calls()[0].Name)
+	assert.ElementsMatch(t, expectedArgs, recorder.Calls()[0].Args)
 
 	// Ensure the power command returns the anticipated state, without error
 	assert.NoError(t, err)
@@ -163,23 +259,9 @@ func TestPowerOff(t *testing.T) {
 		State: "off",
 	}
 
-	// Override the factories defined in service.go with mocks
-	var mockedPowerProc testPowerProc
-
-	procFactory = func(_ context.Context, stdout, _ *bytes.Buffer, name string, arg ...string) powerProc {
-		mockedPowerProc = testPowerProc{
-			name: name,
-			arg:  arg,
-		}
-
-		stdout.WriteString("off")
-
-		return mockedPowerProc
-	}
-
-	pathFactory = func(_ string) (string, error) {
-		return expectedMAASCLIName, nil
-	}
+	// Override the runner defined in service.go with a recorder
+	recorder := exectest.NewRecorder().RespondOutput("off")
+	runner = recorder
 
 	ps := PowerService{}
 
@@ -192,8 +274,9 @@ func TestPowerOff(t *testing.T) {
 	val, err := env.ExecuteActivity(ps.PowerOff, param)
 
 	// Ensure the powerCommand was called correctly
-	assert.Equal(t, expectedMAASCLIName, mockedPowerProc.name)
-	assert.ElementsMatch(t, expectedArgs, mockedPowerProc.arg)
+	require.Len(t, recorder.Calls(), 1)
+	assert.Equal(t, expectedMAASCLIName, recorder.Calls()[0].Name)
+	assert.ElementsMatch(t, expectedArgs, recorder.Calls()[0].Args)
 
 	// Ensure the power command returns the anticipated state, without error
 	assert.NoError(t, err)
@@ -224,23 +307,9 @@ func TestPowerCycle(t *testing.T) {
 		State: "on",
 	}
 
-	// Override the factories defined in service.go with mocks
-	var mockedPowerProc testPowerProc
-
-	procFactory = func(_ context.Context, stdout, _ *bytes.Buffer, name string, arg ...string) powerProc {
-		mockedPowerProc = testPowerProc{
-			name: name,
-			arg:  arg,
-		}
-
-		stdout.WriteString("on")
-
-		return mockedPowerProc
-	}
-
-	pathFactory = func(_ string) (string, error) {
-		return expectedMAASCLIName, nil
-	}
+	// Override the runner defined in service.go with a recorder
+	recorder := exectest.NewRecorder().RespondOutput("on")
+	runner = recorder
 
 	ps := PowerService{}
 
@@ -253,8 +322,9 @@ func TestPowerCycle(t *testing.T) {
 	val, err := env.ExecuteActivity(ps.PowerCycle, param)
 
 	// Ensure the powerCommand was called correctly
-	assert.Equal(t, expectedMAASCLIName, mockedPowerProc.name)
-	assert.ElementsMatch(t, expectedArgs, mockedPowerProc.arg)
+	require.Len(t, recorder.Calls(), 1)
+	assert.Equal(t, expectedMAASCLIName, recorder.Calls()[0].Name)
+	assert.ElementsMatch(t, expectedArgs, recorder.Calls()[0].Args)
 
 	// Ensure the power command returns the anticipated state, without error
 	assert.NoError(t, err)
@@ -285,23 +355,9 @@ func TestPowerQuery(t *testing.T) {
 		State: "off",
 	}
 
-	// Override the factories defined in service.go with mocks
-	var mockedPowerProc testPowerProc
-
-	procFactory = func(_ context.Context, stdout, _ *bytes.Buffer, name string, arg ...string) powerProc {
-		mockedPowerProc = testPowerProc{
-			name: name,
-			arg:  arg,
-		}
-
-		stdout.WriteString("off")
-
-		return mockedPowerProc
-	}
-
-	pathFactory = func(_ string) (string, error) {
-		return expectedMAASCLIName, nil
-	}
+	// Override the runner defined in service.go with a recorder
+	recorder := exectest.NewRecorder().RespondOutput("off")
+	runner = recorder
 
 	ps := PowerService{}
 
@@ -314,8 +370,9 @@ func TestPowerQuery(t *testing.T) {
 	val, err := env.ExecuteActivity(ps.PowerQuery, param)
 
 	// Ensure the powerCommand was called correctly
-	assert.Equal(t, expectedMAASCLIName, mockedPowerProc.name)
-	assert.ElementsMatch(t, expectedArgs, mockedPowerProc.arg)
+	require.Len(t, recorder.Calls(), 1)
+	assert.Equal(t, expectedMAASCLIName, recorder.Calls()[0].Name)
+	assert.ElementsMatch(t, expectedArgs, recorder.Calls()[0].Args)
 
 	// Ensure the power command returns the anticipated state, without error
 	assert.NoError(t, err)
@@ -347,23 +404,9 @@ func TestPowerReset(t *testing.T) {
 		State: "on",
 	}
 
-	// Override the factories defined in service.go with mocks
-	var mockedPowerProc testPowerProc
-
-	procFactory = func(_ context.Context, stdout, _ *bytes.Buffer, name string, arg ...string) powerProc {
-		mockedPowerProc = testPowerProc{
-			name: name,
-			arg:  arg,
-		}
-
-		stdout.WriteString("on")
-
-		return mockedPowerProc
-	}
-
-	pathFactory = func(_ string) (string, error) {
-		return expectedMAASCLIName, nil
-	}
+	// Override the runner defined in service.go with a recorder
+	recorder := exectest.NewRecorder().RespondOutput("on")
+	runner = recorder
 
 	ps := PowerService{}
 
@@ -376,8 +419,9 @@ func TestPowerReset(t *testing.T) {
 	val, err := env.ExecuteActivity(ps.PowerReset, param)
 
 	// Ensure the powerCommand was called correctly
-	assert.Equal(t, expectedMAASCLIName, mockedPowerProc.name)
-	assert.ElementsMatch(t, expectedArgs, mockedPowerProc.arg)
+	require.Len(t, recorder.Calls(), 1)
+	assert.Equal(t, expectedMAASCLIName, recorder.Calls()[0].Name)
+	assert.ElementsMatch(t, expectedArgs, recorder.Calls()[0].Args)
 
 	// Ensure the power command returns the anticipated state, without error
 	assert.NoError(t, err)
@@ -409,23 +453,9 @@ func TestPowerOnDPU(t *testing.T) {
 		State: "on",
 	}
 
-	// Override the factories defined in service.go with mocks
-	var mockedPowerProc testPowerProc
-
-	procFactory = func(_ context.Context, stdout, _ *bytes.Buffer, name string, arg ...string) powerProc {
-		mockedPowerProc = testPowerProc{
-			name: name,
-			arg:  arg,
-		}
-
-		stdout.WriteString("on")
-
-		return mockedPowerProc
-	}
-
-	pathFactory = func(_ string) (string, error) {
-		return expectedMAASCLIName, nil
-	}
+	// Override the runner defined in service.go with a recorder
+	recorder := exectest.NewRecorder().RespondOutput("on")
+	runner = recorder
 
 	ps := PowerService{}
 
@@ -438,8 +468,9 @@ func TestPowerOnDPU(t *testing.T) {
 	val, err := env.ExecuteActivity(ps.PowerOn, param)
 
 	// Ensure the powerCommand was called correctly
-	assert.Equal(t, expectedMAASCLIName, mockedPowerProc.name)
-	assert.ElementsMatch(t, expectedArgs, mockedPowerProc.arg)
+	require.Len(t, recorder.Calls(), 1)
+	assert.Equal(t, expectedMAASCLIName, recorder.Calls()[0].Name)
+	assert.ElementsMatch(t, expectedArgs, recorder.Calls()[0].Args)
 
 	// Ensure the power command returns the anticipated state, without error
 	assert.NoError(t, err)
@@ -471,23 +502,9 @@ func TestPowerCycleDPU(t *testing.T) {
 		State: "on",
 	}
 
-	// Override the factories defined in service.go with mocks
-	var mockedPowerProc testPowerProc
-
-	procFactory = func(_ context.Context, stdout, _ *bytes.Buffer, name string, arg ...string) powerProc {
-		mockedPowerProc = testPowerProc{
-			name: name,
-			arg:  arg,
-		}
-
-		stdout.WriteString("on")
-
-		return mockedPowerProc
-	}
-
-	pathFactory = func(_ string) (string, error) {
-		return expectedMAASCLIName, nil
-	}
+	// Override the runner defined in service.go with a recorder
+	recorder := exectest.NewRecorder().RespondOutput("on")
+	runner = recorder
 
 	ps := PowerService{}
 
@@ -500,8 +517,9 @@ func TestPowerCycleDPU(t *testing.T) {
 	val, err := env.ExecuteActivity(ps.PowerCycle, param)
 
 	// Ensure the powerCommand was called correctly
-	assert.Equal(t, expectedMAASCLIName, mockedPowerProc.name)
-	assert.ElementsMatch(t, expectedArgs, mockedPowerProc.arg)
+	require.Len(t, recorder.Calls(), 1)
+	assert.Equal(t, expectedMAASCLIName, recorder.Calls()[0].Name)
+	assert.ElementsMatch(t, expectedArgs, recorder.Calls()[0].Args)
 
 	// Ensure the power command returns the anticipated state, without error
 	assert.NoError(t, err)
@@ -534,23 +552,9 @@ func TestPowerResetDPU(t *testing.T) {
 		State: "on",
 	}
 
-	// Override the factories defined in service.go with mocks
-	var mockedPowerProc testPowerProc
-
-	procFactory = func(_ context.Context, stdout, _ *bytes.Buffer, name string, arg ...string) powerProc {
-		mockedPowerProc = testPowerProc{
-			name: name,
-			arg:  arg,
-		}
-
-		stdout.WriteString("on")
-
-		return mockedPowerProc
-	}
-
-	pathFactory = func(_ string) (string, error) {
-		return expectedMAASCLIName, nil
-	}
+	// Override the runner defined in service.go with a recorder
+	recorder := exectest.NewRecorder().RespondOutput("on")
+	runner = recorder
 
 	ps := PowerService{}
 
@@ -563,8 +567,9 @@ func TestPowerResetDPU(t *testing.T) {
 	val, err := env.ExecuteActivity(ps.PowerReset, param)
 
 	// Ensure the powerCommand was called correctly
-	assert.Equal(t, expectedMAASCLIName, mockedPowerProc.name)
-	assert.ElementsMatch(t, expectedArgs, mockedPowerProc.arg)
+	require.Len(t, recorder.Calls(), 1)
+	assert.Equal(t, expectedMAASCLIName, recorder.Calls()[0].Name)
+	assert.ElementsMatch(t, expectedArgs, recorder.Calls()[0].Args)
 
 	// Ensure the power command returns the anticipated state, without error
 	assert.NoError(t, err)
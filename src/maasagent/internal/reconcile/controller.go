@@ -0,0 +1,345 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package reconcile
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"maas.io/core/src/maasagent/internal/events"
+	"maas.io/core/src/maasgocommon/clock"
+	"maas.io/core/src/maasgocommon/retry"
+)
+
+const (
+	defaultResyncInterval = 10 * time.Minute
+	defaultInitialBackoff = time.Second
+	defaultMaxBackoff     = 5 * time.Minute
+)
+
+var (
+	// ErrUnknownModule is returned for documents of modules that are not
+	// registered.
+	ErrUnknownModule = errors.New("unknown module")
+	// ErrStaleGeneration is returned for documents older than the desired
+	// state of their module.
+	ErrStaleGeneration = errors.New("stale generation")
+)
+
+// Controller reconciles the registered modules with their desired state,
+// each in its own goroutine, so that a slow module doesn't hold the others
+// back.
+type Controller struct {
+	clock   clock.Clock
+	bus     *events.Bus
+	modules map[string]*module
+	backoff []retry.Option
+	resync  time.Duration
+	mu      sync.Mutex
+}
+
+// module is the state of a registered module. desired and status are
+// guarded by Controller.mu, backoff is only used by the goroutine
+// reconciling the module.
+type module struct {
+	impl    Module
+	trigger chan struct{}
+	backoff *retry.Backoff
+	desired *Document
+	status  Status
+}
+
+// Option configures a Controller.
+type Option func(*Controller)
+
+// WithResyncInterval sets the time between reconciliations of a module
+// whose desired state didn't change, which correct drift, 10 minutes by
+// default.
+func WithResyncInterval(d time.Duration) Option {
+	return func(c *Controller) {
+		c.resync = d
+	}
+}
+
+// WithBackoff configures the delays between retries of a failing module
+// with the interval, multiplier and jitter options of the retry package.
+func WithBackoff(opts ...retry.Option) Option {
+	return func(c *Controller) {
+		c.backoff = append(c.backoff, opts...)
+	}
+}
+
+// WithEvents publishes a ModuleStatus event on b whenever a condition of a
+// module changes.
+func WithEvents(b *events.Bus) Option {
+	return func(c *Controller) {
+		c.bus = b
+	}
+}
+
+// WithClock sets the clock used to schedule reconciliations and timestamp
+// conditions.
+func WithClock(clk clock.Clock) Option {
+	return func(c *Controller) {
+		c.clock = clk
+	}
+}
+
+// NewController returns a Controller without modules.
+func NewController(opts ...Option) *Controller {
+	c := &Controller{
+		clock:   clock.New(),
+		modules: make(map[string]*module),
+		resync:  defaultResyncInterval,
+		backoff: []retry.Option{
+			retry.WithInitialInterval(defaultInitialBackoff),
+			retry.WithMaxInterval(defaultMaxBackoff),
+		},
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// Register adds a module, which is reconciled once a document is applied
+// for it. It must be called before Run, and panics if a module with the
+// same name is registered already.
+func (c *Controller) Register(m Module) {
+	name := m.Name()
+	if _, ok := c.modules[name]; ok {
+		panic(fmt.Sprintf("reconcile: module %q registered twice", name))
+	}
+
+	mod := &module{
+		impl:    m,
+		trigger: make(chan struct{}, 1),
+		backoff: retry.NewBackoff(c.backoff...),
+		status:  Status{Module: name},
+	}
+	mod.status.set(c.clock.Now(), Ready, false, ReasonNoDesiredState, "")
+
+	c.modules[name] = mod
+}
+
+// Apply sets the desired state of the module of doc, which is reconciled
+// shortly after. Applying the current desired state again is a no-op.
+func (c *Controller) Apply(doc Document) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	m, ok := c.modules[doc.Module]
+	if !ok {
+		return fmt.Errorf("%w %q", ErrUnknownModule, doc.Module)
+	}
+
+	if m.desired != nil {
+		if doc.Generation < m.desired.Generation {
+			return fmt.Errorf("%w: module %q is at generation %d, got %d",
+				ErrStaleGeneration, doc.Module, m.desired.Generation, doc.Generation)
+		}
+
+		if doc.Generation == m.desired.Generation && bytes.Equal(doc.Spec, m.desired.Spec) {
+			return nil
+		}
+	}
+
+	m.desired = &doc
+	m.status.Generation = doc.Generation
+
+	select {
+	case m.trigger <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+// Status returns the status of the modules, sorted by name.
+func (c *Controller) Status() []Status {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	statuses := make([]Status, 0, len(c.modules))
+	for _, m := range c.modules {
+		s := m.status
+		s.Conditions = slices.Clone(s.Conditions)
+		statuses = append(statuses, s)
+	}
+
+	slices.SortFunc(statuses, func(a, b Status) int { return strings.Compare(a.Module, b.Module) })
+
+	return statuses
+}
+
+// Run reconciles the modules until ctx is cancelled.
+func (c *Controller) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+
+	for _, m := range c.modules {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			c.run(ctx, m)
+		}()
+	}
+
+	wg.Wait()
+}
+
+func (c *Controller) run(ctx context.Context, m *module) {
+	timer := c.clock.NewTimer(c.resync)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-m.trigger:
+		case <-timer.C():
+		}
+
+		timer.Reset(c.reconcile(ctx, m))
+	}
+}
+
+// reconcile converges m on its desired state once and returns the delay
+// before the next reconciliation.
+func (c *Controller) reconcile(ctx context.Context, m *module) time.Duration {
+	c.mu.Lock()
+	doc := m.desired
+	converged := m.status.IsTrue(Ready) && doc != nil && m.status.ObservedGeneration == doc.Generation
+	c.mu.Unlock()
+
+	if doc == nil {
+		return c.resync
+	}
+
+	actions, err := m.impl.Plan(ctx, doc.Spec)
+	if errors.Is(err, ErrInvalidSpec) {
+		c.update(m, func(s *Status, now time.Time) {
+			s.set(now, Ready, false, ReasonInvalidSpec, err.Error())
+			s.set(now, Progressing, false, ReasonInvalidSpec, "")
+			s.set(now, Degraded, true, ReasonInvalidSpec, err.Error())
+		})
+
+		// Retrying won't help, wait for another document.
+		return c.resync
+	} else if err != nil {
+		c.update(m, func(s *Status, now time.Time) {
+			s.set(now, Ready, false, ReasonPlanFailed, err.Error())
+			s.set(now, Progressing, false, ReasonPlanFailed, "")
+			s.set(now, Degraded, true, ReasonPlanFailed, err.Error())
+		})
+
+		return m.backoff.Next()
+	}
+
+	if len(actions) == 0 {
+		c.update(m, func(s *Status, now time.Time) {
+			s.ObservedGeneration = doc.Generation
+			s.set(now, Ready, true, ReasonInSync, "")
+			s.set(now, Progressing, false, ReasonInSync, "")
+			s.set(now, Degraded, false, ReasonInSync, "")
+		})
+		m.backoff.Reset()
+
+		return c.resync
+	}
+
+	reason := ReasonApplying
+	if converged {
+		reason = ReasonDriftDetected
+	}
+
+	c.update(m, func(s *Status, now time.Time) {
+		if converged {
+			s.Drifts++
+		}
+
+		msg := fmt.Sprintf("%d actions to apply", len(actions))
+		s.set(now, Ready, false, reason, msg)
+		s.set(now, Progressing, true, reason, msg)
+	})
+
+	for _, a := range actions {
+		if err := a.Apply(ctx); err != nil {
+			msg := fmt.Sprintf("%s: %v", a.Description, err)
+
+			c.update(m, func(s *Status, now time.Time) {
+				s.set(now, Ready, false, ReasonApplyFailed, msg)
+				s.set(now, Progressing, false, ReasonApplyFailed, "")
+				s.set(now, Degraded, true, ReasonApplyFailed, msg)
+			})
+
+			return m.backoff.Next()
+		}
+
+		log.Debug().Str("module", m.status.Module).Str("action", a.Description).Msg("Applied action")
+	}
+
+	// Plan again shortly, to confirm the host converged. The backoff is
+	// only reset then, so that a module that never converges isn't
+	// reconciled in a tight loop.
+	return m.backoff.Next()
+}
+
+// update changes the status of m with fn, and logs and publishes the
+// conditions that changed.
+func (c *Controller) update(m *module, fn func(s *Status, now time.Time)) {
+	now := c.clock.Now()
+
+	c.mu.Lock()
+	before := slices.Clone(m.status.Conditions)
+	fn(&m.status, now)
+	m.status.LastReconcileTime = now
+	after := slices.Clone(m.status.Conditions)
+	name := m.status.Module
+	c.mu.Unlock()
+
+	for _, cond := range after {
+		i := slices.IndexFunc(before, func(b Condition) bool { return b.Type == cond.Type })
+		if i >= 0 && before[i].Status == cond.Status && before[i].Reason == cond.Reason &&
+			before[i].Message == cond.Message {
+			continue
+		}
+
+		log.Info().Str("module", name).Str("condition", string(cond.Type)).
+			Bool("status", cond.Status).Str("reason", cond.Reason).Str("message", cond.Message).
+			Msg("Module condition changed")
+
+		if c.bus != nil {
+			c.bus.Publish(events.Event{
+				Time:    now,
+				Type:    events.ModuleStatus,
+				Service: name,
+				Message: fmt.Sprintf("%s=%t %s %s", cond.Type, cond.Status, cond.Reason, cond.Message),
+			})
+		}
+	}
+}
@@ -0,0 +1,330 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package reconcile
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"maas.io/core/src/maasagent/internal/events"
+	"maas.io/core/src/maasgocommon/clock"
+	"maas.io/core/src/maasgocommon/retry"
+)
+
+var epoch = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+var errApply = errors.New("permission denied")
+
+// fakeHost is a module managing key/value pairs, failing the next failures
+// actions it applies.
+type fakeHost struct {
+	state    map[string]string
+	failures int
+	mu       sync.Mutex
+}
+
+func (h *fakeHost) observe(context.Context) (map[string]string, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return maps.Clone(h.state), nil
+}
+
+func (h *fakeHost) diff(desired, observed map[string]string) []Action {
+	var actions []Action
+
+	for _, k := range slices.Sorted(maps.Keys(desired)) {
+		if observed[k] != desired[k] {
+			actions = append(actions, Action{
+				Description: fmt.Sprintf("set %s", k),
+				Apply:       func(context.Context) error { return h.set(k, desired[k]) },
+			})
+		}
+	}
+
+	for _, k := range slices.Sorted(maps.Keys(observed)) {
+		if _, ok := desired[k]; !ok {
+			actions = append(actions, Action{
+				Description: fmt.Sprintf("delete %s", k),
+				Apply:       func(context.Context) error { return h.set(k, "") },
+			})
+		}
+	}
+
+	return actions
+}
+
+func (h *fakeHost) set(k, v string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.failures > 0 {
+		h.failures--
+		return errApply
+	}
+
+	if v == "" {
+		delete(h.state, k)
+	} else {
+		h.state[k] = v
+	}
+
+	return nil
+}
+
+func (h *fakeHost) get() map[string]string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return maps.Clone(h.state)
+}
+
+func newTestController(t *testing.T, clk clock.Clock, opts ...Option) (*Controller, *module, *fakeHost) {
+	t.Helper()
+
+	h := &fakeHost{state: map[string]string{"stale": "1"}}
+
+	opts = append([]Option{
+		WithClock(clk),
+		WithBackoff(retry.WithJitter(0)),
+	}, opts...)
+
+	c := NewController(opts...)
+	c.Register(NewModule("dns", h.observe, h.diff))
+
+	return c, c.modules["dns"], h
+}
+
+func apply(t *testing.T, c *Controller, generation int64, spec string) {
+	t.Helper()
+
+	require.NoError(t, c.Apply(Document{Module: "dns", Generation: generation, Spec: json.RawMessage(spec)}))
+}
+
+func conditions(s Status) map[ConditionType]string {
+	out := make(map[ConditionType]string)
+	for _, c := range s.Conditions {
+		out[c.Type] = fmt.Sprintf("%t %s", c.Status, c.Reason)
+	}
+
+	return out
+}
+
+func TestReconcileConverges(t *testing.T) {
+	clk := clock.NewFake(epoch)
+	c, m, h := newTestController(t, clk)
+
+	assert.Equal(t, map[ConditionType]string{Ready: "false NoDesiredState"}, conditions(c.Status()[0]))
+	assert.Equal(t, 10*time.Minute, c.reconcile(context.Background(), m))
+
+	apply(t, c, 1, `{"a": "1", "b": "2"}`)
+
+	// Actions are applied, and the result is checked after the backoff.
+	assert.Equal(t, time.Second, c.reconcile(context.Background(), m))
+	assert.Equal(t, map[string]string{"a": "1", "b": "2"}, h.get())
+
+	status := c.Status()[0]
+	assert.Equal(t, map[ConditionType]string{
+		Ready:       "false Applying",
+		Progressing: "true Applying",
+	}, conditions(status))
+	assert.Equal(t, int64(1), status.Generation)
+	assert.Zero(t, status.ObservedGeneration)
+
+	clk.Advance(time.Second)
+	assert.Equal(t, 10*time.Minute, c.reconcile(context.Background(), m))
+
+	status = c.Status()[0]
+	assert.Equal(t, map[ConditionType]string{
+		Ready:       "true InSync",
+		Progressing: "false InSync",
+		Degraded:    "false InSync",
+	}, conditions(status))
+	assert.Equal(t, int64(1), status.ObservedGeneration)
+	assert.Equal(t, epoch.Add(time.Second), status.LastReconcileTime)
+	assert.Zero(t, status.Drifts)
+
+	ready, ok := status.Condition(Ready)
+	require.True(t, ok)
+	assert.Equal(t, epoch.Add(time.Second), ready.LastTransitionTime)
+}
+
+func TestReconcileCorrectsDrift(t *testing.T) {
+	clk := clock.NewFake(epoch)
+	c, m, h := newTestController(t, clk)
+
+	apply(t, c, 1, `{"a": "1"}`)
+	c.reconcile(context.Background(), m)
+	c.reconcile(context.Background(), m)
+	require.True(t, c.Status()[0].IsTrue(Ready))
+
+	require.NoError(t, h.set("a", "changed"))
+
+	c.reconcile(context.Background(), m)
+	assert.Equal(t, map[string]string{"a": "1"}, h.get())
+
+	status := c.Status()[0]
+	assert.Equal(t, "false DriftDetected", conditions(status)[Ready])
+	assert.Equal(t, 1, status.Drifts)
+
+	c.reconcile(context.Background(), m)
+	assert.True(t, c.Status()[0].IsTrue(Ready))
+}
+
+func TestReconcileRetriesWithBackoff(t *testing.T) {
+	clk := clock.NewFake(epoch)
+	c, m, h := newTestController(t, clk)
+	h.failures = 3
+
+	apply(t, c, 1, `{"a": "1"}`)
+
+	var delays []time.Duration
+	for range 3 {
+		delays = append(delays, c.reconcile(context.Background(), m))
+	}
+
+	assert.Equal(t, []time.Duration{time.Second, 1500 * time.Millisecond, 2250 * time.Millisecond}, delays)
+
+	status := c.Status()[0]
+	assert.Equal(t, map[ConditionType]string{
+		Ready:       "false ApplyFailed",
+		Progressing: "false ApplyFailed",
+		Degraded:    "true ApplyFailed",
+	}, conditions(status))
+
+	degraded, _ := status.Condition(Degraded)
+	assert.Equal(t, "set a: permission denied", degraded.Message)
+
+	// The backoff is reset once the module converged.
+	c.reconcile(context.Background(), m)
+	assert.Equal(t, 10*time.Minute, c.reconcile(context.Background(), m))
+	assert.True(t, c.Status()[0].IsTrue(Ready))
+
+	require.NoError(t, h.set("a", "changed"))
+	h.failures = 1
+	assert.Equal(t, time.Second, c.reconcile(context.Background(), m))
+}
+
+func TestReconcileInvalidSpec(t *testing.T) {
+	clk := clock.NewFake(epoch)
+	c, m, h := newTestController(t, clk)
+
+	apply(t, c, 1, `["a"]`)
+	assert.Equal(t, 10*time.Minute, c.reconcile(context.Background(), m))
+	assert.Equal(t, map[string]string{"stale": "1"}, h.get())
+
+	status := c.Status()[0]
+	assert.Equal(t, "true InvalidSpec", conditions(status)[Degraded])
+
+	apply(t, c, 2, `{}`)
+	c.reconcile(context.Background(), m)
+	c.reconcile(context.Background(), m)
+	assert.Empty(t, h.get())
+	assert.True(t, c.Status()[0].IsTrue(Ready))
+}
+
+func TestApply(t *testing.T) {
+	c, m, _ := newTestController(t, clock.NewFake(epoch))
+
+	err := c.Apply(Document{Module: "ntp", Generation: 1})
+	assert.ErrorIs(t, err, ErrUnknownModule)
+
+	apply(t, c, 2, `{}`)
+	<-m.trigger
+
+	err = c.Apply(Document{Module: "dns", Generation: 1, Spec: json.RawMessage(`{}`)})
+	assert.ErrorIs(t, err, ErrStaleGeneration)
+
+	// The current desired state doesn't trigger a reconciliation.
+	apply(t, c, 2, `{}`)
+	assert.Empty(t, m.trigger)
+
+	apply(t, c, 2, `{"a": "1"}`)
+	assert.Len(t, m.trigger, 1)
+}
+
+func TestRegisterTwice(t *testing.T) {
+	c, _, h := newTestController(t, clock.NewFake(epoch))
+
+	assert.Panics(t, func() {
+		c.Register(NewModule("dns", h.observe, h.diff))
+	})
+}
+
+func TestRun(t *testing.T) {
+	clk := clock.NewFake(epoch)
+	bus := events.NewBus(events.WithClock(clk))
+	sub := bus.Subscribe(events.Filter{Types: []events.Type{events.ModuleStatus}})
+	c, _, h := newTestController(t, clk, WithEvents(bus))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		c.Run(ctx)
+	}()
+
+	apply(t, c, 1, `{"a": "1"}`)
+
+	// Advance the clock until the check after the backoff happened.
+	require.Eventually(t, func() bool {
+		clk.Advance(time.Second)
+		return c.Status()[0].IsTrue(Ready)
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, map[string]string{"a": "1"}, h.get())
+
+	cancel()
+	<-done
+
+	e := <-sub.Events()
+	assert.Equal(t, "dns", e.Service)
+	assert.Equal(t, "Ready=false Applying 2 actions to apply", e.Message)
+}
+
+func TestHandler(t *testing.T) {
+	c, m, _ := newTestController(t, clock.NewFake(epoch))
+
+	apply(t, c, 1, `{}`)
+	c.reconcile(context.Background(), m)
+
+	rec := httptest.NewRecorder()
+	Handler(c).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/modules", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var statuses []Status
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &statuses))
+	require.Len(t, statuses, 1)
+	assert.Equal(t, "dns", statuses[0].Module)
+
+	rec = httptest.NewRecorder()
+	Handler(c).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/modules", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
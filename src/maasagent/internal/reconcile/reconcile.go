@@ -0,0 +1,106 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package reconcile converges agent modules, such as DHCP, DNS, network and
+// boot, on the desired state the region pushes for them.
+//
+// The region sends a Document per module, carrying the full desired state
+// and a generation increasing with every change. Each module compares it
+// with what it observes on the host and returns the actions converging the
+// two, which the Controller applies. The same loop corrects drift: modules
+// are reconciled again periodically, and changes made behind the back of
+// the agent are reverted. Failures are retried with an exponential backoff.
+//
+// The outcome is reported as status conditions, like Kubernetes
+// controllers, so that every module reports its state the same way:
+//
+//   - Ready: the host matches the desired state.
+//   - Progressing: actions are being applied.
+//   - Degraded: planning or applying actions failed, and is retried.
+package reconcile
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidSpec is wrapped by the errors of modules given a desired state
+// they can't apply. Such documents are not retried until the region sends
+// another one.
+var ErrInvalidSpec = errors.New("invalid desired state")
+
+// Document is the desired state of a module, pushed by the region.
+type Document struct {
+	// Module is the name of the module the document is for.
+	Module string `json:"module"`
+	// Spec is the desired state, in the format of the module.
+	Spec json.RawMessage `json:"spec"`
+	// Generation increases with every change of the desired state.
+	// Documents older than the last one applied are rejected.
+	Generation int64 `json:"generation"`
+}
+
+// Action is a change bringing the host closer to the desired state.
+type Action struct {
+	// Apply makes the change.
+	Apply func(ctx context.Context) error
+	// Description says what Apply does, e.g. "bind 10.0.0.1:53", for
+	// status messages and logs.
+	Description string
+}
+
+// Module is a subsystem of the agent converged on a desired state.
+type Module interface {
+	// Name identifies the module in documents and status, e.g. "dns".
+	Name() string
+	// Plan returns the actions converging the host on spec, none if it
+	// already matches. An error wrapping ErrInvalidSpec means spec can't
+	// be applied.
+	Plan(ctx context.Context, spec json.RawMessage) ([]Action, error)
+}
+
+// NewModule returns a Module whose desired and observed states have the
+// type S. Specs are decoded from JSON into S, and diff returns the actions
+// turning observed into desired.
+func NewModule[S any](name string, observe func(context.Context) (S, error),
+	diff func(desired, observed S) []Action) Module {
+	return &typedModule[S]{name: name, observe: observe, diff: diff}
+}
+
+type typedModule[S any] struct {
+	observe func(context.Context) (S, error)
+	diff    func(desired, observed S) []Action
+	name    string
+}
+
+func (m *typedModule[S]) Name() string {
+	return m.name
+}
+
+func (m *typedModule[S]) Plan(ctx context.Context, spec json.RawMessage) ([]Action, error) {
+	var desired S
+	if err := json.Unmarshal(spec, &desired); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSpec, err)
+	}
+
+	observed, err := m.observe(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("observing state: %w", err)
+	}
+
+	return m.diff(desired, observed), nil
+}
@@ -0,0 +1,56 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package reconcile
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewModulePlan(t *testing.T) {
+	h := &fakeHost{state: map[string]string{"a": "1", "b": "2"}}
+	m := NewModule("dns", h.observe, h.diff)
+	assert.Equal(t, "dns", m.Name())
+
+	actions, err := m.Plan(context.Background(), json.RawMessage(`{"a": "1", "c": "3"}`))
+	require.NoError(t, err)
+
+	var descriptions []string
+	for _, a := range actions {
+		descriptions = append(descriptions, a.Description)
+	}
+
+	assert.Equal(t, []string{"set c", "delete b"}, descriptions)
+
+	_, err = m.Plan(context.Background(), json.RawMessage(`"a"`))
+	assert.ErrorIs(t, err, ErrInvalidSpec)
+}
+
+func TestNewModulePlanObserveError(t *testing.T) {
+	m := NewModule("dns",
+		func(context.Context) (map[string]string, error) { return nil, errors.New("netlink: busy") },
+		func(_, _ map[string]string) []Action { return nil },
+	)
+
+	_, err := m.Plan(context.Background(), json.RawMessage(`{}`))
+	assert.EqualError(t, err, "observing state: netlink: busy")
+	assert.NotErrorIs(t, err, ErrInvalidSpec)
+}
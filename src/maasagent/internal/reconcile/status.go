@@ -0,0 +1,112 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package reconcile
+
+import (
+	"slices"
+	"time"
+)
+
+// ConditionType is an aspect of the state of a module.
+type ConditionType string
+
+const (
+	// Ready is true when the host matches the desired state.
+	Ready ConditionType = "Ready"
+	// Progressing is true while actions are being applied.
+	Progressing ConditionType = "Progressing"
+	// Degraded is true when the last reconciliation failed.
+	Degraded ConditionType = "Degraded"
+)
+
+// Reasons of the conditions.
+const (
+	ReasonNoDesiredState = "NoDesiredState"
+	ReasonInSync         = "InSync"
+	ReasonApplying       = "Applying"
+	ReasonDriftDetected  = "DriftDetected"
+	ReasonInvalidSpec    = "InvalidSpec"
+	ReasonPlanFailed     = "PlanFailed"
+	ReasonApplyFailed    = "ApplyFailed"
+)
+
+// Condition is the state of an aspect of a module.
+type Condition struct {
+	// LastTransitionTime is when Status last changed.
+	LastTransitionTime time.Time     `json:"last_transition_time"`
+	Type               ConditionType `json:"type"`
+	// Reason is a CamelCase identifier of the cause of the condition.
+	Reason string `json:"reason"`
+	// Message details the reason, e.g. the error of a failed action.
+	Message string `json:"message,omitempty"`
+	Status  bool   `json:"status"`
+}
+
+// Status is the state of a module.
+type Status struct {
+	// LastReconcileTime is when the module was last reconciled, zero if it
+	// never was.
+	LastReconcileTime time.Time   `json:"last_reconcile_time,omitzero"`
+	Module            string      `json:"module"`
+	Conditions        []Condition `json:"conditions"`
+	// Generation is the generation of the desired state, ObservedGeneration
+	// the one the host was last converged on.
+	Generation         int64 `json:"generation"`
+	ObservedGeneration int64 `json:"observed_generation"`
+	// Drifts counts the times the host was found to differ from a desired
+	// state it had been converged on.
+	Drifts int `json:"drifts"`
+}
+
+// Condition returns the condition t of the module.
+func (s Status) Condition(t ConditionType) (Condition, bool) {
+	i := slices.IndexFunc(s.Conditions, func(c Condition) bool { return c.Type == t })
+	if i < 0 {
+		return Condition{}, false
+	}
+
+	return s.Conditions[i], true
+}
+
+// IsTrue reports whether the condition t of the module is true.
+func (s Status) IsTrue(t ConditionType) bool {
+	c, _ := s.Condition(t)
+	return c.Status
+}
+
+// set updates the condition t, keeping its transition time if its status
+// doesn't change.
+func (s *Status) set(now time.Time, t ConditionType, status bool, reason, message string) {
+	c := Condition{
+		LastTransitionTime: now,
+		Type:               t,
+		Reason:             reason,
+		Message:            message,
+		Status:             status,
+	}
+
+	i := slices.IndexFunc(s.Conditions, func(c Condition) bool { return c.Type == t })
+	if i < 0 {
+		s.Conditions = append(s.Conditions, c)
+		return
+	}
+
+	if s.Conditions[i].Status == status {
+		c.LastTransitionTime = s.Conditions[i].LastTransitionTime
+	}
+
+	s.Conditions[i] = c
+}
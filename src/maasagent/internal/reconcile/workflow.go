@@ -0,0 +1,66 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package reconcile
+
+import (
+	"context"
+	"errors"
+
+	"go.temporal.io/sdk/temporal"
+	tworkflow "go.temporal.io/sdk/workflow"
+
+	"maas.io/core/src/maasagent/internal/workflow"
+)
+
+// ApplyDesiredStateParam is the parameter of the apply-desired-state
+// workflow, which the region executes to push desired state.
+type ApplyDesiredStateParam struct {
+	Documents []Document `json:"documents"`
+}
+
+// ConfigurationWorkflows returns the workflow the region pushes desired
+// state with.
+func (c *Controller) ConfigurationWorkflows() map[string]any {
+	return map[string]any{"apply-desired-state": c.applyDesiredState}
+}
+
+// ConfigurationActivities returns no activities, documents are applied in
+// a local activity.
+func (c *Controller) ConfigurationActivities() map[string]any {
+	return map[string]any{}
+}
+
+// applyDesiredState applies the documents of param. It returns once they
+// are accepted, modules converge on them in the background and report it
+// in their status.
+func (c *Controller) applyDesiredState(ctx tworkflow.Context, param ApplyDesiredStateParam) error {
+	return workflow.RunAsLocalActivity(ctx, func(_ context.Context) error {
+		var errs []error
+
+		for _, doc := range param.Documents {
+			if err := c.Apply(doc); err != nil {
+				errs = append(errs, err)
+			}
+		}
+
+		if err := errors.Join(errs...); err != nil {
+			// Rejected documents are rejected again if retried.
+			return temporal.NewNonRetryableApplicationError(err.Error(), "InvalidDocument", err)
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package reconcile
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/testsuite"
+
+	"maas.io/core/src/maasgocommon/clock"
+)
+
+func TestApplyDesiredStateWorkflow(t *testing.T) {
+	testcases := map[string]struct {
+		documents []Document
+		err       string
+	}{
+		"accepted": {
+			documents: []Document{{Module: "dns", Generation: 1, Spec: json.RawMessage(`{"a": "1"}`)}},
+		},
+		"unknown module": {
+			documents: []Document{
+				{Module: "dns", Generation: 1, Spec: json.RawMessage(`{"a": "1"}`)},
+				{Module: "ntp", Generation: 1},
+			},
+			err: `unknown module "ntp"`,
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			c, _, _ := newTestController(t, clock.NewFake(epoch))
+
+			assert.Empty(t, c.ConfigurationActivities())
+
+			workflows := c.ConfigurationWorkflows()
+			require.Contains(t, workflows, "apply-desired-state")
+
+			testSuite := &testsuite.WorkflowTestSuite{}
+			env := testSuite.NewTestWorkflowEnvironment()
+			env.RegisterWorkflow(workflows["apply-desired-state"])
+			env.ExecuteWorkflow(workflows["apply-desired-state"], ApplyDesiredStateParam{Documents: tc.documents})
+
+			require.True(t, env.IsWorkflowCompleted())
+
+			// Valid documents are applied either way.
+			assert.Equal(t, int64(1), c.Status()[0].Generation)
+
+			err := env.GetWorkflowError()
+			if tc.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			var appErr *temporal.ApplicationError
+
+			require.True(t, errors.As(err, &appErr))
+			assert.True(t, appErr.NonRetryable())
+			assert.ErrorContains(t, err, tc.err)
+		})
+	}
+}
@@ -16,25 +16,55 @@
 package resolver
 
 import (
+	"sync/atomic"
 	"time"
 
 	lru "github.com/hashicorp/golang-lru/v2"
 	"github.com/miekg/dns"
+
+	"maas.io/core/src/maasgocommon/clock"
 )
 
 const (
 	maxRecordSize        = 512
 	defaultMaxNumRecords = 1000
+	// defaultMaxNegativeTTL caps how long a negative answer is cached,
+	// RFC 2308 recommends one to three hours.
+	defaultMaxNegativeTTL = 3 * time.Hour
+	// defaultPrefetchMinHits is the number of hits making a record popular
+	// enough to be refreshed before it expires.
+	defaultPrefetchMinHits = 3
+	// minPrefetchTTL prevents refreshing records with a short TTL, which
+	// are better fetched again on demand.
+	minPrefetchTTL = 10 * time.Second
 )
 
 type Cache interface {
 	Get(string, uint16) (dns.RR, bool)
 	Set(dns.RR)
+	// GetNegative returns the response code and SOA of a cached negative
+	// answer (NXDOMAIN or NODATA) for the given name and type. The SOA TTL
+	// is the time left before the answer expires.
+	GetNegative(string, uint16) (int, *dns.SOA, bool)
+	// SetNegative caches a negative answer for the given name and type, as
+	// described in RFC 2308. NXDOMAIN answers apply to every type.
+	SetNegative(string, uint16, int, *dns.SOA)
+}
+
+// prefetcher is implemented by caches refreshing popular records before
+// they expire, with the function given by the handler.
+type prefetcher interface {
+	setPrefetchFunc(func(dns.Question))
 }
 
 type cacheEntry struct {
 	RR        dns.RR
 	CreatedAt time.Time
+	// Rcode is the response code of a negative answer, whose RR is the SOA
+	// of the zone.
+	Rcode       int
+	hits        atomic.Int64
+	prefetching atomic.Bool
 }
 
 // Expired calculates if an entry has reached its TTL
@@ -44,19 +74,31 @@ func (c *cacheEntry) expired(ts time.Time) bool {
 	return ts.Sub(c.CreatedAt) >= time.Duration(ttl)*time.Second
 }
 
+// remaining returns the time left before an entry reaches its TTL
+func (c *cacheEntry) remaining(ts time.Time) time.Duration {
+	return time.Duration(c.RR.Header().Ttl)*time.Second - ts.Sub(c.CreatedAt)
+}
+
 type CacheOption func(*cache)
 
 type cache struct {
-	cache         *lru.Cache[string, *cacheEntry]
-	stats         *cacheStats
-	maxNumRecords int
+	cache           *lru.Cache[string, *cacheEntry]
+	stats           *cacheStats
+	clock           clock.Clock
+	prefetch        func(dns.Question)
+	maxNumRecords   int
+	maxNegativeTTL  time.Duration
+	prefetchMinHits int64
 }
 
 // NewCache provides a constructor for an in-memory DNS cache
 func NewCache(options ...CacheOption) (Cache, error) {
 	c := &cache{
-		stats:         &cacheStats{},
-		maxNumRecords: defaultMaxNumRecords,
+		stats:           &cacheStats{},
+		clock:           clock.New(),
+		maxNumRecords:   defaultMaxNumRecords,
+		maxNegativeTTL:  defaultMaxNegativeTTL,
+		prefetchMinHits: defaultPrefetchMinHits,
 	}
 
 	for _, option := range options {
@@ -84,43 +126,167 @@ func WithMaxSize(size int64) CacheOption {
 	}
 }
 
+// WithCacheClock sets the clock used to expire records
+func WithCacheClock(clk clock.Clock) CacheOption {
+	return func(c *cache) {
+		c.clock = clk
+	}
+}
+
+// WithMaxNegativeTTL caps how long negative answers are cached. By default
+// it is defaultMaxNegativeTTL (3h)
+func WithMaxNegativeTTL(ttl time.Duration) CacheOption {
+	return func(c *cache) {
+		if ttl > 0 {
+			c.maxNegativeTTL = ttl
+		}
+	}
+}
+
+// WithPrefetch sets the number of hits after which a record is refreshed
+// when less than a tenth of its TTL is left, so that popular records do not
+// expire. By default it is defaultPrefetchMinHits (3), a negative value
+// disables prefetching.
+func WithPrefetch(minHits int) CacheOption {
+	return func(c *cache) {
+		if minHits != 0 {
+			c.prefetchMinHits = max(int64(minHits), 0)
+		}
+	}
+}
+
 // Get fetches a record for the given name and type if one is present
 // in the cache, returns false if one is absent or expired
 func (c *cache) Get(name string, rrtype uint16) (dns.RR, bool) {
-	key := c.key(name, rrtype)
-
-	entry, ok := c.cache.Get(key)
+	entry, ok := c.lookup(c.key(name, rrtype))
 	if !ok {
 		c.stats.misses.Add(1)
 
-		return nil, ok
-	}
-
-	if entry.expired(time.Now()) {
-		_ = c.cache.Remove(key)
-
-		c.stats.expirations.Add(1)
-
 		return nil, false
 	}
 
 	c.stats.hits.Add(1)
 
+	c.maybePrefetch(entry, name, rrtype)
+
 	return entry.RR, ok
 }
 
 // Set inserts a record into the cache
 func (c *cache) Set(rr dns.RR) {
 	hdr := rr.Header()
-	key := c.key(hdr.Name, hdr.Rrtype)
 
-	_ = c.cache.Add(key, &cacheEntry{
+	c.add(c.key(hdr.Name, hdr.Rrtype), &cacheEntry{
 		RR:        rr,
-		CreatedAt: time.Now(),
+		CreatedAt: c.clock.Now(),
+	})
+}
+
+// GetNegative fetches a negative answer for the given name and type if one
+// is present in the cache, returns false if one is absent or expired
+func (c *cache) GetNegative(name string, rrtype uint16) (int, *dns.SOA, bool) {
+	entry, ok := c.lookup(c.negativeKey(name, dns.TypeNone))
+	if !ok {
+		entry, ok = c.lookup(c.negativeKey(name, rrtype))
+	}
+
+	if !ok {
+		return 0, nil, false
+	}
+
+	cached, ok := entry.RR.(*dns.SOA)
+	if !ok {
+		return 0, nil, false
+	}
+
+	c.stats.negativeHits.Add(1)
+
+	soa := *cached
+	soa.Hdr.Ttl = uint32(entry.remaining(c.clock.Now()).Seconds())
+
+	return entry.Rcode, &soa, true
+}
+
+// SetNegative inserts a negative answer into the cache, kept for the
+// minimum of the SOA TTL and MINIMUM field (RFC 2308 section 5)
+func (c *cache) SetNegative(name string, rrtype uint16, rcode int, soa *dns.SOA) {
+	if rcode == dns.RcodeNameError {
+		rrtype = dns.TypeNone
+	}
+
+	ttl := min(soa.Hdr.Ttl, soa.Minttl, uint32(c.maxNegativeTTL.Seconds()))
+	if ttl == 0 {
+		return
+	}
+
+	rr := *soa
+	rr.Hdr.Ttl = ttl
+
+	c.add(c.negativeKey(name, rrtype), &cacheEntry{
+		RR:        &rr,
+		CreatedAt: c.clock.Now(),
+		Rcode:     rcode,
 	})
 }
 
+func (c *cache) setPrefetchFunc(fn func(dns.Question)) {
+	c.prefetch = fn
+}
+
+// lookup returns the entry for key, expired entries are removed
+func (c *cache) lookup(key string) (*cacheEntry, bool) {
+	entry, ok := c.cache.Get(key)
+	if !ok {
+		return nil, false
+	}
+
+	if entry.expired(c.clock.Now()) {
+		_ = c.cache.Remove(key)
+
+		c.stats.expirations.Add(1)
+
+		return nil, false
+	}
+
+	return entry, true
+}
+
+func (c *cache) add(key string, entry *cacheEntry) {
+	if c.cache.Add(key, entry) {
+		c.stats.evictions.Add(1)
+	}
+}
+
+// maybePrefetch refreshes a popular entry with less than a tenth of its
+// TTL left. An entry is refreshed once, the refreshed record replaces it.
+func (c *cache) maybePrefetch(entry *cacheEntry, name string, rrtype uint16) {
+	hits := entry.hits.Add(1)
+
+	if c.prefetch == nil || c.prefetchMinHits == 0 || hits < c.prefetchMinHits {
+		return
+	}
+
+	ttl := time.Duration(entry.RR.Header().Ttl) * time.Second
+	if ttl < minPrefetchTTL || entry.remaining(c.clock.Now()) > ttl/10 {
+		return
+	}
+
+	if !entry.prefetching.CompareAndSwap(false, true) {
+		return
+	}
+
+	c.stats.prefetches.Add(1)
+
+	go c.prefetch(dns.Question{Name: name, Qtype: rrtype, Qclass: dns.ClassINET})
+}
+
 // key generates the key for a record in the cache
 func (c *cache) key(name string, rrtype uint16) string {
 	return name + "_" + dns.TypeToString[rrtype]
 }
+
+// negativeKey generates the key for a negative answer in the cache, the
+// type of NXDOMAIN answers is dns.TypeNone
+func (c *cache) negativeKey(name string, rrtype uint16) string {
+	return name + "_" + dns.TypeToString[rrtype] + "_negative"
+}
@@ -23,6 +23,8 @@ import (
 	lru "github.com/hashicorp/golang-lru/v2"
 	"github.com/miekg/dns"
 	"github.com/stretchr/testify/assert"
+
+	"maas.io/core/src/maasgocommon/clock"
 )
 
 type key struct {
@@ -202,6 +204,7 @@ func TestCache_Get(t *testing.T) {
 			c := &cache{
 				cache: lcache,
 				stats: &cacheStats{},
+				clock: clock.New(),
 			}
 
 			rr, ok := c.Get(tc.in.key.name, tc.in.key.rrtype)
@@ -287,3 +290,180 @@ func TestCache_Set(t *testing.T) {
 		})
 	}
 }
+
+func TestCacheExpiry(t *testing.T) {
+	clk := clock.NewFake(time.Now())
+
+	cache, err := NewCache(WithCacheClock(clk))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cache.Set(createARecord("example.com", 60, "127.0.0.1"))
+
+	clk.Advance(59 * time.Second)
+
+	_, ok := cache.Get("example.com", dns.TypeA)
+	assert.True(t, ok)
+
+	clk.Advance(time.Second)
+
+	_, ok = cache.Get("example.com", dns.TypeA)
+	assert.False(t, ok)
+}
+
+func createSOARecord(name string, ttl, minttl uint32) *dns.SOA {
+	return &dns.SOA{
+		Hdr: dns.RR_Header{
+			Name:   name,
+			Rrtype: dns.TypeSOA,
+			Class:  dns.ClassINET,
+			Ttl:    ttl,
+		},
+		Ns:     "ns1." + name,
+		Mbox:   "hostmaster." + name,
+		Minttl: minttl,
+	}
+}
+
+func TestCacheNegative(t *testing.T) {
+	testcases := map[string]struct {
+		rcode   int
+		soa     *dns.SOA
+		lookups map[uint16]bool
+		ttl     uint32
+	}{
+		"nxdomain applies to every type": {
+			rcode:   dns.RcodeNameError,
+			soa:     createSOARecord("example.com.", 3600, 300),
+			lookups: map[uint16]bool{dns.TypeA: true, dns.TypeAAAA: true},
+			ttl:     300,
+		},
+		"nodata applies to the type": {
+			rcode:   dns.RcodeSuccess,
+			soa:     createSOARecord("example.com.", 60, 300),
+			lookups: map[uint16]bool{dns.TypeA: true, dns.TypeAAAA: false},
+			ttl:     60,
+		},
+		"capped": {
+			rcode:   dns.RcodeNameError,
+			soa:     createSOARecord("example.com.", 86400, 86400),
+			lookups: map[uint16]bool{dns.TypeA: true},
+			ttl:     3 * 3600,
+		},
+		"zero ttl is not cached": {
+			rcode:   dns.RcodeNameError,
+			soa:     createSOARecord("example.com.", 3600, 0),
+			lookups: map[uint16]bool{dns.TypeA: false},
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			cache, err := NewCache(WithCacheClock(clock.NewFake(time.Now())))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			cache.SetNegative("missing.example.com.", dns.TypeA, tc.rcode, tc.soa)
+
+			for rrtype, want := range tc.lookups {
+				rcode, soa, ok := cache.GetNegative("missing.example.com.", rrtype)
+
+				assert.Equal(t, want, ok, dns.TypeToString[rrtype])
+
+				if want {
+					assert.Equal(t, tc.rcode, rcode)
+					assert.Equal(t, tc.ttl, soa.Hdr.Ttl)
+					assert.Equal(t, tc.soa.Ns, soa.Ns)
+				}
+			}
+
+			// negative answers are not positive ones
+			_, ok := cache.Get("missing.example.com.", dns.TypeA)
+			assert.False(t, ok)
+		})
+	}
+}
+
+func TestCacheNegativeExpiry(t *testing.T) {
+	clk := clock.NewFake(time.Now())
+
+	cache, err := NewCache(WithCacheClock(clk))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cache.SetNegative("missing.example.com.", dns.TypeA, dns.RcodeNameError,
+		createSOARecord("example.com.", 3600, 60))
+
+	clk.Advance(50 * time.Second)
+
+	_, soa, ok := cache.GetNegative("missing.example.com.", dns.TypeA)
+	assert.True(t, ok)
+	assert.Equal(t, uint32(10), soa.Hdr.Ttl)
+
+	clk.Advance(10 * time.Second)
+
+	_, _, ok = cache.GetNegative("missing.example.com.", dns.TypeA)
+	assert.False(t, ok)
+}
+
+func TestCachePrefetch(t *testing.T) {
+	clk := clock.NewFake(time.Now())
+
+	c, err := NewCache(WithCacheClock(clk), WithPrefetch(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	prefetched := make(chan dns.Question, 2)
+
+	p, ok := c.(prefetcher)
+	if !ok {
+		t.Fatal("cache does not prefetch")
+	}
+
+	p.setPrefetchFunc(func(q dns.Question) {
+		prefetched <- q
+	})
+
+	c.Set(createARecord("example.com.", 60, "127.0.0.1"))
+
+	// Popular, but far from expiring.
+	c.Get("example.com.", dns.TypeA)
+	c.Get("example.com.", dns.TypeA)
+
+	clk.Advance(55 * time.Second)
+
+	// Prefetched once, however many hits follow.
+	c.Get("example.com.", dns.TypeA)
+	c.Get("example.com.", dns.TypeA)
+
+	select {
+	case q := <-prefetched:
+		assert.Equal(t, dns.Question{Name: "example.com.", Qtype: dns.TypeA, Qclass: dns.ClassINET}, q)
+	case <-time.After(5 * time.Second):
+		t.Fatal("record not prefetched")
+	}
+
+	assert.Empty(t, prefetched)
+}
+
+func TestCacheEvictions(t *testing.T) {
+	c, err := NewCache(WithMaxSize(maxRecordSize))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.Set(createARecord("example.com.", 60, "127.0.0.1"))
+	c.Set(createARecord("example.com.", 60, "127.0.0.2"))
+	c.Set(createARecord("www.example.com.", 60, "127.0.0.1"))
+
+	lc, ok := c.(*cache)
+	if !ok {
+		t.Fatal("unexpected cache type")
+	}
+
+	assert.Equal(t, int64(1), lc.stats.evictions.Load())
+}
@@ -0,0 +1,381 @@
+// Copyright (c) 2025 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"os"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// conformanceEnv enables TestConformance. It is set to conformanceNetns when
+// the test runs in its own network namespace.
+const (
+	conformanceEnv   = "TEST_RESOLVER_CONFORMANCE"
+	conformanceNetns = "netns"
+)
+
+// conformanceZone is served by the upstream server of the resolver.
+var conformanceZone = func() []dns.RR {
+	records := []string{
+		"example.com. 300 IN SOA ns.example.com. admin.example.com. 1 3600 600 86400 300",
+		"host.example.com. 300 IN A 10.0.0.1",
+		"host.example.com. 300 IN AAAA 2001:db8::1",
+		"alias.example.com. 300 IN CNAME host.example.com.",
+	}
+
+	// Large enough to be truncated over UDP without EDNS0.
+	for i := range 20 {
+		records = append(records, fmt.Sprintf(`big.example.com. 300 IN TXT "%02d %s"`, i, strings.Repeat("x", 60)))
+	}
+
+	rrs := make([]dns.RR, 0, len(records))
+
+	for _, record := range records {
+		rr, err := dns.NewRR(record)
+		if err != nil {
+			panic(err)
+		}
+
+		rrs = append(rrs, rr)
+	}
+
+	return rrs
+}()
+
+// referenceClient is a DNS client the resolver must interoperate with.
+type referenceClient struct {
+	name string
+	// args only print the header and the answer section, and query once.
+	args []string
+}
+
+var referenceClients = []referenceClient{
+	{name: "dig", args: []string{"+noall", "+comments", "+answer", "+time=2", "+tries=1"}},
+	{name: "kdig", args: []string{"+noall", "+header", "+answer", "+time=2", "+retry=0"}},
+}
+
+type conformanceVector struct {
+	args []string
+	// status is the expected RCODE, e.g. NOERROR.
+	status string
+	// flags must be set in the response, noFlags must not.
+	flags   []string
+	noFlags []string
+	// answer is the expected answer section, as "<type> <rdata>", unless
+	// only the number of answers is checked.
+	answer  []string
+	answers int
+}
+
+var conformanceVectors = map[string]conformanceVector{
+	"A": {
+		args:   []string{"host.example.com", "A"},
+		status: "NOERROR",
+		flags:  []string{"qr", "rd", "ra"},
+		answer: []string{"A 10.0.0.1"},
+	},
+	"AAAA over TCP": {
+		args:   []string{"+tcp", "host.example.com", "AAAA"},
+		status: "NOERROR",
+		answer: []string{"AAAA 2001:db8::1"},
+	},
+	"mixed case": {
+		args:   []string{"HoSt.ExAmPlE.cOm", "A"},
+		status: "NOERROR",
+		answer: []string{"A 10.0.0.1"},
+	},
+	"CNAME": {
+		args:   []string{"alias.example.com", "A"},
+		status: "NOERROR",
+		answer: []string{"CNAME host.example.com.", "A 10.0.0.1"},
+	},
+	"NXDOMAIN": {
+		args:   []string{"missing.example.com", "A"},
+		status: "NXDOMAIN",
+	},
+	"NODATA": {
+		args:   []string{"host.example.com", "MX"},
+		status: "NOERROR",
+		answer: []string{},
+	},
+	"truncated without EDNS0": {
+		args:   []string{"+noedns", "+ignore", "big.example.com", "TXT"},
+		status: "NOERROR",
+		flags:  []string{"tc"},
+	},
+	"retried over TCP": {
+		args:    []string{"+noedns", "big.example.com", "TXT"},
+		status:  "NOERROR",
+		noFlags: []string{"tc"},
+		answers: 20,
+	},
+	"EDNS0 buffer size": {
+		args:    []string{"+bufsize=4096", "+ignore", "big.example.com", "TXT"},
+		status:  "NOERROR",
+		noFlags: []string{"tc"},
+		answers: 20,
+	},
+}
+
+// TestConformance queries the resolver with reference DNS clients, dig and
+// kdig, to catch interoperability issues that tests of miekg/dns messages
+// miss. Clients that are not installed are skipped. The resolver and its
+// upstream server listen on port 53 in a network namespace of their own,
+// created with unshare(1).
+// TEST_RESOLVER_CONFORMANCE=true \
+// go test maas.io/core/src/maasagent/internal/resolver -run 'TestConformance' -count 1 -v
+func TestConformance(t *testing.T) {
+	switch os.Getenv(conformanceEnv) {
+	case "":
+		t.Skip("set " + conformanceEnv + " to run this test")
+	case conformanceNetns:
+	default:
+		runInNetns(t)
+		return
+	}
+
+	var clients []referenceClient
+
+	for _, client := range referenceClients {
+		if _, err := exec.LookPath(client.name); err == nil {
+			clients = append(clients, client)
+		}
+	}
+
+	if len(clients) == 0 {
+		t.Skip("no reference DNS client installed")
+	}
+
+	upstream := netip.MustParseAddr("127.0.0.1")
+	serveZone(t, upstream, conformanceZone)
+
+	// The upstream server is reached over TCP, so that only the exchanges
+	// between the clients and the resolver are over UDP.
+	handler := NewRecursiveHandler(noopCache{})
+	require.NoError(t, handler.SetUpstreams(systemConfig{
+		Nameservers: []netip.Addr{upstream},
+		UseTCP:      true,
+	}, nil))
+	t.Cleanup(handler.Close)
+
+	resolver := netip.MustParseAddr("127.0.0.2")
+
+	servers, err := NewResolverService(handler).serve(resolver)
+	require.NoError(t, err)
+	t.Cleanup(func() { assert.NoError(t, servers.Close()) })
+
+	for _, client := range clients {
+		for name, vector := range conformanceVectors {
+			t.Run(client.name+"/"+name, func(t *testing.T) {
+				ctx, cancel := context.WithTimeout(t.Context(), 10*time.Second)
+				defer cancel()
+
+				args := slices.Concat([]string{"@" + resolver.String()}, client.args, vector.args)
+
+				out, err := exec.CommandContext(ctx, client.name, args...).CombinedOutput()
+				require.NoError(t, err, string(out))
+
+				result, err := parseClientOutput(string(out))
+				require.NoError(t, err, string(out))
+
+				assert.Equal(t, vector.status, result.status, string(out))
+
+				for _, flag := range vector.flags {
+					assert.Contains(t, result.flags, flag, string(out))
+				}
+
+				for _, flag := range vector.noFlags {
+					assert.NotContains(t, result.flags, flag, string(out))
+				}
+
+				switch {
+				case vector.answer != nil:
+					assert.Equal(t, vector.answer, result.answer, string(out))
+				case vector.answers > 0:
+					assert.Len(t, result.answer, vector.answers, string(out))
+				}
+			})
+		}
+	}
+}
+
+// runInNetns runs TestConformance again in a new network namespace, where
+// binding port 53 needs no privileges.
+func runInNetns(t *testing.T) {
+	t.Helper()
+
+	unshare, err := exec.LookPath("unshare")
+	if err != nil {
+		t.Skip("unshare is required to create a network namespace")
+	}
+
+	//nolint:gosec // G204: runs the test binary itself
+	cmd := exec.CommandContext(t.Context(), unshare, "--net", "--map-root-user",
+		"sh", "-c", `ip link set lo up && exec "$@"`, "sh",
+		os.Args[0], "-test.run=^TestConformance$", "-test.count=1",
+		"-test.v="+strconv.FormatBool(testing.Verbose()))
+	cmd.Env = append(os.Environ(), conformanceEnv+"="+conformanceNetns)
+	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("conformance tests failed in a network namespace: %v", err)
+	}
+}
+
+// serveZone answers queries for the records of zone on port 53 of addr,
+// over UDP and TCP.
+func serveZone(t *testing.T, addr netip.Addr, zone []dns.RR) {
+	t.Helper()
+
+	handler := dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+		resp := new(dns.Msg)
+		resp.SetReply(r)
+		resp.Authoritative = true
+		resp.Answer, resp.Rcode = lookupZone(zone, r.Question[0])
+
+		if len(resp.Answer) == 0 {
+			resp.Ns = []dns.RR{zone[0]}
+		}
+
+		_ = w.WriteMsg(resp) //nolint:errcheck // the client times out
+	})
+
+	for _, network := range []string{"udp", "tcp"} {
+		server := &dns.Server{
+			Addr:    netip.AddrPortFrom(addr, 53).String(),
+			Net:     network,
+			Handler: handler,
+		}
+
+		started := make(chan struct{})
+		server.NotifyStartedFunc = func() { close(started) }
+
+		go func() {
+			if err := server.ListenAndServe(); err != nil {
+				t.Errorf("upstream %s server failed: %v", network, err)
+			}
+		}()
+
+		<-started
+
+		t.Cleanup(func() { _ = server.Shutdown() }) //nolint:errcheck // test
+	}
+}
+
+// lookupZone returns the records answering q, following CNAMEs, and the
+// RCODE of the answer.
+func lookupZone(zone []dns.RR, q dns.Question) ([]dns.RR, int) {
+	name := strings.ToLower(q.Name)
+
+	var (
+		answer []dns.RR
+		found  bool
+	)
+
+	for _, rr := range zone {
+		hdr := rr.Header()
+		if hdr.Name != name {
+			continue
+		}
+
+		found = true
+
+		if cname, ok := rr.(*dns.CNAME); ok && q.Qtype != dns.TypeCNAME {
+			target, _ := lookupZone(zone, dns.Question{Name: cname.Target, Qtype: q.Qtype})
+			answer = append(append(answer, rr), target...)
+
+			continue
+		}
+
+		if hdr.Rrtype == q.Qtype {
+			answer = append(answer, rr)
+		}
+	}
+
+	if !found {
+		return nil, dns.RcodeNameError
+	}
+
+	return answer, dns.RcodeSuccess
+}
+
+// clientResult is what a reference client printed about a response.
+type clientResult struct {
+	status string
+	flags  []string
+	// answer holds the answer section as "<type> <rdata>".
+	answer []string
+}
+
+var (
+	clientStatusRegexp = regexp.MustCompile(`status: ([A-Z]+)`)
+	clientFlagsRegexp  = regexp.MustCompile(`(?mi)^;; flags:([a-z ]*);`)
+)
+
+// parseClientOutput parses the output of dig and kdig, printing only the
+// header and the answer section.
+func parseClientOutput(out string) (clientResult, error) {
+	var result clientResult
+
+	result.answer = []string{}
+
+	status := clientStatusRegexp.FindAllStringSubmatch(out, -1)
+	if status == nil {
+		return result, fmt.Errorf("no status in output")
+	}
+
+	// After a retry over TCP, the last response is the one that counts.
+	result.status = status[len(status)-1][1]
+
+	if flags := clientFlagsRegexp.FindAllStringSubmatch(out, -1); flags != nil {
+		result.flags = strings.Fields(flags[len(flags)-1][1])
+	}
+
+	for line := range strings.Lines(out) {
+		line = strings.TrimSpace(line)
+		if strings.Contains(line, "->>HEADER<<-") {
+			result.answer = []string{}
+		}
+
+		if line == "" || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		rr, err := dns.NewRR(line)
+		if err != nil {
+			return result, fmt.Errorf("invalid answer %q: %w", line, err)
+		}
+
+		hdr := rr.Header()
+		rdata := strings.TrimSpace(strings.TrimPrefix(rr.String(), hdr.String()))
+		result.answer = append(result.answer, dns.TypeToString[hdr.Rrtype]+" "+rdata)
+	}
+
+	return result, nil
+}
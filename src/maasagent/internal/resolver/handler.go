@@ -27,11 +27,15 @@ import (
 	"os"
 	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/miekg/dns"
 	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
 	"maas.io/core/src/maasagent/internal/connpool"
+	"maas.io/core/src/maasgocommon/clock"
 )
 
 const (
@@ -70,8 +74,14 @@ type RecursiveHandler struct {
 	authoritativeServers []netip.Addr
 	systemConfig         systemConfig
 	sessions             sessions
+	clock                clock.Clock
 	stats                handlerStats
-	connPoolSize         int
+	queryLog             *QueryLog
+	queryDuration        metric.Float64Histogram
+	// refreshing holds the keys of the questions being prefetched, which
+	// must be answered by upstream servers rather than the cache
+	refreshing   sync.Map
+	connPoolSize int
 }
 
 // NewRecursiveHandler provides a constructor for a new handler for recursive queries
@@ -86,6 +96,7 @@ func NewRecursiveHandler(cache Cache, options ...RecursiveHandlerOption) *Recurs
 			m: make(map[netip.Addr]connpool.Pool),
 		},
 		stats:        handlerStats{},
+		clock:        clock.New(),
 		connPoolSize: defaultConnPoolSize,
 		recordCache:  cache,
 	}
@@ -94,6 +105,10 @@ func NewRecursiveHandler(cache Cache, options ...RecursiveHandlerOption) *Recurs
 		option(r)
 	}
 
+	if p, ok := cache.(prefetcher); ok {
+		p.setPrefetchFunc(r.prefetch)
+	}
+
 	return r
 }
 
@@ -135,7 +150,7 @@ func (h *RecursiveHandler) SetUpstreams(systemConfig systemConfig, authServers [
 
 // ClearExpiredSessions removes all expired sessions
 func (h *RecursiveHandler) ClearExpiredSessions() {
-	h.sessions.ClearExpired()
+	h.sessions.ClearExpired(h.clock.Now())
 }
 
 // Close closes all connections in the handler's connection pool
@@ -148,6 +163,22 @@ func (h *RecursiveHandler) Close() {
 func (h *RecursiveHandler) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
 	h.stats.queries.Add(1)
 
+	if h.queryLog != nil || h.queryDuration != nil {
+		rw := &recordingResponseWriter{ResponseWriter: w}
+		w = rw
+
+		questions := slices.Clone(r.Question)
+		start := h.clock.Now()
+
+		defer func() {
+			h.observeQuery(rw, questions, h.clock.Since(start))
+		}()
+	}
+
+	if _, ok := w.RemoteAddr().(*net.UDPAddr); ok {
+		w = &truncatingResponseWriter{ResponseWriter: w, size: udpResponseSize(r)}
+	}
+
 	ok := h.validateQuery(w, r)
 	if !ok {
 		h.stats.invalid.Add(1)
@@ -160,7 +191,7 @@ func (h *RecursiveHandler) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
 	remoteSession := h.getOrCreateSession(sessionKey, remoteAddr)
 
 	defer func() {
-		if remoteSession.expired(time.Now()) {
+		if remoteSession.expired(h.clock.Now()) {
 			h.sessions.Delete(sessionKey)
 		}
 	}()
@@ -184,6 +215,16 @@ func (h *RecursiveHandler) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
 			continue
 		}
 
+		if rcode, soa, ok := h.recordCache.GetNegative(q.Name, q.Qtype); ok {
+			r.Rcode = rcode
+
+			appendUniqueRR(uniqueNs, &r.Ns, []dns.RR{soa})
+
+			h.stats.fromCache.Add(1)
+
+			continue
+		}
+
 		var msg *dns.Msg
 
 		qstate := newQueryState(q.Name)
@@ -266,6 +307,8 @@ func (h *RecursiveHandler) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
 			return
 		}
 
+		h.cacheNegativeResponse(q, msg)
+
 		if msg.Rcode != dns.RcodeSuccess {
 			r.Rcode = msg.Rcode
 
@@ -291,6 +334,57 @@ func (h *RecursiveHandler) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
 	}
 }
 
+// recordingResponseWriter records the response written to a client
+type recordingResponseWriter struct {
+	dns.ResponseWriter
+	msg *dns.Msg
+}
+
+func (w *recordingResponseWriter) WriteMsg(m *dns.Msg) error {
+	w.msg = m
+
+	return w.ResponseWriter.WriteMsg(m)
+}
+
+// truncatingResponseWriter truncates UDP responses to the size the client
+// accepts, setting TC so that it retries over TCP
+type truncatingResponseWriter struct {
+	dns.ResponseWriter
+	size int
+}
+
+func (w *truncatingResponseWriter) WriteMsg(m *dns.Msg) error {
+	m.Truncate(w.size)
+
+	return w.ResponseWriter.WriteMsg(m)
+}
+
+// udpResponseSize returns the largest UDP response the client of r accepts:
+// the EDNS0 buffer size it advertised, or 512 bytes without EDNS0
+func udpResponseSize(r *dns.Msg) int {
+	if opt := r.IsEdns0(); opt != nil {
+		return max(int(opt.UDPSize()), dns.MinMsgSize)
+	}
+
+	return dns.MinMsgSize
+}
+
+// observeQuery logs and measures a query once it was answered
+func (h *RecursiveHandler) observeQuery(w *recordingResponseWriter, questions []dns.Question, latency time.Duration) {
+	if w.msg == nil {
+		return
+	}
+
+	if h.queryLog != nil {
+		h.queryLog.Log(w.RemoteAddr(), questions, w.msg.Rcode, latency)
+	}
+
+	if h.queryDuration != nil {
+		h.queryDuration.Record(context.Background(), float64(latency)/float64(time.Millisecond),
+			metric.WithAttributes(attribute.String("rcode", dns.RcodeToString[w.msg.Rcode])))
+	}
+}
+
 // appendUniqueRR is used to append RR to the result only if it was not met before.
 // Tracking of previous occurrences happens via map[string]struct{}
 func appendUniqueRR(seen map[string]struct{}, result *[]dns.RR, rrs []dns.RR) {
@@ -369,7 +463,7 @@ func (h *RecursiveHandler) handleAuthoritative(q dns.Question, remoteSession *se
 func (h *RecursiveHandler) getOrCreateSession(key string, remoteAddr net.Addr) *session {
 	remoteSession := h.sessions.Load(key)
 	if remoteSession == nil {
-		remoteSession = newSession(remoteAddr)
+		remoteSession = newSession(remoteAddr, h.clock.Now())
 
 		h.sessions.Store(key, remoteSession)
 	}
@@ -523,7 +617,7 @@ func (h *RecursiveHandler) resolveAuthoritativeArbitraryName(addrStr string) (ne
 	qstate := newQueryState(addrStr)
 
 	// create session for self, addr just needs to be something we wont see real traffic from
-	sess := newSession(&net.UDPAddr{IP: net.ParseIP("0.0.0.0"), Port: 0})
+	sess := newSession(&net.UDPAddr{IP: net.ParseIP("0.0.0.0"), Port: 0}, h.clock.Now())
 
 outerLoop:
 	for {
@@ -739,6 +833,10 @@ func (h *RecursiveHandler) fetchAnswer(server netip.Addr, r *dns.Msg) (*dns.Msg,
 	cachedMsg := r.Copy()
 
 	for i, q := range cachedMsg.Question {
+		if _, ok := h.refreshing.Load(questionKey(q)); ok {
+			continue
+		}
+
 		rr, ok := h.getCachedRecordForQuestion(q)
 		if !ok {
 			continue
@@ -824,6 +922,59 @@ func (h *RecursiveHandler) cacheResponse(msg *dns.Msg) {
 	}
 }
 
+// cacheNegativeResponse caches a NXDOMAIN or NODATA response to q, when its
+// authority section holds the SOA needed to know for how long (RFC 2308)
+func (h *RecursiveHandler) cacheNegativeResponse(q dns.Question, msg *dns.Msg) {
+	nodata := msg.Rcode == dns.RcodeSuccess && len(msg.Answer) == 0
+	if msg.Rcode != dns.RcodeNameError && !nodata {
+		return
+	}
+
+	for _, rr := range msg.Ns {
+		if soa, ok := rr.(*dns.SOA); ok {
+			h.recordCache.SetNegative(q.Name, q.Qtype, msg.Rcode, soa)
+
+			return
+		}
+	}
+}
+
+// prefetch refreshes the cached records answering q, querying the same
+// servers as ServeDNS would
+func (h *RecursiveHandler) prefetch(q dns.Question) {
+	key := questionKey(q)
+	if _, loaded := h.refreshing.LoadOrStore(key, struct{}{}); loaded {
+		return
+	}
+
+	defer h.refreshing.Delete(key)
+
+	qstate := newQueryState(q.Name)
+
+	nameserver, err := h.findRecursiveNS(qstate)
+	if err != nil && !errors.Is(err, ErrNoAnswer) {
+		log.Debug().Err(err).Msgf("Failed to prefetch %q %q", q.Name, dns.TypeToString[q.Qtype])
+
+		return
+	}
+
+	if nameserver != nil {
+		msg, err := h.authoritativeQuery(&dns.Msg{Question: []dns.Question{q}}, nameserver)
+		if err == nil && msg.Rcode == dns.RcodeSuccess && len(msg.Answer) > 0 {
+			return
+		}
+	}
+
+	if _, err := h.handleNonAuthoritative(q); err != nil {
+		log.Debug().Err(err).Msgf("Failed to prefetch %q %q", q.Name, dns.TypeToString[q.Qtype])
+	}
+}
+
+// questionKey identifies a question being prefetched
+func questionKey(q dns.Question) string {
+	return q.Name + "_" + dns.TypeToString[q.Qtype]
+}
+
 // getCachedRecordForQuestion checks the cache for a record corresponding to the given question
 func (h *RecursiveHandler) getCachedRecordForQuestion(q dns.Question) (dns.RR, bool) {
 	return h.recordCache.Get(q.Name, q.Qtype)
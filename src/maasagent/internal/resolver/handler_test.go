@@ -18,11 +18,13 @@ package resolver
 import (
 	"context"
 	"flag"
+	"fmt"
 	"io"
 	"net"
 	"net/netip"
 	"os"
 	"path"
+	"strings"
 	"testing"
 	"time"
 
@@ -56,6 +58,15 @@ func (m *mockResponseWriter) RemoteAddr() net.Addr {
 	return addr
 }
 
+// udpResponseWriter is a mockResponseWriter for a client over UDP.
+type udpResponseWriter struct {
+	mockResponseWriter
+}
+
+func (m *udpResponseWriter) RemoteAddr() net.Addr {
+	return &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 5353}
+}
+
 type mockConn struct {
 	net.Conn
 }
@@ -124,6 +135,12 @@ func (n noopCache) Get(_ string, _ uint16) (dns.RR, bool) {
 
 func (n noopCache) Set(_ dns.RR) {}
 
+func (n noopCache) GetNegative(_ string, _ uint16) (int, *dns.SOA, bool) {
+	return 0, nil, false
+}
+
+func (n noopCache) SetNegative(_ string, _ uint16, _ int, _ *dns.SOA) {}
+
 func TestMain(m *testing.M) {
 	flag.Parse()
 
@@ -482,6 +499,133 @@ func TestServeDNS_Cached(t *testing.T) {
 	}
 }
 
+func TestServeDNS_NegativeCached(t *testing.T) {
+	cache, _ := NewCache()
+
+	handler := NewRecursiveHandler(cache)
+	handler.SetUpstreams(systemConfig{
+		Nameservers: []netip.Addr{netip.MustParseAddr("127.0.0.1")},
+	}, nil)
+
+	question := dns.Question{Name: "missing.example.com.", Qtype: dns.TypeA, Qclass: dns.ClassINET}
+	soa := createSOARecord("example.com.", 3600, 300)
+
+	nxdomain := &dns.Msg{
+		MsgHdr:   dns.MsgHdr{Response: true, Rcode: dns.RcodeNameError},
+		Question: []dns.Question{question},
+		Ns:       []dns.RR{soa},
+	}
+
+	client := &mockClient{received: []*dns.Msg{nxdomain}}
+	handler.client = client
+
+	for range 2 {
+		responseWriter := &mockResponseWriter{}
+
+		handler.ServeDNS(responseWriter, &dns.Msg{
+			MsgHdr:   dns.MsgHdr{Id: 1, Opcode: dns.OpcodeQuery},
+			Question: []dns.Question{question},
+		})
+
+		require.Len(t, responseWriter.sent, 1)
+		assert.Equal(t, dns.RcodeNameError, responseWriter.sent[0].Rcode)
+	}
+
+	// The second query was answered from the cache, with the SOA.
+	assert.Len(t, client.sent, 1)
+
+	_, cached, ok := cache.GetNegative(question.Name, dns.TypeAAAA)
+	require.True(t, ok)
+	assert.Equal(t, soa.Ns, cached.Ns)
+}
+
+func TestServeDNS_Truncated(t *testing.T) {
+	question := dns.Question{Name: "big.example.com.", Qtype: dns.TypeTXT, Qclass: dns.ClassINET}
+
+	answer := &dns.Msg{
+		MsgHdr:   dns.MsgHdr{Response: true},
+		Question: []dns.Question{question},
+	}
+
+	for i := range 20 {
+		answer.Answer = append(answer.Answer, &dns.TXT{
+			Hdr: dns.RR_Header{Name: question.Name, Rrtype: dns.TypeTXT, Class: dns.ClassINET, Ttl: 30},
+			Txt: []string{fmt.Sprintf("%02d %s", i, strings.Repeat("x", 60))},
+		})
+	}
+
+	testcases := map[string]struct {
+		edns      uint16
+		truncated bool
+	}{
+		"without EDNS0": {truncated: true},
+		"small buffer":  {edns: 1024, truncated: true},
+		"large buffer":  {edns: 4096},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			handler := NewRecursiveHandler(noopCache{})
+			handler.SetUpstreams(systemConfig{
+				Nameservers: []netip.Addr{netip.MustParseAddr("127.0.0.1")},
+			}, nil)
+			handler.client = &mockClient{received: []*dns.Msg{answer.Copy()}}
+
+			query := &dns.Msg{
+				MsgHdr:   dns.MsgHdr{Id: 1, Opcode: dns.OpcodeQuery, RecursionDesired: true},
+				Question: []dns.Question{question},
+			}
+
+			if tc.edns > 0 {
+				query.SetEdns0(tc.edns, false)
+			}
+
+			w := &udpResponseWriter{}
+			handler.ServeDNS(w, query)
+
+			require.Len(t, w.sent, 1)
+
+			resp := w.sent[0]
+			assert.Equal(t, tc.truncated, resp.Truncated)
+			assert.LessOrEqual(t, resp.Len(), udpResponseSize(query))
+
+			if !tc.truncated {
+				assert.Len(t, resp.Answer, 20)
+			}
+		})
+	}
+}
+
+func TestPrefetch(t *testing.T) {
+	cache, _ := NewCache()
+
+	handler := NewRecursiveHandler(cache)
+	handler.SetUpstreams(systemConfig{
+		Nameservers: []netip.Addr{netip.MustParseAddr("127.0.0.1")},
+	}, nil)
+
+	cache.Set(createARecord("example.com.", 60, "127.0.0.1"))
+
+	question := dns.Question{Name: "example.com.", Qtype: dns.TypeA, Qclass: dns.ClassINET}
+	refreshed := createARecord("example.com.", 60, "127.0.0.2")
+
+	client := &mockClient{received: []*dns.Msg{{
+		MsgHdr:   dns.MsgHdr{Response: true},
+		Question: []dns.Question{question},
+		Answer:   []dns.RR{refreshed},
+	}}}
+	handler.client = client
+
+	// The cached record is ignored, the upstream server is queried.
+	handler.prefetch(question)
+
+	require.Len(t, client.sent, 1)
+
+	rr, ok := cache.Get("example.com.", dns.TypeA)
+	require.True(t, ok)
+	assert.Equal(t, refreshed, rr)
+}
+
 func FuzzServeDNSQuestion(f *testing.F) {
 	f.Add("example", uint16(5), uint16(1), "rdata")
 
@@ -35,9 +35,12 @@ type handlerStats struct {
 }
 
 type cacheStats struct {
-	hits        atomic.Int64
-	misses      atomic.Int64
-	expirations atomic.Int64
+	hits         atomic.Int64
+	negativeHits atomic.Int64
+	misses       atomic.Int64
+	expirations  atomic.Int64
+	evictions    atomic.Int64
+	prefetches   atomic.Int64
 }
 
 func must[T any](v T, err error) T {
@@ -79,6 +82,10 @@ func WithHandlerMetrics(meter metric.Meter) RecursiveHandlerOption {
 				return nil
 			})))
 
+		h.queryDuration = must(meter.Float64Histogram("resolver.query.duration",
+			metric.WithUnit("ms"),
+			metric.WithExplicitBucketBoundaries(1, 5, 10, 25, 50, 100, 250, 500, 1000, 5000)))
+
 		must(meter.Int64ObservableCounter("resolver.queries",
 			metric.WithUnit("{count}"),
 			metric.WithInt64Callback(func(_ context.Context, o metric.Int64Observer) error {
@@ -92,15 +99,21 @@ func WithHandlerMetrics(meter metric.Meter) RecursiveHandlerOption {
 func WithCacheMetrics(meter metric.Meter) CacheOption {
 	return func(c *cache) {
 		hits := attribute.String("type", "hits")
+		negativeHits := attribute.String("type", "negative_hits")
 		expirations := attribute.String("type", "expirations")
+		evictions := attribute.String("type", "evictions")
 		misses := attribute.String("type", "misses")
+		prefetches := attribute.String("type", "prefetches")
 
 		must(meter.Int64ObservableCounter("resolver.cache.usage",
 			metric.WithUnit("{count}"),
 			metric.WithInt64Callback(func(_ context.Context, o metric.Int64Observer) error {
 				o.Observe(c.stats.hits.Load(), metric.WithAttributes(hits))
+				o.Observe(c.stats.negativeHits.Load(), metric.WithAttributes(negativeHits))
 				o.Observe(c.stats.expirations.Load(), metric.WithAttributes(expirations))
+				o.Observe(c.stats.evictions.Load(), metric.WithAttributes(evictions))
 				o.Observe(c.stats.misses.Load(), metric.WithAttributes(misses))
+				o.Observe(c.stats.prefetches.Load(), metric.WithAttributes(prefetches))
 
 				return nil
 			})))
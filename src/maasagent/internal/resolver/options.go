@@ -19,6 +19,8 @@ import (
 	"time"
 
 	"github.com/miekg/dns"
+
+	"maas.io/core/src/maasgocommon/clock"
 )
 
 // WithConnPoolSize sets the number of connections for each
@@ -65,3 +67,17 @@ func WithUDPSize(size uint16) RecursiveHandlerOption {
 		client.UDPSize = size
 	}
 }
+
+// WithQueryLog logs the queries served by the handler to l
+func WithQueryLog(l *QueryLog) RecursiveHandlerOption {
+	return func(h *RecursiveHandler) {
+		h.queryLog = l
+	}
+}
+
+// WithHandlerClock sets the clock used to expire sessions
+func WithHandlerClock(clk clock.Clock) RecursiveHandlerOption {
+	return func(h *RecursiveHandler) {
+		h.clock = clk
+	}
+}
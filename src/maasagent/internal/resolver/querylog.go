@@ -0,0 +1,220 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package resolver
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// anonymizedIPv4Bits and anonymizedIPv6Bits are the prefix lengths of
+	// the networks logged instead of client addresses
+	anonymizedIPv4Bits = 24
+	anonymizedIPv6Bits = 48
+	// truncatedLabels is the number of labels kept of truncated names
+	truncatedLabels = 2
+	qnameHashSize   = 8
+)
+
+// QNameAnonymization selects how query names are written to the query log
+type QNameAnonymization string
+
+const (
+	// QNamePlain logs query names as is
+	QNamePlain QNameAnonymization = "plain"
+	// QNameHash logs a hash of query names, which is the same for a name
+	// until the agent restarts
+	QNameHash QNameAnonymization = "hash"
+	// QNameTruncate logs the last two labels of query names,
+	// e.g. *.example.com.
+	QNameTruncate QNameAnonymization = "truncate"
+)
+
+// QueryLogOption allows to set additional QueryLog options
+type QueryLogOption func(*QueryLog)
+
+// QueryLog writes a structured log entry for a sample of the queries
+// served by the resolver
+type QueryLog struct {
+	logger           zerolog.Logger
+	qnames           QNameAnonymization
+	salt             []byte
+	count            atomic.Uint64
+	sampleRate       float64
+	anonymizeClients bool
+}
+
+// NewQueryLog provides a constructor for a query log. By default every
+// query is logged with its client address and query name
+func NewQueryLog(options ...QueryLogOption) (*QueryLog, error) {
+	l := &QueryLog{
+		logger:     log.Logger,
+		qnames:     QNamePlain,
+		sampleRate: 1,
+	}
+
+	for _, option := range options {
+		option(l)
+	}
+
+	switch l.qnames {
+	case QNamePlain, QNameTruncate:
+	case QNameHash:
+		l.salt = make([]byte, sha256.Size)
+
+		//nolint:errcheck,gosec // rand.Read() never returns an error
+		rand.Read(l.salt)
+	default:
+		return nil, fmt.Errorf("unsupported query name anonymization %q", l.qnames)
+	}
+
+	return l, nil
+}
+
+// WithQueryLogger sets the logger entries are written to (default: the
+// global logger). Entries have no level, they are written whatever the
+// configured log level is
+func WithQueryLogger(logger zerolog.Logger) QueryLogOption {
+	return func(l *QueryLog) {
+		l.logger = logger
+	}
+}
+
+// WithSampleRate sets the fraction of queries that are logged, between 0
+// (exclusive) and 1 (default)
+func WithSampleRate(rate float64) QueryLogOption {
+	return func(l *QueryLog) {
+		if rate > 0 && rate <= 1 {
+			l.sampleRate = rate
+		}
+	}
+}
+
+// WithQNameAnonymization sets how query names are logged (default:
+// QNamePlain)
+func WithQNameAnonymization(mode QNameAnonymization) QueryLogOption {
+	return func(l *QueryLog) {
+		if mode != "" {
+			l.qnames = mode
+		}
+	}
+}
+
+// WithClientAnonymization logs the /24 (IPv4) or /48 (IPv6) network of
+// clients instead of their address
+func WithClientAnonymization(enabled bool) QueryLogOption {
+	return func(l *QueryLog) {
+		l.anonymizeClients = enabled
+	}
+}
+
+// Log logs a query answered with rcode after latency, if it is part of the
+// sample. Every question of the query is logged
+func (l *QueryLog) Log(client net.Addr, questions []dns.Question, rcode int, latency time.Duration) {
+	if !l.sampled() {
+		return
+	}
+
+	addr := l.client(client)
+
+	for _, q := range questions {
+		l.logger.Log().
+			Str("client", addr).
+			Str("qname", l.qname(q.Name)).
+			Str("qtype", dns.TypeToString[q.Qtype]).
+			Str("rcode", dns.RcodeToString[rcode]).
+			Dur("latency", latency).
+			Msg("DNS query")
+	}
+}
+
+// sampled reports whether the next query is logged. Queries are picked at
+// regular intervals, so that exactly the sample rate of them is logged
+func (l *QueryLog) sampled() bool {
+	n := l.count.Add(1)
+
+	return uint64(float64(n)*l.sampleRate) > uint64(float64(n-1)*l.sampleRate)
+}
+
+func (l *QueryLog) client(a net.Addr) string {
+	if a == nil {
+		return ""
+	}
+
+	var addr netip.Addr
+
+	switch a := a.(type) {
+	case *net.UDPAddr:
+		addr = a.AddrPort().Addr()
+	case *net.TCPAddr:
+		addr = a.AddrPort().Addr()
+	default:
+		var err error
+
+		addr, err = netip.ParseAddr(a.String())
+		if err != nil {
+			return a.String()
+		}
+	}
+
+	addr = addr.Unmap()
+
+	if !l.anonymizeClients {
+		return addr.String()
+	}
+
+	bits := anonymizedIPv6Bits
+	if addr.Is4() {
+		bits = anonymizedIPv4Bits
+	}
+
+	// bits is valid for the address family
+	prefix, _ := addr.WithZone("").Prefix(bits)
+
+	return prefix.String()
+}
+
+func (l *QueryLog) qname(name string) string {
+	switch l.qnames {
+	case QNameHash:
+		h := sha256.New()
+		h.Write(l.salt)
+		h.Write([]byte(strings.ToLower(dns.Fqdn(name))))
+
+		return hex.EncodeToString(h.Sum(nil)[:qnameHashSize])
+	case QNameTruncate:
+		labels := dns.SplitDomainName(name)
+		if len(labels) <= truncatedLabels {
+			return dns.Fqdn(name)
+		}
+
+		return "*." + dns.Fqdn(strings.Join(labels[len(labels)-truncatedLabels:], "."))
+	default:
+		return name
+	}
+}
@@ -0,0 +1,232 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package resolver
+
+import (
+	"bytes"
+	"encoding/json"
+	"net"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// enableLogging lifts the global level set by TestMain, which drops the
+// query log entries
+func enableLogging(t *testing.T) {
+	t.Helper()
+
+	level := zerolog.GlobalLevel()
+	zerolog.SetGlobalLevel(zerolog.TraceLevel)
+
+	t.Cleanup(func() { zerolog.SetGlobalLevel(level) })
+}
+
+func decodeQueryLog(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+
+	var entries []map[string]any
+
+	for line := range strings.SplitSeq(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+
+		var entry map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &entry))
+
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
+
+func TestQueryLog(t *testing.T) {
+	enableLogging(t)
+
+	var buf bytes.Buffer
+
+	l, err := NewQueryLog(WithQueryLogger(zerolog.New(&buf)))
+	require.NoError(t, err)
+
+	l.Log(&net.UDPAddr{IP: net.ParseIP("10.0.0.5"), Port: 5353}, []dns.Question{
+		{Name: "example.com.", Qtype: dns.TypeA},
+		{Name: "example.com.", Qtype: dns.TypeAAAA},
+	}, dns.RcodeNameError, 3*time.Millisecond)
+
+	assert.Equal(t, []map[string]any{
+		{
+			"client":  "10.0.0.5",
+			"qname":   "example.com.",
+			"qtype":   "A",
+			"rcode":   "NXDOMAIN",
+			"latency": float64(3),
+			"message": "DNS query",
+		},
+		{
+			"client":  "10.0.0.5",
+			"qname":   "example.com.",
+			"qtype":   "AAAA",
+			"rcode":   "NXDOMAIN",
+			"latency": float64(3),
+			"message": "DNS query",
+		},
+	}, decodeQueryLog(t, &buf))
+}
+
+func TestQueryLogSampling(t *testing.T) {
+	testcases := map[string]struct {
+		rate float64
+		out  int
+	}{
+		"all":      {rate: 1, out: 100},
+		"quarter":  {rate: 0.25, out: 25},
+		"third":    {rate: 1.0 / 3, out: 33},
+		"zero":     {rate: 0, out: 100},
+		"too high": {rate: 2, out: 100},
+	}
+
+	enableLogging(t)
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			l, err := NewQueryLog(WithQueryLogger(zerolog.New(&buf)), WithSampleRate(tc.rate))
+			require.NoError(t, err)
+
+			for range 100 {
+				l.Log(nil, []dns.Question{{Name: "example.com.", Qtype: dns.TypeA}}, dns.RcodeSuccess, 0)
+			}
+
+			assert.Len(t, decodeQueryLog(t, &buf), tc.out)
+		})
+	}
+}
+
+func TestQueryLogClient(t *testing.T) {
+	testcases := map[string]struct {
+		in        net.Addr
+		anonymize bool
+		out       string
+	}{
+		"ipv4": {
+			in:  &net.UDPAddr{IP: net.ParseIP("10.0.0.5"), Port: 53},
+			out: "10.0.0.5",
+		},
+		"ipv4 anonymized": {
+			in:        &net.UDPAddr{IP: net.ParseIP("10.0.0.5"), Port: 53},
+			anonymize: true,
+			out:       "10.0.0.0/24",
+		},
+		"ipv4-mapped anonymized": {
+			in:        net.TCPAddrFromAddrPort(netip.MustParseAddrPort("[::ffff:10.0.0.5]:53")),
+			anonymize: true,
+			out:       "10.0.0.0/24",
+		},
+		"ipv6 anonymized": {
+			in:        &net.UDPAddr{IP: net.ParseIP("2001:db8:1:2::5"), Port: 53},
+			anonymize: true,
+			out:       "2001:db8:1::/48",
+		},
+		"link-local anonymized": {
+			in:        &net.UDPAddr{IP: net.ParseIP("fe80::5"), Port: 53, Zone: "eth0"},
+			anonymize: true,
+			out:       "fe80::/48",
+		},
+		"ip": {
+			in:  &net.IPAddr{IP: net.ParseIP("10.0.0.5")},
+			out: "10.0.0.5",
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			l, err := NewQueryLog(WithClientAnonymization(tc.anonymize))
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.out, l.client(tc.in))
+		})
+	}
+}
+
+func TestQueryLogQName(t *testing.T) {
+	truncated, err := NewQueryLog(WithQNameAnonymization(QNameTruncate))
+	require.NoError(t, err)
+
+	assert.Equal(t, "*.example.com.", truncated.qname("host.sub.example.com."))
+	assert.Equal(t, "example.com.", truncated.qname("example.com."))
+	assert.Equal(t, "com.", truncated.qname("com."))
+
+	hashed, err := NewQueryLog(WithQNameAnonymization(QNameHash))
+	require.NoError(t, err)
+
+	hash := hashed.qname("host.example.com.")
+	assert.Len(t, hash, 2*qnameHashSize)
+	assert.NotContains(t, hash, "example")
+	assert.Equal(t, hash, hashed.qname("HOST.example.com"))
+	assert.NotEqual(t, hash, hashed.qname("other.example.com."))
+
+	other, err := NewQueryLog(WithQNameAnonymization(QNameHash))
+	require.NoError(t, err)
+
+	// salted per query log
+	assert.NotEqual(t, hash, other.qname("host.example.com."))
+
+	_, err = NewQueryLog(WithQNameAnonymization("rot13"))
+	assert.Error(t, err)
+}
+
+func TestServeDNS_QueryLog(t *testing.T) {
+	enableLogging(t)
+
+	var buf bytes.Buffer
+
+	queryLog, err := NewQueryLog(WithQueryLogger(zerolog.New(&buf)))
+	require.NoError(t, err)
+
+	handler := NewRecursiveHandler(noopCache{}, WithQueryLog(queryLog))
+	handler.SetUpstreams(systemConfig{
+		Nameservers: []netip.Addr{netip.MustParseAddr("127.0.0.1")},
+	}, nil)
+
+	question := dns.Question{Name: "www.example.com.", Qtype: dns.TypeA, Qclass: dns.ClassINET}
+
+	handler.client = &mockClient{received: []*dns.Msg{{
+		MsgHdr:   dns.MsgHdr{Response: true},
+		Question: []dns.Question{question},
+		Answer:   []dns.RR{createARecord("www.example.com.", 60, "10.0.0.1")},
+	}}}
+
+	handler.ServeDNS(&mockResponseWriter{}, &dns.Msg{
+		MsgHdr:   dns.MsgHdr{Id: 1, Opcode: dns.OpcodeQuery},
+		Question: []dns.Question{question},
+	})
+
+	entries := decodeQueryLog(t, &buf)
+	require.Len(t, entries, 1)
+
+	assert.Equal(t, "127.0.0.1", entries[0]["client"])
+	assert.Equal(t, "www.example.com.", entries[0]["qname"])
+	assert.Equal(t, "A", entries[0]["qtype"])
+	assert.Equal(t, "NOERROR", entries[0]["rcode"])
+}
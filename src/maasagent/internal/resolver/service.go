@@ -18,17 +18,21 @@ package resolver
 import (
 	"context"
 	"errors"
+	"io"
 	"net"
 	"net/netip"
 	"time"
 
 	"github.com/miekg/dns"
 	tworkflow "go.temporal.io/sdk/workflow"
+	"maas.io/core/src/maasagent/internal/listener"
 	"maas.io/core/src/maasagent/internal/workflow"
 )
 
 const (
 	defaultResolvConfPath = "/etc/resolv.conf"
+	// listenerName is the name of the resolver in the listener.Manager.
+	listenerName = "resolver"
 )
 
 var (
@@ -52,8 +56,9 @@ type ResolverService struct {
 	// in order to not conflict with systemd-resolved, we need
 	// to listen on all other IPs in subnets with DNS enabled,
 	// so for each IP the resolver is bound to we create a
-	// TCP listener or UDP connection
-	frontendServers []*dns.Server
+	// TCP listener and UDP connection, as the IP appears
+	listeners *listener.Manager
+	running   bool
 }
 
 type ResolverServiceOption func(*ResolverService)
@@ -75,6 +80,7 @@ func NewResolverService(handler Handler, options ...ResolverServiceOption) *Reso
 		fatal:          make(chan error),
 		resolvConfPath: defaultResolvConfPath,
 		sessionTicker:  time.NewTicker(sessionTTL),
+		listeners:      listener.NewManager(),
 	}
 
 	for _, opt := range options {
@@ -91,6 +97,14 @@ func WithResolvConf(path string) ResolverServiceOption {
 	}
 }
 
+// WithListenerManager sets the Manager binding the resolver to the addresses
+// of the host, shared by the services of the agent.
+func WithListenerManager(m *listener.Manager) ResolverServiceOption {
+	return func(s *ResolverService) {
+		s.listeners = m
+	}
+}
+
 func (s *ResolverService) ConfigurationWorkflows() map[string]any {
 	return map[string]any{"configure-resolver-service": s.configure}
 }
@@ -122,7 +136,7 @@ func (s *ResolverService) configure(ctx tworkflow.Context, systemID string) erro
 	if !resolverConfigResult.Enabled {
 		log.Info("resolver-service is not enabled")
 
-		if len(s.frontendServers) > 0 {
+		if s.running {
 			return workflow.RunAsLocalActivity(ctx, func(ctx context.Context) error {
 				return s.stop(ctx)
 			})
@@ -155,53 +169,23 @@ func (s *ResolverService) configure(ctx tworkflow.Context, systemID string) erro
 			return err
 		}
 
-		if len(s.frontendServers) > 0 {
-			if err := s.stop(ctx); err != nil {
-				return err
-			}
-		}
-
-		for _, ipStr := range s.bindIPs {
-			var (
-				tcpNet string
-				udpNet string
-			)
-
-			ip := net.ParseIP(ipStr)
-
-			if ip == nil {
+		bindAddrs := make([]netip.Addr, len(s.bindIPs))
+		for i, ipStr := range s.bindIPs {
+			bindAddrs[i], err = netip.ParseAddr(ipStr)
+			if err != nil {
 				return ErrInvalidBindIP
 			}
+		}
 
-			if ip4 := ip.To4(); ip4 != nil {
-				ip = ip4
-				tcpNet = "tcp4"
-				udpNet = "udp4"
-			} else {
-				tcpNet = "tcp6"
-				udpNet = "udp6"
-			}
-
-			tcpServer := &dns.Server{
-				Addr:    net.JoinHostPort(ip.String(), "53"),
-				Net:     tcpNet,
-				Handler: s.handler,
-			}
-
-			udpServer := &dns.Server{
-				Addr:    net.JoinHostPort(ip.String(), "53"),
-				Net:     udpNet,
-				Handler: s.handler,
-			}
-
-			s.frontendServers = append(s.frontendServers, tcpServer, udpServer)
+		if !s.running {
+			s.running = true
 
-			go func() { s.fatal <- tcpServer.ListenAndServe() }()
-			go func() { s.fatal <- udpServer.ListenAndServe() }()
 			go s.sessionGC()
 		}
 
-		return nil
+		// Listeners on addresses that are still wanted are kept, missing
+		// addresses are bound once they appear.
+		return s.listeners.Bind(listenerName, s.serve, bindAddrs)
 	}); err != nil {
 		return err
 	}
@@ -211,14 +195,12 @@ func (s *ResolverService) configure(ctx tworkflow.Context, systemID string) erro
 	return nil
 }
 
-func (s *ResolverService) stop(ctx context.Context) error {
-	for _, server := range s.frontendServers {
-		if err := server.ShutdownContext(ctx); err != nil {
-			return err
-		}
+func (s *ResolverService) stop(_ context.Context) error {
+	if err := s.listeners.Unbind(listenerName); err != nil {
+		return err
 	}
 
-	s.frontendServers = []*dns.Server{}
+	s.running = false
 
 	s.sessionTicker.Stop()
 
@@ -227,6 +209,79 @@ func (s *ResolverService) stop(ctx context.Context) error {
 	return nil
 }
 
+// serve starts the TCP and UDP frontend servers on addr.
+func (s *ResolverService) serve(addr netip.Addr) (io.Closer, error) {
+	hostPort := netip.AddrPortFrom(addr, 53).String()
+
+	network := "6"
+	if addr.Is4() {
+		network = "4"
+	}
+
+	l, err := net.Listen("tcp"+network, hostPort)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := net.ListenPacket("udp"+network, hostPort)
+	if err != nil {
+		return nil, errors.Join(err, l.Close())
+	}
+
+	tcpServer := &dns.Server{Listener: l, Handler: s.handler}
+	udpServer := &dns.Server{PacketConn: conn, Handler: s.handler}
+
+	if err := s.activate(tcpServer); err != nil {
+		return nil, errors.Join(err, l.Close(), conn.Close())
+	}
+
+	if err := s.activate(udpServer); err != nil {
+		return nil, errors.Join(err, tcpServer.Shutdown(), conn.Close())
+	}
+
+	return frontendServers{tcpServer, udpServer}, nil
+}
+
+// activate serves in the background and returns once server started.
+func (s *ResolverService) activate(server *dns.Server) error {
+	started := make(chan struct{})
+	failed := make(chan error, 1)
+
+	server.NotifyStartedFunc = func() { close(started) }
+
+	go func() {
+		err := server.ActivateAndServe()
+
+		select {
+		case <-started:
+			if err != nil {
+				s.fatal <- err
+			}
+		default:
+			failed <- err
+		}
+	}()
+
+	select {
+	case <-started:
+		return nil
+	case err := <-failed:
+		return err
+	}
+}
+
+// frontendServers are the servers bound to one address.
+type frontendServers []*dns.Server
+
+func (servers frontendServers) Close() error {
+	var errs []error
+	for _, server := range servers {
+		errs = append(errs, server.Shutdown())
+	}
+
+	return errors.Join(errs...)
+}
+
 func (s *ResolverService) Error() error {
 	return <-s.fatal
 }
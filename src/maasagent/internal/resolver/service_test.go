@@ -17,13 +17,16 @@ package resolver
 
 import (
 	"context"
+	"errors"
 	"net"
 	"net/netip"
+	"os"
 	"testing"
 
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"go.temporal.io/sdk/activity"
 	"go.temporal.io/sdk/testsuite"
 	"maas.io/core/src/maasagent/internal/workflow/log"
@@ -92,3 +95,20 @@ func TestConfigurationWorkflow(t *testing.T) {
 		})
 	}
 }
+
+func TestServeRebind(t *testing.T) {
+	svc := NewResolverService(&mockHandler{})
+	addr := netip.MustParseAddr("127.0.0.1")
+
+	// Closing the servers must release the address, so that it can be bound
+	// again when it reappears.
+	for range 2 {
+		servers, err := svc.serve(addr)
+		if errors.Is(err, os.ErrPermission) {
+			t.Skip("binding port 53 requires privileges")
+		}
+
+		require.NoError(t, err)
+		assert.NoError(t, servers.Close())
+	}
+}
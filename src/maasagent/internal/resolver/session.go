@@ -45,12 +45,12 @@ type session struct {
 }
 
 // newSession creates a *session to track query chains for
-// a given remote address
-func newSession(remoteAddr net.Addr) *session {
+// a given remote address, starting at createdAt
+func newSession(remoteAddr net.Addr, createdAt time.Time) *session {
 	return &session{
 		remoteAddr: remoteAddr,
 		chain:      []byte{},
-		createdAt:  time.Now(),
+		createdAt:  createdAt,
 	}
 }
 
@@ -231,10 +231,8 @@ func (s *sessions) Delete(key string) {
 	delete(s.m, key)
 }
 
-// ClearExpired removes all expired sessions
-func (s *sessions) ClearExpired() {
-	now := time.Now()
-
+// ClearExpired removes all sessions expired at now
+func (s *sessions) ClearExpired(now time.Time) {
 	s.lock.Lock()
 	defer s.lock.Unlock()
 
@@ -27,7 +27,7 @@ import (
 )
 
 func TestSession_StringNil(t *testing.T) {
-	s := newSession(nil)
+	s := newSession(nil, time.Now())
 	assert.Equal(t, s.String(), "")
 }
 
@@ -63,7 +63,7 @@ func TestSession_String(t *testing.T) {
 				&net.UDPAddr{IP: net.IP(addr.AsSlice()), Port: 53, Zone: addr.Zone()},
 			}
 			for _, a := range netaddr {
-				s := newSession(a)
+				s := newSession(a, time.Now())
 				assert.Equal(t, s.String(), fmt.Sprintf(tc.out, a.Network()))
 			}
 		})
@@ -259,7 +259,7 @@ func TestSession_StoreName(t *testing.T) {
 		tc := tc
 
 		t.Run(name, func(t *testing.T) {
-			s := newSession(&net.UDPAddr{IP: net.ParseIP("10.0.0.1"), Port: 53})
+			s := newSession(&net.UDPAddr{IP: net.ParseIP("10.0.0.1"), Port: 53}, time.Now())
 
 			s.chain = tc.in.generateChain()
 
@@ -319,7 +319,7 @@ func TestSession_NameAlreadyQueried(t *testing.T) {
 		tc := tc
 
 		t.Run(name, func(t *testing.T) {
-			s := newSession(&net.UDPAddr{IP: net.ParseIP("10.0.0.1"), Port: 53})
+			s := newSession(&net.UDPAddr{IP: net.ParseIP("10.0.0.1"), Port: 53}, time.Now())
 
 			name, err := s.format(tc.in.name)
 			if err != nil {
@@ -340,8 +340,7 @@ func TestSession_NameAlreadyQueried(t *testing.T) {
 func TestSession_Expired(t *testing.T) {
 	expiredEquals := func(createdAt time.Time, timePassed time.Duration, want bool) func(t *testing.T) {
 		return func(t *testing.T) {
-			s := newSession(&net.UDPAddr{IP: net.ParseIP("10.0.0.1"), Port: 53})
-			s.createdAt = createdAt
+			s := newSession(&net.UDPAddr{IP: net.ParseIP("10.0.0.1"), Port: 53}, createdAt)
 
 			timePassed := createdAt.Add(timePassed)
 
@@ -356,7 +355,7 @@ func TestSession_Expired(t *testing.T) {
 }
 
 func BenchmarkSession_Contains(b *testing.B) {
-	s := newSession(&net.UDPAddr{IP: net.ParseIP("10.0.0.1"), Port: 53})
+	s := newSession(&net.UDPAddr{IP: net.ParseIP("10.0.0.1"), Port: 53}, time.Now())
 	s.chain = []byte{
 		0x03, 'f', 'o', 'o',
 		0x07, 'e', 'x', 'a', 'm', 'p', 'l', 'e',
@@ -374,7 +373,7 @@ func BenchmarkSession_Contains(b *testing.B) {
 }
 
 func BenchmarkSession_Add(b *testing.B) {
-	s := newSession(&net.UDPAddr{IP: net.ParseIP("10.0.0.1"), Port: 53})
+	s := newSession(&net.UDPAddr{IP: net.ParseIP("10.0.0.1"), Port: 53}, time.Now())
 	s.chain = []byte{
 		0x03, 'f', 'o', 'o',
 		0x07, 'e', 'x', 'a', 'm', 'p', 'l', 'e',
@@ -18,8 +18,9 @@ package servicecontroller
 import (
 	"context"
 	"fmt"
-	"os/exec"
 	"strings"
+
+	"maas.io/core/src/maasagent/internal/execx"
 )
 
 const systemctlBin = "/bin/systemctl"
@@ -29,24 +30,30 @@ type client interface {
 	CombinedOutputSystemctlCommand(context.Context, ...string) (string, error)
 }
 
-type systemdClient struct{}
+type systemdClient struct {
+	runner execx.Runner
+}
 
 func (s *systemdClient) OutputSystemctlCommand(ctx context.Context, args ...string) (string, error) {
-	args = append([]string{systemctlBin}, args...)
-	cmd := exec.CommandContext(ctx, "sudo", args...)
-
-	out, err := cmd.Output()
+	res, err := s.runner.Run(ctx, execx.Command("sudo", append([]string{systemctlBin}, args...)...))
+	if res == nil {
+		return "", err
+	}
 
-	return string(out), err
+	return string(res.Stdout), err
 }
 
 func (s *systemdClient) CombinedOutputSystemctlCommand(ctx context.Context, args ...string) (string, error) {
-	args = append([]string{systemctlBin}, args...)
-	cmd := exec.CommandContext(ctx, "sudo", args...)
-
-	out, err := cmd.CombinedOutput()
+	res, err := s.runner.Run(ctx, &execx.Cmd{
+		Name:     "sudo",
+		Args:     append([]string{systemctlBin}, args...),
+		Combined: true,
+	})
+	if res == nil {
+		return "", err
+	}
 
-	return string(out), err
+	return string(res.Stdout), err
 }
 
 type SystemdController struct {
@@ -56,7 +63,7 @@ type SystemdController struct {
 
 func NewSystemdController(service string) *SystemdController {
 	return &SystemdController{
-		client: &systemdClient{},
+		client: &systemdClient{runner: execx.New()},
 		unit:   service,
 	}
 }
@@ -22,7 +22,6 @@ import (
 	"os"
 	"time"
 
-	"github.com/cenkalti/backoff/v4"
 	"github.com/rs/zerolog/log"
 	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/trace"
@@ -32,6 +31,8 @@ import (
 	"go.temporal.io/sdk/interceptor"
 	wflog "maas.io/core/src/maasagent/internal/workflow/log"
 	"maas.io/core/src/maasagent/pkg/workflow/codec"
+	maaserrors "maas.io/core/src/maasgocommon/errors"
+	"maas.io/core/src/maasgocommon/retry"
 )
 
 // ClientConfig used to configure Temporal client
@@ -63,9 +64,6 @@ func NewClient(ctx context.Context,
 		temporalotel.MetricsHandlerOptions{Meter: config.Meter},
 	)
 
-	retry := backoff.NewExponentialBackOff()
-	retry.MaxElapsedTime = 60 * time.Second
-
 	tracingInterceptor, err := temporalotel.NewTracingInterceptor(
 		temporalotel.TracerOptions{Tracer: config.Tracer},
 	)
@@ -73,7 +71,7 @@ func NewClient(ctx context.Context,
 		return nil, fmt.Errorf("failed setting up tracing interceptor: %w", err)
 	}
 
-	connect := func() (client.Client, error) {
+	connect := func(ctx context.Context) (client.Client, error) {
 		return client.DialContext(ctx, client.Options{
 			HostPort:     config.Endpoint,
 			Identity:     fmt.Sprintf("%s@agent:%d", config.SystemID, os.Getpid()),
@@ -90,5 +88,12 @@ func NewClient(ctx context.Context,
 		})
 	}
 
-	return backoff.RetryWithData(connect, retry)
+	return retry.DoWithData(ctx, connect,
+		retry.WithMaxElapsedTime(60*time.Second),
+		// Rejected credentials won't be accepted on the next attempt either.
+		retry.WithRetryIf(maaserrors.Retryable),
+		retry.WithNotify(func(a retry.Attempt) {
+			log.Warn().Err(a.Err).Msgf("Failed to connect to Temporal, retrying in %s", a.Delay)
+		}),
+	)
 }
@@ -0,0 +1,129 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package exectest provides an execx.Runner recording the commands it is
+// asked to run instead of executing them.
+package exectest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"sync"
+
+	"maas.io/core/src/maasagent/internal/execx"
+)
+
+// Call is a command run through a Recorder.
+type Call struct {
+	Sandbox *execx.Sandbox
+	Name    string
+	Args    []string
+	Env     []string
+	Stdin   []byte
+}
+
+type response struct {
+	result *execx.Result
+	err    error
+}
+
+// Recorder is an execx.Runner recording calls and replying with queued
+// responses, an empty result once there are none left.
+type Recorder struct {
+	// Paths maps executables to the path returned by LookPath. When nil,
+	// LookPath returns the name it is given.
+	Paths     map[string]string
+	calls     []Call
+	responses []response
+	mu        sync.Mutex
+}
+
+// NewRecorder returns an empty Recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// Respond queues the result of the next command not replied to yet.
+func (r *Recorder) Respond(result *execx.Result, err error) *Recorder {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.responses = append(r.responses, response{result: result, err: err})
+
+	return r
+}
+
+// RespondOutput queues a successful result with the given stdout.
+func (r *Recorder) RespondOutput(stdout string) *Recorder {
+	return r.Respond(&execx.Result{Stdout: []byte(stdout)}, nil)
+}
+
+// Calls returns the commands run so far.
+func (r *Recorder) Calls() []Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]Call(nil), r.calls...)
+}
+
+// Run implements execx.Runner.
+func (r *Recorder) Run(ctx context.Context, cmd *execx.Cmd) (*execx.Result, error) {
+	call := Call{Name: cmd.Name, Args: cmd.Args, Env: cmd.Env, Sandbox: cmd.Sandbox}
+
+	if cmd.Stdin != nil {
+		data, err := io.ReadAll(cmd.Stdin)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read stdin of %q: %w", cmd, err)
+		}
+
+		call.Stdin = data
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.calls = append(r.calls, call)
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(r.responses) == 0 {
+		return &execx.Result{}, nil
+	}
+
+	resp := r.responses[0]
+	r.responses = r.responses[1:]
+
+	return resp.result, resp.err
+}
+
+// LookPath implements execx.Runner.
+func (r *Recorder) LookPath(file string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.Paths == nil {
+		return file, nil
+	}
+
+	if path, ok := r.Paths[file]; ok {
+		return path, nil
+	}
+
+	return "", &exec.Error{Name: file, Err: exec.ErrNotFound}
+}
@@ -0,0 +1,367 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package vcr provides an http.RoundTripper which records HTTP interactions
+// to a cassette file and replays them later, so tests of code talking to
+// the region API (or any other HTTP service, e.g. LXD or a BMC) can run
+// deterministically and offline.
+//
+// Cassettes are replayed by default. Set VCR_MODE=record to (re)record them
+// against real services. Secrets are redacted before anything is written.
+package vcr
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Mode defines whether a Recorder records or replays interactions.
+type Mode int
+
+const (
+	// ModeReplay serves responses from the cassette and never hits the network.
+	ModeReplay Mode = iota
+	// ModeRecord forwards requests to the real transport and stores them.
+	ModeRecord
+)
+
+// Redacted replaces secret values in recorded interactions.
+const Redacted = "[REDACTED]"
+
+// ErrNoInteraction is returned in ModeReplay when a request does not match
+// any interaction left in the cassette.
+var ErrNoInteraction = errors.New("no matching interaction in cassette")
+
+var defaultRedactedHeaders = []string{
+	"Authorization",
+	"Cookie",
+	"Proxy-Authorization",
+	"Set-Cookie",
+	"X-Auth-Token",
+}
+
+var defaultRedactedFields = []string{
+	"password",
+	"secret",
+	"token",
+}
+
+// Recorder is an http.RoundTripper recording or replaying interactions.
+type Recorder struct {
+	transport http.RoundTripper
+	cassette  *Cassette
+	headers   []string
+	fields    []string
+	path      string
+	mode      Mode
+	mutex     sync.Mutex
+}
+
+// Option allows to set additional Recorder options
+type Option func(*Recorder)
+
+// WithMode overrides the mode selected with VCR_MODE.
+func WithMode(mode Mode) Option {
+	return func(r *Recorder) {
+		r.mode = mode
+	}
+}
+
+// WithTransport sets the transport used to reach real services in
+// ModeRecord (default: http.DefaultTransport)
+func WithTransport(transport http.RoundTripper) Option {
+	return func(r *Recorder) {
+		r.transport = transport
+	}
+}
+
+// WithRedactedHeaders adds header names whose values are redacted.
+func WithRedactedHeaders(headers ...string) Option {
+	return func(r *Recorder) {
+		for _, h := range headers {
+			r.headers = append(r.headers, http.CanonicalHeaderKey(h))
+		}
+	}
+}
+
+// WithRedactedFields adds names of query parameters and JSON object keys
+// (at any depth of request and response bodies) whose values are redacted.
+func WithRedactedFields(fields ...string) Option {
+	return func(r *Recorder) {
+		for _, f := range fields {
+			r.fields = append(r.fields, strings.ToLower(f))
+		}
+	}
+}
+
+// New returns a Recorder backed by the cassette at path. In ModeReplay the
+// cassette must exist, in ModeRecord it is created (or overwritten) by Stop.
+func New(path string, options ...Option) (*Recorder, error) {
+	r := &Recorder{
+		transport: http.DefaultTransport,
+		cassette:  &Cassette{Version: cassetteVersion},
+		headers:   slices.Clone(defaultRedactedHeaders),
+		fields:    slices.Clone(defaultRedactedFields),
+		path:      path,
+	}
+
+	if os.Getenv("VCR_MODE") == "record" {
+		r.mode = ModeRecord
+	}
+
+	for _, opt := range options {
+		opt(r)
+	}
+
+	if r.mode == ModeReplay {
+		c, err := loadCassette(path)
+		if err != nil {
+			return nil, err
+		}
+
+		r.cassette = c
+	}
+
+	return r, nil
+}
+
+// Mode returns the mode the Recorder operates in.
+func (r *Recorder) Mode() Mode {
+	return r.mode
+}
+
+// Client returns an *http.Client using the Recorder as its transport.
+func (r *Recorder) Client() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// Stop saves the cassette when recording. It is a no-op in ModeReplay.
+func (r *Recorder) Stop() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.cassette.save(r.path)
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	recorded, err := r.recordRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.mode == ModeReplay {
+		return r.replay(req, recorded)
+	}
+
+	return r.record(req, recorded)
+}
+
+func (r *Recorder) replay(req *http.Request, recorded Request) (*http.Response, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for i := range r.cassette.Interactions {
+		interaction := &r.cassette.Interactions[i]
+		if interaction.used || !matches(interaction.Request, recorded) {
+			continue
+		}
+
+		interaction.used = true
+
+		return interaction.Response.toHTTP(req), nil
+	}
+
+	return nil, fmt.Errorf("%w: %s %s", ErrNoInteraction, recorded.Method, recorded.URL)
+}
+
+func (r *Recorder) record(req *http.Request, recorded Request) (*http.Response, error) {
+	resp, err := r.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if closeErr := resp.Body.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.cassette.Interactions = append(r.cassette.Interactions, Interaction{
+		Request: recorded,
+		Response: Response{
+			StatusCode: resp.StatusCode,
+			Header:     r.redactHeader(resp.Header),
+			Body:       r.redactBody(body),
+		},
+	})
+
+	return resp, nil
+}
+
+// recordRequest returns the redacted representation of req, restoring
+// its body so it can still be sent.
+func (r *Recorder) recordRequest(req *http.Request) (Request, error) {
+	var body []byte
+
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+
+		body, err = io.ReadAll(req.Body)
+		if closeErr := req.Body.Close(); err == nil {
+			err = closeErr
+		}
+
+		if err != nil {
+			return Request{}, fmt.Errorf("failed to read request body: %w", err)
+		}
+
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	return Request{
+		Method: req.Method,
+		URL:    r.redactURL(req.URL),
+		Header: r.redactHeader(req.Header),
+		Body:   r.redactBody(body),
+	}, nil
+}
+
+// matches compares the parts of a request that identify it. Headers are
+// ignored as they often carry volatile values (dates, user agents).
+func matches(recorded, req Request) bool {
+	return recorded.Method == req.Method &&
+		recorded.URL == req.URL &&
+		recorded.Body == req.Body
+}
+
+func (r *Recorder) redactURL(u *url.URL) string {
+	redacted := *u
+
+	if redacted.User != nil {
+		redacted.User = url.User(redacted.User.Username())
+	}
+
+	query := redacted.Query()
+	for key := range query {
+		if slices.Contains(r.fields, strings.ToLower(key)) {
+			query.Set(key, Redacted)
+		}
+	}
+
+	redacted.RawQuery = query.Encode()
+
+	return redacted.String()
+}
+
+func (r *Recorder) redactHeader(header http.Header) http.Header {
+	if len(header) == 0 {
+		return nil
+	}
+
+	redacted := header.Clone()
+	for _, key := range r.headers {
+		if _, ok := redacted[key]; ok {
+			redacted[key] = []string{Redacted}
+		}
+	}
+
+	return redacted
+}
+
+// redactBody redacts configured fields of JSON bodies. Any other content
+// is stored as is.
+func (r *Recorder) redactBody(body []byte) string {
+	var v any
+	if err := json.Unmarshal(body, &v); err != nil {
+		return string(body)
+	}
+
+	if !r.redactValue(v) {
+		return string(body)
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return string(body)
+	}
+
+	return string(data)
+}
+
+// redactValue walks a decoded JSON value and reports if anything was redacted.
+func (r *Recorder) redactValue(v any) bool {
+	var redacted bool
+
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			if slices.Contains(r.fields, strings.ToLower(key)) {
+				v[key] = Redacted
+				redacted = true
+
+				continue
+			}
+
+			redacted = r.redactValue(value) || redacted
+		}
+	case []any:
+		for _, value := range v {
+			redacted = r.redactValue(value) || redacted
+		}
+	}
+
+	return redacted
+}
+
+func (resp Response) toHTTP(req *http.Request) *http.Response {
+	header := resp.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", resp.StatusCode, http.StatusText(resp.StatusCode)),
+		StatusCode:    resp.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(resp.Body)),
+		ContentLength: int64(len(resp.Body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,134 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package vcr
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func do(t *testing.T, client *http.Client, method, url, body string) (int, string) {
+	t.Helper()
+
+	req, err := http.NewRequestWithContext(t.Context(), method, url, strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer s3cr3t")
+
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+
+	defer resp.Body.Close() //nolint:errcheck // test
+
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	return resp.StatusCode, string(data)
+}
+
+func TestRecordReplay(t *testing.T) {
+	var calls int
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+
+		w.Header().Set("Set-Cookie", "session=abc")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = io.WriteString(w, `{"certificate":"cert","encryption_key":"key"}`)
+	}))
+	t.Cleanup(srv.Close)
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	recorder, err := New(path, WithMode(ModeRecord), WithRedactedFields("encryption_key"))
+	require.NoError(t, err)
+
+	status, body := do(t, recorder.Client(), http.MethodPost, srv.URL+"/enroll?token=t0k3n",
+		`{"secret":"s3cr3t"}`)
+	assert.Equal(t, http.StatusCreated, status)
+	assert.JSONEq(t, `{"certificate":"cert","encryption_key":"key"}`, body,
+		"live response must not be redacted")
+	require.NoError(t, recorder.Stop())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "s3cr3t")
+	assert.NotContains(t, string(data), "t0k3n")
+	assert.NotContains(t, string(data), "session=abc")
+	assert.NotContains(t, string(data), `"key"`)
+
+	srv.Close()
+
+	recorder, err = New(path, WithMode(ModeReplay), WithRedactedFields("encryption_key"))
+	require.NoError(t, err)
+
+	client := recorder.Client()
+
+	status, body = do(t, client, http.MethodPost, srv.URL+"/enroll?token=other",
+		`{"secret":"other"}`)
+	assert.Equal(t, http.StatusCreated, status)
+	assert.JSONEq(t, `{"certificate":"cert","encryption_key":"[REDACTED]"}`, body)
+	assert.Equal(t, 1, calls)
+
+	// Each interaction is replayed only once.
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodPost,
+		srv.URL+"/enroll?token=other", strings.NewReader(`{"secret":"other"}`))
+	require.NoError(t, err)
+
+	_, err = client.Do(req) //nolint:bodyclose // request fails
+	assert.ErrorIs(t, err, ErrNoInteraction)
+}
+
+func TestReplayOrder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	cassette := &Cassette{
+		Version: cassetteVersion,
+		Interactions: []Interaction{
+			{
+				Request:  Request{Method: http.MethodGet, URL: "http://maas/status"},
+				Response: Response{StatusCode: http.StatusServiceUnavailable},
+			},
+			{
+				Request:  Request{Method: http.MethodGet, URL: "http://maas/status"},
+				Response: Response{StatusCode: http.StatusOK, Body: "ok"},
+			},
+		},
+	}
+	require.NoError(t, cassette.save(path))
+
+	recorder, err := New(path, WithMode(ModeReplay))
+	require.NoError(t, err)
+
+	status, _ := do(t, recorder.Client(), http.MethodGet, "http://maas/status", "")
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+
+	status, body := do(t, recorder.Client(), http.MethodGet, "http://maas/status", "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "ok", body)
+}
+
+func TestNewMissingCassette(t *testing.T) {
+	_, err := New(filepath.Join(t.TempDir(), "missing.json"), WithMode(ModeReplay))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
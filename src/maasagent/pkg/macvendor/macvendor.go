@@ -0,0 +1,353 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package macvendor resolves the vendor of network interfaces from their
+// MAC address, using the registries of assignments published by the IEEE.
+//
+// Databases are read from the CSV files of the registries, e.g. oui.csv,
+// mam.csv, oui36.csv and iab.csv from https://standards-oui.ieee.org, which
+// may be gzipped or in a zip archive. A MAC
+// address matches the longest assigned prefix: MA-S and IAB assignments
+// (36 bits) take precedence over MA-M (28 bits) and MA-L (24 bits) ones.
+//
+// Locally administered addresses, which include randomized and most
+// virtual machine addresses, and multicast addresses are assigned to no
+// vendor. Lookups report them as such rather than as unknown.
+package macvendor
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+const macBits = 48
+
+// prefixBits are the lengths of the assigned prefixes, longest first.
+var prefixBits = []int{36, 28, 24}
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zipMagic  = []byte("PK\x03\x04")
+)
+
+// Kind classifies MAC addresses.
+type Kind int
+
+const (
+	// Global addresses are assigned by their vendor.
+	Global Kind = iota
+	// Local addresses are administered locally, e.g. randomized for
+	// privacy or generated for a virtual machine.
+	Local
+	// Multicast addresses, including broadcast, belong to no interface.
+	Multicast
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Global:
+		return "global"
+	case Local:
+		return "local"
+	case Multicast:
+		return "multicast"
+	default:
+		return "Kind(" + strconv.Itoa(int(k)) + ")"
+	}
+}
+
+// Classify returns the kind of mac, from the I/G and U/L bits of its first
+// octet.
+func Classify(mac net.HardwareAddr) Kind {
+	switch {
+	case len(mac) == 0:
+		return Global
+	case mac[0]&0x01 != 0:
+		return Multicast
+	case mac[0]&0x02 != 0:
+		return Local
+	default:
+		return Global
+	}
+}
+
+// Result is the outcome of a lookup.
+type Result struct {
+	// Vendor is the organization the prefix is assigned to, empty when
+	// unknown or not Global.
+	Vendor string
+	// Prefix is the hexadecimal assignment matched, e.g. "70B3D5F2E".
+	Prefix string
+	Kind   Kind
+}
+
+// Database holds the assignments of IEEE registries.
+type Database struct {
+	// assignments maps prefix lengths to prefixes to organizations.
+	assignments map[int]map[uint64]string
+	size        int
+}
+
+// Len returns the number of assignments of db.
+func (db *Database) Len() int {
+	return db.size
+}
+
+// Lookup returns the vendor of mac. Only 48-bit addresses are looked up.
+func (db *Database) Lookup(mac net.HardwareAddr) Result {
+	result := Result{Kind: Classify(mac)}
+	if result.Kind != Global || len(mac) != macBits/8 {
+		return result
+	}
+
+	var addr uint64
+	for _, b := range mac {
+		addr = addr<<8 | uint64(b)
+	}
+
+	for _, bits := range prefixBits {
+		prefix := addr >> (macBits - bits)
+		if vendor, ok := db.assignments[bits][prefix]; ok {
+			result.Vendor = vendor
+			result.Prefix = fmt.Sprintf("%0*X", bits/4, prefix)
+
+			return result
+		}
+	}
+
+	return result
+}
+
+// Parse reads the CSV files of IEEE registries. Registries may be gzipped,
+// or zip archives of CSV files. Columns are matched by name, and an
+// assignment found in several files keeps its first organization.
+func Parse(registries ...io.Reader) (*Database, error) {
+	db := &Database{assignments: make(map[int]map[uint64]string)}
+	for _, bits := range prefixBits {
+		db.assignments[bits] = make(map[uint64]string)
+	}
+
+	for _, r := range registries {
+		if err := db.read(r); err != nil {
+			return nil, err
+		}
+	}
+
+	return db, nil
+}
+
+// read parses a registry, recognizing gzip and zip content by its magic
+// number.
+func (db *Database) read(r io.Reader) error {
+	br := bufio.NewReader(r)
+	// Shorter registries are not compressed, and fail to parse.
+	magic, _ := br.Peek(len(zipMagic))
+
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return fmt.Errorf("failed to decompress registry: %w", err)
+		}
+
+		if err := db.parse(zr); err != nil {
+			return err
+		}
+
+		return zr.Close()
+	case bytes.HasPrefix(magic, zipMagic):
+		return db.readZip(br)
+	default:
+		return db.parse(br)
+	}
+}
+
+// readZip parses the CSV files of a zip archive, in the order of the
+// archive.
+func (db *Database) readZip(r io.Reader) error {
+	// Registries are a few megabytes, zip needs random access.
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("failed to read registry archive: %w", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return fmt.Errorf("failed to read registry archive: %w", err)
+	}
+
+	found := false
+
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() || !strings.EqualFold(path.Ext(f.Name), ".csv") {
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return fmt.Errorf("%s: failed to decompress registry: %w", f.Name, err)
+		}
+
+		err = errors.Join(db.parse(rc), rc.Close())
+		if err != nil {
+			return fmt.Errorf("%s: %w", f.Name, err)
+		}
+
+		found = true
+	}
+
+	if !found {
+		return errors.New("no CSV registry in archive")
+	}
+
+	return nil
+}
+
+func (db *Database) parse(r io.Reader) error {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("failed to read registry header: %w", err)
+	}
+
+	// Spreadsheet exports start with a byte order mark.
+	header[0] = strings.TrimPrefix(header[0], "\ufeff")
+
+	column := func(name string) int {
+		return slices.IndexFunc(header, func(h string) bool {
+			return strings.EqualFold(strings.TrimSpace(h), name)
+		})
+	}
+
+	assignment := column("Assignment")
+	organization := column("Organization Name")
+
+	if assignment < 0 || organization < 0 {
+		return fmt.Errorf("invalid registry header %q: "+
+			"expected Assignment and Organization Name columns", strings.Join(header, ","))
+	}
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return fmt.Errorf("failed to read registry: %w", err)
+		}
+
+		line, _ := reader.FieldPos(0)
+
+		if len(record) <= max(assignment, organization) {
+			return fmt.Errorf("line %d: missing columns in record %q", line, strings.Join(record, ","))
+		}
+
+		hex := strings.TrimSpace(record[assignment])
+		bits := len(hex) * 4
+
+		prefixes, ok := db.assignments[bits]
+		if !ok {
+			return fmt.Errorf("line %d: invalid assignment %q in record %q",
+				line, hex, strings.Join(record, ","))
+		}
+
+		prefix, err := strconv.ParseUint(hex, 16, bits)
+		if err != nil {
+			return fmt.Errorf("line %d: invalid assignment %q in record %q",
+				line, hex, strings.Join(record, ","))
+		}
+
+		if _, ok := prefixes[prefix]; !ok {
+			prefixes[prefix] = strings.TrimSpace(record[organization])
+			db.size++
+		}
+	}
+}
+
+// Load parses the registry files at paths.
+func Load(paths ...string) (*Database, error) {
+	files := make([]*os.File, 0, len(paths))
+	readers := make([]io.Reader, 0, len(paths))
+
+	defer func() {
+		for _, f := range files {
+			f.Close() //nolint:errcheck // files are opened for reading only
+		}
+	}()
+
+	for _, path := range paths {
+		f, err := os.Open(filepath.Clean(path))
+		if err != nil {
+			return nil, fmt.Errorf("failed to open registry: %w", err)
+		}
+
+		files = append(files, f)
+		readers = append(readers, f)
+	}
+
+	db, err := Parse(readers...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s: %w", strings.Join(paths, ", "), err)
+	}
+
+	return db, nil
+}
+
+// Resolver looks up vendors in a database that can be replaced while in use.
+// The zero value has an empty database.
+type Resolver struct {
+	db atomic.Pointer[Database]
+}
+
+// NewResolver returns a Resolver using db.
+func NewResolver(db *Database) *Resolver {
+	r := &Resolver{}
+	r.Update(db)
+
+	return r
+}
+
+// Update replaces the database of r, lookups in progress complete with the
+// previous one.
+func (r *Resolver) Update(db *Database) {
+	r.db.Store(db)
+}
+
+// Lookup returns the vendor of mac.
+func (r *Resolver) Lookup(mac net.HardwareAddr) Result {
+	db := r.db.Load()
+	if db == nil {
+		return Result{Kind: Classify(mac)}
+	}
+
+	return db.Lookup(mac)
+}
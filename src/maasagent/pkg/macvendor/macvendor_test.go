@@ -0,0 +1,241 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package macvendor
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func load(t *testing.T, names ...string) *Database {
+	t.Helper()
+
+	paths := make([]string, 0, len(names))
+	for _, name := range names {
+		paths = append(paths, filepath.Join("testdata", name))
+	}
+
+	db, err := Load(paths...)
+	require.NoError(t, err)
+
+	return db
+}
+
+func mac(t *testing.T, s string) net.HardwareAddr {
+	t.Helper()
+
+	addr, err := net.ParseMAC(s)
+	require.NoError(t, err)
+
+	return addr
+}
+
+func TestClassify(t *testing.T) {
+	testcases := map[string]Kind{
+		"00:16:3e:12:34:56": Global,
+		"52:54:00:12:34:56": Local,
+		"da:a1:19:00:00:01": Local,
+		"01:00:5e:00:00:fb": Multicast,
+		"ff:ff:ff:ff:ff:ff": Multicast,
+	}
+
+	for in, kind := range testcases {
+		t.Run(in, func(t *testing.T) {
+			assert.Equal(t, kind, Classify(mac(t, in)))
+		})
+	}
+}
+
+func TestLookup(t *testing.T) {
+	db := load(t, "oui.csv", "mam.csv", "oui36.csv")
+	assert.Equal(t, 5, db.Len())
+
+	testcases := map[string]Result{
+		"00:16:3e:12:34:56": {Vendor: "Xensource, Inc.", Prefix: "00163E"},
+		"08:00:27:ab:cd:ef": {Vendor: "PCS Systemtechnik GmbH", Prefix: "080027"},
+		"70:b3:d5:f2:e0:01": {Vendor: "Example Sensors Ltd", Prefix: "70B3D5F2E"},
+		"70:b3:d5:f1:00:01": {Vendor: "Example Networks Co", Prefix: "70B3D5F"},
+		"70:b3:d5:01:00:01": {Vendor: "IEEE Registration Authority", Prefix: "70B3D5"},
+		"00:00:01:00:00:01": {},
+		"52:54:00:12:34:56": {Kind: Local},
+		"01:00:5e:00:00:fb": {Kind: Multicast},
+		// Only EUI-48 addresses are assigned by these registries.
+		"00:16:3e:12:34:56:78:9a": {},
+	}
+
+	for in, want := range testcases {
+		t.Run(in, func(t *testing.T) {
+			assert.Equal(t, want, db.Lookup(mac(t, in)))
+		})
+	}
+}
+
+func TestParseFirstAssignmentWins(t *testing.T) {
+	db, err := Parse(
+		strings.NewReader("Assignment,Organization Name\n00163E,First\n"),
+		strings.NewReader("Assignment,Organization Name\n00163E,Second\n"),
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, db.Len())
+	assert.Equal(t, "First", db.Lookup(mac(t, "00:16:3e:00:00:01")).Vendor)
+}
+
+func TestParseErrors(t *testing.T) {
+	testcases := map[string]struct {
+		in     string
+		errMsg string
+	}{
+		"empty": {
+			errMsg: "failed to read registry header",
+		},
+		"missing columns in header": {
+			in:     "Registry,Assignment\nMA-L,00163E\n",
+			errMsg: "expected Assignment and Organization Name columns",
+		},
+		"missing columns in record": {
+			in:     "Assignment,Organization Name\n00163E\n",
+			errMsg: `line 2: missing columns in record "00163E"`,
+		},
+		"invalid length": {
+			in:     "Assignment,Organization Name\n00163,Short\n",
+			errMsg: `line 2: invalid assignment "00163" in record "00163,Short"`,
+		},
+		"invalid hex": {
+			in:     "Assignment,Organization Name\n080027,Valid\n00163G,Invalid\n",
+			errMsg: `line 3: invalid assignment "00163G" in record "00163G,Invalid"`,
+		},
+		"truncated gzip": {
+			in:     "\x1f\x8b\x08",
+			errMsg: "failed to decompress registry",
+		},
+		"zip without registry": {
+			in:     string(zipped(t, map[string]string{"README.txt": "registries"})),
+			errMsg: "no CSV registry in archive",
+		},
+		"invalid registry in zip": {
+			in:     string(zipped(t, map[string]string{"oui.csv": "Registry\nMA-L\n"})),
+			errMsg: "oui.csv: invalid registry header",
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tc.in))
+			assert.ErrorContains(t, err, tc.errMsg)
+		})
+	}
+}
+
+func gzipped(t *testing.T, data []byte) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	w := gzip.NewWriter(&buf)
+	_, err := w.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	return buf.Bytes()
+}
+
+func zipped(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	w := zip.NewWriter(&buf)
+
+	for name, content := range files {
+		f, err := w.Create(name)
+		require.NoError(t, err)
+
+		_, err = f.Write([]byte(content))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, w.Close())
+
+	return buf.Bytes()
+}
+
+func TestParseCompressed(t *testing.T) {
+	oui, err := os.ReadFile(filepath.Join("testdata", "oui.csv"))
+	require.NoError(t, err)
+
+	mam, err := os.ReadFile(filepath.Join("testdata", "mam.csv"))
+	require.NoError(t, err)
+
+	plain, err := Parse(bytes.NewReader(oui), bytes.NewReader(mam))
+	require.NoError(t, err)
+
+	testcases := map[string][]byte{
+		"gzip": gzipped(t, oui),
+		"zip": zipped(t, map[string]string{
+			"oui.csv":    string(oui),
+			"README.txt": "not a registry",
+		}),
+	}
+
+	for name, data := range testcases {
+		t.Run(name, func(t *testing.T) {
+			db, err := Parse(bytes.NewReader(data), bytes.NewReader(mam))
+			require.NoError(t, err)
+			assert.Equal(t, plain.Len(), db.Len())
+			assert.Equal(t, "Xensource, Inc.", db.Lookup(mac(t, "00:16:3e:00:00:01")).Vendor)
+		})
+	}
+}
+
+func TestParseHeader(t *testing.T) {
+	// A byte order mark, names in another case and reordered columns.
+	db, err := Parse(strings.NewReader("\ufeffORGANIZATION NAME, assignment ,Registry\n" +
+		"PCS Systemtechnik GmbH, 080027 ,MA-L\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "PCS Systemtechnik GmbH", db.Lookup(mac(t, "08:00:27:00:00:01")).Vendor)
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	_, err := Load(filepath.Join("testdata", "missing.csv"))
+	assert.ErrorContains(t, err, "failed to open registry")
+}
+
+func TestResolverUpdate(t *testing.T) {
+	var r Resolver
+
+	addr := mac(t, "00:16:3e:12:34:56")
+	assert.Equal(t, Result{}, r.Lookup(addr))
+	assert.Equal(t, Result{Kind: Local}, r.Lookup(mac(t, "52:54:00:12:34:56")))
+
+	r.Update(load(t, "oui.csv"))
+	assert.Equal(t, "Xensource, Inc.", r.Lookup(addr).Vendor)
+
+	r.Update(load(t, "oui36.csv"))
+	assert.Equal(t, Result{}, r.Lookup(addr))
+
+	assert.Equal(t, "Example Sensors Ltd",
+		NewResolver(load(t, "oui36.csv")).Lookup(mac(t, "70:b3:d5:f2:e0:01")).Vendor)
+}
@@ -101,11 +101,14 @@ class AgentHandler(Handler):
         self,
         system_id: str,
         service_name: str,
+        request: Request,
         response: Response,
         services: ServiceCollectionV3 = Depends(services),  # noqa: B008
     ) -> Response:
+        # Query parameters, e.g. the hashes of the configuration the agent
+        # already has, are understood by the region.
         tokens = await services.agents.get_service_configuration(
-            system_id, service_name
+            system_id, service_name, params=dict(request.query_params)
         )
 
         return tokens
@@ -15,6 +15,7 @@ from maasapiserver.common.api.models.responses.errors import (
 from maasapiserver.v3.api import services
 from maasapiserver.v3.api.public.models.requests.entitlements import (
     EntitlementRequest,
+    PoolsEntitlementRequest,
 )
 from maasapiserver.v3.api.public.models.requests.query import PaginationParams
 from maasapiserver.v3.api.public.models.requests.usergroup_members import (
@@ -29,6 +30,7 @@ from maasapiserver.v3.api.public.models.responses.base import (
 from maasapiserver.v3.api.public.models.responses.entitlements import (
     EntitlementResponse,
     EntitlementsListResponse,
+    PoolsEntitlementResponse,
 )
 from maasapiserver.v3.api.public.models.responses.usergroup_members import (
     UserGroupMemberResponse,
@@ -409,6 +411,47 @@ class UserGroupsHandler(Handler):
         openfga_tuple = await services.openfga_tuples.upsert(builder)
         return EntitlementResponse.from_model(openfga_tuple)
 
+    @handler(
+        path="/groups/{group_id}/entitlements:grant_pools",
+        methods=["POST"],
+        tags=TAGS,
+        responses={
+            200: {
+                "model": PoolsEntitlementResponse,
+            },
+            400: {"model": BadRequestBodyResponse},
+            404: {"model": NotFoundBodyResponse},
+        },
+        response_model_exclude_none=True,
+        status_code=200,
+        dependencies=[
+            Depends(check_permissions(required_roles={UserRole.ADMIN}))
+        ],
+    )
+    async def grant_group_pools_entitlement(
+        self,
+        group_id: int,
+        entitlement_request: PoolsEntitlementRequest,
+        services: ServiceCollectionV3 = Depends(services),  # noqa: B008
+    ) -> PoolsEntitlementResponse:
+        group = await services.usergroups.get_by_id(group_id)
+        if not group:
+            raise NotFoundException()
+
+        pools = await entitlement_request.get_pools(services)
+        pool_ids = [pool.id for pool in pools]
+        granted = await services.openfga_tuples.grant_pools_entitlement(
+            group_id, entitlement_request.entitlement, pool_ids
+        )
+        return PoolsEntitlementResponse(
+            entitlement=entitlement_request.entitlement,
+            matched=len(pool_ids),
+            granted=granted,
+            already_granted=[
+                pool_id for pool_id in pool_ids if pool_id not in granted
+            ],
+        )
+
     @handler(
         path="/groups/{group_id}/entitlements",
         methods=["DELETE"],
@@ -1,6 +1,8 @@
 # Copyright 2026 Canonical Ltd.  This software is licensed under the
 # GNU Affero General Public License version 3 (see the file LICENSE).
 
+from fnmatch import fnmatchcase
+
 from pydantic import BaseModel, Field
 
 from maascommon.openfga.base import OpenFGAEntitlementResourceType
@@ -16,16 +18,18 @@ from maasservicelayer.exceptions.catalog import (
 from maasservicelayer.exceptions.constants import (
     INVALID_ARGUMENT_VIOLATION_TYPE,
 )
+from maasservicelayer.models.resource_pools import ResourcePool
 from maasservicelayer.services import ServiceCollectionV3
 from maasservicelayer.services.openfga_tuples import (
     EntitlementsBuilderFactory,
+    PoolTupleBuilderFactory,
     UndefinedEntitlementError,
 )
 
 
 class EntitlementRequest(BaseModel):
     resource_type: OpenFGAEntitlementResourceType = Field(
-        description="The resource type (e.g. 'maas', 'pool')."
+        description="The resource type (e.g. 'maas', 'pool', 'tag')."
     )
     resource_id: int = Field(
         description="The resource ID. Must be 0 for 'maas' type."
@@ -63,6 +67,17 @@ class EntitlementRequest(BaseModel):
                         )
                     ]
                 )
+        elif self.resource_type == OpenFGAEntitlementResourceType.TAG:
+            tag = await services.tags.get_by_id(self.resource_id)
+            if tag is None:
+                raise NotFoundException(
+                    details=[
+                        BaseExceptionDetail(
+                            type=INVALID_ARGUMENT_VIOLATION_TYPE,
+                            message=f"Tag with id {self.resource_id} not found.",
+                        )
+                    ]
+                )
         elif self.resource_type == OpenFGAEntitlementResourceType.MAAS:
             if self.resource_id != 0:
                 raise BadRequestException(
@@ -74,3 +89,62 @@ class EntitlementRequest(BaseModel):
                     ]
                 )
         return factory.build_tuple(group_id, self.resource_id)
+
+
+class PoolsEntitlementRequest(BaseModel):
+    entitlement: str = Field(description="The entitlement name.")
+    name: str | None = Field(
+        default=None,
+        description="Shell-style pattern matched against the pool names "
+        "(e.g. 'lab-*').",
+    )
+    tag: str | None = Field(
+        default=None,
+        description="Name of a tag; selects the pools holding at least one "
+        "machine with the tag.",
+    )
+    all_pools: bool = Field(
+        default=False, description="Whether to select every pool."
+    )
+
+    async def get_pools(
+        self, services: ServiceCollectionV3
+    ) -> list[ResourcePool]:
+        """Validate the request and return the pools it selects."""
+        is_valid, error_message = (
+            PoolTupleBuilderFactory.validate_entitlement(self.entitlement)
+        )
+        if not is_valid:
+            raise BadRequestException(
+                details=[
+                    BaseExceptionDetail(
+                        type=INVALID_ARGUMENT_VIOLATION_TYPE,
+                        message=error_message,  # type: ignore[reportArgumentType]
+                    )
+                ]
+            )
+        selectors = [
+            self.name is not None,
+            self.tag is not None,
+            self.all_pools,
+        ]
+        if selectors.count(True) != 1:
+            raise BadRequestException(
+                details=[
+                    BaseExceptionDetail(
+                        type=INVALID_ARGUMENT_VIOLATION_TYPE,
+                        message="Exactly one of 'name', 'tag' and 'all_pools' must be given.",
+                    )
+                ]
+            )
+
+        if self.tag is not None:
+            return await services.resource_pools.get_many(
+                QuerySpec(
+                    where=ResourcePoolClauseFactory.with_machine_tag(self.tag)
+                )
+            )
+        pools = await services.resource_pools.get_many(QuerySpec())
+        if self.name is None:
+            return pools
+        return [pool for pool in pools if fnmatchcase(pool.name, self.name)]
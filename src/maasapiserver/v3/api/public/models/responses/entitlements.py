@@ -26,3 +26,11 @@ class EntitlementResponse(BaseModel):
 class EntitlementsListResponse(BaseModel):
     kind = "EntitlementsList"
     items: list[EntitlementResponse]
+
+
+class PoolsEntitlementResponse(BaseModel):
+    kind = "PoolsEntitlement"
+    entitlement: str
+    matched: int
+    granted: list[int]
+    already_granted: list[int]
@@ -9,6 +9,11 @@ from starlette.responses import Response
 from starlette.types import ASGIApp
 import structlog
 
+from maascommon.deadline import (
+    parse_request_timeout,
+    REQUEST_TIMEOUT_HEADER,
+    set_request_timeout,
+)
 from maascommon.tracing import get_or_set_trace_id, set_trace_id
 from maasservicelayer.context import Context
 
@@ -34,6 +39,10 @@ class ContextMiddleware(BaseHTTPMiddleware):
         else:
             trace_id = get_or_set_trace_id()
 
+        set_request_timeout(
+            parse_request_timeout(request.headers.get(REQUEST_TIMEOUT_HEADER))
+        )
+
         context = Context(trace_id=trace_id)
         request.state.context = context
         structlog.contextvars.clear_contextvars()
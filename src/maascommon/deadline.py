@@ -0,0 +1,59 @@
+# Copyright 2026 Canonical Ltd.  This software is licensed under the
+# GNU Affero General Public License version 3 (see the file LICENSE).
+
+"""Time budget of the request being served.
+
+The budget is received from the caller in the `MAAS-Request-Timeout`
+header (milliseconds) and passed on to downstream services, such as
+maas-openfga, so that they can abandon work nobody waits for anymore.
+"""
+
+import contextvars
+import time
+
+REQUEST_TIMEOUT_HEADER = "MAAS-Request-Timeout"
+
+# Monotonic time by which the current request must be answered.
+REQUEST_DEADLINE: contextvars.ContextVar[float | None] = (
+    contextvars.ContextVar("_MAAS_REQUEST_DEADLINE", default=None)
+)
+
+
+def set_request_timeout(timeout: float | None) -> contextvars.Token:
+    """Set the remaining time of the current request, in seconds.
+
+    Returns a token to restore the previous deadline with
+    `REQUEST_DEADLINE.reset()`.
+    """
+    deadline = None if timeout is None else time.monotonic() + timeout
+    return REQUEST_DEADLINE.set(deadline)
+
+
+def get_remaining_time() -> float | None:
+    """Return the remaining time of the current request, in seconds.
+
+    The result is negative once the deadline has passed, and None if the
+    request has no deadline.
+    """
+    deadline = REQUEST_DEADLINE.get()
+    if deadline is None:
+        return None
+    return deadline - time.monotonic()
+
+
+def parse_request_timeout(value: str | None) -> float | None:
+    """Parse a `MAAS-Request-Timeout` header value into seconds."""
+    if not value:
+        return None
+    try:
+        milliseconds = int(value)
+    except ValueError:
+        return None
+    if milliseconds < 0:
+        return None
+    return milliseconds / 1000
+
+
+def format_request_timeout(timeout: float) -> str:
+    """Format a remaining time in seconds as a header value."""
+    return str(max(int(timeout * 1000), 0))
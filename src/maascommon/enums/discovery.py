@@ -27,3 +27,15 @@ class ActiveDiscoveryIntervalEnum(IntEnum):
     EVERY_HOUR = _timedelta_to_whole_seconds(hours=1)
     EVERY_30_MINUTES = _timedelta_to_whole_seconds(minutes=30)
     EVERY_10_MINUTES = _timedelta_to_whole_seconds(minutes=10)
+
+
+class DeviceTypeEnum(StrEnum):
+    """Kinds of discovered devices MAAS can guess."""
+
+    PRINTER = "printer"
+    PHONE = "phone"
+    BMC = "bmc"
+    SWITCH = "switch"
+
+    def __str__(self):
+        return str(self.value)
@@ -2,4 +2,8 @@
 # GNU Affero General Public License version 3 (see the file LICENSE).
 
 OPENFGA_STORE_ID = "00000000000000000000000000"
-OPENFGA_AUTHORIZATION_MODEL_ID = "00000000000000000000000000"
+OPENFGA_AUTHORIZATION_MODEL_ID = "00000000000000000000000004"
+
+# Values of openfga.changelog.operation, as in openfga.v1.TupleOperation.
+OPENFGA_TUPLE_OPERATION_WRITE = 0
+OPENFGA_TUPLE_OPERATION_DELETE = 1
@@ -10,20 +10,25 @@ from maascommon.enums.openfga import (
 from maascommon.openfga.base import (
     BaseOpenFGAClient,
     OpenFGAEntitlementResourceType,
+    OpenFGARequestPriority,
 )
 
 
 class OpenFGAClient(BaseOpenFGAClient):
     """Asynchronous client for interacting with OpenFGA API."""
 
-    def __init__(self, unix_socket: str | None = None):
-        super().__init__(unix_socket)
+    def __init__(
+        self,
+        unix_socket: str | None = None,
+        priority: OpenFGARequestPriority = OpenFGARequestPriority.INTERACTIVE,
+    ):
+        super().__init__(unix_socket, priority)
         self.client = self._init_client()
 
     def _init_client(self) -> httpx.AsyncClient:
         return httpx.AsyncClient(
-            timeout=httpx.Timeout(10),
-            headers=self.HEADERS,
+            timeout=httpx.Timeout(self.TIMEOUT),
+            headers=self.headers,
             base_url="http://unix/",
             transport=httpx.AsyncHTTPTransport(uds=self.socket_path),
         )
@@ -42,7 +47,10 @@ class OpenFGAClient(BaseOpenFGAClient):
                 },
                 "authorization_model_id": OPENFGA_AUTHORIZATION_MODEL_ID,
             },
+            **self._request_options(),
         )
+        if self._resolution_limit_exceeded(response):
+            return False
         response.raise_for_status()
         return response.json().get("allowed", False)
 
@@ -57,10 +65,35 @@ class OpenFGAClient(BaseOpenFGAClient):
                 "relation": relation,
                 "type": obj_type,
             },
+            **self._request_options(),
         )
+        if self._resolution_limit_exceeded(response):
+            return []
         response.raise_for_status()
         return self._parse_list_objects(response.json())
 
+    async def list_users_with_access(
+        self,
+        resource_type: OpenFGAEntitlementResourceType,
+        resource_id: int | str,
+        *relations: str,
+    ) -> dict[str, list[int]]:
+        """Return the IDs of the users having each relation on a resource.
+
+        Group memberships and permissions inherited from MAAS or from the
+        pool are resolved by maas-openfga. Every relation of the resource
+        type is listed if none is given.
+        """
+        response = await self.client.get(
+            f"/stores/{OPENFGA_STORE_ID}/access",
+            params=self._access_params(resource_type, resource_id, relations),
+            **self._request_options(),
+        )
+        if self._resolution_limit_exceeded(response):
+            return {}
+        response.raise_for_status()
+        return self._parse_access(response.json())
+
     # Machine & Pool Permissions
     async def can_edit_machines(self, user_id: int) -> bool:
         return await self._check(
@@ -95,6 +128,28 @@ class OpenFGAClient(BaseOpenFGAClient):
             user_id, "can_view_available_machines", self._format_pool(pool_id)
         )
 
+    async def can_edit_machine(self, user_id: int, machine_id: int) -> bool:
+        return await self._check(
+            user_id, "can_edit", self._format_machine(machine_id)
+        )
+
+    async def can_deploy_machine(self, user_id: int, machine_id: int) -> bool:
+        return await self._check(
+            user_id, "can_deploy", self._format_machine(machine_id)
+        )
+
+    async def can_view_machine(self, user_id: int, machine_id: int) -> bool:
+        return await self._check(
+            user_id, "can_view", self._format_machine(machine_id)
+        )
+
+    async def can_view_available_machine(
+        self, user_id: int, machine_id: int
+    ) -> bool:
+        return await self._check(
+            user_id, "can_view_available", self._format_machine(machine_id)
+        )
+
     # Global Permissions
     async def can_edit_global_entities(self, user_id: int) -> bool:
         return await self._check(
@@ -206,3 +261,33 @@ class OpenFGAClient(BaseOpenFGAClient):
         return await self._list_objects(
             user_id, "can_edit_machines", OpenFGAEntitlementResourceType.POOL
         )
+
+    async def list_tags_with_view_machines_access(
+        self, user_id: int
+    ) -> list[int]:
+        return await self._list_objects(
+            user_id, "can_view_machines", OpenFGAEntitlementResourceType.TAG
+        )
+
+    async def list_tags_with_view_available_machines_access(
+        self, user_id: int
+    ) -> list[int]:
+        return await self._list_objects(
+            user_id,
+            "can_view_available_machines",
+            OpenFGAEntitlementResourceType.TAG,
+        )
+
+    async def list_tags_with_deploy_machines_access(
+        self, user_id: int
+    ) -> list[int]:
+        return await self._list_objects(
+            user_id, "can_deploy_machines", OpenFGAEntitlementResourceType.TAG
+        )
+
+    async def list_tags_with_edit_machines_access(
+        self, user_id: int
+    ) -> list[int]:
+        return await self._list_objects(
+            user_id, "can_edit_machines", OpenFGAEntitlementResourceType.TAG
+        )
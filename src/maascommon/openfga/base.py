@@ -6,6 +6,13 @@ import os
 from pathlib import Path
 from typing import Any
 
+import httpx
+
+from maascommon.deadline import (
+    format_request_timeout,
+    get_remaining_time,
+    REQUEST_TIMEOUT_HEADER,
+)
 from maascommon.path import get_maas_data_path
 
 
@@ -14,16 +21,55 @@ class OpenFGAEntitlementResourceType(StrEnum):
 
     POOL = "pool"
     MAAS = "maas"
+    ZONE = "zone"
+    FABRIC = "fabric"
+    VLAN = "vlan"
+    BOOT_RESOURCE = "boot_resource"
+    TAG = "tag"
+    MACHINE = "machine"
+
+
+REQUEST_PRIORITY_HEADER = "MAAS-Request-Priority"
+
+
+class OpenFGARequestPriority(StrEnum):
+    """Priority class of requests to maas-openfga.
+
+    Batch requests are only served when maas-openfga has spare capacity,
+    so that background work doesn't add latency to interactive checks.
+    """
+
+    INTERACTIVE = "interactive"
+    BATCH = "batch"
+
+
+# Error code answered, with status 422, to the queries maas-openfga
+# abandoned because they hit its resolution limits.
+RESOLUTION_LIMIT_EXCEEDED = "resolution_limit_exceeded"
+
+
+class OpenFGADeadlineExceeded(TimeoutError):
+    """The request deadline passed before OpenFGA was called."""
 
 
 class BaseOpenFGAClient:
     """Abstract base for sync/async OpenFGA clients."""
 
     HEADERS = {"User-Agent": "maas-openfga-client/1.0"}
+    TIMEOUT = 10
     MAAS_GLOBAL_OBJ = f"{OpenFGAEntitlementResourceType.MAAS}:0"
 
-    def __init__(self, unix_socket: str | None = None):
+    def __init__(
+        self,
+        unix_socket: str | None = None,
+        priority: OpenFGARequestPriority = OpenFGARequestPriority.INTERACTIVE,
+    ):
         self.socket_path = unix_socket or self._get_default_socket_path()
+        self.priority = priority
+        self.headers = {
+            **self.HEADERS,
+            REQUEST_PRIORITY_HEADER: str(priority),
+        }
 
     def _get_default_socket_path(self) -> str:
         return str(
@@ -35,8 +81,62 @@ class BaseOpenFGAClient:
             )
         )
 
+    def _request_options(self) -> dict[str, Any]:
+        """Pass the remaining time of the current request to OpenFGA.
+
+        maas-openfga turns it into a deadline, abandoning authorization
+        work once the upstream request has timed out.
+        """
+        remaining = get_remaining_time()
+        if remaining is None:
+            return {}
+        if remaining <= 0:
+            raise OpenFGADeadlineExceeded()
+        return {
+            "headers": {
+                REQUEST_TIMEOUT_HEADER: format_request_timeout(remaining)
+            },
+            "timeout": min(self.TIMEOUT, remaining),
+        }
+
+    def _resolution_limit_exceeded(self, response: httpx.Response) -> bool:
+        """Whether the query was abandoned on a resolution limit.
+
+        maas-openfga answers queries too deep or too long to resolve with
+        a 422, which must be taken as a denial, or as an empty listing,
+        rather than an error.
+        """
+        if response.status_code != httpx.codes.UNPROCESSABLE_ENTITY:
+            return False
+        try:
+            details = response.json().get("details") or []
+        except ValueError:
+            return False
+        return bool(details) and (
+            details[0].get("type") == RESOLUTION_LIMIT_EXCEEDED
+        )
+
     def _format_pool(self, pool_id: int) -> str:
         return f"{OpenFGAEntitlementResourceType.POOL}:{pool_id}"
 
+    def _format_machine(self, machine_id: int) -> str:
+        return f"{OpenFGAEntitlementResourceType.MACHINE}:{machine_id}"
+
     def _parse_list_objects(self, data: dict[str, Any]) -> list[int]:
         return [int(item.split(":")[1]) for item in data.get("objects", [])]
+
+    def _access_params(
+        self,
+        resource_type: OpenFGAEntitlementResourceType,
+        resource_id: int | str,
+        relations: tuple[str, ...],
+    ) -> list[tuple[str, str]]:
+        return [("object", f"{resource_type}:{resource_id}")] + [
+            ("relation", relation) for relation in relations
+        ]
+
+    def _parse_access(self, data: dict[str, Any]) -> dict[str, list[int]]:
+        return {
+            relation: [int(user.split(":")[1]) for user in users]
+            for relation, users in data.get("relations", {}).items()
+        }
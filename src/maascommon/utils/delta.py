@@ -0,0 +1,177 @@
+#  Copyright 2026 Canonical Ltd.  This software is licensed under the
+#  GNU Affero General Public License version 3 (see the file LICENSE).
+
+"""Binary deltas used to push large configuration files to agents.
+
+A delta rebuilds a target from a base the receiver already has. Bases are
+content-addressed by their SHA-256, so the receiver can tell which one a
+delta applies to, and check the result against the hash of the target.
+
+The encoding, also implemented by the agent (internal/delta), is:
+
+    uvarint base length
+    uvarint target length
+    ops until the end of the delta:
+        0x01 uvarint offset, uvarint length   copy a range of the base
+        0x02 uvarint length, bytes            insert literal bytes
+
+Unsigned varints are LEB128, as read by Go's encoding/binary.Uvarint.
+"""
+
+from collections import OrderedDict
+from difflib import SequenceMatcher
+import hashlib
+from itertools import accumulate
+from threading import Lock
+
+OP_COPY = 0x01
+OP_INSERT = 0x02
+
+
+class DeltaError(ValueError):
+    """Raised when a delta is malformed or doesn't match its base."""
+
+
+def content_hash(data: bytes) -> str:
+    """Return the address of `data` for deltas."""
+    return hashlib.sha256(data).hexdigest()
+
+
+def _put_uvarint(out: bytearray, value: int) -> None:
+    while value >= 0x80:
+        out.append((value & 0x7F) | 0x80)
+        value >>= 7
+    out.append(value)
+
+
+def _read_uvarint(data: bytes, pos: int) -> tuple[int, int]:
+    value = shift = 0
+    while pos < len(data):
+        byte = data[pos]
+        pos += 1
+        value |= (byte & 0x7F) << shift
+        if byte < 0x80:
+            return value, pos
+        shift += 7
+        if shift > 63:
+            break
+    raise DeltaError("truncated or oversized varint")
+
+
+def make_delta(base: bytes, target: bytes) -> bytes:
+    """Return a delta rebuilding `target` from `base`.
+
+    Configuration files are matched line by line, which is what changes
+    between two renderings of the same template.
+    """
+    base_lines = base.splitlines(keepends=True)
+    target_lines = target.splitlines(keepends=True)
+    # Byte offset of each line of the base, and of the end of the base.
+    offsets = [0, *accumulate(len(line) for line in base_lines)]
+
+    ops: list[tuple[int, int, int]] = []
+    target_pos = 0
+    matcher = SequenceMatcher(None, base_lines, target_lines)
+    for tag, i1, i2, j1, j2 in matcher.get_opcodes():
+        if tag == "equal":
+            start, end = offsets[i1], offsets[i2]
+            if ops and ops[-1][0] == OP_COPY and ops[-1][2] == start:
+                ops[-1] = (OP_COPY, ops[-1][1], end)
+            else:
+                ops.append((OP_COPY, start, end))
+            target_pos += end - start
+        elif j2 > j1:
+            length = sum(len(line) for line in target_lines[j1:j2])
+            if ops and ops[-1][0] == OP_INSERT:
+                ops[-1] = (OP_INSERT, ops[-1][1], target_pos + length)
+            else:
+                ops.append((OP_INSERT, target_pos, target_pos + length))
+            target_pos += length
+
+    out = bytearray()
+    _put_uvarint(out, len(base))
+    _put_uvarint(out, len(target))
+    for op, start, end in ops:
+        out.append(op)
+        if op == OP_COPY:
+            _put_uvarint(out, start)
+            _put_uvarint(out, end - start)
+        else:
+            _put_uvarint(out, end - start)
+            out += target[start:end]
+    return bytes(out)
+
+
+def apply_delta(base: bytes, delta: bytes) -> bytes:
+    """Rebuild the target of `delta` from `base`."""
+    base_len, pos = _read_uvarint(delta, 0)
+    target_len, pos = _read_uvarint(delta, pos)
+    if base_len != len(base):
+        raise DeltaError(
+            f"delta expects a {base_len} bytes base, got {len(base)}"
+        )
+
+    out = bytearray()
+    while pos < len(delta):
+        op = delta[pos]
+        pos += 1
+        if op == OP_COPY:
+            offset, pos = _read_uvarint(delta, pos)
+            length, pos = _read_uvarint(delta, pos)
+            if offset + length > len(base):
+                raise DeltaError("copy past the end of the base")
+            out += base[offset : offset + length]
+        elif op == OP_INSERT:
+            length, pos = _read_uvarint(delta, pos)
+            if pos + length > len(delta):
+                raise DeltaError("insert past the end of the delta")
+            out += delta[pos : pos + length]
+            pos += length
+        else:
+            raise DeltaError(f"unknown delta op {op:#x}")
+        if len(out) > target_len:
+            raise DeltaError("delta produces more than its target length")
+
+    if len(out) != target_len:
+        raise DeltaError(
+            f"delta produced {len(out)} bytes, expected {target_len}"
+        )
+    return bytes(out)
+
+
+class ContentStore:
+    """Least recently used store of contents by hash, bounded in size.
+
+    The region keeps the configurations it sent recently, to compute deltas
+    against the one an agent reports it has.
+    """
+
+    def __init__(self, max_bytes: int):
+        self._max_bytes = max_bytes
+        self._size = 0
+        self._contents: OrderedDict[str, bytes] = OrderedDict()
+        self._lock = Lock()
+
+    def put(self, data: bytes) -> str:
+        """Store `data` and return its hash."""
+        digest = content_hash(data)
+        if len(data) > self._max_bytes:
+            return digest
+        with self._lock:
+            if digest in self._contents:
+                self._contents.move_to_end(digest)
+                return digest
+            self._contents[digest] = data
+            self._size += len(data)
+            while self._size > self._max_bytes:
+                _, evicted = self._contents.popitem(last=False)
+                self._size -= len(evicted)
+        return digest
+
+    def get(self, digest: str) -> bytes | None:
+        """Return the content with the given hash, if still stored."""
+        with self._lock:
+            data = self._contents.get(digest)
+            if data is not None:
+                self._contents.move_to_end(digest)
+            return data
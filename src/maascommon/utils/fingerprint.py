@@ -0,0 +1,127 @@
+#  Copyright 2026 Canonical Ltd.  This software is licensed under the
+#  GNU Affero General Public License version 3 (see the file LICENSE).
+
+"""Guess the type of discovered devices.
+
+Discovery sees little more than addresses, but devices give away what they
+are in other ways: the vendor class and the options they request over
+DHCP, the services they announce over mDNS and the vendor of their MAC
+address. Each of those is weak evidence on its own, so every match carries
+a weight, and the weights found for a type are combined into a confidence
+as independent probabilities would be.
+"""
+
+from dataclasses import dataclass
+from typing import Iterable, Sequence
+
+from maascommon.enums.discovery import DeviceTypeEnum
+
+# (substring of the DHCP vendor class identifier, type, weight), matched
+# case-insensitively. More specific identifiers come first, as the first
+# match wins.
+VENDOR_CLASS_RULES = (
+    ("ip phone", DeviceTypeEnum.PHONE, 0.9),
+    ("android-dhcp", DeviceTypeEnum.PHONE, 0.8),
+    ("polycom", DeviceTypeEnum.PHONE, 0.9),
+    ("yealink", DeviceTypeEnum.PHONE, 0.9),
+    ("mitel", DeviceTypeEnum.PHONE, 0.9),
+    ("avaya", DeviceTypeEnum.PHONE, 0.8),
+    ("jetdirect", DeviceTypeEnum.PRINTER, 0.9),
+    ("printer", DeviceTypeEnum.PRINTER, 0.8),
+    ("idrac", DeviceTypeEnum.BMC, 0.9),
+    ("cpqrib", DeviceTypeEnum.BMC, 0.9),
+    ("ipmi", DeviceTypeEnum.BMC, 0.8),
+    ("onie_vendor", DeviceTypeEnum.SWITCH, 0.9),
+    ("arista", DeviceTypeEnum.SWITCH, 0.8),
+    ("juniper", DeviceTypeEnum.SWITCH, 0.8),
+    ("cumulus", DeviceTypeEnum.SWITCH, 0.8),
+    ("cisco", DeviceTypeEnum.SWITCH, 0.5),
+)
+
+# (DHCP option in the parameter request list, type, weight).
+PARAMETER_REQUEST_RULES = (
+    # TFTP server address of Cisco IP phones.
+    (150, DeviceTypeEnum.PHONE, 0.4),
+    # Avaya IP phone configuration.
+    (242, DeviceTypeEnum.PHONE, 0.6),
+)
+
+# (mDNS service type, type, weight).
+MDNS_SERVICE_RULES = (
+    ("_ipp._tcp", DeviceTypeEnum.PRINTER, 0.8),
+    ("_ipps._tcp", DeviceTypeEnum.PRINTER, 0.8),
+    ("_printer._tcp", DeviceTypeEnum.PRINTER, 0.8),
+    ("_pdl-datastream._tcp", DeviceTypeEnum.PRINTER, 0.9),
+    ("_apple-mobdev2._tcp", DeviceTypeEnum.PHONE, 0.6),
+    ("_companion-link._tcp", DeviceTypeEnum.PHONE, 0.3),
+)
+
+# (substring of the MAC address organization, type, weight), matched
+# case-insensitively. Vendors making many kinds of devices are left out.
+ORGANIZATION_RULES = (
+    ("brother industries", DeviceTypeEnum.PRINTER, 0.6),
+    ("seiko epson", DeviceTypeEnum.PRINTER, 0.6),
+    ("lexmark", DeviceTypeEnum.PRINTER, 0.6),
+    ("xerox", DeviceTypeEnum.PRINTER, 0.6),
+    ("kyocera", DeviceTypeEnum.PRINTER, 0.5),
+    ("polycom", DeviceTypeEnum.PHONE, 0.6),
+    ("yealink", DeviceTypeEnum.PHONE, 0.6),
+    ("grandstream", DeviceTypeEnum.PHONE, 0.5),
+    ("arista", DeviceTypeEnum.SWITCH, 0.5),
+    ("juniper", DeviceTypeEnum.SWITCH, 0.4),
+    ("mellanox", DeviceTypeEnum.SWITCH, 0.3),
+)
+
+
+@dataclass(frozen=True)
+class DeviceGuess:
+    device_type: DeviceTypeEnum
+    # Between 0 (no evidence) and 1 (certain).
+    confidence: float
+
+
+def _match_substring(value, rules):
+    value = value.lower()
+    for pattern, device_type, weight in rules:
+        if pattern in value:
+            return [(device_type, weight)]
+    return []
+
+
+def classify_device(
+    vendor_class: str | None = None,
+    parameter_request_list: Sequence[int] = (),
+    mdns_service_types: Iterable[str] = (),
+    mac_organization: str | None = None,
+) -> list[DeviceGuess]:
+    """Guess the type of a device from what was observed of it.
+
+    :return: the types with any evidence, most likely first.
+    """
+    evidence = []
+    if vendor_class:
+        evidence += _match_substring(vendor_class, VENDOR_CLASS_RULES)
+    requested = set(parameter_request_list)
+    evidence += [
+        (device_type, weight)
+        for option, device_type, weight in PARAMETER_REQUEST_RULES
+        if option in requested
+    ]
+    services = set(mdns_service_types)
+    evidence += [
+        (device_type, weight)
+        for service, device_type, weight in MDNS_SERVICE_RULES
+        if service in services
+    ]
+    if mac_organization:
+        evidence += _match_substring(mac_organization, ORGANIZATION_RULES)
+
+    # The chance that every piece of evidence for a type is wrong.
+    doubt = {}
+    for device_type, weight in evidence:
+        doubt[device_type] = doubt.get(device_type, 1.0) * (1 - weight)
+    guesses = [
+        DeviceGuess(device_type, round(1 - value, 3))
+        for device_type, value in doubt.items()
+    ]
+    return sorted(guesses, key=lambda guess: -guess.confidence)
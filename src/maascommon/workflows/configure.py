@@ -1,7 +1,7 @@
 #  Copyright 2024 Canonical Ltd.  This software is licensed under the
 #  GNU Affero General Public License version 3 (see the file LICENSE).
 
-from dataclasses import dataclass
+from dataclasses import dataclass, field
 
 # Workflows names
 CONFIGURE_AGENT_WORKFLOW_NAME = "configure-agent"
@@ -15,9 +15,20 @@ CONFIGURE_RESOLVER_SERVICE_WORKFLOW_NAME = "configure-resolver-service"
 
 
 # Workflows parameters
+@dataclass
+class AgentCapability:
+    """A capability probed by the Agent at startup, e.g. raw-sockets."""
+
+    name: str
+    available: bool
+    reason: str = ""
+
+
 @dataclass
 class ConfigureAgentParam:
     system_id: str
+    # Reported by the Agent, empty when the workflow is started by the Region.
+    capabilities: list[AgentCapability] = field(default_factory=list)
 
 
 @dataclass
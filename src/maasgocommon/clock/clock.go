@@ -0,0 +1,103 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+// Package clock abstracts the passage of time.
+//
+// Code that reads the time or waits on timers and tickers takes a Clock,
+// which is the system clock in production. Tests pass a Fake instead and
+// move it forward explicitly, so that TTLs, schedules and backoffs can be
+// exercised without sleeping.
+package clock
+
+import "time"
+
+// Clock tells the time and creates timers and tickers.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// Since returns the time elapsed since t.
+	Since(t time.Time) time.Duration
+	// After returns a channel receiving the current time once d elapsed.
+	After(d time.Duration) <-chan time.Time
+	// NewTimer returns a Timer firing once d elapsed.
+	NewTimer(d time.Duration) Timer
+	// NewTicker returns a Ticker firing every d. It panics if d is not
+	// positive.
+	NewTicker(d time.Duration) Ticker
+}
+
+// Timer is the interface of time.Timer.
+type Timer interface {
+	// C returns the channel the time is delivered on.
+	C() <-chan time.Time
+	// Stop prevents the Timer from firing. It returns false if the timer
+	// already fired or was stopped.
+	Stop() bool
+	// Reset makes the Timer fire once d elapsed. It returns false if the
+	// timer already fired or was stopped.
+	Reset(d time.Duration) bool
+}
+
+// Ticker is the interface of time.Ticker.
+type Ticker interface {
+	// C returns the channel the ticks are delivered on.
+	C() <-chan time.Time
+	// Stop turns off the Ticker.
+	Stop()
+	// Reset stops the Ticker and resets its period to d.
+	Reset(d time.Duration)
+}
+
+// New returns the system clock.
+func New() Clock {
+	return realClock{}
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) Since(t time.Time) time.Duration {
+	return time.Since(t)
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+func (realClock) NewTimer(d time.Duration) Timer {
+	return realTimer{time.NewTimer(d)}
+}
+
+func (realClock) NewTicker(d time.Duration) Ticker {
+	return realTicker{time.NewTicker(d)}
+}
+
+type realTimer struct {
+	*time.Timer
+}
+
+func (t realTimer) C() <-chan time.Time {
+	return t.Timer.C
+}
+
+type realTicker struct {
+	*time.Ticker
+}
+
+func (t realTicker) C() <-chan time.Time {
+	return t.Ticker.C
+}
@@ -0,0 +1,265 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package clock
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Fake is a Clock whose time only moves when told to, for tests.
+//
+// Timers and tickers fire synchronously from Advance, in the order of their
+// deadlines. Like time.Ticker, a ticker whose channel is full drops ticks.
+// Since code under test usually creates its timers in another goroutine,
+// BlockUntil lets tests wait for them before moving the clock.
+type Fake struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []*fakeTimer
+	// changed is closed and replaced whenever waiters change.
+	changed chan struct{}
+}
+
+// NewFake returns a Fake clock set to now.
+func NewFake(now time.Time) *Fake {
+	return &Fake{
+		now:     now,
+		changed: make(chan struct{}),
+	}
+}
+
+// Now returns the current time of the clock.
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.now
+}
+
+// Since returns the time elapsed on the clock since t.
+func (f *Fake) Since(t time.Time) time.Duration {
+	return f.Now().Sub(t)
+}
+
+// After returns a channel receiving the time of the clock once d elapsed.
+func (f *Fake) After(d time.Duration) <-chan time.Time {
+	return f.NewTimer(d).C()
+}
+
+// NewTimer returns a Timer firing once the clock moved by d.
+func (f *Fake) NewTimer(d time.Duration) Timer {
+	t := &fakeTimer{clock: f, c: make(chan time.Time, 1)}
+	t.Reset(d)
+
+	return t
+}
+
+// NewTicker returns a Ticker firing every time the clock moved by d.
+func (f *Fake) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("non-positive interval for NewTicker")
+	}
+
+	t := fakeTicker{&fakeTimer{clock: f, c: make(chan time.Time, 1)}}
+	t.Reset(d)
+
+	return t
+}
+
+// Advance moves the clock forward by d, firing the timers and tickers that
+// are due on the way.
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	end := f.now.Add(d)
+
+	for {
+		next := f.next()
+		if next == nil || next.deadline.After(end) {
+			break
+		}
+
+		f.now = next.deadline
+		next.fire(f.now)
+
+		if next.period > 0 {
+			next.deadline = next.deadline.Add(next.period)
+		} else {
+			f.remove(next)
+		}
+	}
+
+	f.now = end
+}
+
+// Waiters returns the number of active timers and tickers.
+func (f *Fake) Waiters() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return len(f.waiters)
+}
+
+// BlockUntil waits until at least n timers and tickers are active, or ctx
+// is done.
+func (f *Fake) BlockUntil(ctx context.Context, n int) error {
+	for {
+		f.mu.Lock()
+		active, changed := len(f.waiters), f.changed
+		f.mu.Unlock()
+
+		if active >= n {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-changed:
+		}
+	}
+}
+
+// next returns the waiter with the earliest deadline. f.mu must be held.
+func (f *Fake) next() *fakeTimer {
+	var next *fakeTimer
+
+	for _, t := range f.waiters {
+		if next == nil || t.deadline.Before(next.deadline) {
+			next = t
+		}
+	}
+
+	return next
+}
+
+// add registers t, unless it already is. f.mu must be held.
+func (f *Fake) add(t *fakeTimer) bool {
+	for _, w := range f.waiters {
+		if w == t {
+			return true
+		}
+	}
+
+	f.waiters = append(f.waiters, t)
+	f.notify()
+
+	return false
+}
+
+// remove unregisters t and reports whether it was registered. f.mu must be
+// held.
+func (f *Fake) remove(t *fakeTimer) bool {
+	for i, w := range f.waiters {
+		if w == t {
+			f.waiters = append(f.waiters[:i], f.waiters[i+1:]...)
+			f.notify()
+
+			return true
+		}
+	}
+
+	return false
+}
+
+func (f *Fake) notify() {
+	close(f.changed)
+	f.changed = make(chan struct{})
+}
+
+// fakeTimer implements both timers (without period) and tickers.
+type fakeTimer struct {
+	clock    *Fake
+	c        chan time.Time
+	deadline time.Time
+	period   time.Duration
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	t.drain()
+
+	return t.clock.remove(t)
+}
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	t.drain()
+	t.deadline = t.clock.now.Add(d)
+
+	if d <= 0 {
+		// Like time.Timer, fire right away.
+		active := t.clock.remove(t)
+		t.fire(t.clock.now)
+
+		return active
+	}
+
+	return t.clock.add(t)
+}
+
+// fire delivers now without blocking. t.clock.mu must be held.
+func (t *fakeTimer) fire(now time.Time) {
+	select {
+	case t.c <- now:
+	default:
+	}
+}
+
+// drain discards a pending tick, which time.Timer also guarantees on Stop
+// and Reset. t.clock.mu must be held.
+func (t *fakeTimer) drain() {
+	select {
+	case <-t.c:
+	default:
+	}
+}
+
+type fakeTicker struct {
+	t *fakeTimer
+}
+
+func (t fakeTicker) C() <-chan time.Time {
+	return t.t.c
+}
+
+func (t fakeTicker) Stop() {
+	t.t.Stop()
+}
+
+func (t fakeTicker) Reset(d time.Duration) {
+	if d <= 0 {
+		panic("non-positive interval for Ticker.Reset")
+	}
+
+	t.t.clock.mu.Lock()
+	defer t.t.clock.mu.Unlock()
+
+	t.t.drain()
+	t.t.period = d
+	t.t.deadline = t.t.clock.now.Add(d)
+	t.t.clock.add(t.t)
+}
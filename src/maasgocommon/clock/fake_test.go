@@ -0,0 +1,158 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package clock
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var epoch = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func fired(c <-chan time.Time) (time.Time, bool) {
+	select {
+	case ts := <-c:
+		return ts, true
+	default:
+		return time.Time{}, false
+	}
+}
+
+func TestFakeNow(t *testing.T) {
+	c := NewFake(epoch)
+	assert.Equal(t, epoch, c.Now())
+
+	c.Advance(time.Minute)
+	assert.Equal(t, epoch.Add(time.Minute), c.Now())
+	assert.Equal(t, time.Minute, c.Since(epoch))
+}
+
+func TestFakeTimer(t *testing.T) {
+	c := NewFake(epoch)
+	timer := c.NewTimer(time.Second)
+
+	c.Advance(999 * time.Millisecond)
+
+	_, ok := fired(timer.C())
+	assert.False(t, ok)
+
+	c.Advance(time.Second)
+
+	ts, ok := fired(timer.C())
+	assert.True(t, ok)
+	assert.Equal(t, epoch.Add(time.Second), ts, "timers fire at their deadline")
+	assert.Equal(t, 0, c.Waiters())
+	assert.False(t, timer.Stop())
+
+	assert.False(t, timer.Reset(time.Second))
+	assert.True(t, timer.Stop())
+
+	c.Advance(time.Hour)
+
+	_, ok = fired(timer.C())
+	assert.False(t, ok)
+}
+
+func TestFakeTimerNotPositive(t *testing.T) {
+	c := NewFake(epoch)
+
+	ts, ok := fired(c.After(0))
+	assert.True(t, ok)
+	assert.Equal(t, epoch, ts)
+}
+
+func TestFakeTicker(t *testing.T) {
+	c := NewFake(epoch)
+	ticker := c.NewTicker(time.Second)
+
+	c.Advance(time.Second)
+
+	ts, ok := fired(ticker.C())
+	assert.True(t, ok)
+	assert.Equal(t, epoch.Add(time.Second), ts)
+
+	// Like time.Ticker, ticks are dropped while nobody reads them.
+	c.Advance(3 * time.Second)
+
+	ts, ok = fired(ticker.C())
+	assert.True(t, ok)
+	assert.Equal(t, epoch.Add(2*time.Second), ts)
+
+	_, ok = fired(ticker.C())
+	assert.False(t, ok)
+
+	ticker.Reset(time.Minute)
+	c.Advance(time.Second)
+
+	_, ok = fired(ticker.C())
+	assert.False(t, ok)
+
+	ticker.Stop()
+	assert.Equal(t, 0, c.Waiters())
+
+	assert.Panics(t, func() { c.NewTicker(0) })
+}
+
+func TestFakeOrder(t *testing.T) {
+	c := NewFake(epoch)
+	late := c.NewTimer(2 * time.Second)
+	early := c.NewTimer(time.Second)
+
+	c.Advance(time.Minute)
+
+	ts, ok := fired(early.C())
+	require.True(t, ok)
+	assert.Equal(t, epoch.Add(time.Second), ts)
+
+	ts, ok = fired(late.C())
+	require.True(t, ok)
+	assert.Equal(t, epoch.Add(2*time.Second), ts)
+}
+
+func TestFakeBlockUntil(t *testing.T) {
+	c := NewFake(epoch)
+	done := make(chan time.Time)
+
+	go func() {
+		done <- <-c.After(time.Minute)
+	}()
+
+	require.NoError(t, c.BlockUntil(context.Background(), 1))
+	c.Advance(time.Minute)
+	assert.Equal(t, epoch.Add(time.Minute), <-done)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	assert.ErrorIs(t, c.BlockUntil(ctx, 1), context.Canceled)
+}
+
+func TestReal(t *testing.T) {
+	c := New()
+
+	timer := c.NewTimer(time.Millisecond)
+	<-timer.C()
+	assert.False(t, timer.Stop())
+
+	ticker := c.NewTicker(time.Millisecond)
+	<-ticker.C()
+	ticker.Stop()
+
+	assert.WithinDuration(t, time.Now(), c.Now(), time.Second)
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package configschema
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Markdown writes a reference of all configuration properties as a table.
+func (s *Schema) Markdown(w io.Writer) error {
+	var b strings.Builder
+
+	if s.Title != "" {
+		fmt.Fprintf(&b, "# %s\n\n", s.Title)
+	}
+
+	if s.Description != "" {
+		fmt.Fprintf(&b, "%s\n\n", s.Description)
+	}
+
+	b.WriteString("| Key | Type | Default | Description |\n")
+	b.WriteString("|-----|------|---------|-------------|\n")
+
+	s.markdownRows(&b, "")
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
+
+func (s *Schema) markdownRows(b *strings.Builder, prefix string) {
+	for _, name := range s.order {
+		prop := s.Properties[name]
+		key := prefix + name
+
+		var notes []string
+
+		if prop.Description != "" {
+			notes = append(notes, prop.Description)
+		}
+
+		notes = append(notes, constraints(s, name, prop)...)
+
+		typ := prop.Type
+		if prop.Type == "array" && prop.Items != nil {
+			typ = "array of " + prop.Items.Type
+		}
+
+		var def string
+		if prop.Default != nil {
+			def = fmt.Sprintf("`%v`", prop.Default)
+		}
+
+		fmt.Fprintf(b, "| `%s` | %s | %s | %s |\n", key, typ, def,
+			strings.ReplaceAll(strings.Join(notes, " "), "|", `\|`))
+
+		switch {
+		case prop.Type == "object":
+			prop.markdownRows(b, key+".")
+		case prop.Type == "array" && prop.Items != nil && prop.Items.Type == "object":
+			prop.Items.markdownRows(b, key+"[].")
+		}
+	}
+}
+
+func constraints(parent *Schema, name string, prop *Schema) []string {
+	var notes []string
+
+	for _, required := range parent.Required {
+		if required == name {
+			notes = append(notes, "Required.")
+		}
+	}
+
+	if len(prop.Enum) > 0 {
+		notes = append(notes, fmt.Sprintf("One of: `%s`.", strings.Join(prop.Enum, "`, `")))
+	}
+
+	switch {
+	case prop.Minimum != nil && prop.Maximum != nil:
+		notes = append(notes, fmt.Sprintf("Between %v and %v.", *prop.Minimum, *prop.Maximum))
+	case prop.Minimum != nil:
+		notes = append(notes, fmt.Sprintf("At least %v.", *prop.Minimum))
+	case prop.Maximum != nil:
+		notes = append(notes, fmt.Sprintf("At most %v.", *prop.Maximum))
+	}
+
+	if prop.Pattern != "" {
+		notes = append(notes, fmt.Sprintf("Must match `%s`.", prop.Pattern))
+	}
+
+	return notes
+}
@@ -0,0 +1,242 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package configschema generates a JSON Schema from configuration structs
+// and validates configuration documents against it.
+//
+// Property names are taken from `yaml` tags. Descriptions come from `doc`
+// tags and constraints from `schema` tags, a comma separated list of:
+//
+//	required          the property must be set
+//	enum=a|b          the value must be one of the listed strings
+//	min=N, max=N      inclusive bounds of an integer or number
+//	pattern=RE        regular expression a string must match (no commas)
+//	default=V         value used when the property is not set
+//
+// Types can provide their own schema by implementing Describer.
+package configschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const draft = "https://json-schema.org/draft/2020-12/schema"
+
+// durationPattern matches values accepted by time.ParseDuration.
+const durationPattern = `^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`
+
+// Schema is the subset of JSON Schema used to describe configuration.
+type Schema struct {
+	Schema      string             `json:"$schema,omitempty"`
+	Title       string             `json:"title,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Type        string             `json:"type,omitempty"`
+	Properties  map[string]*Schema `json:"properties,omitempty"`
+	Required    []string           `json:"required,omitempty"`
+	// AdditionalProperties is either a bool or a *Schema of map values.
+	AdditionalProperties any      `json:"additionalProperties,omitempty"`
+	Items                *Schema  `json:"items,omitempty"`
+	Enum                 []string `json:"enum,omitempty"`
+	Minimum              *float64 `json:"minimum,omitempty"`
+	Maximum              *float64 `json:"maximum,omitempty"`
+	Pattern              string   `json:"pattern,omitempty"`
+	Default              any      `json:"default,omitempty"`
+	// order keeps properties in declaration order, for documentation.
+	order []string
+}
+
+// Describer is implemented by types which provide their own schema,
+// e.g. values with custom YAML unmarshalling.
+type Describer interface {
+	JSONSchema() *Schema
+}
+
+var describerType = reflect.TypeFor[Describer]()
+
+// Generate returns the schema of v, which must be a struct (or a pointer
+// to a struct).
+func Generate(v any, title string) *Schema {
+	s := generate(reflect.TypeOf(v))
+	s.Schema = draft
+	s.Title = title
+
+	return s
+}
+
+// JSON returns the schema encoded as indented JSON.
+func (s *Schema) JSON() ([]byte, error) {
+	return json.MarshalIndent(s, "", "  ")
+}
+
+func generate(t reflect.Type) *Schema {
+	if t.Kind() == reflect.Pointer {
+		return generate(t.Elem())
+	}
+
+	if t.Implements(describerType) {
+		if d, ok := reflect.Zero(t).Interface().(Describer); ok {
+			return d.JSONSchema()
+		}
+	}
+
+	if t == reflect.TypeFor[time.Duration]() {
+		return &Schema{Type: "string", Pattern: durationPattern}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: generate(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: generate(t.Elem())}
+	case reflect.Struct:
+		return generateStruct(t)
+	default:
+		return &Schema{}
+	}
+}
+
+func generateStruct(t reflect.Type) *Schema {
+	s := &Schema{
+		Type:                 "object",
+		Properties:           make(map[string]*Schema),
+		AdditionalProperties: false,
+	}
+
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, inline := yamlName(field)
+
+		switch {
+		case name == "-":
+			continue
+		case inline:
+			embedded := generate(field.Type)
+			for _, name := range embedded.order {
+				s.Properties[name] = embedded.Properties[name]
+				s.order = append(s.order, name)
+			}
+
+			s.Required = append(s.Required, embedded.Required...)
+
+			continue
+		}
+
+		prop := generate(field.Type)
+		prop.Description = field.Tag.Get("doc")
+
+		if applyConstraints(prop, field.Tag.Get("schema")) {
+			s.Required = append(s.Required, name)
+		}
+
+		s.Properties[name] = prop
+		s.order = append(s.order, name)
+	}
+
+	return s
+}
+
+// yamlName returns the property name of a struct field and reports
+// whether the field is inlined.
+func yamlName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("yaml")
+	name, opts, _ := strings.Cut(tag, ",")
+
+	if slices.Contains(strings.Split(opts, ","), "inline") {
+		return "", true
+	}
+
+	if name == "" {
+		name = strings.ToLower(field.Name)
+	}
+
+	return name, false
+}
+
+// applyConstraints applies a `schema` tag to s and reports whether the
+// property is required. Malformed tags are programming errors.
+func applyConstraints(s *Schema, tag string) bool {
+	var required bool
+
+	for _, opt := range strings.Split(tag, ",") {
+		key, value, _ := strings.Cut(opt, "=")
+
+		switch key {
+		case "":
+		case "required":
+			required = true
+		case "enum":
+			s.Enum = strings.Split(value, "|")
+		case "min":
+			s.Minimum = mustFloat(value)
+		case "max":
+			s.Maximum = mustFloat(value)
+		case "pattern":
+			s.Pattern = value
+		case "default":
+			s.Default = defaultValue(s.Type, value)
+		default:
+			panic(fmt.Sprintf("configschema: unknown constraint %q", key))
+		}
+	}
+
+	return required
+}
+
+func mustFloat(value string) *float64 {
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		panic(fmt.Sprintf("configschema: invalid bound %q", value))
+	}
+
+	return &f
+}
+
+func defaultValue(typ, value string) any {
+	switch typ {
+	case "integer":
+		if i, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return i
+		}
+	case "number":
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+
+	return value
+}
@@ -0,0 +1,179 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package configschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type size string
+
+func (size) JSONSchema() *Schema {
+	return &Schema{Type: "string", Pattern: `^[0-9]+(MB|GB)$`}
+}
+
+type testConfig struct {
+	Controller string `yaml:"controller" doc:"Controller URL." schema:"required"`
+	Cache      struct {
+		Size    size          `yaml:"size" doc:"Cache size."`
+		Timeout time.Duration `yaml:"timeout"`
+	} `yaml:"cache"`
+	Level     string            `yaml:"level" schema:"enum=debug|info,default=info"`
+	Port      int               `yaml:"port" schema:"min=1,max=65535,default=5432"`
+	Listeners []testListener    `yaml:"listeners"`
+	Labels    map[string]string `yaml:"labels"`
+	Internal  string            `yaml:"-"`
+}
+
+type testListener struct {
+	Network string `yaml:"network" schema:"required,enum=unix|tcp"`
+}
+
+func TestGenerate(t *testing.T) {
+	s := Generate(testConfig{}, "Test")
+
+	data, err := s.JSON()
+	require.NoError(t, err)
+
+	var got map[string]any
+	require.NoError(t, json.Unmarshal(data, &got))
+
+	assert.Equal(t, draft, got["$schema"])
+	assert.Equal(t, []any{"controller"}, got["required"])
+	assert.Equal(t, false, got["additionalProperties"])
+
+	props, ok := got["properties"].(map[string]any)
+	require.True(t, ok)
+	assert.NotContains(t, props, "Internal")
+	assert.Equal(t, map[string]any{
+		"type": "integer", "minimum": 1.0, "maximum": 65535.0, "default": 5432.0,
+	}, props["port"])
+	assert.Equal(t, map[string]any{
+		"type": "string", "enum": []any{"debug", "info"}, "default": "info",
+	}, props["level"])
+	assert.Equal(t, map[string]any{
+		"type": "object", "additionalProperties": map[string]any{"type": "string"},
+	}, props["labels"])
+}
+
+func TestValidateYAML(t *testing.T) {
+	s := Generate(testConfig{}, "Test")
+
+	testcases := map[string]struct {
+		in   string
+		errs []string
+	}{
+		"valid": {
+			in: `
+controller: http://10.0.0.1:5240/MAAS
+cache:
+  size: 20GB
+  timeout: 1m30s
+level: debug
+port: 5433
+listeners:
+  - network: unix
+labels:
+  rack: r1
+`,
+		},
+		"missing required": {
+			in:   `level: info`,
+			errs: []string{"controller: is required"},
+		},
+		"violations": {
+			in: `
+controller: http://10.0.0.1:5240/MAAS
+cache:
+  size: 20TB
+  timeout: forever
+level: trace
+port: 0
+listeners:
+  - network: udp
+  - {}
+unknown: true
+`,
+			errs: []string{
+				`cache.size: "20TB" does not match ^[0-9]+(MB|GB)$`,
+				`cache.timeout: "forever" does not match ` + durationPattern,
+				"level: must be one of [debug info]",
+				"listeners[0].network: must be one of [unix tcp]",
+				"listeners[1].network: is required",
+				"port: must be at least 1",
+				"unknown: unknown property",
+			},
+		},
+		"wrong types": {
+			in: `
+controller: [a]
+port: "80"
+labels: {rack: 1}
+`,
+			errs: []string{
+				"controller: must be a string",
+				"labels.rack: must be a string",
+				"port: must be an integer",
+			},
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			err := s.ValidateYAML([]byte(tc.in))
+			if len(tc.errs) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+
+			var (
+				joined interface{ Unwrap() []error }
+				got    []string
+			)
+
+			require.ErrorAs(t, err, &joined)
+
+			for _, e := range joined.Unwrap() {
+				var verr *ValidationError
+				require.True(t, errors.As(e, &verr))
+
+				got = append(got, verr.Error())
+			}
+
+			assert.Equal(t, tc.errs, got)
+		})
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+
+	require.NoError(t, Generate(testConfig{}, "Test").Markdown(&buf))
+
+	out := buf.String()
+	assert.Contains(t, out, "# Test\n")
+	assert.Contains(t, out, "| `controller` | string |  | Controller URL. Required. |\n")
+	assert.Contains(t, out, "| `cache.size` | string |  | Cache size. Must match `^[0-9]+(MB\\|GB)$`. |\n")
+	assert.Contains(t, out, "| `port` | integer | `5432` | Between 1 and 65535. |\n")
+	assert.Contains(t, out, "| `listeners[].network` | string |  | Required. One of: `unix`, `tcp`. |\n")
+}
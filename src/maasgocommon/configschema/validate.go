@@ -0,0 +1,212 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package configschema
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ValidationError describes a property violating the schema.
+type ValidationError struct {
+	// Path of the property, e.g. "services.dns.cache.size"
+	Path    string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+
+	return e.Path + ": " + e.Message
+}
+
+// ValidateYAML decodes a YAML document and validates it against s.
+func (s *Schema) ValidateYAML(data []byte) error {
+	var doc any
+
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("invalid YAML: %w", err)
+	}
+
+	if doc == nil {
+		doc = map[string]any{}
+	}
+
+	return s.Validate(doc)
+}
+
+// Validate checks a decoded document against s. All violations are
+// returned (joined), as *ValidationError.
+func (s *Schema) Validate(doc any) error {
+	var errs []error
+
+	s.validate("", doc, &errs)
+
+	return errors.Join(errs...)
+}
+
+func (s *Schema) validate(path string, v any, errs *[]error) {
+	fail := func(format string, args ...any) {
+		*errs = append(*errs, &ValidationError{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	switch s.Type {
+	case "object":
+		m, ok := toMap(v)
+		if !ok {
+			fail("must be an object")
+			return
+		}
+
+		s.validateObject(path, m, errs)
+	case "array":
+		items, ok := v.([]any)
+		if !ok {
+			fail("must be an array")
+			return
+		}
+
+		if s.Items != nil {
+			for i, item := range items {
+				s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item, errs)
+			}
+		}
+	case "string":
+		str, ok := v.(string)
+		if !ok {
+			fail("must be a string")
+			return
+		}
+
+		if s.Pattern != "" {
+			re, err := regexp.Compile(s.Pattern)
+			if err != nil {
+				fail("invalid pattern in schema: %v", err)
+				return
+			}
+
+			if !re.MatchString(str) {
+				fail("%q does not match %s", str, s.Pattern)
+			}
+		}
+	case "integer", "number":
+		n, ok := toFloat(v)
+		if !ok || (s.Type == "integer" && n != math.Trunc(n)) {
+			fail("must be an %s", s.Type)
+			return
+		}
+
+		if s.Minimum != nil && n < *s.Minimum {
+			fail("must be at least %v", *s.Minimum)
+		}
+
+		if s.Maximum != nil && n > *s.Maximum {
+			fail("must be at most %v", *s.Maximum)
+		}
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			fail("must be a boolean")
+			return
+		}
+	}
+
+	if len(s.Enum) > 0 && !slices.Contains(s.Enum, fmt.Sprint(v)) {
+		fail("must be one of %v", s.Enum)
+	}
+}
+
+func (s *Schema) validateObject(path string, m map[string]any, errs *[]error) {
+	join := func(key string) string {
+		if path == "" {
+			return key
+		}
+
+		return path + "." + key
+	}
+
+	for _, key := range s.Required {
+		if m[key] == nil {
+			*errs = append(*errs, &ValidationError{Path: join(key), Message: "is required"})
+		}
+	}
+
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := m[key]
+		if value == nil {
+			continue
+		}
+
+		if prop, ok := s.Properties[key]; ok {
+			prop.validate(join(key), value, errs)
+			continue
+		}
+
+		switch additional := s.AdditionalProperties.(type) {
+		case *Schema:
+			additional.validate(join(key), value, errs)
+		case bool:
+			if !additional {
+				*errs = append(*errs, &ValidationError{Path: join(key), Message: "unknown property"})
+			}
+		}
+	}
+}
+
+func toMap(v any) (map[string]any, bool) {
+	switch v := v.(type) {
+	case map[string]any:
+		return v, true
+	case map[any]any:
+		m := make(map[string]any, len(v))
+		for key, value := range v {
+			m[fmt.Sprint(key)] = value
+		}
+
+		return m, true
+	}
+
+	return nil, false
+}
+
+func toFloat(v any) (float64, bool) {
+	switch v := v.(type) {
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,273 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package crash records the state of the process when it fails, for the
+// post-mortem analysis of failures that can't be reproduced.
+//
+// Every report is a directory of the crash directory, named after the time
+// of the failure, holding:
+//
+//	cause.txt       the error or the panic, with its stack
+//	goroutines.txt  the stacks of all goroutines
+//	heap.pprof      a heap profile, to read with go tool pprof
+//	log.txt         the last lines logged through Recorder.Writer
+//	runtime.txt     the traceback written by the Go runtime, instead of the
+//	                other files, for crashes Recover can't catch
+//
+// Only the most recent reports are kept.
+package crash
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"runtime/pprof"
+	"slices"
+	"strings"
+	"time"
+
+	"maas.io/core/src/maasgocommon/clock"
+)
+
+const (
+	defaultKeep     = 5
+	defaultLogLines = 1000
+
+	reportPrefix = "crash-"
+	// reportTime sorts reports by name in chronological order.
+	reportTime  = "20060102T150405.000000000Z"
+	runtimeFile = "runtime.txt"
+)
+
+// Recorder writes crash reports to a directory.
+type Recorder struct {
+	clock clock.Clock
+	logs  *ring
+	dir   string
+	keep  int
+}
+
+// Option configures a Recorder.
+type Option func(*Recorder)
+
+// WithKeep sets the number of reports kept, 5 by default.
+func WithKeep(n int) Option {
+	return func(r *Recorder) {
+		if n > 0 {
+			r.keep = n
+		}
+	}
+}
+
+// WithLogLines sets the number of log lines kept for reports, 1000 by
+// default.
+func WithLogLines(n int) Option {
+	return func(r *Recorder) {
+		if n > 0 {
+			r.logs = newRing(n)
+		}
+	}
+}
+
+// WithClock sets the clock naming reports.
+func WithClock(clk clock.Clock) Option {
+	return func(r *Recorder) {
+		r.clock = clk
+	}
+}
+
+// New returns a Recorder writing reports to dir, which is created when the
+// first report is written.
+func New(dir string, options ...Option) *Recorder {
+	r := &Recorder{
+		clock: clock.New(),
+		logs:  newRing(defaultLogLines),
+		dir:   dir,
+		keep:  defaultKeep,
+	}
+
+	for _, opt := range options {
+		opt(r)
+	}
+
+	return r
+}
+
+// Writer returns a writer copying to w what is written to it, keeping the
+// last lines for reports. Secrets should be masked before.
+func (r *Recorder) Writer(w io.Writer) io.Writer {
+	return io.MultiWriter(w, r.logs)
+}
+
+// Record writes a report of the failure cause and returns its directory.
+// A partial report may be written along with an error.
+func (r *Recorder) Record(cause string) (string, error) {
+	now := r.clock.Now()
+
+	dir, err := r.newReport(now)
+	if err != nil {
+		return "", err
+	}
+
+	files := []struct {
+		write func(io.Writer) error
+		name  string
+	}{
+		{name: "cause.txt", write: func(w io.Writer) error {
+			_, err := fmt.Fprintf(w, "%s\n\ntime: %s\ngo: %s\n",
+				strings.TrimSpace(cause), now.UTC().Format(time.RFC3339Nano), runtime.Version())
+
+			return err
+		}},
+		{name: "goroutines.txt", write: func(w io.Writer) error {
+			return pprof.Lookup("goroutine").WriteTo(w, 2)
+		}},
+		{name: "heap.pprof", write: func(w io.Writer) error {
+			// Up to date statistics, rather than those of the last GC.
+			runtime.GC()
+
+			return pprof.Lookup("heap").WriteTo(w, 0)
+		}},
+		{name: "log.txt", write: r.logs.writeTo},
+	}
+
+	var errs []error
+
+	for _, f := range files {
+		if err := writeFile(filepath.Join(dir, f.name), f.write); err != nil {
+			errs = append(errs, fmt.Errorf("failed to write %s: %w", f.name, err))
+		}
+	}
+
+	errs = append(errs, r.rotate())
+
+	return dir, errors.Join(errs...)
+}
+
+// Recover records a report of a panic of the calling goroutine, which
+// then goes on panicking. It must be deferred.
+func (r *Recorder) Recover() {
+	if v := recover(); v != nil {
+		//nolint:errcheck // the panic matters more than the report
+		_, _ = r.Record(fmt.Sprintf("panic: %v\n\n%s", v, debug.Stack()))
+
+		panic(v)
+	}
+}
+
+// CaptureRuntimeCrashes makes the Go runtime write the traceback of the
+// crashes Recover can't catch, such as panics of other goroutines or fatal
+// errors, to the crash directory as well as stderr. Tracebacks then show all
+// goroutines. Since the process is gone by then, the traceback becomes a
+// report when CaptureRuntimeCrashes is next called, at the next start.
+func (r *Recorder) CaptureRuntimeCrashes() error {
+	if err := os.MkdirAll(r.dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create crash directory: %w", err)
+	}
+
+	path := filepath.Join(r.dir, runtimeFile)
+
+	if info, err := os.Stat(path); err == nil && info.Size() > 0 {
+		if err := r.collect(path, info.ModTime()); err != nil {
+			return err
+		}
+	}
+
+	f, err := os.OpenFile(filepath.Clean(path), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open runtime crash output: %w", err)
+	}
+
+	// The runtime duplicates the file descriptor.
+	defer f.Close() //nolint:errcheck // nothing was written
+
+	debug.SetTraceback("all")
+
+	if err := debug.SetCrashOutput(f, debug.CrashOptions{}); err != nil {
+		return fmt.Errorf("failed to set runtime crash output: %w", err)
+	}
+
+	return nil
+}
+
+// collect moves the runtime traceback at path to a report of the time of
+// the crash.
+func (r *Recorder) collect(path string, t time.Time) error {
+	dir, err := r.newReport(t)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Rename(path, filepath.Join(dir, runtimeFile)); err != nil {
+		return fmt.Errorf("failed to move runtime crash output: %w", err)
+	}
+
+	return r.rotate()
+}
+
+func (r *Recorder) newReport(t time.Time) (string, error) {
+	dir := filepath.Join(r.dir, reportPrefix+t.UTC().Format(reportTime))
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("failed to create crash report directory: %w", err)
+	}
+
+	return dir, nil
+}
+
+// rotate removes the oldest reports beyond the number kept.
+func (r *Recorder) rotate() error {
+	entries, err := os.ReadDir(r.dir)
+	if err != nil {
+		return fmt.Errorf("failed to list crash reports: %w", err)
+	}
+
+	var reports []string
+
+	for _, e := range entries {
+		if e.IsDir() && strings.HasPrefix(e.Name(), reportPrefix) {
+			reports = append(reports, e.Name())
+		}
+	}
+
+	slices.Sort(reports)
+
+	var errs []error
+
+	for len(reports) > r.keep {
+		if err := os.RemoveAll(filepath.Join(r.dir, reports[0])); err != nil {
+			errs = append(errs, fmt.Errorf("failed to remove crash report: %w", err))
+		}
+
+		reports = reports[1:]
+	}
+
+	return errors.Join(errs...)
+}
+
+func writeFile(path string, write func(io.Writer) error) error {
+	f, err := os.OpenFile(filepath.Clean(path), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+
+	err = write(f)
+
+	return errors.Join(err, f.Close())
+}
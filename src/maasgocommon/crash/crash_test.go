@@ -0,0 +1,167 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package crash
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"maas.io/core/src/maasgocommon/clock"
+)
+
+var epoch = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func reports(t *testing.T, dir string) []string {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+
+	var names []string
+
+	for _, e := range entries {
+		if e.IsDir() {
+			names = append(names, e.Name())
+		}
+	}
+
+	return names
+}
+
+func TestRecord(t *testing.T) {
+	dir := t.TempDir()
+	r := New(dir, WithClock(clock.NewFake(epoch)))
+
+	var out bytes.Buffer
+
+	w := r.Writer(&out)
+	fmt.Fprintln(w, "starting")
+	fmt.Fprint(w, "failing")
+
+	path, err := r.Record("service failure: database gone")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "crash-20260101T000000.000000000Z"), path)
+	assert.Equal(t, "starting\nfailing", out.String())
+
+	cause, err := os.ReadFile(filepath.Join(path, "cause.txt"))
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(cause), "service failure: database gone\n\ntime: 2026-01-01T00:00:00Z\n"))
+
+	goroutines, err := os.ReadFile(filepath.Join(path, "goroutines.txt"))
+	require.NoError(t, err)
+	assert.Contains(t, string(goroutines), "TestRecord")
+
+	heap, err := os.Stat(filepath.Join(path, "heap.pprof"))
+	require.NoError(t, err)
+	assert.NotZero(t, heap.Size())
+
+	logs, err := os.ReadFile(filepath.Join(path, "log.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "starting\nfailing", string(logs))
+}
+
+func TestRecordRotation(t *testing.T) {
+	dir := t.TempDir()
+	clk := clock.NewFake(epoch)
+	r := New(dir, WithClock(clk), WithKeep(2))
+
+	// Other files of the directory are left alone.
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "other"), 0o700))
+
+	for range 3 {
+		_, err := r.Record("failure")
+		require.NoError(t, err)
+
+		clk.Advance(time.Hour)
+	}
+
+	assert.Equal(t, []string{
+		"crash-20260101T010000.000000000Z",
+		"crash-20260101T020000.000000000Z",
+		"other",
+	}, reports(t, dir))
+}
+
+func TestRecordUnwritableDirectory(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(dir, nil, 0o600))
+
+	_, err := New(dir).Record("failure")
+	assert.ErrorContains(t, err, "failed to create crash report directory")
+}
+
+func TestRecover(t *testing.T) {
+	dir := t.TempDir()
+	r := New(dir, WithClock(clock.NewFake(epoch)))
+	errPanic := errors.New("boom")
+
+	assert.PanicsWithValue(t, errPanic, func() {
+		defer r.Recover()
+
+		panic(errPanic)
+	})
+
+	cause, err := os.ReadFile(filepath.Join(dir, "crash-20260101T000000.000000000Z", "cause.txt"))
+	require.NoError(t, err)
+	assert.Contains(t, string(cause), "panic: boom")
+	assert.Contains(t, string(cause), "TestRecover")
+}
+
+func TestRecoverWithoutPanic(t *testing.T) {
+	dir := t.TempDir()
+
+	func() {
+		defer New(dir).Recover()
+	}()
+
+	assert.Empty(t, reports(t, dir))
+}
+
+func TestCaptureRuntimeCrashes(t *testing.T) {
+	dir := t.TempDir()
+	r := New(dir)
+
+	t.Cleanup(func() {
+		require.NoError(t, debug.SetCrashOutput(nil, debug.CrashOptions{}))
+	})
+
+	// The traceback of a previous crash.
+	crashed := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	traceback := filepath.Join(dir, runtimeFile)
+	require.NoError(t, os.WriteFile(traceback, []byte("fatal error: out of memory\n"), 0o600))
+	require.NoError(t, os.Chtimes(traceback, crashed, crashed))
+
+	require.NoError(t, r.CaptureRuntimeCrashes())
+
+	data, err := os.ReadFile(filepath.Join(dir, "crash-20260101T120000.000000000Z", runtimeFile))
+	require.NoError(t, err)
+	assert.Equal(t, "fatal error: out of memory\n", string(data))
+
+	// A new, empty, output is in place for the next crash.
+	info, err := os.Stat(traceback)
+	require.NoError(t, err)
+	assert.Zero(t, info.Size())
+}
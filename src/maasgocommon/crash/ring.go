@@ -0,0 +1,92 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package crash
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// maxLineSize bounds the memory used by a line without newline.
+const maxLineSize = 64 * 1024
+
+// ring keeps the last lines written to it.
+type ring struct {
+	lines [][]byte
+	// partial is the end of the output, after the last newline.
+	partial []byte
+	// next is the index of the oldest line, once lines is full.
+	next int
+	mu   sync.Mutex
+}
+
+func newRing(lines int) *ring {
+	return &ring{lines: make([][]byte, 0, lines)}
+}
+
+// Write never fails, not to fail the writes it is teed with.
+func (r *ring) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	n := len(p)
+
+	for len(p) > 0 {
+		i := bytes.IndexByte(p, '\n')
+		if i < 0 {
+			r.partial = append(r.partial, p[:min(len(p), maxLineSize-len(r.partial))]...)
+			break
+		}
+
+		line := append(r.partial, p[:min(i+1, maxLineSize-len(r.partial))]...)
+		if line[len(line)-1] != '\n' {
+			line = append(line, '\n')
+		}
+
+		r.partial = nil
+		r.add(line)
+		p = p[i+1:]
+	}
+
+	return n, nil
+}
+
+func (r *ring) add(line []byte) {
+	if len(r.lines) < cap(r.lines) {
+		r.lines = append(r.lines, line)
+		return
+	}
+
+	r.lines[r.next] = line
+	r.next = (r.next + 1) % len(r.lines)
+}
+
+// writeTo writes the lines kept, oldest first.
+func (r *ring) writeTo(w io.Writer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.lines {
+		if _, err := w.Write(r.lines[(r.next+i)%len(r.lines)]); err != nil {
+			return err
+		}
+	}
+
+	_, err := w.Write(r.partial)
+
+	return err
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package crash
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRing(t *testing.T) {
+	testcases := map[string]struct {
+		writes []string
+		out    string
+	}{
+		"empty": {},
+		"lines": {
+			writes: []string{"a\nb\n"},
+			out:    "a\nb\n",
+		},
+		"split lines": {
+			writes: []string{"a", "\nb", "\n", "c"},
+			out:    "a\nb\nc",
+		},
+		"oldest lines dropped": {
+			writes: []string{"a\nb\nc\nd\ne\n"},
+			out:    "c\nd\ne\n",
+		},
+		"long line truncated": {
+			writes: []string{strings.Repeat("x", maxLineSize), "yyy\nz\n"},
+			out:    strings.Repeat("x", maxLineSize) + "\nz\n",
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			r := newRing(3)
+
+			for _, w := range tc.writes {
+				n, err := r.Write([]byte(w))
+				require.NoError(t, err)
+				assert.Equal(t, len(w), n)
+			}
+
+			var out bytes.Buffer
+			require.NoError(t, r.writeTo(&out))
+			assert.Equal(t, tc.out, out.String())
+		})
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package errors
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// sqlStater is implemented by Postgres errors, e.g. *pgconn.PgError, so
+// that they are classified without depending on a driver.
+type sqlStater interface {
+	SQLState() string
+}
+
+// sqlStates maps SQLSTATE codes, or their two-character class, to kinds.
+var sqlStates = map[string]Kind{
+	"02":    NotFound,         // no data
+	"08":    Unavailable,      // connection exception
+	"22":    Invalid,          // data exception
+	"23":    Conflict,         // integrity constraint violation
+	"28":    PermissionDenied, // invalid authorization specification
+	"3D000": NotFound,         // invalid catalog name, the database is missing
+	"40001": Unavailable,      // serialization failure, safe to retry
+	"40P01": Unavailable,      // deadlock detected, safe to retry
+	"42":    Invalid,          // syntax error or access rule violation
+	"42501": PermissionDenied, // insufficient privilege
+	"53":    Unavailable,      // insufficient resources
+	"57P01": Unavailable,      // admin shutdown
+	"57P02": Unavailable,      // crash shutdown
+	"57P03": Unavailable,      // cannot connect now
+	"P0002": NotFound,         // no data found
+}
+
+// grpcCodes maps gRPC codes to kinds.
+var grpcCodes = map[codes.Code]Kind{
+	codes.NotFound:           NotFound,
+	codes.AlreadyExists:      Conflict,
+	codes.Aborted:            Conflict,
+	codes.Unavailable:        Unavailable,
+	codes.DeadlineExceeded:   Unavailable,
+	codes.ResourceExhausted:  Unavailable,
+	codes.PermissionDenied:   PermissionDenied,
+	codes.Unauthenticated:    PermissionDenied,
+	codes.InvalidArgument:    Invalid,
+	codes.FailedPrecondition: Invalid,
+	codes.OutOfRange:         Invalid,
+}
+
+// FromHTTPStatus returns the kind of errors answered with the given HTTP
+// status code. Codes that aren't errors are Unknown.
+func FromHTTPStatus(code int) Kind {
+	switch code {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity, http.StatusRequestEntityTooLarge:
+		return Invalid
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return PermissionDenied
+	case http.StatusNotFound, http.StatusGone:
+		return NotFound
+	case http.StatusConflict, http.StatusPreconditionFailed:
+		return Conflict
+	case http.StatusRequestTimeout, http.StatusTooManyRequests, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return Unavailable
+	default:
+		return Unknown
+	}
+}
+
+// FromSQLState returns the kind of Postgres errors with the given SQLSTATE.
+func FromSQLState(state string) Kind {
+	if kind, ok := sqlStates[state]; ok {
+		return kind
+	}
+
+	if len(state) == 5 {
+		if kind, ok := sqlStates[state[:2]]; ok {
+			return kind
+		}
+	}
+
+	return Unknown
+}
+
+// FromGRPCCode returns the kind of errors with the given gRPC code.
+func FromGRPCCode(code codes.Code) Kind {
+	return grpcCodes[code]
+}
+
+// classify infers the kind of an error without an explicit one.
+func classify(err error) Kind {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return NotFound
+	case errors.Is(err, context.DeadlineExceeded):
+		return Unavailable
+	case errors.Is(err, context.Canceled):
+		return Unknown
+	}
+
+	// Postgres errors first: connection errors wrap them, e.g. on
+	// authentication failures, which must not be seen as network errors.
+	var pgErr sqlStater
+	if errors.As(err, &pgErr) {
+		return FromSQLState(strings.ToUpper(pgErr.SQLState()))
+	}
+
+	if st, ok := status.FromError(err); ok && st.Code() != codes.Unknown {
+		return FromGRPCCode(st.Code())
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return Unavailable
+	}
+
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		return Unavailable
+	}
+
+	return Unknown
+}
@@ -0,0 +1,156 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+// Package errors classifies errors into a few kinds, so that retries,
+// HTTP status codes and metrics labels are derived from errors the same way
+// everywhere.
+//
+// A kind is either attached explicitly with Wrap, New or Errorf, or
+// inferred by KindOf from well-known errors: Postgres errors (by SQLSTATE),
+// gRPC statuses, network errors and context errors. HTTP clients classify
+// responses with FromHTTPStatus.
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Kind is a category of errors.
+type Kind uint8
+
+const (
+	// Unknown is the kind of errors that couldn't be classified.
+	Unknown Kind = iota
+	// NotFound means the resource doesn't exist.
+	NotFound
+	// Conflict means the request conflicts with the current state, e.g.
+	// the resource already exists.
+	Conflict
+	// Unavailable means a dependency is temporarily unreachable or
+	// overloaded, and the request may succeed later.
+	Unavailable
+	// PermissionDenied means the caller isn't allowed to make the request,
+	// or couldn't be authenticated.
+	PermissionDenied
+	// Invalid means the request is malformed.
+	Invalid
+)
+
+var kindNames = [...]string{
+	Unknown:          "unknown",
+	NotFound:         "not_found",
+	Conflict:         "conflict",
+	Unavailable:      "unavailable",
+	PermissionDenied: "permission_denied",
+	Invalid:          "invalid",
+}
+
+// String returns the name of the kind, suitable as a metrics label.
+func (k Kind) String() string {
+	if int(k) < len(kindNames) {
+		return kindNames[k]
+	}
+
+	return kindNames[Unknown]
+}
+
+// HTTPStatus returns the HTTP status code for errors of the kind.
+func (k Kind) HTTPStatus() int {
+	switch k {
+	case NotFound:
+		return http.StatusNotFound
+	case Conflict:
+		return http.StatusConflict
+	case Unavailable:
+		return http.StatusServiceUnavailable
+	case PermissionDenied:
+		return http.StatusForbidden
+	case Invalid:
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+type kindError struct {
+	err  error
+	kind Kind
+}
+
+func (e *kindError) Error() string {
+	return e.err.Error()
+}
+
+func (e *kindError) Unwrap() error {
+	return e.err
+}
+
+// Wrap attaches kind to err, overriding the kind KindOf would infer. It
+// returns nil if err is nil.
+func Wrap(kind Kind, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &kindError{err: err, kind: kind}
+}
+
+// New returns an error of the given kind with the given message.
+func New(kind Kind, msg string) error {
+	return Wrap(kind, errors.New(msg))
+}
+
+// Errorf formats an error of the given kind, like fmt.Errorf.
+func Errorf(kind Kind, format string, args ...any) error {
+	return Wrap(kind, fmt.Errorf(format, args...))
+}
+
+// KindOf returns the kind of err: the kind attached by the outermost Wrap,
+// or else the kind inferred from the errors err wraps. It returns Unknown
+// for nil.
+func KindOf(err error) Kind {
+	if err == nil {
+		return Unknown
+	}
+
+	var ke *kindError
+	if errors.As(err, &ke) {
+		return ke.kind
+	}
+
+	return classify(err)
+}
+
+// Retryable reports whether err may go away if the operation is retried:
+// it is Unavailable, or Unknown and given the benefit of the doubt. nil is
+// not retryable.
+func Retryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	switch KindOf(err) {
+	case Unavailable, Unknown:
+		return true
+	default:
+		return false
+	}
+}
+
+// HTTPStatus returns the HTTP status code to answer err with.
+func HTTPStatus(err error) int {
+	return KindOf(err).HTTPStatus()
+}
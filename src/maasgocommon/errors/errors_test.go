@@ -0,0 +1,127 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package errors
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// pgError mimics *pgconn.PgError.
+type pgError struct {
+	code string
+}
+
+func (e *pgError) Error() string {
+	return "ERROR (SQLSTATE " + e.code + ")"
+}
+
+func (e *pgError) SQLState() string {
+	return e.code
+}
+
+func TestKindOf(t *testing.T) {
+	testcases := map[string]struct {
+		err  error
+		want Kind
+	}{
+		"nil":                {nil, Unknown},
+		"plain":              {errors.New("boom"), Unknown},
+		"explicit":           {New(Conflict, "taken"), Conflict},
+		"wrapped explicit":   {fmt.Errorf("creating: %w", Errorf(NotFound, "no %s", "pool")), NotFound},
+		"outermost wins":     {Wrap(Invalid, Wrap(Unavailable, errors.New("boom"))), Invalid},
+		"explicit overrides": {Wrap(PermissionDenied, sql.ErrNoRows), PermissionDenied},
+		"no rows":            {fmt.Errorf("get: %w", sql.ErrNoRows), NotFound},
+		"deadline":           {context.DeadlineExceeded, Unavailable},
+		"canceled":           {context.Canceled, Unknown},
+		"unique violation":   {&pgError{"23505"}, Conflict},
+		"serialization":      {&pgError{"40001"}, Unavailable},
+		"bad password":       {fmt.Errorf("connect: %w", &pgError{"28P01"}), PermissionDenied},
+		"missing database":   {&pgError{"3D000"}, NotFound},
+		"undefined table":    {&pgError{"42P01"}, Invalid},
+		"no privilege":       {&pgError{"42501"}, PermissionDenied},
+		"unknown sqlstate":   {&pgError{"XX000"}, Unknown},
+		"grpc not found":     {status.Error(codes.NotFound, "nope"), NotFound},
+		"grpc unavailable":   {fmt.Errorf("dial: %w", status.Error(codes.Unavailable, "down")), Unavailable},
+		"grpc unauth":        {status.Error(codes.Unauthenticated, "who"), PermissionDenied},
+		"grpc unknown":       {status.Error(codes.Unknown, "?"), Unknown},
+		"network":            {&net.OpError{Op: "dial", Err: errors.New("refused")}, Unavailable},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.want, KindOf(tc.err))
+		})
+	}
+}
+
+func TestWrap(t *testing.T) {
+	assert.NoError(t, Wrap(NotFound, nil))
+
+	err := Wrap(NotFound, sql.ErrNoRows)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.Equal(t, sql.ErrNoRows.Error(), err.Error())
+}
+
+func TestRetryable(t *testing.T) {
+	assert.False(t, Retryable(nil))
+	assert.True(t, Retryable(errors.New("boom")))
+	assert.True(t, Retryable(&pgError{"08006"}))
+	assert.False(t, Retryable(&pgError{"28P01"}))
+	assert.False(t, Retryable(New(Invalid, "bad")))
+}
+
+func TestHTTP(t *testing.T) {
+	for code, kind := range map[int]Kind{
+		http.StatusOK:                  Unknown,
+		http.StatusBadRequest:          Invalid,
+		http.StatusUnauthorized:        PermissionDenied,
+		http.StatusForbidden:           PermissionDenied,
+		http.StatusNotFound:            NotFound,
+		http.StatusConflict:            Conflict,
+		http.StatusTooManyRequests:     Unavailable,
+		http.StatusInternalServerError: Unknown,
+		http.StatusServiceUnavailable:  Unavailable,
+	} {
+		assert.Equal(t, kind, FromHTTPStatus(code), "status %d", code)
+	}
+
+	for kind, code := range map[Kind]int{
+		Unknown:          http.StatusInternalServerError,
+		NotFound:         http.StatusNotFound,
+		Conflict:         http.StatusConflict,
+		Unavailable:      http.StatusServiceUnavailable,
+		PermissionDenied: http.StatusForbidden,
+		Invalid:          http.StatusBadRequest,
+	} {
+		assert.Equal(t, code, kind.HTTPStatus(), "kind %s", kind)
+	}
+
+	assert.Equal(t, http.StatusNotFound, HTTPStatus(fmt.Errorf("get: %w", sql.ErrNoRows)))
+}
+
+func TestKindString(t *testing.T) {
+	assert.Equal(t, "permission_denied", PermissionDenied.String())
+	assert.Equal(t, "unknown", Kind(42).String())
+}
@@ -0,0 +1,186 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package redact
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	stdlog "log"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const secret = "s3cr3t-Value"
+
+func TestString(t *testing.T) {
+	testcases := map[string]struct {
+		in   string
+		want string
+	}{
+		"URL": {
+			in:   "failed to connect to postgres://maas:" + secret + "@db:5432/maasdb",
+			want: "failed to connect to postgres://maas:***@db:5432/maasdb",
+		},
+		"URL without password": {
+			in:   "GET http://maas@10.0.0.1:5240/MAAS/api",
+			want: "GET http://maas@10.0.0.1:5240/MAAS/api",
+		},
+		"libpq DSN": {
+			in:   "host=/var/run/postgresql user=maas password=" + secret + " dbname=maasdb",
+			want: "host=/var/run/postgresql user=maas password=*** dbname=maasdb",
+		},
+		"zerolog field": {
+			in:   "ERR power query failed power_address=10.0.0.5 power_pass=" + secret,
+			want: "ERR power query failed power_address=10.0.0.5 power_pass=***",
+		},
+		"quoted field": {
+			in:   `database_pass="` + secret + ` with spaces" name=maasdb`,
+			want: `database_pass=*** name=maasdb`,
+		},
+		"JSON": {
+			in:   `params={"power_user":"admin","power_pass":"` + secret + `","power_address":"10.0.0.5"}`,
+			want: `params={"power_user":"admin","power_pass":"***","power_address":"10.0.0.5"}`,
+		},
+		"YAML": {
+			in:   "secret: " + secret,
+			want: "secret: ***",
+		},
+		"query string": {
+			in:   "GET /api?access_token=" + secret + "&page=2",
+			want: "GET /api?access_token=***&page=2",
+		},
+		"authorization header": {
+			in:   "Authorization: Bearer " + secret,
+			want: "Authorization: *** ***",
+		},
+		"bearer token": {
+			in:   "sending bearer " + secret,
+			want: "sending bearer ***",
+		},
+		"case insensitive": {
+			in:   "PASSWORD=" + secret,
+			want: "PASSWORD=***",
+		},
+		"nothing to redact": {
+			in:   "INF DHCP lease allocated ip=10.0.0.7 mac=00:16:3e:12:34:56",
+			want: "INF DHCP lease allocated ip=10.0.0.7 mac=00:16:3e:12:34:56",
+		},
+	}
+
+	r := New()
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			got := r.String(tc.in)
+			assert.Equal(t, tc.want, got)
+			assert.NotContains(t, got, secret)
+		})
+	}
+}
+
+func TestOptions(t *testing.T) {
+	r := New(
+		WithFields("ipmi_k_g"),
+		WithPatterns(regexp.MustCompile(`sk-[A-Za-z0-9]{8,}`)),
+	)
+
+	assert.Equal(t, "ipmi_k_g=***", r.String("ipmi_k_g="+secret))
+	assert.Equal(t, "using ***", r.String("using sk-abcdef123456"))
+	assert.Equal(t, "power_pass=***", r.String("power_pass="+secret),
+		"default fields are still redacted")
+}
+
+// TestLoggers checks that secrets never reach the output of loggers writing
+// through a Redactor, whether they are logged as fields, in messages or in
+// errors.
+func TestLoggers(t *testing.T) {
+	var buf bytes.Buffer
+
+	w := New().Writer(&buf)
+
+	logger := stdlog.New(w, "", 0)
+	logger.Printf("connecting with password=%s", secret)
+	logger.Print(fmt.Errorf("dial postgres://maas:%s@db/maasdb: %w", secret, errors.New("refused")))
+	logger.Printf(`{"level":"info","msg":"write","token":"%s"}`, secret)
+	logger.Printf("power on power_pass=%s", secret)
+
+	out := buf.String()
+	require.NotEmpty(t, out)
+	assert.NotContains(t, out, secret)
+	assert.Contains(t, out, "power on")
+	assert.Contains(t, out, "power_pass="+Mask)
+}
+
+func TestMap(t *testing.T) {
+	type listener struct {
+		Address string `yaml:"address"`
+	}
+
+	cfg := struct {
+		Host      string     `yaml:"database_host"`
+		Pass      string     `yaml:"database_pass"`
+		User      string     `yaml:"database_user"`
+		Token     string     `yaml:"token"`
+		Port      int        `yaml:"database_port"`
+		Listeners []listener `yaml:"listeners"`
+		Nested    struct {
+			Secret string `yaml:"secret"`
+			URL    string `yaml:"url"`
+		} `yaml:"nested"`
+	}{
+		Host:      "db",
+		Pass:      secret,
+		User:      "maas",
+		Port:      5432,
+		Listeners: []listener{{Address: "/run/maas.sock"}},
+	}
+	cfg.Nested.Secret = secret
+	cfg.Nested.URL = "postgres://maas:" + secret + "@db/maasdb"
+
+	m, err := New().Map(cfg)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]any{
+		"database_host": "db",
+		"database_pass": Mask,
+		"database_user": "maas",
+		"token":         "",
+		"database_port": 5432,
+		"listeners":     []any{map[string]any{"address": "/run/maas.sock"}},
+		"nested": map[string]any{
+			"secret": Mask,
+			"url":    "postgres://maas:***@db/maasdb",
+		},
+	}, m)
+
+	_, err = New().Map([]string{"a"})
+	assert.Error(t, err)
+}
+
+func TestWriterLength(t *testing.T) {
+	var buf bytes.Buffer
+
+	p := []byte("password=" + secret + "\n")
+
+	n, err := New().Writer(&buf).Write(p)
+	require.NoError(t, err)
+	assert.Equal(t, len(p), n)
+	assert.Equal(t, "password=***\n", buf.String())
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package retry
+
+import "sync"
+
+// Budget limits retries across the operations sharing it, so that a
+// dependency that keeps failing isn't overwhelmed by retries. It holds up
+// to maxTokens tokens: every failed attempt takes one, every success gives
+// back ratio, and retries stop while half of the tokens or fewer are left.
+// The first attempt of an operation is never throttled.
+type Budget struct {
+	mu        sync.Mutex
+	tokens    float64
+	maxTokens float64
+	ratio     float64
+}
+
+// NewBudget returns a full Budget.
+func NewBudget(maxTokens int, ratio float64) *Budget {
+	return &Budget{
+		tokens:    float64(maxTokens),
+		maxTokens: float64(maxTokens),
+		ratio:     ratio,
+	}
+}
+
+// Allowed reports whether retries are currently allowed.
+func (b *Budget) Allowed() bool {
+	if b == nil {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.tokens > b.maxTokens/2
+}
+
+// fail records a failed attempt and reports whether it may be retried.
+func (b *Budget) fail() bool {
+	if b == nil {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens = max(b.tokens-1, 0)
+
+	return b.tokens > b.maxTokens/2
+}
+
+func (b *Budget) succeed() {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens = min(b.tokens+b.ratio, b.maxTokens)
+}
@@ -0,0 +1,292 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package retry retries operations with a context-aware exponential backoff.
+//
+// Delays grow exponentially from an initial interval up to a maximum, with
+// random jitter so that clients failing at the same time don't retry in
+// lockstep. Operations are retried until they succeed, ctx is done, a limit
+// is reached, or they return an error wrapped with Permanent. A Budget may
+// be shared between operations to stop retrying against a dependency that
+// keeps failing.
+package retry
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	"maas.io/core/src/maasgocommon/clock"
+)
+
+const (
+	defaultInitialInterval = 500 * time.Millisecond
+	defaultMaxInterval     = time.Minute
+	defaultMultiplier      = 1.5
+	defaultJitter          = 0.5
+)
+
+// ErrBudgetExhausted is returned, joined with the last error of the
+// operation, when the retry budget doesn't allow another attempt.
+var ErrBudgetExhausted = errors.New("retry budget exhausted")
+
+// Attempt describes a failed attempt, passed to the hooks registered with
+// WithNotify.
+type Attempt struct {
+	// Err is the error returned by the attempt.
+	Err error
+	// Number of the attempt, starting at 1.
+	Number int
+	// Delay before the next attempt.
+	Delay time.Duration
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent wraps err so that it is returned as is instead of retried.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &permanentError{err: err}
+}
+
+// Option configures retries.
+type Option func(*config)
+
+type config struct {
+	clock          clock.Clock
+	budget         *Budget
+	retryIf        func(error) bool
+	notify         []func(Attempt)
+	backoff        Backoff
+	maxElapsedTime time.Duration
+	maxAttempts    int
+}
+
+// WithInitialInterval sets the delay after the first failed attempt.
+func WithInitialInterval(d time.Duration) Option {
+	return func(c *config) {
+		c.backoff.initialInterval = d
+	}
+}
+
+// WithMaxInterval caps the delay between attempts.
+func WithMaxInterval(d time.Duration) Option {
+	return func(c *config) {
+		c.backoff.maxInterval = d
+	}
+}
+
+// WithMultiplier sets the factor the delay grows by after each attempt.
+func WithMultiplier(m float64) Option {
+	return func(c *config) {
+		c.backoff.multiplier = m
+	}
+}
+
+// WithJitter sets how much delays are randomised, as a fraction of the
+// delay between 0 (none) and 1.
+func WithJitter(j float64) Option {
+	return func(c *config) {
+		c.backoff.jitter = min(max(j, 0), 1)
+	}
+}
+
+// WithMaxElapsedTime stops retrying once the next attempt would start
+// after d. Zero, the default, means no limit.
+func WithMaxElapsedTime(d time.Duration) Option {
+	return func(c *config) {
+		c.maxElapsedTime = d
+	}
+}
+
+// WithMaxAttempts stops retrying after n attempts. Zero, the default, means
+// no limit.
+func WithMaxAttempts(n int) Option {
+	return func(c *config) {
+		c.maxAttempts = n
+	}
+}
+
+// WithBudget takes retries from b.
+func WithBudget(b *Budget) Option {
+	return func(c *config) {
+		c.budget = b
+	}
+}
+
+// WithRetryIf only retries the errors fn returns true for, e.g.
+// errors.Retryable. Other errors are returned as if wrapped with Permanent.
+func WithRetryIf(fn func(error) bool) Option {
+	return func(c *config) {
+		c.retryIf = fn
+	}
+}
+
+// WithNotify calls fn after every failed attempt that is going to be
+// retried, e.g. to log it or to record metrics.
+func WithNotify(fn func(Attempt)) Option {
+	return func(c *config) {
+		c.notify = append(c.notify, fn)
+	}
+}
+
+// WithClock sets the clock used to wait between attempts and to measure the
+// elapsed time, e.g. a fake one in tests.
+func WithClock(clk clock.Clock) Option {
+	return func(c *config) {
+		c.clock = clk
+	}
+}
+
+func newConfig(opts []Option) *config {
+	c := &config{
+		clock: clock.New(),
+		backoff: Backoff{
+			initialInterval: defaultInitialInterval,
+			maxInterval:     defaultMaxInterval,
+			multiplier:      defaultMultiplier,
+			jitter:          defaultJitter,
+		},
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	c.backoff.Reset()
+
+	return c
+}
+
+// Do calls op until it succeeds and returns its last error otherwise. If ctx
+// is done while waiting, the error of ctx is joined with the last error.
+// Without WithMaxElapsedTime or WithMaxAttempts, op is retried until ctx is
+// done.
+func Do(ctx context.Context, op func(context.Context) error, opts ...Option) error {
+	_, err := DoWithData(ctx, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, op(ctx)
+	}, opts...)
+
+	return err
+}
+
+// DoWithData is like Do, for operations returning a value.
+func DoWithData[T any](ctx context.Context, op func(context.Context) (T, error), opts ...Option) (T, error) {
+	c := newConfig(opts)
+	start := c.clock.Now()
+
+	for n := 1; ; n++ {
+		res, err := op(ctx)
+		if err == nil {
+			c.budget.succeed()
+			return res, nil
+		}
+
+		var permanent *permanentError
+		if errors.As(err, &permanent) {
+			return res, permanent.err
+		}
+
+		if c.retryIf != nil && !c.retryIf(err) {
+			return res, err
+		}
+
+		if ctx.Err() != nil {
+			return res, errors.Join(ctx.Err(), err)
+		}
+
+		if c.maxAttempts > 0 && n >= c.maxAttempts {
+			return res, err
+		}
+
+		delay := c.backoff.Next()
+		if c.maxElapsedTime > 0 && c.clock.Since(start)+delay > c.maxElapsedTime {
+			return res, err
+		}
+
+		if !c.budget.fail() {
+			return res, errors.Join(ErrBudgetExhausted, err)
+		}
+
+		for _, fn := range c.notify {
+			fn(Attempt{Number: n, Err: err, Delay: delay})
+		}
+
+		timer := c.clock.NewTimer(delay)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return res, errors.Join(ctx.Err(), err)
+		case <-timer.C():
+		}
+	}
+}
+
+// Backoff computes the delays between attempts, for loops that can't use Do,
+// e.g. because they reset the delay after a partial success.
+type Backoff struct {
+	current         time.Duration
+	initialInterval time.Duration
+	maxInterval     time.Duration
+	multiplier      float64
+	jitter          float64
+}
+
+// NewBackoff returns a Backoff configured by the interval, multiplier and
+// jitter options. Other options are ignored.
+func NewBackoff(opts ...Option) *Backoff {
+	b := newConfig(opts).backoff
+	return &b
+}
+
+// Next returns the delay before the next attempt.
+func (b *Backoff) Next() time.Duration {
+	d := b.current
+
+	if next := time.Duration(float64(b.current) * b.multiplier); next < b.maxInterval {
+		b.current = next
+	} else {
+		b.current = b.maxInterval
+	}
+
+	if b.jitter == 0 || d == 0 {
+		return d
+	}
+
+	delta := b.jitter * float64(d)
+
+	//nolint:gosec // jitter doesn't need a secure random source
+	return time.Duration(float64(d) - delta + rand.Float64()*2*delta)
+}
+
+// Reset restarts the delays from the initial interval.
+func (b *Backoff) Reset() {
+	b.current = min(b.initialInterval, b.maxInterval)
+}
@@ -0,0 +1,212 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"maas.io/core/src/maasgocommon/clock"
+)
+
+var errTest = errors.New("test")
+
+// fast retries without waiting noticeably.
+var fast = []Option{WithInitialInterval(time.Millisecond), WithJitter(0)}
+
+func failing(n int, calls *int) func(context.Context) error {
+	return func(context.Context) error {
+		*calls++
+		if *calls <= n {
+			return errTest
+		}
+
+		return nil
+	}
+}
+
+func TestDo(t *testing.T) {
+	var calls int
+
+	var attempts []Attempt
+
+	err := Do(context.Background(), failing(2, &calls),
+		append(fast, WithNotify(func(a Attempt) {
+			attempts = append(attempts, a)
+		}))...)
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, []Attempt{
+		{Number: 1, Err: errTest, Delay: time.Millisecond},
+		{Number: 2, Err: errTest, Delay: 1500 * time.Microsecond},
+	}, attempts)
+}
+
+func TestDoWithData(t *testing.T) {
+	var calls int
+
+	res, err := DoWithData(context.Background(), func(ctx context.Context) (int, error) {
+		return 42, failing(1, &calls)(ctx)
+	}, fast...)
+	require.NoError(t, err)
+
+	assert.Equal(t, 42, res)
+	assert.Equal(t, 2, calls)
+}
+
+func TestDoPermanent(t *testing.T) {
+	var calls int
+
+	err := Do(context.Background(), func(context.Context) error {
+		calls++
+		return Permanent(errTest)
+	}, fast...)
+
+	assert.Equal(t, errTest, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestDoRetryIf(t *testing.T) {
+	errOther := errors.New("other")
+
+	var calls int
+
+	err := Do(context.Background(), func(context.Context) error {
+		calls++
+		if calls < 3 {
+			return errTest
+		}
+
+		return errOther
+	}, append(fast, WithRetryIf(func(err error) bool {
+		return errors.Is(err, errTest)
+	}))...)
+
+	assert.ErrorIs(t, err, errOther)
+	assert.Equal(t, 3, calls)
+}
+
+func TestDoMaxAttempts(t *testing.T) {
+	var calls int
+
+	err := Do(context.Background(), failing(10, &calls),
+		append(fast, WithMaxAttempts(3))...)
+
+	assert.ErrorIs(t, err, errTest)
+	assert.Equal(t, 3, calls)
+}
+
+func TestDoMaxElapsedTime(t *testing.T) {
+	var calls int
+
+	err := Do(context.Background(), failing(10, &calls), WithInitialInterval(time.Hour),
+		WithMaxInterval(time.Hour), WithMaxElapsedTime(time.Minute))
+
+	assert.ErrorIs(t, err, errTest)
+	assert.Equal(t, 1, calls)
+}
+
+func TestDoClock(t *testing.T) {
+	clk := clock.NewFake(time.Now())
+	done := make(chan error)
+
+	var delays []time.Duration
+
+	go func() {
+		done <- Do(context.Background(), func(context.Context) error { return errTest },
+			WithClock(clk), WithInitialInterval(time.Second), WithMultiplier(2),
+			WithJitter(0), WithMaxElapsedTime(10*time.Second),
+			WithNotify(func(a Attempt) { delays = append(delays, a.Delay) }))
+	}()
+
+	// Attempts start after 0s, 1s, 3s and 7s, the next one would start
+	// after 15s.
+	for _, d := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second} {
+		require.NoError(t, clk.BlockUntil(context.Background(), 1))
+		clk.Advance(d)
+	}
+
+	assert.ErrorIs(t, <-done, errTest)
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second, 4 * time.Second}, delays)
+}
+
+func TestDoContextDone(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	var calls int
+
+	err := Do(ctx, failing(1, &calls), WithInitialInterval(time.Hour))
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.ErrorIs(t, err, errTest)
+	assert.Equal(t, 1, calls)
+}
+
+func TestDoBudget(t *testing.T) {
+	budget := NewBudget(4, 1)
+
+	var calls int
+
+	// The second failure leaves half of the tokens.
+	err := Do(context.Background(), failing(10, &calls),
+		append(fast, WithBudget(budget))...)
+
+	assert.ErrorIs(t, err, ErrBudgetExhausted)
+	assert.ErrorIs(t, err, errTest)
+	assert.Equal(t, 2, calls)
+	assert.False(t, budget.Allowed())
+
+	// Successes refill the budget.
+	require.NoError(t, Do(context.Background(), func(context.Context) error {
+		return nil
+	}, WithBudget(budget)))
+	assert.True(t, budget.Allowed())
+}
+
+func TestBackoff(t *testing.T) {
+	b := NewBackoff(WithInitialInterval(time.Second), WithMultiplier(2),
+		WithMaxInterval(5*time.Second), WithJitter(0))
+
+	var delays []time.Duration
+	for range 5 {
+		delays = append(delays, b.Next())
+	}
+
+	assert.Equal(t, []time.Duration{
+		time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second,
+	}, delays)
+
+	b.Reset()
+	assert.Equal(t, time.Second, b.Next())
+}
+
+func TestBackoffJitter(t *testing.T) {
+	b := NewBackoff(WithInitialInterval(time.Second), WithMultiplier(1),
+		WithJitter(0.5))
+
+	for range 100 {
+		d := b.Next()
+		assert.GreaterOrEqual(t, d, 500*time.Millisecond)
+		assert.LessOrEqual(t, d, 1500*time.Millisecond)
+	}
+}
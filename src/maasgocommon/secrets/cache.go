@@ -0,0 +1,131 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package secrets
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"maas.io/core/src/maasgocommon/clock"
+)
+
+const defaultMaxAge = 5 * time.Minute
+
+// Cache is a Provider keeping the secrets of another one, so that they are
+// not fetched for every use.
+//
+// Secrets are fetched again once they are older than the maximum age, so
+// that rotated secrets are picked up, or after two thirds of their TTL, so
+// that dynamic secrets are renewed before they expire. If fetching fails,
+// the cached secret is returned as long as it's valid.
+type Cache struct {
+	clock    clock.Clock
+	provider Provider
+	entries  map[string]cached
+	maxAge   time.Duration
+	mu       sync.Mutex
+}
+
+type cached struct {
+	// renewAt is when the secret is fetched again, expiresAt when it
+	// can't be used anymore, zero if it doesn't expire.
+	renewAt   time.Time
+	expiresAt time.Time
+	secret    *Secret
+}
+
+// CacheOption allows to set additional Cache options
+type CacheOption func(*Cache)
+
+// WithMaxAge sets how long secrets are kept at most (default: 5m)
+func WithMaxAge(d time.Duration) CacheOption {
+	return func(c *Cache) {
+		if d > 0 {
+			c.maxAge = d
+		}
+	}
+}
+
+// WithCacheClock sets the clock used to expire secrets (default: the
+// system clock)
+func WithCacheClock(clk clock.Clock) CacheOption {
+	return func(c *Cache) {
+		c.clock = clk
+	}
+}
+
+// NewCache returns a Cache of the secrets of provider.
+func NewCache(provider Provider, options ...CacheOption) *Cache {
+	c := &Cache{
+		clock:    clock.New(),
+		provider: provider,
+		entries:  make(map[string]cached),
+		maxAge:   defaultMaxAge,
+	}
+
+	for _, opt := range options {
+		opt(c)
+	}
+
+	return c
+}
+
+// Get returns the secret at path, fetching it if it's not cached or due
+// for renewal.
+func (c *Cache) Get(ctx context.Context, path string) (*Secret, error) {
+	now := c.clock.Now()
+
+	c.mu.Lock()
+	entry, ok := c.entries[path]
+	c.mu.Unlock()
+
+	if ok && now.Before(entry.renewAt) {
+		return entry.secret, nil
+	}
+
+	s, err := c.provider.Get(ctx, path)
+	if err != nil {
+		if ok && (entry.expiresAt.IsZero() || now.Before(entry.expiresAt)) {
+			log.Printf("failed to renew secret %s, using the cached one: %v", path, err)
+			return entry.secret, nil
+		}
+
+		return nil, err
+	}
+
+	entry = cached{renewAt: now.Add(c.maxAge), secret: s}
+
+	if s.TTL > 0 {
+		entry.expiresAt = now.Add(s.TTL)
+		entry.renewAt = now.Add(min(c.maxAge, s.TTL*2/3))
+	}
+
+	c.mu.Lock()
+	c.entries[path] = entry
+	c.mu.Unlock()
+
+	return s, nil
+}
+
+// Invalidate drops the cached secret at path, e.g. after it was rejected,
+// so that the next Get fetches it.
+func (c *Cache) Invalidate(path string) {
+	c.mu.Lock()
+	delete(c.entries, path)
+	c.mu.Unlock()
+}
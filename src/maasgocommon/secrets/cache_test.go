@@ -0,0 +1,117 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package secrets
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maas.io/core/src/maasgocommon/clock"
+)
+
+var epoch = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// counter returns secrets with the given TTL, whose value is the number of
+// times they were fetched, or err if set.
+type counter struct {
+	err   error
+	ttl   time.Duration
+	calls int
+}
+
+func (c *counter) Get(_ context.Context, _ string) (*Secret, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	c.calls++
+
+	return &Secret{Fields: map[string]string{ValueField: string(rune('0' + c.calls))}, TTL: c.ttl}, nil
+}
+
+func value(t *testing.T, c *Cache) string {
+	t.Helper()
+
+	s, err := c.Get(context.Background(), "a")
+	require.NoError(t, err)
+
+	v, err := s.Value()
+	require.NoError(t, err)
+
+	return v
+}
+
+func TestCacheMaxAge(t *testing.T) {
+	clk := clock.NewFake(epoch)
+	c := NewCache(&counter{}, WithCacheClock(clk), WithMaxAge(time.Minute))
+
+	assert.Equal(t, "1", value(t, c))
+
+	clk.Advance(59 * time.Second)
+	assert.Equal(t, "1", value(t, c))
+
+	clk.Advance(time.Second)
+	assert.Equal(t, "2", value(t, c))
+
+	c.Invalidate("a")
+	assert.Equal(t, "3", value(t, c))
+}
+
+func TestCacheRenewsDynamicSecrets(t *testing.T) {
+	clk := clock.NewFake(epoch)
+	p := &counter{ttl: 90 * time.Second}
+	c := NewCache(p, WithCacheClock(clk))
+
+	assert.Equal(t, "1", value(t, c))
+
+	clk.Advance(59 * time.Second)
+	assert.Equal(t, "1", value(t, c))
+
+	// Renewed after two thirds of the TTL.
+	clk.Advance(time.Second)
+	assert.Equal(t, "2", value(t, c))
+
+	// Until the secret expires, it's used if renewing it fails.
+	p.err = errors.New("vault is sealed")
+
+	clk.Advance(80 * time.Second)
+	assert.Equal(t, "2", value(t, c))
+
+	clk.Advance(10 * time.Second)
+	_, err := c.Get(context.Background(), "a")
+	assert.EqualError(t, err, "vault is sealed")
+}
+
+func TestCacheKeepsStaticSecretsOnError(t *testing.T) {
+	clk := clock.NewFake(epoch)
+	p := &counter{}
+	c := NewCache(p, WithCacheClock(clk))
+
+	_, err := c.Get(context.Background(), "a")
+	require.NoError(t, err)
+
+	p.err = errors.New("vault is sealed")
+
+	clk.Advance(time.Hour)
+	assert.Equal(t, "1", value(t, c))
+
+	_, err = c.Get(context.Background(), "b")
+	assert.EqualError(t, err, "vault is sealed")
+}
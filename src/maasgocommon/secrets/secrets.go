@@ -0,0 +1,214 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package secrets reads secrets, such as database passwords, BMC
+// credentials, TLS keys and webhook secrets, from pluggable providers:
+// HashiCorp Vault, files and environment variables.
+//
+// Secrets are named by slash-separated paths, the ones MAAS uses in Vault
+// (e.g. controller/<id>/database-creds), and hold named string fields.
+// Secrets made of a single value, such as the RPC shared secret, have the
+// field "secret" only, as MAAS stores them.
+//
+// Dynamic secrets, such as leased database credentials, expire. Cache keeps
+// secrets until they are due for renewal and then fetches them again.
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	maaserrors "maas.io/core/src/maasgocommon/errors"
+)
+
+// ValueField is the field of secrets made of a single value, the one MAAS
+// stores them under.
+const ValueField = "secret"
+
+// ErrNotFound is wrapped by the errors of providers without the requested
+// secret.
+var ErrNotFound = maaserrors.New(maaserrors.NotFound, "secret not found")
+
+// Secret is a set of named string fields, e.g. the user and password of a
+// database.
+type Secret struct {
+	Fields map[string]string
+	// TTL is how long the secret is valid for once fetched, 0 if it
+	// doesn't expire.
+	TTL time.Duration
+}
+
+// Field returns the field name of the secret.
+func (s *Secret) Field(name string) (string, error) {
+	v, ok := s.Fields[name]
+	if !ok {
+		return "", fmt.Errorf("secret has no field %q", name)
+	}
+
+	return v, nil
+}
+
+// Value returns the value of a secret made of a single value.
+func (s *Secret) Value() (string, error) {
+	return s.Field(ValueField)
+}
+
+// Provider fetches secrets.
+type Provider interface {
+	// Get returns the secret at path, or an error wrapping ErrNotFound if
+	// there is none.
+	Get(ctx context.Context, path string) (*Secret, error)
+}
+
+// Chain is a Provider returning the secret of the first of its providers
+// that has it. Errors other than ErrNotFound are returned straight away
+// rather than falling back to the next provider, which could hold an
+// outdated copy.
+type Chain []Provider
+
+// Get returns the secret at path from the first provider having it.
+func (c Chain) Get(ctx context.Context, path string) (*Secret, error) {
+	for _, p := range c {
+		s, err := p.Get(ctx, path)
+		if !errors.Is(err, ErrNotFound) {
+			return s, err
+		}
+	}
+
+	return nil, fmt.Errorf("%s: %w", path, ErrNotFound)
+}
+
+// parseFields decodes a JSON object of fields. Values other than strings
+// are kept as JSON.
+func parseFields(data []byte) (map[string]string, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]string, len(raw))
+
+	for name, v := range raw {
+		var s string
+		if err := json.Unmarshal(v, &s); err != nil {
+			s = string(v)
+		}
+
+		fields[name] = s
+	}
+
+	return fields, nil
+}
+
+// Env is a Provider reading secrets from environment variables, named
+// after the path of secrets: upper case, with characters other than
+// letters and digits replaced with underscores, and a prefix. A variable
+// holds the value of the secret, or its fields as a JSON object if its
+// name ends in _JSON.
+//
+// For example, with the prefix MAAS_SECRET_, the secret
+// controller/abc/database-creds is read from
+// MAAS_SECRET_CONTROLLER_ABC_DATABASE_CREDS_JSON or else
+// MAAS_SECRET_CONTROLLER_ABC_DATABASE_CREDS.
+type Env struct {
+	lookup func(string) (string, bool)
+	prefix string
+}
+
+// NewEnv returns an Env reading variables starting with prefix.
+func NewEnv(prefix string) *Env {
+	return &Env{lookup: os.LookupEnv, prefix: prefix}
+}
+
+// Get returns the secret at path.
+func (e *Env) Get(_ context.Context, path string) (*Secret, error) {
+	name := e.prefix + strings.Map(func(r rune) rune {
+		switch {
+		case 'a' <= r && r <= 'z':
+			return r - 'a' + 'A'
+		case 'A' <= r && r <= 'Z', '0' <= r && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, path)
+
+	if v, ok := e.lookup(name + "_JSON"); ok {
+		fields, err := parseFields([]byte(v))
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s_JSON: %w", name, err)
+		}
+
+		return &Secret{Fields: fields}, nil
+	}
+
+	if v, ok := e.lookup(name); ok {
+		return &Secret{Fields: map[string]string{ValueField: v}}, nil
+	}
+
+	return nil, fmt.Errorf("%s: %w", path, ErrNotFound)
+}
+
+// File is a Provider reading secrets from files of a directory, e.g. the
+// credentials directory of a systemd service. The file named after the
+// path of a secret holds its value, with surrounding whitespace removed,
+// or else the same file with the .json extension holds its fields as a
+// JSON object.
+type File struct {
+	dir string
+}
+
+// NewFile returns a File reading secrets from dir.
+func NewFile(dir string) *File {
+	return &File{dir: dir}
+}
+
+// Get returns the secret at path.
+func (f *File) Get(_ context.Context, path string) (*Secret, error) {
+	if !filepath.IsLocal(path) {
+		return nil, maaserrors.Errorf(maaserrors.Invalid, "invalid secret path %q", path)
+	}
+
+	name := filepath.Join(f.dir, filepath.FromSlash(path))
+
+	data, err := os.ReadFile(filepath.Clean(name))
+	if err == nil {
+		return &Secret{Fields: map[string]string{ValueField: strings.TrimSpace(string(data))}}, nil
+	}
+
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	data, err = os.ReadFile(filepath.Clean(name + ".json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%s: %w", path, ErrNotFound)
+	} else if err != nil {
+		return nil, err
+	}
+
+	fields, err := parseFields(data)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s.json: %w", name, err)
+	}
+
+	return &Secret{Fields: fields}, nil
+}
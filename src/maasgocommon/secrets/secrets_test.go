@@ -0,0 +1,160 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package secrets
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	maaserrors "maas.io/core/src/maasgocommon/errors"
+)
+
+func TestSecretField(t *testing.T) {
+	s := &Secret{Fields: map[string]string{"user": "maas", ValueField: "token"}}
+
+	user, err := s.Field("user")
+	require.NoError(t, err)
+	assert.Equal(t, "maas", user)
+
+	value, err := s.Value()
+	require.NoError(t, err)
+	assert.Equal(t, "token", value)
+
+	_, err = s.Field("pass")
+	assert.EqualError(t, err, `secret has no field "pass"`)
+}
+
+func TestEnv(t *testing.T) {
+	vars := map[string]string{
+		"MAAS_SECRET_WEBHOOKS_DEPLOYED":                  "s3cret",
+		"MAAS_SECRET_CONTROLLER_ABC_DATABASE_CREDS_JSON": `{"user": "maas", "pass": "pw", "port": 5432}`,
+		"MAAS_SECRET_BROKEN_JSON":                        "{",
+	}
+
+	env := NewEnv("MAAS_SECRET_")
+	env.lookup = func(name string) (string, bool) {
+		v, ok := vars[name]
+		return v, ok
+	}
+
+	testcases := map[string]struct {
+		fields map[string]string
+		err    string
+	}{
+		"webhooks/deployed": {
+			fields: map[string]string{ValueField: "s3cret"},
+		},
+		"controller/abc/database-creds": {
+			fields: map[string]string{"user": "maas", "pass": "pw", "port": "5432"},
+		},
+		"broken": {
+			err: "parsing MAAS_SECRET_BROKEN_JSON: unexpected end of JSON input",
+		},
+		"missing": {
+			err: "missing: secret not found",
+		},
+	}
+
+	for path, tc := range testcases {
+		t.Run(path, func(t *testing.T) {
+			s, err := env.Get(context.Background(), path)
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.fields, s.Fields)
+			assert.Zero(t, s.TTL)
+		})
+	}
+}
+
+func TestFile(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "tls"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "tls", "key.pem"), []byte("PEM\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "bmc.json"), []byte(`{"user": "admin", "pass": "pw"}`), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "broken.json"), []byte("{"), 0o600))
+
+	testcases := map[string]struct {
+		fields map[string]string
+		err    string
+	}{
+		"tls/key.pem": {
+			fields: map[string]string{ValueField: "PEM"},
+		},
+		"bmc": {
+			fields: map[string]string{"user": "admin", "pass": "pw"},
+		},
+		"broken": {
+			err: "parsing " + filepath.Join(dir, "broken") + ".json: unexpected end of JSON input",
+		},
+		"missing": {
+			err: "missing: secret not found",
+		},
+		"../escape": {
+			err: `invalid secret path "../escape"`,
+		},
+	}
+
+	for path, tc := range testcases {
+		t.Run(path, func(t *testing.T) {
+			s, err := NewFile(dir).Get(context.Background(), path)
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.fields, s.Fields)
+		})
+	}
+}
+
+type providerFunc func(ctx context.Context, path string) (*Secret, error)
+
+func (f providerFunc) Get(ctx context.Context, path string) (*Secret, error) {
+	return f(ctx, path)
+}
+
+func TestChain(t *testing.T) {
+	notFound := providerFunc(func(_ context.Context, path string) (*Secret, error) {
+		return nil, ErrNotFound
+	})
+	found := providerFunc(func(_ context.Context, path string) (*Secret, error) {
+		return &Secret{Fields: map[string]string{ValueField: path}}, nil
+	})
+	failing := providerFunc(func(_ context.Context, _ string) (*Secret, error) {
+		return nil, errors.New("vault is sealed")
+	})
+
+	s, err := Chain{notFound, found, failing}.Get(context.Background(), "a")
+	require.NoError(t, err)
+	assert.Equal(t, "a", s.Fields[ValueField])
+
+	_, err = Chain{notFound, failing, found}.Get(context.Background(), "a")
+	assert.EqualError(t, err, "vault is sealed")
+
+	_, err = Chain{notFound}.Get(context.Background(), "a")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Equal(t, maaserrors.NotFound, maaserrors.KindOf(err))
+}
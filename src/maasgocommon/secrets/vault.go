@@ -0,0 +1,310 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"maas.io/core/src/maasgocommon/clock"
+	maaserrors "maas.io/core/src/maasgocommon/errors"
+)
+
+const (
+	defaultMount = "secret"
+
+	// tokenRenewMargin is how long before it expires a token is replaced,
+	// as MAAS does, so that it doesn't expire during a request.
+	tokenRenewMargin = 20 * time.Second
+
+	vaultTimeout = 30 * time.Second
+)
+
+// Vault is a Provider reading secrets from HashiCorp Vault, with the
+// settings of the MAAS Vault integration: it logs in with an AppRole, and
+// reads secrets from a KV version 2 engine, under a base path.
+//
+// The token is replaced by logging in again before it expires.
+type Vault struct {
+	tokenExpiry time.Time
+	clock       clock.Clock
+	httpClient  *http.Client
+	url         string
+	roleID      string
+	secretID    string
+	mount       string
+	basePath    string
+	token       string
+	mu          sync.Mutex
+}
+
+// VaultOption allows to set additional Vault options
+type VaultOption func(*Vault)
+
+// WithMount sets the mount path of the KV engine (default: secret)
+func WithMount(mount string) VaultOption {
+	return func(v *Vault) {
+		if mount != "" {
+			v.mount = strings.Trim(mount, "/")
+		}
+	}
+}
+
+// WithBasePath sets the path prefix of secrets in the KV engine, the
+// vault_secrets_path of MAAS (default: none)
+func WithBasePath(path string) VaultOption {
+	return func(v *Vault) {
+		v.basePath = strings.Trim(path, "/")
+	}
+}
+
+// WithHTTPClient sets the client used to reach Vault, e.g. to configure
+// TLS (default: a client honouring the proxy environment)
+func WithHTTPClient(c *http.Client) VaultOption {
+	return func(v *Vault) {
+		v.httpClient = c
+	}
+}
+
+// WithVaultClock sets the clock used to expire tokens (default: the system
+// clock)
+func WithVaultClock(clk clock.Clock) VaultOption {
+	return func(v *Vault) {
+		v.clock = clk
+	}
+}
+
+// NewVault returns a Vault reaching the server at vaultURL and logging in
+// with the AppRole roleID and secretID.
+func NewVault(vaultURL, roleID, secretID string, options ...VaultOption) *Vault {
+	v := &Vault{
+		clock: clock.New(),
+		httpClient: &http.Client{
+			Transport: &http.Transport{Proxy: http.ProxyFromEnvironment},
+			Timeout:   vaultTimeout,
+		},
+		url:      strings.TrimSuffix(vaultURL, "/"),
+		roleID:   roleID,
+		secretID: secretID,
+		mount:    defaultMount,
+	}
+
+	for _, opt := range options {
+		opt(v)
+	}
+
+	return v
+}
+
+// Get returns the secret at path in the KV engine.
+func (v *Vault) Get(ctx context.Context, path string) (*Secret, error) {
+	apiPath := v.mount + "/data/" + path
+	if v.basePath != "" {
+		apiPath = v.mount + "/data/" + v.basePath + "/" + path
+	}
+
+	var resp struct {
+		Data struct {
+			Data json.RawMessage `json:"data"`
+		} `json:"data"`
+	}
+
+	if _, err := v.read(ctx, apiPath, &resp); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	fields, err := parseFields(resp.Data.Data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: parsing secret: %w", path, err)
+	}
+
+	return &Secret{Fields: fields}, nil
+}
+
+// Dynamic returns a Provider reading secrets generated by Vault, e.g. by
+// the database secrets engine, from their full API path (e.g.
+// database/creds/maas). Their TTL is the lease duration, so that Cache
+// fetches new ones before they expire.
+func (v *Vault) Dynamic() Provider {
+	return dynamic{v}
+}
+
+type dynamic struct {
+	v *Vault
+}
+
+func (d dynamic) Get(ctx context.Context, path string) (*Secret, error) {
+	var resp struct {
+		Data json.RawMessage `json:"data"`
+	}
+
+	lease, err := d.v.read(ctx, path, &resp)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	fields, err := parseFields(resp.Data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: parsing secret: %w", path, err)
+	}
+
+	return &Secret{Fields: fields, TTL: lease}, nil
+}
+
+// read decodes the response of Vault at apiPath into out and returns its
+// lease duration. A token rejected by Vault, e.g. because it was revoked,
+// is replaced once.
+func (v *Vault) read(ctx context.Context, apiPath string, out any) (time.Duration, error) {
+	token, err := v.ensureToken(ctx, false)
+	if err != nil {
+		return 0, err
+	}
+
+	var lease struct {
+		LeaseDuration int64 `json:"lease_duration"`
+	}
+
+	body, err := v.do(ctx, http.MethodGet, apiPath, token, nil)
+	if maaserrors.KindOf(err) == maaserrors.PermissionDenied {
+		if token, err = v.ensureToken(ctx, true); err != nil {
+			return 0, err
+		}
+
+		body, err = v.do(ctx, http.MethodGet, apiPath, token, nil)
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	if err := json.Unmarshal(body, out); err != nil {
+		return 0, fmt.Errorf("decoding Vault response: %w", err)
+	}
+
+	if err := json.Unmarshal(body, &lease); err != nil {
+		return 0, fmt.Errorf("decoding Vault response: %w", err)
+	}
+
+	return time.Duration(lease.LeaseDuration) * time.Second, nil
+}
+
+// ensureToken returns a valid token, logging in if there is none, it's
+// about to expire or force is set.
+func (v *Vault) ensureToken(ctx context.Context, force bool) (string, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if !force && v.token != "" &&
+		(v.tokenExpiry.IsZero() || v.clock.Now().Before(v.tokenExpiry.Add(-tokenRenewMargin))) {
+		return v.token, nil
+	}
+
+	req, err := json.Marshal(map[string]string{"role_id": v.roleID, "secret_id": v.secretID})
+	if err != nil {
+		return "", err
+	}
+
+	body, err := v.do(ctx, http.MethodPost, "auth/approle/login", "", req)
+	if err != nil {
+		return "", fmt.Errorf("logging in to Vault: %w", err)
+	}
+
+	var resp struct {
+		Auth struct {
+			ClientToken   string `json:"client_token"`
+			LeaseDuration int64  `json:"lease_duration"`
+		} `json:"auth"`
+	}
+
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return "", fmt.Errorf("decoding Vault login response: %w", err)
+	}
+
+	if resp.Auth.ClientToken == "" {
+		return "", errors.New("logging in to Vault: no token in response")
+	}
+
+	v.token = resp.Auth.ClientToken
+	v.tokenExpiry = time.Time{}
+
+	if resp.Auth.LeaseDuration > 0 {
+		v.tokenExpiry = v.clock.Now().Add(time.Duration(resp.Auth.LeaseDuration) * time.Second)
+	}
+
+	return v.token, nil
+}
+
+// do sends a request to the Vault API and returns the body of its
+// successful response.
+func (v *Vault) do(ctx context.Context, method, apiPath, token string, body []byte) ([]byte, error) {
+	u, err := url.JoinPath(v.url, "v1", apiPath)
+	if err != nil {
+		return nil, maaserrors.Wrap(maaserrors.Invalid, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return nil, maaserrors.Wrap(maaserrors.Unavailable, err)
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, maaserrors.Wrap(maaserrors.Unavailable, err)
+	}
+
+	if resp.StatusCode == http.StatusOK {
+		return data, nil
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+
+	var vaultErr struct {
+		Errors []string `json:"errors"`
+	}
+
+	msg := resp.Status
+	if json.Unmarshal(data, &vaultErr) == nil && len(vaultErr.Errors) > 0 {
+		msg += ": " + strings.Join(vaultErr.Errors, "; ")
+	}
+
+	return nil, maaserrors.Errorf(maaserrors.FromHTTPStatus(resp.StatusCode), "vault: %s", msg)
+}
@@ -0,0 +1,195 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maas.io/core/src/maasgocommon/clock"
+	maaserrors "maas.io/core/src/maasgocommon/errors"
+)
+
+// fakeVault serves the parts of the Vault API used by Vault: AppRole login
+// and reads, with tokens valid for tokenTTL seconds.
+type fakeVault struct {
+	secrets  map[string]string
+	tokens   map[string]bool
+	logins   int
+	tokenTTL int
+	mu       sync.Mutex
+}
+
+func newFakeVault(t *testing.T, fv *fakeVault) *httptest.Server {
+	t.Helper()
+
+	fv.tokens = make(map[string]bool)
+
+	srv := httptest.NewServer(fv)
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func (fv *fakeVault) revokeTokens() {
+	fv.mu.Lock()
+	defer fv.mu.Unlock()
+
+	clear(fv.tokens)
+}
+
+func (fv *fakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	fv.mu.Lock()
+	defer fv.mu.Unlock()
+
+	if r.URL.Path == "/v1/auth/approle/login" {
+		var req map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil ||
+			req["role_id"] != "role" || req["secret_id"] != "secret" {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"errors": ["invalid role or secret ID"]}`)
+
+			return
+		}
+
+		fv.logins++
+		token := fmt.Sprintf("token-%d", fv.logins)
+		fv.tokens[token] = true
+		fmt.Fprintf(w, `{"auth": {"client_token": %q, "lease_duration": %d}}`, token, fv.tokenTTL)
+
+		return
+	}
+
+	if !fv.tokens[r.Header.Get("X-Vault-Token")] {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"errors": ["permission denied"]}`)
+
+		return
+	}
+
+	body, ok := fv.secrets[r.URL.Path]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"errors": []}`)
+
+		return
+	}
+
+	fmt.Fprint(w, body)
+}
+
+const kvCreds = `{
+	"data": {
+		"data": {"user": "maas", "pass": "pw", "name": "maasdb"},
+		"metadata": {"version": 1}
+	},
+	"lease_duration": 0
+}`
+
+func TestVaultGet(t *testing.T) {
+	fv := &fakeVault{secrets: map[string]string{
+		"/v1/maas/data/prefix/controller/abc/database-creds": kvCreds,
+	}}
+	srv := newFakeVault(t, fv)
+
+	v := NewVault(srv.URL, "role", "secret", WithMount("maas"), WithBasePath("prefix/"))
+
+	s, err := v.Get(context.Background(), "controller/abc/database-creds")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"user": "maas", "pass": "pw", "name": "maasdb"}, s.Fields)
+	assert.Zero(t, s.TTL)
+
+	_, err = v.Get(context.Background(), "controller/def/database-creds")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// The token is reused.
+	assert.Equal(t, 1, fv.logins)
+}
+
+func TestVaultGetDefaultMount(t *testing.T) {
+	fv := &fakeVault{secrets: map[string]string{"/v1/secret/data/creds": kvCreds}}
+	srv := newFakeVault(t, fv)
+
+	_, err := NewVault(srv.URL+"/", "role", "secret").Get(context.Background(), "creds")
+	require.NoError(t, err)
+}
+
+func TestVaultTokenRenewal(t *testing.T) {
+	fv := &fakeVault{
+		secrets:  map[string]string{"/v1/secret/data/creds": kvCreds},
+		tokenTTL: 60,
+	}
+	srv := newFakeVault(t, fv)
+
+	clk := clock.NewFake(epoch)
+	v := NewVault(srv.URL, "role", "secret", WithVaultClock(clk))
+
+	get := func() {
+		t.Helper()
+
+		_, err := v.Get(context.Background(), "creds")
+		require.NoError(t, err)
+	}
+
+	get()
+	assert.Equal(t, 1, fv.logins)
+
+	clk.Advance(39 * time.Second)
+	get()
+	assert.Equal(t, 1, fv.logins)
+
+	// Replaced shortly before it expires.
+	clk.Advance(time.Second)
+	get()
+	assert.Equal(t, 2, fv.logins)
+
+	// Replaced when rejected.
+	fv.revokeTokens()
+	get()
+	assert.Equal(t, 3, fv.logins)
+}
+
+func TestVaultLoginFailure(t *testing.T) {
+	srv := newFakeVault(t, &fakeVault{})
+
+	_, err := NewVault(srv.URL, "role", "wrong").Get(context.Background(), "creds")
+	assert.EqualError(t, err, "creds: logging in to Vault: vault: 400 Bad Request: invalid role or secret ID")
+	assert.Equal(t, maaserrors.Invalid, maaserrors.KindOf(err))
+}
+
+func TestVaultDynamic(t *testing.T) {
+	fv := &fakeVault{secrets: map[string]string{
+		"/v1/database/creds/maas": `{
+			"lease_id": "database/creds/maas/xyz",
+			"lease_duration": 3600,
+			"data": {"username": "v-maas", "password": "pw"}
+		}`,
+	}}
+	srv := newFakeVault(t, fv)
+
+	s, err := NewVault(srv.URL, "role", "secret").Dynamic().Get(context.Background(), "database/creds/maas")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"username": "v-maas", "password": "pw"}, s.Fields)
+	assert.Equal(t, time.Hour, s.TTL)
+}
@@ -0,0 +1,315 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/spf13/cobra"
+)
+
+// Output formats of the access commands.
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+// accessTuple is a tuple as printed by the access commands.
+type accessTuple struct {
+	User     string `json:"user"`
+	Relation string `json:"relation"`
+	Object   string `json:"object"`
+}
+
+func accessCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "access",
+		Short: "Grant, revoke or list access to MAAS entities.",
+		Long: `Grant, revoke or list access to MAAS entities.
+
+Unlike the tuple commands, grant and revoke check the relation against the
+authorization model used by the running maas-openfga before writing, so
+typos and users of the wrong type are rejected with a clear message.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(accessGrantCmd())
+	cmd.AddCommand(accessRevokeCmd())
+	cmd.AddCommand(accessListCmd())
+
+	return cmd
+}
+
+func accessGrantCmd() *cobra.Command {
+	var socketPath, store, output string
+
+	cmd := &cobra.Command{
+		Use:     "grant <user> <relation> <object>",
+		Short:   "Grant a relation on an object to a user or group.",
+		Example: "maas-openfga access grant group:1#member can_edit_machines pool:0",
+		Args:    cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateOutput(output); err != nil {
+				return err
+			}
+
+			client := newAPIClient(socketPath, store)
+
+			model, err := client.latestModel(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			if err := validateTuple(model, args[0], args[1], args[2]); err != nil {
+				return err
+			}
+
+			req := &openfgav1.WriteRequest{
+				AuthorizationModelId: model.GetId(),
+				Writes: &openfgav1.WriteRequestWrites{
+					TupleKeys: []*openfgav1.TupleKey{
+						{User: args[0], Relation: args[1], Object: args[2]},
+					},
+					OnDuplicate: "ignore",
+				},
+			}
+
+			if err := client.do(cmd.Context(), http.MethodPost, "/write", req,
+				&openfgav1.WriteResponse{}); err != nil {
+				return err
+			}
+
+			return printTuples(cmd.OutOrStdout(), output,
+				[]accessTuple{{User: args[0], Relation: args[1], Object: args[2]}})
+		},
+	}
+
+	addSocketFlag(cmd, &socketPath)
+	addStoreFlag(cmd, &store)
+	addOutputFlag(cmd, &output)
+
+	return cmd
+}
+
+func accessRevokeCmd() *cobra.Command {
+	var socketPath, store, output string
+
+	cmd := &cobra.Command{
+		Use:   "revoke <user> <relation> <object>",
+		Short: "Revoke a relation on an object from a user or group.",
+		Long: `Revoke a relation on an object from a user or group.
+
+Revoking access that was not granted is not an error. Tuples of relations
+removed from the model can still be deleted with "tuple delete".`,
+		Example: "maas-openfga access revoke group:1#member can_edit_machines pool:0",
+		Args:    cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateOutput(output); err != nil {
+				return err
+			}
+
+			client := newAPIClient(socketPath, store)
+
+			model, err := client.latestModel(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			if err := validateTuple(model, args[0], args[1], args[2]); err != nil {
+				return err
+			}
+
+			req := &openfgav1.WriteRequest{
+				AuthorizationModelId: model.GetId(),
+				Deletes: &openfgav1.WriteRequestDeletes{
+					TupleKeys: []*openfgav1.TupleKeyWithoutCondition{
+						{User: args[0], Relation: args[1], Object: args[2]},
+					},
+					OnMissing: "ignore",
+				},
+			}
+
+			if err := client.do(cmd.Context(), http.MethodPost, "/write", req,
+				&openfgav1.WriteResponse{}); err != nil {
+				return err
+			}
+
+			return printTuples(cmd.OutOrStdout(), output,
+				[]accessTuple{{User: args[0], Relation: args[1], Object: args[2]}})
+		},
+	}
+
+	addSocketFlag(cmd, &socketPath)
+	addStoreFlag(cmd, &store)
+	addOutputFlag(cmd, &output)
+
+	return cmd
+}
+
+func accessListCmd() *cobra.Command {
+	var (
+		socketPath string
+		store      string
+		output     string
+		key        openfgav1.ReadRequestTupleKey
+	)
+
+	cmd := &cobra.Command{
+		Use:     "list",
+		Short:   "List the access granted on an object or a type of objects.",
+		Example: "maas-openfga access list --object pool:0 --output json",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateOutput(output); err != nil {
+				return err
+			}
+
+			keys, err := newAPIClient(socketPath, store).readTuples(cmd.Context(), &key)
+			if err != nil {
+				return err
+			}
+
+			tuples := make([]accessTuple, 0, len(keys))
+			for _, k := range keys {
+				tuples = append(tuples, accessTuple{
+					User:     k.GetUser(),
+					Relation: k.GetRelation(),
+					Object:   k.GetObject(),
+				})
+			}
+
+			return printTuples(cmd.OutOrStdout(), output, tuples)
+		},
+	}
+
+	addSocketFlag(cmd, &socketPath)
+	addStoreFlag(cmd, &store)
+	addOutputFlag(cmd, &output)
+	cmd.Flags().StringVar(&key.Object, "object", "",
+		"Object, either a type (pool:) or a single object (pool:0)")
+	cmd.Flags().StringVar(&key.User, "user", "", "Filter by user")
+	cmd.Flags().StringVar(&key.Relation, "relation", "", "Filter by relation")
+	_ = cmd.MarkFlagRequired("object") //nolint:errcheck // the flag exists
+
+	return cmd
+}
+
+// addOutputFlag registers the flag selecting the output format.
+func addOutputFlag(cmd *cobra.Command, output *string) {
+	cmd.Flags().StringVarP(output, "output", "o", outputTable,
+		"Output format, either table or json")
+}
+
+func validateOutput(output string) error {
+	if output != outputTable && output != outputJSON {
+		return fmt.Errorf("invalid output format %q, must be one of: %s, %s",
+			output, outputTable, outputJSON)
+	}
+
+	return nil
+}
+
+// printTuples prints tuples in the given output format.
+func printTuples(w io.Writer, output string, tuples []accessTuple) error {
+	if output == outputJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+
+		return enc.Encode(tuples)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "USER\tRELATION\tOBJECT")
+
+	for _, t := range tuples {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", t.User, t.Relation, t.Object)
+	}
+
+	return tw.Flush()
+}
+
+// validateTuple checks that the model defines relation on the type of object
+// and allows the type of user to be directly assigned to it.
+func validateTuple(model *openfgav1.AuthorizationModel, user, relation, object string) error {
+	objectType, objectID, ok := strings.Cut(object, ":")
+	if !ok || objectType == "" || objectID == "" {
+		return fmt.Errorf("invalid object %q, expected <type>:<id>", object)
+	}
+
+	userObject, userRelation, _ := strings.Cut(user, "#")
+
+	userType, userID, ok := strings.Cut(userObject, ":")
+	if !ok || userType == "" || userID == "" {
+		return fmt.Errorf("invalid user %q, expected <type>:<id> or <type>:<id>#<relation>", user)
+	}
+
+	idx := slices.IndexFunc(model.GetTypeDefinitions(), func(td *openfgav1.TypeDefinition) bool {
+		return td.GetType() == objectType
+	})
+	if idx < 0 {
+		return fmt.Errorf("type %q is not defined in model %s", objectType, model.GetId())
+	}
+
+	td := model.GetTypeDefinitions()[idx]
+
+	if _, ok := td.GetRelations()[relation]; !ok {
+		return fmt.Errorf("relation %q is not defined on type %q, expected one of: %s",
+			relation, objectType, strings.Join(slices.Sorted(maps.Keys(td.GetRelations())), ", "))
+	}
+
+	var allowed []string
+
+	for _, ref := range td.GetMetadata().GetRelations()[relation].GetDirectlyRelatedUserTypes() {
+		name := ref.GetType()
+
+		switch {
+		case ref.GetRelation() != "":
+			name += "#" + ref.GetRelation()
+			if userType == ref.GetType() && userRelation == ref.GetRelation() {
+				return nil
+			}
+		case ref.GetWildcard() != nil:
+			name += ":*"
+			if userType == ref.GetType() && userID == "*" && userRelation == "" {
+				return nil
+			}
+		default:
+			if userType == ref.GetType() && userID != "*" && userRelation == "" {
+				return nil
+			}
+		}
+
+		allowed = append(allowed, name)
+	}
+
+	if len(allowed) == 0 {
+		return fmt.Errorf("relation %q on type %q is computed and cannot be granted directly",
+			relation, objectType)
+	}
+
+	return fmt.Errorf("user %q cannot be granted relation %q on type %q, expected one of: %s",
+		user, relation, objectType, strings.Join(allowed, ", "))
+}
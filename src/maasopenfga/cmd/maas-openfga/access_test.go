@@ -0,0 +1,196 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+	authzmodel "maas.io/core/src/maasopenfga/internal/model"
+)
+
+func TestValidateTuple(t *testing.T) {
+	model, err := authzmodel.Load("v4")
+	require.NoError(t, err)
+
+	testcases := map[string]struct {
+		user     string
+		relation string
+		object   string
+		err      string
+	}{
+		"group on pool": {
+			user:     "group:1#member",
+			relation: "can_edit_machines",
+			object:   "pool:0",
+		},
+		"user in group": {
+			user:     "user:1",
+			relation: "member",
+			object:   "group:1",
+		},
+		"unknown type": {
+			user:     "group:1#member",
+			relation: "can_edit",
+			object:   "rack:0",
+			err:      `type "rack" is not defined`,
+		},
+		"unknown relation": {
+			user:     "group:1#member",
+			relation: "can_edit",
+			object:   "pool:0",
+			err:      `relation "can_edit" is not defined on type "pool", expected one of: banned,`,
+		},
+		"wrong user type": {
+			user:     "user:1",
+			relation: "can_edit_machines",
+			object:   "pool:0",
+			err:      `user "user:1" cannot be granted relation "can_edit_machines" on type "pool", expected one of: group#member`,
+		},
+		"computed relation": {
+			user:     "group:1#member",
+			relation: "banned",
+			object:   "pool:0",
+			err:      `relation "banned" on type "pool" is computed`,
+		},
+		"invalid object": {
+			user:     "user:1",
+			relation: "member",
+			object:   "group",
+			err:      `invalid object "group"`,
+		},
+		"invalid user": {
+			user:     "alice",
+			relation: "member",
+			object:   "group:1",
+			err:      `invalid user "alice"`,
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			err := validateTuple(model, tc.user, tc.relation, tc.object)
+			if tc.err != "" {
+				assert.ErrorContains(t, err, tc.err)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+// fakeModelAPI serves the latest model and records write requests.
+func fakeModelAPI(t *testing.T, writes *[]map[string]any) string {
+	t.Helper()
+
+	model, err := authzmodel.Load("v4")
+	require.NoError(t, err)
+
+	model.Id = "01MODEL"
+
+	data, err := protojson.Marshal(&openfgav1.ReadAuthorizationModelsResponse{
+		AuthorizationModels: []*openfgav1.AuthorizationModel{model},
+	})
+	require.NoError(t, err)
+
+	return fakeAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/stores/00000000000000000000000000/authorization-models":
+			_, _ = w.Write(data)
+		case "/stores/00000000000000000000000000/write":
+			var req map[string]any
+
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			*writes = append(*writes, req)
+
+			_, _ = w.Write([]byte(`{}`))
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+}
+
+func TestAccessGrantCmd(t *testing.T) {
+	var writes []map[string]any
+
+	socketPath := fakeModelAPI(t, &writes)
+
+	out, err := execute(t, "access", "grant", "--socket", socketPath, "-o", "json",
+		"group:1#member", "can_edit_machines", "pool:0")
+	require.NoError(t, err)
+	assert.JSONEq(t, `[{"user":"group:1#member","relation":"can_edit_machines","object":"pool:0"}]`, out)
+	assert.Equal(t, []map[string]any{{
+		"authorization_model_id": "01MODEL",
+		"writes": map[string]any{
+			"tuple_keys": []any{map[string]any{
+				"user": "group:1#member", "relation": "can_edit_machines", "object": "pool:0",
+			}},
+			"on_duplicate": "ignore",
+		},
+	}}, writes)
+
+	_, err = execute(t, "access", "grant", "--socket", socketPath,
+		"group:1#member", "can_edt_machines", "pool:0")
+	assert.ErrorContains(t, err, `relation "can_edt_machines" is not defined on type "pool"`)
+	assert.Len(t, writes, 1, "invalid grants must not be written")
+}
+
+func TestAccessRevokeCmd(t *testing.T) {
+	var writes []map[string]any
+
+	socketPath := fakeModelAPI(t, &writes)
+
+	out, err := execute(t, "access", "revoke", "--socket", socketPath,
+		"group:1#member", "can_edit_machines", "pool:0")
+	require.NoError(t, err)
+	assert.Equal(t, "USER            RELATION           OBJECT\n"+
+		"group:1#member  can_edit_machines  pool:0\n", out)
+	require.Len(t, writes, 1)
+	assert.Equal(t, map[string]any{
+		"tuple_keys": []any{map[string]any{
+			"user": "group:1#member", "relation": "can_edit_machines", "object": "pool:0",
+		}},
+		"on_missing": "ignore",
+	}, writes[0]["deletes"])
+}
+
+func TestAccessListCmd(t *testing.T) {
+	socketPath := fakeAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]any
+
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, map[string]any{"object": "pool:0"}, req["tuple_key"])
+
+		_, _ = w.Write([]byte(`{"tuples":[{"key":{"user":"group:1#member",` +
+			`"relation":"can_edit_machines","object":"pool:0"}}]}`))
+	})
+
+	out, err := execute(t, "access", "list", "--socket", socketPath, "--object", "pool:0",
+		"--output", "json")
+	require.NoError(t, err)
+	assert.JSONEq(t, `[{"user":"group:1#member","relation":"can_edit_machines","object":"pool:0"}]`, out)
+
+	_, err = execute(t, "access", "list", "--socket", socketPath, "--object", "pool:0",
+		"--output", "yaml")
+	assert.ErrorContains(t, err, `invalid output format "yaml"`)
+}
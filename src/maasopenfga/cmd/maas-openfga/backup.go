@@ -0,0 +1,147 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"maas.io/core/src/maasopenfga/internal/backup"
+	"maas.io/core/src/maasopenfga/internal/migrations"
+)
+
+func exportCmd() *cobra.Command {
+	var quiet bool
+
+	cmd := &cobra.Command{
+		Use:   "export [<file>]",
+		Short: "Export the tuples of the MAAS store to a file.",
+		Long: `Export the tuples of the MAAS store as JSON lines, to the given file or
+to stdout. The export is taken from a consistent snapshot of the database
+and can be restored with import.`,
+		Example: "maas-openfga export tuples.jsonl",
+		Args:    cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			progress := progressReporter(cmd.ErrOrStderr(), "exported", quiet)
+
+			return withDatastore(func(db *sql.DB) error {
+				if len(args) == 0 || args[0] == "-" {
+					_, err := backup.Export(cmd.Context(), db, migrations.StoreID, cmd.OutOrStdout(), progress)
+					return err
+				}
+
+				path := filepath.Clean(args[0])
+
+				f, err := os.Create(path)
+				if err != nil {
+					return fmt.Errorf("failed to create export file: %w", err)
+				}
+
+				_, err = backup.Export(cmd.Context(), db, migrations.StoreID, f, progress)
+				if err = errors.Join(err, f.Close()); err != nil {
+					// Don't leave an incomplete export behind.
+					return errors.Join(err, os.Remove(path))
+				}
+
+				return nil
+			})
+		},
+	}
+
+	cmd.Flags().BoolVarP(&quiet, "quiet", "q", false, "Don't report progress")
+
+	return cmd
+}
+
+func importCmd() *cobra.Command {
+	var replace, quiet bool
+
+	cmd := &cobra.Command{
+		Use:   "import <file>",
+		Short: "Import tuples exported with export into the MAAS store.",
+		Long: `Import tuples exported with export into the MAAS store.
+
+Tuples are written in chunks, those already present are left untouched, so
+an interrupted import can simply be run again. By default, the tuples of the
+store missing from the file are kept; with --replace, they are deleted once
+the whole file has been imported.`,
+		Example: "maas-openfga import tuples.jsonl --replace",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var in io.Reader = cmd.InOrStdin()
+
+			if args[0] != "-" {
+				f, err := os.Open(filepath.Clean(args[0]))
+				if err != nil {
+					return fmt.Errorf("failed to open export file: %w", err)
+				}
+
+				defer f.Close() //nolint:errcheck // file is opened for reading only
+
+				in = f
+			}
+
+			r, err := backup.NewReader(in)
+			if err != nil {
+				return err
+			}
+
+			mode := backup.ModeMerge
+			if replace {
+				mode = backup.ModeReplace
+			}
+
+			return withDatastore(func(db *sql.DB) error {
+				if err := requirePrimary(cmd.Context(), db, "import tuples"); err != nil {
+					return err
+				}
+
+				result, err := backup.Import(cmd.Context(), db, migrations.StoreID, r, mode,
+					progressReporter(cmd.ErrOrStderr(), "imported", quiet))
+				if err != nil {
+					return fmt.Errorf("import failed after %d tuples: %w", result.Read, err)
+				}
+
+				fmt.Fprintf(cmd.OutOrStdout(), "%d tuples read, %d written, %d deleted\n",
+					result.Read, result.Written, result.Deleted)
+
+				return nil
+			})
+		},
+	}
+
+	cmd.Flags().BoolVar(&replace, "replace", false,
+		"Delete the tuples of the store missing from the file")
+	cmd.Flags().BoolVarP(&quiet, "quiet", "q", false, "Don't report progress")
+
+	return cmd
+}
+
+// progressReporter returns a backup.Progress printing to w, nil if quiet.
+func progressReporter(w io.Writer, verb string, quiet bool) backup.Progress {
+	if quiet {
+		return nil
+	}
+
+	return func(done, total int) {
+		fmt.Fprintf(w, "%s %d/%d tuples\n", verb, done, total)
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/spf13/cobra"
+	"maas.io/core/src/maasopenfga/pkg/openfgaclient"
+)
+
+func checkCmd() *cobra.Command {
+	var (
+		socketPath, store string
+		contextual        []string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "check <user> <relation> <object>",
+		Short: "Check whether a user has a relation with an object.",
+		Example: `  maas-openfga check user:1 can_deploy_machines pool:0
+  maas-openfga check user:1 can_deploy machine:3 --contextual "pool:0 pool machine:3"`,
+		Args: cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			req := &openfgav1.CheckRequest{
+				TupleKey: &openfgav1.CheckRequestTupleKey{
+					User:     args[0],
+					Relation: args[1],
+					Object:   args[2],
+				},
+			}
+
+			if len(contextual) > 0 {
+				keys, err := parseContextualTuples(contextual)
+				if err != nil {
+					return err
+				}
+
+				req.ContextualTuples = &openfgav1.ContextualTupleKeys{TupleKeys: keys}
+			}
+
+			var resp openfgav1.CheckResponse
+
+			if err := newAPIClient(socketPath, store).do(cmd.Context(), http.MethodPost,
+				"/check", req, &resp); err != nil {
+				return err
+			}
+
+			result := "denied"
+			if resp.GetAllowed() {
+				result = "allowed"
+			}
+
+			fmt.Fprintln(cmd.OutOrStdout(), result)
+
+			return nil
+		},
+	}
+
+	addSocketFlag(cmd, &socketPath)
+	addStoreFlag(cmd, &store)
+	cmd.Flags().StringArrayVar(&contextual, "contextual", nil,
+		`Tuple taken into account for this check only, as "<user> <relation> <object>", can be repeated`)
+
+	return cmd
+}
+
+// parseContextualTuples parses the tuples given with --contextual.
+func parseContextualTuples(values []string) ([]*openfgav1.TupleKey, error) {
+	if len(values) > openfgaclient.MaxContextualTuples {
+		return nil, fmt.Errorf("too many contextual tuples: %d, at most %d are allowed",
+			len(values), openfgaclient.MaxContextualTuples)
+	}
+
+	keys := make([]*openfgav1.TupleKey, 0, len(values))
+
+	for _, value := range values {
+		fields := strings.Fields(value)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("invalid contextual tuple %q: expected \"<user> <relation> <object>\"", value)
+		}
+
+		keys = append(keys, &openfgav1.TupleKey{User: fields[0], Relation: fields[1], Object: fields[2]})
+	}
+
+	return keys, nil
+}
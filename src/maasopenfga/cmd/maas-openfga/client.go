@@ -0,0 +1,198 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+	"maas.io/core/src/maasopenfga/internal/migrations"
+	"maas.io/core/src/maasopenfga/internal/server"
+)
+
+const (
+	clientTimeout = 30 * time.Second
+)
+
+// apiClient talks to a running maas-openfga over its HTTP API.
+type apiClient struct {
+	httpClient *http.Client
+	store      string
+}
+
+// newAPIClient returns a client of the store with the given name or ID,
+// the MAAS store if empty.
+func newAPIClient(socketPath, store string) *apiClient {
+	if store == "" {
+		store = migrations.StoreID
+	}
+
+	return &apiClient{
+		store: store,
+		httpClient: &http.Client{
+			Timeout: clientTimeout,
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					var d net.Dialer
+					return d.DialContext(ctx, "unix", socketPath)
+				},
+			},
+		},
+	}
+}
+
+// do calls an endpoint of the store, e.g. "/check".
+func (c *apiClient) do(ctx context.Context, method, path string,
+	in, out proto.Message) error {
+	var body io.Reader
+
+	if in != nil {
+		data, err := protojson.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+
+		body = bytes.NewReader(data)
+	}
+
+	// Host is ignored, connections always go to the unix socket.
+	endpoint := "http://maas-openfga/stores/" + url.PathEscape(c.store) + path
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+
+	//nolint:errcheck // we do not care about possible error here
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return apiError(resp, data)
+	}
+
+	unmarshal := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err := unmarshal.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return nil
+}
+
+// latestModel returns the authorization model currently used by the store.
+func (c *apiClient) latestModel(ctx context.Context) (*openfgav1.AuthorizationModel, error) {
+	var resp openfgav1.ReadAuthorizationModelsResponse
+
+	// Models are returned newest first.
+	if err := c.do(ctx, http.MethodGet, "/authorization-models?page_size=1",
+		nil, &resp); err != nil {
+		return nil, err
+	}
+
+	models := resp.GetAuthorizationModels()
+	if len(models) == 0 {
+		return nil, errors.New("no authorization model found, were migrations applied?")
+	}
+
+	return models[0], nil
+}
+
+// readTuples returns the tuples matching key, all of them if nil, following
+// continuation tokens.
+func (c *apiClient) readTuples(ctx context.Context,
+	key *openfgav1.ReadRequestTupleKey) ([]*openfgav1.TupleKey, error) {
+	req := &openfgav1.ReadRequest{PageSize: wrapperspb.Int32(tuplePageSize), TupleKey: key}
+
+	var keys []*openfgav1.TupleKey
+
+	for {
+		var resp openfgav1.ReadResponse
+
+		if err := c.do(ctx, http.MethodPost, "/read", req, &resp); err != nil {
+			return nil, err
+		}
+
+		for _, t := range resp.GetTuples() {
+			keys = append(keys, t.GetKey())
+		}
+
+		if resp.GetContinuationToken() == "" {
+			return keys, nil
+		}
+
+		req.ContinuationToken = resp.GetContinuationToken()
+	}
+}
+
+// apiError extracts the error code and message from the error envelope of
+// a response, with the request ID to look for in the server logs.
+func apiError(resp *http.Response, data []byte) error {
+	var e struct {
+		Details []struct {
+			Type    string `json:"type"`
+			Message string `json:"message"`
+		} `json:"details"`
+	}
+
+	msg := fmt.Sprintf("request failed with status %d", resp.StatusCode)
+	if err := json.Unmarshal(data, &e); err == nil && len(e.Details) > 0 {
+		msg = e.Details[0].Type + ": " + e.Details[0].Message
+	}
+
+	if id := resp.Header.Get(server.RequestIDHeader); id != "" {
+		msg += " (request " + id + ")"
+	}
+
+	return errors.New(msg)
+}
+
+// addSocketFlag registers the flag selecting the maas-openfga socket.
+func addSocketFlag(cmd *cobra.Command, socketPath *string) {
+	cmd.Flags().StringVar(socketPath, "socket", defaultSocketPath(),
+		"Path to the maas-openfga unix socket")
+}
+
+// addStoreFlag registers the flag selecting the store.
+func addStoreFlag(cmd *cobra.Command, store *string) {
+	cmd.Flags().StringVar(store, "store", "",
+		"Name or ID of the store (default: the MAAS store)")
+}
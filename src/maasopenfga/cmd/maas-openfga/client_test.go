@@ -0,0 +1,145 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maas.io/core/src/maasopenfga/internal/server"
+)
+
+// fakeAPI serves handler on a unix socket and returns its path.
+func fakeAPI(t *testing.T, handler http.HandlerFunc) string {
+	t.Helper()
+
+	socketPath := filepath.Join(t.TempDir(), "openfga.sock")
+
+	lis, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+
+	srv := &http.Server{Handler: handler} //nolint:gosec // test server
+	go srv.Serve(lis)                     //nolint:errcheck // closed on cleanup
+
+	t.Cleanup(func() { _ = srv.Close() }) //nolint:errcheck // test
+
+	return socketPath
+}
+
+func execute(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+
+	var out bytes.Buffer
+
+	cmd := rootCmd()
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs(args)
+
+	err := cmd.ExecuteContext(t.Context())
+
+	return out.String(), err
+}
+
+func TestCheckCmd(t *testing.T) {
+	testcases := map[string]struct {
+		status   int
+		response string
+		out      string
+		err      string
+	}{
+		"allowed": {
+			status:   http.StatusOK,
+			response: `{"allowed":true}`,
+			out:      "allowed\n",
+		},
+		"denied": {
+			status:   http.StatusOK,
+			response: `{"allowed":false}`,
+			out:      "denied\n",
+		},
+		"invalid relation": {
+			status: http.StatusBadRequest,
+			response: `{"kind":"Error","code":400,"message":"Bad request.",` +
+				`"details":[{"type":"invalid_argument","message":"relation 'pool#foo' not found"}]}`,
+			err: "invalid_argument: relation 'pool#foo' not found (request req-1)",
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			var got map[string]any
+
+			socketPath := fakeAPI(t, func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/stores/00000000000000000000000000/check", r.URL.Path)
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+
+				w.Header().Set(server.RequestIDHeader, "req-1")
+				w.WriteHeader(tc.status)
+				_, _ = w.Write([]byte(tc.response))
+			})
+
+			out, err := execute(t, "check", "--socket", socketPath,
+				"user:1", "can_deploy_machines", "pool:0")
+			if tc.err != "" {
+				assert.ErrorContains(t, err, tc.err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.out, out)
+			assert.Equal(t, map[string]any{"tuple_key": map[string]any{
+				"user": "user:1", "relation": "can_deploy_machines", "object": "pool:0",
+			}}, got)
+		})
+	}
+}
+
+func TestTupleReadCmd(t *testing.T) {
+	pages := []string{
+		`{"tuples":[{"key":{"user":"group:1#member","relation":"can_edit_machines",` +
+			`"object":"pool:0"}}],"continuation_token":"next"}`,
+		`{"tuples":[{"key":{"user":"maas:0","relation":"parent","object":"pool:0"}}]}`,
+	}
+
+	var calls int
+
+	socketPath := fakeAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]any
+
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		if calls > 0 {
+			assert.Equal(t, "next", req["continuation_token"])
+		}
+
+		_, _ = w.Write([]byte(pages[calls]))
+		calls++
+	})
+
+	out, err := execute(t, "tuple", "read", "--socket", socketPath, "--object", "pool:0")
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, "USER            RELATION           OBJECT\n"+
+		"group:1#member  can_edit_machines  pool:0\n"+
+		"maas:0          parent             pool:0\n", out)
+}
@@ -0,0 +1,365 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	"maas.io/core/src/maasgocommon/configschema"
+)
+
+const (
+	defaultMaxOpenConns     = 3
+	defaultMaxIdleConns     = 1
+	defaultMaxBatchRequests = 1
+	defaultDatabasePort     = 5432
+	defaultMirrorInterval   = 300
+	defaultMaxDepth         = 25
+	defaultMaxBreadth       = 10
+	defaultRequestTimeout   = 3
+)
+
+// sslModes are the sslmode values understood by libpq and pgx.
+var sslModes = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+
+// regionConfig holds the settings maas-openfga reads from regiond.conf.
+// The file contains other regiond settings, which are ignored.
+type regionConfig struct {
+	DatabaseHost              string           `yaml:"database_host" doc:"Unix socket directory or host of the PostgreSQL database." schema:"required"`
+	DatabasePort              int              `yaml:"database_port" doc:"Port of the PostgreSQL database." schema:"min=1,max=65535,default=5432"`
+	DatabaseName              string           `yaml:"database_name" doc:"Name of the PostgreSQL database." schema:"required"`
+	DatabasePass              string           `yaml:"database_pass" doc:"Password of the PostgreSQL user, required over TCP."`
+	DatabaseUser              string           `yaml:"database_user" doc:"User to connect to PostgreSQL as." schema:"required"`
+	DatabaseSSLMode           string           `yaml:"database_sslmode" doc:"SSL mode used over TCP." schema:"enum=disable|allow|prefer|require|verify-ca|verify-full"`
+	DatabaseSSLRootCert       string           `yaml:"database_sslrootcert" doc:"CA certificate used to verify the database server."`
+	OpenFGAMaxOpenConns       int              `yaml:"openfga_max_open_conns" doc:"Maximum number of open database connections." schema:"min=0,default=3"`
+	OpenFGAMaxIdleConns       int              `yaml:"openfga_max_idle_conns" doc:"Maximum number of idle database connections." schema:"min=0,default=1"`
+	OpenFGAMaxBatchRequests   int              `yaml:"openfga_max_batch_requests" doc:"Maximum number of batch requests (e.g. replication) served at once." schema:"min=0,default=1"`
+	OpenFGAListeners          []listenerConfig `yaml:"openfga_listeners" doc:"Addresses to serve the OpenFGA HTTP API on (default: the regiond unix socket)."`
+	OpenFGAReplicationPrimary string           `yaml:"openfga_replication_primary" doc:"HTTP API URL of the primary maas-openfga to replicate tuples from, on standby region clusters only."`
+	OpenFGAMirrorSource       string           `yaml:"openfga_mirror_source" doc:"HTTP API URL of a maas-openfga to serve a read-only copy of, instead of the database, for preview environments."`
+	OpenFGADatastoreURI       string           `yaml:"openfga_datastore_uri" doc:"PostgreSQL URI of a dedicated OpenFGA datastore, instead of the openfga schema of the MAAS database, as set by migrate-datastore --switch."`
+	VaultURL                  string           `yaml:"vault_url" doc:"URL of the Vault server regiond stores secrets in, the database credentials among them."`
+	VaultSecretsMount         string           `yaml:"vault_secrets_mount" doc:"Mount path of the Vault KV engine." schema:"default=secret"`
+	VaultSecretsPath          string           `yaml:"vault_secrets_path" doc:"Path prefix of the MAAS secrets in the Vault KV engine."`
+	VaultAppRoleID            string           `yaml:"vault_approle_id" doc:"AppRole ID used to log in to Vault."`
+	VaultSecretID             string           `yaml:"vault_secret_id" doc:"Secret ID used to log in to Vault."`
+	OpenFGAReconcileInterval  int              `yaml:"openfga_reconcile_interval" doc:"Seconds between removals of tuples referencing deleted MAAS entities, 0 to disable." schema:"min=0,default=0"`
+	OpenFGAMirrorInterval     int              `yaml:"openfga_mirror_interval" doc:"Seconds between refreshes of the read-only copy of openfga_mirror_source." schema:"min=1,default=300"`
+	OpenFGARequestTimeout     int              `yaml:"openfga_request_timeout" doc:"Seconds given to a check or listing to resolve, after which it fails with a resolution limit error." schema:"min=0,default=3"`
+	OpenFGAMaxResolutionDepth uint32           `yaml:"openfga_max_resolution_depth" doc:"Maximum number of nested relations (e.g. groups of groups) resolved to answer a check." schema:"min=0,default=25"`
+	OpenFGAResolutionBreadth  uint32           `yaml:"openfga_max_resolution_breadth" doc:"Maximum number of relations resolved at once on each level of a check." schema:"min=0,default=10"`
+	OpenFGAThrottleThreshold  uint32           `yaml:"openfga_dispatch_throttling_threshold" doc:"Number of relations a check resolves before being slowed down, so that it leaves database connections to the others, 0 to disable." schema:"min=0,default=0"`
+	OpenFGAEntitySync         bool             `yaml:"openfga_entity_sync" doc:"Update tuples as regiond creates and deletes users, groups and resource pools, ignored on standby region clusters."`
+
+	// source is where the settings were read from.
+	source configSource
+}
+
+// configSchema returns the JSON Schema of the settings maas-openfga reads
+// from regiond.conf.
+func configSchema() *configschema.Schema {
+	s := configschema.Generate(regionConfig{}, "maas-openfga configuration")
+	s.Description = "Settings read from regiond.conf by maas-openfga. In the snap, they can be set " +
+		"with `snap set maas` instead, the prefix being the section and dashes replacing " +
+		"underscores, e.g. database.host or openfga.max-open-conns."
+	// Other regiond settings live in the same file.
+	s.AdditionalProperties = true
+
+	return s
+}
+
+// regionConfigPath returns the path of regiond.conf.
+func regionConfigPath() string {
+	configDir := os.Getenv("SNAP_DATA")
+	if configDir == "" {
+		// Deb installation
+		configDir = "/etc/maas"
+	}
+
+	return filepath.Join(configDir, "regiond.conf")
+}
+
+// readRegionConfig reads the settings from the snap configuration or
+// regiond.conf, with the database credentials regiond migrated to Vault if
+// it's enabled.
+func readRegionConfig() (*regionConfig, error) {
+	ctx := context.Background()
+
+	cfg, source, err := readConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	regionCfg, err := parseRegionConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", source, err)
+	}
+
+	regionCfg.source = source
+
+	if regionCfg.VaultURL != "" {
+		if err := applyVaultDatabaseCreds(ctx, regionCfg, newVault(regionCfg)); err != nil {
+			return nil, err
+		}
+	}
+
+	return regionCfg, nil
+}
+
+func parseRegionConfig(cfg []byte) (*regionConfig, error) {
+	var regionCfg regionConfig
+
+	err := yaml.Unmarshal(cfg, &regionCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse region config file: %w", err)
+	}
+
+	if regionCfg.OpenFGAMaxOpenConns <= 0 {
+		regionCfg.OpenFGAMaxOpenConns = defaultMaxOpenConns
+	}
+
+	if regionCfg.OpenFGAMaxIdleConns <= 0 {
+		regionCfg.OpenFGAMaxIdleConns = defaultMaxIdleConns
+	}
+
+	if regionCfg.OpenFGAMaxBatchRequests <= 0 {
+		regionCfg.OpenFGAMaxBatchRequests = defaultMaxBatchRequests
+	}
+
+	if regionCfg.OpenFGAMirrorInterval <= 0 {
+		regionCfg.OpenFGAMirrorInterval = defaultMirrorInterval
+	}
+
+	if regionCfg.OpenFGAMaxResolutionDepth == 0 {
+		regionCfg.OpenFGAMaxResolutionDepth = defaultMaxDepth
+	}
+
+	if regionCfg.OpenFGAResolutionBreadth == 0 {
+		regionCfg.OpenFGAResolutionBreadth = defaultMaxBreadth
+	}
+
+	if regionCfg.OpenFGARequestTimeout <= 0 {
+		regionCfg.OpenFGARequestTimeout = defaultRequestTimeout
+	}
+
+	if len(regionCfg.OpenFGAListeners) == 0 {
+		regionCfg.OpenFGAListeners = []listenerConfig{defaultListenerConfig()}
+	}
+
+	for i := range regionCfg.OpenFGAListeners {
+		if err := regionCfg.OpenFGAListeners[i].validate(); err != nil {
+			return nil, fmt.Errorf("invalid listener configuration: %w", err)
+		}
+	}
+
+	if primary := regionCfg.OpenFGAReplicationPrimary; primary != "" {
+		u, err := url.Parse(primary)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("invalid replication primary %q: expected an http(s) URL", primary)
+		}
+	}
+
+	if source := regionCfg.OpenFGAMirrorSource; source != "" {
+		u, err := url.Parse(source)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("invalid mirror source %q: expected an http(s) URL", source)
+		}
+
+		if regionCfg.OpenFGAReplicationPrimary != "" {
+			return nil, errors.New("openfga_mirror_source and openfga_replication_primary are mutually exclusive")
+		}
+	}
+
+	if uri := regionCfg.OpenFGADatastoreURI; uri != "" {
+		u, err := url.Parse(uri)
+		if err != nil || (u.Scheme != "postgres" && u.Scheme != "postgresql") {
+			return nil, errors.New("invalid openfga_datastore_uri: expected a postgres:// URI")
+		}
+
+		// Both join the tuples with the MAAS tables.
+		if regionCfg.OpenFGAEntitySync || regionCfg.OpenFGAReconcileInterval > 0 {
+			return nil, errors.New("openfga_entity_sync and openfga_reconcile_interval " +
+				"can't be used with openfga_datastore_uri")
+		}
+	}
+
+	if vaultURL := regionCfg.VaultURL; vaultURL != "" {
+		u, err := url.Parse(vaultURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("invalid vault_url %q: expected an http(s) URL", vaultURL)
+		}
+
+		if regionCfg.VaultAppRoleID == "" || regionCfg.VaultSecretID == "" {
+			return nil, errors.New("vault_approle_id and vault_secret_id are required with vault_url")
+		}
+	}
+
+	return &regionCfg, nil
+}
+
+// validateRegionConfig checks regiond.conf content against the schema and
+// then the semantic rules applied at startup.
+func validateRegionConfig(data []byte) error {
+	if err := configSchema().ValidateYAML(data); err != nil {
+		return err
+	}
+
+	cfg, err := parseRegionConfig(data)
+	if err != nil {
+		return err
+	}
+
+	// With Vault, the credentials are usually missing from the file and
+	// fetched at startup, the rest of the settings are still checked.
+	if cfg.VaultURL != "" && cfg.DatabasePass == "" {
+		cfg.DatabasePass = "from-vault"
+	}
+
+	if _, err := getPostgresDSN(cfg); err != nil {
+		return fmt.Errorf("invalid database configuration: %w", err)
+	}
+
+	return nil
+}
+
+// errDedicatedDatastore is returned when the openfga schema and the MAAS
+// tables are needed together, but the datastore was moved out of the MAAS
+// database.
+var errDedicatedDatastore = errors.New("not available with openfga_datastore_uri: " +
+	"the openfga schema is no longer in the MAAS database")
+
+// getPostgresDSN builds the connection string for the OpenFGA datastore,
+// the dedicated one if configured.
+func getPostgresDSN(cfg *regionConfig) (string, error) {
+	if cfg.OpenFGADatastoreURI != "" {
+		return cfg.OpenFGADatastoreURI, nil
+	}
+
+	return buildPostgresDSN(cfg, "openfga")
+}
+
+// getAppPostgresDSN builds the connection string used by MAAS migrations,
+// which need to access MAAS tables as well as the openfga schema.
+func getAppPostgresDSN(cfg *regionConfig) (string, error) {
+	if cfg.OpenFGADatastoreURI != "" {
+		return "", errDedicatedDatastore
+	}
+
+	return buildPostgresDSN(cfg, "")
+}
+
+// buildPostgresDSN builds a connection string from the region config.
+// database_host pointing to a directory means a local unix socket (usually
+// with peer authentication), anything else is a host reached over TCP with
+// password authentication and optional SSL.
+func buildPostgresDSN(cfg *regionConfig, searchPath string) (string, error) {
+	if cfg.DatabaseHost == "" {
+		return "", errors.New("database_host is not set")
+	}
+
+	if cfg.DatabasePort < 0 || cfg.DatabasePort > 65535 {
+		return "", fmt.Errorf("invalid database_port %d", cfg.DatabasePort)
+	}
+
+	if cfg.DatabaseSSLMode != "" && !slices.Contains(sslModes, cfg.DatabaseSSLMode) {
+		return "", fmt.Errorf("invalid database_sslmode %q, must be one of: %s",
+			cfg.DatabaseSSLMode, strings.Join(sslModes, ", "))
+	}
+
+	dsn := url.URL{
+		Scheme: "postgres",
+		User:   url.User(cfg.DatabaseUser),
+		Path:   "/" + cfg.DatabaseName,
+	}
+
+	if cfg.DatabasePass != "" {
+		dsn.User = url.UserPassword(cfg.DatabaseUser, cfg.DatabasePass)
+	}
+
+	query := url.Values{}
+	if searchPath != "" {
+		query.Set("search_path", searchPath)
+	}
+
+	if strings.HasPrefix(cfg.DatabaseHost, "/") {
+		// SSL is never negotiated over unix sockets.
+		if cfg.DatabaseSSLMode != "" && cfg.DatabaseSSLMode != "disable" {
+			return "", fmt.Errorf("database_sslmode %q is not supported "+
+				"when database_host is a unix socket directory", cfg.DatabaseSSLMode)
+		}
+
+		if cfg.DatabaseSSLRootCert != "" {
+			return "", errors.New("database_sslrootcert is not supported " +
+				"when database_host is a unix socket directory")
+		}
+
+		query.Set("host", cfg.DatabaseHost)
+
+		if cfg.DatabasePort != 0 {
+			query.Set("port", strconv.Itoa(cfg.DatabasePort))
+		}
+
+		dsn.RawQuery = query.Encode()
+
+		return dsn.String(), nil
+	}
+
+	if cfg.DatabasePass == "" {
+		return "", fmt.Errorf("database_pass is required to connect to %q over TCP",
+			cfg.DatabaseHost)
+	}
+
+	port := cfg.DatabasePort
+	if port == 0 {
+		port = defaultDatabasePort
+	}
+
+	dsn.Host = net.JoinHostPort(cfg.DatabaseHost, strconv.Itoa(port))
+
+	if cfg.DatabaseSSLMode != "" {
+		query.Set("sslmode", cfg.DatabaseSSLMode)
+	}
+
+	if cfg.DatabaseSSLRootCert != "" {
+		switch cfg.DatabaseSSLMode {
+		case "require", "verify-ca", "verify-full":
+		default:
+			return "", fmt.Errorf("database_sslrootcert requires database_sslmode "+
+				"to be one of: require, verify-ca, verify-full (got %q)", cfg.DatabaseSSLMode)
+		}
+
+		if _, err := os.Stat(cfg.DatabaseSSLRootCert); err != nil {
+			return "", fmt.Errorf("database_sslrootcert is not accessible: %w", err)
+		}
+
+		query.Set("sslrootcert", cfg.DatabaseSSLRootCert)
+	}
+
+	dsn.RawQuery = query.Encode()
+
+	return dsn.String(), nil
+}
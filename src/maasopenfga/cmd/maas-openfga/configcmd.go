@@ -0,0 +1,120 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func configCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect or validate the maas-openfga configuration.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(configSchemaCmd())
+	cmd.AddCommand(configValidateCmd())
+
+	return cmd
+}
+
+func configSchemaCmd() *cobra.Command {
+	var format string
+
+	cmd := &cobra.Command{
+		Use:     "schema",
+		Short:   "Print the configuration schema.",
+		Example: "maas-openfga config schema --format markdown",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			schema := configSchema()
+
+			switch format {
+			case "json":
+				data, err := schema.JSON()
+				if err != nil {
+					return err
+				}
+
+				fmt.Fprintln(cmd.OutOrStdout(), string(data))
+
+				return nil
+			case "markdown":
+				return schema.Markdown(cmd.OutOrStdout())
+			default:
+				return fmt.Errorf("unsupported format %q (json, markdown)", format)
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", "json",
+		"Output format: json (JSON Schema) or markdown (reference documentation)")
+
+	return cmd
+}
+
+func configValidateCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "validate [file]",
+		Short: "Validate regiond.conf without starting the service.",
+		Long: "Validate regiond.conf without starting the service.\n\n" +
+			"Without a file, the settings maas-openfga would start with are validated: " +
+			"the snap configuration when it holds the database settings, regiond.conf otherwise.",
+		Example: "maas-openfga config validate /etc/maas/regiond.conf",
+		Args:    cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var (
+				data   []byte
+				source configSource
+				err    error
+			)
+
+			if len(args) == 1 {
+				source = fileConfig(args[0])
+				data, err = source.Read(cmd.Context())
+			} else {
+				data, source, err = readConfig(cmd.Context())
+			}
+
+			if err != nil {
+				return err
+			}
+
+			if err := validateRegionConfig(data); err != nil {
+				var joined interface{ Unwrap() []error }
+				if errors.As(err, &joined) {
+					for _, e := range joined.Unwrap() {
+						fmt.Fprintln(cmd.ErrOrStderr(), e)
+					}
+				} else {
+					fmt.Fprintln(cmd.ErrOrStderr(), err)
+				}
+
+				return fmt.Errorf("%s is not valid", source)
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "%s is valid\n", source)
+
+			return nil
+		},
+	}
+}
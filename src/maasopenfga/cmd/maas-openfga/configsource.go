@@ -0,0 +1,347 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const snapctlTimeout = 10 * time.Second
+
+// snapConfigSections are the snap configuration options holding the
+// settings of maas-openfga, e.g. `snap set maas database.host=...`.
+var snapConfigSections = []string{"database", "openfga", "vault"}
+
+// errNoSnapConfig is returned when the snap doesn't hold the database
+// settings, which are then read from regiond.conf.
+var errNoSnapConfig = errors.New("no database settings in the snap configuration")
+
+// configSource is where the settings of maas-openfga are read from.
+type configSource interface {
+	// Read returns the settings in the format of regiond.conf.
+	Read(ctx context.Context) ([]byte, error)
+	// Set changes a setting, named as in regiond.conf, at once: readers
+	// see either the previous settings or the new ones. validate is given
+	// the settings with the change, which is only made if it succeeds.
+	Set(ctx context.Context, name, value string, validate func(*regionConfig) error) error
+	// String describes the source, e.g. in logs.
+	String() string
+}
+
+// fileConfig reads the settings from a regiond.conf file.
+type fileConfig string
+
+func (f fileConfig) Read(context.Context) ([]byte, error) {
+	data, err := os.ReadFile(filepath.Clean(string(f)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read region config file: %w", err)
+	}
+
+	return data, nil
+}
+
+// Set rewrites the file with the setting changed, keeping the others and
+// their comments, and replaces the file with the new one once the written
+// settings are validated.
+func (f fileConfig) Set(_ context.Context, name, value string, validate func(*regionConfig) error) error {
+	path := filepath.Clean(string(f))
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to read region config file: %w", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read region config file: %w", err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to parse region config file: %w", err)
+	}
+
+	if err := setConfigNode(&doc, name, value); err != nil {
+		return err
+	}
+
+	var out bytes.Buffer
+
+	enc := yaml.NewEncoder(&out)
+	enc.SetIndent(2)
+
+	if err := enc.Encode(&doc); err != nil {
+		return fmt.Errorf("failed to write region config file: %w", err)
+	}
+
+	return writeFileAtomic(path, out.Bytes(), info, func(tmpPath string) error {
+		return validateConfigFile(tmpPath, validate)
+	})
+}
+
+// validateConfigFile parses the settings written to path and validates them.
+func validateConfigFile(path string, validate func(*regionConfig) error) error {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return err
+	}
+
+	cfg, err := parseRegionConfig(data)
+	if err != nil {
+		return err
+	}
+
+	return validate(cfg)
+}
+
+// setConfigNode sets the top-level setting of the YAML document.
+func setConfigNode(doc *yaml.Node, name, value string) error {
+	if doc.Kind == 0 {
+		// An empty file.
+		doc.Kind = yaml.DocumentNode
+		doc.Content = []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}
+	}
+
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) != 1 || doc.Content[0].Kind != yaml.MappingNode {
+		return errors.New("failed to parse region config file: expected a mapping of settings")
+	}
+
+	settings := doc.Content[0]
+	node := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+
+	for i := 0; i+1 < len(settings.Content); i += 2 {
+		if settings.Content[i].Value == name {
+			settings.Content[i+1] = node
+			return nil
+		}
+	}
+
+	settings.Content = append(settings.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: name}, node)
+
+	return nil
+}
+
+// writeFileAtomic replaces the file at path with one holding data, written
+// next to it and renamed over it, with the mode and owner of the file (as
+// regiond reads it too). check is given the path of the written file, which
+// is only renamed if it succeeds.
+func writeFileAtomic(path string, data []byte, info os.FileInfo, check func(tmpPath string) error) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write region config file: %w", err)
+	}
+
+	defer os.Remove(tmp.Name()) //nolint:errcheck // gone after the rename
+
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Chmod(info.Mode().Perm())
+	}
+
+	if st, ok := info.Sys().(*syscall.Stat_t); ok && err == nil &&
+		(int(st.Uid) != os.Getuid() || int(st.Gid) != os.Getgid()) {
+		err = tmp.Chown(int(st.Uid), int(st.Gid))
+	}
+
+	if err == nil {
+		err = tmp.Sync()
+	}
+
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to write region config file: %w", err)
+	}
+
+	if err := check(tmp.Name()); err != nil {
+		return fmt.Errorf("invalid region config file, left unchanged: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write region config file: %w", err)
+	}
+
+	return nil
+}
+
+func (f fileConfig) String() string {
+	return string(f)
+}
+
+// snapConfig reads the settings from the snap configuration with snapctl,
+// so that they can be managed with `snap set maas`. Options are named
+// after the regiond.conf settings, with the prefix as section and dashes
+// instead of underscores: database_host is database.host, and
+// openfga_max_open_conns is openfga.max-open-conns.
+type snapConfig struct {
+	// run runs snapctl with the given arguments and returns its output.
+	run func(ctx context.Context, args ...string) ([]byte, error)
+}
+
+func newSnapConfig() snapConfig {
+	return snapConfig{run: runSnapctl}
+}
+
+func runSnapctl(ctx context.Context, args ...string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, snapctlTimeout)
+	defer cancel()
+
+	var stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, "snapctl", args...)
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("snapctl %s failed: %s: %w",
+			strings.Join(args, " "), bytes.TrimSpace(stderr.Bytes()), err)
+	}
+
+	return out, nil
+}
+
+func (s snapConfig) Read(ctx context.Context) ([]byte, error) {
+	out, err := s.run(ctx, append([]string{"get", "-d"}, snapConfigSections...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	var sections map[string]map[string]any
+	if err := json.Unmarshal(out, &sections); err != nil {
+		return nil, fmt.Errorf("failed to parse snap configuration: %w", err)
+	}
+
+	if len(sections["database"]) == 0 {
+		return nil, errNoSnapConfig
+	}
+
+	settings := make(map[string]any)
+
+	for section, options := range sections {
+		for name, value := range options {
+			settings[regionConfigName(section+"_"+name)] = regionConfigNames(value)
+		}
+	}
+
+	// JSON documents are YAML documents.
+	return json.Marshal(settings)
+}
+
+// Set sets the snap option of the setting, e.g. openfga.datastore-uri for
+// openfga_datastore_uri, once the settings with the change are validated.
+// snapctl applies it at once.
+func (s snapConfig) Set(ctx context.Context, name, value string, validate func(*regionConfig) error) error {
+	section, option, ok := strings.Cut(name, "_")
+	if !ok {
+		return fmt.Errorf("setting %q has no section", name)
+	}
+
+	data, err := s.Read(ctx)
+	if err != nil {
+		return err
+	}
+
+	var settings map[string]any
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return fmt.Errorf("failed to parse snap configuration: %w", err)
+	}
+
+	settings[name] = value
+
+	if data, err = json.Marshal(settings); err != nil {
+		return err
+	}
+
+	cfg, err := parseRegionConfig(data)
+	if err == nil {
+		err = validate(cfg)
+	}
+
+	if err != nil {
+		return fmt.Errorf("invalid snap configuration, left unchanged: %w", err)
+	}
+
+	_, err = s.run(ctx, "set", section+"."+strings.ReplaceAll(option, "_", "-")+"="+value)
+
+	return err
+}
+
+func (snapConfig) String() string {
+	return "snapctl"
+}
+
+// regionConfigName returns the regiond.conf name of a snap option.
+func regionConfigName(name string) string {
+	return strings.ReplaceAll(name, "-", "_")
+}
+
+// regionConfigNames renames the keys of the objects in value, e.g. the
+// settings of listeners, after regiond.conf.
+func regionConfigNames(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		renamed := make(map[string]any, len(v))
+		for name, item := range v {
+			renamed[regionConfigName(name)] = regionConfigNames(item)
+		}
+
+		return renamed
+	case []any:
+		for i, item := range v {
+			v[i] = regionConfigNames(item)
+		}
+
+		return v
+	default:
+		return v
+	}
+}
+
+// readConfig reads the settings of the first source holding them: the
+// snap configuration when confined in the snap and it has the database
+// settings, regiond.conf otherwise.
+func readConfig(ctx context.Context) ([]byte, configSource, error) {
+	if os.Getenv("SNAP") != "" {
+		snap := newSnapConfig()
+
+		data, err := snap.Read(ctx)
+		if err == nil {
+			return data, snap, nil
+		} else if !errors.Is(err, errNoSnapConfig) {
+			return nil, nil, err
+		}
+	}
+
+	file := fileConfig(regionConfigPath())
+
+	data, err := file.Read(ctx)
+
+	return data, file, err
+}
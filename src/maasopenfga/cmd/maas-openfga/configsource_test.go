@@ -0,0 +1,268 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapConfigRead(t *testing.T) {
+	testcases := map[string]struct {
+		output string
+		err    error
+		want   *regionConfig
+		errIs  error
+		errMsg string
+	}{
+		"database and listeners": {
+			output: `{
+				"database": {"host": "10.0.0.1", "port": 5433, "name": "maasdb", "user": "maas", "pass": "secret"},
+				"openfga": {
+					"max-open-conns": 8,
+					"listeners": [{"network": "tcp", "address": "10.0.0.2:5000", "allowed-networks": ["10.0.0.0/24"]}]
+				}
+			}`,
+			want: &regionConfig{
+				DatabaseHost: "10.0.0.1",
+				DatabasePort: 5433,
+				DatabaseName: "maasdb",
+				DatabaseUser: "maas",
+				DatabasePass: "secret",
+				OpenFGAListeners: []listenerConfig{{
+					Network:         networkTCP,
+					Address:         "10.0.0.2:5000",
+					AllowedNetworks: []string{"10.0.0.0/24"},
+				}},
+				OpenFGAMaxOpenConns: 8,
+			},
+		},
+		"no database settings": {
+			output: `{"openfga": {"max-open-conns": 8}}`,
+			errIs:  errNoSnapConfig,
+		},
+		"nothing set": {
+			output: `{}`,
+			errIs:  errNoSnapConfig,
+		},
+		"snapctl failure": {
+			err:    errors.New("permission denied"),
+			errMsg: "permission denied",
+		},
+		"invalid output": {
+			output: `{"database": "10.0.0.1"}`,
+			errMsg: "failed to parse snap configuration",
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			var gotArgs []string
+
+			snap := snapConfig{run: func(_ context.Context, args ...string) ([]byte, error) {
+				gotArgs = args
+				return []byte(tc.output), tc.err
+			}}
+
+			data, err := snap.Read(context.Background())
+
+			assert.Equal(t, []string{"get", "-d", "database", "openfga", "vault"}, gotArgs)
+
+			switch {
+			case tc.errIs != nil:
+				require.ErrorIs(t, err, tc.errIs)
+				return
+			case tc.errMsg != "":
+				require.ErrorContains(t, err, tc.errMsg)
+				return
+			}
+
+			require.NoError(t, err)
+
+			cfg, err := parseRegionConfig(data)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.want.DatabaseHost, cfg.DatabaseHost)
+			assert.Equal(t, tc.want.DatabasePort, cfg.DatabasePort)
+			assert.Equal(t, tc.want.DatabaseName, cfg.DatabaseName)
+			assert.Equal(t, tc.want.DatabaseUser, cfg.DatabaseUser)
+			assert.Equal(t, tc.want.DatabasePass, cfg.DatabasePass)
+			assert.Equal(t, tc.want.OpenFGAMaxOpenConns, cfg.OpenFGAMaxOpenConns)
+			require.Len(t, cfg.OpenFGAListeners, len(tc.want.OpenFGAListeners))
+
+			for i, want := range tc.want.OpenFGAListeners {
+				got := cfg.OpenFGAListeners[i]
+				assert.Equal(t, want.Network, got.Network)
+				assert.Equal(t, want.Address, got.Address)
+				assert.Equal(t, want.AllowedNetworks, got.AllowedNetworks)
+			}
+		})
+	}
+}
+
+func TestReadConfigDeb(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "regiond.conf"),
+		[]byte("database_host: /var/run/postgresql\n"), 0o600))
+
+	// Deb installs don't set SNAP, SNAP_DATA only relocates regiond.conf.
+	t.Setenv("SNAP", "")
+	t.Setenv("SNAP_DATA", dir)
+
+	data, source, err := readConfig(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, fileConfig(filepath.Join(dir, "regiond.conf")), source)
+	assert.Equal(t, "database_host: /var/run/postgresql\n", string(data))
+}
+
+func TestFileConfigSet(t *testing.T) {
+	testcases := map[string]struct {
+		in   string
+		want string
+	}{
+		"new setting": {
+			in: "# MAAS database\ndatabase_host: /var/run/postgresql\n",
+			want: "# MAAS database\ndatabase_host: /var/run/postgresql\n" +
+				"openfga_datastore_uri: postgres://db2.example.com/openfga\n",
+		},
+		"existing setting": {
+			in: "openfga_datastore_uri: postgres://db1.example.com/openfga # old\nmaas_url: http://localhost:5240/MAAS\n",
+			want: "openfga_datastore_uri: postgres://db2.example.com/openfga\n" +
+				"maas_url: http://localhost:5240/MAAS\n",
+		},
+		"empty file": {
+			want: "openfga_datastore_uri: postgres://db2.example.com/openfga\n",
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "regiond.conf")
+			require.NoError(t, os.WriteFile(path, []byte(tc.in), 0o640))
+
+			var validated string
+
+			err := fileConfig(path).Set(context.Background(), "openfga_datastore_uri",
+				"postgres://db2.example.com/openfga", func(cfg *regionConfig) error {
+					validated = cfg.OpenFGADatastoreURI
+					return nil
+				})
+			require.NoError(t, err)
+			assert.Equal(t, "postgres://db2.example.com/openfga", validated)
+
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, string(data))
+
+			info, err := os.Stat(path)
+			require.NoError(t, err)
+			assert.Equal(t, os.FileMode(0o640), info.Mode().Perm())
+
+			// The temporary file is gone.
+			entries, err := os.ReadDir(filepath.Dir(path))
+			require.NoError(t, err)
+			assert.Len(t, entries, 1)
+		})
+	}
+}
+
+func TestFileConfigSetNotMapping(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "regiond.conf")
+	require.NoError(t, os.WriteFile(path, []byte("- database_host\n"), 0o600))
+
+	err := fileConfig(path).Set(context.Background(), "openfga_datastore_uri", "postgres://db2/openfga",
+		func(*regionConfig) error { return nil })
+	require.ErrorContains(t, err, "expected a mapping of settings")
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "- database_host\n", string(data))
+}
+
+func TestFileConfigSetInvalid(t *testing.T) {
+	const in = "database_host: /var/run/postgresql\n"
+
+	path := filepath.Join(t.TempDir(), "regiond.conf")
+	require.NoError(t, os.WriteFile(path, []byte(in), 0o600))
+
+	err := fileConfig(path).Set(context.Background(), "openfga_datastore_uri", "postgres://db2/openfga",
+		func(*regionConfig) error { return errors.New("connection refused") })
+	require.ErrorContains(t, err, "left unchanged: connection refused")
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, in, string(data))
+
+	// The temporary file is gone.
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestSnapConfigSet(t *testing.T) {
+	testcases := map[string]struct {
+		validateErr error
+		errMsg      string
+	}{
+		"valid": {},
+		"invalid": {
+			validateErr: errors.New("connection refused"),
+			errMsg:      "left unchanged: connection refused",
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			var setArgs []string
+
+			snap := snapConfig{run: func(_ context.Context, args ...string) ([]byte, error) {
+				if args[0] == "get" {
+					return []byte(`{"database": {"host": "10.0.0.1", "name": "maasdb", "user": "maas"}}`), nil
+				}
+
+				setArgs = args
+
+				return nil, nil
+			}}
+
+			var validated *regionConfig
+
+			err := snap.Set(context.Background(), "openfga_datastore_uri", "postgres://db2/openfga",
+				func(cfg *regionConfig) error {
+					validated = cfg
+					return tc.validateErr
+				})
+			if tc.errMsg != "" {
+				require.ErrorContains(t, err, tc.errMsg)
+				assert.Nil(t, setArgs)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, "10.0.0.1", validated.DatabaseHost)
+			assert.Equal(t, "postgres://db2/openfga", validated.OpenFGADatastoreURI)
+			assert.Equal(t, []string{"set", "openfga.datastore-uri=postgres://db2/openfga"}, setArgs)
+		})
+	}
+}
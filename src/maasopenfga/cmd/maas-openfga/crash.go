@@ -0,0 +1,50 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+
+	"maas.io/core/src/maasgocommon/crash"
+)
+
+// crashes records the reports of failures of serve. Logs are teed to it
+// after secrets are masked.
+var crashes = crash.New(crashDir())
+
+// crashDir returns the directory of crash reports, shared with the agent.
+func crashDir() string {
+	base := "/var/lib/maas"
+	if dataDir := os.Getenv("SNAP_COMMON"); dataDir != "" {
+		base = filepath.Join(filepath.Clean(dataDir), base)
+	}
+
+	return filepath.Join(base, "crash", "maas-openfga")
+}
+
+// recordCrash writes a report of the failure of serve.
+func recordCrash(err error) {
+	path, recordErr := crashes.Record(redactor.String(err.Error()))
+	if path != "" {
+		log.Printf("crash report written to %s", path)
+	}
+
+	if recordErr != nil {
+		log.Printf("failed to write crash report: %v", recordErr)
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+
+	"maas.io/core/src/maasopenfga/internal/entitysync"
+	"maas.io/core/src/maasopenfga/internal/migrations"
+)
+
+// startEntitySync keeps the tuples of MAAS users, groups and resource pools
+// in line with the notifications of regiond, until the returned function
+// is called.
+func startEntitySync(ctx context.Context, appDSN string) (func(), error) {
+	db, err := sql.Open("pgx", appDSN)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	// One connection listens, the other applies the changes.
+	db.SetMaxOpenConns(2)
+
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		//nolint:errcheck // Run only returns once ctx is done
+		entitysync.New(db, migrations.StoreID).Run(ctx)
+	}()
+
+	return func() {
+		cancel()
+		<-done
+
+		if err := db.Close(); err != nil {
+			log.Printf("failed to close entity sync database: %v", err)
+		}
+	}, nil
+}
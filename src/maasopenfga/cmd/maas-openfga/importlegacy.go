@@ -0,0 +1,80 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"maas.io/core/src/maasopenfga/internal/legacyimport"
+	"maas.io/core/src/maasopenfga/internal/migrations"
+)
+
+func importLegacyCmd() *cobra.Command {
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "import-legacy",
+		Short: "Import the access rules MAAS applied before OpenFGA.",
+		Long: "Translate the superusers and users of the MAAS database, and its " +
+			"resource pools, into the tuples granting the same access through " +
+			"the Administrators and Users groups, and print what each rule maps " +
+			"to. Tuples already present are left untouched, so the import can " +
+			"be run again.",
+		Example: "maas-openfga import-legacy --dry-run",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withAppDatabase(func(db *sql.DB) error {
+				report, err := legacyimport.Convert(cmd.Context(), legacyimport.NewSource(db))
+				if err != nil {
+					return err
+				}
+
+				if err := report.Write(cmd.OutOrStdout()); err != nil {
+					return err
+				}
+
+				toWrite := report.Tuples()
+
+				if dryRun {
+					fmt.Fprintf(cmd.ErrOrStderr(), "dry run: %d tuples would be imported, %d entries skipped\n",
+						len(toWrite), report.Skipped())
+
+					return nil
+				}
+
+				if err := requirePrimary(cmd.Context(), db, "import tuples"); err != nil {
+					return err
+				}
+
+				written, err := legacyimport.Apply(cmd.Context(), db, migrations.StoreID, toWrite)
+				if err != nil {
+					return fmt.Errorf("failed to import tuples: %w", err)
+				}
+
+				fmt.Fprintf(cmd.ErrOrStderr(), "%d tuples written, %d already present, %d entries skipped\n",
+					written, len(toWrite)-written, report.Skipped())
+
+				return nil
+			})
+		},
+	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the mapping report without writing tuples")
+
+	return cmd
+}
@@ -0,0 +1,94 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"maas.io/core/src/maasopenfga/internal/migrations"
+	"maas.io/core/src/maasopenfga/internal/rbacimport"
+)
+
+func importRBACCmd() *cobra.Command {
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "import-rbac <export-file>",
+		Short: "Import role assignments exported from the external RBAC service.",
+		Long: "Convert the resource pool role assignments exported from the external " +
+			"RBAC service into tuples granted to the MAAS groups of the same name, " +
+			"and print what each assignment maps to. Assignments that can't be " +
+			"imported are reported as skipped.",
+		Example: "maas-openfga import-rbac rbac-export.json --dry-run",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			f, err := os.Open(filepath.Clean(args[0]))
+			if err != nil {
+				return fmt.Errorf("failed to open RBAC export: %w", err)
+			}
+
+			export, err := rbacimport.Parse(f)
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+
+			if err != nil {
+				return err
+			}
+
+			return withAppDatabase(func(db *sql.DB) error {
+				report, err := rbacimport.Convert(cmd.Context(), rbacimport.NewDirectory(db), export)
+				if err != nil {
+					return err
+				}
+
+				if err := report.Write(cmd.OutOrStdout()); err != nil {
+					return err
+				}
+
+				toWrite := report.Tuples()
+
+				if dryRun {
+					fmt.Fprintf(cmd.ErrOrStderr(), "dry run: %d tuples would be written, %d entries skipped\n",
+						len(toWrite), report.Skipped())
+
+					return nil
+				}
+
+				if err := requirePrimary(cmd.Context(), db, "import tuples"); err != nil {
+					return err
+				}
+
+				if err := rbacimport.Apply(cmd.Context(), db, migrations.StoreID, toWrite); err != nil {
+					return fmt.Errorf("failed to import tuples: %w", err)
+				}
+
+				fmt.Fprintf(cmd.ErrOrStderr(), "%d tuples written, %d entries skipped\n",
+					len(toWrite), report.Skipped())
+
+				return nil
+			})
+		},
+	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the mapping report without writing tuples")
+
+	return cmd
+}
@@ -0,0 +1,259 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/netip"
+	"os"
+	"os/user"
+	"strconv"
+	"sync"
+	"syscall"
+
+	"maas.io/core/src/maasopenfga/pkg/openfgaclient"
+)
+
+const (
+	networkUnix = "unix"
+	networkTCP  = "tcp"
+)
+
+// socketUmask creates unix sockets that only their owner can connect to,
+// until mode and group are applied.
+const socketUmask = 0o177
+
+// loopbackNetworks are the networks tcp clients may connect from when
+// allowed_networks isn't set: the API isn't authenticated.
+var loopbackNetworks = []netip.Prefix{
+	netip.MustParsePrefix("127.0.0.0/8"),
+	netip.MustParsePrefix("::1/128"),
+}
+
+// umaskMu serializes the changes of the umask, which is process-wide.
+var umaskMu sync.Mutex
+
+// listenerConfig describes a single address maas-openfga accepts connections
+// on. All listeners serve the same OpenFGA server instance.
+type listenerConfig struct {
+	// Network is either "unix" or "tcp".
+	Network string `yaml:"network" doc:"Listener type." schema:"required,enum=unix|tcp"`
+	// Address is a socket path for unix listeners or host:port for tcp.
+	Address string `yaml:"address" doc:"Socket path for unix listeners or host:port for tcp." schema:"required"`
+	// Mode is the octal file mode applied to unix sockets (e.g. "0660").
+	Mode string `yaml:"mode" doc:"Octal file mode of unix sockets." schema:"pattern=^[0-7]?[0-7]{3}$"`
+	// Group is the group owning unix sockets.
+	Group string `yaml:"group" doc:"Group owning unix sockets."`
+	// AllowedNetworks restricts tcp clients to the given CIDRs.
+	// When empty, only loopback connections are accepted.
+	AllowedNetworks []string `yaml:"allowed_networks" doc:"CIDRs tcp clients must connect from (loopback only when empty)."`
+	// ReadOnly rejects the requests writing stores, models or tuples.
+	ReadOnly bool `yaml:"read_only" doc:"Reject the requests writing stores, models or tuples."`
+
+	allowed []netip.Prefix
+}
+
+// defaultListenerConfig returns the unix socket regiond expects, which can be
+// overridden with MAAS_OPENFGA_HTTP_SOCKET_PATH.
+func defaultListenerConfig() listenerConfig {
+	return listenerConfig{Network: networkUnix, Address: defaultSocketPath()}
+}
+
+// defaultSocketPath is the unix socket regiond uses to reach maas-openfga.
+func defaultSocketPath() string {
+	return openfgaclient.DefaultSocketPath()
+}
+
+func (c *listenerConfig) validate() error {
+	if c.Address == "" {
+		return errors.New("listener address must be specified")
+	}
+
+	switch c.Network {
+	case networkUnix:
+		if len(c.AllowedNetworks) > 0 {
+			return fmt.Errorf("listener %s: allowed_networks is only supported for tcp", c)
+		}
+
+		if c.Mode != "" {
+			if _, err := strconv.ParseUint(c.Mode, 8, 32); err != nil {
+				return fmt.Errorf("listener %s: invalid mode %q", c, c.Mode)
+			}
+		}
+	case networkTCP:
+		if c.Mode != "" || c.Group != "" {
+			return fmt.Errorf("listener %s: mode and group are only supported for unix", c)
+		}
+
+		if _, _, err := net.SplitHostPort(c.Address); err != nil {
+			return fmt.Errorf("listener %s: %w", c, err)
+		}
+
+		c.allowed = make([]netip.Prefix, 0, len(c.AllowedNetworks))
+
+		for _, network := range c.AllowedNetworks {
+			prefix, err := netip.ParsePrefix(network)
+			if err != nil {
+				return fmt.Errorf("listener %s: invalid allowed network: %w", c, err)
+			}
+
+			c.allowed = append(c.allowed, prefix.Masked())
+		}
+
+		if len(c.allowed) == 0 {
+			c.allowed = loopbackNetworks
+		}
+	default:
+		return fmt.Errorf("listener %s: unsupported network %q", c, c.Network)
+	}
+
+	return nil
+}
+
+func (c *listenerConfig) String() string {
+	return c.Network + "://" + c.Address
+}
+
+// listen opens the configured listener and applies its access-control settings.
+func (c *listenerConfig) listen() (net.Listener, error) {
+	if c.Network == networkTCP {
+		lis, err := net.Listen(networkTCP, c.Address)
+		if err != nil {
+			return nil, err
+		}
+
+		return &filteredListener{Listener: lis, allowed: c.allowed}, nil
+	}
+
+	err := os.Remove(c.Address)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to remove existing socket file: %w", err)
+	}
+
+	lis, err := listenUnix(c.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.applyPermissions(); err != nil {
+		if errr := lis.Close(); errr != nil {
+			log.Printf("failed to close listener %s: %v", c, errr)
+		}
+
+		return nil, err
+	}
+
+	return lis, nil
+}
+
+// listenUnix creates the socket at address under socketUmask, so that no
+// other user can connect before its permissions are applied.
+func listenUnix(address string) (net.Listener, error) {
+	umaskMu.Lock()
+	defer umaskMu.Unlock()
+
+	umask := syscall.Umask(socketUmask)
+	defer syscall.Umask(umask)
+
+	return net.Listen(networkUnix, address)
+}
+
+func (c *listenerConfig) applyPermissions() error {
+	if c.Group != "" {
+		grp, err := user.LookupGroup(c.Group)
+		if err != nil {
+			return fmt.Errorf("failed to lookup group %q: %w", c.Group, err)
+		}
+
+		gid, err := strconv.Atoi(grp.Gid)
+		if err != nil {
+			return fmt.Errorf("invalid gid for group %q: %w", c.Group, err)
+		}
+
+		if err := os.Chown(c.Address, -1, gid); err != nil {
+			return fmt.Errorf("failed to change socket group: %w", err)
+		}
+	}
+
+	if c.Mode != "" {
+		// Already validated
+		mode, _ := strconv.ParseUint(c.Mode, 8, 32) //nolint:errcheck // validated
+
+		if err := os.Chmod(c.Address, os.FileMode(mode)); err != nil {
+			return fmt.Errorf("failed to change socket mode: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// cleanup removes the socket file of unix listeners.
+func (c *listenerConfig) cleanup() {
+	if c.Network != networkUnix {
+		return
+	}
+
+	err := os.Remove(c.Address)
+	if err != nil && !os.IsNotExist(err) {
+		log.Printf("failed to remove socket file: %v", err)
+	}
+}
+
+// filteredListener drops connections coming from addresses outside of the
+// allowed networks.
+type filteredListener struct {
+	net.Listener
+	allowed []netip.Prefix
+}
+
+func (l *filteredListener) Accept() (net.Conn, error) {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+
+		if l.isAllowed(conn.RemoteAddr()) {
+			return conn, nil
+		}
+
+		log.Printf("rejected connection from %s", conn.RemoteAddr())
+
+		if err := conn.Close(); err != nil {
+			log.Printf("failed to close rejected connection: %v", err)
+		}
+	}
+}
+
+func (l *filteredListener) isAllowed(addr net.Addr) bool {
+	tcpAddr, ok := addr.(*net.TCPAddr)
+	if !ok {
+		return false
+	}
+
+	ip := tcpAddr.AddrPort().Addr().Unmap()
+
+	for _, prefix := range l.allowed {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
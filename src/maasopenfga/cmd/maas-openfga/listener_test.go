@@ -0,0 +1,141 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"net"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListenerConfigValidate(t *testing.T) {
+	testcases := map[string]struct {
+		in  listenerConfig
+		err bool
+	}{
+		"unix": {
+			in: listenerConfig{Network: "unix", Address: "/run/openfga.sock", Mode: "0660"},
+		},
+		"tcp": {
+			in: listenerConfig{Network: "tcp", Address: "10.0.0.1:5280",
+				AllowedNetworks: []string{"10.0.0.0/24", "fd00::/64"}},
+		},
+		"missing address": {
+			in:  listenerConfig{Network: "unix"},
+			err: true,
+		},
+		"unsupported network": {
+			in:  listenerConfig{Network: "udp", Address: "10.0.0.1:5280"},
+			err: true,
+		},
+		"invalid mode": {
+			in:  listenerConfig{Network: "unix", Address: "/run/openfga.sock", Mode: "rw"},
+			err: true,
+		},
+		"tcp with mode": {
+			in:  listenerConfig{Network: "tcp", Address: "10.0.0.1:5280", Mode: "0660"},
+			err: true,
+		},
+		"unix with allowed networks": {
+			in: listenerConfig{Network: "unix", Address: "/run/openfga.sock",
+				AllowedNetworks: []string{"10.0.0.0/24"}},
+			err: true,
+		},
+		"tcp without port": {
+			in:  listenerConfig{Network: "tcp", Address: "10.0.0.1"},
+			err: true,
+		},
+		"invalid allowed network": {
+			in: listenerConfig{Network: "tcp", Address: "10.0.0.1:5280",
+				AllowedNetworks: []string{"10.0.0.0"}},
+			err: true,
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			err := tc.in.validate()
+			if tc.err {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestFilteredListenerIsAllowed(t *testing.T) {
+	l := &filteredListener{allowed: []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/24"),
+	}}
+
+	assert.True(t, l.isAllowed(&net.TCPAddr{IP: net.ParseIP("10.0.0.42")}))
+	assert.True(t, l.isAllowed(&net.TCPAddr{IP: net.ParseIP("::ffff:10.0.0.42")}))
+	assert.False(t, l.isAllowed(&net.TCPAddr{IP: net.ParseIP("10.0.1.1")}))
+	assert.False(t, l.isAllowed(&net.UnixAddr{Name: "/run/openfga.sock"}))
+}
+
+func TestDefaultListenerConfig(t *testing.T) {
+	t.Setenv("MAAS_OPENFGA_HTTP_SOCKET_PATH", "/tmp/openfga.sock")
+	assert.Equal(t, listenerConfig{Network: "unix", Address: "/tmp/openfga.sock"},
+		defaultListenerConfig())
+}
+
+func TestListenerConfigValidateLoopbackByDefault(t *testing.T) {
+	c := listenerConfig{Network: "tcp", Address: "0.0.0.0:5280"}
+	require.NoError(t, c.validate())
+
+	l := &filteredListener{allowed: c.allowed}
+	assert.True(t, l.isAllowed(&net.TCPAddr{IP: net.ParseIP("127.0.0.1")}))
+	assert.True(t, l.isAllowed(&net.TCPAddr{IP: net.ParseIP("::1")}))
+	assert.False(t, l.isAllowed(&net.TCPAddr{IP: net.ParseIP("10.0.0.42")}))
+}
+
+func TestListenerConfigListenUnixMode(t *testing.T) {
+	testcases := map[string]struct {
+		mode string
+		want os.FileMode
+	}{
+		"default": {
+			want: 0o600,
+		},
+		"mode": {
+			mode: "0660",
+			want: 0o660,
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			c := listenerConfig{Network: "unix", Address: filepath.Join(t.TempDir(), "openfga.sock"), Mode: tc.mode}
+			require.NoError(t, c.validate())
+
+			lis, err := c.listen()
+			require.NoError(t, err)
+
+			defer lis.Close()
+
+			info, err := os.Stat(c.Address)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, info.Mode().Perm())
+		})
+	}
+}
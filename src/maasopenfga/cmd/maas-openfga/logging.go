@@ -0,0 +1,146 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"os"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/openfga/openfga/pkg/logger"
+	"go.uber.org/zap"
+	"maas.io/core/src/maasgocommon/redact"
+)
+
+// redactScheme is the scheme of zap outputs writing to stdout or stderr
+// with secrets masked, e.g. "redact:stdout".
+const redactScheme = "redact"
+
+var (
+	redactor = redact.New()
+
+	registerSinkOnce sync.Once
+	errRegisterSink  error
+)
+
+// redactSink is a zap sink writing to a standard stream through the
+// redactor.
+type redactSink struct {
+	io.Writer
+	f *os.File
+}
+
+func (s redactSink) Sync() error {
+	return s.f.Sync()
+}
+
+// Close doesn't close the standard stream, other loggers may still use it.
+func (redactSink) Close() error {
+	return nil
+}
+
+func newRedactSink(u *url.URL) (zap.Sink, error) {
+	switch u.Opaque {
+	case "stdout":
+		return redactSink{Writer: redactor.Writer(crashes.Writer(os.Stdout)), f: os.Stdout}, nil
+	case "stderr":
+		return redactSink{Writer: redactor.Writer(crashes.Writer(os.Stderr)), f: os.Stderr}, nil
+	default:
+		return nil, fmt.Errorf("unsupported %s output %q", redactScheme, u.Opaque)
+	}
+}
+
+// newLogger returns an OpenFGA logger writing to stdout with secrets
+// masked.
+func newLogger(options ...logger.OptionLogger) (*logger.ZapLogger, error) {
+	registerSinkOnce.Do(func() {
+		errRegisterSink = zap.RegisterSink(redactScheme, newRedactSink)
+	})
+
+	if errRegisterSink != nil {
+		return nil, errRegisterSink
+	}
+
+	return logger.NewLogger(append(options, logger.WithOutputPaths(redactScheme+":stdout"))...)
+}
+
+// logStartup logs the effective configuration, with secrets masked, and the
+// environment in a single record, to ease diagnosing misconfigurations. dsn
+// is the datastore queried for the PostgreSQL version.
+func logStartup(ctx context.Context, l logger.Logger, dsn string, cfg *regionConfig, migrate bool) {
+	fields := []zap.Field{
+		zap.Stringer("config_source", cfg.source),
+		zap.Bool("migrate", migrate),
+		zap.String("install", installType()),
+		zap.String("snap_revision", os.Getenv("SNAP_REVISION")),
+		zap.String("go_version", runtime.Version()),
+	}
+
+	if config, err := redactor.Map(cfg); err != nil {
+		fields = append(fields, zap.NamedError("config_error", err))
+	} else {
+		fields = append(fields, zap.Any("config", config))
+	}
+
+	// Mirrors don't use the database.
+	if dsn != "" {
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+
+		if version, err := postgresVersion(ctx, dsn); err != nil {
+			fields = append(fields, zap.NamedError("postgres_error", err))
+		} else {
+			fields = append(fields, zap.String("postgres_version", version))
+		}
+	}
+
+	l.Info("maas-openfga starting", fields...)
+}
+
+func postgresVersion(ctx context.Context, dsn string) (string, error) {
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		return "", err
+	}
+
+	defer func() {
+		if err := db.Close(); err != nil {
+			log.Printf("failed to close database: %v", err)
+		}
+	}()
+
+	var version string
+
+	err = db.QueryRowContext(ctx, "SHOW server_version").Scan(&version)
+
+	return version, err
+}
+
+// installType returns how MAAS is installed, "snap" or "deb".
+func installType() string {
+	if _, ok := os.LookupEnv("SNAP"); ok {
+		return "snap"
+	}
+
+	return "deb"
+}
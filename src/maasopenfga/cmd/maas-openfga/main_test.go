@@ -0,0 +1,74 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRootCmdServes(t *testing.T) {
+	testcases := map[string]struct {
+		args    []string
+		migrate bool
+	}{
+		"bare": {},
+		"bare with migrate": {
+			args:    []string{"--migrate"},
+			migrate: true,
+		},
+		"serve": {
+			args: []string{"serve"},
+		},
+		"serve with migrate": {
+			args:    []string{"serve", "--migrate"},
+			migrate: true,
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			var calls []bool
+
+			serveFunc = func(_ context.Context, migrate bool) error {
+				calls = append(calls, migrate)
+				return nil
+			}
+
+			t.Cleanup(func() { serveFunc = runServe })
+
+			out, err := execute(t, tc.args...)
+			require.NoError(t, err)
+			assert.Empty(t, out, "no help is printed")
+			assert.Equal(t, []bool{tc.migrate}, calls)
+		})
+	}
+}
+
+func TestRootCmdUnknownCommand(t *testing.T) {
+	serveFunc = func(context.Context, bool) error {
+		t.Fatal("unknown commands must not serve")
+		return nil
+	}
+
+	t.Cleanup(func() { serveFunc = runServe })
+
+	_, err := execute(t, "srve")
+	assert.ErrorContains(t, err, `unknown command "srve"`)
+}
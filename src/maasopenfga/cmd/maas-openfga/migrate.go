@@ -0,0 +1,404 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/openfga/openfga/pkg/logger"
+	"github.com/spf13/cobra"
+	"maas.io/core/src/maasopenfga/internal/migrator"
+	"maas.io/core/src/maasopenfga/internal/seed"
+)
+
+func migrateCmd() *cobra.Command {
+	var seedFiles []string
+
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Apply datastore and authorization model migrations.",
+		Long: "Apply OpenFGA datastore migrations followed by MAAS authorization " +
+			"model migrations, using the database configured in regiond.conf.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			regionCfg, err := readRegionConfig()
+			if err != nil {
+				return err
+			}
+
+			dsn, err := getPostgresDSN(regionCfg)
+			if err != nil {
+				return fmt.Errorf("invalid database configuration: %w", err)
+			}
+
+			appDSN, err := getAppPostgresDSN(regionCfg)
+			if err != nil {
+				return fmt.Errorf("invalid database configuration: %w", err)
+			}
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), migrationTimeout)
+			defer cancel()
+
+			if err := migrator.Up(ctx, dsn, appDSN, migrationLogger()); err != nil {
+				return err
+			}
+
+			return runSeed(ctx, cmd.OutOrStdout(), appDSN, seedFiles, false)
+		},
+	}
+
+	cmd.Flags().StringArrayVar(&seedFiles, "seed", nil, "Seed tuples from this YAML file after migrating (repeatable)")
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "datastore <datastore-uri>",
+		Short: "Apply OpenFGA datastore migrations.",
+		Long: "Apply OpenFGA datastore migrations. The datastore URI must set " +
+			"search_path to the openfga schema.",
+		Args: cobra.ExactArgs(1),
+		// Tested in the integration tests of the dbupgrade django command.
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return migrator.Datastore(cmd.Context(), args[0], migrationLogger())
+		},
+	})
+
+	appCmd := &cobra.Command{
+		Use:   "app <datastore-uri>",
+		Short: "Apply MAAS authorization model migrations.",
+		// Note that these migrations manage the openfga schema manually because they need to access
+		// also the MAAS tables in the default schema. Do not pass the search_path in the datastore-uri
+		// like we do for the datastore migrations.
+		Long: "Apply MAAS migrations (store, authorization model and tuples). " +
+			"The datastore URI must not set search_path.",
+		Args: cobra.ExactArgs(1),
+		// Tested in the integration tests of the dbupgrade django command.
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := migrator.App(cmd.Context(), args[0]); err != nil {
+				return err
+			}
+
+			return runSeed(cmd.Context(), cmd.OutOrStdout(), args[0], seedFiles, false)
+		},
+	}
+
+	appCmd.Flags().StringArrayVar(&seedFiles, "seed", nil, "Seed tuples from this YAML file after migrating (repeatable)")
+	cmd.AddCommand(appCmd)
+
+	cmd.AddCommand(migrateStatusCmd())
+	cmd.AddCommand(migrateVersionCmd())
+	cmd.AddCommand(migrateUpToCmd())
+	cmd.AddCommand(migrateDownCmd())
+	cmd.AddCommand(migrateRedoCmd())
+	cmd.AddCommand(migrateSQLCmd())
+	cmd.AddCommand(migrateSeedCmd())
+
+	return cmd
+}
+
+func migrateStatusCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "Show which MAAS authorization model migrations are applied.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			appDSN, err := regionAppDSN()
+			if err != nil {
+				return err
+			}
+
+			status, err := migrator.Status(cmd.Context(), appDSN)
+			if err != nil {
+				return err
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "VERSION\tSTATE\tAPPLIED AT")
+
+			for _, s := range status {
+				appliedAt := ""
+				if !s.AppliedAt.IsZero() {
+					appliedAt = s.AppliedAt.Format(time.RFC3339)
+				}
+
+				fmt.Fprintf(w, "%d\t%s\t%s\n", s.Source.Version, s.State, appliedAt)
+			}
+
+			return w.Flush()
+		},
+	}
+}
+
+func migrateVersionCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "version",
+		Short: "Print the version of the last applied MAAS migration.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			appDSN, err := regionAppDSN()
+			if err != nil {
+				return err
+			}
+
+			current, latest, err := migrator.Version(cmd.Context(), appDSN)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "%d (latest: %d)\n", current, latest)
+
+			return nil
+		},
+	}
+}
+
+func migrateUpToCmd() *cobra.Command {
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "up-to <version>",
+		Short: "Apply MAAS authorization model migrations up to a version.",
+		Long: "Apply the MAAS migrations up to and including the given version, " +
+			"using the database configured in regiond.conf. OpenFGA datastore " +
+			"migrations must have been applied already.",
+		Example: "maas-openfga migrate up-to 4 --dry-run",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			version, err := strconv.ParseInt(args[0], 10, 64)
+			if err != nil || version <= 0 {
+				return fmt.Errorf("invalid version %q", args[0])
+			}
+
+			appDSN, err := regionAppDSN()
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), migrationTimeout)
+			defer cancel()
+
+			if dryRun {
+				return migrator.SQL(ctx, appDSN, version, cmd.OutOrStdout())
+			}
+
+			return migrator.UpTo(ctx, appDSN, version)
+		},
+	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false,
+		"Print the SQL the migrations would run without applying them")
+
+	return cmd
+}
+
+func migrateDownCmd() *cobra.Command {
+	var (
+		version   int64
+		allowDown bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "down",
+		Short: "Roll back MAAS authorization model migrations.",
+		Long: "Roll back the last MAAS migration, or those applied after --to, " +
+			"using the database configured in regiond.conf. This deletes the " +
+			"authorization models and tuples the migrations installed, and is " +
+			"meant for development environments only.",
+		Example: "maas-openfga migrate down --to 3 --allow-down",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !allowDown {
+				return fmt.Errorf("%w, pass --allow-down to confirm", migrator.ErrDownNotAllowed)
+			}
+
+			appDSN, err := regionAppDSN()
+			if err != nil {
+				return err
+			}
+
+			if !cmd.Flags().Changed("to") {
+				version = -1
+			}
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), migrationTimeout)
+			defer cancel()
+
+			return migrator.Down(ctx, appDSN, version, allowDown)
+		},
+	}
+
+	cmd.Flags().Int64Var(&version, "to", 0,
+		"Roll back the migrations applied after this version (0 rolls back all of them)")
+	cmd.Flags().BoolVar(&allowDown, "allow-down", false,
+		"Confirm that rolling back may delete authorization data")
+
+	return cmd
+}
+
+func migrateRedoCmd() *cobra.Command {
+	var allowDown bool
+
+	cmd := &cobra.Command{
+		Use:   "redo",
+		Short: "Roll back the last MAAS migration and apply it again.",
+		Long: "Roll back the last MAAS migration and apply it again, using the " +
+			"database configured in regiond.conf. Rolling back deletes the " +
+			"authorization data the migration installed, and is meant for " +
+			"development environments only.",
+		Example: "maas-openfga migrate redo --allow-down",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !allowDown {
+				return fmt.Errorf("%w, pass --allow-down to confirm", migrator.ErrDownNotAllowed)
+			}
+
+			appDSN, err := regionAppDSN()
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), migrationTimeout)
+			defer cancel()
+
+			return migrator.Redo(ctx, appDSN, allowDown)
+		},
+	}
+
+	cmd.Flags().BoolVar(&allowDown, "allow-down", false,
+		"Confirm that rolling back may delete authorization data")
+
+	return cmd
+}
+
+func migrateSQLCmd() *cobra.Command {
+	var version int64
+
+	cmd := &cobra.Command{
+		Use:   "sql",
+		Short: "Print the SQL the pending MAAS migrations would run.",
+		Long: "Print the SQL the pending MAAS migrations would run against the " +
+			"database configured in regiond.conf, for review before an upgrade. " +
+			"The migrations run in a transaction that is rolled back. Generated " +
+			"values, such as tuple ULIDs, differ from those of the actual upgrade.",
+		Example: "maas-openfga migrate sql --to 5 > upgrade.sql",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			appDSN, err := regionAppDSN()
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), migrationTimeout)
+			defer cancel()
+
+			return migrator.SQL(ctx, appDSN, version, cmd.OutOrStdout())
+		},
+	}
+
+	cmd.Flags().Int64Var(&version, "to", 0,
+		"Stop after this version (0 includes all pending migrations)")
+
+	return cmd
+}
+
+func migrateSeedCmd() *cobra.Command {
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "seed <seed-file>...",
+		Short: "Seed tuples from YAML seed files.",
+		Long: "Write the tuples of the seed files that are not in the MAAS store yet, " +
+			"in a single transaction, using the database configured in regiond.conf. " +
+			"Object IDs starting with @ refer to MAAS objects by name, e.g. " +
+			"group:@Administrators#member or pool:@default.",
+		Example: "maas-openfga migrate seed seeds.yaml --dry-run",
+		Args:    cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			appDSN, err := regionAppDSN()
+			if err != nil {
+				return err
+			}
+
+			return runSeed(cmd.Context(), cmd.OutOrStdout(), appDSN, args, dryRun)
+		},
+	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the tuples that would be written without writing them")
+
+	return cmd
+}
+
+// runSeed seeds the MAAS store with the tuples of the given files, if any.
+func runSeed(ctx context.Context, out io.Writer, uri string, paths []string, dryRun bool) error {
+	if len(paths) == 0 {
+		return nil
+	}
+
+	files := make([]*seed.File, 0, len(paths))
+
+	for _, path := range paths {
+		data, err := os.ReadFile(filepath.Clean(path))
+		if err != nil {
+			return fmt.Errorf("failed to read seed file: %w", err)
+		}
+
+		file, err := seed.Parse(data)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+
+		files = append(files, file)
+	}
+
+	result, err := migrator.Seed(ctx, uri, files, dryRun)
+	if err != nil {
+		return err
+	}
+
+	result.Write(out, dryRun)
+
+	return nil
+}
+
+// regionAppDSN returns the connection string used by MAAS migrations for
+// the database configured in regiond.conf.
+func regionAppDSN() (string, error) {
+	regionCfg, err := readRegionConfig()
+	if err != nil {
+		return "", err
+	}
+
+	appDSN, err := getAppPostgresDSN(regionCfg)
+	if err != nil {
+		return "", fmt.Errorf("invalid database configuration: %w", err)
+	}
+
+	return appDSN, nil
+}
+
+func migrationLogger() logger.Logger {
+	l, err := newLogger(logger.WithFormat("text"), logger.WithTimestampFormat("Unix"))
+	if err != nil {
+		panic(err)
+	}
+
+	return l
+}
@@ -0,0 +1,153 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+
+	parser "github.com/openfga/language/pkg/go/transformer"
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/encoding/protojson"
+	"maas.io/core/src/maasopenfga/internal/migrator"
+	authzmodel "maas.io/core/src/maasopenfga/internal/model"
+)
+
+func modelCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "model",
+		Short: "Inspect the authorization model.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(modelShowCmd())
+	cmd.AddCommand(modelExportCmd())
+
+	return cmd
+}
+
+func modelShowCmd() *cobra.Command {
+	var (
+		socketPath string
+		store      string
+		asJSON     bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "show",
+		Short: "Show the latest authorization model.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			model, err := newAPIClient(socketPath, store).latestModel(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			if asJSON {
+				data, err := protojson.MarshalOptions{Multiline: true}.Marshal(model)
+				if err != nil {
+					return err
+				}
+
+				fmt.Fprintln(cmd.OutOrStdout(), string(data))
+
+				return nil
+			}
+
+			dsl, err := parser.TransformJSONProtoToDSL(model)
+			if err != nil {
+				return fmt.Errorf("failed to render authorization model: %w", err)
+			}
+
+			fmt.Fprint(cmd.OutOrStdout(), dsl)
+
+			return nil
+		},
+	}
+
+	addSocketFlag(cmd, &socketPath)
+	addStoreFlag(cmd, &store)
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Print the model as JSON instead of DSL")
+
+	return cmd
+}
+
+func modelExportCmd() *cobra.Command {
+	var (
+		modelID string
+		diff    bool
+		version string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Print the authorization model stored in the database.",
+		Long: `Print the authorization model stored in the database as DSL.
+
+Unlike show, export reads the model straight from the database, so it works
+while maas-openfga is not running. With --diff, it prints the differences
+from the embedded source of a model version instead, and fails if there are
+any.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			appDSN, err := regionAppDSN()
+			if err != nil {
+				return err
+			}
+
+			model, err := migrator.Model(cmd.Context(), appDSN, modelID)
+			if err != nil {
+				return err
+			}
+
+			if !diff {
+				dsl, err := parser.TransformJSONProtoToDSL(model)
+				if err != nil {
+					return fmt.Errorf("failed to render authorization model: %w", err)
+				}
+
+				fmt.Fprint(cmd.OutOrStdout(), dsl)
+
+				return nil
+			}
+
+			if version == "" {
+				versions := authzmodel.Versions()
+				version = versions[len(versions)-1]
+			}
+
+			out, err := authzmodel.Diff(version, model)
+			if err != nil {
+				return err
+			}
+
+			if out != "" {
+				fmt.Fprint(cmd.OutOrStdout(), out)
+				return fmt.Errorf("authorization model %s differs from model version %s",
+					model.GetId(), version)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&modelID, "model-id", "", "ID of the model to export (default: the latest one)")
+	cmd.Flags().BoolVar(&diff, "diff", false, "Compare the model with the embedded source, exit non-zero on drift")
+	cmd.Flags().StringVar(&version, "version", "", "Model version to compare with (default: the latest one)")
+
+	return cmd
+}
@@ -0,0 +1,154 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/spf13/cobra"
+	"maas.io/core/src/maasopenfga/internal/migrations"
+	"maas.io/core/src/maasopenfga/internal/reconcile"
+	"maas.io/core/src/maasopenfga/internal/replication"
+)
+
+func reconcileCmd() *cobra.Command {
+	var fix bool
+
+	cmd := &cobra.Command{
+		Use:   "reconcile",
+		Short: "Find tuples referencing deleted MAAS entities.",
+		Long: "Compare the users and objects of the tuples of the MAAS store with " +
+			"the users, groups, resource pools and other entities of the MAAS " +
+			"database, and print every tuple referencing an entity that no longer " +
+			"exists. With --fix, these tuples are removed as well. The command fails " +
+			"if orphans are found without --fix.",
+		Example: "maas-openfga reconcile --fix",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withAppDatabase(func(db *sql.DB) error {
+				if fix {
+					if err := requirePrimary(cmd.Context(), db, "remove tuples"); err != nil {
+						return err
+					}
+				}
+
+				report, err := reconcile.Run(cmd.Context(), db, migrations.StoreID, fix)
+				if err != nil {
+					return err
+				}
+
+				if len(report.Orphans) > 0 {
+					if err := report.Write(cmd.OutOrStdout()); err != nil {
+						return err
+					}
+				}
+
+				if fix {
+					fmt.Fprintf(cmd.ErrOrStderr(), "%d tuples checked, %d orphans removed\n",
+						report.Tuples, report.Removed)
+
+					return nil
+				}
+
+				fmt.Fprintf(cmd.ErrOrStderr(), "%d tuples checked, %d orphans found\n",
+					report.Tuples, len(report.Orphans))
+
+				if len(report.Orphans) > 0 {
+					return fmt.Errorf("found %d orphan tuples, run with --fix to remove them",
+						len(report.Orphans))
+				}
+
+				return nil
+			})
+		},
+	}
+
+	cmd.Flags().BoolVar(&fix, "fix", false, "Remove the tuples referencing deleted entities")
+
+	return cmd
+}
+
+// startReconciliation removes orphan tuples every interval, until the
+// returned function is called. Every removed tuple is logged. Nothing is
+// removed while the store is a standby, as it mirrors its primary.
+func startReconciliation(ctx context.Context, appDSN string, interval time.Duration) (func(), error) {
+	db, err := sql.Open("pgx", appDSN)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	db.SetMaxOpenConns(1)
+
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			if err := reconcileOnce(ctx, db); err != nil && ctx.Err() == nil {
+				log.Printf("reconciliation failed: %v", err)
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-done
+
+		if err := db.Close(); err != nil {
+			log.Printf("failed to close reconciliation database: %v", err)
+		}
+	}, nil
+}
+
+func reconcileOnce(ctx context.Context, db *sql.DB) error {
+	state, err := replication.LoadState(ctx, db, migrations.StoreID)
+
+	switch {
+	case errors.Is(err, replication.ErrNotConfigured):
+	case err != nil:
+		return err
+	case state.Role == replication.RoleStandby:
+		return nil
+	}
+
+	report, err := reconcile.Run(ctx, db, migrations.StoreID, true)
+	if err != nil {
+		return err
+	}
+
+	for _, o := range report.Orphans {
+		log.Printf("reconciliation: removed %s %s %s, %s no longer exists",
+			o.Tuple.GetUser(), o.Tuple.GetRelation(), o.Tuple.GetObject(), o.Missing)
+	}
+
+	return nil
+}
@@ -0,0 +1,172 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/spf13/cobra"
+	"maas.io/core/src/maasopenfga/internal/migrations"
+	"maas.io/core/src/maasopenfga/internal/replication"
+)
+
+func replicationCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "replication",
+		Short: "Inspect or promote a standby replicating tuples from a primary.",
+		Long: "Region clusters setting openfga_replication_primary in regiond.conf " +
+			"replicate the MAAS store of the primary region cluster.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "status",
+		Short: "Show the replication state of the local store.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withDatastore(func(db *sql.DB) error {
+				state, err := replication.LoadState(cmd.Context(), db, migrations.StoreID)
+				if err != nil {
+					return err
+				}
+
+				out := cmd.OutOrStdout()
+
+				fmt.Fprintf(out, "Role:        %s\n", state.Role)
+				fmt.Fprintf(out, "Primary:     %s\n", state.PrimaryURL)
+
+				if !state.LastChangeAt.IsZero() {
+					fmt.Fprintf(out, "Last change: %s (%s ago)\n",
+						state.LastChangeAt.Format(time.RFC3339),
+						time.Since(state.LastChangeAt).Truncate(time.Second))
+				}
+
+				fmt.Fprintf(out, "Updated:     %s\n", state.UpdatedAt.Format(time.RFC3339))
+
+				return nil
+			})
+		},
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "promote",
+		Short: "Promote the local standby store to primary.",
+		Long: "Promote the local standby store to primary. Replication stops " +
+			"before applying any further change from the former primary; remove " +
+			"openfga_replication_primary from regiond.conf afterwards.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withDatastore(func(db *sql.DB) error {
+				if err := replication.Promote(cmd.Context(), db, migrations.StoreID); err != nil {
+					return err
+				}
+
+				fmt.Fprintln(cmd.OutOrStdout(), "store promoted to primary")
+
+				return nil
+			})
+		},
+	})
+
+	return cmd
+}
+
+// requirePrimary fails on standby stores, which only mirror their primary:
+// local changes would be undone by replication.
+func requirePrimary(ctx context.Context, db *sql.DB, action string) error {
+	state, err := replication.LoadState(ctx, db, migrations.StoreID)
+
+	switch {
+	case errors.Is(err, replication.ErrNotConfigured):
+		return nil
+	case err != nil:
+		return err
+	case state.Role == replication.RoleStandby:
+		return fmt.Errorf("can't %s on a standby store, use the primary instead", action)
+	}
+
+	return nil
+}
+
+// withDatastore calls fn with the datastore configured in regiond.conf.
+func withDatastore(fn func(db *sql.DB) error) error {
+	return withDatabase(getPostgresDSN, fn)
+}
+
+// withAppDatabase calls fn with the database configured in regiond.conf,
+// giving access to the MAAS tables as well as the openfga schema.
+func withAppDatabase(fn func(db *sql.DB) error) error {
+	return withDatabase(getAppPostgresDSN, fn)
+}
+
+func withDatabase(getDSN func(*regionConfig) (string, error), fn func(db *sql.DB) error) error {
+	regionCfg, err := readRegionConfig()
+	if err != nil {
+		return err
+	}
+
+	dsn, err := getDSN(regionCfg)
+	if err != nil {
+		return fmt.Errorf("invalid database configuration: %w", err)
+	}
+
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+
+	fnErr := fn(db)
+
+	return errors.Join(fnErr, db.Close())
+}
+
+// startReplication replicates the primary store in the background. The
+// returned function stops replication and waits for it to end.
+func startReplication(ctx context.Context, dsn, primaryURL string) (func(), error) {
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		err := replication.New(db, primaryURL, migrations.StoreID).Run(ctx)
+		if errors.Is(err, replication.ErrPromoted) {
+			log.Println("store promoted to primary, replication stopped")
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-done
+
+		if err := db.Close(); err != nil {
+			log.Printf("failed to close replication database: %v", err)
+		}
+	}, nil
+}
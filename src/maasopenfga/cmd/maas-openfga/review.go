@@ -0,0 +1,126 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	"maas.io/core/src/maasopenfga/internal/migrations"
+	"maas.io/core/src/maasopenfga/internal/review"
+)
+
+const day = 24 * time.Hour
+
+func reviewCmd() *cobra.Command {
+	var (
+		grantDays, membershipDays int
+		format, output            string
+		expire                    bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "review",
+		Short: "Report grants and memberships due for an access review.",
+		Long: "Report grants older than --grant-days, grants to disabled or deleted " +
+			"MAAS users, and group memberships not reconfirmed for --membership-days. " +
+			"With --expire, the reported tuples are deleted.",
+		Example: "maas-openfga review --grant-days 180 --format csv --output review.csv",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			write := review.WriteJSON
+
+			switch format {
+			case "json":
+			case "csv":
+				write = review.WriteCSV
+			default:
+				return fmt.Errorf("unsupported format %q (json, csv)", format)
+			}
+
+			if grantDays < 0 || membershipDays < 0 {
+				return errors.New("--grant-days and --membership-days can't be negative")
+			}
+
+			opts := review.Options{
+				Now:              time.Now(),
+				GrantMaxAge:      time.Duration(grantDays) * day,
+				MembershipMaxAge: time.Duration(membershipDays) * day,
+			}
+
+			return withAppDatabase(func(db *sql.DB) error {
+				packet, err := review.Collect(cmd.Context(), db, migrations.StoreID, opts)
+				if err != nil {
+					return err
+				}
+
+				out := cmd.OutOrStdout()
+
+				if output != "" {
+					f, err := os.Create(filepath.Clean(output))
+					if err != nil {
+						return fmt.Errorf("failed to create review packet: %w", err)
+					}
+
+					if err := errors.Join(write(f, packet), f.Close()); err != nil {
+						return fmt.Errorf("failed to write review packet: %w", err)
+					}
+
+					fmt.Fprintf(cmd.ErrOrStderr(), "%d findings written to %s\n", len(packet.Findings), output)
+				} else if err := write(out, packet); err != nil {
+					return err
+				}
+
+				if !expire {
+					return nil
+				}
+
+				return expireFindings(cmd, db, packet)
+			})
+		},
+	}
+
+	cmd.Flags().IntVar(&grantDays, "grant-days", 90,
+		"Report grants older than this many days (0 disables the check)")
+	cmd.Flags().IntVar(&membershipDays, "membership-days", 90,
+		"Report group memberships not reconfirmed for this many days (0 disables the check)")
+	cmd.Flags().StringVar(&format, "format", "json", "Output format: json or csv")
+	cmd.Flags().StringVar(&output, "output", "", "Write the review packet to this file instead of stdout")
+	cmd.Flags().BoolVar(&expire, "expire", false, "Delete the reported tuples")
+
+	return cmd
+}
+
+// expireFindings deletes the reported tuples.
+func expireFindings(cmd *cobra.Command, db *sql.DB, packet *review.Packet) error {
+	if err := requirePrimary(cmd.Context(), db, "expire grants"); err != nil {
+		return err
+	}
+
+	deleted, err := review.Expire(cmd.Context(), db, migrations.StoreID, packet.Findings)
+	if err != nil {
+		return fmt.Errorf("failed to expire grants: %w", err)
+	}
+
+	fmt.Fprintf(cmd.ErrOrStderr(), "%d tuples expired\n", deleted)
+
+	return nil
+}
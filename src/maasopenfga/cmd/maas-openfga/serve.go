@@ -0,0 +1,279 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/openfga/openfga/pkg/logger"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/spf13/cobra"
+	"maas.io/core/src/maasopenfga/internal/migrations"
+	"maas.io/core/src/maasopenfga/internal/migrator"
+	"maas.io/core/src/maasopenfga/internal/mirror"
+	"maas.io/core/src/maasopenfga/internal/server"
+)
+
+const (
+	migrationTimeout = 5 * time.Minute
+)
+
+// serveFunc serves the API, replaced in tests.
+var serveFunc = runServe
+
+func serveCmd() *cobra.Command {
+	var migrate bool
+
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve the OpenFGA HTTP API on the configured listeners.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return serveFunc(cmd.Context(), migrate)
+		},
+	}
+
+	addMigrateFlag(cmd, &migrate)
+
+	return cmd
+}
+
+func addMigrateFlag(cmd *cobra.Command, migrate *bool) {
+	cmd.Flags().BoolVar(migrate, "migrate", false,
+		"Apply datastore and authorization model migrations before serving")
+}
+
+// runServe serves, recording the crashes of the server.
+func runServe(ctx context.Context, migrate bool) error {
+	if err := crashes.CaptureRuntimeCrashes(); err != nil {
+		log.Printf("runtime crashes won't be recorded: %v", err)
+	}
+
+	defer crashes.Recover()
+
+	err := serve(ctx, migrate)
+	if err != nil {
+		recordCrash(err)
+	}
+
+	return err
+}
+
+// Tested in src/tests/e2e/test_openfga_integration.py
+func serve(ctx context.Context, migrate bool) error {
+	regionCfg, err := readRegionConfig()
+	if err != nil {
+		return err
+	}
+
+	openfgaLogger, err := newLogger(logger.WithFormat("json"))
+	if err != nil {
+		return err
+	}
+
+	if regionCfg.OpenFGAMirrorSource != "" {
+		if migrate {
+			return errors.New("--migrate can't be used with openfga_mirror_source, mirrors don't use the database")
+		}
+
+		return serveMirror(ctx, openfgaLogger, regionCfg)
+	}
+
+	dsn, err := getPostgresDSN(regionCfg)
+	if err != nil {
+		return fmt.Errorf("invalid database configuration: %w", err)
+	}
+
+	if migrate {
+		appDSN, err := getAppPostgresDSN(regionCfg)
+		if err != nil {
+			return fmt.Errorf("invalid database configuration: %w", err)
+		}
+
+		migrateCtx, cancel := context.WithTimeout(ctx, migrationTimeout)
+
+		err = migrator.Up(migrateCtx, dsn, appDSN, openfgaLogger)
+
+		cancel()
+
+		if err != nil {
+			return fmt.Errorf("failed to apply migrations: %w", err)
+		}
+
+		log.Println("migrations applied")
+	}
+
+	logStartup(ctx, openfgaLogger, dsn, regionCfg, migrate)
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if primary := regionCfg.OpenFGAReplicationPrimary; primary != "" {
+		stopReplication, err := startReplication(ctx, dsn, primary)
+		if err != nil {
+			return err
+		}
+
+		defer stopReplication()
+	}
+
+	// Standby stores mirror the tuples of their primary.
+	if regionCfg.OpenFGAEntitySync && regionCfg.OpenFGAReplicationPrimary == "" {
+		appDSN, err := getAppPostgresDSN(regionCfg)
+		if err != nil {
+			return fmt.Errorf("invalid database configuration: %w", err)
+		}
+
+		stopEntitySync, err := startEntitySync(ctx, appDSN)
+		if err != nil {
+			return err
+		}
+
+		defer stopEntitySync()
+	}
+
+	if interval := regionCfg.OpenFGAReconcileInterval; interval > 0 {
+		appDSN, err := getAppPostgresDSN(regionCfg)
+		if err != nil {
+			return fmt.Errorf("invalid database configuration: %w", err)
+		}
+
+		stopReconciliation, err := startReconciliation(ctx, appDSN, time.Duration(interval)*time.Second)
+		if err != nil {
+			return err
+		}
+
+		defer stopReconciliation()
+	}
+
+	cfg := server.Config{
+		Logger:           openfgaLogger,
+		DSN:              dsn,
+		MaxOpenConns:     regionCfg.OpenFGAMaxOpenConns,
+		MaxIdleConns:     regionCfg.OpenFGAMaxIdleConns,
+		MaxBatchRequests: regionCfg.OpenFGAMaxBatchRequests,
+	}
+	applyResolutionLimits(&cfg, regionCfg)
+
+	return runServer(ctx, regionCfg, cfg)
+}
+
+// serveMirror serves a read-only copy of the MAAS store of
+// openfga_mirror_source, kept in memory and refreshed in the background.
+// The database, replication, entity sync and reconciliation are unused.
+func serveMirror(ctx context.Context, openfgaLogger logger.Logger, regionCfg *regionConfig) error {
+	logStartup(ctx, openfgaLogger, "", regionCfg, false)
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	datastore := memory.New()
+	defer datastore.Close()
+
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	go func() {
+		defer close(done)
+
+		m := mirror.New(datastore, regionCfg.OpenFGAMirrorSource, migrations.StoreID,
+			mirror.WithInterval(time.Duration(regionCfg.OpenFGAMirrorInterval)*time.Second))
+		_ = m.Run(ctx) //nolint:errcheck // only returns once stopped
+	}()
+
+	log.Printf("mirroring %s every %ds", regionCfg.OpenFGAMirrorSource, regionCfg.OpenFGAMirrorInterval)
+
+	cfg := server.Config{
+		Logger:           openfgaLogger,
+		Datastore:        datastore,
+		MaxOpenConns:     regionCfg.OpenFGAMaxOpenConns,
+		MaxBatchRequests: regionCfg.OpenFGAMaxBatchRequests,
+		ReadOnly:         true,
+	}
+	applyResolutionLimits(&cfg, regionCfg)
+
+	return runServer(ctx, regionCfg, cfg)
+}
+
+// applyResolutionLimits sets the limits of the work done to answer a query.
+func applyResolutionLimits(cfg *server.Config, regionCfg *regionConfig) {
+	cfg.MaxResolutionDepth = regionCfg.OpenFGAMaxResolutionDepth
+	cfg.MaxResolutionBreadth = regionCfg.OpenFGAResolutionBreadth
+	cfg.DispatchThrottlingThreshold = regionCfg.OpenFGAThrottleThreshold
+	cfg.RequestTimeout = time.Duration(regionCfg.OpenFGARequestTimeout) * time.Second
+}
+
+// runServer serves cfg on the listeners configured in regiond.conf until
+// ctx is done.
+func runServer(ctx context.Context, regionCfg *regionConfig, cfg server.Config) error {
+	listeners := regionCfg.OpenFGAListeners
+	netListeners := make([]net.Listener, 0, len(listeners))
+
+	defer func() {
+		for i := range netListeners {
+			listeners[i].cleanup()
+		}
+	}()
+
+	for i := range listeners {
+		lis, err := listeners[i].listen()
+		if err != nil {
+			closeListeners(netListeners)
+			return fmt.Errorf("failed to listen on %s: %w", &listeners[i], err)
+		}
+
+		netListeners = append(netListeners, lis)
+
+		if listeners[i].ReadOnly {
+			cfg.ReadOnlyListeners = append(cfg.ReadOnlyListeners, lis)
+		} else {
+			cfg.Listeners = append(cfg.Listeners, lis)
+		}
+	}
+
+	srv, err := server.New(cfg)
+	if err != nil {
+		return err
+	}
+
+	for i := range listeners {
+		log.Printf("OpenFGA HTTP listening on %s", &listeners[i])
+	}
+
+	return srv.Run(ctx)
+}
+
+// closeListeners closes listeners opened before a failure to open another.
+func closeListeners(listeners []net.Listener) {
+	for _, lis := range listeners {
+		if err := lis.Close(); err != nil {
+			log.Printf("failed to close listener %s: %v", lis.Addr(), err)
+		}
+	}
+}
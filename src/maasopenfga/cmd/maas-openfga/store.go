@@ -0,0 +1,111 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"maas.io/core/src/maasopenfga/internal/migrations"
+	"maas.io/core/src/maasopenfga/internal/migrator"
+	"maas.io/core/src/maasopenfga/internal/server"
+	"maas.io/core/src/maasopenfga/internal/stores"
+)
+
+func storeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "store",
+		Short: "Manage the stores holding the authorization data of tenants.",
+		Long: "Besides the MAAS store, named \"" + stores.DefaultName + "\", additional " +
+			"stores isolate the authorization data of tenants. Each one gets the MAAS " +
+			"authorization model, which migrations keep up to date. API clients select " +
+			"a store by name, either in the path (/stores/<name>/...) or with the " +
+			server.StoreHeader + " header.",
+		Args: cobra.NoArgs,
+	}
+
+	cmd.AddCommand(storeCreateCmd())
+	cmd.AddCommand(storeListCmd())
+
+	return cmd
+}
+
+func storeCreateCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:     "create <name>",
+		Short:   "Create a store with the latest MAAS authorization model.",
+		Example: "maas-openfga store create tenant-a",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Only the MAAS store is replicated, but standby regions are
+			// still expected to be managed from their primary.
+			if err := withDatastore(func(db *sql.DB) error {
+				return requirePrimary(cmd.Context(), db, "create stores")
+			}); err != nil {
+				return err
+			}
+
+			appDSN, err := regionAppDSN()
+			if err != nil {
+				return err
+			}
+
+			store, err := migrator.CreateStore(cmd.Context(), appDSN, args[0])
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "created store %q (%s) with model %s\n",
+				store.Name, store.ID, store.ModelVersion)
+
+			return nil
+		},
+	}
+}
+
+func storeListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List the MAAS store and the tenant stores.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			appDSN, err := regionAppDSN()
+			if err != nil {
+				return err
+			}
+
+			list, err := migrator.ListStores(cmd.Context(), appDSN)
+			if err != nil {
+				return err
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "NAME\tID\tMODEL VERSION\tCREATED AT")
+			// The model of the MAAS store is managed by MAAS migrations.
+			fmt.Fprintf(w, "%s\t%s\t\t\n", stores.DefaultName, migrations.StoreID)
+
+			for _, s := range list {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", s.Name, s.ID, s.ModelVersion,
+					s.CreatedAt.Format(time.RFC3339))
+			}
+
+			return w.Flush()
+		},
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"text/tabwriter"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/spf13/cobra"
+)
+
+const (
+	tuplePageSize = 100
+)
+
+func tupleCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tuple",
+		Short: "Read, write or delete relationship tuples.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(tupleReadCmd())
+	cmd.AddCommand(tupleWriteCmd())
+	cmd.AddCommand(tupleDeleteCmd())
+
+	return cmd
+}
+
+func tupleReadCmd() *cobra.Command {
+	var (
+		socketPath string
+		store      string
+		key        openfgav1.ReadRequestTupleKey
+	)
+
+	cmd := &cobra.Command{
+		Use:     "read",
+		Short:   "List tuples, optionally filtered by user, relation or object.",
+		Example: "maas-openfga tuple read --object pool:0",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var filter *openfgav1.ReadRequestTupleKey
+			if key.GetUser() != "" || key.GetRelation() != "" || key.GetObject() != "" {
+				filter = &key
+			}
+
+			keys, err := newAPIClient(socketPath, store).readTuples(cmd.Context(), filter)
+			if err != nil {
+				return err
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "USER\tRELATION\tOBJECT")
+
+			for _, k := range keys {
+				fmt.Fprintf(w, "%s\t%s\t%s\n", k.GetUser(), k.GetRelation(), k.GetObject())
+			}
+
+			return w.Flush()
+		},
+	}
+
+	addSocketFlag(cmd, &socketPath)
+	addStoreFlag(cmd, &store)
+	cmd.Flags().StringVar(&key.User, "user", "", "Filter by user (requires --object)")
+	cmd.Flags().StringVar(&key.Relation, "relation", "", "Filter by relation (requires --object)")
+	cmd.Flags().StringVar(&key.Object, "object", "",
+		"Filter by object, either a type (pool:) or a single object (pool:0)")
+
+	return cmd
+}
+
+func tupleWriteCmd() *cobra.Command {
+	var socketPath, store string
+
+	cmd := &cobra.Command{
+		Use:     "write <user> <relation> <object>",
+		Short:   "Write a tuple.",
+		Example: "maas-openfga tuple write group:1#member can_edit_machines pool:0",
+		Args:    cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			req := &openfgav1.WriteRequest{
+				Writes: &openfgav1.WriteRequestWrites{
+					TupleKeys: []*openfgav1.TupleKey{
+						{User: args[0], Relation: args[1], Object: args[2]},
+					},
+				},
+			}
+
+			return newAPIClient(socketPath, store).do(cmd.Context(), http.MethodPost,
+				"/write", req, &openfgav1.WriteResponse{})
+		},
+	}
+
+	addSocketFlag(cmd, &socketPath)
+	addStoreFlag(cmd, &store)
+
+	return cmd
+}
+
+func tupleDeleteCmd() *cobra.Command {
+	var socketPath, store string
+
+	cmd := &cobra.Command{
+		Use:     "delete <user> <relation> <object>",
+		Short:   "Delete a tuple.",
+		Example: "maas-openfga tuple delete group:1#member can_edit_machines pool:0",
+		Args:    cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			req := &openfgav1.WriteRequest{
+				Deletes: &openfgav1.WriteRequestDeletes{
+					TupleKeys: []*openfgav1.TupleKeyWithoutCondition{
+						{User: args[0], Relation: args[1], Object: args[2]},
+					},
+				},
+			}
+
+			return newAPIClient(socketPath, store).do(cmd.Context(), http.MethodPost,
+				"/write", req, &openfgav1.WriteResponse{})
+		},
+	}
+
+	addSocketFlag(cmd, &socketPath)
+	addStoreFlag(cmd, &store)
+
+	return cmd
+}
@@ -0,0 +1,100 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"maas.io/core/src/maasgocommon/secrets"
+)
+
+// newVault returns the provider of the secrets regiond stores in Vault.
+func newVault(cfg *regionConfig) secrets.Provider {
+	return secrets.NewVault(cfg.VaultURL, cfg.VaultAppRoleID, cfg.VaultSecretID,
+		secrets.WithMount(cfg.VaultSecretsMount),
+		secrets.WithBasePath(cfg.VaultSecretsPath),
+	)
+}
+
+// maasDataDir returns the directory regiond keeps its state in.
+func maasDataDir() string {
+	if dir := os.Getenv("MAAS_DATA"); dir != "" {
+		return filepath.Clean(dir)
+	}
+
+	base := "/var/lib/maas"
+	if dataDir := os.Getenv("SNAP_COMMON"); dataDir != "" {
+		base = filepath.Join(filepath.Clean(dataDir), base)
+	}
+
+	return base
+}
+
+// dbCredsVaultPath returns the path regiond stores the database credentials
+// of this controller at in Vault.
+func dbCredsVaultPath() (string, error) {
+	path := filepath.Join(maasDataDir(), "maas_id")
+
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return "", fmt.Errorf("failed to read MAAS ID: %w", err)
+	}
+
+	id := strings.TrimSpace(string(data))
+	if id == "" {
+		return "", fmt.Errorf("MAAS ID not set in %s", path)
+	}
+
+	return "controller/" + id + "/database-creds", nil
+}
+
+// applyVaultDatabaseCreds replaces the database credentials of cfg with the
+// ones regiond migrated to Vault. As regiond does, the credentials of cfg
+// are kept if Vault doesn't have them or can't be reached.
+func applyVaultDatabaseCreds(ctx context.Context, cfg *regionConfig, vault secrets.Provider) error {
+	path, err := dbCredsVaultPath()
+	if err != nil {
+		return err
+	}
+
+	creds, err := vault.Get(ctx, path)
+	if errors.Is(err, secrets.ErrNotFound) {
+		return nil
+	} else if err != nil {
+		log.Printf("unable to fetch database credentials from Vault, using regiond.conf: %v", err)
+		return nil
+	}
+
+	fields := make(map[string]string, 3)
+
+	for _, name := range []string{"user", "pass", "name"} {
+		if fields[name], err = creds.Field(name); err != nil {
+			return fmt.Errorf("incomplete database credentials in Vault: %w", err)
+		}
+	}
+
+	cfg.DatabaseUser = fields["user"]
+	cfg.DatabasePass = fields["pass"]
+	cfg.DatabaseName = fields["name"]
+
+	return nil
+}
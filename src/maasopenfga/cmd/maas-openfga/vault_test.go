@@ -0,0 +1,105 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maas.io/core/src/maasgocommon/secrets"
+)
+
+// fakeVault holds the secrets at their path, or fails with err.
+type fakeVault struct {
+	err     error
+	secrets map[string]map[string]string
+}
+
+func (v fakeVault) Get(_ context.Context, path string) (*secrets.Secret, error) {
+	if v.err != nil {
+		return nil, v.err
+	}
+
+	fields, ok := v.secrets[path]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", path, secrets.ErrNotFound)
+	}
+
+	return &secrets.Secret{Fields: fields}, nil
+}
+
+func TestApplyVaultDatabaseCreds(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "maas_id"), []byte("abc\n"), 0o600))
+	t.Setenv("MAAS_DATA", dir)
+
+	const path = "controller/abc/database-creds"
+
+	testcases := map[string]struct {
+		vault  fakeVault
+		user   string
+		errMsg string
+	}{
+		"credentials in vault": {
+			vault: fakeVault{secrets: map[string]map[string]string{
+				path: {"user": "vault-user", "pass": "vault-pass", "name": "vault-db"},
+			}},
+			user: "vault-user",
+		},
+		"credentials not in vault": {
+			vault: fakeVault{},
+			user:  "maas",
+		},
+		"vault unavailable": {
+			vault: fakeVault{err: errors.New("vault is sealed")},
+			user:  "maas",
+		},
+		"incomplete credentials": {
+			vault: fakeVault{secrets: map[string]map[string]string{
+				path: {"user": "vault-user", "name": "vault-db"},
+			}},
+			errMsg: `incomplete database credentials in Vault: secret has no field "pass"`,
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			cfg := &regionConfig{DatabaseUser: "maas", DatabasePass: "pass", DatabaseName: "maasdb"}
+
+			err := applyVaultDatabaseCreds(context.Background(), cfg, tc.vault)
+			if tc.errMsg != "" {
+				assert.EqualError(t, err, tc.errMsg)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.user, cfg.DatabaseUser)
+		})
+	}
+}
+
+func TestApplyVaultDatabaseCredsWithoutMAASID(t *testing.T) {
+	t.Setenv("MAAS_DATA", t.TempDir())
+
+	err := applyVaultDatabaseCreds(context.Background(), &regionConfig{}, fakeVault{})
+	assert.ErrorContains(t, err, "failed to read MAAS ID")
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"maas.io/core/src/maasopenfga/internal/migrations"
+	"maas.io/core/src/maasopenfga/internal/verify"
+)
+
+func verifyCmd() *cobra.Command {
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Check the integrity of the MAAS store and authorization model.",
+		Long: "Check that the MAAS store exists, that every authorization model is " +
+			"stored in a single row whose protobuf deserializes and matches its ID, " +
+			"that the latest model is valid, and that every tuple references types " +
+			"and relations of the latest model. The results are written as JSON and " +
+			"the command fails if any check does.",
+		Example: "maas-openfga verify --output verify.json",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var report *verify.Report
+
+			err := withDatastore(func(db *sql.DB) error {
+				state, err := verify.Collect(cmd.Context(), db, migrations.StoreID)
+				if err != nil {
+					return err
+				}
+
+				report = verify.Verify(cmd.Context(), migrations.StoreID, state)
+
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+
+			if output != "" {
+				f, err := os.Create(filepath.Clean(output))
+				if err != nil {
+					return fmt.Errorf("failed to create report: %w", err)
+				}
+
+				if err := errors.Join(verify.WriteJSON(f, report), f.Close()); err != nil {
+					return fmt.Errorf("failed to write report: %w", err)
+				}
+			} else if err := verify.WriteJSON(cmd.OutOrStdout(), report); err != nil {
+				return err
+			}
+
+			if failed := report.Failed(); len(failed) > 0 {
+				return fmt.Errorf("verification failed: %s", strings.Join(failed, ", "))
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&output, "output", "", "Write the report to this file instead of stdout")
+
+	return cmd
+}
@@ -0,0 +1,234 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package access answers "who has access" questions about MAAS entities,
+// e.g. who can deploy machines in a pool, for the MAAS UI.
+//
+// OpenFGA answers them with Expand, whose tree of usersets is left to the
+// client to walk, or ListUsers, one relation at a time. The handler asks
+// ListUsers for every relation of the object, which resolves group
+// memberships and inherited permissions, and returns a flat, sorted list of
+// users per relation.
+package access
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// UserType is the type of the users listed by default.
+const UserType = "user"
+
+// Lister is the part of the OpenFGA service used to list users.
+type Lister interface {
+	ReadAuthorizationModels(ctx context.Context,
+		req *openfgav1.ReadAuthorizationModelsRequest) (*openfgav1.ReadAuthorizationModelsResponse, error)
+	ListUsers(ctx context.Context,
+		req *openfgav1.ListUsersRequest) (*openfgav1.ListUsersResponse, error)
+}
+
+// Response is the body of the answer to an access request.
+type Response struct {
+	// Relations maps the relations to the users having them, sorted.
+	// Relations nobody has map to an empty list.
+	Relations            map[string][]string `json:"relations"`
+	Object               string              `json:"object"`
+	AuthorizationModelID string              `json:"authorization_model_id"`
+}
+
+// Handler serves the users having access to an object of a store.
+type Handler struct {
+	lister     Lister
+	writeError func(http.ResponseWriter, *http.Request, error)
+}
+
+// Option allows to set additional Handler options
+type Option func(*Handler)
+
+// WithErrorHandler sets how errors are written (default: their gRPC
+// status, as the grpc-gateway does for the OpenFGA API)
+func WithErrorHandler(fn func(http.ResponseWriter, *http.Request, error)) Option {
+	return func(h *Handler) {
+		if fn != nil {
+			h.writeError = fn
+		}
+	}
+}
+
+// New returns a Handler listing users with lister.
+func New(lister Lister, options ...Option) *Handler {
+	h := &Handler{lister: lister, writeError: writeError}
+
+	for _, opt := range options {
+		opt(h)
+	}
+
+	return h
+}
+
+// HandlerFunc returns a grpc-gateway handler for a path with a {store_id}
+// parameter, e.g. "/stores/{store_id}/access".
+func (h *Handler) HandlerFunc() runtime.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request, params map[string]string) {
+		h.Serve(w, r, params["store_id"])
+	}
+}
+
+// Serve answers which users have access to ?object= in the given store,
+// using its latest authorization model. Relations can be restricted with
+// one or more ?relation=, all the relations of the type of the object are
+// listed otherwise. ?user_type= lists users of another type, or usersets
+// such as group#member to find the groups granting access.
+func (h *Handler) Serve(w http.ResponseWriter, r *http.Request, storeID string) {
+	query := r.URL.Query()
+
+	resp, err := h.list(r.Context(), storeID, query.Get("object"), query["relation"],
+		query.Get("user_type"))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	//nolint:errcheck // the client is probably gone already
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (h *Handler) list(ctx context.Context, storeID, object string, relations []string,
+	userType string) (*Response, error) {
+	objectType, objectID, ok := strings.Cut(object, ":")
+	if !ok || objectType == "" || objectID == "" {
+		return nil, status.Errorf(codes.InvalidArgument,
+			"invalid object %q, expected <type>:<id>", object)
+	}
+
+	if userType == "" {
+		userType = UserType
+	}
+
+	filterType, filterRelation, _ := strings.Cut(userType, "#")
+
+	models, err := h.lister.ReadAuthorizationModels(ctx, &openfgav1.ReadAuthorizationModelsRequest{
+		StoreId:  storeID,
+		PageSize: wrapperspb.Int32(1),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(models.GetAuthorizationModels()) == 0 {
+		return nil, status.Errorf(codes.NotFound, "store %s has no authorization model", storeID)
+	}
+
+	// The models are listed from the latest.
+	model := models.GetAuthorizationModels()[0]
+
+	idx := slices.IndexFunc(model.GetTypeDefinitions(), func(td *openfgav1.TypeDefinition) bool {
+		return td.GetType() == objectType
+	})
+	if idx < 0 {
+		return nil, status.Errorf(codes.InvalidArgument,
+			"type %q is not defined in model %s", objectType, model.GetId())
+	}
+
+	defined := model.GetTypeDefinitions()[idx].GetRelations()
+
+	if len(relations) == 0 {
+		for relation := range defined {
+			relations = append(relations, relation)
+		}
+	}
+
+	resp := &Response{
+		Object:               object,
+		AuthorizationModelID: model.GetId(),
+		Relations:            make(map[string][]string, len(relations)),
+	}
+
+	for _, relation := range relations {
+		if _, ok := defined[relation]; !ok {
+			return nil, status.Errorf(codes.InvalidArgument,
+				"relation %q is not defined on type %q", relation, objectType)
+		}
+
+		if _, ok := resp.Relations[relation]; ok {
+			continue
+		}
+
+		users, err := h.lister.ListUsers(ctx, &openfgav1.ListUsersRequest{
+			StoreId:              storeID,
+			AuthorizationModelId: model.GetId(),
+			Object:               &openfgav1.Object{Type: objectType, Id: objectID},
+			Relation:             relation,
+			UserFilters: []*openfgav1.UserTypeFilter{
+				{Type: filterType, Relation: filterRelation},
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		resp.Relations[relation] = flatten(users.GetUsers())
+	}
+
+	return resp, nil
+}
+
+// flatten returns the sorted, deduplicated names of users, e.g. "user:1" or
+// "user:*" when every user has access.
+func flatten(users []*openfgav1.User) []string {
+	names := make([]string, 0, len(users))
+
+	for _, u := range users {
+		switch {
+		case u.GetObject() != nil:
+			names = append(names, u.GetObject().GetType()+":"+u.GetObject().GetId())
+		case u.GetWildcard() != nil:
+			names = append(names, u.GetWildcard().GetType()+":*")
+		case u.GetUserset() != nil:
+			us := u.GetUserset()
+			names = append(names, us.GetType()+":"+us.GetId()+"#"+us.GetRelation())
+		}
+	}
+
+	slices.Sort(names)
+
+	return slices.Compact(names)
+}
+
+// writeError writes err as the grpc-gateway does for the OpenFGA API.
+func writeError(w http.ResponseWriter, _ *http.Request, err error) {
+	st := status.Convert(err)
+
+	body, errr := protojson.Marshal(st.Proto())
+	if errr != nil {
+		http.Error(w, st.Message(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(runtime.HTTPStatusFromCode(st.Code()))
+	w.Write(body) //nolint:errcheck // the client is probably gone already
+}
@@ -0,0 +1,204 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package access
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/openfga/openfga/pkg/server"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maas.io/core/src/maasopenfga/internal/model"
+)
+
+// newStore returns an in-memory OpenFGA service holding a store with the
+// latest MAAS model and tuples.
+func newStore(t *testing.T, tuples ...*openfgav1.TupleKey) (*server.Server, string) {
+	t.Helper()
+
+	ctx := context.Background()
+
+	datastore := memory.New()
+	t.Cleanup(datastore.Close)
+
+	srv, err := server.NewServerWithOpts(server.WithDatastore(datastore))
+	require.NoError(t, err)
+	t.Cleanup(srv.Close)
+
+	store, err := srv.CreateStore(ctx, &openfgav1.CreateStoreRequest{Name: "maas"})
+	require.NoError(t, err)
+
+	versions := model.Versions()
+
+	m, err := model.Load(versions[len(versions)-1])
+	require.NoError(t, err)
+
+	_, err = srv.WriteAuthorizationModel(ctx, &openfgav1.WriteAuthorizationModelRequest{
+		StoreId:         store.GetId(),
+		TypeDefinitions: m.GetTypeDefinitions(),
+		SchemaVersion:   m.GetSchemaVersion(),
+		Conditions:      m.GetConditions(),
+	})
+	require.NoError(t, err)
+
+	if len(tuples) > 0 {
+		_, err = srv.Write(ctx, &openfgav1.WriteRequest{
+			StoreId: store.GetId(),
+			Writes:  &openfgav1.WriteRequestWrites{TupleKeys: tuples},
+		})
+		require.NoError(t, err)
+	}
+
+	return srv, store.GetId()
+}
+
+func get(t *testing.T, h *Handler, storeID string, query url.Values) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, "/stores/"+storeID+"/access?"+query.Encode(), nil)
+	w := httptest.NewRecorder()
+
+	h.Serve(w, req, storeID)
+
+	return w
+}
+
+func TestServe(t *testing.T) {
+	srv, storeID := newStore(t,
+		&openfgav1.TupleKey{User: "user:1", Relation: "member", Object: "group:1"},
+		&openfgav1.TupleKey{User: "user:2", Relation: "member", Object: "group:1"},
+		&openfgav1.TupleKey{User: "user:2", Relation: "member", Object: "group:2"},
+		&openfgav1.TupleKey{User: "user:3", Relation: "member", Object: "group:3"},
+		&openfgav1.TupleKey{User: "user:4", Relation: "member", Object: "group:3"},
+		&openfgav1.TupleKey{User: "user:4", Relation: "banned", Object: "maas:0"},
+		&openfgav1.TupleKey{User: "maas:0", Relation: "parent", Object: "pool:1"},
+		&openfgav1.TupleKey{User: "group:1#member", Relation: "can_deploy_machines", Object: "pool:1"},
+		&openfgav1.TupleKey{User: "group:2#member", Relation: "can_edit_machines", Object: "pool:1"},
+		&openfgav1.TupleKey{User: "group:3#member", Relation: "can_edit_machines", Object: "maas:0"},
+	)
+	h := New(srv)
+
+	w := get(t, h, storeID, url.Values{
+		"object":   {"pool:1"},
+		"relation": {"can_deploy_machines", "can_edit_machines", "can_deploy_machines"},
+	})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var resp Response
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+
+	assert.Equal(t, "pool:1", resp.Object)
+	assert.NotEmpty(t, resp.AuthorizationModelID)
+	assert.Equal(t, map[string][]string{
+		// Directly, through a group and from maas:0, deduplicated, but
+		// not user:4 who is banned.
+		"can_deploy_machines": {"user:1", "user:2", "user:3"},
+		"can_edit_machines":   {"user:2", "user:3"},
+	}, resp.Relations)
+}
+
+func TestServeAllRelations(t *testing.T) {
+	srv, storeID := newStore(t,
+		&openfgav1.TupleKey{User: "user:1", Relation: "member", Object: "group:1"},
+		&openfgav1.TupleKey{User: "group:1#member", Relation: "can_view", Object: "zone:1"},
+	)
+
+	w := get(t, New(srv), storeID, url.Values{"object": {"zone:1"}})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var resp Response
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+
+	assert.Equal(t, map[string][]string{
+		"banned":     {},
+		"can_delete": {},
+		"can_edit":   {},
+		"can_view":   {"user:1"},
+		"parent":     {},
+	}, resp.Relations)
+}
+
+func TestServeUserType(t *testing.T) {
+	srv, storeID := newStore(t,
+		&openfgav1.TupleKey{User: "group:1#member", Relation: "can_view", Object: "zone:1"},
+		&openfgav1.TupleKey{User: "group:2#member", Relation: "can_edit", Object: "zone:1"},
+	)
+
+	w := get(t, New(srv), storeID, url.Values{
+		"object": {"zone:1"}, "relation": {"can_view"}, "user_type": {"group#member"},
+	})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var resp Response
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+
+	assert.Equal(t, map[string][]string{
+		"can_view": {"group:1#member", "group:2#member"},
+	}, resp.Relations)
+}
+
+func TestServeErrors(t *testing.T) {
+	srv, storeID := newStore(t)
+	h := New(srv)
+
+	testcases := map[string]struct {
+		query      url.Values
+		wantStatus int
+		wantError  string
+	}{
+		"missing object": {
+			query:      url.Values{},
+			wantStatus: http.StatusBadRequest,
+			wantError:  `invalid object ""`,
+		},
+		"invalid object": {
+			query:      url.Values{"object": {"pool"}},
+			wantStatus: http.StatusBadRequest,
+			wantError:  `invalid object "pool"`,
+		},
+		"unknown type": {
+			query:      url.Values{"object": {"rack:1"}},
+			wantStatus: http.StatusBadRequest,
+			wantError:  `type "rack" is not defined`,
+		},
+		"unknown relation": {
+			query:      url.Values{"object": {"pool:1"}, "relation": {"can_fly"}},
+			wantStatus: http.StatusBadRequest,
+			wantError:  `relation "can_fly" is not defined on type "pool"`,
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			w := get(t, h, storeID, tc.query)
+			assert.Equal(t, tc.wantStatus, w.Code)
+
+			var body struct {
+				Message string `json:"message"`
+			}
+
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Contains(t, body.Message, tc.wantError)
+		})
+	}
+}
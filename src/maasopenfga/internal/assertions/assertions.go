@@ -0,0 +1,274 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package assertions checks that an authorization model grants exactly the
+// intended permissions.
+//
+// Assertions are written in YAML, in the format of `fga model test`:
+//
+//	tuples:
+//	  - user: user:1
+//	    relation: member
+//	    object: group:1
+//	tests:
+//	  - name: members of a group inherit its permissions
+//	    tuples:
+//	      - user: group:1#member
+//	        relation: can_edit_machines
+//	        object: maas:0
+//	    check:
+//	      - user: user:1
+//	        object: maas:0
+//	        assertions:
+//	          can_edit_machines: true
+//	          can_edit_identities: false
+//
+// Every test runs against a fresh in-memory store holding the top-level
+// tuples and its own.
+package assertions
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/openfga/openfga/pkg/server"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"gopkg.in/yaml.v3"
+)
+
+// Tuple is a relationship tuple.
+type Tuple struct {
+	User     string `yaml:"user"`
+	Relation string `yaml:"relation"`
+	Object   string `yaml:"object"`
+}
+
+// Check lists the expected result of checking relations between a user and
+// an object.
+type Check struct {
+	User       string          `yaml:"user"`
+	Object     string          `yaml:"object"`
+	Assertions map[string]bool `yaml:"assertions"`
+}
+
+// Test is a set of checks against the tuples of the file and its own.
+type Test struct {
+	Name   string  `yaml:"name"`
+	Tuples []Tuple `yaml:"tuples"`
+	Check  []Check `yaml:"check"`
+}
+
+// File is a parsed assertions file.
+type File struct {
+	Tuples []Tuple `yaml:"tuples"`
+	Tests  []Test  `yaml:"tests"`
+}
+
+// Failure is an assertion that doesn't hold.
+type Failure struct {
+	Test     string
+	User     string
+	Relation string
+	Object   string
+	Want     bool
+}
+
+func (f Failure) String() string {
+	verb := "denied"
+	if f.Want {
+		verb = "allowed"
+	}
+
+	return fmt.Sprintf("%s: %s should be %s %s on %s", f.Test, f.User, verb, f.Relation, f.Object)
+}
+
+// Parse parses an assertions file. Unknown fields are rejected, so that
+// a typo doesn't silently drop assertions.
+func Parse(data []byte) (*File, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	var file File
+	if err := decoder.Decode(&file); err != nil {
+		return nil, fmt.Errorf("invalid assertions: %w", err)
+	}
+
+	for i, test := range file.Tests {
+		if test.Name == "" {
+			return nil, fmt.Errorf("invalid assertions: test %d has no name", i+1)
+		}
+
+		if len(test.Check) == 0 {
+			return nil, fmt.Errorf("invalid assertions: test %q has no checks", test.Name)
+		}
+
+		for _, check := range test.Check {
+			if check.User == "" || check.Object == "" || len(check.Assertions) == 0 {
+				return nil, fmt.Errorf("invalid assertions: test %q has a check "+
+					"without user, object or assertions", test.Name)
+			}
+		}
+	}
+
+	return &file, nil
+}
+
+// Run evaluates every assertion of file against model and returns those
+// that don't hold. An error means the assertions could not be evaluated,
+// for instance because they refer to a relation the model doesn't define.
+func Run(ctx context.Context, model *openfgav1.AuthorizationModel, file *File) ([]Failure, error) {
+	var failures []Failure
+
+	for _, test := range file.Tests {
+		testFailures, err := runTest(ctx, model, file.Tuples, test)
+		if err != nil {
+			return nil, fmt.Errorf("test %q: %w", test.Name, err)
+		}
+
+		failures = append(failures, testFailures...)
+	}
+
+	return failures, nil
+}
+
+func runTest(ctx context.Context, model *openfgav1.AuthorizationModel, tuples []Tuple, test Test) ([]Failure, error) {
+	store, err := NewStore(ctx, model)
+	if err != nil {
+		return nil, err
+	}
+	defer store.Close()
+
+	if err := store.Write(ctx, append(slices.Clone(tuples), test.Tuples...)...); err != nil {
+		return nil, err
+	}
+
+	var failures []Failure
+
+	for _, check := range test.Check {
+		// Sorted, so that failures are reported in a stable order.
+		for _, relation := range slices.Sorted(maps.Keys(check.Assertions)) {
+			want := check.Assertions[relation]
+
+			got, err := store.Check(ctx, check.User, relation, check.Object)
+			if err != nil {
+				return nil, err
+			}
+
+			if got != want {
+				failures = append(failures, Failure{
+					Test:     test.Name,
+					User:     check.User,
+					Relation: relation,
+					Object:   check.Object,
+					Want:     want,
+				})
+			}
+		}
+	}
+
+	return failures, nil
+}
+
+// Store is an in-memory OpenFGA store using a single model.
+type Store struct {
+	datastore storage.OpenFGADatastore
+	srv       *server.Server
+	storeID   string
+	modelID   string
+}
+
+// NewStore starts an in-memory OpenFGA server and writes model to a new
+// store. The store must be closed after use.
+func NewStore(ctx context.Context, model *openfgav1.AuthorizationModel) (*Store, error) {
+	datastore := memory.New()
+
+	srv, err := server.NewServerWithOpts(server.WithDatastore(datastore))
+	if err != nil {
+		datastore.Close()
+		return nil, err
+	}
+
+	s := &Store{datastore: datastore, srv: srv}
+
+	store, err := srv.CreateStore(ctx, &openfgav1.CreateStoreRequest{Name: "MAAS"})
+	if err != nil {
+		s.Close()
+		return nil, err
+	}
+
+	written, err := srv.WriteAuthorizationModel(ctx, &openfgav1.WriteAuthorizationModelRequest{
+		StoreId:         store.GetId(),
+		TypeDefinitions: model.GetTypeDefinitions(),
+		SchemaVersion:   model.GetSchemaVersion(),
+		Conditions:      model.GetConditions(),
+	})
+	if err != nil {
+		s.Close()
+		return nil, fmt.Errorf("invalid model: %w", err)
+	}
+
+	s.storeID = store.GetId()
+	s.modelID = written.GetAuthorizationModelId()
+
+	return s, nil
+}
+
+// Write writes tuples to the store.
+func (s *Store) Write(ctx context.Context, tuples ...Tuple) error {
+	if len(tuples) == 0 {
+		return nil
+	}
+
+	keys := make([]*openfgav1.TupleKey, 0, len(tuples))
+	for _, t := range tuples {
+		keys = append(keys, &openfgav1.TupleKey{User: t.User, Relation: t.Relation, Object: t.Object})
+	}
+
+	_, err := s.srv.Write(ctx, &openfgav1.WriteRequest{
+		StoreId:              s.storeID,
+		AuthorizationModelId: s.modelID,
+		Writes:               &openfgav1.WriteRequestWrites{TupleKeys: keys},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write tuples: %w", err)
+	}
+
+	return nil
+}
+
+// Check reports whether user has relation on object.
+func (s *Store) Check(ctx context.Context, user, relation, object string) (bool, error) {
+	resp, err := s.srv.Check(ctx, &openfgav1.CheckRequest{
+		StoreId:              s.storeID,
+		AuthorizationModelId: s.modelID,
+		TupleKey:             &openfgav1.CheckRequestTupleKey{User: user, Relation: relation, Object: object},
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to check %s %s %s: %w", user, relation, object, err)
+	}
+
+	return resp.GetAllowed(), nil
+}
+
+// Close stops the server.
+func (s *Store) Close() {
+	s.srv.Close()
+	s.datastore.Close()
+}
@@ -0,0 +1,146 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package assertions
+
+import (
+	"context"
+	"testing"
+
+	parser "github.com/openfga/language/pkg/go/transformer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testModel = `model
+  schema 1.1
+
+type user
+
+type doc
+  relations
+    define owner: [user]
+    define viewer: [user] or owner
+`
+
+func TestParse(t *testing.T) {
+	testcases := map[string]struct {
+		in  string
+		err string
+	}{
+		"valid": {
+			in: `
+tests:
+  - name: owners can view
+    check:
+      - {user: user:1, object: doc:1, assertions: {viewer: true}}
+`,
+		},
+		"unknown field": {
+			in: `
+tests:
+  - name: owners can view
+    checks:
+      - {user: user:1, object: doc:1, assertions: {viewer: true}}
+`,
+			err: "field checks not found",
+		},
+		"test without name": {
+			in: `
+tests:
+  - check:
+      - {user: user:1, object: doc:1, assertions: {viewer: true}}
+`,
+			err: "test 1 has no name",
+		},
+		"test without checks": {
+			in: `
+tests:
+  - name: empty
+`,
+			err: `test "empty" has no checks`,
+		},
+		"check without assertions": {
+			in: `
+tests:
+  - name: empty
+    check:
+      - {user: user:1, object: doc:1}
+`,
+			err: `test "empty" has a check without user, object or assertions`,
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			_, err := Parse([]byte(tc.in))
+			if tc.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.ErrorContains(t, err, tc.err)
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	model, err := parser.TransformDSLToProto(testModel)
+	require.NoError(t, err)
+
+	file, err := Parse([]byte(`
+tuples:
+  - {user: user:1, relation: owner, object: doc:1}
+tests:
+  - name: owners
+    check:
+      - {user: user:1, object: doc:1, assertions: {owner: true, viewer: true}}
+  - name: viewers
+    tuples:
+      - {user: user:2, relation: viewer, object: doc:1}
+    check:
+      - {user: user:2, object: doc:1, assertions: {owner: true, viewer: true}}
+  - name: tuples are not shared between tests
+    check:
+      - {user: user:2, object: doc:1, assertions: {viewer: true}}
+`))
+	require.NoError(t, err)
+
+	failures, err := Run(context.Background(), model, file)
+	require.NoError(t, err)
+
+	assert.Equal(t, []Failure{
+		{Test: "viewers", User: "user:2", Relation: "owner", Object: "doc:1", Want: true},
+		{Test: "tuples are not shared between tests", User: "user:2", Relation: "viewer", Object: "doc:1", Want: true},
+	}, failures)
+	assert.Equal(t, "viewers: user:2 should be allowed owner on doc:1", failures[0].String())
+}
+
+func TestRunUnknownRelation(t *testing.T) {
+	model, err := parser.TransformDSLToProto(testModel)
+	require.NoError(t, err)
+
+	file, err := Parse([]byte(`
+tests:
+  - name: editors
+    check:
+      - {user: user:1, object: doc:1, assertions: {editor: false}}
+`))
+	require.NoError(t, err)
+
+	_, err = Run(context.Background(), model, file)
+	assert.ErrorContains(t, err, `test "editors"`)
+	assert.ErrorContains(t, err, "doc#editor")
+}
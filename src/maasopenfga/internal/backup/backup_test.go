@@ -0,0 +1,147 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package backup
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	tupleUtils "github.com/openfga/openfga/pkg/tuple"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestRoundTrip(t *testing.T) {
+	condCtx, err := structpb.NewStruct(map[string]any{"cidr": "10.0.0.0/8"})
+	require.NoError(t, err)
+
+	keys := []*openfgav1.TupleKey{
+		tupleUtils.NewTupleKey("pool:0", "can_edit_machines", "group:1#member"),
+		tupleUtils.NewTupleKeyWithCondition("maas:0", "banned", "user:2", "in_network", condCtx),
+	}
+
+	header := Header{
+		ExportedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Store:      "00000000000000000000000000",
+		ModelID:    "01MODEL",
+		Tuples:     len(keys),
+	}
+
+	var buf bytes.Buffer
+
+	w, err := NewWriter(&buf, header)
+	require.NoError(t, err)
+
+	for _, key := range keys {
+		require.NoError(t, w.Write(key))
+	}
+
+	require.NoError(t, w.Flush())
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	require.Len(t, lines, 3)
+	assert.JSONEq(t, `{"format":"maas-openfga-tuples","version":1,`+
+		`"store":"00000000000000000000000000","model_id":"01MODEL",`+
+		`"exported_at":"2026-01-02T03:04:05Z","tuples":2}`, lines[0])
+
+	r, err := NewReader(&buf)
+	require.NoError(t, err)
+
+	header.Format = Format
+	header.Version = Version
+	assert.Equal(t, header, r.Header())
+
+	for _, want := range keys {
+		got, err := r.Next()
+		require.NoError(t, err)
+		assert.True(t, proto.Equal(want, got), "got %v, want %v", got, want)
+	}
+
+	_, err = r.Next()
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestNewReaderErrors(t *testing.T) {
+	testcases := map[string]struct {
+		content string
+		err     string
+	}{
+		"empty": {
+			err: "empty export file",
+		},
+		"not an export": {
+			content: `{"format":"something-else","version":1}` + "\n",
+			err:     "not a tuple export file",
+		},
+		"not json": {
+			content: "user:1 member group:1\n",
+			err:     "not a tuple export file",
+		},
+		"newer version": {
+			content: `{"format":"maas-openfga-tuples","version":2}` + "\n",
+			err:     "unsupported export file version 2, expected at most 1",
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewReader(strings.NewReader(tc.content))
+			assert.EqualError(t, err, tc.err)
+		})
+	}
+}
+
+func TestReaderNextErrors(t *testing.T) {
+	testcases := map[string]struct {
+		content string
+		err     string
+	}{
+		"truncated": {
+			content: `{"format":"maas-openfga-tuples","version":1,"tuples":2}` + "\n" +
+				`{"user":"user:1","relation":"member","object":"group:1"}` + "\n",
+			err: "export file holds 1 tuples, 2 expected: the file is incomplete",
+		},
+		"invalid tuple": {
+			content: `{"format":"maas-openfga-tuples","version":1,"tuples":1}` + "\n" +
+				`{"user":"user:1","relation":"member"` + "\n",
+			err: "invalid tuple on line 2",
+		},
+		"missing object": {
+			content: `{"format":"maas-openfga-tuples","version":1,"tuples":1}` + "\n" +
+				`{"user":"user:1","relation":"member"}` + "\n",
+			err: "invalid tuple on line 2: user, relation and object are required",
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			r, err := NewReader(strings.NewReader(tc.content))
+			require.NoError(t, err)
+
+			for err == nil {
+				_, err = r.Next()
+			}
+
+			assert.ErrorContains(t, err, tc.err)
+		})
+	}
+}
@@ -0,0 +1,323 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package backup
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	tupleUtils "github.com/openfga/openfga/pkg/tuple"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+	"maas.io/core/src/maasopenfga/internal/tuples"
+)
+
+// ChunkSize is the number of tuples written per transaction on import, and
+// between progress reports.
+const ChunkSize = 1000
+
+// Mode selects how Import treats the tuples of the store missing from the
+// file.
+type Mode string
+
+const (
+	// ModeMerge keeps them.
+	ModeMerge Mode = "merge"
+	// ModeReplace deletes them, once the whole file has been imported.
+	ModeReplace Mode = "replace"
+)
+
+// Progress is called with the number of tuples processed so far, and the
+// total if known.
+type Progress func(done, total int)
+
+// Result summarizes an import.
+type Result struct {
+	// Read is the number of tuples in the file.
+	Read int
+	// Written is the number of tuples added or changed.
+	Written int
+	// Deleted is the number of tuples removed in ModeReplace.
+	Deleted int
+}
+
+func psql() sq.StatementBuilderType {
+	return sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+}
+
+// Export writes all the tuples of the store to w, sorted, from a consistent
+// snapshot of the database. It returns the number of tuples written.
+func Export(ctx context.Context, db *sql.DB, storeID string, w io.Writer, progress Progress) (int, error) {
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	//nolint:errcheck // read-only transaction
+	defer tx.Rollback()
+
+	header := Header{ExportedAt: time.Now().UTC(), Store: storeID}
+
+	if err := tx.QueryRowContext(ctx,
+		"SELECT count(*) FROM openfga.tuple WHERE store = $1", storeID).Scan(&header.Tuples); err != nil {
+		return 0, fmt.Errorf("failed to count tuples: %w", err)
+	}
+
+	err = tx.QueryRowContext(ctx, "SELECT id FROM openfga.authorization_model "+
+		"WHERE store = $1 ORDER BY id DESC LIMIT 1", storeID).Scan(&header.ModelID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("failed to read authorization model: %w", err)
+	}
+
+	bw, err := NewWriter(w, header)
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := tx.QueryContext(ctx, "SELECT object_type, object_id, relation, _user, "+
+		"condition_name, condition_context FROM openfga.tuple WHERE store = $1 "+
+		"ORDER BY object_type, object_id, relation, _user", storeID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read tuples: %w", err)
+	}
+
+	var n int
+
+	for rows.Next() {
+		key, err := scanKey(rows)
+		if err != nil {
+			return n, errors.Join(err, rows.Close())
+		}
+
+		if err := bw.Write(key); err != nil {
+			return n, errors.Join(err, rows.Close())
+		}
+
+		n++
+		if n%ChunkSize == 0 && progress != nil {
+			progress(n, header.Tuples)
+		}
+	}
+
+	if err := errors.Join(rows.Err(), rows.Close()); err != nil {
+		return n, fmt.Errorf("failed to read tuples: %w", err)
+	}
+
+	if progress != nil && n%ChunkSize != 0 {
+		progress(n, header.Tuples)
+	}
+
+	return n, bw.Flush()
+}
+
+func scanKey(rows *sql.Rows) (*openfgav1.TupleKey, error) {
+	var (
+		objectType, objectID, relation, user string
+		conditionName                        sql.NullString
+		conditionContext                     []byte
+	)
+
+	if err := rows.Scan(&objectType, &objectID, &relation, &user,
+		&conditionName, &conditionContext); err != nil {
+		return nil, err
+	}
+
+	key := tupleUtils.NewTupleKey(tupleUtils.BuildObject(objectType, objectID), relation, user)
+
+	if conditionName.String != "" {
+		key.Condition = &openfgav1.RelationshipCondition{Name: conditionName.String}
+
+		if conditionContext != nil {
+			var condCtx structpb.Struct
+			if err := proto.Unmarshal(conditionContext, &condCtx); err != nil {
+				return nil, fmt.Errorf("invalid condition context of %s: %w",
+					tupleUtils.TupleKeyToString(key), err)
+			}
+
+			key.Condition.Context = &condCtx
+		}
+	}
+
+	return key, nil
+}
+
+// Import writes the tuples read from r to the store, ChunkSize tuples per
+// transaction. Tuples already present are left untouched, so an interrupted
+// import can be run again. In ModeReplace, the tuples missing from the file
+// are deleted once all of it is imported.
+func Import(ctx context.Context, db *sql.DB, storeID string, r *Reader, mode Mode,
+	progress Progress) (*Result, error) {
+	if mode != ModeMerge && mode != ModeReplace {
+		return nil, fmt.Errorf("invalid import mode %q", mode)
+	}
+
+	var (
+		result Result
+		chunk  []*openfgav1.TupleKey
+		// imported holds the keys of the file in ModeReplace.
+		imported = make(map[string]struct{})
+	)
+
+	total := r.Header().Tuples
+
+	flush := func() error {
+		n, err := inTx(ctx, db, func(tx *sql.Tx) (int, error) {
+			return tuples.WriteBatch(ctx, tx, storeID, chunk)
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Written += n
+		chunk = chunk[:0]
+
+		if progress != nil {
+			progress(result.Read, total)
+		}
+
+		return nil
+	}
+
+	for {
+		key, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return &result, err
+		}
+
+		result.Read++
+		chunk = append(chunk, key)
+
+		if mode == ModeReplace {
+			imported[tupleUtils.TupleKeyToString(key)] = struct{}{}
+		}
+
+		if len(chunk) == ChunkSize {
+			if err := flush(); err != nil {
+				return &result, err
+			}
+		}
+	}
+
+	if len(chunk) > 0 {
+		if err := flush(); err != nil {
+			return &result, err
+		}
+	}
+
+	if mode == ModeReplace {
+		n, err := deleteMissing(ctx, db, storeID, imported)
+		result.Deleted = n
+
+		if err != nil {
+			return &result, err
+		}
+	}
+
+	return &result, nil
+}
+
+// deleteMissing deletes the tuples of the store not in keep.
+func deleteMissing(ctx context.Context, db *sql.DB, storeID string, keep map[string]struct{}) (int, error) {
+	stmt, args, err := psql().
+		Select("object_type", "object_id", "relation", "_user").
+		From("openfga.tuple").
+		Where(sq.Eq{"store": storeID}).
+		ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read tuples: %w", err)
+	}
+
+	var toDelete []*openfgav1.TupleKey
+
+	for rows.Next() {
+		var objectType, objectID, relation, user string
+		if err := rows.Scan(&objectType, &objectID, &relation, &user); err != nil {
+			return 0, errors.Join(err, rows.Close())
+		}
+
+		key := tupleUtils.NewTupleKey(tupleUtils.BuildObject(objectType, objectID), relation, user)
+		if _, ok := keep[tupleUtils.TupleKeyToString(key)]; !ok {
+			toDelete = append(toDelete, key)
+		}
+	}
+
+	if err := errors.Join(rows.Err(), rows.Close()); err != nil {
+		return 0, fmt.Errorf("failed to read tuples: %w", err)
+	}
+
+	var deleted int
+
+	for start := 0; start < len(toDelete); start += ChunkSize {
+		chunk := toDelete[start:min(start+ChunkSize, len(toDelete))]
+
+		n, err := inTx(ctx, db, func(tx *sql.Tx) (int, error) {
+			var n int
+
+			for _, key := range chunk {
+				ok, err := tuples.Delete(ctx, tx, storeID, key)
+				if err != nil {
+					return 0, err
+				}
+
+				if ok {
+					n++
+				}
+			}
+
+			return n, nil
+		})
+		if err != nil {
+			return deleted, err
+		}
+
+		deleted += n
+	}
+
+	return deleted, nil
+}
+
+func inTx(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) (int, error)) (int, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	n, err := fn(tx)
+	if err != nil {
+		return 0, errors.Join(err, tx.Rollback())
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return n, nil
+}
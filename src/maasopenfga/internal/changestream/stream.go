@@ -0,0 +1,282 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package changestream streams OpenFGA tuple changes to HTTP clients as
+// Server-Sent Events, so that they can invalidate caches of authorization
+// decisions as soon as tuples change.
+//
+// Every event carries a page of changes, as returned by ReadChanges, and
+// uses the page continuation token as event ID. Clients reconnecting with
+// Last-Event-ID (or ?continuation_token=) resume right after the last page
+// they have seen; new streams start from ?start_time= (RFC 3339) or from
+// the time they are opened.
+package changestream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+const (
+	defaultPollInterval      = time.Second
+	defaultHeartbeatInterval = 15 * time.Second
+	defaultPageSize          = 100
+)
+
+// Reader is the part of the OpenFGA service used to read tuple changes.
+type Reader interface {
+	ReadChanges(ctx context.Context,
+		req *openfgav1.ReadChangesRequest) (*openfgav1.ReadChangesResponse, error)
+}
+
+// Handler serves a stream of tuple changes of a store.
+type Handler struct {
+	reader            Reader
+	now               func() time.Time
+	pollInterval      time.Duration
+	heartbeatInterval time.Duration
+	pageSize          int32
+	writeError        func(http.ResponseWriter, *http.Request, error)
+}
+
+// Option allows to set additional Handler options
+type Option func(*Handler)
+
+// WithPollInterval sets how often the datastore is polled for new changes
+// while the stream is idle (default: 1s)
+func WithPollInterval(d time.Duration) Option {
+	return func(h *Handler) {
+		if d > 0 {
+			h.pollInterval = d
+		}
+	}
+}
+
+// WithHeartbeatInterval sets how often a comment is sent on idle streams,
+// which keeps proxies from closing them (default: 15s)
+func WithHeartbeatInterval(d time.Duration) Option {
+	return func(h *Handler) {
+		if d > 0 {
+			h.heartbeatInterval = d
+		}
+	}
+}
+
+// WithPageSize sets the maximum number of changes carried by an event
+// (default: 100)
+func WithPageSize(n int32) Option {
+	return func(h *Handler) {
+		if n > 0 {
+			h.pageSize = n
+		}
+	}
+}
+
+// WithErrorHandler sets how the errors reported before the stream starts
+// are written (default: the message of their gRPC status, as plain text)
+func WithErrorHandler(fn func(http.ResponseWriter, *http.Request, error)) Option {
+	return func(h *Handler) {
+		if fn != nil {
+			h.writeError = fn
+		}
+	}
+}
+
+// New returns a Handler reading changes from reader.
+func New(reader Reader, options ...Option) *Handler {
+	h := &Handler{
+		reader:            reader,
+		now:               time.Now,
+		pollInterval:      defaultPollInterval,
+		heartbeatInterval: defaultHeartbeatInterval,
+		pageSize:          defaultPageSize,
+		writeError:        writeError,
+	}
+
+	for _, opt := range options {
+		opt(h)
+	}
+
+	return h
+}
+
+// HandlerFunc returns a grpc-gateway handler for a path with a {store_id}
+// parameter, e.g. "/stores/{store_id}/changes/watch".
+func (h *Handler) HandlerFunc() runtime.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request, params map[string]string) {
+		h.Serve(w, r, params["store_id"])
+	}
+}
+
+// Serve streams changes of the given store until the client goes away.
+// Changes can be restricted to an object type with ?type=.
+func (h *Handler) Serve(w http.ResponseWriter, r *http.Request, storeID string) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		h.writeError(w, r, status.Error(codes.Internal, "streaming is not supported"))
+		return
+	}
+
+	ctx := r.Context()
+
+	query := r.URL.Query()
+
+	req := &openfgav1.ReadChangesRequest{
+		StoreId:           storeID,
+		Type:              query.Get("type"),
+		PageSize:          wrapperspb.Int32(h.pageSize),
+		ContinuationToken: r.Header.Get("Last-Event-ID"),
+	}
+
+	if req.ContinuationToken == "" {
+		req.ContinuationToken = query.Get("continuation_token")
+	}
+
+	if req.ContinuationToken == "" {
+		start := h.now()
+
+		if v := query.Get("start_time"); v != "" {
+			var err error
+
+			start, err = time.Parse(time.RFC3339Nano, v)
+			if err != nil {
+				h.writeError(w, r, status.Error(codes.InvalidArgument, "invalid start_time: "+err.Error()))
+				return
+			}
+		}
+
+		req.StartTime = timestamppb.New(start)
+	}
+
+	// The first read validates the request, so that errors can still be
+	// reported with a proper status code.
+	resp, err := h.reader.ReadChanges(ctx, req)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	err = h.stream(ctx, w, flusher, req, resp)
+	if err != nil && !errors.Is(err, context.Canceled) {
+		msg := strings.ReplaceAll(status.Convert(err).Message(), "\n", " ")
+		//nolint:errcheck // the client is probably gone already
+		fmt.Fprintf(w, "event: error\ndata: %s\n\n", msg)
+		flusher.Flush()
+	}
+}
+
+func (h *Handler) stream(ctx context.Context, w http.ResponseWriter,
+	flusher http.Flusher, req *openfgav1.ReadChangesRequest,
+	resp *openfgav1.ReadChangesResponse) error {
+	heartbeat := time.NewTicker(h.heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		if len(resp.GetChanges()) > 0 {
+			if err := writeEvent(w, resp); err != nil {
+				return err
+			}
+
+			flusher.Flush()
+			heartbeat.Reset(h.heartbeatInterval)
+		}
+
+		// An empty token is returned when there are no changes yet, keep
+		// polling from the same position.
+		if token := resp.GetContinuationToken(); token != "" {
+			req.ContinuationToken = token
+			req.StartTime = nil
+		}
+
+		// A full page means more changes are likely pending.
+		if len(resp.GetChanges()) < int(h.pageSize) {
+			if err := h.wait(ctx, w, flusher, heartbeat); err != nil {
+				return err
+			}
+		}
+
+		var err error
+
+		resp, err = h.reader.ReadChanges(ctx, req)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// wait blocks for the poll interval, sending heartbeats meanwhile.
+func (h *Handler) wait(ctx context.Context, w http.ResponseWriter,
+	flusher http.Flusher, heartbeat *time.Ticker) error {
+	poll := time.NewTimer(h.pollInterval)
+	defer poll.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-poll.C:
+			return nil
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return err
+			}
+
+			flusher.Flush()
+		}
+	}
+}
+
+func writeEvent(w http.ResponseWriter, resp *openfgav1.ReadChangesResponse) error {
+	data, err := protojson.Marshal(resp)
+	if err != nil {
+		return fmt.Errorf("failed to marshal changes: %w", err)
+	}
+
+	var b strings.Builder
+
+	if token := resp.GetContinuationToken(); token != "" {
+		b.WriteString("id: " + token + "\n")
+	}
+
+	b.WriteString("event: changes\n")
+	b.WriteString("data: " + string(data) + "\n\n")
+
+	_, err = w.Write([]byte(b.String()))
+
+	return err
+}
+
+func writeError(w http.ResponseWriter, _ *http.Request, err error) {
+	st := status.Convert(err)
+	http.Error(w, st.Message(), runtime.HTTPStatusFromCode(st.Code()))
+}
@@ -0,0 +1,287 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package changestream
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// fakeReader returns one scripted response per call, then empty pages.
+type fakeReader struct {
+	responses []*openfgav1.ReadChangesResponse
+	requests  []*openfgav1.ReadChangesRequest
+	err       error
+	mutex     sync.Mutex
+}
+
+func (f *fakeReader) ReadChanges(_ context.Context,
+	req *openfgav1.ReadChangesRequest) (*openfgav1.ReadChangesResponse, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.requests = append(f.requests, proto.CloneOf(req))
+
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	if len(f.responses) == 0 {
+		return &openfgav1.ReadChangesResponse{
+			ContinuationToken: req.GetContinuationToken(),
+		}, nil
+	}
+
+	resp := f.responses[0]
+	f.responses = f.responses[1:]
+
+	return resp, nil
+}
+
+func (f *fakeReader) calls() []*openfgav1.ReadChangesRequest {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return append([]*openfgav1.ReadChangesRequest(nil), f.requests...)
+}
+
+func change(object string) *openfgav1.TupleChange {
+	return &openfgav1.TupleChange{
+		TupleKey: &openfgav1.TupleKey{
+			User: "user:1", Relation: "member", Object: object,
+		},
+		Operation: openfgav1.TupleOperation_TUPLE_OPERATION_WRITE,
+	}
+}
+
+func newServer(t *testing.T, h *Handler) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.Serve(w, r, "store")
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+type event struct {
+	id   string
+	name string
+	data string
+}
+
+// readEvents reads n events from an SSE stream, skipping comments.
+func readEvents(t *testing.T, scanner *bufio.Scanner, n int) []event {
+	t.Helper()
+
+	var (
+		events  []event
+		current event
+	)
+
+	for len(events) < n && scanner.Scan() {
+		line := scanner.Text()
+
+		switch {
+		case line == "":
+			if current.name != "" {
+				events = append(events, current)
+			}
+
+			current = event{}
+		case strings.HasPrefix(line, ":"):
+		default:
+			key, value, _ := strings.Cut(line, ": ")
+
+			switch key {
+			case "id":
+				current.id = value
+			case "event":
+				current.name = value
+			case "data":
+				current.data = value
+			}
+		}
+	}
+
+	require.Len(t, events, n)
+
+	return events
+}
+
+func TestHandlerStream(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	reader := &fakeReader{
+		responses: []*openfgav1.ReadChangesResponse{
+			// No changes since the stream was opened.
+			{},
+			{Changes: []*openfgav1.TupleChange{change("group:1")}, ContinuationToken: "t1"},
+			{Changes: []*openfgav1.TupleChange{change("group:2")}, ContinuationToken: "t2"},
+		},
+	}
+
+	h := New(reader, WithPollInterval(time.Millisecond))
+	h.now = func() time.Time { return now }
+
+	srv := newServer(t, h)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"?type=group", nil)
+	require.NoError(t, err)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = resp.Body.Close() }) //nolint:errcheck // test cleanup
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	events := readEvents(t, bufio.NewScanner(resp.Body), 2)
+
+	assert.Equal(t, "t1", events[0].id)
+	assert.Equal(t, "changes", events[0].name)
+	assert.Contains(t, events[0].data, `"object":"group:1"`)
+	assert.Equal(t, "t2", events[1].id)
+	assert.Contains(t, events[1].data, `"object":"group:2"`)
+
+	calls := reader.calls()
+	require.GreaterOrEqual(t, len(calls), 3)
+
+	for _, call := range calls {
+		assert.Equal(t, "store", call.GetStoreId())
+		assert.Equal(t, "group", call.GetType())
+	}
+
+	// The stream starts from the time it is opened until a token is known.
+	assert.Equal(t, now, calls[0].GetStartTime().AsTime())
+	assert.Equal(t, now, calls[1].GetStartTime().AsTime())
+	assert.Empty(t, calls[1].GetContinuationToken())
+	assert.Nil(t, calls[2].GetStartTime())
+	assert.Equal(t, "t1", calls[2].GetContinuationToken())
+}
+
+func TestHandlerResume(t *testing.T) {
+	testcases := map[string]struct {
+		header string
+		query  string
+	}{
+		"last event id": {
+			header: "t5",
+		},
+		"query parameter": {
+			query: "?continuation_token=t5",
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			reader := &fakeReader{
+				responses: []*openfgav1.ReadChangesResponse{
+					{Changes: []*openfgav1.TupleChange{change("group:6")}, ContinuationToken: "t6"},
+				},
+			}
+
+			srv := newServer(t, New(reader))
+
+			ctx, cancel := context.WithCancel(context.Background())
+			t.Cleanup(cancel)
+
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+tc.query, nil)
+			require.NoError(t, err)
+
+			if tc.header != "" {
+				req.Header.Set("Last-Event-ID", tc.header)
+			}
+
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+
+			t.Cleanup(func() { _ = resp.Body.Close() }) //nolint:errcheck // test cleanup
+
+			events := readEvents(t, bufio.NewScanner(resp.Body), 1)
+			assert.Equal(t, "t6", events[0].id)
+
+			calls := reader.calls()
+			assert.Equal(t, "t5", calls[0].GetContinuationToken())
+			assert.Nil(t, calls[0].GetStartTime())
+		})
+	}
+}
+
+func TestHandlerStartTime(t *testing.T) {
+	reader := &fakeReader{
+		responses: []*openfgav1.ReadChangesResponse{
+			{Changes: []*openfgav1.TupleChange{change("group:1")}, ContinuationToken: "t1"},
+		},
+	}
+
+	srv := newServer(t, New(reader))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		srv.URL+"?start_time=1970-01-01T00:00:00Z", nil)
+	require.NoError(t, err)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = resp.Body.Close() }) //nolint:errcheck // test cleanup
+
+	readEvents(t, bufio.NewScanner(resp.Body), 1)
+
+	calls := reader.calls()
+	assert.Equal(t, time.Unix(0, 0).UTC(), calls[0].GetStartTime().AsTime())
+
+	resp, err = http.Get(srv.URL + "?start_time=yesterday") //nolint:noctx // test request
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = resp.Body.Close() }) //nolint:errcheck // test cleanup
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestHandlerError(t *testing.T) {
+	reader := &fakeReader{err: status.Error(codes.NotFound, "store not found")}
+
+	srv := newServer(t, New(reader))
+
+	resp, err := http.Get(srv.URL) //nolint:noctx // test request
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = resp.Body.Close() }) //nolint:errcheck // test cleanup
+
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
@@ -0,0 +1,329 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package datastorecopy copies the stores, authorization models and tuples
+// of an OpenFGA datastore to another one, possibly of a different engine,
+// e.g. from a development SQLite database to PostgreSQL.
+//
+// Store and model IDs are kept, so clients (and the MAAS store ID) keep
+// working against the copy. With Copy, the source is expected to be idle:
+// its changelog position is compared before and after the copy, and the
+// tuples counted on both sides, to make sure the copy is complete. With
+// CopyOnline, the changes made during the copy are replayed from the
+// changelog until the copy catches up.
+package datastorecopy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/openfga/openfga/pkg/storage"
+)
+
+// pageSize is the number of stores, models or tuples read at once.
+const pageSize = 100
+
+var (
+	// ErrSourceChanged is returned when the source was written to while
+	// being copied.
+	ErrSourceChanged = errors.New("the source datastore changed during the copy")
+	// ErrTargetNotEmpty is returned when a store of the target already
+	// holds tuples.
+	ErrTargetNotEmpty = errors.New("the target store already holds tuples")
+	// ErrCountMismatch is returned when the copy doesn't hold as many
+	// tuples as the source.
+	ErrCountMismatch = errors.New("the copy doesn't hold as many tuples as the source")
+)
+
+// Progress is called with the store being copied and the number of its
+// tuples copied so far.
+type Progress func(store *openfgav1.Store, tuples int)
+
+// StoreResult summarizes the copy of a store.
+type StoreResult struct {
+	Store  *openfgav1.Store
+	Models int
+	Tuples int
+	// Changes is the number of changes replayed by CopyOnline.
+	Changes int
+}
+
+// Copy copies every store of src, with its models and tuples, to dst. Stores
+// and models already in dst are kept, but stores of dst must not hold any
+// tuple yet.
+func Copy(ctx context.Context, src, dst storage.OpenFGADatastore, progress Progress) ([]StoreResult, error) {
+	srcStores, err := listStores(ctx, src)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list source stores: %w", err)
+	}
+
+	before, err := positions(ctx, src, srcStores)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]StoreResult, 0, len(srcStores))
+
+	for _, store := range srcStores {
+		result, err := copyStore(ctx, src, dst, store, progress)
+		if err != nil {
+			return results, fmt.Errorf("failed to copy store %q (%s): %w", store.GetName(), store.GetId(), err)
+		}
+
+		results = append(results, result)
+	}
+
+	if err := verify(ctx, src, dst, before, results); err != nil {
+		return results, err
+	}
+
+	return results, nil
+}
+
+func copyStore(ctx context.Context, src, dst storage.OpenFGADatastore, store *openfgav1.Store,
+	progress Progress) (StoreResult, error) {
+	result := StoreResult{Store: store}
+
+	if err := ensureStore(ctx, dst, store); err != nil {
+		return result, err
+	}
+
+	var err error
+
+	if result.Models, err = copyModels(ctx, src, dst, store.GetId()); err != nil {
+		return result, err
+	}
+
+	result.Tuples, err = copyTuples(ctx, src, dst, store, progress)
+
+	return result, err
+}
+
+// ensureStore creates the store in dst, unless it exists already, and
+// checks it holds no tuple.
+func ensureStore(ctx context.Context, dst storage.OpenFGADatastore, store *openfgav1.Store) error {
+	_, err := dst.GetStore(ctx, store.GetId())
+
+	switch {
+	case errors.Is(err, storage.ErrNotFound):
+		if _, err := dst.CreateStore(ctx, &openfgav1.Store{Id: store.GetId(), Name: store.GetName()}); err != nil {
+			return fmt.Errorf("failed to create store: %w", err)
+		}
+
+		return nil
+	case err != nil:
+		return fmt.Errorf("failed to read target store: %w", err)
+	}
+
+	tuples, _, err := dst.ReadPage(ctx, store.GetId(), storage.ReadFilter{}, storage.ReadPageOptions{
+		Pagination: storage.NewPaginationOptions(1, ""),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read target tuples: %w", err)
+	}
+
+	if len(tuples) > 0 {
+		return ErrTargetNotEmpty
+	}
+
+	return nil
+}
+
+func copyModels(ctx context.Context, src, dst storage.OpenFGADatastore, storeID string) (int, error) {
+	copied := 0
+	token := ""
+
+	for {
+		models, next, err := src.ReadAuthorizationModels(ctx, storeID, storage.ReadAuthorizationModelsOptions{
+			Pagination: storage.NewPaginationOptions(pageSize, token),
+		})
+		if err != nil {
+			return copied, fmt.Errorf("failed to read models: %w", err)
+		}
+
+		for _, model := range models {
+			_, err := dst.ReadAuthorizationModel(ctx, storeID, model.GetId())
+			if err == nil {
+				continue
+			}
+
+			if !errors.Is(err, storage.ErrNotFound) {
+				return copied, fmt.Errorf("failed to read target model %s: %w", model.GetId(), err)
+			}
+
+			if err := dst.WriteAuthorizationModel(ctx, storeID, model); err != nil {
+				return copied, fmt.Errorf("failed to write model %s: %w", model.GetId(), err)
+			}
+
+			copied++
+		}
+
+		if next == "" {
+			return copied, nil
+		}
+
+		token = next
+	}
+}
+
+func copyTuples(ctx context.Context, src, dst storage.OpenFGADatastore, store *openfgav1.Store,
+	progress Progress) (int, error) {
+	chunk := min(pageSize, dst.MaxTuplesPerWrite())
+	copied := 0
+
+	err := readTuples(ctx, src, store.GetId(), func(tuples []*openfgav1.Tuple) error {
+		for len(tuples) > 0 {
+			n := min(chunk, len(tuples))
+
+			writes := make(storage.Writes, 0, n)
+			for _, t := range tuples[:n] {
+				writes = append(writes, t.GetKey())
+			}
+
+			// Paging over a source being written to may return a tuple twice.
+			err := dst.Write(ctx, store.GetId(), nil, writes,
+				storage.WithOnDuplicateInsert(storage.OnDuplicateInsertIgnore))
+			if err != nil {
+				return fmt.Errorf("failed to write tuples: %w", err)
+			}
+
+			copied += n
+			tuples = tuples[n:]
+		}
+
+		if progress != nil {
+			progress(store, copied)
+		}
+
+		return nil
+	})
+
+	return copied, err
+}
+
+// readTuples calls fn with every page of the tuples of the store.
+func readTuples(ctx context.Context, ds storage.OpenFGADatastore, storeID string,
+	fn func([]*openfgav1.Tuple) error) error {
+	token := ""
+
+	for {
+		tuples, next, err := ds.ReadPage(ctx, storeID, storage.ReadFilter{}, storage.ReadPageOptions{
+			Pagination: storage.NewPaginationOptions(pageSize, token),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to read tuples: %w", err)
+		}
+
+		if err := fn(tuples); err != nil {
+			return err
+		}
+
+		if next == "" {
+			return nil
+		}
+
+		token = next
+	}
+}
+
+func countTuples(ctx context.Context, ds storage.OpenFGADatastore, storeID string) (int, error) {
+	count := 0
+
+	err := readTuples(ctx, ds, storeID, func(tuples []*openfgav1.Tuple) error {
+		count += len(tuples)
+		return nil
+	})
+
+	return count, err
+}
+
+func listStores(ctx context.Context, ds storage.OpenFGADatastore) ([]*openfgav1.Store, error) {
+	var all []*openfgav1.Store
+
+	token := ""
+
+	for {
+		stores, next, err := ds.ListStores(ctx, storage.ListStoresOptions{
+			Pagination: storage.NewPaginationOptions(pageSize, token),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		all = append(all, stores...)
+
+		if next == "" {
+			return all, nil
+		}
+
+		token = next
+	}
+}
+
+// positions returns the changelog position of each store, the ID of its
+// last change, or an empty string when it has none.
+func positions(ctx context.Context, ds storage.OpenFGADatastore, stores []*openfgav1.Store) (map[string]string, error) {
+	result := make(map[string]string, len(stores))
+
+	for _, store := range stores {
+		_, position, err := ds.ReadChanges(ctx, store.GetId(), storage.ReadChangesFilter{}, storage.ReadChangesOptions{
+			Pagination: storage.NewPaginationOptions(1, ""),
+			SortDesc:   true,
+		})
+		if err != nil && !errors.Is(err, storage.ErrNotFound) {
+			return nil, fmt.Errorf("failed to read the changelog of store %s: %w", store.GetId(), err)
+		}
+
+		result[store.GetId()] = position
+	}
+
+	return result, nil
+}
+
+// verify checks that the source didn't change since its positions were
+// taken, and that every store of dst holds as many tuples as was copied.
+func verify(ctx context.Context, src, dst storage.OpenFGADatastore, before map[string]string,
+	results []StoreResult) error {
+	srcStores, err := listStores(ctx, src)
+	if err != nil {
+		return fmt.Errorf("failed to list source stores: %w", err)
+	}
+
+	after, err := positions(ctx, src, srcStores)
+	if err != nil {
+		return err
+	}
+
+	if !maps.Equal(before, after) {
+		return ErrSourceChanged
+	}
+
+	for _, result := range results {
+		count, err := countTuples(ctx, dst, result.Store.GetId())
+		if err != nil {
+			return fmt.Errorf("failed to count the tuples of store %s: %w", result.Store.GetId(), err)
+		}
+
+		if count != result.Tuples {
+			return fmt.Errorf("%w: store %q holds %d tuples, %d were copied",
+				ErrCountMismatch, result.Store.GetName(), count, result.Tuples)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,182 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package datastorecopy
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/oklog/ulid/v2"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	parser "github.com/openfga/language/pkg/go/transformer"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testModel = `
+model
+  schema 1.1
+
+type user
+
+type doc
+  relations
+    define viewer: [user]
+`
+
+// newDatastore returns a memory datastore, closed when the test ends.
+func newDatastore(t *testing.T) storage.OpenFGADatastore {
+	t.Helper()
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	return ds
+}
+
+// addStore creates a store with a model and n tuples in ds.
+func addStore(t *testing.T, ds storage.OpenFGADatastore, name string, n int) (*openfgav1.Store, *openfgav1.AuthorizationModel) {
+	t.Helper()
+
+	ctx := context.Background()
+
+	store, err := ds.CreateStore(ctx, &openfgav1.Store{Id: ulid.Make().String(), Name: name})
+	require.NoError(t, err)
+
+	model, err := parser.TransformDSLToProto(testModel)
+	require.NoError(t, err)
+
+	model.Id = ulid.Make().String()
+	require.NoError(t, ds.WriteAuthorizationModel(ctx, store.GetId(), model))
+
+	for i := range n {
+		require.NoError(t, ds.Write(ctx, store.GetId(), nil, storage.Writes{
+			tuple.NewTupleKey(fmt.Sprintf("doc:%d", i), "viewer", "user:1"),
+		}))
+	}
+
+	return store, model
+}
+
+func tupleKeys(t *testing.T, ds storage.OpenFGADatastore, storeID string) []string {
+	t.Helper()
+
+	var keys []string
+
+	err := readTuples(context.Background(), ds, storeID, func(tuples []*openfgav1.Tuple) error {
+		for _, tk := range tuples {
+			keys = append(keys, tuple.TupleKeyToString(tk.GetKey()))
+		}
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	return keys
+}
+
+func TestCopy(t *testing.T) {
+	ctx := context.Background()
+	src, dst := newDatastore(t), newDatastore(t)
+
+	maas, model := addStore(t, src, "maas", 250)
+	tenant, _ := addStore(t, src, "tenant", 0)
+
+	var progress []int
+
+	results, err := Copy(ctx, src, dst, func(store *openfgav1.Store, tuples int) {
+		if store.GetId() == maas.GetId() {
+			progress = append(progress, tuples)
+		}
+	})
+	require.NoError(t, err)
+
+	require.Len(t, results, 2)
+
+	byName := map[string]StoreResult{}
+	for _, r := range results {
+		byName[r.Store.GetName()] = r
+	}
+
+	assert.Equal(t, 1, byName["maas"].Models)
+	assert.Equal(t, 250, byName["maas"].Tuples)
+	assert.Equal(t, 1, byName["tenant"].Models)
+	assert.Equal(t, 0, byName["tenant"].Tuples)
+	assert.Equal(t, []int{100, 200, 250}, progress)
+
+	copied, err := dst.GetStore(ctx, tenant.GetId())
+	require.NoError(t, err)
+	assert.Equal(t, "tenant", copied.GetName())
+
+	copiedModel, err := dst.ReadAuthorizationModel(ctx, maas.GetId(), model.GetId())
+	require.NoError(t, err)
+	assert.Len(t, copiedModel.GetTypeDefinitions(), 2)
+
+	assert.ElementsMatch(t, tupleKeys(t, src, maas.GetId()), tupleKeys(t, dst, maas.GetId()))
+}
+
+func TestCopyKeepsExistingStoresAndModels(t *testing.T) {
+	ctx := context.Background()
+	src, dst := newDatastore(t), newDatastore(t)
+
+	store, model := addStore(t, src, "maas", 3)
+
+	_, err := dst.CreateStore(ctx, store)
+	require.NoError(t, err)
+	require.NoError(t, dst.WriteAuthorizationModel(ctx, store.GetId(), model))
+
+	results, err := Copy(ctx, src, dst, nil)
+	require.NoError(t, err)
+
+	require.Len(t, results, 1)
+	assert.Equal(t, 0, results[0].Models)
+	assert.Equal(t, 3, results[0].Tuples)
+}
+
+func TestCopyTargetNotEmpty(t *testing.T) {
+	ctx := context.Background()
+	src, dst := newDatastore(t), newDatastore(t)
+
+	store, _ := addStore(t, src, "maas", 3)
+
+	_, err := dst.CreateStore(ctx, store)
+	require.NoError(t, err)
+	require.NoError(t, dst.Write(ctx, store.GetId(), nil, storage.Writes{
+		tuple.NewTupleKey("doc:other", "viewer", "user:2"),
+	}))
+
+	_, err = Copy(ctx, src, dst, nil)
+	assert.ErrorIs(t, err, ErrTargetNotEmpty)
+}
+
+func TestCopySourceChanged(t *testing.T) {
+	ctx := context.Background()
+	src, dst := newDatastore(t), newDatastore(t)
+
+	store, _ := addStore(t, src, "maas", 3)
+
+	_, err := Copy(ctx, src, dst, func(*openfgav1.Store, int) {
+		// A write to the source in the middle of the copy.
+		require.NoError(t, src.Write(ctx, store.GetId(), nil, storage.Writes{
+			tuple.NewTupleKey("doc:new", "viewer", "user:2"),
+		}))
+	})
+	assert.ErrorIs(t, err, ErrSourceChanged)
+}
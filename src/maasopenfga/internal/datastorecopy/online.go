@@ -0,0 +1,221 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package datastorecopy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+// maxCatchUpRounds is the number of times the changelog of the source is
+// replayed before giving up on catching up with its writers.
+const maxCatchUpRounds = 100
+
+// ErrNotCaughtUp is returned when the source is written to faster than
+// its changes can be replayed to the copy.
+var ErrNotCaughtUp = errors.New("the copy didn't catch up with the source datastore")
+
+// CopyOnline copies every store of src to dst like Copy, but while src is
+// being written to. The tuples are copied in chunks, then the changes made
+// to src since the copy started are replayed to dst from its changelog,
+// until a round finds no new change and both hold as many tuples. Stores
+// created during the copy make it fail with ErrSourceChanged.
+func CopyOnline(ctx context.Context, src, dst storage.OpenFGADatastore, progress Progress) ([]StoreResult, error) {
+	srcStores, err := listStores(ctx, src)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list source stores: %w", err)
+	}
+
+	// Changes after these positions are replayed, including the ones the
+	// chunked copy already saw.
+	position, err := positions(ctx, src, srcStores)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]StoreResult, 0, len(srcStores))
+
+	for _, store := range srcStores {
+		result, err := copyStore(ctx, src, dst, store, progress)
+		if err != nil {
+			return results, fmt.Errorf("failed to copy store %q (%s): %w", store.GetName(), store.GetId(), err)
+		}
+
+		results = append(results, result)
+	}
+
+	for range maxCatchUpRounds {
+		replayed := 0
+
+		for i, result := range results {
+			storeID := result.Store.GetId()
+
+			n, next, err := replayChanges(ctx, src, dst, storeID, position[storeID])
+			if err != nil {
+				return results, fmt.Errorf("failed to catch up with store %q (%s): %w",
+					result.Store.GetName(), storeID, err)
+			}
+
+			results[i].Changes += n
+			position[storeID] = next
+			replayed += n
+		}
+
+		if replayed > 0 {
+			continue
+		}
+
+		caughtUp, err := verifyOnline(ctx, src, dst, position, results)
+		if err != nil || caughtUp {
+			return results, err
+		}
+	}
+
+	return results, ErrNotCaughtUp
+}
+
+// replayChanges applies the changes made to the store of src after the
+// position to dst, and returns how many there were and the new position.
+// Changes the copy already holds are skipped.
+func replayChanges(ctx context.Context, src, dst storage.OpenFGADatastore, storeID, position string) (int,
+	string, error) {
+	replayed := 0
+
+	for {
+		changes, next, err := src.ReadChanges(ctx, storeID, storage.ReadChangesFilter{}, storage.ReadChangesOptions{
+			Pagination: storage.NewPaginationOptions(pageSize, position),
+		})
+		if errors.Is(err, storage.ErrNotFound) {
+			return replayed, position, nil
+		} else if err != nil {
+			return replayed, position, fmt.Errorf("failed to read changes: %w", err)
+		}
+
+		for _, change := range changes {
+			if err := applyChange(ctx, dst, storeID, change); err != nil {
+				return replayed, position, err
+			}
+		}
+
+		replayed += len(changes)
+		position = next
+	}
+}
+
+func applyChange(ctx context.Context, dst storage.OpenFGADatastore, storeID string,
+	change *openfgav1.TupleChange) error {
+	key := change.GetTupleKey()
+
+	var err error
+
+	switch change.GetOperation() {
+	case openfgav1.TupleOperation_TUPLE_OPERATION_WRITE:
+		err = dst.Write(ctx, storeID, nil, storage.Writes{key},
+			storage.WithOnDuplicateInsert(storage.OnDuplicateInsertIgnore))
+	case openfgav1.TupleOperation_TUPLE_OPERATION_DELETE:
+		err = dst.Write(ctx, storeID, storage.Deletes{tuple.TupleKeyToTupleKeyWithoutCondition(key)}, nil,
+			storage.WithOnMissingDelete(storage.OnMissingDeleteIgnore))
+	default:
+		return fmt.Errorf("unknown operation %s on %s", change.GetOperation(), tuple.TupleKeyToString(key))
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to replay %s of %s: %w", change.GetOperation(), tuple.TupleKeyToString(key), err)
+	}
+
+	return nil
+}
+
+// verifyOnline copies the models written since the copy started and
+// compares the tuples of both datastores. It reports whether the copy
+// caught up, which is only known when src didn't change while counting.
+func verifyOnline(ctx context.Context, src, dst storage.OpenFGADatastore, position map[string]string,
+	results []StoreResult) (bool, error) {
+	srcStores, err := listStores(ctx, src)
+	if err != nil {
+		return false, fmt.Errorf("failed to list source stores: %w", err)
+	}
+
+	if len(srcStores) != len(results) {
+		return false, fmt.Errorf("%w: %d stores were created", ErrSourceChanged, len(srcStores)-len(results))
+	}
+
+	counts := make([]int, len(results))
+
+	for i, result := range results {
+		storeID := result.Store.GetId()
+
+		models, err := copyModels(ctx, src, dst, storeID)
+		if err != nil {
+			return false, err
+		}
+
+		results[i].Models += models
+
+		srcCount, err := countTuples(ctx, src, storeID)
+		if err != nil {
+			return false, fmt.Errorf("failed to count the source tuples of store %s: %w", storeID, err)
+		}
+
+		if counts[i], err = countTuples(ctx, dst, storeID); err != nil {
+			return false, fmt.Errorf("failed to count the tuples of store %s: %w", storeID, err)
+		}
+
+		if srcCount != counts[i] {
+			// The mismatch may be changes made while counting.
+			return false, checkPositions(ctx, src, srcStores, position,
+				fmt.Errorf("%w: store %q holds %d tuples, the source %d",
+					ErrCountMismatch, result.Store.GetName(), counts[i], srcCount))
+		}
+	}
+
+	after, err := positions(ctx, src, srcStores)
+	if err != nil {
+		return false, err
+	}
+
+	if !maps.Equal(position, after) {
+		return false, nil
+	}
+
+	for i := range results {
+		results[i].Tuples = counts[i]
+	}
+
+	return true, nil
+}
+
+// checkPositions returns mismatch when the source didn't change since the
+// positions, and nil when the copy should catch up again.
+func checkPositions(ctx context.Context, src storage.OpenFGADatastore, stores []*openfgav1.Store,
+	position map[string]string, mismatch error) error {
+	after, err := positions(ctx, src, stores)
+	if err != nil {
+		return err
+	}
+
+	if maps.Equal(position, after) {
+		return mismatch
+	}
+
+	return nil
+}
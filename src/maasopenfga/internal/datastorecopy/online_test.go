@@ -0,0 +1,152 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package datastorecopy
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/oklog/ulid/v2"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writingDatastore calls write before every page of tuples read, as if
+// the source was being written to during the copy.
+type writingDatastore struct {
+	storage.OpenFGADatastore
+	write func()
+}
+
+func (ds *writingDatastore) ReadPage(ctx context.Context, store string, filter storage.ReadFilter,
+	options storage.ReadPageOptions) ([]*openfgav1.Tuple, string, error) {
+	if ds.write != nil {
+		ds.write()
+	}
+
+	return ds.OpenFGADatastore.ReadPage(ctx, store, filter, options)
+}
+
+func TestCopyOnline(t *testing.T) {
+	ctx := context.Background()
+	src, dst := newDatastore(t), newDatastore(t)
+
+	store, _ := addStore(t, src, "maas", 250)
+	storeID := store.GetId()
+
+	writes := 0
+	writing := &writingDatastore{OpenFGADatastore: src}
+	writing.write = func() {
+		// Stop writing before the copy is checked.
+		if writes == 3 {
+			return
+		}
+
+		// The memory datastore pages by offset: deleting tuples that
+		// weren't read yet keeps the pages in place.
+		require.NoError(t, src.Write(ctx, storeID,
+			storage.Deletes{tuple.TupleKeyToTupleKeyWithoutCondition(
+				tuple.NewTupleKey(fmt.Sprintf("doc:%d", 249-writes), "viewer", "user:1"))},
+			storage.Writes{tuple.NewTupleKey(fmt.Sprintf("doc:new-%d", writes), "viewer", "user:2")}))
+
+		writes++
+	}
+
+	results, err := CopyOnline(ctx, writing, dst, nil)
+	require.NoError(t, err)
+
+	require.Len(t, results, 1)
+	assert.Equal(t, 250, results[0].Tuples)
+	assert.Equal(t, 6, results[0].Changes)
+
+	assert.ElementsMatch(t, tupleKeys(t, src, storeID), tupleKeys(t, dst, storeID))
+}
+
+func TestCopyOnlineModels(t *testing.T) {
+	ctx := context.Background()
+	src, dst := newDatastore(t), newDatastore(t)
+
+	store, model := addStore(t, src, "maas", 3)
+
+	written := false
+	writing := &writingDatastore{OpenFGADatastore: src}
+	writing.write = func() {
+		if written {
+			return
+		}
+
+		newModel := &openfgav1.AuthorizationModel{
+			Id:              ulid.Make().String(),
+			SchemaVersion:   model.GetSchemaVersion(),
+			TypeDefinitions: model.GetTypeDefinitions(),
+		}
+		require.NoError(t, src.WriteAuthorizationModel(ctx, store.GetId(), newModel))
+
+		written = true
+	}
+
+	results, err := CopyOnline(ctx, writing, dst, nil)
+	require.NoError(t, err)
+
+	require.Len(t, results, 1)
+	assert.Equal(t, 2, results[0].Models)
+	assert.Equal(t, 0, results[0].Changes)
+}
+
+func TestCopyOnlineNewStore(t *testing.T) {
+	ctx := context.Background()
+	src, dst := newDatastore(t), newDatastore(t)
+
+	addStore(t, src, "maas", 3)
+
+	created := false
+	writing := &writingDatastore{OpenFGADatastore: src}
+	writing.write = func() {
+		if !created {
+			created = true
+
+			_, err := src.CreateStore(ctx, &openfgav1.Store{Id: ulid.Make().String(), Name: "tenant"})
+			require.NoError(t, err)
+		}
+	}
+
+	_, err := CopyOnline(ctx, writing, dst, nil)
+	assert.ErrorIs(t, err, ErrSourceChanged)
+}
+
+func TestCopyOnlineNotCaughtUp(t *testing.T) {
+	ctx := context.Background()
+	src, dst := newDatastore(t), newDatastore(t)
+
+	store, _ := addStore(t, src, "maas", 3)
+
+	writes := 0
+	writing := &writingDatastore{OpenFGADatastore: src}
+	writing.write = func() {
+		require.NoError(t, src.Write(ctx, store.GetId(), nil, storage.Writes{
+			tuple.NewTupleKey(fmt.Sprintf("doc:new-%d", writes), "viewer", "user:2"),
+		}))
+
+		writes++
+	}
+
+	_, err := CopyOnline(ctx, writing, dst, nil)
+	assert.ErrorIs(t, err, ErrNotCaughtUp)
+}
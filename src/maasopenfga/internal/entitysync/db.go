@@ -0,0 +1,477 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package entitysync
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5/stdlib"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	tupleUtils "github.com/openfga/openfga/pkg/tuple"
+	"maas.io/core/src/maasgocommon/clock"
+	"maas.io/core/src/maasopenfga/internal/migrations"
+	"maas.io/core/src/maasopenfga/internal/reconcile"
+	"maas.io/core/src/maasopenfga/internal/tuples"
+)
+
+const (
+	administratorsGroup = "Administrators"
+	usersGroup          = "Users"
+
+	closeTimeout = 5 * time.Second
+)
+
+// internalUsers are never given a group, as in migration 2.
+var internalUsers = []string{"MAAS", "maas-init-node"}
+
+func psql() sq.StatementBuilderType {
+	return sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+}
+
+// New returns a Syncer applying entity changes to the store storeID. db
+// must give access to the MAAS tables as well as the openfga schema.
+func New(db *sql.DB, storeID string, options ...Option) *Syncer {
+	s := &Syncer{
+		store:            &sqlStore{db: db, storeID: storeID},
+		listen:           listen(db),
+		clock:            clock.New(),
+		maxRetryInterval: defaultMaxRetryInterval,
+	}
+
+	for _, opt := range options {
+		opt(s)
+	}
+
+	return s
+}
+
+// listen returns a function taking the sync lock and listening on Channel
+// with a dedicated connection of db.
+func listen(db *sql.DB) func(ctx context.Context) (Notifications, error) {
+	return func(ctx context.Context) (Notifications, error) {
+		conn, err := db.Conn(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to acquire database connection: %w", err)
+		}
+
+		var locked bool
+		if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)",
+			lockID).Scan(&locked); err != nil {
+			return nil, errors.Join(fmt.Errorf("failed to acquire sync lock: %w", err), conn.Close())
+		}
+
+		if !locked {
+			return nil, errors.Join(errLocked, conn.Close())
+		}
+
+		n := &pgNotifications{conn: conn}
+
+		if _, err := conn.ExecContext(ctx, "LISTEN "+Channel); err != nil {
+			return nil, errors.Join(fmt.Errorf("failed to listen: %w", err), n.Close())
+		}
+
+		return n, nil
+	}
+}
+
+type pgNotifications struct {
+	conn *sql.Conn
+}
+
+func (n *pgNotifications) Next(ctx context.Context) (string, error) {
+	var payload string
+
+	err := n.conn.Raw(func(driverConn any) error {
+		c, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return fmt.Errorf("unexpected driver connection %T", driverConn)
+		}
+
+		notification, err := c.Conn().WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		payload = notification.Payload
+
+		return nil
+	})
+
+	return payload, err
+}
+
+// Close stops listening and releases the lock before returning the
+// connection to the pool. A connection that fails to do so is discarded,
+// which ends its session.
+func (n *pgNotifications) Close() error {
+	// Use a fresh context, the connection is usually closed once ctx is done.
+	ctx, cancel := context.WithTimeout(context.Background(), closeTimeout)
+	defer cancel()
+
+	_, err := n.conn.ExecContext(ctx, "UNLISTEN "+Channel)
+	if err == nil {
+		_, err = n.conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", lockID)
+	}
+
+	if err != nil {
+		//nolint:errcheck // the connection is broken already
+		n.conn.Raw(func(driverConn any) error {
+			if c, ok := driverConn.(*stdlib.Conn); ok {
+				return c.Close()
+			}
+
+			return nil
+		})
+	}
+
+	return errors.Join(err, n.conn.Close())
+}
+
+type sqlStore struct {
+	db      *sql.DB
+	storeID string
+}
+
+// CatchUp gives the pools created and the users that joined since the last
+// sync their tuples, and removes the tuples of deleted entities.
+func (s *sqlStore) CatchUp(ctx context.Context) error {
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		pools, err := queryIDs(ctx, tx, psql().Select("id").From("maasserver_resourcepool"))
+		if err != nil {
+			return fmt.Errorf("failed to read pools: %w", err)
+		}
+
+		if err := s.addPools(ctx, tx, pools...); err != nil {
+			return err
+		}
+
+		var lastUserID int64
+		if err := tx.QueryRowContext(ctx,
+			"SELECT COALESCE(MAX(id), 0) FROM auth_user").Scan(&lastUserID); err != nil {
+			return fmt.Errorf("failed to read users: %w", err)
+		}
+
+		return s.addUsers(ctx, tx, lastUserID)
+	})
+	if err != nil {
+		return err
+	}
+
+	report, err := reconcile.Run(ctx, s.db, s.storeID, true)
+	if err != nil {
+		return err
+	}
+
+	for _, o := range report.Orphans {
+		log.Printf("entity sync: removed %s %s %s, %s no longer exists",
+			o.Tuple.GetUser(), o.Tuple.GetRelation(), o.Tuple.GetObject(), o.Missing)
+	}
+
+	return nil
+}
+
+func (s *sqlStore) Apply(ctx context.Context, e Event) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		switch {
+		case e.Op == OpDelete:
+			return s.deleteReferences(ctx, tx, tupleUtils.BuildObject(e.Type, strconv.FormatInt(e.ID, 10)))
+		case e.Type == "pool":
+			return s.addPools(ctx, tx, e.ID)
+		default:
+			return s.addUsers(ctx, tx, e.ID)
+		}
+	})
+}
+
+// addPools makes the pools inherit from the maas object.
+func (s *sqlStore) addPools(ctx context.Context, tx *sql.Tx, ids ...int64) error {
+	keys := make([]*openfgav1.TupleKey, 0, len(ids))
+	for _, id := range ids {
+		keys = append(keys, tupleUtils.NewTupleKey(
+			tupleUtils.BuildObject("pool", strconv.FormatInt(id, 10)), "parent", "maas:0"))
+	}
+
+	if _, err := tuples.WriteBatch(ctx, tx, s.storeID, keys); err != nil {
+		return fmt.Errorf("failed to write pool tuples: %w", err)
+	}
+
+	return nil
+}
+
+// addUsers makes the users that joined since the last sync, up to upTo,
+// members of the Administrators or Users group, unless they are a member
+// of a group already. Users that joined before are left alone, as they may
+// have been removed from their groups on purpose.
+func (s *sqlStore) addUsers(ctx context.Context, tx *sql.Tx, upTo int64) error {
+	lastUserID, err := s.lastUserID(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	if upTo <= lastUserID {
+		return nil
+	}
+
+	groups, err := s.defaultGroups(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	stmt, args, err := psql().
+		Select("u.id", "u.is_superuser").
+		From("auth_user u").
+		Where(sq.Gt{"u.id": lastUserID}).
+		Where(sq.LtOrEq{"u.id": upTo}).
+		Where(sq.NotEq{"u.username": internalUsers}).
+		Where(sq.Expr(`NOT EXISTS (SELECT 1 FROM openfga.tuple t
+			WHERE t.store = ? AND t.object_type = 'group' AND t.relation = 'member'
+			AND t._user = 'user:' || u.id)`, s.storeID)).
+		OrderBy("u.id").
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	rows, err := tx.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return fmt.Errorf("failed to read users: %w", err)
+	}
+
+	var keys []*openfgav1.TupleKey
+
+	for rows.Next() {
+		var (
+			id          int64
+			isSuperuser bool
+		)
+
+		if err := rows.Scan(&id, &isSuperuser); err != nil {
+			return errors.Join(err, rows.Close())
+		}
+
+		group := usersGroup
+		if isSuperuser {
+			group = administratorsGroup
+		}
+
+		groupID, ok := groups[group]
+		if !ok {
+			log.Printf("entity sync: not adding user %d to group %q, the group does not exist", id, group)
+			continue
+		}
+
+		keys = append(keys, tupleUtils.NewTupleKey(tupleUtils.BuildObject("group", groupID),
+			"member", tupleUtils.BuildObject("user", strconv.FormatInt(id, 10))))
+	}
+
+	if err := errors.Join(rows.Err(), rows.Close()); err != nil {
+		return fmt.Errorf("failed to read users: %w", err)
+	}
+
+	if _, err := tuples.WriteBatch(ctx, tx, s.storeID, keys); err != nil {
+		return fmt.Errorf("failed to write group memberships: %w", err)
+	}
+
+	stmt, args, err = psql().
+		Update(migrations.EntitySyncTable).
+		Set("last_user_id", upTo).
+		Set("updated_at", sq.Expr("NOW()")).
+		Where(sq.Eq{"store": s.storeID}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, stmt, args...); err != nil {
+		return fmt.Errorf("failed to record sync progress: %w", err)
+	}
+
+	return nil
+}
+
+// lastUserID returns the ID of the last user the sync handled. On the
+// first sync, all the existing users are considered handled.
+func (s *sqlStore) lastUserID(ctx context.Context, tx *sql.Tx) (int64, error) {
+	stmt, args, err := psql().
+		Insert(migrations.EntitySyncTable).
+		Columns("store", "last_user_id", "updated_at").
+		Select(psql().
+			Select().
+			Column(sq.Expr("?", s.storeID)).
+			Column("COALESCE(MAX(id), 0)").
+			Column("NOW()").
+			From("auth_user")).
+		Suffix("ON CONFLICT (store) DO NOTHING").
+		ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.ExecContext(ctx, stmt, args...); err != nil {
+		return 0, fmt.Errorf("failed to initialize sync progress: %w", err)
+	}
+
+	stmt, args, err = psql().
+		Select("last_user_id").
+		From(migrations.EntitySyncTable).
+		Where(sq.Eq{"store": s.storeID}).
+		ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	var id int64
+	if err := tx.QueryRowContext(ctx, stmt, args...).Scan(&id); err != nil {
+		return 0, fmt.Errorf("failed to read sync progress: %w", err)
+	}
+
+	return id, nil
+}
+
+// defaultGroups returns the IDs of the groups new users join, by name.
+func (s *sqlStore) defaultGroups(ctx context.Context, tx *sql.Tx) (map[string]string, error) {
+	stmt, args, err := psql().
+		Select("id::text", "name").
+		From("maasserver_usergroup").
+		Where(sq.Eq{"name": []string{administratorsGroup, usersGroup}}).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read groups: %w", err)
+	}
+
+	groups := map[string]string{}
+
+	for rows.Next() {
+		var id, name string
+		if err := rows.Scan(&id, &name); err != nil {
+			return nil, errors.Join(err, rows.Close())
+		}
+
+		groups[name] = id
+	}
+
+	if err := errors.Join(rows.Err(), rows.Close()); err != nil {
+		return nil, fmt.Errorf("failed to read groups: %w", err)
+	}
+
+	return groups, nil
+}
+
+// deleteReferences deletes the tuples whose user or object is object,
+// including usersets of object, e.g. group:1#member.
+func (s *sqlStore) deleteReferences(ctx context.Context, tx *sql.Tx, object string) error {
+	objectType, objectID := tupleUtils.SplitObject(object)
+
+	stmt, args, err := psql().
+		Select("object_type", "object_id", "relation", "_user").
+		From("openfga.tuple").
+		Where(sq.Eq{"store": s.storeID}).
+		Where(sq.Or{
+			sq.Eq{"object_type": objectType, "object_id": objectID},
+			sq.Eq{"_user": object},
+			sq.Like{"_user": object + "#%"},
+		}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	rows, err := tx.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return fmt.Errorf("failed to read tuples of %s: %w", object, err)
+	}
+
+	var keys []*openfgav1.TupleKey
+
+	for rows.Next() {
+		var objType, objID, relation, user string
+		if err := rows.Scan(&objType, &objID, &relation, &user); err != nil {
+			return errors.Join(err, rows.Close())
+		}
+
+		keys = append(keys, tupleUtils.NewTupleKey(tupleUtils.BuildObject(objType, objID), relation, user))
+	}
+
+	if err := errors.Join(rows.Err(), rows.Close()); err != nil {
+		return fmt.Errorf("failed to read tuples of %s: %w", object, err)
+	}
+
+	for _, key := range keys {
+		if _, err := tuples.Delete(ctx, tx, s.storeID, key); err != nil {
+			return err
+		}
+	}
+
+	if len(keys) > 0 {
+		log.Printf("entity sync: removed %d tuples of %s", len(keys), object)
+	}
+
+	return nil
+}
+
+func (s *sqlStore) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	if err := fn(tx); err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func queryIDs(ctx context.Context, tx *sql.Tx, query sq.SelectBuilder) ([]int64, error) {
+	stmt, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []int64
+
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, errors.Join(err, rows.Close())
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, errors.Join(rows.Err(), rows.Close())
+}
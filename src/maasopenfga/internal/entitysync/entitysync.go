@@ -0,0 +1,197 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package entitysync keeps the tuples derived from MAAS entities up to date
+// as regiond creates and deletes them.
+//
+// regiond triggers notify the sys_openfga_sync channel when users and
+// resource pools are created, and when users, pools and groups are deleted.
+// The Syncer listens on the channel and writes the tuples a new entity
+// needs, or deletes the tuples referencing a deleted one. Notifications are
+// lost while nobody listens, so every time it starts listening the Syncer
+// first catches up, bringing the tuples in line with all entities. Events
+// are idempotent, they are applied at least once.
+package entitysync
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"maas.io/core/src/maasgocommon/clock"
+	"maas.io/core/src/maasgocommon/retry"
+)
+
+// Channel is the notification channel of the regiond triggers.
+const Channel = "sys_openfga_sync"
+
+const (
+	// lockID is the Postgres advisory lock key ensuring a single region
+	// controller syncs at a time ("maasosyn").
+	lockID int64 = 0x6d6161736f73796e
+
+	defaultMaxRetryInterval = time.Minute
+)
+
+// Operations notified by the triggers.
+const (
+	OpInsert = "insert"
+	OpDelete = "delete"
+)
+
+var errLocked = errors.New("entity sync is running on another region controller")
+
+// Event is a change of a MAAS entity.
+type Event struct {
+	// Type is the OpenFGA type of the entity, e.g. pool.
+	Type string `json:"type"`
+	Op   string `json:"op"`
+	ID   int64  `json:"id"`
+}
+
+func (e Event) String() string {
+	return fmt.Sprintf("%s of %s:%d", e.Op, e.Type, e.ID)
+}
+
+// ParseEvent parses the payload of a notification.
+func ParseEvent(payload string) (Event, error) {
+	var e Event
+
+	if err := json.Unmarshal([]byte(payload), &e); err != nil {
+		return e, fmt.Errorf("invalid notification payload: %w", err)
+	}
+
+	switch {
+	case e.Type == "user" && (e.Op == OpInsert || e.Op == OpDelete):
+	case e.Type == "pool" && (e.Op == OpInsert || e.Op == OpDelete):
+	case e.Type == "group" && e.Op == OpDelete:
+	default:
+		return e, fmt.Errorf("unsupported event %s", e)
+	}
+
+	return e, nil
+}
+
+// Store applies entity changes to the tuples.
+type Store interface {
+	// CatchUp brings the tuples in line with all entities.
+	CatchUp(ctx context.Context) error
+	// Apply applies the tuple changes for e. Applying an event twice, or
+	// an event about an entity that changed since, must be harmless.
+	Apply(ctx context.Context, e Event) error
+}
+
+// Notifications are the notifications received on Channel.
+type Notifications interface {
+	// Next waits for the next notification and returns its payload.
+	Next(ctx context.Context) (string, error)
+	Close() error
+}
+
+// Syncer applies entity changes notified by regiond.
+type Syncer struct {
+	store            Store
+	listen           func(ctx context.Context) (Notifications, error)
+	clock            clock.Clock
+	maxRetryInterval time.Duration
+}
+
+// Option allows to set additional Syncer options
+type Option func(*Syncer)
+
+// WithMaxRetryInterval caps the delay between attempts to listen (default:
+// 1m)
+func WithMaxRetryInterval(d time.Duration) Option {
+	return func(s *Syncer) {
+		if d > 0 {
+			s.maxRetryInterval = d
+		}
+	}
+}
+
+// WithClock sets the clock used to wait between attempts (default: the
+// system clock)
+func WithClock(clk clock.Clock) Option {
+	return func(s *Syncer) {
+		s.clock = clk
+	}
+}
+
+// Run listens for entity changes and applies them until ctx is done.
+// Failures, including failures to apply an event, are retried by listening
+// again, which catches up on missed events.
+func (s *Syncer) Run(ctx context.Context) error {
+	policy := retry.NewBackoff(retry.WithMaxInterval(s.maxRetryInterval))
+
+	for {
+		err := s.run(ctx, policy)
+
+		switch {
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case errors.Is(err, errLocked):
+		default:
+			log.Printf("entity sync failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-s.clock.After(policy.Next()):
+		}
+	}
+}
+
+func (s *Syncer) run(ctx context.Context, policy *retry.Backoff) error {
+	notifications, err := s.listen(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = s.sync(ctx, notifications, policy)
+
+	return errors.Join(err, notifications.Close())
+}
+
+// sync catches up, then applies the events received until one fails.
+func (s *Syncer) sync(ctx context.Context, notifications Notifications, policy *retry.Backoff) error {
+	// Listening started first, changes made while catching up are notified
+	// as well.
+	if err := s.store.CatchUp(ctx); err != nil {
+		return fmt.Errorf("failed to catch up: %w", err)
+	}
+
+	policy.Reset()
+
+	for {
+		payload, err := notifications.Next(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to receive notification: %w", err)
+		}
+
+		event, err := ParseEvent(payload)
+		if err != nil {
+			log.Printf("entity sync: ignoring notification %q: %v", payload, err)
+			continue
+		}
+
+		if err := s.store.Apply(ctx, event); err != nil {
+			return fmt.Errorf("failed to apply %s: %w", event, err)
+		}
+	}
+}
@@ -0,0 +1,237 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package entitysync
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maas.io/core/src/maasgocommon/clock"
+)
+
+func TestParseEvent(t *testing.T) {
+	testcases := map[string]struct {
+		in  string
+		out Event
+		err bool
+	}{
+		"user insert": {
+			in:  `{"type": "user", "op": "insert", "id": 3}`,
+			out: Event{Type: "user", Op: OpInsert, ID: 3},
+		},
+		"pool delete": {
+			in:  `{"type": "pool", "op": "delete", "id": 2}`,
+			out: Event{Type: "pool", Op: OpDelete, ID: 2},
+		},
+		"group delete": {
+			in:  `{"type": "group", "op": "delete", "id": 4}`,
+			out: Event{Type: "group", Op: OpDelete, ID: 4},
+		},
+		"group insert": {
+			in:  `{"type": "group", "op": "insert", "id": 4}`,
+			err: true,
+		},
+		"unknown type": {
+			in:  `{"type": "zone", "op": "delete", "id": 1}`,
+			err: true,
+		},
+		"invalid json": {
+			in:  `user:1`,
+			err: true,
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			e, err := ParseEvent(tc.in)
+			if tc.err {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.out, e)
+		})
+	}
+}
+
+type fakeNotifications struct {
+	payloads chan string
+	closed   atomic.Bool
+}
+
+func (n *fakeNotifications) Next(ctx context.Context) (string, error) {
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case p := <-n.payloads:
+		return p, nil
+	}
+}
+
+func (n *fakeNotifications) Close() error {
+	n.closed.Store(true)
+	return nil
+}
+
+// fakeStore reports catch-ups and applied events on calls.
+type fakeStore struct {
+	calls    chan string
+	applyErr error
+}
+
+func (s *fakeStore) CatchUp(context.Context) error {
+	s.calls <- "catch-up"
+	return nil
+}
+
+func (s *fakeStore) Apply(_ context.Context, e Event) error {
+	s.calls <- e.String()
+
+	err := s.applyErr
+	s.applyErr = nil
+
+	return err
+}
+
+func newTestSyncer(store Store, listen func(ctx context.Context) (Notifications, error),
+	clk clock.Clock) *Syncer {
+	return &Syncer{
+		store:            store,
+		listen:           listen,
+		clock:            clk,
+		maxRetryInterval: time.Second,
+	}
+}
+
+func runSyncer(t *testing.T, s *Syncer) context.CancelFunc {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+
+	go func() { done <- s.Run(ctx) }()
+
+	t.Cleanup(func() {
+		cancel()
+		assert.ErrorIs(t, <-done, context.Canceled)
+	})
+
+	return cancel
+}
+
+func nextCall(t *testing.T, calls <-chan string) string {
+	t.Helper()
+
+	select {
+	case c := <-calls:
+		return c
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the store")
+		return ""
+	}
+}
+
+func TestSyncerRun(t *testing.T) {
+	store := &fakeStore{calls: make(chan string)}
+	notifications := &fakeNotifications{payloads: make(chan string)}
+	s := newTestSyncer(store, func(context.Context) (Notifications, error) {
+		return notifications, nil
+	}, clock.NewFake(time.Now()))
+
+	cancel := runSyncer(t, s)
+
+	assert.Equal(t, "catch-up", nextCall(t, store.calls))
+
+	notifications.payloads <- `{"type": "user", "op": "insert", "id": 1}`
+
+	assert.Equal(t, "insert of user:1", nextCall(t, store.calls))
+
+	// Unsupported notifications are skipped.
+	notifications.payloads <- `{"type": "zone", "op": "insert", "id": 1}`
+	notifications.payloads <- `{"type": "pool", "op": "delete", "id": 2}`
+
+	assert.Equal(t, "delete of pool:2", nextCall(t, store.calls))
+
+	cancel()
+	assert.Eventually(t, notifications.closed.Load, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestSyncerRunApplyFailure(t *testing.T) {
+	store := &fakeStore{calls: make(chan string), applyErr: errors.New("boom")}
+	clk := clock.NewFake(time.Now())
+
+	var listens []*fakeNotifications
+
+	s := newTestSyncer(store, func(context.Context) (Notifications, error) {
+		n := &fakeNotifications{payloads: make(chan string, 1)}
+		listens = append(listens, n)
+
+		return n, nil
+	}, clk)
+
+	runSyncer(t, s)
+
+	assert.Equal(t, "catch-up", nextCall(t, store.calls))
+
+	listens[0].payloads <- `{"type": "user", "op": "insert", "id": 1}`
+
+	assert.Equal(t, "insert of user:1", nextCall(t, store.calls))
+
+	// The failed event is caught up on after listening again.
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	require.NoError(t, clk.BlockUntil(ctx, 1))
+	assert.True(t, listens[0].closed.Load())
+
+	clk.Advance(time.Second)
+
+	assert.Equal(t, "catch-up", nextCall(t, store.calls))
+	assert.Len(t, listens, 2)
+}
+
+func TestSyncerRunLocked(t *testing.T) {
+	store := &fakeStore{calls: make(chan string)}
+	clk := clock.NewFake(time.Now())
+
+	var attempts atomic.Int32
+
+	s := newTestSyncer(store, func(context.Context) (Notifications, error) {
+		if attempts.Add(1) == 1 {
+			return nil, errLocked
+		}
+
+		return &fakeNotifications{payloads: make(chan string)}, nil
+	}, clk)
+
+	runSyncer(t, s)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Nothing is caught up on until the lock is taken.
+	require.NoError(t, clk.BlockUntil(ctx, 1))
+	clk.Advance(time.Second)
+
+	assert.Equal(t, "catch-up", nextCall(t, store.calls))
+	assert.Equal(t, int32(2), attempts.Load())
+}
@@ -0,0 +1,144 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package legacyimport
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	tupleUtils "github.com/openfga/openfga/pkg/tuple"
+	"maas.io/core/src/maasopenfga/internal/tuples"
+)
+
+func psql() sq.StatementBuilderType {
+	return sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+}
+
+type sqlSource struct {
+	db *sql.DB
+}
+
+// NewSource returns a Source reading the MAAS tables of db.
+func NewSource(db *sql.DB) Source {
+	return &sqlSource{db: db}
+}
+
+func (s *sqlSource) Users(ctx context.Context) ([]User, error) {
+	stmt, args, err := psql().
+		Select("id", "username", "is_superuser").
+		From("auth_user").
+		OrderBy("id").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var users []User
+
+	for rows.Next() {
+		var u User
+		if err := rows.Scan(&u.ID, &u.Username, &u.IsSuperuser); err != nil {
+			return nil, errors.Join(err, rows.Close())
+		}
+
+		users = append(users, u)
+	}
+
+	return users, errors.Join(rows.Err(), rows.Close())
+}
+
+func (s *sqlSource) GroupID(ctx context.Context, name string) (int64, bool, error) {
+	stmt, args, err := psql().
+		Select("id").
+		From("maasserver_usergroup").
+		Where(sq.Eq{"name": name}).
+		ToSql()
+	if err != nil {
+		return 0, false, err
+	}
+
+	var id int64
+
+	err = s.db.QueryRowContext(ctx, stmt, args...).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+
+	return id, err == nil, err
+}
+
+func (s *sqlSource) PoolIDs(ctx context.Context) ([]int64, error) {
+	stmt, args, err := psql().
+		Select("id").
+		From("maasserver_resourcepool").
+		OrderBy("id").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []int64
+
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, errors.Join(err, rows.Close())
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, errors.Join(rows.Err(), rows.Close())
+}
+
+// Apply writes the tuples to the store in a single transaction and returns
+// how many were missing. Tuples that already exist are left untouched.
+func Apply(ctx context.Context, db *sql.DB, storeID string, toWrite []Tuple) (int, error) {
+	keys := make([]*openfgav1.TupleKey, 0, len(toWrite))
+	for _, t := range toWrite {
+		keys = append(keys, tupleUtils.NewTupleKey(t.Object, t.Relation, t.User))
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	written, err := tuples.WriteBatch(ctx, tx, storeID, keys)
+	if err != nil {
+		return 0, errors.Join(err, tx.Rollback())
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return written, nil
+}
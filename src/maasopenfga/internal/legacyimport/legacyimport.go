@@ -0,0 +1,231 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package legacyimport translates the access rules MAAS applied before
+// OpenFGA, derived from the Django users table, into tuples.
+//
+// Without OpenFGA, superusers administer MAAS and other users may view and
+// deploy machines of every resource pool. The equivalent tuples are:
+//
+//   - the relations of the Administrators and Users groups on maas:0,
+//   - the membership of superusers in Administrators, and of other users in
+//     Users,
+//   - the maas:0 parent of every resource pool, through which pools inherit
+//     the group relations.
+//
+// These are the tuples seeded by the migrations when OpenFGA is first
+// installed; importing again adds those missing since, e.g. after a
+// restore of the MAAS tables.
+package legacyimport
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"text/tabwriter"
+
+	tupleUtils "github.com/openfga/openfga/pkg/tuple"
+)
+
+const (
+	administratorsGroup = "Administrators"
+	usersGroup          = "Users"
+)
+
+// groupRelations are the relations each default group has on maas:0.
+var groupRelations = map[string][]string{
+	administratorsGroup: {
+		"can_edit_machines", "can_edit_global_entities", "can_edit_controllers",
+		"can_edit_identities", "can_edit_configurations", "can_edit_notifications",
+		"can_edit_boot_entities", "can_edit_license_keys", "can_view_devices",
+		"can_view_ipaddresses",
+	},
+	usersGroup: {"can_deploy_machines", "can_view_available_machines", "can_view_global_entities"},
+}
+
+// internalUsers are the users MAAS creates for its own use.
+var internalUsers = []string{"MAAS", "maas-init-node"}
+
+// User is a row of the Django users table.
+type User struct {
+	Username    string
+	ID          int64
+	IsSuperuser bool
+}
+
+// Source reads the legacy MAAS tables.
+type Source interface {
+	// Users returns every user, including internal ones.
+	Users(ctx context.Context) ([]User, error)
+	// GroupID returns the ID of the MAAS group with the given name, if
+	// it exists.
+	GroupID(ctx context.Context, name string) (int64, bool, error)
+	// PoolIDs returns the IDs of the resource pools.
+	PoolIDs(ctx context.Context) ([]int64, error)
+}
+
+// Entry is a legacy rule and the tuple it translates to.
+type Entry struct {
+	// Subject is what the rule applies to, e.g. "user admin".
+	Subject string
+	// Rule describes the legacy rule.
+	Rule string
+	// Tuple is the generated tuple, unset for skipped entries.
+	Tuple *Tuple
+	// Reason explains why the entry was skipped.
+	Reason string
+}
+
+// Tuple is a tuple to write.
+type Tuple struct {
+	User     string
+	Relation string
+	Object   string
+}
+
+// Report lists the tuples generated from the legacy rules.
+type Report struct {
+	Entries []Entry
+}
+
+// Tuples returns the distinct tuples of the report.
+func (r *Report) Tuples() []Tuple {
+	var tuples []Tuple
+
+	for _, e := range r.Entries {
+		if e.Tuple != nil && !slices.Contains(tuples, *e.Tuple) {
+			tuples = append(tuples, *e.Tuple)
+		}
+	}
+
+	return tuples
+}
+
+// Skipped returns how many entries were not translated.
+func (r *Report) Skipped() int {
+	n := 0
+
+	for _, e := range r.Entries {
+		if e.Tuple == nil {
+			n++
+		}
+	}
+
+	return n
+}
+
+// Write writes the report as a table.
+func (r *Report) Write(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SUBJECT\tRULE\tRESULT")
+
+	for _, e := range r.Entries {
+		result := "skipped: " + e.Reason
+		if e.Tuple != nil {
+			result = fmt.Sprintf("%s %s %s", e.Tuple.User, e.Tuple.Relation, e.Tuple.Object)
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", e.Subject, e.Rule, result)
+	}
+
+	return tw.Flush()
+}
+
+// Convert translates the legacy rules read from src.
+func Convert(ctx context.Context, src Source) (*Report, error) {
+	report := &Report{}
+
+	groups := make(map[string]string, len(groupRelations))
+
+	for _, name := range []string{administratorsGroup, usersGroup} {
+		id, ok, err := src.GroupID(ctx, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up group %q: %w", name, err)
+		}
+
+		subject := "group " + name
+		rule := "default group"
+
+		if !ok {
+			report.Entries = append(report.Entries, Entry{
+				Subject: subject,
+				Rule:    rule,
+				Reason:  "the group does not exist",
+			})
+
+			continue
+		}
+
+		groupID := strconv.FormatInt(id, 10)
+		groups[name] = groupID
+
+		for _, relation := range groupRelations[name] {
+			report.Entries = append(report.Entries, Entry{
+				Subject: subject,
+				Rule:    rule,
+				Tuple:   &Tuple{User: "group:" + groupID + "#member", Relation: relation, Object: "maas:0"},
+			})
+		}
+	}
+
+	users, err := src.Users(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read users: %w", err)
+	}
+
+	for _, u := range users {
+		if slices.Contains(internalUsers, u.Username) {
+			continue
+		}
+
+		entry := Entry{Subject: "user " + u.Username, Rule: "user"}
+
+		group := usersGroup
+		if u.IsSuperuser {
+			entry.Rule = "superuser"
+			group = administratorsGroup
+		}
+
+		if groupID, ok := groups[group]; ok {
+			entry.Tuple = &Tuple{
+				User:     tupleUtils.BuildObject("user", strconv.FormatInt(u.ID, 10)),
+				Relation: "member",
+				Object:   tupleUtils.BuildObject("group", groupID),
+			}
+		} else {
+			entry.Reason = fmt.Sprintf("group %q does not exist", group)
+		}
+
+		report.Entries = append(report.Entries, entry)
+	}
+
+	pools, err := src.PoolIDs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read resource pools: %w", err)
+	}
+
+	for _, id := range pools {
+		object := tupleUtils.BuildObject("pool", strconv.FormatInt(id, 10))
+		report.Entries = append(report.Entries, Entry{
+			Subject: "pool " + strconv.FormatInt(id, 10),
+			Rule:    "resource pool",
+			Tuple:   &Tuple{User: "maas:0", Relation: "parent", Object: object},
+		})
+	}
+
+	return report, nil
+}
@@ -0,0 +1,127 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package legacyimport
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authzmodel "maas.io/core/src/maasopenfga/internal/model"
+)
+
+type fakeSource struct {
+	groups map[string]int64
+	users  []User
+	pools  []int64
+}
+
+func (s *fakeSource) Users(_ context.Context) ([]User, error) {
+	return s.users, nil
+}
+
+func (s *fakeSource) GroupID(_ context.Context, name string) (int64, bool, error) {
+	id, ok := s.groups[name]
+	return id, ok, nil
+}
+
+func (s *fakeSource) PoolIDs(_ context.Context) ([]int64, error) {
+	return s.pools, nil
+}
+
+func TestConvert(t *testing.T) {
+	src := &fakeSource{
+		groups: map[string]int64{"Administrators": 1, "Users": 2},
+		users: []User{
+			{ID: 1, Username: "MAAS", IsSuperuser: true},
+			{ID: 2, Username: "admin", IsSuperuser: true},
+			{ID: 3, Username: "alice"},
+		},
+		pools: []int64{0, 4},
+	}
+
+	report, err := Convert(t.Context(), src)
+	require.NoError(t, err)
+	assert.Zero(t, report.Skipped())
+
+	tuples := report.Tuples()
+	assert.Contains(t, tuples, Tuple{User: "group:1#member", Relation: "can_edit_machines", Object: "maas:0"})
+	assert.Contains(t, tuples, Tuple{User: "group:2#member", Relation: "can_deploy_machines", Object: "maas:0"})
+	assert.Equal(t, []Tuple{
+		{User: "user:2", Relation: "member", Object: "group:1"},
+		{User: "user:3", Relation: "member", Object: "group:2"},
+		{User: "maas:0", Relation: "parent", Object: "pool:0"},
+		{User: "maas:0", Relation: "parent", Object: "pool:4"},
+	}, tuples[len(tuples)-4:])
+
+	for _, tuple := range tuples {
+		assert.NotEqual(t, "user:1", tuple.User, "internal users must not be imported")
+	}
+}
+
+func TestConvertMissingGroup(t *testing.T) {
+	src := &fakeSource{
+		groups: map[string]int64{"Users": 2},
+		users: []User{
+			{ID: 2, Username: "admin", IsSuperuser: true},
+			{ID: 3, Username: "alice"},
+		},
+	}
+
+	report, err := Convert(t.Context(), src)
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Skipped())
+
+	var out bytes.Buffer
+	require.NoError(t, report.Write(&out))
+	assert.Contains(t, out.String(), "group Administrators  default group  skipped: the group does not exist\n")
+	assert.Contains(t, out.String(), "user admin            superuser      skipped: group \"Administrators\" does not exist\n")
+	assert.Contains(t, out.String(), "user alice            user           user:3 member group:2\n")
+}
+
+// TestRelationsInModel checks that the generated tuples are valid for the
+// latest authorization model.
+func TestRelationsInModel(t *testing.T) {
+	versions := authzmodel.Versions()
+
+	model, err := authzmodel.Load(versions[len(versions)-1])
+	require.NoError(t, err)
+
+	relations := make(map[string]map[string]bool)
+
+	for _, td := range model.GetTypeDefinitions() {
+		relations[td.GetType()] = make(map[string]bool)
+		for relation := range td.GetRelations() {
+			relations[td.GetType()][relation] = true
+		}
+	}
+
+	report, err := Convert(t.Context(), &fakeSource{
+		groups: map[string]int64{"Administrators": 1, "Users": 2},
+		users:  []User{{ID: 2, Username: "admin"}},
+		pools:  []int64{0},
+	})
+	require.NoError(t, err)
+
+	for _, tuple := range report.Tuples() {
+		objectType, _, _ := strings.Cut(tuple.Object, ":")
+		assert.True(t, relations[objectType][tuple.Relation],
+			"relation %s is not defined on %s", tuple.Relation, objectType)
+	}
+}
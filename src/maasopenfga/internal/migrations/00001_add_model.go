@@ -21,9 +21,7 @@ import (
 	"fmt"
 
 	sq "github.com/Masterminds/squirrel"
-	parser "github.com/openfga/language/pkg/go/transformer"
-	"github.com/pressly/goose/v3"
-	"google.golang.org/protobuf/proto"
+	"maas.io/core/src/maasopenfga/internal/modelupgrade"
 )
 
 const (
@@ -31,7 +29,8 @@ const (
 )
 
 func init() {
-	goose.AddMigrationContext(Up00001, Down00001)
+	register(1, Up00001, Down00001)
+	registerModel("v1", storeID)
 }
 
 func createStore(ctx context.Context, tx *sql.Tx) error {
@@ -53,102 +52,78 @@ func createStore(ctx context.Context, tx *sql.Tx) error {
 	return err
 }
 
-func createAuthorizationModel(ctx context.Context, tx *sql.Tx) error {
-	modelDSL := `
-model 
-  schema 1.1
-
-type user
-
-type group
-  relations
-    define member: [user]
-
-type maas
-  relations
-    define can_edit_machines: [group#member]
-    define can_deploy_machines: [group#member] or can_edit_machines
-    define can_view_machines: [group#member] or can_edit_machines
-    define can_view_available_machines: [group#member] or can_edit_machines or can_view_machines
-    
-    define can_edit_global_entities: [group#member] 
-    define can_view_global_entities: [group#member] or can_edit_global_entities
-    
-    define can_edit_controllers: [group#member]
-    define can_view_controllers: [group#member] or can_edit_controllers
-
-    define can_edit_identities: [group#member]
-    define can_view_identities: [group#member] or can_edit_identities
-
-    define can_edit_configurations: [group#member]
-    define can_view_configurations: [group#member] or can_edit_configurations
-
-    define can_edit_notifications: [group#member]
-    define can_view_notifications: [group#member] or can_edit_notifications
-
-    define can_edit_boot_entities: [group#member]
-    define can_view_boot_entities: [group#member] or can_edit_boot_entities
-
-    define can_edit_license_keys: [group#member]
-    define can_view_license_keys: [group#member] or can_edit_license_keys
-
-    define can_view_devices: [group#member]
-
-    define can_view_ipaddresses: [group#member]
-
-type pool
-  relations
-    define parent: [maas]
-
-    define can_edit_machines: [group#member] or can_edit_machines from parent
-    define can_deploy_machines: [group#member] or can_edit_machines or can_deploy_machines from parent
-    define can_view_machines: [group#member] or can_edit_machines or can_view_machines from parent
-    define can_view_available_machines: [group#member] or can_edit_machines or can_view_machines or can_view_available_machines from parent
-`
+// createAuthorizationModel installs the given model version in the MAAS
+// store under modelID. OpenFGA considers the model with the greatest ID to
+// be the latest one.
+func createAuthorizationModel(ctx context.Context, tx *sql.Tx, version, modelID string) error {
+	return modelupgrade.Install(ctx, tx, storeID, version, modelID)
+}
 
-	model, err := parser.TransformDSLToProto(modelDSL)
-	if err != nil {
-		return err
+func Up00001(ctx context.Context, tx *sql.Tx) error {
+	if err := createStore(ctx, tx); err != nil {
+		return fmt.Errorf("failed to create store: %w", err)
 	}
 
-	// The ID in the protobuf and in the database must be set and match, otherwise openfga will not work properly with this model.
-	model.Id = storeID
-
-	pbdata, err := proto.Marshal(model)
-	if err != nil {
-		return err
+	if err := createAuthorizationModel(ctx, tx, "v1", storeID); err != nil {
+		return fmt.Errorf("failed to create authorization model: %w", err)
 	}
 
+	return nil
+}
+
+// deleteTuples deletes the tuples of the MAAS store matching where.
+func deleteTuples(ctx context.Context, tx *sql.Tx, where sq.Sqlizer) error {
 	stmt, args, err := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
-		Insert("openfga.authorization_model").
-		Columns("store", "authorization_model_id", "schema_version", "type", "type_definition", "serialized_protobuf").
-		Values(storeID, model.GetId(), model.GetSchemaVersion(), "", nil, pbdata).
+		Delete("openfga.tuple").
+		Where(sq.Eq{"store": storeID}).
+		Where(where).
 		ToSql()
 	if err != nil {
 		return err
 	}
 
-	_, err = tx.ExecContext(
-		ctx,
-		stmt,
-		args...,
-	)
+	_, err = tx.ExecContext(ctx, stmt, args...)
 
 	return err
 }
 
-func Up00001(ctx context.Context, tx *sql.Tx) error {
-	if err := createStore(ctx, tx); err != nil {
-		return fmt.Errorf("failed to create store: %w", err)
+func deleteAuthorizationModel(ctx context.Context, tx *sql.Tx, modelID string) error {
+	return modelupgrade.Uninstall(ctx, tx, storeID, modelID)
+}
+
+// Down00001 deletes the store with everything it still holds.
+func Down00001(ctx context.Context, tx *sql.Tx) error {
+	return deleteStore(ctx, tx, storeID)
+}
+
+// deleteStore deletes a store with its models, tuples, changelog and
+// assertions.
+func deleteStore(ctx context.Context, tx *sql.Tx, id string) error {
+	for _, table := range []string{"openfga.tuple", "openfga.changelog", "openfga.assertion", "openfga.authorization_model"} {
+		stmt, args, err := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+			Delete(table).
+			Where(sq.Eq{"store": id}).
+			ToSql()
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, stmt, args...); err != nil {
+			return fmt.Errorf("failed to delete from %s: %w", table, err)
+		}
 	}
 
-	if err := createAuthorizationModel(ctx, tx); err != nil {
-		return fmt.Errorf("failed to create authorization model: %w", err)
+	stmt, args, err := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+		Delete("openfga.store").
+		Where(sq.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return err
 	}
 
-	return nil
-}
+	if _, err := tx.ExecContext(ctx, stmt, args...); err != nil {
+		return fmt.Errorf("failed to delete store: %w", err)
+	}
 
-func Down00001(ctx context.Context, tx *sql.Tx) error {
-	return fmt.Errorf("downgrade not supported")
+	return nil
 }
@@ -24,7 +24,6 @@ import (
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/oklog/ulid/v2"
-	"github.com/pressly/goose/v3"
 )
 
 const (
@@ -32,8 +31,16 @@ const (
 	usersGroupName         = "Users"
 )
 
+var (
+	administratorRelations = []string{"can_edit_machines", "can_edit_global_entities", "can_edit_controllers", "can_edit_identities",
+		"can_edit_configurations", "can_edit_notifications", "can_edit_boot_entities", "can_edit_license_keys",
+		"can_view_devices",
+		"can_view_ipaddresses"}
+	usersRelations = []string{"can_deploy_machines", "can_view_deployable_machines", "can_view_global_entities"}
+)
+
 func init() {
-	goose.AddMigrationContext(Up00002, Down00002)
+	register(2, Up00002, Down00002)
 }
 
 // Get group id for a given group name.
@@ -263,6 +270,25 @@ func addUsersToGroup(ctx context.Context, tx *sql.Tx, administratorGroupID int64
 	return nil
 }
 
+// deleteGroup deletes the given relations of a group to the maas:0 object,
+// and the memberships of the group.
+func deleteGroup(ctx context.Context, tx *sql.Tx, groupID int64, relations []string) error {
+	if err := deleteTuples(ctx, tx, sq.Eq{
+		"_user":       fmt.Sprintf("group:%d#member", groupID),
+		"relation":    relations,
+		"object_type": "maas",
+		"object_id":   "0",
+	}); err != nil {
+		return err
+	}
+
+	return deleteTuples(ctx, tx, sq.Eq{
+		"relation":    "member",
+		"object_type": "group",
+		"object_id":   strconv.FormatInt(groupID, 10),
+	})
+}
+
 func Up00002(ctx context.Context, tx *sql.Tx) error {
 	if err := createPools(ctx, tx); err != nil {
 		return fmt.Errorf("failed to create pools: %w", err)
@@ -280,15 +306,12 @@ func Up00002(ctx context.Context, tx *sql.Tx) error {
 		return fmt.Errorf("failed to get users group id: %w", err)
 	}
 
-	relations := []string{"can_edit_machines", "can_edit_global_entities", "can_edit_controllers", "can_edit_identities",
-		"can_edit_configurations", "can_edit_notifications", "can_edit_boot_entities", "can_edit_license_keys",
-		"can_view_devices",
-		"can_view_ipaddresses"}
+	relations := administratorRelations
 	if err := createGroup(ctx, tx, administratorGroupID, &relations); err != nil {
 		return fmt.Errorf("failed to create administrators group: %w", err)
 	}
 
-	relations = []string{"can_deploy_machines", "can_view_deployable_machines", "can_view_global_entities"}
+	relations = usersRelations
 	if err := createGroup(ctx, tx, usersGroupID, &relations); err != nil {
 		return fmt.Errorf("failed to create users group: %w", err)
 	}
@@ -300,6 +323,36 @@ func Up00002(ctx context.Context, tx *sql.Tx) error {
 	return nil
 }
 
+// Down00002 deletes the tuples seeded by Up00002. Groups that no longer
+// exist are skipped.
 func Down00002(ctx context.Context, tx *sql.Tx) error {
-	return fmt.Errorf("downgrade not supported")
+	groups := []struct {
+		name      string
+		relations []string
+	}{
+		{administratorGroupName, administratorRelations},
+		{usersGroupName, usersRelations},
+	}
+
+	for _, group := range groups {
+		groupID, err := getGroupID(ctx, tx, group.name)
+		if err != nil {
+			log.Printf("skipping group %q: %v", group.name, err)
+			continue
+		}
+
+		if err := deleteGroup(ctx, tx, groupID, group.relations); err != nil {
+			return fmt.Errorf("failed to delete group %q: %w", group.name, err)
+		}
+	}
+
+	if err := deleteTuples(ctx, tx, sq.Eq{
+		"_user":       "maas:0",
+		"relation":    "parent",
+		"object_type": "pool",
+	}); err != nil {
+		return fmt.Errorf("failed to delete pools: %w", err)
+	}
+
+	return nil
 }
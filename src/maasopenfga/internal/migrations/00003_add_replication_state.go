@@ -0,0 +1,54 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+func init() {
+	register(3, Up00003, Down00003)
+}
+
+// Up00003 adds the table tracking tuple replication from a primary region
+// cluster. It stays empty unless replication is configured.
+func Up00003(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+CREATE TABLE openfga.maas_replication (
+	store TEXT PRIMARY KEY,
+	role TEXT NOT NULL,
+	primary_url TEXT NOT NULL,
+	continuation_token TEXT NOT NULL DEFAULT '',
+	start_time TIMESTAMPTZ,
+	last_change_at TIMESTAMPTZ,
+	updated_at TIMESTAMPTZ NOT NULL
+)`)
+	if err != nil {
+		return fmt.Errorf("failed to create replication table: %w", err)
+	}
+
+	return nil
+}
+
+func Down00003(ctx context.Context, tx *sql.Tx) error {
+	if _, err := tx.ExecContext(ctx, "DROP TABLE openfga.maas_replication"); err != nil {
+		return fmt.Errorf("failed to drop replication table: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+// modelV2ID is the ID of the authorization model adding the banned
+// relation. It must sort after the ID of the previous model.
+const modelV2ID = "00000000000000000000000002"
+
+func init() {
+	register(4, Up00004, Down00004)
+	registerModel("v2", modelV2ID)
+}
+
+func Up00004(ctx context.Context, tx *sql.Tx) error {
+	if err := createAuthorizationModel(ctx, tx, "v2", modelV2ID); err != nil {
+		return fmt.Errorf("failed to create authorization model: %w", err)
+	}
+
+	return nil
+}
+
+// Down00004 lifts every ban, which the previous model can't express,
+// and deletes the model.
+func Down00004(ctx context.Context, tx *sql.Tx) error {
+	if err := deleteTuples(ctx, tx, sq.Eq{"object_type": "maas", "relation": "banned"}); err != nil {
+		return fmt.Errorf("failed to delete bans: %w", err)
+	}
+
+	if err := deleteAuthorizationModel(ctx, tx, modelV2ID); err != nil {
+		return fmt.Errorf("failed to delete authorization model: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,177 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/oklog/ulid/v2"
+)
+
+// modelV3ID is the ID of the authorization model adding zones, fabrics,
+// VLANs, boot resources and tags.
+const modelV3ID = "00000000000000000000000003"
+
+func init() {
+	register(5, Up00005, Down00005)
+	registerModel("v3", modelV3ID)
+}
+
+// parentTuple links a MAAS object to the object it inherits permissions
+// from.
+type parentTuple struct {
+	user       string
+	relation   string
+	objectType string
+	objectID   string
+}
+
+// listParentTuples returns a parent tuple for every row of query, which
+// selects the object ID and the user of the tuple.
+func listParentTuples(ctx context.Context, tx *sql.Tx, query sq.SelectBuilder, relation, objectType string) ([]parentTuple, error) {
+	stmt, args, err := query.PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("failed to close rows: %v", err)
+		}
+	}()
+
+	var tuples []parentTuple
+
+	for rows.Next() {
+		t := parentTuple{relation: relation, objectType: objectType}
+		if err := rows.Scan(&t.objectID, &t.user); err != nil {
+			return nil, err
+		}
+
+		tuples = append(tuples, t)
+	}
+
+	return tuples, rows.Err()
+}
+
+// insertParentTuples writes the given tuples to the MAAS store.
+func insertParentTuples(ctx context.Context, tx *sql.Tx, tuples []parentTuple) error {
+	builder := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+
+	for _, t := range tuples {
+		insertStmt, insertArgs, err := builder.
+			Insert("openfga.tuple").
+			Columns(
+				"store",
+				"_user",
+				"user_type",
+				"relation",
+				"object_type",
+				"object_id",
+				"ulid",
+				"inserted_at",
+			).
+			Values(
+				storeID,
+				t.user,
+				"user",
+				t.relation,
+				t.objectType,
+				t.objectID,
+				ulid.Make().String(),
+				sq.Expr("NOW()"),
+			).
+			ToSql()
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, insertStmt, insertArgs...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// createParents links every existing zone, fabric, boot resource and tag to
+// maas:0, and every VLAN to its fabric.
+func createParents(ctx context.Context, tx *sql.Tx) error {
+	sources := []struct {
+		table      string
+		userColumn string
+		relation   string
+		objectType string
+	}{
+		{"maasserver_zone", "'maas:0'", "parent", "zone"},
+		{"maasserver_fabric", "'maas:0'", "parent", "fabric"},
+		{"maasserver_vlan", "'fabric:' || fabric_id", "fabric", "vlan"},
+		{"maasserver_bootresource", "'maas:0'", "parent", "boot_resource"},
+		{"maasserver_tag", "'maas:0'", "parent", "tag"},
+	}
+
+	for _, source := range sources {
+		query := sq.Select("id::text", source.userColumn).From(source.table)
+
+		tuples, err := listParentTuples(ctx, tx, query, source.relation, source.objectType)
+		if err != nil {
+			return fmt.Errorf("failed to list %s objects: %w", source.objectType, err)
+		}
+
+		if err := insertParentTuples(ctx, tx, tuples); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func Up00005(ctx context.Context, tx *sql.Tx) error {
+	if err := createAuthorizationModel(ctx, tx, "v3", modelV3ID); err != nil {
+		return fmt.Errorf("failed to create authorization model: %w", err)
+	}
+
+	if err := createParents(ctx, tx); err != nil {
+		return fmt.Errorf("failed to create parent tuples: %w", err)
+	}
+
+	return nil
+}
+
+// Down00005 deletes every tuple of the resource types added by the model,
+// and the model itself.
+func Down00005(ctx context.Context, tx *sql.Tx) error {
+	if err := deleteTuples(ctx, tx, sq.Eq{
+		"object_type": []string{"zone", "fabric", "vlan", "boot_resource", "tag"},
+	}); err != nil {
+		return fmt.Errorf("failed to delete resource tuples: %w", err)
+	}
+
+	if err := deleteAuthorizationModel(ctx, tx, modelV3ID); err != nil {
+		return fmt.Errorf("failed to delete authorization model: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,104 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+// modelV4ID is the ID of the authorization model adding machines, so that
+// permissions can be granted on the machines having a tag.
+const modelV4ID = "00000000000000000000000004"
+
+// nodeTypeMachine is the node_type of machines in maasserver_node.
+const nodeTypeMachine = 0
+
+func init() {
+	register(6, Up00006, Down00006)
+	registerModel("v4", modelV4ID)
+}
+
+// createMachines links every existing machine to its pool and its tags.
+func createMachines(ctx context.Context, tx *sql.Tx) error {
+	pools := sq.Select("id::text", "'pool:' || pool_id").
+		From("maasserver_node").
+		Where(sq.And{sq.Eq{"node_type": nodeTypeMachine}, sq.NotEq{"pool_id": nil}})
+
+	tags := sq.Select("nt.node_id::text", "'tag:' || nt.tag_id").
+		From("maasserver_node_tags nt").
+		Join("maasserver_node n ON n.id = nt.node_id").
+		Where(sq.Eq{"n.node_type": nodeTypeMachine})
+
+	sources := []struct {
+		query    sq.SelectBuilder
+		relation string
+	}{
+		{pools, "pool"},
+		{tags, "tag"},
+	}
+
+	for _, source := range sources {
+		tuples, err := listParentTuples(ctx, tx, source.query, source.relation, "machine")
+		if err != nil {
+			return fmt.Errorf("failed to list machine %ss: %w", source.relation, err)
+		}
+
+		if err := insertParentTuples(ctx, tx, tuples); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func Up00006(ctx context.Context, tx *sql.Tx) error {
+	if err := createAuthorizationModel(ctx, tx, "v4", modelV4ID); err != nil {
+		return fmt.Errorf("failed to create authorization model: %w", err)
+	}
+
+	if err := createMachines(ctx, tx); err != nil {
+		return fmt.Errorf("failed to create machine tuples: %w", err)
+	}
+
+	return nil
+}
+
+// Down00006 deletes the machine tuples, the machine permissions granted on
+// tags, and the model itself.
+func Down00006(ctx context.Context, tx *sql.Tx) error {
+	if err := deleteTuples(ctx, tx, sq.Eq{"object_type": "machine"}); err != nil {
+		return fmt.Errorf("failed to delete machine tuples: %w", err)
+	}
+
+	if err := deleteTuples(ctx, tx, sq.Eq{
+		"object_type": "tag",
+		"relation": []string{
+			"can_edit_machines", "can_deploy_machines", "can_view_machines", "can_view_available_machines",
+		},
+	}); err != nil {
+		return fmt.Errorf("failed to delete tag permissions: %w", err)
+	}
+
+	if err := deleteAuthorizationModel(ctx, tx, modelV4ID); err != nil {
+		return fmt.Errorf("failed to delete authorization model: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// TenantStoresTable records the stores provisioned for tenants, in
+// addition to the MAAS store.
+const TenantStoresTable = "openfga.maas_store"
+
+func init() {
+	register(7, Up00007, Down00007)
+}
+
+// Up00007 adds the table recording tenant stores and the model version
+// installed in each of them, so that upgrades can bring them up to date.
+func Up00007(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+CREATE TABLE `+TenantStoresTable+` (
+	store TEXT PRIMARY KEY,
+	name TEXT NOT NULL UNIQUE,
+	model_version TEXT NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL,
+	updated_at TIMESTAMPTZ NOT NULL
+)`)
+	if err != nil {
+		return fmt.Errorf("failed to create tenant stores table: %w", err)
+	}
+
+	return nil
+}
+
+// Down00007 deletes the tenant stores with everything they hold, and then
+// the table recording them.
+func Down00007(ctx context.Context, tx *sql.Tx) error {
+	ids, err := tenantStoreIDs(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		if err := deleteStore(ctx, tx, id); err != nil {
+			return fmt.Errorf("failed to delete tenant store %s: %w", id, err)
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, "DROP TABLE "+TenantStoresTable); err != nil {
+		return fmt.Errorf("failed to drop tenant stores table: %w", err)
+	}
+
+	return nil
+}
+
+func tenantStoreIDs(ctx context.Context, tx *sql.Tx) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, "SELECT store FROM "+TenantStoresTable)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tenant stores: %w", err)
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("failed to close rows: %v", err)
+		}
+	}()
+
+	var ids []string
+
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
@@ -0,0 +1,54 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// EntitySyncTable records, per store, the last MAAS user the entity sync
+// gave a default group to.
+const EntitySyncTable = "openfga.maas_entity_sync"
+
+func init() {
+	register(8, Up00008, Down00008)
+}
+
+// Up00008 adds the table tracking the progress of the entity sync. It stays
+// empty until the sync first runs.
+func Up00008(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+CREATE TABLE `+EntitySyncTable+` (
+	store TEXT PRIMARY KEY,
+	last_user_id BIGINT NOT NULL,
+	updated_at TIMESTAMPTZ NOT NULL
+)`)
+	if err != nil {
+		return fmt.Errorf("failed to create entity sync table: %w", err)
+	}
+
+	return nil
+}
+
+func Down00008(ctx context.Context, tx *sql.Tx) error {
+	if _, err := tx.ExecContext(ctx, "DROP TABLE "+EntitySyncTable); err != nil {
+		return fmt.Errorf("failed to drop entity sync table: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package migrations
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maas.io/core/src/maasopenfga/internal/assertions"
+	authzmodel "maas.io/core/src/maasopenfga/internal/model"
+)
+
+// TestModelAssertions evaluates testdata/<version>.yaml against every model
+// version installed by migrations. The latest version must have assertions.
+func TestModelAssertions(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "*.yaml"))
+	require.NoError(t, err)
+
+	versions := authzmodel.Versions()
+	latest := versions[len(versions)-1]
+	assert.FileExists(t, filepath.Join("testdata", latest+".yaml"),
+		"the latest model %s has no assertions", latest)
+
+	for _, path := range files {
+		version := strings.TrimSuffix(filepath.Base(path), ".yaml")
+
+		t.Run(version, func(t *testing.T) {
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+
+			file, err := assertions.Parse(data)
+			require.NoError(t, err)
+
+			model, err := authzmodel.Load(version)
+			require.NoError(t, err)
+
+			failures, err := assertions.Run(context.Background(), model, file)
+			require.NoError(t, err)
+
+			for _, failure := range failures {
+				t.Error(failure)
+			}
+		})
+	}
+}
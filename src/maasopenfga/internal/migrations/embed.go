@@ -15,13 +15,123 @@
 
 package migrations
 
-import "embed"
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/pressly/goose/v3"
+	"maas.io/core/src/maasopenfga/internal/modelupgrade"
+)
 
 const (
 	MigrationDir = "." // Only valid inside the MigrationsFS file system.
+	// AppMigrationsTable tracks applied migrations, separately from
+	// the OpenFGA datastore ones.
+	AppMigrationsTable = "openfga.goose_app_db_version"
+	// StoreID is the ID of the store holding MAAS authorization data.
+	StoreID = storeID
 )
 
 // MigrationsFS embeds the migrations
 //
 //go:embed *
 var MigrationsFS embed.FS
+
+// goMigrations are deliberately kept out of the goose global registry:
+// OpenFGA datastore migrations are applied using the goose global API and
+// would otherwise pick them up when linked into the same binary.
+var goMigrations []*goose.Migration
+
+// upFuncs are the up functions of goMigrations, by version, for dry runs.
+var upFuncs = map[int64]migrationFunc{}
+
+type migrationFunc func(ctx context.Context, tx *sql.Tx) error
+
+// modelIDs are the IDs of the models installed in the MAAS store, by model
+// version.
+var modelIDs = map[string]string{}
+
+// modelUpgrades are the model upgrades registered with registerModelUpgrade,
+// by migration version.
+var modelUpgrades = map[int64]modelupgrade.Upgrade{}
+
+func register(version int64, up, down migrationFunc) {
+	upFuncs[version] = up
+	goMigrations = append(goMigrations, goose.NewGoMigration(version,
+		&goose.GoFunc{RunTx: up}, &goose.GoFunc{RunTx: down}))
+}
+
+// registerModel records that a migration installs the given model version
+// in the MAAS store under modelID.
+func registerModel(version, modelID string) {
+	modelIDs[version] = modelID
+}
+
+// registerModelUpgrade registers a migration applying upgrade to the MAAS
+// store. Tenant stores are upgraded after migrations, see ModelUpgrades.
+func registerModelUpgrade(version int64, upgrade modelupgrade.Upgrade) {
+	registerModel(upgrade.Version, upgrade.ModelID)
+	modelUpgrades[version] = upgrade
+
+	register(version,
+		func(ctx context.Context, tx *sql.Tx) error {
+			return upgrade.Apply(ctx, tx, storeID)
+		},
+		func(ctx context.Context, tx *sql.Tx) error {
+			return upgrade.Revert(ctx, tx, storeID)
+		})
+}
+
+// ModelUpgrades returns the registered model upgrades, in migration order.
+func ModelUpgrades() []modelupgrade.Upgrade {
+	upgrades := make([]modelupgrade.Upgrade, 0, len(modelUpgrades))
+	for _, version := range slices.Sorted(maps.Keys(modelUpgrades)) {
+		upgrades = append(upgrades, modelUpgrades[version])
+	}
+
+	return upgrades
+}
+
+// LatestModelID returns the ID of the latest model installed in the MAAS
+// store, which clients pinning a model ID (maascommon.enums.openfga) must
+// use.
+func LatestModelID() string {
+	return slices.Max(slices.Collect(maps.Values(modelIDs)))
+}
+
+// NewProvider returns a goose Provider applying MAAS migrations to db.
+func NewProvider(db *sql.DB) (*goose.Provider, error) {
+	return goose.NewProvider(goose.DialectPostgres, db, MigrationsFS,
+		goose.WithTableName(AppMigrationsTable),
+		goose.WithDisableGlobalRegistry(true),
+		goose.WithGoMigrations(goMigrations...),
+		goose.WithLogger(goose.NopLogger()),
+	)
+}
+
+// Versions returns the versions of the MAAS migrations, in ascending order.
+func Versions() []int64 {
+	versions := make([]int64, 0, len(goMigrations))
+	for _, m := range goMigrations {
+		versions = append(versions, m.Version)
+	}
+
+	slices.Sort(versions)
+
+	return versions
+}
+
+// UpTx applies the given migration within tx without recording it in
+// AppMigrationsTable. It is meant for dry runs, which roll tx back.
+func UpTx(ctx context.Context, tx *sql.Tx, version int64) error {
+	up, ok := upFuncs[version]
+	if !ok {
+		return fmt.Errorf("unknown migration version %d", version)
+	}
+
+	return up(ctx, tx)
+}
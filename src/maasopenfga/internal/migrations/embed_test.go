@@ -0,0 +1,63 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package migrations
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewProvider(t *testing.T) {
+	// sql.Open does not connect, listing sources doesn't need a database.
+	db, err := sql.Open("pgx", "postgres://maas@/maasdb")
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = db.Close() }) //nolint:errcheck // test
+
+	provider, err := NewProvider(db)
+	require.NoError(t, err)
+
+	var versions []int64
+	for _, source := range provider.ListSources() {
+		versions = append(versions, source.Version)
+	}
+
+	assert.Equal(t, []int64{1, 2, 3, 4, 5, 6, 7, 8}, versions)
+}
+
+func TestVersions(t *testing.T) {
+	assert.Equal(t, []int64{1, 2, 3, 4, 5, 6, 7, 8}, Versions())
+}
+
+// TestClientModelID checks that the model ID pinned by regiond matches the
+// latest model installed by migrations.
+func TestClientModelID(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "..", "maascommon", "enums", "openfga.py"))
+	require.NoError(t, err)
+
+	match := regexp.MustCompile(`(?m)^OPENFGA_AUTHORIZATION_MODEL_ID = "(\w+)"$`).FindSubmatch(data)
+	require.NotNil(t, match, "OPENFGA_AUTHORIZATION_MODEL_ID not found")
+
+	assert.Equal(t, LatestModelID(), string(match[1]),
+		"update OPENFGA_AUTHORIZATION_MODEL_ID in maascommon/enums/openfga.py")
+}
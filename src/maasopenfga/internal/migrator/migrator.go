@@ -0,0 +1,459 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package migrator brings the OpenFGA datastore schema and the MAAS
+// authorization model up to date.
+package migrator
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/openfga/openfga/pkg/logger"
+	"github.com/openfga/openfga/pkg/storage/migrate"
+	"github.com/pressly/goose/v3"
+	"google.golang.org/protobuf/proto"
+	maaserrors "maas.io/core/src/maasgocommon/errors"
+	"maas.io/core/src/maasgocommon/retry"
+	"maas.io/core/src/maasopenfga/internal/migrations"
+	authzmodel "maas.io/core/src/maasopenfga/internal/model"
+	"maas.io/core/src/maasopenfga/internal/seed"
+	"maas.io/core/src/maasopenfga/internal/stores"
+)
+
+const (
+	// lockID is the Postgres advisory lock key serialising migrations
+	// between region controllers ("maasofga").
+	lockID int64 = 0x6d6161736f666761
+
+	connectTimeout = 30 * time.Second
+)
+
+// ErrDownNotAllowed is returned by Down unless rolling back is explicitly
+// allowed.
+var ErrDownNotAllowed = errors.New("rolling back migrations deletes authorization data and is not allowed")
+
+// Datastore applies the OpenFGA datastore migrations. uri must set
+// search_path to the openfga schema.
+func Datastore(ctx context.Context, uri string, log logger.Logger) error {
+	return withLock(ctx, uri, func(db *sql.DB) error {
+		return datastore(ctx, db, uri, log)
+	})
+}
+
+// App applies the MAAS migrations (store, authorization model and tuples),
+// and then installs the latest model in tenant stores. uri must not set
+// search_path, since migrations also read MAAS tables.
+func App(ctx context.Context, uri string) error {
+	return withLock(ctx, uri, func(db *sql.DB) error {
+		return app(ctx, db)
+	})
+}
+
+// Up applies the datastore and then the MAAS migrations while holding
+// a single advisory lock, so concurrent callers don't race.
+func Up(ctx context.Context, datastoreURI, appURI string, log logger.Logger) error {
+	return withLock(ctx, appURI, func(db *sql.DB) error {
+		if err := datastore(ctx, db, datastoreURI, log); err != nil {
+			return err
+		}
+
+		return app(ctx, db)
+	})
+}
+
+// Down rolls back the MAAS migrations applied after version, or the last
+// applied one if version is negative. Rolling back deletes the models and
+// the tuples the migrations installed, so it is only meant for development
+// and fails with ErrDownNotAllowed unless allowDown is set. uri must not
+// set search_path.
+func Down(ctx context.Context, uri string, version int64, allowDown bool) error {
+	if !allowDown {
+		return ErrDownNotAllowed
+	}
+
+	return withLock(ctx, uri, func(db *sql.DB) error {
+		provider, err := migrations.NewProvider(db)
+		if err != nil {
+			return fmt.Errorf("failed to load migrations: %w", err)
+		}
+
+		if version < 0 {
+			_, err = provider.Down(ctx)
+		} else {
+			_, err = provider.DownTo(ctx, version)
+		}
+
+		if err != nil {
+			return fmt.Errorf("failed to roll back migrations: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// Status returns the state of every MAAS migration. uri must not set
+// search_path.
+func Status(ctx context.Context, uri string) ([]*goose.MigrationStatus, error) {
+	var status []*goose.MigrationStatus
+
+	err := withDB(ctx, uri, nil, func(db *sql.DB) error {
+		provider, err := migrations.NewProvider(db)
+		if err != nil {
+			return fmt.Errorf("failed to load migrations: %w", err)
+		}
+
+		status, err = provider.Status(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get migration status: %w", err)
+		}
+
+		return nil
+	})
+
+	return status, err
+}
+
+// Version returns the version of the last applied MAAS migration, 0 if
+// there is none, and the version of the latest known one. uri must not set
+// search_path.
+func Version(ctx context.Context, uri string) (int64, int64, error) {
+	versions := migrations.Versions()
+	latest := versions[len(versions)-1]
+
+	var current int64
+
+	err := withDB(ctx, uri, nil, func(db *sql.DB) error {
+		var err error
+
+		current, err = dbVersion(ctx, db)
+
+		return err
+	})
+
+	return current, latest, err
+}
+
+// UpTo applies the MAAS migrations up to and including version. uri must
+// not set search_path.
+func UpTo(ctx context.Context, uri string, version int64) error {
+	return withLock(ctx, uri, func(db *sql.DB) error {
+		provider, err := migrations.NewProvider(db)
+		if err != nil {
+			return fmt.Errorf("failed to load migrations: %w", err)
+		}
+
+		if _, err := provider.UpTo(ctx, version); err != nil {
+			return fmt.Errorf("failed to run migrations: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// Redo rolls back the last applied MAAS migration and applies it again.
+// Like Down, it fails with ErrDownNotAllowed unless allowDown is set. uri
+// must not set search_path.
+func Redo(ctx context.Context, uri string, allowDown bool) error {
+	if !allowDown {
+		return ErrDownNotAllowed
+	}
+
+	return withLock(ctx, uri, func(db *sql.DB) error {
+		provider, err := migrations.NewProvider(db)
+		if err != nil {
+			return fmt.Errorf("failed to load migrations: %w", err)
+		}
+
+		result, err := provider.Down(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to roll back migration: %w", err)
+		}
+
+		if _, err := provider.UpTo(ctx, result.Source.Version); err != nil {
+			return fmt.Errorf("failed to reapply migration %d: %w", result.Source.Version, err)
+		}
+
+		return nil
+	})
+}
+
+// Model reads the authorization model of the MAAS store with the given ID,
+// or the latest one if modelID is empty, as stored in the database. uri
+// must not set search_path.
+func Model(ctx context.Context, uri, modelID string) (*openfgav1.AuthorizationModel, error) {
+	query := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+		Select("serialized_protobuf").
+		From("openfga.authorization_model").
+		Where(sq.Eq{"store": migrations.StoreID}).
+		OrderBy("authorization_model_id DESC").
+		Limit(1)
+	if modelID != "" {
+		query = query.Where(sq.Eq{"authorization_model_id": modelID})
+	}
+
+	stmt, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var data []byte
+
+	err = withDB(ctx, uri, nil, func(db *sql.DB) error {
+		return db.QueryRowContext(ctx, stmt, args...).Scan(&data)
+	})
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows) && modelID != "":
+		return nil, fmt.Errorf("authorization model %q not found", modelID)
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, errors.New("no authorization model found, were migrations applied?")
+	case err != nil:
+		return nil, fmt.Errorf("failed to read authorization model: %w", err)
+	}
+
+	var model openfgav1.AuthorizationModel
+	if err := proto.Unmarshal(data, &model); err != nil {
+		return nil, fmt.Errorf("invalid authorization model: %w", err)
+	}
+
+	return &model, nil
+}
+
+// Seed writes the tuples of the seed files that are not in the MAAS store
+// yet, in a single transaction. Tuples are checked against the latest model.
+// With dryRun, the transaction is rolled back. uri must not set search_path,
+// since seed files may refer to MAAS objects by name.
+func Seed(ctx context.Context, uri string, files []*seed.File, dryRun bool) (*seed.Result, error) {
+	versions := authzmodel.Versions()
+
+	model, err := authzmodel.Load(versions[len(versions)-1])
+	if err != nil {
+		return nil, err
+	}
+
+	var result *seed.Result
+
+	err = withLock(ctx, uri, func(db *sql.DB) error {
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("failed to begin transaction: %w", err)
+		}
+
+		result, err = seed.Apply(ctx, tx, migrations.StoreID, model, files, dryRun)
+		if err != nil || dryRun {
+			return errors.Join(err, tx.Rollback())
+		}
+
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("failed to commit transaction: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to seed tuples: %w", err)
+	}
+
+	return result, nil
+}
+
+func datastore(ctx context.Context, db *sql.DB, uri string, log logger.Logger) error {
+	if _, err := db.ExecContext(ctx, "CREATE SCHEMA IF NOT EXISTS openfga"); err != nil {
+		return fmt.Errorf("failed to create openfga schema: %w", err)
+	}
+
+	cfg := migrate.MigrationConfig{
+		Engine:        "postgres",
+		URI:           uri,
+		TargetVersion: 0, // migrate to latest
+		Timeout:       connectTimeout,
+		Verbose:       true,
+		Logger:        log,
+	}
+
+	if err := migrate.RunMigrations(cfg); err != nil {
+		return fmt.Errorf("failed to run datastore migrations: %w", err)
+	}
+
+	return nil
+}
+
+func app(ctx context.Context, db *sql.DB) error {
+	provider, err := migrations.NewProvider(db)
+	if err != nil {
+		return fmt.Errorf("failed to load migrations: %w", err)
+	}
+
+	if _, err := provider.Up(ctx); err != nil {
+		return fmt.Errorf("failed to run migrations: %w", err)
+	}
+
+	return inTx(ctx, db, func(tx *sql.Tx) error {
+		upgraded, err := stores.Upgrade(ctx, tx)
+		for _, s := range upgraded {
+			log.Printf("upgraded store %q to model %s", s.Name, s.ModelVersion)
+		}
+
+		return err
+	})
+}
+
+// CreateStore provisions a tenant store named name with the latest MAAS
+// model. uri must not set search_path.
+func CreateStore(ctx context.Context, uri, name string) (*stores.Store, error) {
+	var store *stores.Store
+
+	err := withLock(ctx, uri, func(db *sql.DB) error {
+		return inTx(ctx, db, func(tx *sql.Tx) error {
+			var err error
+
+			store, err = stores.Create(ctx, tx, name)
+
+			return err
+		})
+	})
+
+	return store, err
+}
+
+// ListStores returns the tenant stores. uri must not set search_path.
+func ListStores(ctx context.Context, uri string) ([]stores.Store, error) {
+	var list []stores.Store
+
+	err := withDB(ctx, uri, nil, func(db *sql.DB) error {
+		var err error
+
+		list, err = stores.List(ctx, db)
+
+		return err
+	})
+
+	return list, err
+}
+
+// inTx calls fn within a transaction, committed if fn succeeds.
+func inTx(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	if err := fn(tx); err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// queryRower is implemented by *sql.DB and *sql.Tx.
+type queryRower interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// dbVersion returns the version of the last applied MAAS migration, or 0
+// if there is none. Unlike the goose provider, it never creates the version
+// table, so it may be used within dry runs.
+func dbVersion(ctx context.Context, q queryRower) (int64, error) {
+	var exists bool
+
+	if err := q.QueryRowContext(ctx, "SELECT to_regclass($1) IS NOT NULL",
+		migrations.AppMigrationsTable).Scan(&exists); err != nil {
+		return 0, fmt.Errorf("failed to get migration version: %w", err)
+	}
+
+	if !exists {
+		return 0, nil
+	}
+
+	stmt, args, err := sq.Select("COALESCE(MAX(version_id), 0)").
+		From(migrations.AppMigrationsTable).
+		ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	var version int64
+
+	if err := q.QueryRowContext(ctx, stmt, args...).Scan(&version); err != nil {
+		return 0, fmt.Errorf("failed to get migration version: %w", err)
+	}
+
+	return version, nil
+}
+
+// withDB calls fn with a connection pool to the database at uri, once it
+// accepts connections. tracer, if not nil, traces the queries of the pool.
+func withDB(ctx context.Context, uri string, tracer pgx.QueryTracer, fn func(db *sql.DB) error) error {
+	cfg, err := pgx.ParseConfig(uri)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+
+	cfg.Tracer = tracer
+	db := stdlib.OpenDB(*cfg)
+
+	// Don't wait for a database that rejects our credentials or is missing.
+	if err := retry.Do(ctx, db.PingContext,
+		retry.WithMaxElapsedTime(connectTimeout),
+		retry.WithRetryIf(maaserrors.Retryable)); err != nil {
+		return errors.Join(fmt.Errorf("failed to initialize database connection: %w", err), db.Close())
+	}
+
+	fnErr := fn(db)
+
+	return errors.Join(fnErr, db.Close())
+}
+
+// withLock calls fn with a connection pool to the database at uri while
+// holding the migration advisory lock on a dedicated session.
+func withLock(ctx context.Context, uri string, fn func(db *sql.DB) error) error {
+	return withDB(ctx, uri, nil, func(db *sql.DB) error {
+		return lock(ctx, db, fn)
+	})
+}
+
+func lock(ctx context.Context, db *sql.DB, fn func(db *sql.DB) error) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire database connection: %w", err)
+	}
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", lockID); err != nil {
+		return errors.Join(fmt.Errorf("failed to acquire migration lock: %w", err), conn.Close())
+	}
+
+	fnErr := fn(db)
+
+	// Use a fresh context, the lock must be released even if ctx is done.
+	_, unlockErr := conn.ExecContext(context.WithoutCancel(ctx), "SELECT pg_advisory_unlock($1)", lockID)
+	if unlockErr != nil {
+		unlockErr = fmt.Errorf("failed to release migration lock: %w", unlockErr)
+	}
+
+	return errors.Join(fnErr, unlockErr, conn.Close())
+}
@@ -0,0 +1,50 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package migrator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDownNotAllowed(t *testing.T) {
+	// The URI is never used, Down must fail before connecting.
+	err := Down(context.Background(), "postgres://maas@/maasdb", -1, false)
+	assert.ErrorIs(t, err, ErrDownNotAllowed)
+}
+
+func TestRedoNotAllowed(t *testing.T) {
+	err := Redo(context.Background(), "postgres://maas@/maasdb", false)
+	assert.ErrorIs(t, err, ErrDownNotAllowed)
+}
+
+func TestFormatStatement(t *testing.T) {
+	stmt := formatStatement(
+		"INSERT INTO openfga.tuple (store,_user,object_id,condition_context) VALUES ($1,$2,$3,$4)",
+		[]any{"01ARZ3NDEKTSV4RRFFQ69G5FAV", "o'brien", int64(3), nil},
+	)
+
+	assert.Equal(t, `INSERT INTO openfga.tuple (store,_user,object_id,condition_context) VALUES ($1,$2,$3,$4);
+--   $1 = '01ARZ3NDEKTSV4RRFFQ69G5FAV'
+--   $2 = 'o''brien'
+--   $3 = 3
+--   $4 = NULL
+`, stmt)
+
+	assert.Equal(t, "SELECT 1;\n", formatStatement("  SELECT 1;\n", nil))
+}
@@ -0,0 +1,126 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package migrator
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync/atomic"
+
+	"github.com/jackc/pgx/v5"
+	"maas.io/core/src/maasopenfga/internal/migrations"
+)
+
+// SQL writes the statements that the pending MAAS migrations up to and
+// including version, or all of them if version is 0, would run, for review
+// before an upgrade. The migrations run in a transaction that is rolled
+// back, so nothing is applied. Generated values, such as tuple ULIDs, differ
+// from those of the actual upgrade. uri must not set search_path.
+func SQL(ctx context.Context, uri string, version int64, w io.Writer) error {
+	rec := &recorder{w: w}
+
+	return withDB(ctx, uri, rec, func(db *sql.DB) error {
+		return lock(ctx, db, func(db *sql.DB) error {
+			tx, err := db.BeginTx(ctx, nil)
+			if err != nil {
+				return fmt.Errorf("failed to begin transaction: %w", err)
+			}
+
+			return errors.Join(dryRun(ctx, tx, rec, version), tx.Rollback())
+		})
+	})
+}
+
+func dryRun(ctx context.Context, tx *sql.Tx, rec *recorder, version int64) error {
+	current, err := dbVersion(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	pending := 0
+
+	for _, v := range migrations.Versions() {
+		if v <= current || (version != 0 && v > version) {
+			continue
+		}
+
+		pending++
+
+		fmt.Fprintf(rec.w, "-- migration %d\n", v)
+
+		rec.enabled.Store(true)
+		err := migrations.UpTx(ctx, tx, v)
+		rec.enabled.Store(false)
+
+		if err != nil {
+			return fmt.Errorf("failed to run migration %d: %w", v, err)
+		}
+	}
+
+	if pending == 0 {
+		fmt.Fprintf(rec.w, "-- no pending migrations, the database is at version %d\n", current)
+	}
+
+	return nil
+}
+
+// recorder is a pgx.QueryTracer writing the statements run while enabled.
+type recorder struct {
+	w       io.Writer
+	enabled atomic.Bool
+}
+
+func (r *recorder) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	if r.enabled.Load() {
+		fmt.Fprint(r.w, formatStatement(data.SQL, data.Args))
+	}
+
+	return ctx
+}
+
+func (r *recorder) TraceQueryEnd(context.Context, *pgx.Conn, pgx.TraceQueryEndData) {}
+
+// formatStatement formats a statement and its arguments as SQL, arguments
+// going in a comment after the statement.
+func formatStatement(stmt string, args []any) string {
+	var b strings.Builder
+
+	b.WriteString(strings.TrimSuffix(strings.TrimSpace(stmt), ";"))
+	b.WriteString(";\n")
+
+	for i, arg := range args {
+		fmt.Fprintf(&b, "--   $%d = %s\n", i+1, formatArg(arg))
+	}
+
+	return b.String()
+}
+
+func formatArg(arg any) string {
+	switch v := arg.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		return "'" + strings.ReplaceAll(v, "'", "''") + "'"
+	case []byte:
+		return fmt.Sprintf(`'\x%x'`, v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
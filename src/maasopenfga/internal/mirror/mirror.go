@@ -0,0 +1,417 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package mirror keeps a read-only copy of the MAAS store of another
+// maas-openfga, for staging and preview environments that need realistic
+// permissions without write access to production authorization data.
+//
+// The copy lives in a datastore of its own, usually in memory, refreshed
+// periodically from the HTTP API of the source: new models are added and
+// tuples are brought in line with the source, whose tuples always win.
+// Changes are applied in chunks, so checks served during a refresh may see
+// some of them only.
+package mirror
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+	"maas.io/core/src/maasgocommon/clock"
+	maaserrors "maas.io/core/src/maasgocommon/errors"
+	"maas.io/core/src/maasopenfga/internal/priority"
+	"maas.io/core/src/maasopenfga/internal/stores"
+)
+
+const (
+	pageSize = 100
+
+	defaultInterval       = 5 * time.Minute
+	responseHeaderTimeout = 30 * time.Second
+)
+
+// unmarshal tolerates fields added to the API by newer sources.
+var unmarshal = protojson.UnmarshalOptions{DiscardUnknown: true}
+
+var (
+	lastRefreshGauge = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "maas_openfga",
+		Subsystem: "mirror",
+		Name:      "last_refresh_timestamp_seconds",
+		Help:      "Time at which the mirror was last refreshed successfully.",
+	})
+	errorsCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "maas_openfga",
+		Subsystem: "mirror",
+		Name:      "errors_total",
+		Help:      "Number of failed refreshes of the mirror, by kind of error.",
+	}, []string{"kind"})
+)
+
+// Mirror refreshes a datastore with the MAAS store of another maas-openfga.
+type Mirror struct {
+	clock      clock.Clock
+	datastore  storage.OpenFGADatastore
+	httpClient *http.Client
+	sourceURL  string
+	storeID    string
+	interval   time.Duration
+}
+
+// Option allows to set additional Mirror options
+type Option func(*Mirror)
+
+// WithInterval sets the time between refreshes (default: 5m)
+func WithInterval(d time.Duration) Option {
+	return func(m *Mirror) {
+		if d > 0 {
+			m.interval = d
+		}
+	}
+}
+
+// WithHTTPClient sets the client used to reach the source, e.g. to
+// configure TLS (default: a client honouring the proxy environment)
+func WithHTTPClient(c *http.Client) Option {
+	return func(m *Mirror) {
+		m.httpClient = c
+	}
+}
+
+// WithClock sets the clock used to wait between refreshes (default: the
+// system clock)
+func WithClock(clk clock.Clock) Option {
+	return func(m *Mirror) {
+		m.clock = clk
+	}
+}
+
+// New returns a Mirror copying the store storeID from the maas-openfga HTTP
+// API at sourceURL into datastore.
+func New(datastore storage.OpenFGADatastore, sourceURL, storeID string, options ...Option) *Mirror {
+	m := &Mirror{
+		clock:     clock.New(),
+		datastore: datastore,
+		httpClient: &http.Client{
+			Transport: &http.Transport{
+				Proxy:                 http.ProxyFromEnvironment,
+				ResponseHeaderTimeout: responseHeaderTimeout,
+			},
+		},
+		sourceURL: sourceURL,
+		storeID:   storeID,
+		interval:  defaultInterval,
+	}
+
+	for _, opt := range options {
+		opt(m)
+	}
+
+	return m
+}
+
+// Result summarizes a refresh.
+type Result struct {
+	// Models is the number of models added.
+	Models int
+	// Written and Deleted are the numbers of tuples written and deleted.
+	Written int
+	Deleted int
+}
+
+// Run refreshes the mirror right away and then every interval, until ctx
+// is done. Failed refreshes are retried at the next interval.
+func (m *Mirror) Run(ctx context.Context) error {
+	for {
+		result, err := m.Refresh(ctx)
+
+		switch {
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case err != nil:
+			errorsCounter.WithLabelValues(maaserrors.KindOf(err).String()).Inc()
+			log.Printf("mirror refresh from %s failed: %v", m.sourceURL, err)
+		default:
+			lastRefreshGauge.Set(float64(m.clock.Now().Unix()))
+			log.Printf("mirror refreshed from %s: %d models added, %d tuples written, %d deleted",
+				m.sourceURL, result.Models, result.Written, result.Deleted)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-m.clock.After(m.interval):
+		}
+	}
+}
+
+// Refresh brings the mirror in line with the source.
+func (m *Mirror) Refresh(ctx context.Context) (Result, error) {
+	var result Result
+
+	// Models come first, tuples may use conditions of new models.
+	models, err := m.sourceModels(ctx)
+	if err != nil {
+		return result, err
+	}
+
+	want, err := m.sourceTuples(ctx)
+	if err != nil {
+		return result, err
+	}
+
+	if err := m.ensureStore(ctx); err != nil {
+		return result, err
+	}
+
+	if result.Models, err = m.addModels(ctx, models); err != nil {
+		return result, err
+	}
+
+	writes, deletes, err := m.diff(ctx, want)
+	if err != nil {
+		return result, err
+	}
+
+	if err := m.apply(ctx, writes, deletes); err != nil {
+		return result, err
+	}
+
+	result.Written, result.Deleted = len(writes), len(deletes)
+
+	return result, nil
+}
+
+func (m *Mirror) ensureStore(ctx context.Context) error {
+	_, err := m.datastore.GetStore(ctx, m.storeID)
+	if !errors.Is(err, storage.ErrNotFound) {
+		return err
+	}
+
+	if _, err := m.datastore.CreateStore(ctx, &openfgav1.Store{Id: m.storeID, Name: stores.DefaultName}); err != nil {
+		return fmt.Errorf("failed to create store: %w", err)
+	}
+
+	return nil
+}
+
+// addModels writes the models missing from the mirror. Models are
+// immutable, existing ones are left alone.
+func (m *Mirror) addModels(ctx context.Context, models []*openfgav1.AuthorizationModel) (int, error) {
+	added := 0
+
+	for _, model := range models {
+		_, err := m.datastore.ReadAuthorizationModel(ctx, m.storeID, model.GetId())
+		if err == nil {
+			continue
+		}
+
+		if !errors.Is(err, storage.ErrNotFound) {
+			return added, fmt.Errorf("failed to read model %s: %w", model.GetId(), err)
+		}
+
+		if err := m.datastore.WriteAuthorizationModel(ctx, m.storeID, model); err != nil {
+			return added, fmt.Errorf("failed to write model %s: %w", model.GetId(), err)
+		}
+
+		added++
+	}
+
+	return added, nil
+}
+
+// diff returns the tuples to write to and delete from the mirror for it to
+// hold want, keyed by their string form. A tuple whose condition changed
+// is deleted and written again.
+func (m *Mirror) diff(ctx context.Context, want map[string]*openfgav1.TupleKey) (storage.Writes, storage.Deletes, error) {
+	var deletes storage.Deletes
+
+	token := ""
+
+	for {
+		tuples, next, err := m.datastore.ReadPage(ctx, m.storeID, storage.ReadFilter{}, storage.ReadPageOptions{
+			Pagination: storage.NewPaginationOptions(pageSize, token),
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read mirrored tuples: %w", err)
+		}
+
+		for _, t := range tuples {
+			key := t.GetKey()
+			s := tuple.TupleKeyToString(key)
+
+			if wanted, ok := want[s]; ok && proto.Equal(wanted.GetCondition(), key.GetCondition()) {
+				delete(want, s)
+				continue
+			}
+
+			deletes = append(deletes, tuple.TupleKeyToTupleKeyWithoutCondition(key))
+		}
+
+		if next == "" {
+			break
+		}
+
+		token = next
+	}
+
+	writes := make(storage.Writes, 0, len(want))
+	for _, key := range want {
+		writes = append(writes, key)
+	}
+
+	return writes, deletes, nil
+}
+
+// apply deletes and then writes tuples, in chunks the datastore accepts.
+func (m *Mirror) apply(ctx context.Context, writes storage.Writes, deletes storage.Deletes) error {
+	chunk := min(pageSize, m.datastore.MaxTuplesPerWrite())
+
+	for len(deletes) > 0 {
+		n := min(chunk, len(deletes))
+		if err := m.datastore.Write(ctx, m.storeID, deletes[:n], nil); err != nil {
+			return fmt.Errorf("failed to delete tuples: %w", err)
+		}
+
+		deletes = deletes[n:]
+	}
+
+	for len(writes) > 0 {
+		n := min(chunk, len(writes))
+		if err := m.datastore.Write(ctx, m.storeID, nil, writes[:n]); err != nil {
+			return fmt.Errorf("failed to write tuples: %w", err)
+		}
+
+		writes = writes[n:]
+	}
+
+	return nil
+}
+
+func (m *Mirror) sourceModels(ctx context.Context) ([]*openfgav1.AuthorizationModel, error) {
+	var models []*openfgav1.AuthorizationModel
+
+	query := url.Values{"page_size": {strconv.Itoa(pageSize)}}
+
+	for {
+		var resp openfgav1.ReadAuthorizationModelsResponse
+
+		if err := m.do(ctx, http.MethodGet, "/authorization-models?"+query.Encode(), nil, &resp); err != nil {
+			return nil, err
+		}
+
+		models = append(models, resp.GetAuthorizationModels()...)
+
+		if resp.GetContinuationToken() == "" {
+			return models, nil
+		}
+
+		query.Set("continuation_token", resp.GetContinuationToken())
+	}
+}
+
+// sourceTuples returns the tuples of the source, keyed by their string
+// form.
+func (m *Mirror) sourceTuples(ctx context.Context) (map[string]*openfgav1.TupleKey, error) {
+	tuples := make(map[string]*openfgav1.TupleKey)
+	req := &openfgav1.ReadRequest{PageSize: wrapperspb.Int32(pageSize)}
+
+	for {
+		var resp openfgav1.ReadResponse
+
+		if err := m.do(ctx, http.MethodPost, "/read", req, &resp); err != nil {
+			return nil, err
+		}
+
+		for _, t := range resp.GetTuples() {
+			tuples[tuple.TupleKeyToString(t.GetKey())] = t.GetKey()
+		}
+
+		if resp.GetContinuationToken() == "" {
+			return tuples, nil
+		}
+
+		req.ContinuationToken = resp.GetContinuationToken()
+	}
+}
+
+// do sends a request to the store endpoint path of the source, encoding in
+// as the body unless it is nil, and decodes the response into out.
+func (m *Mirror) do(ctx context.Context, method, path string, in, out proto.Message) error {
+	var body io.Reader
+
+	if in != nil {
+		data, err := protojson.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method,
+		strings.TrimSuffix(m.sourceURL, "/")+"/stores/"+m.storeID+path, body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	// Refreshes must not slow down the checks served by the source.
+	req.Header.Set(priority.Header, string(priority.Batch))
+
+	resp, err := m.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to connect to source: %w", err)
+	}
+
+	defer resp.Body.Close() //nolint:errcheck // nothing to do with the error
+
+	if resp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096)) //nolint:errcheck // best effort
+
+		return maaserrors.Errorf(maaserrors.FromHTTPStatus(resp.StatusCode),
+			"source returned %s: %s", resp.Status, bytes.TrimSpace(data))
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if err := unmarshal.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,196 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package mirror
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	maaserrors "maas.io/core/src/maasgocommon/errors"
+	"maas.io/core/src/maasopenfga/internal/priority"
+	"maas.io/core/src/maasopenfga/internal/stores"
+)
+
+const storeID = "01JMAASSTORE0000000000000"
+
+var model = &openfgav1.AuthorizationModel{
+	Id:            "01JMAASMODEL0000000000000",
+	SchemaVersion: "1.1",
+	TypeDefinitions: []*openfgav1.TypeDefinition{
+		{Type: "user"},
+		{Type: "group", Relations: map[string]*openfgav1.Userset{
+			"member": {Userset: &openfgav1.Userset_This{}},
+		}},
+	},
+}
+
+func member(user, group string) *openfgav1.TupleKey {
+	return tuple.NewTupleKey("group:"+group, "member", "user:"+user)
+}
+
+// source serves the store API of a maas-openfga holding tuples, one per
+// page.
+func source(t *testing.T, tuples ...*openfgav1.TupleKey) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "batch", r.Header.Get(priority.Header))
+
+		var resp proto.Message
+
+		switch r.URL.Path {
+		case "/stores/" + storeID + "/authorization-models":
+			assert.Equal(t, http.MethodGet, r.Method)
+
+			resp = &openfgav1.ReadAuthorizationModelsResponse{
+				AuthorizationModels: []*openfgav1.AuthorizationModel{model},
+			}
+		case "/stores/" + storeID + "/read":
+			assert.Equal(t, http.MethodPost, r.Method)
+
+			body, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+
+			var req openfgav1.ReadRequest
+			require.NoError(t, protojson.Unmarshal(body, &req))
+
+			page := &openfgav1.ReadResponse{}
+
+			i := slices.IndexFunc(tuples, func(k *openfgav1.TupleKey) bool {
+				return req.GetContinuationToken() == "" || tuple.TupleKeyToString(k) == req.GetContinuationToken()
+			})
+			if i >= 0 {
+				page.Tuples = []*openfgav1.Tuple{{Key: tuples[i]}}
+				if i+1 < len(tuples) {
+					page.ContinuationToken = tuple.TupleKeyToString(tuples[i+1])
+				}
+			}
+
+			resp = page
+		default:
+			http.NotFound(w, r)
+			return
+		}
+
+		data, err := protojson.Marshal(resp)
+		require.NoError(t, err)
+
+		_, _ = w.Write(data) //nolint:errcheck // test server
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func readAll(t *testing.T, ds storage.OpenFGADatastore) []string {
+	t.Helper()
+
+	var keys []string
+
+	token := ""
+
+	for {
+		tuples, next, err := ds.ReadPage(context.Background(), storeID, storage.ReadFilter{}, storage.ReadPageOptions{
+			Pagination: storage.NewPaginationOptions(pageSize, token),
+		})
+		require.NoError(t, err)
+
+		for _, t := range tuples {
+			keys = append(keys, tuple.TupleKeyToString(t.GetKey()))
+		}
+
+		if next == "" {
+			break
+		}
+
+		token = next
+	}
+
+	slices.Sort(keys)
+
+	return keys
+}
+
+func TestRefresh(t *testing.T) {
+	ctx := context.Background()
+	ds := memory.New()
+
+	t.Cleanup(ds.Close)
+
+	srv := source(t, member("1", "admins"), member("2", "admins"), member("2", "users"))
+
+	result, err := New(ds, srv.URL, storeID).Refresh(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, Result{Models: 1, Written: 3}, result)
+
+	store, err := ds.GetStore(ctx, storeID)
+	require.NoError(t, err)
+	assert.Equal(t, stores.DefaultName, store.GetName())
+
+	_, err = ds.ReadAuthorizationModel(ctx, storeID, model.GetId())
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"group:admins#member@user:1",
+		"group:admins#member@user:2",
+		"group:users#member@user:2",
+	}, readAll(t, ds))
+
+	// The source tuples win over the local ones.
+	require.NoError(t, ds.Write(ctx, storeID, nil, storage.Writes{member("3", "users")}))
+
+	srv = source(t, member("1", "admins"), member("3", "admins"))
+
+	result, err = New(ds, srv.URL, storeID).Refresh(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, Result{Written: 1, Deleted: 3}, result)
+
+	assert.Equal(t, []string{
+		"group:admins#member@user:1",
+		"group:admins#member@user:3",
+	}, readAll(t, ds))
+}
+
+func TestRefreshSourceError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "no such store", http.StatusNotFound)
+	}))
+	t.Cleanup(srv.Close)
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	_, err := New(ds, srv.URL, storeID).Refresh(context.Background())
+	require.Error(t, err)
+	assert.Equal(t, maaserrors.NotFound, maaserrors.KindOf(err))
+	assert.ErrorContains(t, err, "no such store")
+
+	// Nothing is created until the source could be read.
+	_, err = ds.GetStore(context.Background(), storeID)
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+}
@@ -0,0 +1,106 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package model
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maas.io/core/src/maasopenfga/internal/assertions"
+)
+
+// TestBannedRevokesEveryPermission checks every permission of the model,
+// so that new ones can't forget to exclude banned users. Other intended
+// permissions are asserted in internal/migrations/testdata.
+func TestBannedRevokesEveryPermission(t *testing.T) {
+	ctx := context.Background()
+
+	versions := Versions()
+
+	model, err := Load(versions[len(versions)-1])
+	require.NoError(t, err)
+
+	store, err := assertions.NewStore(ctx, model)
+	require.NoError(t, err)
+	t.Cleanup(store.Close)
+
+	objects := map[string]string{
+		"maas":          "maas:0",
+		"pool":          "pool:1",
+		"zone":          "zone:1",
+		"fabric":        "fabric:1",
+		"vlan":          "vlan:1",
+		"boot_resource": "boot_resource:1",
+		"tag":           "tag:1",
+		"machine":       "machine:1",
+	}
+	// Relations to other objects, rather than permissions.
+	links := []string{"banned", "parent", "fabric", "pool", "tag"}
+
+	tuples := []assertions.Tuple{
+		{User: "user:1", Relation: "member", Object: "group:1"},
+		{User: "user:2", Relation: "member", Object: "group:1"},
+		{User: "user:2", Relation: "banned", Object: "maas:0"},
+		{User: "maas:0", Relation: "parent", Object: "pool:1"},
+		{User: "maas:0", Relation: "parent", Object: "zone:1"},
+		{User: "maas:0", Relation: "parent", Object: "fabric:1"},
+		{User: "fabric:1", Relation: "fabric", Object: "vlan:1"},
+		{User: "maas:0", Relation: "parent", Object: "boot_resource:1"},
+		{User: "maas:0", Relation: "parent", Object: "tag:1"},
+		{User: "pool:1", Relation: "pool", Object: "machine:1"},
+		{User: "tag:1", Relation: "tag", Object: "machine:1"},
+	}
+
+	permissions := map[string][]string{}
+
+	for _, typeDef := range model.GetTypeDefinitions() {
+		for relation := range typeDef.GetRelations() {
+			if !slices.Contains(links, relation) {
+				permissions[typeDef.GetType()] = append(permissions[typeDef.GetType()], relation)
+			}
+		}
+	}
+
+	for _, permission := range permissions["maas"] {
+		tuples = append(tuples, assertions.Tuple{
+			User: "group:1#member", Relation: permission, Object: "maas:0",
+		})
+	}
+
+	require.NoError(t, store.Write(ctx, tuples...))
+
+	for objectType, relations := range permissions {
+		if objectType == "group" {
+			continue
+		}
+
+		object, ok := objects[objectType]
+		require.True(t, ok, "no test object of type %s", objectType)
+
+		for _, relation := range relations {
+			allowed, err := store.Check(ctx, "user:1", relation, object)
+			require.NoError(t, err)
+			assert.True(t, allowed, "user:1 %s %s", relation, object)
+
+			allowed, err = store.Check(ctx, "user:2", relation, object)
+			require.NoError(t, err)
+			assert.False(t, allowed, "user:2 %s %s", relation, object)
+		}
+	}
+}
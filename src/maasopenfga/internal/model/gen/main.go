@@ -13,40 +13,37 @@
 // You should have received a copy of the GNU Affero General Public License
 // along with this program.  If not, see <http://www.gnu.org/licenses/>.
 
+// gen writes the payload of every model source of the current directory.
 package main
 
 import (
-	"fmt"
+	"log"
 	"os"
-	"time"
+	"path/filepath"
 
-	_ "github.com/jackc/pgx/v5/stdlib"
-
-	"github.com/openfga/openfga/pkg/logger"
-	"github.com/openfga/openfga/pkg/storage/migrate"
+	"maas.io/core/src/maasopenfga/internal/model"
 )
 
-// Tested in the integration tests of the dbupgrade django command.
 func main() {
-	if len(os.Args) != 2 {
-		fmt.Fprintf(os.Stderr, "usage: %s <datastore-uri>\n", os.Args[0])
-		os.Exit(1)
-	}
-
-	uri := os.Args[1]
-
-	log := logger.MustNewLogger("text", "info", "Unix")
-
-	cfg := migrate.MigrationConfig{
-		Engine:        "postgres",
-		URI:           uri,
-		TargetVersion: 0, // migrate to latest
-		Timeout:       time.Second * 30,
-		Verbose:       true,
-		Logger:        log,
+	sources, err := filepath.Glob("*.fga")
+	if err != nil {
+		log.Fatal(err)
 	}
 
-	if err := migrate.RunMigrations(cfg); err != nil {
-		panic(err)
+	for _, source := range sources {
+		dsl, err := os.ReadFile(filepath.Clean(source))
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		payload, err := model.Generate(string(dsl))
+		if err != nil {
+			log.Fatalf("%s: %v", source, err)
+		}
+
+		//nolint:gosec // generated files are not secret
+		if err := os.WriteFile(model.PayloadFile(source), payload, 0o644); err != nil {
+			log.Fatal(err)
+		}
 	}
 }
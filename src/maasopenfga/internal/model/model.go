@@ -0,0 +1,170 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package model holds the MAAS authorization model.
+//
+// Every version of the model is written in the OpenFGA DSL in a
+// <version>.fga file, which is the single source of truth. `go generate`
+// turns each of them into <version>.json, the payload installed by
+// migrations. Keeping the payload generated (and reviewed) means that an
+// update of the DSL parser never changes what a past migration installs.
+//
+// Tests fail if a payload is missing or out of date.
+package model
+
+//go:generate go run ./gen
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	parser "github.com/openfga/language/pkg/go/transformer"
+	"github.com/pmezard/go-difflib/difflib"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+const (
+	sourceExt  = ".fga"
+	payloadExt = ".json"
+)
+
+// files embeds the model sources and their generated payloads.
+//
+//go:embed *.fga *.json
+var files embed.FS
+
+// Versions returns the available model versions, oldest first.
+func Versions() []string {
+	sources, err := fs.Glob(files, "*"+sourceExt)
+	if err != nil {
+		panic(err) // The pattern is valid.
+	}
+
+	versions := make([]string, 0, len(sources))
+	for _, source := range sources {
+		versions = append(versions, strings.TrimSuffix(source, sourceExt))
+	}
+
+	slices.SortFunc(versions, compareVersions)
+
+	return versions
+}
+
+// DSL returns the source of the given model version.
+func DSL(version string) (string, error) {
+	data, err := files.ReadFile(version + sourceExt)
+	if err != nil {
+		return "", fmt.Errorf("unknown model version %q", version)
+	}
+
+	return string(data), nil
+}
+
+// Load returns the given model version, as installed by migrations.
+// The model ID is left empty.
+func Load(version string) (*openfgav1.AuthorizationModel, error) {
+	data, err := files.ReadFile(version + payloadExt)
+	if err != nil {
+		return nil, fmt.Errorf("missing payload of model version %q, run go generate: %w",
+			version, err)
+	}
+
+	var model openfgav1.AuthorizationModel
+	if err := protojson.Unmarshal(data, &model); err != nil {
+		return nil, fmt.Errorf("invalid payload of model version %q: %w", version, err)
+	}
+
+	return &model, nil
+}
+
+// Generate parses a model DSL and returns its payload.
+func Generate(dsl string) ([]byte, error) {
+	model, err := parser.TransformDSLToProto(dsl)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := protojson.Marshal(model)
+	if err != nil {
+		return nil, err
+	}
+
+	// protojson output is deliberately unstable, normalise it.
+	var compact, out bytes.Buffer
+	if err := json.Compact(&compact, data); err != nil {
+		return nil, err
+	}
+
+	if err := json.Indent(&out, compact.Bytes(), "", "  "); err != nil {
+		return nil, err
+	}
+
+	out.WriteByte('\n')
+
+	return out.Bytes(), nil
+}
+
+// Diff returns a unified diff from the given model version to model, or an
+// empty string if they match. Both sides are rendered by the DSL
+// transformer, so comments and formatting of the source are ignored.
+func Diff(version string, model *openfgav1.AuthorizationModel) (string, error) {
+	want, err := Load(version)
+	if err != nil {
+		return "", err
+	}
+
+	wantDSL, err := parser.TransformJSONProtoToDSL(want)
+	if err != nil {
+		return "", fmt.Errorf("failed to render model version %q: %w", version, err)
+	}
+
+	gotDSL, err := parser.TransformJSONProtoToDSL(model)
+	if err != nil {
+		return "", fmt.Errorf("failed to render authorization model: %w", err)
+	}
+
+	if wantDSL == gotDSL {
+		return "", nil
+	}
+
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(wantDSL),
+		B:        difflib.SplitLines(gotDSL),
+		FromFile: version + sourceExt,
+		ToFile:   model.GetId(),
+		Context:  3,
+	})
+}
+
+// PayloadFile returns the name of the payload generated from source.
+func PayloadFile(source string) string {
+	return strings.TrimSuffix(path.Base(source), sourceExt) + payloadExt
+}
+
+// compareVersions orders "v2" before "v10".
+func compareVersions(a, b string) int {
+	if len(a) != len(b) {
+		return len(a) - len(b)
+	}
+
+	return strings.Compare(a, b)
+}
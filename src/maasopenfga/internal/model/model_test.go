@@ -0,0 +1,103 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package model
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/openfga/openfga/pkg/typesystem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersions(t *testing.T) {
+	versions := Versions()
+	require.NotEmpty(t, versions)
+	assert.Equal(t, "v1", versions[0])
+
+	versions = []string{"v10", "v2", "v1"}
+	slices.SortFunc(versions, compareVersions)
+	assert.Equal(t, []string{"v1", "v2", "v10"}, versions)
+}
+
+// TestPayloadsUpToDate ensures the DSL stays the single source of truth.
+func TestPayloadsUpToDate(t *testing.T) {
+	for _, version := range Versions() {
+		t.Run(version, func(t *testing.T) {
+			dsl, err := DSL(version)
+			require.NoError(t, err)
+
+			want, err := Generate(dsl)
+			require.NoError(t, err)
+
+			got, err := files.ReadFile(version + payloadExt)
+			require.NoError(t, err, "run go generate ./internal/model")
+
+			assert.Equal(t, string(want), string(got),
+				"%s%s is out of date, run go generate ./internal/model", version, payloadExt)
+		})
+	}
+}
+
+func TestModelsValid(t *testing.T) {
+	for _, version := range Versions() {
+		t.Run(version, func(t *testing.T) {
+			model, err := Load(version)
+			require.NoError(t, err)
+
+			_, err = typesystem.NewAndValidate(context.Background(), model)
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	versions := Versions()
+	latest := versions[len(versions)-1]
+
+	model, err := Load(latest)
+	require.NoError(t, err)
+
+	model.Id = "00000000000000000000000001"
+
+	diff, err := Diff(latest, model)
+	require.NoError(t, err)
+	assert.Empty(t, diff, "the model ID is not part of the DSL")
+
+	previous, err := Load(versions[len(versions)-2])
+	require.NoError(t, err)
+
+	previous.Id = "00000000000000000000000001"
+
+	diff, err = Diff(latest, previous)
+	require.NoError(t, err)
+	assert.Contains(t, diff, "--- "+latest+sourceExt)
+	assert.Contains(t, diff, "+++ 00000000000000000000000001")
+	assert.Contains(t, diff, "\n-type machine\n")
+
+	_, err = Diff("v0", model)
+	assert.ErrorContains(t, err, "run go generate")
+}
+
+func TestUnknownVersion(t *testing.T) {
+	_, err := DSL("v0")
+	assert.ErrorContains(t, err, `unknown model version "v0"`)
+
+	_, err = Load("v0")
+	assert.ErrorContains(t, err, "run go generate")
+}
@@ -0,0 +1,344 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package modelupgrade installs new versions of the MAAS authorization model
+// and rewrites existing tuples to match them, within the transaction of a
+// migration.
+//
+// Changes between model versions are declared with a Mapping (renamed
+// relations and types, removed relations) rather than written as SQL, so
+// that they can also be reverted and applied to every store.
+package modelupgrade
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	sq "github.com/Masterminds/squirrel"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"google.golang.org/protobuf/proto"
+	authzmodel "maas.io/core/src/maasopenfga/internal/model"
+)
+
+// Upgrade installs a model version and rewrites the tuples of the previous
+// version according to Mapping.
+type Upgrade struct {
+	// Version is the model version to install, e.g. "v5".
+	Version string
+	// ModelID is the ID of the installed model. It must sort after the ID
+	// of the previous model, since OpenFGA uses the greatest one. Clients
+	// pinning a model ID must be updated to use it.
+	ModelID string
+	// Mapping translates the tuples of the previous version.
+	Mapping Mapping
+}
+
+// Apply installs the model in store and rewrites its tuples.
+func (u Upgrade) Apply(ctx context.Context, tx *sql.Tx, store string) error {
+	model, err := authzmodel.Load(u.Version)
+	if err != nil {
+		return err
+	}
+
+	if err := u.Mapping.Validate(model); err != nil {
+		return fmt.Errorf("invalid mapping to model %s: %w", u.Version, err)
+	}
+
+	if err := install(ctx, tx, store, model, u.ModelID); err != nil {
+		return fmt.Errorf("failed to install model %s: %w", u.Version, err)
+	}
+
+	if err := u.Mapping.Apply(ctx, tx, store); err != nil {
+		return fmt.Errorf("failed to rewrite tuples for model %s: %w", u.Version, err)
+	}
+
+	return nil
+}
+
+// Revert rewrites the tuples of store back and deletes the model. Tuples of
+// dropped relations are not restored.
+func (u Upgrade) Revert(ctx context.Context, tx *sql.Tx, store string) error {
+	if err := u.Mapping.Inverse().Apply(ctx, tx, store); err != nil {
+		return fmt.Errorf("failed to rewrite tuples back from model %s: %w", u.Version, err)
+	}
+
+	if err := Uninstall(ctx, tx, store, u.ModelID); err != nil {
+		return fmt.Errorf("failed to delete model %s: %w", u.Version, err)
+	}
+
+	return nil
+}
+
+// Install installs the given model version in store under modelID.
+func Install(ctx context.Context, tx *sql.Tx, store, version, modelID string) error {
+	model, err := authzmodel.Load(version)
+	if err != nil {
+		return err
+	}
+
+	return install(ctx, tx, store, model, modelID)
+}
+
+func install(ctx context.Context, tx *sql.Tx, store string, model *openfgav1.AuthorizationModel, modelID string) error {
+	// The ID in the protobuf and in the database must be set and match, otherwise openfga will not work properly with this model.
+	model = proto.CloneOf(model)
+	model.Id = modelID
+
+	pbdata, err := proto.Marshal(model)
+	if err != nil {
+		return err
+	}
+
+	stmt, args, err := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+		Insert("openfga.authorization_model").
+		Columns("store", "authorization_model_id", "schema_version", "type", "type_definition", "serialized_protobuf").
+		Values(store, model.GetId(), model.GetSchemaVersion(), "", nil, pbdata).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, stmt, args...)
+
+	return err
+}
+
+// Uninstall deletes the model modelID of store.
+func Uninstall(ctx context.Context, tx *sql.Tx, store, modelID string) error {
+	stmt, args, err := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+		Delete("openfga.authorization_model").
+		Where(sq.Eq{"store": store, "authorization_model_id": modelID}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, stmt, args...)
+
+	return err
+}
+
+// Mapping translates tuples from a model version to the next one. Relations
+// are referred to by the type name of the previous version, and renames
+// must not chain (a renamed relation can't be the source of another
+// rename).
+type Mapping struct {
+	// Relations renames relations, by object type and then by relation,
+	// e.g. {"pool": {"can_edit": "can_edit_machines"}}. Usersets referring
+	// to the relation (pool:1#can_edit) are renamed too.
+	Relations map[string]map[string]string
+	// Types renames types, of objects as well as of users and usersets.
+	Types map[string]string
+	// Dropped lists the relations removed by the new version, by object
+	// type. Their tuples and the usersets referring to them are deleted.
+	Dropped map[string][]string
+}
+
+// Inverse returns the mapping translating tuples back. Dropped relations
+// can't be restored and are left out.
+func (m Mapping) Inverse() Mapping {
+	inverse := Mapping{
+		Relations: make(map[string]map[string]string, len(m.Relations)),
+		Types:     make(map[string]string, len(m.Types)),
+	}
+
+	for objectType, relations := range m.Relations {
+		renamed := make(map[string]string, len(relations))
+		for from, to := range relations {
+			renamed[to] = from
+		}
+
+		// Relations are renamed before types: translating back, tuples still
+		// have the new type name when their relations are renamed.
+		if newType, ok := m.Types[objectType]; ok {
+			objectType = newType
+		}
+
+		inverse.Relations[objectType] = renamed
+	}
+
+	for from, to := range m.Types {
+		inverse.Types[to] = from
+	}
+
+	return inverse
+}
+
+// Validate checks that the renamed relations and types exist in model, the
+// model the tuples are translated to.
+func (m Mapping) Validate(model *openfgav1.AuthorizationModel) error {
+	relations := map[string][]string{}
+	for _, typeDef := range model.GetTypeDefinitions() {
+		relations[typeDef.GetType()] = slices.Collect(maps.Keys(typeDef.GetRelations()))
+	}
+
+	var errs []error
+
+	for _, from := range slices.Sorted(maps.Keys(m.Types)) {
+		if _, ok := relations[m.Types[from]]; !ok {
+			errs = append(errs, fmt.Errorf("type %q is renamed to unknown type %q", from, m.Types[from]))
+		}
+	}
+
+	for _, objectType := range slices.Sorted(maps.Keys(m.Relations)) {
+		newType := objectType
+		if renamed, ok := m.Types[objectType]; ok {
+			newType = renamed
+		}
+
+		for _, from := range slices.Sorted(maps.Keys(m.Relations[objectType])) {
+			to := m.Relations[objectType][from]
+			if _, ok := m.Relations[objectType][to]; ok {
+				errs = append(errs, fmt.Errorf("relation %s#%s is renamed to %q, which is renamed too",
+					objectType, from, to))
+			}
+
+			if !slices.Contains(relations[newType], to) {
+				errs = append(errs, fmt.Errorf("relation %s#%s is renamed to unknown relation %s#%s",
+					objectType, from, newType, to))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Apply rewrites the tuples of store: dropped relations are deleted first,
+// then relations and finally types are renamed.
+func (m Mapping) Apply(ctx context.Context, tx *sql.Tx, store string) error {
+	for _, r := range m.rewrites() {
+		for _, stmt := range r.statements() {
+			if _, err := tx.ExecContext(ctx, stmt, append([]any{store}, r.args...)...); err != nil {
+				return fmt.Errorf("failed to %s: %w", r.desc, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// rewrites returns the rewrites of m, in a stable order.
+func (m Mapping) rewrites() []rewrite {
+	var rewrites []rewrite
+
+	for _, objectType := range slices.Sorted(maps.Keys(m.Dropped)) {
+		for _, relation := range slices.Sorted(slices.Values(m.Dropped[objectType])) {
+			rewrites = append(rewrites,
+				rewrite{
+					desc:   fmt.Sprintf("delete %s#%s tuples", objectType, relation),
+					cond:   "t.object_type = $2 AND t.relation = $3",
+					args:   []any{objectType, relation},
+					delete: true,
+				},
+				rewrite{
+					desc:   fmt.Sprintf("delete %s#%s usersets", objectType, relation),
+					cond:   usersetCond,
+					args:   []any{objectType, relation},
+					delete: true,
+				})
+		}
+	}
+
+	for _, objectType := range slices.Sorted(maps.Keys(m.Relations)) {
+		relations := m.Relations[objectType]
+		for _, from := range slices.Sorted(maps.Keys(relations)) {
+			rewrites = append(rewrites,
+				rewrite{
+					desc:     fmt.Sprintf("rename %s#%s tuples", objectType, from),
+					cond:     "t.object_type = $2 AND t.relation = $3",
+					args:     []any{objectType, from, relations[from]},
+					relation: "$4",
+				},
+				rewrite{
+					desc: fmt.Sprintf("rename %s#%s usersets", objectType, from),
+					cond: usersetCond,
+					args: []any{objectType, from, relations[from]},
+					user: "split_part(t._user, '#', 1) || '#' || $4",
+				})
+		}
+	}
+
+	for _, from := range slices.Sorted(maps.Keys(m.Types)) {
+		rewrites = append(rewrites,
+			rewrite{
+				desc:       fmt.Sprintf("rename %s objects", from),
+				cond:       "t.object_type = $2",
+				args:       []any{from, m.Types[from]},
+				objectType: "$3",
+			},
+			rewrite{
+				desc: fmt.Sprintf("rename %s users", from),
+				cond: "split_part(t._user, ':', 1) = $2",
+				args: []any{from, m.Types[from]},
+				user: "$3 || substr(t._user, length($2) + 1)",
+			})
+	}
+
+	return rewrites
+}
+
+// usersetCond matches the usersets of object type $2 and relation $3, e.g.
+// group:1#member.
+const usersetCond = "t.user_type = 'userset' AND split_part(t._user, ':', 1) = $2 " +
+	"AND split_part(t._user, '#', 2) = $3"
+
+// rewrite deletes or updates the tuples matching cond, whose $1 is the store
+// and $2... are args. New values are SQL expressions of the tuple columns,
+// the unchanged ones are left empty.
+type rewrite struct {
+	desc       string
+	cond       string
+	args       []any
+	delete     bool
+	objectType string
+	relation   string
+	user       string
+}
+
+func (r rewrite) statements() []string {
+	where := "t.store = $1 AND " + r.cond
+	if r.delete {
+		return []string{"DELETE FROM openfga.tuple t WHERE " + where}
+	}
+
+	objectType := orColumn(r.objectType, "t.object_type")
+	relation := orColumn(r.relation, "t.relation")
+	user := orColumn(r.user, "t._user")
+
+	return []string{
+		// Tuples already existing under the new key would conflict.
+		"DELETE FROM openfga.tuple t WHERE " + where + " AND EXISTS (" +
+			"SELECT 1 FROM openfga.tuple d WHERE d.store = t.store AND d.object_id = t.object_id" +
+			" AND d.object_type = " + objectType +
+			" AND d.relation = " + relation +
+			" AND d._user = " + user + ")",
+		"UPDATE openfga.tuple t SET object_type = " + objectType +
+			", relation = " + relation +
+			", _user = " + user +
+			" WHERE " + where,
+	}
+}
+
+func orColumn(expr, column string) string {
+	if expr == "" {
+		return column
+	}
+
+	return expr
+}
@@ -0,0 +1,141 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package modelupgrade
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authzmodel "maas.io/core/src/maasopenfga/internal/model"
+)
+
+func TestInverse(t *testing.T) {
+	m := Mapping{
+		Relations: map[string]map[string]string{
+			"pool": {"can_edit": "can_edit_machines"},
+			"tag":  {"tagged": "tag"},
+		},
+		Types:   map[string]string{"pool": "resource_pool"},
+		Dropped: map[string][]string{"maas": {"obsolete"}},
+	}
+
+	assert.Equal(t, Mapping{
+		Relations: map[string]map[string]string{
+			"resource_pool": {"can_edit_machines": "can_edit"},
+			"tag":           {"tag": "tagged"},
+		},
+		Types: map[string]string{"resource_pool": "pool"},
+	}, m.Inverse())
+}
+
+func TestValidate(t *testing.T) {
+	model, err := authzmodel.Load("v4")
+	require.NoError(t, err)
+
+	testcases := map[string]struct {
+		mapping Mapping
+		err     string
+	}{
+		"empty": {},
+		"valid": {
+			mapping: Mapping{
+				Relations: map[string]map[string]string{"pool": {"can_edit": "can_edit_machines"}},
+				Dropped:   map[string][]string{"maas": {"obsolete"}},
+			},
+		},
+		"renamed type": {
+			mapping: Mapping{
+				Relations: map[string]map[string]string{"resource_pool": {"can_edit": "can_edit_machines"}},
+				Types:     map[string]string{"resource_pool": "pool"},
+			},
+		},
+		"unknown relation": {
+			mapping: Mapping{
+				Relations: map[string]map[string]string{"pool": {"can_edit": "can_edit_everything"}},
+			},
+			err: `relation pool#can_edit is renamed to unknown relation pool#can_edit_everything`,
+		},
+		"unknown type": {
+			mapping: Mapping{Types: map[string]string{"pool": "resource_pool"}},
+			err:     `type "pool" is renamed to unknown type "resource_pool"`,
+		},
+		"chained renames": {
+			mapping: Mapping{
+				Relations: map[string]map[string]string{
+					"pool": {"can_edit": "can_deploy_machines", "can_deploy_machines": "can_view_machines"},
+				},
+			},
+			err: `relation pool#can_edit is renamed to "can_deploy_machines", which is renamed too`,
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			err := tc.mapping.Validate(model)
+			if tc.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tc.err)
+			}
+		})
+	}
+}
+
+func TestRewrites(t *testing.T) {
+	m := Mapping{
+		Relations: map[string]map[string]string{"pool": {"can_edit": "can_edit_machines"}},
+		Types:     map[string]string{"resource_pool": "pool"},
+		Dropped:   map[string][]string{"maas": {"obsolete"}},
+	}
+
+	var descs []string
+
+	placeholder := regexp.MustCompile(`\$(\d+)`)
+
+	for _, r := range m.rewrites() {
+		descs = append(descs, r.desc)
+
+		for _, stmt := range r.statements() {
+			used := map[int]bool{}
+			for _, match := range placeholder.FindAllStringSubmatch(stmt, -1) {
+				n, err := strconv.Atoi(match[1])
+				require.NoError(t, err)
+
+				used[n] = true
+			}
+
+			// $1 is the store, every argument must be used by every statement.
+			for n := 1; n <= len(r.args)+1; n++ {
+				assert.True(t, used[n], fmt.Sprintf("%s: $%d is unused in %q", r.desc, n, stmt))
+			}
+
+			assert.Len(t, used, len(r.args)+1, r.desc)
+		}
+	}
+
+	assert.Equal(t, []string{
+		"delete maas#obsolete tuples",
+		"delete maas#obsolete usersets",
+		"rename pool#can_edit tuples",
+		"rename pool#can_edit usersets",
+		"rename resource_pool objects",
+		"rename resource_pool users",
+	}, descs)
+}
@@ -0,0 +1,238 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package priority sorts OpenFGA requests into interactive and batch
+// classes and admits batch requests only while there is spare capacity, so
+// that background tuple churn doesn't add latency to UI-driven checks.
+package priority
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Header carries the class of a request. Requests without it are
+// interactive.
+const Header = "MAAS-Request-Priority"
+
+// Class is the priority class of a request.
+type Class string
+
+const (
+	// Interactive requests are made on behalf of a user waiting for the
+	// answer, e.g. permission checks of API and UI requests.
+	Interactive Class = "interactive"
+	// Batch requests come from background work, e.g. replication or tuple
+	// garbage collection, and may be delayed.
+	Batch Class = "batch"
+)
+
+// Classes lists all the priority classes.
+var Classes = []Class{Interactive, Batch}
+
+var (
+	waitHistogram = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "maas_openfga",
+		Name:      "admission_wait_seconds",
+		Help:      "Time requests waited to be admitted, by priority class.",
+		Buckets:   prometheus.ExponentialBuckets(0.001, 2, 14),
+	}, []string{"class"})
+
+	durationHistogram = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "maas_openfga",
+		Name:      "request_duration_seconds",
+		Help:      "Time to answer requests, admission included, by priority class.",
+		Buckets:   prometheus.ExponentialBuckets(0.001, 2, 14),
+	}, []string{"class"})
+
+	inFlightGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "maas_openfga",
+		Name:      "requests_in_flight",
+		Help:      "Number of admitted requests being served, by priority class.",
+	}, []string{"class"})
+
+	rejectedCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "maas_openfga",
+		Name:      "admission_rejected_total",
+		Help:      "Number of requests given up while waiting to be admitted, by priority class.",
+	}, []string{"class"})
+)
+
+// ParseClass parses the value of the priority header.
+func ParseClass(value string) (Class, error) {
+	if value == "" {
+		return Interactive, nil
+	}
+
+	class := Class(value)
+	if !slices.Contains(Classes, class) {
+		return "", fmt.Errorf("unknown priority class %q", value)
+	}
+
+	return class, nil
+}
+
+// Admission decides when requests are served. Interactive requests are
+// always admitted straight away. Batch requests are admitted in arrival
+// order, at most maxBatch at a time and only while fewer than maxInFlight
+// requests of any class are being served, which is meant to match the size
+// of the database connection pool.
+type Admission struct {
+	maxBatch    int
+	maxInFlight int
+
+	mu          sync.Mutex
+	interactive int
+	batch       int
+	waiting     []chan struct{}
+}
+
+// NewAdmission returns an Admission with the given limits, raised to 1 if
+// lower so that batch requests are never blocked forever on an idle server.
+func NewAdmission(maxBatch, maxInFlight int) *Admission {
+	return &Admission{
+		maxBatch:    max(maxBatch, 1),
+		maxInFlight: max(maxInFlight, 1),
+	}
+}
+
+// Acquire waits until a request of the given class is admitted or ctx is
+// done. On success, release must be called once the request is served.
+func (a *Admission) Acquire(ctx context.Context, class Class) (func(), error) {
+	a.mu.Lock()
+
+	if class == Interactive {
+		a.interactive++
+		a.mu.Unlock()
+
+		return a.releaseFunc(class), nil
+	}
+
+	if len(a.waiting) == 0 && a.batchAllowed() {
+		a.batch++
+		a.mu.Unlock()
+
+		return a.releaseFunc(class), nil
+	}
+
+	admitted := make(chan struct{})
+	a.waiting = append(a.waiting, admitted)
+	a.mu.Unlock()
+
+	select {
+	case <-admitted:
+		return a.releaseFunc(class), nil
+	case <-ctx.Done():
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if i := slices.Index(a.waiting, admitted); i >= 0 {
+		a.waiting = slices.Delete(a.waiting, i, i+1)
+		return nil, ctx.Err()
+	}
+
+	// Admitted while giving up, hand the slot over.
+	a.batch--
+	a.dispatch()
+
+	return nil, ctx.Err()
+}
+
+func (a *Admission) releaseFunc(class Class) func() {
+	var once sync.Once
+
+	return func() {
+		once.Do(func() {
+			a.mu.Lock()
+			defer a.mu.Unlock()
+
+			if class == Interactive {
+				a.interactive--
+			} else {
+				a.batch--
+			}
+
+			a.dispatch()
+		})
+	}
+}
+
+// batchAllowed reports whether one more batch request may be served.
+// a.mu must be held.
+func (a *Admission) batchAllowed() bool {
+	return a.batch < a.maxBatch && a.interactive+a.batch < a.maxInFlight
+}
+
+// dispatch admits waiting batch requests while there is capacity.
+// a.mu must be held.
+func (a *Admission) dispatch() {
+	for len(a.waiting) > 0 && a.batchAllowed() {
+		close(a.waiting[0])
+		a.waiting = a.waiting[1:]
+		a.batch++
+	}
+}
+
+// Handler admits requests to next according to their priority class and
+// records per-class latency metrics. Errors are written through mux, like
+// the OpenFGA API errors.
+func (a *Admission) Handler(mux *runtime.ServeMux, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		class, err := ParseClass(r.Header.Get(Header))
+		if err != nil {
+			runtime.HTTPError(r.Context(), mux, &runtime.JSONPb{}, w, r,
+				status.Errorf(codes.InvalidArgument, "invalid %s header: %v", Header, err))
+
+			return
+		}
+
+		release, err := a.Acquire(r.Context(), class)
+
+		waitHistogram.WithLabelValues(string(class)).Observe(time.Since(start).Seconds())
+
+		if err != nil {
+			rejectedCounter.WithLabelValues(string(class)).Inc()
+			runtime.HTTPError(r.Context(), mux, &runtime.JSONPb{}, w, r,
+				status.FromContextError(err).Err())
+
+			return
+		}
+
+		defer release()
+
+		inFlight := inFlightGauge.WithLabelValues(string(class))
+		inFlight.Inc()
+
+		defer inFlight.Dec()
+
+		next.ServeHTTP(w, r)
+
+		durationHistogram.WithLabelValues(string(class)).Observe(time.Since(start).Seconds())
+	})
+}
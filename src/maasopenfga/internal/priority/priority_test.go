@@ -0,0 +1,206 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package priority
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseClass(t *testing.T) {
+	class, err := ParseClass("")
+	require.NoError(t, err)
+	assert.Equal(t, Interactive, class)
+
+	class, err = ParseClass("batch")
+	require.NoError(t, err)
+	assert.Equal(t, Batch, class)
+
+	_, err = ParseClass("urgent")
+	assert.EqualError(t, err, `unknown priority class "urgent"`)
+}
+
+// admitted reports whether a batch request is admitted within a short
+// time, releasing it straight away if so.
+func admitted(t *testing.T, a *Admission) bool {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	release, err := a.Acquire(ctx, Batch)
+	if err != nil {
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		return false
+	}
+
+	release()
+
+	return true
+}
+
+func TestAdmissionInteractiveIsNeverQueued(t *testing.T) {
+	a := NewAdmission(1, 2)
+
+	batch, err := a.Acquire(context.Background(), Batch)
+	require.NoError(t, err)
+
+	defer batch()
+
+	for range 5 {
+		_, err := a.Acquire(context.Background(), Interactive)
+		require.NoError(t, err)
+	}
+}
+
+func TestAdmissionLimitsBatchRequests(t *testing.T) {
+	a := NewAdmission(1, 3)
+
+	release, err := a.Acquire(context.Background(), Batch)
+	require.NoError(t, err)
+
+	assert.False(t, admitted(t, a))
+
+	release()
+	assert.True(t, admitted(t, a))
+}
+
+func TestAdmissionDefersBatchToInteractive(t *testing.T) {
+	a := NewAdmission(2, 2)
+
+	first, err := a.Acquire(context.Background(), Interactive)
+	require.NoError(t, err)
+
+	second, err := a.Acquire(context.Background(), Interactive)
+	require.NoError(t, err)
+
+	assert.False(t, admitted(t, a))
+
+	done := make(chan error, 1)
+
+	go func() {
+		release, err := a.Acquire(context.Background(), Batch)
+		if err == nil {
+			release()
+		}
+
+		done <- err
+	}()
+
+	first()
+	second()
+
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("batch request wasn't admitted once interactive requests were served")
+	}
+}
+
+func TestAdmissionCancelledWaitKeepsOrder(t *testing.T) {
+	a := NewAdmission(1, 1)
+
+	release, err := a.Acquire(context.Background(), Batch)
+	require.NoError(t, err)
+
+	// A waiter that gives up leaves the queue.
+	assert.False(t, admitted(t, a))
+
+	release()
+	assert.True(t, admitted(t, a))
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	assert.Empty(t, a.waiting)
+	assert.Zero(t, a.batch)
+}
+
+func TestHandler(t *testing.T) {
+	testcases := map[string]struct {
+		header     string
+		busy       bool
+		wantStatus int
+		wantCalled bool
+	}{
+		"default": {
+			wantStatus: http.StatusOK,
+			wantCalled: true,
+		},
+		"interactive while busy": {
+			header:     "interactive",
+			busy:       true,
+			wantStatus: http.StatusOK,
+			wantCalled: true,
+		},
+		"batch": {
+			header:     "batch",
+			wantStatus: http.StatusOK,
+			wantCalled: true,
+		},
+		"batch while busy": {
+			header:     "batch",
+			busy:       true,
+			wantStatus: http.StatusGatewayTimeout,
+		},
+		"invalid": {
+			header:     "urgent",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			a := NewAdmission(1, 1)
+
+			if tc.busy {
+				release, err := a.Acquire(context.Background(), Interactive)
+				require.NoError(t, err)
+
+				defer release()
+			}
+
+			var called bool
+
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				called = true
+			})
+
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+
+			req := httptest.NewRequestWithContext(ctx, http.MethodPost, "/stores/store/check", nil)
+			if tc.header != "" {
+				req.Header.Set(Header, tc.header)
+			}
+
+			rec := httptest.NewRecorder()
+
+			a.Handler(runtime.NewServeMux(), next).ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.wantStatus, rec.Code)
+			assert.Equal(t, tc.wantCalled, called)
+		})
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package rbacimport
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	tupleUtils "github.com/openfga/openfga/pkg/tuple"
+	"maas.io/core/src/maasopenfga/internal/tuples"
+)
+
+type sqlDirectory struct {
+	db *sql.DB
+}
+
+// NewDirectory returns a Directory reading the MAAS tables of db.
+func NewDirectory(db *sql.DB) Directory {
+	return &sqlDirectory{db: db}
+}
+
+func (d *sqlDirectory) GroupID(ctx context.Context, name string) (int64, bool, error) {
+	stmt, args, err := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+		Select("id").
+		From("maasserver_usergroup").
+		Where(sq.Eq{"name": name}).
+		ToSql()
+	if err != nil {
+		return 0, false, err
+	}
+
+	var id int64
+
+	err = d.db.QueryRowContext(ctx, stmt, args...).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+
+	return id, err == nil, err
+}
+
+func (d *sqlDirectory) PoolExists(ctx context.Context, id int64) (bool, error) {
+	stmt, args, err := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+		Select("1").
+		From("maasserver_resourcepool").
+		Where(sq.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return false, err
+	}
+
+	var one int
+
+	err = d.db.QueryRowContext(ctx, stmt, args...).Scan(&one)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+// Apply writes the tuples to the store in a single transaction. Tuples that
+// already exist are rewritten, which reconfirms them.
+func Apply(ctx context.Context, db *sql.DB, storeID string, toWrite []Tuple) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	for _, t := range toWrite {
+		if err := tuples.Write(ctx, tx, storeID, tupleUtils.NewTupleKey(t.Object, t.Relation, t.User)); err != nil {
+			return errors.Join(err, tx.Rollback())
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,290 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package rbacimport converts role assignments exported from the external
+// RBAC service MAAS used to integrate with into OpenFGA tuples.
+//
+// The export lists the roles users and groups have on resource pools:
+//
+//	{
+//	  "assignments": [
+//	    {"group": "ops", "role": "operator", "resource-type": "resource-pool", "resources": ["1", "2"]},
+//	    {"group": "admins", "role": "administrator", "resource-type": "resource-pool", "resources": [""]}
+//	  ]
+//	}
+//
+// An empty resource identifier stands for all resource pools, which maps to
+// the maas:0 object. Each role expands to the legacy permissions it grants,
+// and each permission maps to a relation of the model.
+//
+// The model only grants permissions to MAAS groups, so group assignments
+// are imported for the MAAS group of the same name, and user assignments
+// are reported as skipped: assign the role to a group of the user instead.
+package rbacimport
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"text/tabwriter"
+
+	tupleUtils "github.com/openfga/openfga/pkg/tuple"
+)
+
+const (
+	resourceTypePool = "resource-pool"
+	allResources     = ""
+)
+
+// rolePermissions are the legacy permissions of each RBAC role.
+var rolePermissions = map[string][]string{
+	"administrator": {"view", "view-all", "deploy-machines", "admin-machines", "edit"},
+	"operator":      {"view", "view-all", "deploy-machines", "admin-machines"},
+	"user":          {"view", "deploy-machines"},
+	"auditor":       {"view", "view-all"},
+}
+
+// permissionRelations maps legacy permissions to relations shared by the
+// maas and pool types. Pool management ("edit") has no equivalent.
+var permissionRelations = map[string]string{
+	"view":            "can_view_available_machines",
+	"view-all":        "can_view_machines",
+	"deploy-machines": "can_deploy_machines",
+	"admin-machines":  "can_edit_machines",
+}
+
+// Assignment is a role given to a user or a group on resources.
+type Assignment struct {
+	User         string   `json:"user,omitempty"`
+	Group        string   `json:"group,omitempty"`
+	Role         string   `json:"role"`
+	ResourceType string   `json:"resource-type"`
+	Resources    []string `json:"resources"`
+}
+
+func (a Assignment) principal() string {
+	if a.Group != "" {
+		return "group " + a.Group
+	}
+
+	return "user " + a.User
+}
+
+// Export is an export of the RBAC service.
+type Export struct {
+	Assignments []Assignment `json:"assignments"`
+}
+
+// Parse reads an export.
+func Parse(r io.Reader) (*Export, error) {
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+
+	var export Export
+	if err := decoder.Decode(&export); err != nil {
+		return nil, fmt.Errorf("invalid RBAC export: %w", err)
+	}
+
+	for i, a := range export.Assignments {
+		if (a.User == "") == (a.Group == "") {
+			return nil, fmt.Errorf("invalid RBAC export: assignment %d must have either a user or a group", i+1)
+		}
+	}
+
+	return &export, nil
+}
+
+// Directory resolves the MAAS objects referenced by an export.
+type Directory interface {
+	// GroupID returns the ID of the MAAS group with the given name, if
+	// it exists.
+	GroupID(ctx context.Context, name string) (int64, bool, error)
+	// PoolExists reports whether the resource pool exists.
+	PoolExists(ctx context.Context, id int64) (bool, error)
+}
+
+// Entry is the outcome of importing a permission of an assignment on a
+// resource.
+type Entry struct {
+	Assignment Assignment
+	Permission string
+	Resource   string
+	// Tuple is the imported tuple, unset for skipped entries.
+	Tuple *Tuple
+	// Reason explains why the entry was skipped.
+	Reason string
+}
+
+// Tuple is a tuple to write.
+type Tuple struct {
+	User     string
+	Relation string
+	Object   string
+}
+
+// Report lists what an export maps to.
+type Report struct {
+	Entries []Entry
+}
+
+// Tuples returns the distinct tuples of the report.
+func (r *Report) Tuples() []Tuple {
+	var tuples []Tuple
+
+	for _, e := range r.Entries {
+		if e.Tuple != nil && !slices.Contains(tuples, *e.Tuple) {
+			tuples = append(tuples, *e.Tuple)
+		}
+	}
+
+	return tuples
+}
+
+// Skipped returns how many entries were not imported.
+func (r *Report) Skipped() int {
+	n := 0
+
+	for _, e := range r.Entries {
+		if e.Tuple == nil {
+			n++
+		}
+	}
+
+	return n
+}
+
+// Write writes the report as a table.
+func (r *Report) Write(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PRINCIPAL\tROLE\tPERMISSION\tRESOURCE\tRESULT")
+
+	for _, e := range r.Entries {
+		resource := e.Resource
+		if resource == allResources {
+			resource = "(all)"
+		}
+
+		result := "skipped: " + e.Reason
+		if e.Tuple != nil {
+			result = fmt.Sprintf("%s %s %s", e.Tuple.User, e.Tuple.Relation, e.Tuple.Object)
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n",
+			e.Assignment.principal(), e.Assignment.Role, e.Permission, resource, result)
+	}
+
+	return tw.Flush()
+}
+
+// Convert maps every assignment of the export to tuples.
+func Convert(ctx context.Context, dir Directory, export *Export) (*Report, error) {
+	report := &Report{}
+
+	for _, a := range export.Assignments {
+		entries, err := convert(ctx, dir, a)
+		if err != nil {
+			return nil, err
+		}
+
+		report.Entries = append(report.Entries, entries...)
+	}
+
+	return report, nil
+}
+
+func convert(ctx context.Context, dir Directory, a Assignment) ([]Entry, error) {
+	skip := func(reason string) []Entry {
+		return []Entry{{Assignment: a, Reason: reason}}
+	}
+
+	if a.ResourceType != resourceTypePool {
+		return skip(fmt.Sprintf("unsupported resource type %q", a.ResourceType)), nil
+	}
+
+	permissions, ok := rolePermissions[a.Role]
+	if !ok {
+		return skip(fmt.Sprintf("unknown role %q", a.Role)), nil
+	}
+
+	if a.User != "" {
+		return skip("roles of users can't be imported, assign the role to a group of the user"), nil
+	}
+
+	groupID, ok, err := dir.GroupID(ctx, a.Group)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up group %q: %w", a.Group, err)
+	}
+
+	if !ok {
+		return skip(fmt.Sprintf("no MAAS group named %q", a.Group)), nil
+	}
+
+	user := fmt.Sprintf("group:%d#member", groupID)
+
+	var entries []Entry
+
+	for _, resource := range a.Resources {
+		object, reason, err := resolvePool(ctx, dir, resource)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, permission := range permissions {
+			entry := Entry{Assignment: a, Permission: permission, Resource: resource}
+
+			relation, mapped := permissionRelations[permission]
+
+			switch {
+			case reason != "":
+				entry.Reason = reason
+			case !mapped:
+				entry.Reason = "no equivalent relation"
+			default:
+				entry.Tuple = &Tuple{User: user, Relation: relation, Object: object}
+			}
+
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries, nil
+}
+
+// resolvePool returns the object of a resource pool, or why it can't be
+// imported.
+func resolvePool(ctx context.Context, dir Directory, resource string) (string, string, error) {
+	if resource == allResources {
+		return "maas:0", "", nil
+	}
+
+	id, err := strconv.ParseInt(resource, 10, 64)
+	if err != nil {
+		return "", fmt.Sprintf("invalid resource pool %q", resource), nil
+	}
+
+	exists, err := dir.PoolExists(ctx, id)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to look up resource pool %d: %w", id, err)
+	}
+
+	if !exists {
+		return "", fmt.Sprintf("resource pool %d no longer exists", id), nil
+	}
+
+	return tupleUtils.BuildObject("pool", resource), "", nil
+}
@@ -0,0 +1,149 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package rbacimport
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeDirectory struct {
+	groups map[string]int64
+	pools  []int64
+}
+
+func (d *fakeDirectory) GroupID(_ context.Context, name string) (int64, bool, error) {
+	id, ok := d.groups[name]
+	return id, ok, nil
+}
+
+func (d *fakeDirectory) PoolExists(_ context.Context, id int64) (bool, error) {
+	for _, pool := range d.pools {
+		if pool == id {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func TestParse(t *testing.T) {
+	testcases := map[string]struct {
+		in  string
+		err string
+	}{
+		"valid": {
+			in: `{"assignments": [{"group": "ops", "role": "user", "resource-type": "resource-pool", "resources": [""]}]}`,
+		},
+		"unknown field": {
+			in:  `{"assignments": [{"groups": "ops"}]}`,
+			err: `unknown field "groups"`,
+		},
+		"no principal": {
+			in:  `{"assignments": [{"role": "user", "resource-type": "resource-pool", "resources": [""]}]}`,
+			err: "assignment 1 must have either a user or a group",
+		},
+		"user and group": {
+			in:  `{"assignments": [{"user": "alice", "group": "ops", "role": "user"}]}`,
+			err: "assignment 1 must have either a user or a group",
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tc.in))
+			if tc.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.ErrorContains(t, err, tc.err)
+		})
+	}
+}
+
+func TestConvert(t *testing.T) {
+	dir := &fakeDirectory{groups: map[string]int64{"ops": 5, "audit": 6}, pools: []int64{1}}
+
+	export, err := Parse(strings.NewReader(`{"assignments": [
+		{"group": "ops", "role": "operator", "resource-type": "resource-pool", "resources": ["1", "2"]},
+		{"group": "audit", "role": "auditor", "resource-type": "resource-pool", "resources": [""]},
+		{"group": "audit", "role": "user", "resource-type": "resource-pool", "resources": [""]},
+		{"group": "devs", "role": "user", "resource-type": "resource-pool", "resources": ["1"]},
+		{"user": "alice", "role": "administrator", "resource-type": "resource-pool", "resources": [""]},
+		{"group": "ops", "role": "owner", "resource-type": "resource-pool", "resources": ["1"]},
+		{"group": "ops", "role": "user", "resource-type": "zone", "resources": ["1"]}
+	]}`))
+	require.NoError(t, err)
+
+	report, err := Convert(context.Background(), dir, export)
+	require.NoError(t, err)
+
+	assert.Equal(t, []Tuple{
+		{User: "group:5#member", Relation: "can_view_available_machines", Object: "pool:1"},
+		{User: "group:5#member", Relation: "can_view_machines", Object: "pool:1"},
+		{User: "group:5#member", Relation: "can_deploy_machines", Object: "pool:1"},
+		{User: "group:5#member", Relation: "can_edit_machines", Object: "pool:1"},
+		{User: "group:6#member", Relation: "can_view_available_machines", Object: "maas:0"},
+		{User: "group:6#member", Relation: "can_view_machines", Object: "maas:0"},
+		{User: "group:6#member", Relation: "can_deploy_machines", Object: "maas:0"},
+	}, report.Tuples())
+
+	var reasons []string
+
+	for _, e := range report.Entries {
+		if e.Tuple == nil {
+			reasons = append(reasons, e.Reason)
+		}
+	}
+
+	assert.Equal(t, []string{
+		"resource pool 2 no longer exists",
+		"resource pool 2 no longer exists",
+		"resource pool 2 no longer exists",
+		"resource pool 2 no longer exists",
+		`no MAAS group named "devs"`,
+		"roles of users can't be imported, assign the role to a group of the user",
+		`unknown role "owner"`,
+		`unsupported resource type "zone"`,
+	}, reasons)
+	assert.Equal(t, len(reasons), report.Skipped())
+}
+
+func TestConvertUnmappedPermission(t *testing.T) {
+	dir := &fakeDirectory{groups: map[string]int64{"admins": 1}}
+
+	export, err := Parse(strings.NewReader(`{"assignments": [
+		{"group": "admins", "role": "administrator", "resource-type": "resource-pool", "resources": [""]}
+	]}`))
+	require.NoError(t, err)
+
+	report, err := Convert(context.Background(), dir, export)
+	require.NoError(t, err)
+	assert.Len(t, report.Tuples(), 4)
+	assert.Equal(t, 1, report.Skipped())
+
+	var out bytes.Buffer
+	require.NoError(t, report.Write(&out))
+	assert.Contains(t, out.String(), "group admins  administrator  edit")
+	assert.Contains(t, out.String(), "skipped: no equivalent relation")
+	assert.Contains(t, out.String(), "group:1#member can_edit_machines maas:0")
+}
@@ -0,0 +1,176 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package reconcile
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	sq "github.com/Masterminds/squirrel"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	tupleUtils "github.com/openfga/openfga/pkg/tuple"
+	"maas.io/core/src/maasopenfga/internal/tuples"
+)
+
+func psql() sq.StatementBuilderType {
+	return sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+}
+
+// Run finds the orphans of the store, and removes them if fix is true. db
+// must give access to the MAAS tables as well as the openfga schema.
+func Run(ctx context.Context, db *sql.DB, storeID string, fix bool) (*Report, error) {
+	keys, entities, err := Collect(ctx, db, storeID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{Tuples: len(keys), Orphans: Find(keys, entities), Fixed: fix}
+
+	if fix && len(report.Orphans) > 0 {
+		if report.Removed, err = Remove(ctx, db, storeID, report.Orphans); err != nil {
+			return nil, err
+		}
+	}
+
+	return report, nil
+}
+
+// Collect reads the tuples of the store and the IDs of the MAAS entities
+// from the same snapshot, so that entities created meanwhile aren't missing.
+func Collect(ctx context.Context, db *sql.DB, storeID string) ([]*openfgav1.TupleKey, Entities, error) {
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	//nolint:errcheck // read-only transaction
+	defer tx.Rollback()
+
+	keys, err := readTuples(ctx, tx, storeID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read tuples: %w", err)
+	}
+
+	entities := make(Entities, len(entityTables))
+
+	for _, objectType := range slices.Sorted(maps.Keys(entityTables)) {
+		ids, err := readIDs(ctx, tx, entityTables[objectType])
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read %s entities: %w", objectType, err)
+		}
+
+		entities[objectType] = ids
+	}
+
+	// MAAS always has its internal users, no user at all means that this
+	// isn't the MAAS database, and every tuple would be an orphan.
+	if len(entities["user"]) == 0 {
+		return nil, nil, errors.New("no MAAS users found, check the database configuration")
+	}
+
+	return keys, entities, nil
+}
+
+func readTuples(ctx context.Context, tx *sql.Tx, storeID string) ([]*openfgav1.TupleKey, error) {
+	stmt, args, err := psql().
+		Select("object_type", "object_id", "relation", "_user").
+		From("openfga.tuple").
+		Where(sq.Eq{"store": storeID}).
+		OrderBy("object_type", "object_id", "relation", "_user").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []*openfgav1.TupleKey
+
+	for rows.Next() {
+		var objectType, objectID, relation, user string
+		if err := rows.Scan(&objectType, &objectID, &relation, &user); err != nil {
+			return nil, errors.Join(err, rows.Close())
+		}
+
+		keys = append(keys, tupleUtils.NewTupleKey(
+			tupleUtils.BuildObject(objectType, objectID), relation, user))
+	}
+
+	return keys, errors.Join(rows.Err(), rows.Close())
+}
+
+func readIDs(ctx context.Context, tx *sql.Tx, table string) (map[string]struct{}, error) {
+	stmt, args, err := psql().
+		Select("id::text").
+		From(table).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := map[string]struct{}{}
+
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, errors.Join(err, rows.Close())
+		}
+
+		ids[id] = struct{}{}
+	}
+
+	return ids, errors.Join(rows.Err(), rows.Close())
+}
+
+// Remove deletes the orphans in a single transaction, recording the
+// deletions in the changelog, and returns how many still existed.
+func Remove(ctx context.Context, db *sql.DB, storeID string, orphans []Orphan) (int, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	removed := 0
+
+	for _, o := range orphans {
+		deleted, err := tuples.Delete(ctx, tx, storeID, o.Tuple)
+		if err != nil {
+			return 0, errors.Join(err, tx.Rollback())
+		}
+
+		if deleted {
+			removed++
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return removed, nil
+}
@@ -0,0 +1,132 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package reconcile finds the tuples referencing MAAS entities that no
+// longer exist, e.g. the grants of a deleted resource pool or the
+// memberships of a deleted user, and removes them.
+//
+// regiond deletes the tuples of the entities it deletes, but tuples written
+// by older versions, or left by a failure between both deletions, would
+// otherwise stay in the store forever.
+package reconcile
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	tupleUtils "github.com/openfga/openfga/pkg/tuple"
+)
+
+// entityTables maps the types of the model to the MAAS tables holding
+// their entities, whose id column is the ID of the OpenFGA object.
+var entityTables = map[string]string{
+	"user":          "auth_user",
+	"group":         "maasserver_usergroup",
+	"pool":          "maasserver_resourcepool",
+	"zone":          "maasserver_zone",
+	"fabric":        "maasserver_fabric",
+	"vlan":          "maasserver_vlan",
+	"boot_resource": "maasserver_bootresource",
+	"tag":           "maasserver_tag",
+	"machine":       "maasserver_node",
+}
+
+// maasObjectID is the ID of the single maas object.
+const maasObjectID = "0"
+
+// Entities holds the IDs of the MAAS entities, by OpenFGA type.
+type Entities map[string]map[string]struct{}
+
+// exists reports whether the object references an existing entity. Objects
+// of types that don't map to MAAS entities are never orphans.
+func (e Entities) exists(object string) bool {
+	objectType, id := tupleUtils.SplitObject(object)
+
+	if objectType == "maas" {
+		return id == maasObjectID
+	}
+
+	if _, ok := entityTables[objectType]; !ok || id == tupleUtils.Wildcard {
+		return true
+	}
+
+	_, ok := e[objectType][id]
+
+	return ok
+}
+
+// Orphan is a tuple referencing a missing entity.
+type Orphan struct {
+	Tuple *openfgav1.TupleKey
+	// Missing is the missing object, e.g. pool:3. When both the user and
+	// the object are missing, the object is reported.
+	Missing string
+}
+
+// Find returns the tuples referencing entities missing from entities, in
+// the order of keys.
+func Find(keys []*openfgav1.TupleKey, entities Entities) []Orphan {
+	var orphans []Orphan
+
+	for _, key := range keys {
+		user, _ := tupleUtils.SplitObjectRelation(key.GetUser())
+
+		switch {
+		case !entities.exists(key.GetObject()):
+			orphans = append(orphans, Orphan{Tuple: key, Missing: key.GetObject()})
+		case !entities.exists(user):
+			orphans = append(orphans, Orphan{Tuple: key, Missing: user})
+		}
+	}
+
+	return orphans
+}
+
+// Report is the outcome of a reconciliation.
+type Report struct {
+	// Tuples is the number of tuples checked.
+	Tuples  int
+	Orphans []Orphan
+	// Removed is the number of orphans deleted, if fixing.
+	Removed int
+	Fixed   bool
+}
+
+// Write writes the orphans as a table, sorted by missing entity.
+func (r *Report) Write(w io.Writer) error {
+	orphans := slices.Clone(r.Orphans)
+	slices.SortStableFunc(orphans, func(a, b Orphan) int {
+		return strings.Compare(a.Missing, b.Missing)
+	})
+
+	action := "reported"
+	if r.Fixed {
+		action = "removed"
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "MISSING\tTUPLE\tACTION")
+
+	for _, o := range orphans {
+		fmt.Fprintf(tw, "%s\t%s %s %s\t%s\n", o.Missing,
+			o.Tuple.GetUser(), o.Tuple.GetRelation(), o.Tuple.GetObject(), action)
+	}
+
+	return tw.Flush()
+}
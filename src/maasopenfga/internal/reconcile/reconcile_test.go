@@ -0,0 +1,106 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package reconcile
+
+import (
+	"bytes"
+	"testing"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	tupleUtils "github.com/openfga/openfga/pkg/tuple"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authzmodel "maas.io/core/src/maasopenfga/internal/model"
+)
+
+func ids(values ...string) map[string]struct{} {
+	m := make(map[string]struct{}, len(values))
+	for _, v := range values {
+		m[v] = struct{}{}
+	}
+
+	return m
+}
+
+func key(user, relation, object string) *openfgav1.TupleKey {
+	return tupleUtils.NewTupleKey(object, relation, user)
+}
+
+func TestFind(t *testing.T) {
+	entities := Entities{
+		"user":  ids("1", "2"),
+		"group": ids("1"),
+		"pool":  ids("0"),
+		"tag":   ids("5"),
+	}
+
+	keys := []*openfgav1.TupleKey{
+		key("maas:0", "parent", "pool:0"),
+		key("maas:0", "parent", "pool:3"),
+		key("user:2", "member", "group:1"),
+		key("user:9", "member", "group:1"),
+		key("group:1#member", "can_edit_machines", "pool:0"),
+		key("group:4#member", "can_edit_machines", "pool:0"),
+		key("group:4#member", "can_edit_machines", "pool:3"),
+		key("user:*", "can_view", "tag:5"),
+		key("user:1", "banned", "maas:1"),
+		// Types that don't map to MAAS entities are left to verify.
+		key("user:1", "member", "team:1"),
+	}
+
+	assert.Equal(t, []Orphan{
+		{Tuple: keys[1], Missing: "pool:3"},
+		{Tuple: keys[3], Missing: "user:9"},
+		{Tuple: keys[5], Missing: "group:4"},
+		{Tuple: keys[6], Missing: "pool:3"},
+		{Tuple: keys[8], Missing: "maas:1"},
+	}, Find(keys, entities))
+}
+
+func TestReportWrite(t *testing.T) {
+	report := &Report{
+		Orphans: []Orphan{
+			{Tuple: key("user:9", "member", "group:1"), Missing: "user:9"},
+			{Tuple: key("maas:0", "parent", "pool:3"), Missing: "pool:3"},
+		},
+		Fixed: true,
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, report.Write(&out))
+
+	assert.Equal(t, ""+
+		"MISSING  TUPLE                  ACTION\n"+
+		"pool:3   maas:0 parent pool:3   removed\n"+
+		"user:9   user:9 member group:1  removed\n",
+		out.String())
+}
+
+func TestEntityTypesInModel(t *testing.T) {
+	versions := authzmodel.Versions()
+
+	model, err := authzmodel.Load(versions[len(versions)-1])
+	require.NoError(t, err)
+
+	types := map[string]bool{}
+	for _, td := range model.GetTypeDefinitions() {
+		types[td.GetType()] = true
+	}
+
+	for objectType := range entityTables {
+		assert.True(t, types[objectType], "type %s is not defined in the model", objectType)
+	}
+}
@@ -0,0 +1,297 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package replication keeps the MAAS store of a standby region cluster in
+// sync with the one of a primary region cluster, for disaster recovery.
+//
+// The standby maas-openfga first copies every tuple of the primary and then
+// follows its change stream (see the changestream package), applying each
+// page of changes and the stream position in a single transaction. The
+// primary always wins: local tuples are replaced or deleted as needed.
+//
+// Once promoted, a standby stops applying changes for good and can serve
+// as the new primary.
+package replication
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"maas.io/core/src/maasgocommon/clock"
+	maaserrors "maas.io/core/src/maasgocommon/errors"
+	"maas.io/core/src/maasgocommon/retry"
+)
+
+const (
+	// lockID is the Postgres advisory lock key ensuring a single region
+	// controller replicates at a time ("maasorep").
+	lockID int64 = 0x6d6161736f726570
+
+	// snapshotMargin is how far before a snapshot the change stream is
+	// replayed from, to cover changes made while copying tuples and clock
+	// differences between clusters. Replayed changes are idempotent.
+	snapshotMargin = 5 * time.Minute
+
+	defaultMaxRetryInterval = time.Minute
+	responseHeaderTimeout   = 30 * time.Second
+)
+
+var errLocked = errors.New("replication is running on another region controller")
+
+var (
+	lagGauge = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "maas_openfga",
+		Subsystem: "replication",
+		Name:      "lag_seconds",
+		Help:      "Time between a change on the primary and it being applied on the standby.",
+	})
+	lastChangeGauge = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "maas_openfga",
+		Subsystem: "replication",
+		Name:      "last_change_timestamp_seconds",
+		Help:      "Time at which the primary recorded the last applied change.",
+	})
+	appliedCounter = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "maas_openfga",
+		Subsystem: "replication",
+		Name:      "applied_changes_total",
+		Help:      "Number of tuple changes applied on the standby.",
+	})
+	errorsCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "maas_openfga",
+		Subsystem: "replication",
+		Name:      "errors_total",
+		Help:      "Number of replication failures, by kind of error.",
+	}, []string{"kind"})
+	connectedGauge = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "maas_openfga",
+		Subsystem: "replication",
+		Name:      "connected",
+		Help:      "Whether this region controller is following the primary change stream.",
+	})
+)
+
+// Replicator applies changes of the primary store to the local database.
+type Replicator struct {
+	db               *sql.DB
+	source           *source
+	clock            clock.Clock
+	storeID          string
+	primaryURL       string
+	maxRetryInterval time.Duration
+}
+
+// Option allows to set additional Replicator options
+type Option func(*Replicator)
+
+// WithHTTPClient sets the client used to reach the primary, e.g. to
+// configure TLS (default: a client without overall timeout, which would
+// break the change stream)
+func WithHTTPClient(c *http.Client) Option {
+	return func(r *Replicator) {
+		r.source.httpClient = c
+	}
+}
+
+// WithMaxRetryInterval caps the delay between attempts to reach the
+// primary (default: 1m)
+func WithMaxRetryInterval(d time.Duration) Option {
+	return func(r *Replicator) {
+		if d > 0 {
+			r.maxRetryInterval = d
+		}
+	}
+}
+
+// WithClock sets the clock used to wait between attempts and to measure the
+// replication lag (default: the system clock)
+func WithClock(clk clock.Clock) Option {
+	return func(r *Replicator) {
+		r.clock = clk
+	}
+}
+
+// New returns a Replicator copying the store storeID from the maas-openfga
+// HTTP API at primaryURL into db.
+func New(db *sql.DB, primaryURL, storeID string, options ...Option) *Replicator {
+	r := &Replicator{
+		db: db,
+		source: &source{
+			httpClient: &http.Client{
+				Transport: &http.Transport{
+					Proxy:                 http.ProxyFromEnvironment,
+					ResponseHeaderTimeout: responseHeaderTimeout,
+				},
+			},
+			baseURL: primaryURL,
+			storeID: storeID,
+		},
+		clock:            clock.New(),
+		storeID:          storeID,
+		primaryURL:       primaryURL,
+		maxRetryInterval: defaultMaxRetryInterval,
+	}
+
+	for _, opt := range options {
+		opt(r)
+	}
+
+	return r
+}
+
+// Run replicates changes until ctx is done or the store is promoted, in
+// which case ErrPromoted is returned. Failures to reach the primary or the
+// database are retried.
+func (r *Replicator) Run(ctx context.Context) error {
+	policy := retry.NewBackoff(retry.WithMaxInterval(r.maxRetryInterval))
+
+	for {
+		err := r.run(ctx, policy)
+
+		connectedGauge.Set(0)
+
+		switch {
+		case errors.Is(err, ErrPromoted):
+			return err
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case errors.Is(err, errLocked):
+		default:
+			errorsCounter.WithLabelValues(maaserrors.KindOf(err).String()).Inc()
+			log.Printf("replication from %s failed: %v", r.primaryURL, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-r.clock.After(policy.Next()):
+		}
+	}
+}
+
+func (r *Replicator) run(ctx context.Context, policy *retry.Backoff) error {
+	conn, err := r.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire database connection: %w", err)
+	}
+
+	var locked bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)",
+		lockID).Scan(&locked); err != nil {
+		return errors.Join(fmt.Errorf("failed to acquire replication lock: %w", err), conn.Close())
+	}
+
+	if !locked {
+		return errors.Join(errLocked, conn.Close())
+	}
+
+	err = r.replicate(ctx, conn, policy)
+
+	// Use a fresh context, the lock must be released even if ctx is done.
+	_, unlockErr := conn.ExecContext(context.WithoutCancel(ctx), "SELECT pg_advisory_unlock($1)", lockID)
+	if unlockErr != nil {
+		unlockErr = fmt.Errorf("failed to release replication lock: %w", unlockErr)
+	}
+
+	return errors.Join(err, unlockErr, conn.Close())
+}
+
+// replicate applies the changes of the primary while conn holds the
+// replication lock.
+func (r *Replicator) replicate(ctx context.Context, conn *sql.Conn, policy *retry.Backoff) error {
+	state, err := initState(ctx, r.db, r.storeID, r.primaryURL)
+	if err != nil {
+		return err
+	}
+
+	if state.Role == RolePrimary {
+		return ErrPromoted
+	}
+
+	pos := position{token: state.Token, startTime: state.StartTime}
+
+	if pos.token == "" && pos.startTime.IsZero() {
+		pos.startTime = r.clock.Now().Add(-snapshotMargin)
+
+		if err := r.snapshot(ctx, pos.startTime); err != nil {
+			return err
+		}
+	}
+
+	log.Printf("replicating changes from %s", r.primaryURL)
+
+	connectedGauge.Set(1)
+
+	return r.source.watch(ctx, pos,
+		func(page *openfgav1.ReadChangesResponse) error {
+			if err := applyChanges(ctx, r.db, r.storeID, page); err != nil {
+				return err
+			}
+
+			policy.Reset()
+			observe(page, r.clock.Now())
+
+			return nil
+		},
+		func() error {
+			// The primary only sends heartbeats once the standby caught up.
+			lagGauge.Set(0)
+
+			// Losing the session releases the lock, stop before another
+			// region controller takes over.
+			return conn.PingContext(ctx)
+		},
+	)
+}
+
+func (r *Replicator) snapshot(ctx context.Context, startTime time.Time) error {
+	var count int
+
+	err := applySnapshot(ctx, r.db, r.storeID, startTime,
+		func(fn func([]*openfgav1.Tuple) error) error {
+			return r.source.snapshot(ctx, func(tuples []*openfgav1.Tuple) error {
+				count += len(tuples)
+				return fn(tuples)
+			})
+		})
+	if err != nil {
+		return fmt.Errorf("failed to copy tuples from primary: %w", err)
+	}
+
+	log.Printf("copied %d tuples from %s", count, r.primaryURL)
+
+	return nil
+}
+
+func observe(page *openfgav1.ReadChangesResponse, now time.Time) {
+	changes := page.GetChanges()
+	if len(changes) == 0 {
+		return
+	}
+
+	appliedCounter.Add(float64(len(changes)))
+
+	last := changes[len(changes)-1].GetTimestamp().AsTime()
+	lastChangeGauge.Set(float64(last.UnixNano()) / float64(time.Second))
+	lagGauge.Set(max(now.Sub(last).Seconds(), 0))
+}
@@ -0,0 +1,222 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package replication
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+	maaserrors "maas.io/core/src/maasgocommon/errors"
+	"maas.io/core/src/maasopenfga/internal/priority"
+)
+
+const (
+	snapshotPageSize = 100
+	// maxEventSize bounds the size of a single change stream event.
+	maxEventSize = 4 << 20
+)
+
+// unmarshal tolerates fields added to the API by newer primaries.
+var unmarshal = protojson.UnmarshalOptions{DiscardUnknown: true}
+
+// source reads tuples and tuple changes from the primary maas-openfga.
+type source struct {
+	httpClient *http.Client
+	baseURL    string
+	storeID    string
+}
+
+// position is where the change stream starts: right after the page with
+// the given continuation token or, if there is none, at the given time.
+type position struct {
+	token     string
+	startTime time.Time
+}
+
+// snapshot calls fn for every page of tuples currently stored.
+func (s *source) snapshot(ctx context.Context, fn func([]*openfgav1.Tuple) error) error {
+	req := &openfgav1.ReadRequest{PageSize: wrapperspb.Int32(snapshotPageSize)}
+
+	for {
+		var resp openfgav1.ReadResponse
+
+		if err := s.post(ctx, "/read", req, &resp); err != nil {
+			return err
+		}
+
+		if err := fn(resp.GetTuples()); err != nil {
+			return err
+		}
+
+		if resp.GetContinuationToken() == "" {
+			return nil
+		}
+
+		req.ContinuationToken = resp.GetContinuationToken()
+	}
+}
+
+func (s *source) post(ctx context.Context, path string, in, out proto.Message) error {
+	data, err := protojson.Marshal(in)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url(path),
+		bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	// Replication must not slow down the checks served by the primary.
+	req.Header.Set(priority.Header, string(priority.Batch))
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to connect to primary: %w", err)
+	}
+
+	defer resp.Body.Close() //nolint:errcheck // nothing to do with the error
+
+	if resp.StatusCode != http.StatusOK {
+		return statusError(resp)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if err := unmarshal.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return nil
+}
+
+// watch follows the change stream from pos, calling onChanges for every
+// page of changes and onIdle for every heartbeat. It only returns on error.
+func (s *source) watch(ctx context.Context, pos position,
+	onChanges func(*openfgav1.ReadChangesResponse) error, onIdle func() error) error {
+	u := s.url("/changes/watch")
+	if pos.token == "" && !pos.startTime.IsZero() {
+		u += "?" + url.Values{
+			"start_time": {pos.startTime.UTC().Format(time.RFC3339Nano)},
+		}.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set(priority.Header, string(priority.Batch))
+
+	if pos.token != "" {
+		req.Header.Set("Last-Event-ID", pos.token)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to connect to primary: %w", err)
+	}
+
+	defer resp.Body.Close() //nolint:errcheck // nothing to do with the error
+
+	if resp.StatusCode != http.StatusOK {
+		return statusError(resp)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxEventSize)
+
+	var name, data string
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		switch {
+		case line == "":
+			if err := dispatch(name, data, onChanges); err != nil {
+				return err
+			}
+
+			name, data = "", ""
+		case strings.HasPrefix(line, ":"):
+			if err := onIdle(); err != nil {
+				return err
+			}
+		default:
+			field, value, _ := strings.Cut(line, ":")
+			value = strings.TrimPrefix(value, " ")
+
+			switch field {
+			case "event":
+				name = value
+			case "data":
+				data = value
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read change stream: %w", err)
+	}
+
+	return errors.New("change stream closed by primary")
+}
+
+func dispatch(name, data string, onChanges func(*openfgav1.ReadChangesResponse) error) error {
+	switch name {
+	case "changes":
+		var page openfgav1.ReadChangesResponse
+		if err := unmarshal.Unmarshal([]byte(data), &page); err != nil {
+			return fmt.Errorf("failed to decode changes: %w", err)
+		}
+
+		return onChanges(&page)
+	case "error":
+		return fmt.Errorf("primary change stream failed: %s", data)
+	}
+
+	// Unknown events are ignored, as required by the SSE specification.
+	return nil
+}
+
+func (s *source) url(path string) string {
+	return strings.TrimSuffix(s.baseURL, "/") + "/stores/" + s.storeID + path
+}
+
+func statusError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096)) //nolint:errcheck // best effort
+
+	return maaserrors.Errorf(maaserrors.FromHTTPStatus(resp.StatusCode),
+		"primary returned %s: %s", resp.Status, bytes.TrimSpace(body))
+}
@@ -0,0 +1,192 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package replication
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+	maaserrors "maas.io/core/src/maasgocommon/errors"
+	"maas.io/core/src/maasopenfga/internal/priority"
+)
+
+var errStop = errors.New("stop")
+
+func tuple(object string) *openfgav1.Tuple {
+	return &openfgav1.Tuple{Key: &openfgav1.TupleKey{
+		User: "user:1", Relation: "member", Object: object,
+	}}
+}
+
+func TestSourceSnapshot(t *testing.T) {
+	pages := map[string]*openfgav1.ReadResponse{
+		"": {
+			Tuples:            []*openfgav1.Tuple{tuple("group:1"), tuple("group:2")},
+			ContinuationToken: "next",
+		},
+		"next": {
+			Tuples: []*openfgav1.Tuple{tuple("group:3")},
+		},
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/stores/store/read", r.URL.Path)
+		assert.Equal(t, "batch", r.Header.Get(priority.Header))
+
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		var req openfgav1.ReadRequest
+		require.NoError(t, protojson.Unmarshal(body, &req))
+
+		data, err := protojson.Marshal(pages[req.GetContinuationToken()])
+		require.NoError(t, err)
+
+		_, err = w.Write(data)
+		assert.NoError(t, err)
+	}))
+	t.Cleanup(srv.Close)
+
+	s := &source{httpClient: srv.Client(), baseURL: srv.URL + "/", storeID: "store"}
+
+	var objects []string
+
+	err := s.snapshot(context.Background(), func(tuples []*openfgav1.Tuple) error {
+		for _, t := range tuples {
+			objects = append(objects, t.GetKey().GetObject())
+		}
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"group:1", "group:2", "group:3"}, objects)
+}
+
+func TestSourceWatch(t *testing.T) {
+	testcases := map[string]struct {
+		pos        position
+		stream     string
+		wantQuery  string
+		wantHeader string
+		wantTokens []string
+		wantIdle   int
+		errMsg     string
+	}{
+		"resume from token": {
+			pos: position{token: "t1"},
+			stream: "id: t2\nevent: changes\n" +
+				`data: {"changes":[{"tuple_key":{"user":"user:1","relation":"member","object":"group:1"}}],"continuation_token":"t2"}` +
+				"\n\n: heartbeat\n\n",
+			wantHeader: "t1",
+			wantTokens: []string{"t2"},
+			wantIdle:   1,
+			errMsg:     "closed by primary",
+		},
+		"start at time": {
+			pos:       position{startTime: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)},
+			stream:    "event: unknown\ndata: {}\n\n",
+			wantQuery: "start_time=2026-01-02T03%3A04%3A05Z",
+			errMsg:    "closed by primary",
+		},
+		"stream error": {
+			stream: "event: error\ndata: store not found\n\n",
+			errMsg: "primary change stream failed: store not found",
+		},
+		"invalid event": {
+			stream: "event: changes\ndata: {\n\n",
+			errMsg: "failed to decode changes",
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/stores/store/changes/watch", r.URL.Path)
+				assert.Equal(t, tc.wantQuery, r.URL.RawQuery)
+				assert.Equal(t, tc.wantHeader, r.Header.Get("Last-Event-ID"))
+
+				w.Header().Set("Content-Type", "text/event-stream")
+				_, err := io.WriteString(w, tc.stream)
+				assert.NoError(t, err)
+			}))
+			t.Cleanup(srv.Close)
+
+			s := &source{httpClient: srv.Client(), baseURL: srv.URL, storeID: "store"}
+
+			var (
+				tokens []string
+				idle   int
+			)
+
+			err := s.watch(context.Background(), tc.pos,
+				func(page *openfgav1.ReadChangesResponse) error {
+					tokens = append(tokens, page.GetContinuationToken())
+					return nil
+				},
+				func() error {
+					idle++
+					return nil
+				})
+
+			assert.ErrorContains(t, err, tc.errMsg)
+			assert.Equal(t, tc.wantTokens, tokens)
+			assert.Equal(t, tc.wantIdle, idle)
+		})
+	}
+}
+
+func TestSourceWatchStops(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := io.WriteString(w, "event: changes\ndata: {}\n\n")
+		assert.NoError(t, err)
+	}))
+	t.Cleanup(srv.Close)
+
+	s := &source{httpClient: srv.Client(), baseURL: srv.URL, storeID: "store"}
+
+	err := s.watch(context.Background(), position{token: "t1"},
+		func(*openfgav1.ReadChangesResponse) error { return errStop },
+		func() error { return nil })
+
+	assert.ErrorIs(t, err, errStop)
+}
+
+func TestSourceStatusError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "store not found", http.StatusNotFound)
+	}))
+	t.Cleanup(srv.Close)
+
+	s := &source{httpClient: srv.Client(), baseURL: srv.URL, storeID: "store"}
+
+	err := s.watch(context.Background(), position{token: "t1"},
+		func(*openfgav1.ReadChangesResponse) error { return nil },
+		func() error { return nil })
+
+	assert.ErrorContains(t, err, "primary returned 404 Not Found: store not found")
+	assert.Equal(t, maaserrors.NotFound, maaserrors.KindOf(err))
+}
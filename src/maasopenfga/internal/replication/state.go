@@ -0,0 +1,276 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package replication
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"maas.io/core/src/maasopenfga/internal/tuples"
+)
+
+const (
+	// RoleStandby is the role of a store receiving changes from a primary.
+	RoleStandby = "standby"
+	// RolePrimary is the role of a standby store after promotion.
+	RolePrimary = "primary"
+
+	stateTable = "openfga.maas_replication"
+)
+
+var (
+	// ErrNotConfigured is returned when replication was never started
+	// against the database.
+	ErrNotConfigured = errors.New("replication is not configured")
+	// ErrPromoted is returned when the store has been promoted to primary
+	// and must no longer receive changes.
+	ErrPromoted = errors.New("store has been promoted to primary")
+)
+
+// State describes the replication of a store.
+type State struct {
+	// LastChangeAt is when the primary recorded the last applied change.
+	LastChangeAt time.Time
+	// UpdatedAt is when the state was last written.
+	UpdatedAt time.Time
+	// StartTime is where the change stream starts when Token is empty.
+	StartTime  time.Time
+	Role       string
+	PrimaryURL string
+	Token      string
+}
+
+func psql() sq.StatementBuilderType {
+	return sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+}
+
+// LoadState returns the replication state of the store.
+func LoadState(ctx context.Context, db *sql.DB, storeID string) (*State, error) {
+	return loadState(ctx, db, storeID)
+}
+
+type queryer interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func loadState(ctx context.Context, db queryer, storeID string) (*State, error) {
+	stmt, args, err := psql().
+		Select("role", "primary_url", "continuation_token", "start_time",
+			"last_change_at", "updated_at").
+		From(stateTable).
+		Where(sq.Eq{"store": storeID}).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		state        State
+		startTime    sql.NullTime
+		lastChangeAt sql.NullTime
+	)
+
+	err = db.QueryRowContext(ctx, stmt, args...).Scan(&state.Role, &state.PrimaryURL,
+		&state.Token, &startTime, &lastChangeAt, &state.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotConfigured
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to load replication state: %w", err)
+	}
+
+	state.StartTime = startTime.Time
+	state.LastChangeAt = lastChangeAt.Time
+
+	return &state, nil
+}
+
+// Promote turns a standby store into a primary one. Running replicators
+// stop before applying any further change.
+func Promote(ctx context.Context, db *sql.DB, storeID string) error {
+	stmt, args, err := psql().
+		Update(stateTable).
+		Set("role", RolePrimary).
+		Set("updated_at", sq.Expr("NOW()")).
+		Where(sq.Eq{"store": storeID, "role": RoleStandby}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	res, err := db.ExecContext(ctx, stmt, args...)
+	if err != nil {
+		return fmt.Errorf("failed to promote store: %w", err)
+	}
+
+	if n, err := res.RowsAffected(); err != nil || n > 0 {
+		return err
+	}
+
+	// Nothing updated: tell apart a missing row from a promoted store.
+	if _, err := loadState(ctx, db, storeID); err != nil {
+		return err
+	}
+
+	return ErrPromoted
+}
+
+// initState registers the store as a standby of primaryURL. Replicating
+// from a different primary starts over with a new snapshot.
+func initState(ctx context.Context, db *sql.DB, storeID, primaryURL string) (*State, error) {
+	stmt, args, err := psql().
+		Insert(stateTable).
+		Columns("store", "role", "primary_url", "updated_at").
+		Values(storeID, RoleStandby, primaryURL, sq.Expr("NOW()")).
+		Suffix("ON CONFLICT (store) DO UPDATE SET " +
+			"primary_url = EXCLUDED.primary_url, continuation_token = '', " +
+			"start_time = NULL, updated_at = EXCLUDED.updated_at " +
+			"WHERE maas_replication.role = 'standby' " +
+			"AND maas_replication.primary_url <> EXCLUDED.primary_url").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := db.ExecContext(ctx, stmt, args...); err != nil {
+		return nil, fmt.Errorf("failed to initialize replication state: %w", err)
+	}
+
+	return loadState(ctx, db, storeID)
+}
+
+// applySnapshot replaces all tuples of the store with those read by
+// snapshot, and sets the change stream to start at startTime.
+func applySnapshot(ctx context.Context, db *sql.DB, storeID string, startTime time.Time,
+	snapshot func(fn func([]*openfgav1.Tuple) error) error) error {
+	return inTx(ctx, db, func(tx *sql.Tx) error {
+		if err := tuples.DeleteAll(ctx, tx, storeID); err != nil {
+			return err
+		}
+
+		err := snapshot(func(page []*openfgav1.Tuple) error {
+			for _, t := range page {
+				if err := tuples.Write(ctx, tx, storeID, t.GetKey()); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		return updateState(ctx, tx, storeID, map[string]any{
+			"continuation_token": "",
+			"start_time":         startTime,
+		})
+	})
+}
+
+// applyChanges applies a page of changes and records the stream position
+// in the same transaction, so that every change is applied exactly once.
+// The primary always wins: writes replace the local tuple, if any, and
+// deletes of missing tuples are ignored.
+func applyChanges(ctx context.Context, db *sql.DB, storeID string,
+	page *openfgav1.ReadChangesResponse) error {
+	return inTx(ctx, db, func(tx *sql.Tx) error {
+		var lastChangeAt time.Time
+
+		for _, change := range page.GetChanges() {
+			var err error
+
+			switch change.GetOperation() {
+			case openfgav1.TupleOperation_TUPLE_OPERATION_WRITE:
+				err = tuples.Write(ctx, tx, storeID, change.GetTupleKey())
+			case openfgav1.TupleOperation_TUPLE_OPERATION_DELETE:
+				_, err = tuples.Delete(ctx, tx, storeID, change.GetTupleKey())
+			default:
+				err = fmt.Errorf("unknown tuple operation %s", change.GetOperation())
+			}
+
+			if err != nil {
+				return err
+			}
+
+			lastChangeAt = change.GetTimestamp().AsTime()
+		}
+
+		values := map[string]any{
+			"continuation_token": page.GetContinuationToken(),
+			"start_time":         nil,
+		}
+
+		if !lastChangeAt.IsZero() {
+			values["last_change_at"] = lastChangeAt
+		}
+
+		return updateState(ctx, tx, storeID, values)
+	})
+}
+
+// updateState fails with ErrPromoted once the store is no longer a
+// standby, rolling back the changes applied in the transaction.
+func updateState(ctx context.Context, tx *sql.Tx, storeID string, values map[string]any) error {
+	stmt, args, err := psql().
+		Update(stateTable).
+		SetMap(values).
+		Set("updated_at", sq.Expr("NOW()")).
+		Where(sq.Eq{"store": storeID, "role": RoleStandby}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	res, err := tx.ExecContext(ctx, stmt, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update replication state: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return ErrPromoted
+	}
+
+	return nil
+}
+
+func inTx(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	if err := fn(tx); err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
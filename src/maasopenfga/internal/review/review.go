@@ -0,0 +1,261 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package review lists the tuples of the MAAS store that need an access
+// review: grants older than a maximum age, grants to disabled or deleted
+// MAAS users, and group memberships that were not reconfirmed recently.
+//
+// MAAS rewrites a tuple to reconfirm it, which resets its insertion time,
+// so the age of a tuple is the time since it was last confirmed.
+package review
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	tupleUtils "github.com/openfga/openfga/pkg/tuple"
+	"maas.io/core/src/maasopenfga/internal/tuples"
+)
+
+// Reasons a tuple is flagged for review.
+const (
+	ReasonStaleGrant            = "stale_grant"
+	ReasonUnconfirmedMembership = "unconfirmed_membership"
+	ReasonDisabledUser          = "disabled_user"
+	ReasonDeletedUser           = "deleted_user"
+)
+
+const (
+	membershipRelation   = "member"
+	membershipObjectType = "group"
+	userPrefix           = "user:"
+	hoursPerDay          = 24
+)
+
+// links are relations between objects, or deny-lists, rather than grants.
+// Expiring them would detach objects from their parent or lift bans.
+var links = []string{"parent", "fabric", "banned"}
+
+// Options selects the tuples to report.
+type Options struct {
+	// Now is the time ages are computed at.
+	Now time.Time
+	// GrantMaxAge flags grants older than it. Zero disables the check.
+	GrantMaxAge time.Duration
+	// MembershipMaxAge flags group memberships not reconfirmed for longer
+	// than it. Zero disables the check.
+	MembershipMaxAge time.Duration
+}
+
+// Finding is a tuple flagged for review.
+type Finding struct {
+	InsertedAt time.Time `json:"inserted_at"`
+	User       string    `json:"user"`
+	Relation   string    `json:"relation"`
+	Object     string    `json:"object"`
+	Reasons    []string  `json:"reasons"`
+}
+
+// Packet is the result of a review.
+type Packet struct {
+	GeneratedAt time.Time `json:"generated_at"`
+	// Max ages in days, zero when disabled.
+	GrantMaxAgeDays      int       `json:"grant_max_age_days"`
+	MembershipMaxAgeDays int       `json:"membership_max_age_days"`
+	Findings             []Finding `json:"findings"`
+}
+
+// Tuple is a tuple of the store, with the state of the MAAS user it is
+// granted to.
+type Tuple struct {
+	InsertedAt time.Time
+	// Active is unset if the user is not a MAAS user or doesn't exist.
+	Active   sql.NullBool
+	User     string
+	Relation string
+	Object   string
+}
+
+// Collect reads the tuples of the store and reviews them. db must give
+// access to both the openfga schema and the MAAS tables.
+func Collect(ctx context.Context, db *sql.DB, storeID string, opts Options) (*Packet, error) {
+	stmt, args, err := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+		Select("t._user", "t.relation", "t.object_type", "t.object_id", "t.inserted_at", "u.is_active").
+		From("openfga.tuple t").
+		LeftJoin("auth_user u ON t._user = 'user:' || u.id").
+		Where(sq.Eq{"t.store": storeID}).
+		Where(sq.NotEq{"t.relation": links}).
+		OrderBy("t.inserted_at").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tuples: %w", err)
+	}
+
+	var all []Tuple
+
+	for rows.Next() {
+		var (
+			t                    Tuple
+			objectType, objectID string
+		)
+
+		if err := rows.Scan(&t.User, &t.Relation, &objectType, &objectID, &t.InsertedAt, &t.Active); err != nil {
+			return nil, errors.Join(err, rows.Close())
+		}
+
+		t.Object = tupleUtils.BuildObject(objectType, objectID)
+		all = append(all, t)
+	}
+
+	if err := errors.Join(rows.Err(), rows.Close()); err != nil {
+		return nil, fmt.Errorf("failed to read tuples: %w", err)
+	}
+
+	return Review(all, opts), nil
+}
+
+// Review flags the tuples needing a review.
+func Review(all []Tuple, opts Options) *Packet {
+	packet := &Packet{
+		GeneratedAt:          opts.Now.UTC(),
+		GrantMaxAgeDays:      days(opts.GrantMaxAge),
+		MembershipMaxAgeDays: days(opts.MembershipMaxAge),
+		Findings:             []Finding{},
+	}
+
+	for _, t := range all {
+		if slices.Contains(links, t.Relation) {
+			continue
+		}
+
+		age := opts.Now.Sub(t.InsertedAt)
+		membership := t.Relation == membershipRelation &&
+			strings.HasPrefix(t.Object, membershipObjectType+":")
+
+		var reasons []string
+
+		switch {
+		case membership && opts.MembershipMaxAge > 0 && age > opts.MembershipMaxAge:
+			reasons = append(reasons, ReasonUnconfirmedMembership)
+		case !membership && opts.GrantMaxAge > 0 && age > opts.GrantMaxAge:
+			reasons = append(reasons, ReasonStaleGrant)
+		}
+
+		if strings.HasPrefix(t.User, userPrefix) {
+			switch {
+			case !t.Active.Valid:
+				reasons = append(reasons, ReasonDeletedUser)
+			case !t.Active.Bool:
+				reasons = append(reasons, ReasonDisabledUser)
+			}
+		}
+
+		if len(reasons) > 0 {
+			packet.Findings = append(packet.Findings, Finding{
+				InsertedAt: t.InsertedAt.UTC(),
+				User:       t.User,
+				Relation:   t.Relation,
+				Object:     t.Object,
+				Reasons:    reasons,
+			})
+		}
+	}
+
+	return packet
+}
+
+// WriteJSON writes the packet as indented JSON.
+func WriteJSON(w io.Writer, packet *Packet) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(packet)
+}
+
+// WriteCSV writes the findings of the packet as CSV, one per row, with
+// their age in days at the time the packet was generated.
+func WriteCSV(w io.Writer, packet *Packet) error {
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write([]string{"user", "relation", "object", "inserted_at", "age_days", "reasons"}); err != nil {
+		return err
+	}
+
+	for _, f := range packet.Findings {
+		err := writer.Write([]string{
+			f.User,
+			f.Relation,
+			f.Object,
+			f.InsertedAt.Format(time.RFC3339),
+			strconv.Itoa(days(packet.GeneratedAt.Sub(f.InsertedAt))),
+			strings.Join(f.Reasons, ";"),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
+
+// Expire deletes the tuples of the findings in a single transaction and
+// returns how many were deleted. A tuple reconfirmed since the review is
+// deleted too, so expire right after collecting.
+func Expire(ctx context.Context, db *sql.DB, storeID string, findings []Finding) (int, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	deleted := 0
+
+	for _, f := range findings {
+		ok, err := tuples.Delete(ctx, tx, storeID, tupleUtils.NewTupleKey(f.Object, f.Relation, f.User))
+		if err != nil {
+			return 0, errors.Join(err, tx.Rollback())
+		}
+
+		if ok {
+			deleted++
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return deleted, nil
+}
+
+func days(d time.Duration) int {
+	return int(d.Hours() / hoursPerDay)
+}
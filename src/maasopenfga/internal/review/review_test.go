@@ -0,0 +1,133 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package review
+
+import (
+	"bytes"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var now = time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func daysAgo(n int) time.Time {
+	return now.Add(-time.Duration(n) * 24 * time.Hour)
+}
+
+func active(b bool) sql.NullBool {
+	return sql.NullBool{Bool: b, Valid: true}
+}
+
+func TestReview(t *testing.T) {
+	opts := Options{Now: now, GrantMaxAge: 90 * 24 * time.Hour, MembershipMaxAge: 30 * 24 * time.Hour}
+
+	testcases := map[string]struct {
+		tuple   Tuple
+		opts    Options
+		reasons []string
+	}{
+		"recent grant": {
+			tuple: Tuple{User: "group:1#member", Relation: "can_edit_machines", Object: "maas:0",
+				InsertedAt: daysAgo(10)},
+		},
+		"stale grant": {
+			tuple: Tuple{User: "group:1#member", Relation: "can_edit_machines", Object: "maas:0",
+				InsertedAt: daysAgo(100)},
+			reasons: []string{ReasonStaleGrant},
+		},
+		"grant check disabled": {
+			tuple: Tuple{User: "group:1#member", Relation: "can_edit_machines", Object: "maas:0",
+				InsertedAt: daysAgo(1000)},
+			opts: Options{Now: now},
+		},
+		"recent membership": {
+			tuple: Tuple{User: "user:1", Relation: "member", Object: "group:1",
+				InsertedAt: daysAgo(10), Active: active(true)},
+		},
+		"memberships have their own max age": {
+			tuple: Tuple{User: "user:1", Relation: "member", Object: "group:1",
+				InsertedAt: daysAgo(40), Active: active(true)},
+			reasons: []string{ReasonUnconfirmedMembership},
+		},
+		"membership of a disabled user": {
+			tuple: Tuple{User: "user:1", Relation: "member", Object: "group:1",
+				InsertedAt: daysAgo(40), Active: active(false)},
+			reasons: []string{ReasonUnconfirmedMembership, ReasonDisabledUser},
+		},
+		"grant to a deleted user": {
+			tuple: Tuple{User: "user:1", Relation: "can_view", Object: "zone:1",
+				InsertedAt: daysAgo(1)},
+			reasons: []string{ReasonDeletedUser},
+		},
+		"parents are not grants": {
+			tuple: Tuple{User: "maas:0", Relation: "parent", Object: "pool:1",
+				InsertedAt: daysAgo(1000)},
+		},
+		"bans are not grants": {
+			tuple: Tuple{User: "user:1", Relation: "banned", Object: "maas:0",
+				InsertedAt: daysAgo(1000), Active: active(false)},
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			o := opts
+			if !tc.opts.Now.IsZero() {
+				o = tc.opts
+			}
+
+			packet := Review([]Tuple{tc.tuple}, o)
+
+			if tc.reasons == nil {
+				assert.Empty(t, packet.Findings)
+				return
+			}
+
+			require.Len(t, packet.Findings, 1)
+			assert.Equal(t, tc.reasons, packet.Findings[0].Reasons)
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	packet := Review([]Tuple{
+		{User: "user:1", Relation: "member", Object: "group:1", InsertedAt: daysAgo(40), Active: active(false)},
+	}, Options{Now: now, MembershipMaxAge: 30 * 24 * time.Hour})
+
+	var out bytes.Buffer
+	require.NoError(t, WriteCSV(&out, packet))
+	assert.Equal(t, "user,relation,object,inserted_at,age_days,reasons\n"+
+		"user:1,member,group:1,2026-04-22T12:00:00Z,40,unconfirmed_membership;disabled_user\n", out.String())
+
+	out.Reset()
+	require.NoError(t, WriteJSON(&out, packet))
+	assert.JSONEq(t, `{
+		"generated_at": "2026-06-01T12:00:00Z",
+		"grant_max_age_days": 0,
+		"membership_max_age_days": 30,
+		"findings": [{
+			"inserted_at": "2026-04-22T12:00:00Z",
+			"user": "user:1",
+			"relation": "member",
+			"object": "group:1",
+			"reasons": ["unconfirmed_membership", "disabled_user"]
+		}]
+	}`, out.String())
+}
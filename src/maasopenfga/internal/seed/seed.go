@@ -0,0 +1,276 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package seed writes the tuples listed in YAML seed files, so that
+// initial relationships are declared rather than coded in migrations:
+//
+//	tuples:
+//	  # Members of the Administrators group can edit all machines.
+//	  - user: group:@Administrators#member
+//	    relation: can_edit_machines
+//	    object: maas:0
+//	  - user: maas:0
+//	    relation: parent
+//	    object: pool:@default
+//
+// An object ID starting with @ refers to a MAAS object by name, see
+// references. Seeding is idempotent: tuples that already exist are left
+// untouched, whatever their condition, and reported as existing.
+package seed
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	tupleUtils "github.com/openfga/openfga/pkg/tuple"
+	"gopkg.in/yaml.v3"
+	"maas.io/core/src/maasopenfga/internal/tuples"
+)
+
+const referencePrefix = "@"
+
+// references maps the object types that can be referred to by name to the
+// MAAS table and column holding their name.
+var references = map[string]struct{ table, column string }{
+	"user":   {"auth_user", "username"},
+	"group":  {"maasserver_usergroup", "name"},
+	"pool":   {"maasserver_resourcepool", "name"},
+	"zone":   {"maasserver_zone", "name"},
+	"fabric": {"maasserver_fabric", "name"},
+	"tag":    {"maasserver_tag", "name"},
+}
+
+// Tuple is a tuple of a seed file.
+type Tuple struct {
+	User     string `yaml:"user"`
+	Relation string `yaml:"relation"`
+	Object   string `yaml:"object"`
+}
+
+func (t Tuple) String() string {
+	return fmt.Sprintf("%s %s %s", t.User, t.Relation, t.Object)
+}
+
+// File is a parsed seed file.
+type File struct {
+	Tuples []Tuple `yaml:"tuples"`
+}
+
+// Parse parses a seed file. Unknown fields are rejected.
+func Parse(data []byte) (*File, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	var file File
+	if err := decoder.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid seed file: %w", err)
+	}
+
+	for i, t := range file.Tuples {
+		if t.User == "" || t.Relation == "" || t.Object == "" {
+			return nil, fmt.Errorf("invalid seed file: tuple %d needs a user, a relation and an object", i+1)
+		}
+	}
+
+	return &file, nil
+}
+
+// Result lists the tuples of a seeding.
+type Result struct {
+	// Written lists the tuples written, or that would be written by a dry
+	// run, with references resolved.
+	Written []Tuple
+	// Existing lists the tuples that were already in the store.
+	Existing []Tuple
+}
+
+// Write prints the result, one tuple per line.
+func (r *Result) Write(w io.Writer, dryRun bool) {
+	verb := "write"
+	if dryRun {
+		verb = "would write"
+	}
+
+	for _, t := range r.Written {
+		fmt.Fprintf(w, "%s: %s\n", verb, t)
+	}
+
+	for _, t := range r.Existing {
+		fmt.Fprintf(w, "exists: %s\n", t)
+	}
+}
+
+// Apply seeds the store with the tuples of files, in tx. Tuples are checked
+// against model, so that a typo fails the seeding rather than writing a
+// tuple OpenFGA ignores. With dryRun, nothing is written.
+func Apply(ctx context.Context, tx *sql.Tx, storeID string, model *openfgav1.AuthorizationModel,
+	files []*File, dryRun bool) (*Result, error) {
+	result := &Result{}
+
+	for _, file := range files {
+		for _, t := range file.Tuples {
+			resolved, err := resolve(ctx, tx, t)
+			if err != nil {
+				return nil, fmt.Errorf("tuple %s: %w", t, err)
+			}
+
+			if err := validate(model, resolved); err != nil {
+				return nil, fmt.Errorf("tuple %s: %w", t, err)
+			}
+
+			if slices.Contains(result.Written, resolved) || slices.Contains(result.Existing, resolved) {
+				continue
+			}
+
+			exists, err := tupleExists(ctx, tx, storeID, resolved)
+			if err != nil {
+				return nil, fmt.Errorf("tuple %s: %w", t, err)
+			}
+
+			if exists {
+				result.Existing = append(result.Existing, resolved)
+				continue
+			}
+
+			if !dryRun {
+				key := tupleUtils.NewTupleKey(resolved.Object, resolved.Relation, resolved.User)
+				if err := tuples.Write(ctx, tx, storeID, key); err != nil {
+					return nil, fmt.Errorf("tuple %s: %w", t, err)
+				}
+			}
+
+			result.Written = append(result.Written, resolved)
+		}
+	}
+
+	return result, nil
+}
+
+// resolve replaces references to MAAS objects by their ID.
+func resolve(ctx context.Context, tx *sql.Tx, t Tuple) (Tuple, error) {
+	userObject, userRelation := tupleUtils.SplitObjectRelation(t.User)
+
+	user, err := resolveObject(ctx, tx, userObject)
+	if err != nil {
+		return Tuple{}, err
+	}
+
+	t.User = tupleUtils.GetObjectRelationAsString(&openfgav1.ObjectRelation{Object: user, Relation: userRelation})
+
+	t.Object, err = resolveObject(ctx, tx, t.Object)
+	if err != nil {
+		return Tuple{}, err
+	}
+
+	return t, nil
+}
+
+func resolveObject(ctx context.Context, tx *sql.Tx, object string) (string, error) {
+	objectType, objectID := tupleUtils.SplitObject(object)
+
+	name, ok := strings.CutPrefix(objectID, referencePrefix)
+	if !ok {
+		return object, nil
+	}
+
+	ref, ok := references[objectType]
+	if !ok {
+		return "", fmt.Errorf("objects of type %q can't be referred to by name", objectType)
+	}
+
+	stmt, args, err := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+		Select("id::text").
+		From(ref.table).
+		Where(sq.Eq{ref.column: name}).
+		ToSql()
+	if err != nil {
+		return "", err
+	}
+
+	var id string
+
+	err = tx.QueryRowContext(ctx, stmt, args...).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("%s %q does not exist", objectType, name)
+	} else if err != nil {
+		return "", err
+	}
+
+	return tupleUtils.BuildObject(objectType, id), nil
+}
+
+// validate checks that the model defines the relation on the object type,
+// and allows it for the given user type.
+func validate(model *openfgav1.AuthorizationModel, t Tuple) error {
+	objectType, _ := tupleUtils.SplitObject(t.Object)
+	userObject, userRelation := tupleUtils.SplitObjectRelation(t.User)
+	userType, _ := tupleUtils.SplitObject(userObject)
+
+	for _, typeDef := range model.GetTypeDefinitions() {
+		if typeDef.GetType() != objectType {
+			continue
+		}
+
+		if _, ok := typeDef.GetRelations()[t.Relation]; !ok {
+			return fmt.Errorf("type %q has no relation %q", objectType, t.Relation)
+		}
+
+		for _, allowed := range typeDef.GetMetadata().GetRelations()[t.Relation].GetDirectlyRelatedUserTypes() {
+			if allowed.GetType() == userType && allowed.GetRelation() == userRelation {
+				return nil
+			}
+		}
+
+		return fmt.Errorf("relation %q of type %q can't be granted to %q", t.Relation, objectType, t.User)
+	}
+
+	return fmt.Errorf("unknown type %q", objectType)
+}
+
+func tupleExists(ctx context.Context, tx *sql.Tx, storeID string, t Tuple) (bool, error) {
+	objectType, objectID := tupleUtils.SplitObject(t.Object)
+
+	stmt, args, err := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+		Select("1").
+		From("openfga.tuple").
+		Where(sq.Eq{
+			"store":       storeID,
+			"object_type": objectType,
+			"object_id":   objectID,
+			"relation":    t.Relation,
+			"_user":       t.User,
+		}).
+		ToSql()
+	if err != nil {
+		return false, err
+	}
+
+	var one int
+
+	err = tx.QueryRowContext(ctx, stmt, args...).Scan(&one)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
@@ -0,0 +1,128 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package seed
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authzmodel "maas.io/core/src/maasopenfga/internal/model"
+)
+
+func TestParse(t *testing.T) {
+	testcases := map[string]struct {
+		in     string
+		tuples int
+		err    string
+	}{
+		"valid": {
+			in: `
+tuples:
+  - {user: "group:@Administrators#member", relation: can_edit_machines, object: "maas:0"}
+  - {user: "maas:0", relation: parent, object: "pool:@default"}
+`,
+			tuples: 2,
+		},
+		"empty": {},
+		"unknown field": {
+			in:  "tuple: []",
+			err: "field tuple not found",
+		},
+		"incomplete tuple": {
+			in:  `tuples: [{user: "maas:0", object: "pool:1"}]`,
+			err: "tuple 1 needs a user, a relation and an object",
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			file, err := Parse([]byte(tc.in))
+			if tc.err != "" {
+				assert.ErrorContains(t, err, tc.err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Len(t, file.Tuples, tc.tuples)
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	model, err := authzmodel.Load("v3")
+	require.NoError(t, err)
+
+	testcases := map[string]struct {
+		tuple Tuple
+		err   string
+	}{
+		"group grant": {
+			tuple: Tuple{User: "group:1#member", Relation: "can_edit_machines", Object: "maas:0"},
+		},
+		"parent": {
+			tuple: Tuple{User: "maas:0", Relation: "parent", Object: "pool:1"},
+		},
+		"ban": {
+			tuple: Tuple{User: "user:1", Relation: "banned", Object: "maas:0"},
+		},
+		"unknown type": {
+			tuple: Tuple{User: "maas:0", Relation: "parent", Object: "system:1"},
+			err:   `unknown type "system"`,
+		},
+		"unknown relation": {
+			tuple: Tuple{User: "group:1#member", Relation: "can_edit_everything", Object: "maas:0"},
+			err:   `type "maas" has no relation "can_edit_everything"`,
+		},
+		"user type not allowed": {
+			tuple: Tuple{User: "user:1", Relation: "can_edit_machines", Object: "maas:0"},
+			err:   `relation "can_edit_machines" of type "maas" can't be granted to "user:1"`,
+		},
+		"computed relation": {
+			tuple: Tuple{User: "maas:0", Relation: "banned", Object: "pool:1"},
+			err:   `relation "banned" of type "pool" can't be granted to "maas:0"`,
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			err := validate(model, tc.tuple)
+			if tc.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.EqualError(t, err, tc.err)
+		})
+	}
+}
+
+func TestResultWrite(t *testing.T) {
+	result := &Result{
+		Written:  []Tuple{{User: "maas:0", Relation: "parent", Object: "pool:2"}},
+		Existing: []Tuple{{User: "maas:0", Relation: "parent", Object: "pool:1"}},
+	}
+
+	var out bytes.Buffer
+
+	result.Write(&out, true)
+	assert.Equal(t, "would write: maas:0 parent pool:2\nexists: maas:0 parent pool:1\n", out.String())
+
+	out.Reset()
+	result.Write(&out, false)
+	assert.Equal(t, "write: maas:0 parent pool:2\nexists: maas:0 parent pool:1\n", out.String())
+}
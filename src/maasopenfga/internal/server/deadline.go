@@ -0,0 +1,90 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// requestTimeoutHeader carries the time left to the upstream request (e.g.
+// a MAAS API call), in milliseconds.
+const requestTimeoutHeader = "MAAS-Request-Timeout"
+
+var deadlineExceededCounter = promauto.NewCounter(prometheus.CounterOpts{
+	Namespace: "maas_openfga",
+	Name:      "request_deadline_exceeded_total",
+	Help:      "Number of requests abandoned because the upstream request timed out.",
+})
+
+// withRequestDeadline turns the time left to the upstream request into a
+// context deadline, so that authorization work nobody waits for anymore is
+// abandoned and its database connections are released. Queries resolving
+// relations are also given at most timeout.
+func withRequestDeadline(mux *runtime.ServeMux, timeout time.Duration, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var budget time.Duration
+
+		if value := r.Header.Get(requestTimeoutHeader); value != "" {
+			ms, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || ms < 0 {
+				runtime.HTTPError(r.Context(), mux, &runtime.JSONPb{}, w, r,
+					status.Errorf(codes.InvalidArgument, "invalid %s header %q", requestTimeoutHeader, value))
+
+				return
+			}
+
+			if ms == 0 {
+				deadlineExceededCounter.Inc()
+				runtime.HTTPError(r.Context(), mux, &runtime.JSONPb{}, w, r,
+					status.Error(codes.DeadlineExceeded, "upstream request deadline exceeded"))
+
+				return
+			}
+
+			budget = time.Duration(ms) * time.Millisecond
+		}
+
+		upstream := budget != 0
+
+		if timeout > 0 && isQuery(r) && (budget == 0 || timeout < budget) {
+			budget, upstream = timeout, false
+		}
+
+		if budget == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), budget)
+		defer cancel()
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+
+		if upstream && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			deadlineExceededCounter.Inc()
+		}
+	})
+}
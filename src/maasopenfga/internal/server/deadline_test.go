@@ -0,0 +1,124 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithRequestDeadline(t *testing.T) {
+	testcases := map[string]struct {
+		header       string
+		path         string
+		timeout      time.Duration
+		wantStatus   int
+		wantCalled   bool
+		wantDeadline time.Duration
+	}{
+		"no header": {
+			wantStatus: http.StatusOK,
+			wantCalled: true,
+		},
+		"budget left": {
+			header:       "1500",
+			wantStatus:   http.StatusOK,
+			wantCalled:   true,
+			wantDeadline: 1500 * time.Millisecond,
+		},
+		"budget exhausted": {
+			header:     "0",
+			wantStatus: http.StatusGatewayTimeout,
+		},
+		"invalid": {
+			header:     "soon",
+			wantStatus: http.StatusBadRequest,
+		},
+		"negative": {
+			header:     "-1",
+			wantStatus: http.StatusBadRequest,
+		},
+		"request timeout": {
+			timeout:      2 * time.Second,
+			wantStatus:   http.StatusOK,
+			wantCalled:   true,
+			wantDeadline: 2 * time.Second,
+		},
+		"budget shorter than request timeout": {
+			header:       "1500",
+			timeout:      2 * time.Second,
+			wantStatus:   http.StatusOK,
+			wantCalled:   true,
+			wantDeadline: 1500 * time.Millisecond,
+		},
+		"request timeout shorter than budget": {
+			header:       "5000",
+			timeout:      2 * time.Second,
+			wantStatus:   http.StatusOK,
+			wantCalled:   true,
+			wantDeadline: 2 * time.Second,
+		},
+		"request timeout not applied to writes": {
+			path:       "/stores/store/write",
+			timeout:    2 * time.Second,
+			wantStatus: http.StatusOK,
+			wantCalled: true,
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			var (
+				called   bool
+				deadline time.Time
+				ok       bool
+			)
+
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				called = true
+				deadline, ok = r.Context().Deadline()
+			})
+
+			path := tc.path
+			if path == "" {
+				path = "/stores/store/check"
+			}
+
+			req := httptest.NewRequest(http.MethodPost, path, nil)
+			if tc.header != "" {
+				req.Header.Set(requestTimeoutHeader, tc.header)
+			}
+
+			rec := httptest.NewRecorder()
+			start := time.Now()
+
+			withRequestDeadline(runtime.NewServeMux(), tc.timeout, next).ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.wantStatus, rec.Code)
+			assert.Equal(t, tc.wantCalled, called)
+			assert.Equal(t, tc.wantDeadline != 0, ok)
+
+			if tc.wantDeadline != 0 {
+				assert.WithinDuration(t, start.Add(tc.wantDeadline), deadline, 100*time.Millisecond)
+			}
+		})
+	}
+}
@@ -0,0 +1,188 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/openfga/openfga/pkg/logger"
+	serverErrors "github.com/openfga/openfga/pkg/server/errors"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Error codes identify the errors answered by the API, in the type of
+// their details, independently of the OpenFGA error codes they map to.
+const (
+	InvalidArgument  = "invalid_argument"
+	NotAuthenticated = "not_authenticated"
+	PermissionDenied = "permission_denied"
+	NotFound         = "not_found"
+	Conflict         = "conflict"
+	Unavailable      = "unavailable"
+	DeadlineExceeded = "deadline_exceeded"
+	Cancelled        = "cancelled"
+	InternalError    = "internal_error"
+)
+
+// statusClientClosedRequest is answered to requests the client gave up on.
+const statusClientClosedRequest = 499
+
+type errorCode struct {
+	status  int
+	message string
+}
+
+// errorCodes are the HTTP status and generic message of each error code,
+// which follow the MAAS API.
+var errorCodes = map[string]errorCode{
+	InvalidArgument:  {http.StatusBadRequest, "Bad request."},
+	NotAuthenticated: {http.StatusUnauthorized, "Unauthorized."},
+	PermissionDenied: {http.StatusForbidden, "Forbidden."},
+	NotFound:         {http.StatusNotFound, "Entity not found."},
+	Conflict: {http.StatusConflict,
+		"The request could not be completed due to a conflict with an existing resource."},
+	ResolutionLimitExceeded: {http.StatusUnprocessableEntity, "The request exceeded the resolution limits."},
+	Unavailable: {http.StatusServiceUnavailable,
+		"The service is not available. Please check the server logs for more details."},
+	DeadlineExceeded: {http.StatusGatewayTimeout, "The request deadline was exceeded."},
+	Cancelled:        {statusClientClosedRequest, "The request was cancelled."},
+	InternalError: {http.StatusInternalServerError,
+		"Unexpected internal server error. Please check the server logs for more details."},
+}
+
+// grpcErrorCodes maps the gRPC codes of the errors of the MAAS additions
+// to error codes.
+var grpcErrorCodes = map[codes.Code]string{
+	codes.InvalidArgument:    InvalidArgument,
+	codes.FailedPrecondition: InvalidArgument,
+	codes.OutOfRange:         InvalidArgument,
+	codes.Unauthenticated:    NotAuthenticated,
+	codes.PermissionDenied:   PermissionDenied,
+	codes.NotFound:           NotFound,
+	codes.AlreadyExists:      Conflict,
+	codes.Aborted:            Conflict,
+	codes.Unavailable:        Unavailable,
+	codes.ResourceExhausted:  Unavailable,
+	codes.DeadlineExceeded:   DeadlineExceeded,
+	codes.Canceled:           Cancelled,
+}
+
+// openfgaErrorCodes maps the OpenFGA errors that don't fit the HTTP
+// status OpenFGA answers them with.
+var openfgaErrorCodes = map[codes.Code]string{
+	codes.Code(openfgav1.ErrorCode_authorization_model_not_found):        NotFound,
+	codes.Code(openfgav1.ErrorCode_latest_authorization_model_not_found): NotFound,
+	codes.Code(openfgav1.ErrorCode_cancelled):                            Cancelled,
+	codes.Code(openfgav1.InternalErrorCode_already_exists):               Conflict,
+	codes.Code(openfgav1.InternalErrorCode_unavailable):                  Unavailable,
+	codes.Code(openfgav1.InternalErrorCode_resource_exhausted):           Unavailable,
+}
+
+// statusErrorCodes maps the HTTP status OpenFGA answers its other errors
+// with to error codes.
+var statusErrorCodes = map[int]string{
+	http.StatusBadRequest:   InvalidArgument,
+	http.StatusUnauthorized: NotAuthenticated,
+	http.StatusNotFound:     NotFound,
+	http.StatusConflict:     Conflict,
+}
+
+// errorBody is the error envelope of the MAAS API.
+type errorBody struct {
+	Kind    string        `json:"kind"`
+	Message string        `json:"message"`
+	Details []errorDetail `json:"details"`
+	Code    int           `json:"code"`
+}
+
+type errorDetail struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+// classifyError returns the error code of err, and the message telling
+// what went wrong.
+func classifyError(err error) (string, string) {
+	st := status.Convert(err)
+
+	if reason, ok := resolutionLimitReasons[st.Code()]; ok {
+		resolutionLimitCounter.WithLabelValues(reason).Inc()
+		return ResolutionLimitExceeded, st.Message()
+	}
+
+	if !serverErrors.IsValidEncodedError(int32(st.Code())) {
+		if code, ok := grpcErrorCodes[st.Code()]; ok {
+			return code, st.Message()
+		}
+
+		return InternalError, st.Message()
+	}
+
+	// Strips the causes and gRPC prefixes from the message.
+	encoded := serverErrors.NewEncodedError(int32(st.Code()), st.Message())
+
+	if code, ok := openfgaErrorCodes[st.Code()]; ok {
+		return code, encoded.Error()
+	}
+
+	if code, ok := statusErrorCodes[encoded.HTTPStatus()]; ok {
+		return code, encoded.Error()
+	}
+
+	return InternalError, encoded.Error()
+}
+
+// errorHandler writes the errors of the API in the error envelope of the
+// MAAS API, with the error code as the type of its detail, instead of the
+// gRPC status the OpenFGA API answers. Errors are logged with l, internal
+// ones at the error level.
+func errorHandler(l logger.Logger) runtime.ErrorHandlerFunc {
+	return func(ctx context.Context, _ *runtime.ServeMux, _ runtime.Marshaler,
+		w http.ResponseWriter, r *http.Request, err error) {
+		code, message := classifyError(err)
+		ec := errorCodes[code]
+
+		fields := []zap.Field{
+			zap.String("method", r.Method),
+			zap.String("path", r.URL.Path),
+			zap.String("code", code),
+			zap.Error(err),
+		}
+
+		if ec.status >= http.StatusInternalServerError {
+			l.ErrorWithContext(ctx, "request failed", fields...)
+		} else {
+			l.DebugWithContext(ctx, "request failed", fields...)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(ec.status)
+
+		//nolint:errcheck // the client is probably gone already
+		json.NewEncoder(w).Encode(errorBody{
+			Kind:    "Error",
+			Code:    ec.status,
+			Message: ec.message,
+			Details: []errorDetail{{Type: code, Message: message}},
+		})
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/openfga/openfga/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestErrorHandler(t *testing.T) {
+	testcases := map[string]struct {
+		err         error
+		wantStatus  int
+		wantType    string
+		wantMessage string
+	}{
+		"too complex": {
+			err: status.Error(codes.Code(openfgav1.ErrorCode_authorization_model_resolution_too_complex),
+				"too many rewrite rules"),
+			wantStatus:  http.StatusUnprocessableEntity,
+			wantType:    ResolutionLimitExceeded,
+			wantMessage: "too many rewrite rules",
+		},
+		"throttled": {
+			err: status.Error(codes.Code(openfgav1.UnprocessableContentErrorCode_throttled_timeout_error),
+				"timeout due to throttling"),
+			wantStatus:  http.StatusUnprocessableEntity,
+			wantType:    ResolutionLimitExceeded,
+			wantMessage: "timeout due to throttling",
+		},
+		"timeout": {
+			err: status.Error(codes.Code(openfgav1.InternalErrorCode_deadline_exceeded),
+				"Request Deadline Exceeded"),
+			wantStatus:  http.StatusUnprocessableEntity,
+			wantType:    ResolutionLimitExceeded,
+			wantMessage: "Request Deadline Exceeded",
+		},
+		"validation": {
+			err: status.Error(codes.Code(openfgav1.ErrorCode_relation_not_found),
+				"rpc error: code = InvalidArgument desc = relation 'pool#foo' not found"),
+			wantStatus:  http.StatusBadRequest,
+			wantType:    InvalidArgument,
+			wantMessage: "relation 'pool#foo' not found",
+		},
+		"model not found": {
+			err:         status.Error(codes.Code(openfgav1.ErrorCode_latest_authorization_model_not_found), "no model"),
+			wantStatus:  http.StatusNotFound,
+			wantType:    NotFound,
+			wantMessage: "no model",
+		},
+		"store not found": {
+			err:         status.Error(codes.Code(openfgav1.NotFoundErrorCode_store_id_not_found), "no store"),
+			wantStatus:  http.StatusNotFound,
+			wantType:    NotFound,
+			wantMessage: "no store",
+		},
+		"openfga internal": {
+			err:         status.Error(codes.Code(openfgav1.InternalErrorCode_internal_error), "internal server error"),
+			wantStatus:  http.StatusInternalServerError,
+			wantType:    InternalError,
+			wantMessage: "internal server error",
+		},
+		"read-only": {
+			err:         status.Error(codes.PermissionDenied, "this maas-openfga is a read-only mirror"),
+			wantStatus:  http.StatusForbidden,
+			wantType:    PermissionDenied,
+			wantMessage: "this maas-openfga is a read-only mirror",
+		},
+		"upstream deadline": {
+			err:         status.Error(codes.DeadlineExceeded, "upstream request deadline exceeded"),
+			wantStatus:  http.StatusGatewayTimeout,
+			wantType:    DeadlineExceeded,
+			wantMessage: "upstream request deadline exceeded",
+		},
+		"plain error": {
+			err:         errors.New("boom"),
+			wantStatus:  http.StatusInternalServerError,
+			wantType:    InternalError,
+			wantMessage: "boom",
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/stores/store/check", nil)
+			rec := httptest.NewRecorder()
+
+			errorHandler(logger.NewNoopLogger())(context.Background(), runtime.NewServeMux(),
+				&runtime.JSONPb{}, rec, req, tc.err)
+
+			assert.Equal(t, tc.wantStatus, rec.Code)
+			assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+			var body errorBody
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+			assert.Equal(t, "Error", body.Kind)
+			assert.Equal(t, tc.wantStatus, body.Code)
+			assert.Equal(t, errorCodes[tc.wantType].message, body.Message)
+			assert.Equal(t, []errorDetail{{Type: tc.wantType, Message: tc.wantMessage}}, body.Details)
+		})
+	}
+}
@@ -0,0 +1,187 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package server_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"maas.io/core/src/maasopenfga/internal/server/servertest"
+)
+
+// latencyBudgetEnv names the environment variable holding the p95 Check
+// latency allowed by TestCheckLatencyBudget, e.g. "20ms". It depends on
+// the hardware the test runs on, CI sets the one of its runners.
+const latencyBudgetEnv = "MAAS_OPENFGA_LATENCY_BUDGET"
+
+// Size of the seeded site, in the range of a large MAAS deployment.
+const (
+	seedUsers    = 2000
+	seedGroups   = 50
+	seedPools    = 100
+	seedTags     = 50
+	seedMachines = 5000
+
+	latencyChecks = 1000
+	writeChunk    = 100
+)
+
+// TestCheckLatencyBudget fails when the p95 latency of Check requests on
+// a realistic tuple set exceeds the budget, to catch performance
+// regressions of the model or of the server configuration before release.
+func TestCheckLatencyBudget(t *testing.T) {
+	budget := os.Getenv(latencyBudgetEnv)
+	if budget == "" {
+		t.Skipf("%s is not set", latencyBudgetEnv)
+	}
+
+	p95Budget, err := time.ParseDuration(budget)
+	require.NoError(t, err, "invalid %s", latencyBudgetEnv)
+
+	srv := servertest.Start(t)
+	// Always the same site, for latencies to be comparable across runs.
+	rnd := rand.New(rand.NewPCG(1, 2)) //nolint:gosec // not used for security
+
+	keys := seedSite(rnd)
+	for chunk := range slices.Chunk(keys, writeChunk) {
+		post(t, srv.URL+"/stores/maas/write", &openfgav1.WriteRequest{
+			Writes: &openfgav1.WriteRequestWrites{TupleKeys: chunk, OnDuplicate: "ignore"},
+		}, &openfgav1.WriteResponse{})
+	}
+
+	relations := []string{"can_view", "can_deploy", "can_edit"}
+	latencies := make([]time.Duration, 0, latencyChecks)
+
+	for range latencyChecks {
+		req := &openfgav1.CheckRequest{TupleKey: &openfgav1.CheckRequestTupleKey{
+			User:     fmt.Sprintf("user:%d", rnd.IntN(seedUsers)),
+			Relation: relations[rnd.IntN(len(relations))],
+			Object:   fmt.Sprintf("machine:%d", rnd.IntN(seedMachines)),
+		}}
+
+		start := time.Now()
+
+		post(t, srv.URL+"/stores/maas/check", req, &openfgav1.CheckResponse{})
+
+		latencies = append(latencies, time.Since(start))
+	}
+
+	slices.Sort(latencies)
+
+	p95 := percentile(latencies, 95)
+
+	t.Logf("check latency over %d tuples: p50 %s, p95 %s, p99 %s", len(keys),
+		percentile(latencies, 50), p95, percentile(latencies, 99))
+	assert.LessOrEqual(t, p95, p95Budget, "p95 check latency over budget")
+}
+
+// seedSite returns the tuples of a site where users belong to a few
+// groups, groups are granted permissions on some pools and tags, and
+// administrators on the whole of MAAS.
+func seedSite(rnd *rand.Rand) []*openfgav1.TupleKey {
+	var keys []*openfgav1.TupleKey
+
+	seen := make(map[string]bool)
+
+	// Random picks may repeat a tuple, which can't be written twice.
+	add := func(user, relation, object string) {
+		key := &openfgav1.TupleKey{User: user, Relation: relation, Object: object}
+		if s := tuple.TupleKeyToString(key); !seen[s] {
+			seen[s] = true
+			keys = append(keys, key)
+		}
+	}
+
+	for user := range seedUsers {
+		for range 1 + rnd.IntN(3) {
+			add(fmt.Sprintf("user:%d", user), "member", fmt.Sprintf("group:%d", rnd.IntN(seedGroups)))
+		}
+	}
+
+	add("group:0#member", "can_edit_machines", "maas:0")
+	add("group:0#member", "can_edit_global_entities", "maas:0")
+	add("user:1", "banned", "maas:0")
+
+	entitlements := []string{"can_view_machines", "can_deploy_machines", "can_edit_machines"}
+
+	for pool := range seedPools {
+		add("maas:0", "parent", fmt.Sprintf("pool:%d", pool))
+
+		for range 1 + rnd.IntN(3) {
+			add(fmt.Sprintf("group:%d#member", 1+rnd.IntN(seedGroups-1)),
+				entitlements[rnd.IntN(len(entitlements))], fmt.Sprintf("pool:%d", pool))
+		}
+	}
+
+	for tag := range seedTags {
+		add("maas:0", "parent", fmt.Sprintf("tag:%d", tag))
+		add(fmt.Sprintf("group:%d#member", 1+rnd.IntN(seedGroups-1)),
+			entitlements[rnd.IntN(len(entitlements))], fmt.Sprintf("tag:%d", tag))
+	}
+
+	for machine := range seedMachines {
+		object := fmt.Sprintf("machine:%d", machine)
+		add(fmt.Sprintf("pool:%d", rnd.IntN(seedPools)), "pool", object)
+
+		if rnd.IntN(4) == 0 {
+			add(fmt.Sprintf("tag:%d", rnd.IntN(seedTags)), "tag", object)
+		}
+	}
+
+	return keys
+}
+
+// percentile returns the p-th percentile of sorted latencies.
+func percentile(latencies []time.Duration, p int) time.Duration {
+	return latencies[(len(latencies)-1)*p/100]
+}
+
+func post(t *testing.T, url string, in, out proto.Message) {
+	t.Helper()
+
+	data, err := protojson.Marshal(in)
+	require.NoError(t, err)
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, url, bytes.NewReader(data))
+	require.NoError(t, err)
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+
+	require.NoError(t, protojson.Unmarshal(body, out))
+}
@@ -0,0 +1,71 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package server
+
+import (
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc/codes"
+)
+
+// ResolutionLimitExceeded is the error code answered, with status 422, to
+// queries abandoned because they hit the resolution limits: too deep a
+// graph, or too long a resolution once throttled or past the request
+// timeout. Clients must consider such checks denied.
+const ResolutionLimitExceeded = "resolution_limit_exceeded"
+
+const (
+	defaultMaxResolutionDepth   = 25
+	defaultMaxResolutionBreadth = 10
+	defaultRequestTimeout       = 3 * time.Second
+)
+
+var resolutionLimitCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "maas_openfga",
+	Name:      "resolution_limit_exceeded_total",
+	Help:      "Number of queries abandoned because they hit a resolution limit, by reason.",
+}, []string{"reason"})
+
+// queryEndpoints are the last path segment of the store endpoints
+// resolving relations, which are given the request timeout.
+var queryEndpoints = []string{
+	"access",
+	"batch-check",
+	"check",
+	"expand",
+	"list-objects",
+	"list-users",
+}
+
+// resolutionLimitReasons are the OpenFGA errors reporting a resolution
+// limit, and the reasons they are counted under.
+var resolutionLimitReasons = map[codes.Code]string{
+	codes.Code(openfgav1.ErrorCode_authorization_model_resolution_too_complex):  "depth",
+	codes.Code(openfgav1.UnprocessableContentErrorCode_throttled_timeout_error): "throttled",
+	codes.Code(openfgav1.InternalErrorCode_deadline_exceeded):                   "timeout",
+}
+
+func isQuery(r *http.Request) bool {
+	return strings.HasPrefix(r.URL.Path, storesPrefix) &&
+		slices.Contains(queryEndpoints, path.Base(r.URL.Path))
+}
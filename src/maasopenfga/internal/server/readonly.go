@@ -0,0 +1,65 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package server
+
+import (
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// readEndpoints are the last path segment of the store endpoints that are
+// POSTed to but don't write anything.
+var readEndpoints = []string{
+	"batch-check",
+	"check",
+	"expand",
+	"list-objects",
+	"list-users",
+	"read",
+	"streamed-list-objects",
+}
+
+// withReadOnly rejects the requests that would write to the datastore.
+func withReadOnly(mux *runtime.ServeMux, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !isRead(r) {
+			runtime.HTTPError(r.Context(), mux, &runtime.JSONPb{}, w, r,
+				status.Error(codes.PermissionDenied, "this maas-openfga endpoint is read-only"))
+
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func isRead(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		return true
+	case http.MethodPost:
+		return strings.HasPrefix(r.URL.Path, storesPrefix) &&
+			slices.Contains(readEndpoints, path.Base(r.URL.Path))
+	default:
+		return false
+	}
+}
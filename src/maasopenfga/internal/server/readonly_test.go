@@ -0,0 +1,100 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithReadOnly(t *testing.T) {
+	testcases := map[string]struct {
+		method     string
+		path       string
+		wantStatus int
+	}{
+		"list stores": {
+			method:     http.MethodGet,
+			path:       "/stores",
+			wantStatus: http.StatusOK,
+		},
+		"read changes": {
+			method:     http.MethodGet,
+			path:       "/stores/maas/changes",
+			wantStatus: http.StatusOK,
+		},
+		"check": {
+			method:     http.MethodPost,
+			path:       "/stores/maas/check",
+			wantStatus: http.StatusOK,
+		},
+		"list objects": {
+			method:     http.MethodPost,
+			path:       "/stores/maas/list-objects",
+			wantStatus: http.StatusOK,
+		},
+		"read tuples": {
+			method:     http.MethodPost,
+			path:       "/stores/maas/read",
+			wantStatus: http.StatusOK,
+		},
+		"write tuples": {
+			method:     http.MethodPost,
+			path:       "/stores/maas/write",
+			wantStatus: http.StatusForbidden,
+		},
+		"write model": {
+			method:     http.MethodPost,
+			path:       "/stores/maas/authorization-models",
+			wantStatus: http.StatusForbidden,
+		},
+		"write assertions": {
+			method:     http.MethodPut,
+			path:       "/stores/maas/assertions/01JZ5V0W8Y3K6N3M2Q4R5S6T7V",
+			wantStatus: http.StatusForbidden,
+		},
+		"create store": {
+			method:     http.MethodPost,
+			path:       "/stores",
+			wantStatus: http.StatusForbidden,
+		},
+		"delete store": {
+			method:     http.MethodDelete,
+			path:       "/stores/maas",
+			wantStatus: http.StatusForbidden,
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			var called bool
+
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				called = true
+			})
+
+			rec := httptest.NewRecorder()
+			withReadOnly(runtime.NewServeMux(), next).ServeHTTP(rec, httptest.NewRequest(tc.method, tc.path, nil))
+
+			assert.Equal(t, tc.wantStatus, rec.Code)
+			assert.Equal(t, tc.wantStatus == http.StatusOK, called)
+		})
+	}
+}
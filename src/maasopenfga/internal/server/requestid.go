@@ -0,0 +1,117 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package server
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/openfga/openfga/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// RequestIDHeader carries the ID of a request, set by the client (e.g. the
+// ID regiond logs for the API call being authorized) or by the server, and
+// always returned in the response.
+const RequestIDHeader = "X-Request-Id"
+
+// maxRequestIDLength is the length past which IDs sent by clients are
+// replaced, so that they can't flood the logs.
+const maxRequestIDLength = 128
+
+type requestIDKey struct{}
+
+// RequestID returns the ID of the request ctx belongs to, or "" outside
+// requests.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string) //nolint:errcheck // "" when unset
+
+	return id
+}
+
+// withRequestID propagates the ID of the request, or assigns a new one, so
+// that its log lines and its response can be correlated.
+func withRequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID(id) {
+			id = ulid.Make().String()
+		}
+
+		w.Header().Set(RequestIDHeader, id)
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id)))
+	})
+}
+
+// validRequestID reports whether id is short and only holds printable
+// ASCII characters.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+
+	for i := range len(id) {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// requestLogger adds the request ID to the records logged with the
+// context of a request, e.g. by the OpenFGA service.
+type requestLogger struct {
+	logger.Logger
+}
+
+func withRequestIDField(ctx context.Context, fields []zap.Field) []zap.Field {
+	if id := RequestID(ctx); id != "" {
+		return append(fields, zap.String("request_id", id))
+	}
+
+	return fields
+}
+
+func (l requestLogger) With(fields ...zap.Field) logger.Logger {
+	return requestLogger{l.Logger.With(fields...)}
+}
+
+func (l requestLogger) DebugWithContext(ctx context.Context, msg string, fields ...zap.Field) {
+	l.Logger.DebugWithContext(ctx, msg, withRequestIDField(ctx, fields)...)
+}
+
+func (l requestLogger) InfoWithContext(ctx context.Context, msg string, fields ...zap.Field) {
+	l.Logger.InfoWithContext(ctx, msg, withRequestIDField(ctx, fields)...)
+}
+
+func (l requestLogger) WarnWithContext(ctx context.Context, msg string, fields ...zap.Field) {
+	l.Logger.WarnWithContext(ctx, msg, withRequestIDField(ctx, fields)...)
+}
+
+func (l requestLogger) ErrorWithContext(ctx context.Context, msg string, fields ...zap.Field) {
+	l.Logger.ErrorWithContext(ctx, msg, withRequestIDField(ctx, fields)...)
+}
+
+func (l requestLogger) PanicWithContext(ctx context.Context, msg string, fields ...zap.Field) {
+	l.Logger.PanicWithContext(ctx, msg, withRequestIDField(ctx, fields)...)
+}
+
+func (l requestLogger) FatalWithContext(ctx context.Context, msg string, fields ...zap.Field) {
+	l.Logger.FatalWithContext(ctx, msg, withRequestIDField(ctx, fields)...)
+}
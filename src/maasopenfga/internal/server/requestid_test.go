@@ -0,0 +1,92 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/openfga/openfga/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestWithRequestID(t *testing.T) {
+	testcases := map[string]struct {
+		header   string
+		wantSame bool
+	}{
+		"assigned": {},
+		"propagated": {
+			header:   "regiond-1234",
+			wantSame: true,
+		},
+		"too long": {
+			header: strings.Repeat("a", maxRequestIDLength+1),
+		},
+		"control characters": {
+			header: "id\x00",
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			var got string
+
+			next := http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				got = RequestID(r.Context())
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/stores/store/check", nil)
+			if tc.header != "" {
+				req.Header.Set(RequestIDHeader, tc.header)
+			}
+
+			rec := httptest.NewRecorder()
+
+			withRequestID(next).ServeHTTP(rec, req)
+
+			assert.NotEmpty(t, got)
+			assert.Equal(t, got, rec.Header().Get(RequestIDHeader))
+
+			if tc.wantSame {
+				assert.Equal(t, tc.header, got)
+			} else {
+				assert.NotEqual(t, tc.header, got)
+			}
+		})
+	}
+}
+
+func TestRequestLogger(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+	l := requestLogger{&logger.ZapLogger{Logger: zap.New(core)}}
+
+	ctx := context.WithValue(context.Background(), requestIDKey{}, "id-1")
+
+	l.With(zap.String("component", "test")).ErrorWithContext(ctx, "in request")
+	l.InfoWithContext(context.Background(), "outside request")
+
+	entries := logs.All()
+	assert.Len(t, entries, 2)
+	assert.Equal(t, "id-1", entries[0].ContextMap()["request_id"])
+	assert.Equal(t, "test", entries[0].ContextMap()["component"])
+	assert.NotContains(t, entries[1].ContextMap(), "request_id")
+}
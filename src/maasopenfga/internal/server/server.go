@@ -0,0 +1,407 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package server serves the OpenFGA HTTP API of MAAS: the OpenFGA service
+// backed by the PostgreSQL datastore, with the MAAS additions (change
+// streams, access listing, metrics, store selection by name, request
+// deadlines, admission by priority, request IDs and MAAS error envelopes).
+//
+// It holds the wiring of maas-openfga serve, so tests can run the same
+// server in-process.
+package server
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	_ "github.com/jackc/pgx/v5/stdlib" // database/sql driver for storesDB
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/openfga/openfga/pkg/logger"
+	openfgaServer "github.com/openfga/openfga/pkg/server"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/postgres"
+	"github.com/openfga/openfga/pkg/storage/sqlcommon"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"maas.io/core/src/maasopenfga/internal/access"
+	"maas.io/core/src/maasopenfga/internal/changestream"
+	"maas.io/core/src/maasopenfga/internal/priority"
+	"maas.io/core/src/maasopenfga/internal/stores"
+)
+
+const (
+	defaultMaxOpenConns     = 3
+	defaultMaxIdleConns     = 1
+	defaultMaxBatchRequests = 1
+)
+
+// Config is the configuration of a Server.
+type Config struct {
+	// Logger defaults to a no-op logger.
+	Logger logger.Logger
+	// Datastore, when set, is served instead of the PostgreSQL datastore
+	// of DSN. It is left open when the server stops, and tenant stores
+	// can't be addressed by name.
+	Datastore storage.OpenFGADatastore
+	// DSN is the connection string of the datastore, which must set
+	// search_path to the openfga schema.
+	DSN string
+	// Listeners are the listeners to serve the API on. The server closes
+	// them when it stops.
+	Listeners []net.Listener
+	// ReadOnlyListeners serve the API like Listeners, but reject the
+	// requests writing stores, models or tuples.
+	ReadOnlyListeners []net.Listener
+	// MaxOpenConns and MaxIdleConns limit the connections to the
+	// datastore, 3 and 1 by default.
+	MaxOpenConns int
+	MaxIdleConns int
+	// MaxBatchRequests is the number of batch requests (e.g. replication)
+	// served at once, 1 by default.
+	MaxBatchRequests int
+	// MaxResolutionDepth and MaxResolutionBreadth limit the graph walked
+	// to answer a query: the levels of nested relations, 25 by default,
+	// and the relations resolved at once on each level, 10 by default.
+	MaxResolutionDepth   uint32
+	MaxResolutionBreadth uint32
+	// DispatchThrottlingThreshold, when set, slows down the checks
+	// resolving more relations than that, so that they don't hold the
+	// database connections other checks need.
+	DispatchThrottlingThreshold uint32
+	// RequestTimeout is the time given to a query to resolve, 3s by
+	// default. Shorter deadlines of upstream requests still apply.
+	RequestTimeout time.Duration
+	// ReadOnly rejects the requests writing stores, models or tuples.
+	ReadOnly bool
+}
+
+// Server serves the OpenFGA HTTP API on a set of listeners.
+type Server struct {
+	logger    logger.Logger
+	datastore storage.OpenFGADatastore
+	stopErr   error
+	storesDB  *sql.DB
+	service   *openfgaServer.Server
+	// stopping is closed when the server starts stopping, and stopped
+	// once it has released its resources.
+	stopping  chan struct{}
+	stopped   chan struct{}
+	listeners []net.Listener
+	servers   []*http.Server
+	stopOnce  sync.Once
+}
+
+// New connects to the datastore and prepares the API, which is served by
+// Run.
+func New(cfg Config) (*Server, error) {
+	if cfg.Logger == nil {
+		cfg.Logger = logger.NewNoopLogger()
+	}
+
+	cfg.Logger = requestLogger{cfg.Logger}
+
+	if cfg.MaxOpenConns <= 0 {
+		cfg.MaxOpenConns = defaultMaxOpenConns
+	}
+
+	if cfg.MaxIdleConns <= 0 {
+		cfg.MaxIdleConns = defaultMaxIdleConns
+	}
+
+	if cfg.MaxBatchRequests <= 0 {
+		cfg.MaxBatchRequests = defaultMaxBatchRequests
+	}
+
+	if cfg.MaxResolutionDepth == 0 {
+		cfg.MaxResolutionDepth = defaultMaxResolutionDepth
+	}
+
+	if cfg.MaxResolutionBreadth == 0 {
+		cfg.MaxResolutionBreadth = defaultMaxResolutionBreadth
+	}
+
+	if cfg.RequestTimeout <= 0 {
+		cfg.RequestTimeout = defaultRequestTimeout
+	}
+
+	s := &Server{
+		logger:    cfg.Logger,
+		listeners: append(slices.Clone(cfg.Listeners), cfg.ReadOnlyListeners...),
+		stopping:  make(chan struct{}),
+		stopped:   make(chan struct{}),
+	}
+
+	handler, readOnlyHandler, err := s.setUp(cfg)
+	if err != nil {
+		if errr := errors.Join(s.release()...); errr != nil {
+			s.logger.Error(fmt.Sprintf("failed to release server resources: %v", errr))
+		}
+
+		return nil, err
+	}
+
+	s.servers = make([]*http.Server, len(s.listeners))
+	for i := range s.servers {
+		s.servers[i] = &http.Server{
+			Handler:           handler,
+			ReadHeaderTimeout: 5 * time.Second,
+		}
+
+		if i >= len(cfg.Listeners) {
+			s.servers[i].Handler = readOnlyHandler
+		}
+	}
+
+	return s, nil
+}
+
+// setUp opens the resources of the server and returns the handlers of the
+// API, the second one rejecting writes. The resources opened before a
+// failure are released by the caller.
+func (s *Server) setUp(cfg Config) (http.Handler, http.Handler, error) {
+	datastore, resolver, err := s.openDatastore(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	options := []openfgaServer.OpenFGAServiceV1Option{
+		openfgaServer.WithDatastore(datastore),
+		openfgaServer.WithLogger(cfg.Logger),
+		openfgaServer.WithResolveNodeLimit(cfg.MaxResolutionDepth),
+		openfgaServer.WithResolveNodeBreadthLimit(cfg.MaxResolutionBreadth),
+		openfgaServer.WithRequestTimeout(cfg.RequestTimeout),
+		openfgaServer.WithListObjectsDeadline(cfg.RequestTimeout),
+		openfgaServer.WithListUsersDeadline(cfg.RequestTimeout),
+	}
+
+	if cfg.DispatchThrottlingThreshold > 0 {
+		options = append(options,
+			openfgaServer.WithDispatchThrottlingCheckResolverEnabled(true),
+			openfgaServer.WithDispatchThrottlingCheckResolverThreshold(cfg.DispatchThrottlingThreshold),
+			openfgaServer.WithDispatchThrottlingCheckResolverMaxThreshold(cfg.DispatchThrottlingThreshold),
+		)
+	}
+
+	s.service, err = openfgaServer.NewServerWithOpts(options...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mux := runtime.NewServeMux(runtime.WithErrorHandler(errorHandler(cfg.Logger)))
+
+	// The MAAS additions answer errors like the OpenFGA API.
+	writeError := func(w http.ResponseWriter, r *http.Request, err error) {
+		runtime.HTTPError(r.Context(), mux, &runtime.JSONPb{}, w, r, err)
+	}
+
+	// Handlers use the context of their request, not this one.
+	if err = openfgav1.RegisterOpenFGAServiceHandlerServer(
+		context.Background(),
+		mux,
+		s.service,
+	); err != nil {
+		return nil, nil, err
+	}
+
+	// Lets clients (e.g. regiond) invalidate cached decisions on changes.
+	if err = mux.HandlePath(http.MethodGet, "/stores/{store_id}/changes/watch",
+		changestream.New(s.service, changestream.WithErrorHandler(writeError)).HandlerFunc()); err != nil {
+		return nil, nil, err
+	}
+
+	// Answers "who has access" for the MAAS UI without walking Expand trees.
+	if err = mux.HandlePath(http.MethodGet, "/stores/{store_id}/access",
+		access.New(s.service, access.WithErrorHandler(writeError)).HandlerFunc()); err != nil {
+		return nil, nil, err
+	}
+
+	metrics := promhttp.Handler()
+	if err = mux.HandlePath(http.MethodGet, "/metrics",
+		func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+			metrics.ServeHTTP(w, r)
+		}); err != nil {
+		return nil, nil, err
+	}
+
+	admission := priority.NewAdmission(cfg.MaxBatchRequests, cfg.MaxOpenConns)
+	handler := withStoreSelection(mux, resolver, withAdmission(admission, mux))
+
+	readOnlyHandler := withReadOnly(mux, handler)
+	if cfg.ReadOnly {
+		handler = readOnlyHandler
+	}
+
+	return withRequestID(withRequestDeadline(mux, cfg.RequestTimeout, handler)),
+		withRequestID(withRequestDeadline(mux, cfg.RequestTimeout, readOnlyHandler)), nil
+}
+
+// openDatastore returns the datastore to serve, and the resolver of the
+// names of its stores.
+func (s *Server) openDatastore(cfg Config) (storage.OpenFGADatastore, *stores.Resolver, error) {
+	if cfg.Datastore != nil {
+		return cfg.Datastore, stores.NewDefaultResolver(), nil
+	}
+
+	var err error
+
+	s.datastore, err = postgres.New(
+		cfg.DSN,
+		sqlcommon.NewConfig(
+			sqlcommon.WithMaxOpenConns(cfg.MaxOpenConns),
+			sqlcommon.WithMaxIdleConns(cfg.MaxIdleConns),
+		),
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create postgres datastore: %w", err)
+	}
+
+	// Store names are cached by the resolver, a single connection is enough.
+	s.storesDB, err = sql.Open("pgx", cfg.DSN)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	s.storesDB.SetMaxOpenConns(1)
+
+	return s.datastore, stores.NewResolver(s.storesDB), nil
+}
+
+// Run serves the API until ctx is done, Shutdown is called or a listener
+// fails. Unless Shutdown is called, connections are closed right away.
+func (s *Server) Run(ctx context.Context) error {
+	serveErr := make(chan error, len(s.servers))
+
+	var wg sync.WaitGroup
+
+	for i, srv := range s.servers {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if err := srv.Serve(s.listeners[i]); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				serveErr <- err
+			}
+		}()
+	}
+
+	var err error
+
+	select {
+	case <-ctx.Done():
+		s.logger.Info("shutting down")
+	case <-s.stopping:
+	case err = <-serveErr:
+		err = fmt.Errorf("HTTP server failure: %w", err)
+	}
+
+	if errr := s.stop((*http.Server).Close); errr != nil {
+		s.logger.Error(fmt.Sprintf("failed to stop server: %v", errr))
+	}
+
+	wg.Wait()
+
+	return err
+}
+
+// Shutdown stops the server gracefully, waiting for the requests in flight
+// until ctx is done, and then releases its resources.
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.stop(func(srv *http.Server) error {
+		if err := srv.Shutdown(ctx); err != nil {
+			return errors.Join(err, srv.Close())
+		}
+
+		return nil
+	})
+}
+
+// stop stops the HTTP servers with stopServer and releases the resources
+// of the server. Only the first call stops the server, the others wait for
+// it to be stopped.
+func (s *Server) stop(stopServer func(*http.Server) error) error {
+	s.stopOnce.Do(func() {
+		close(s.stopping)
+
+		var errs []error
+
+		for _, srv := range s.servers {
+			if err := stopServer(srv); err != nil {
+				errs = append(errs, err)
+			}
+		}
+
+		s.stopErr = errors.Join(append(errs, s.release()...)...)
+
+		close(s.stopped)
+	})
+
+	<-s.stopped
+
+	return s.stopErr
+}
+
+// release closes the listeners, which are left open when the server never
+// ran, and whichever of the datastore and the service it opened.
+func (s *Server) release() []error {
+	var errs []error
+
+	for _, lis := range s.listeners {
+		if err := lis.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+			errs = append(errs, err)
+		}
+	}
+
+	if s.service != nil {
+		s.service.Close()
+	}
+
+	if s.storesDB != nil {
+		if err := s.storesDB.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close stores database: %w", err))
+		}
+	}
+
+	if s.datastore != nil {
+		s.datastore.Close()
+	}
+
+	return errs
+}
+
+// withAdmission admits API requests to mux according to their priority
+// class. Metrics and change streams are long-lived or cheap and bypass
+// admission.
+func withAdmission(admission *priority.Admission, mux *runtime.ServeMux) http.Handler {
+	admitted := admission.Handler(mux, mux)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet &&
+			(r.URL.Path == "/metrics" || strings.HasSuffix(r.URL.Path, "/changes/watch")) {
+			mux.ServeHTTP(w, r)
+			return
+		}
+
+		admitted.ServeHTTP(w, r)
+	})
+}
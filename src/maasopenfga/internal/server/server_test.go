@@ -0,0 +1,346 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maas.io/core/src/maasopenfga/internal/migrations"
+	"maas.io/core/src/maasopenfga/internal/model"
+	"maas.io/core/src/maasopenfga/internal/server"
+	"maas.io/core/src/maasopenfga/internal/server/servertest"
+)
+
+func TestServer(t *testing.T) {
+	srv := servertest.Start(t)
+
+	testcases := map[string]struct {
+		path     string
+		header   string
+		wantBody string
+	}{
+		"list stores": {
+			path:     "/stores",
+			wantBody: `"name":"maas"`,
+		},
+		"store name in path": {
+			path:     "/stores/maas/authorization-models",
+			wantBody: `"authorization_models":[`,
+		},
+		"store name in header": {
+			path:     "/stores/ignored/authorization-models",
+			header:   "maas",
+			wantBody: `"authorization_models":[`,
+		},
+		"access": {
+			path:     "/stores/maas/access?object=pool:0&relation=can_deploy_machines",
+			wantBody: `"relations":{"can_deploy_machines":[`,
+		},
+		"metrics": {
+			path:     "/metrics",
+			wantBody: "maas_openfga_",
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, srv.URL+tc.path, nil)
+			require.NoError(t, err)
+
+			if tc.header != "" {
+				req.Header.Set(server.StoreHeader, tc.header)
+			}
+
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+
+			defer resp.Body.Close()
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			assert.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+			assert.Contains(t, string(body), tc.wantBody)
+		})
+	}
+}
+
+func TestServerShutdown(t *testing.T) {
+	srv := servertest.Start(t)
+
+	require.NoError(t, srv.Shutdown(context.Background()))
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, srv.URL+"/stores", nil)
+	require.NoError(t, err)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err == nil {
+		resp.Body.Close()
+	}
+
+	assert.Error(t, err)
+}
+
+func TestServerReadOnly(t *testing.T) {
+	datastore := memory.New()
+	t.Cleanup(datastore.Close)
+
+	srv := servertest.Serve(t, server.Config{Datastore: datastore, ReadOnly: true})
+
+	testcases := map[string]struct {
+		method     string
+		path       string
+		body       string
+		wantStatus int
+	}{
+		"list stores": {
+			method:     http.MethodGet,
+			path:       "/stores",
+			wantStatus: http.StatusOK,
+		},
+		"create store": {
+			method:     http.MethodPost,
+			path:       "/stores",
+			body:       `{"name":"other"}`,
+			wantStatus: http.StatusForbidden,
+		},
+		"write tuples": {
+			method:     http.MethodPost,
+			path:       "/stores/maas/write",
+			body:       `{"writes":{"tuple_keys":[{"user":"user:1","relation":"member","object":"group:1"}]}}`,
+			wantStatus: http.StatusForbidden,
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			req, err := http.NewRequestWithContext(context.Background(), tc.method, srv.URL+tc.path,
+				strings.NewReader(tc.body))
+			require.NoError(t, err)
+
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+
+			defer resp.Body.Close()
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.wantStatus, resp.StatusCode, string(body))
+		})
+	}
+}
+
+func TestServerReadOnlyListeners(t *testing.T) {
+	datastore := memory.New()
+	t.Cleanup(datastore.Close)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	readOnlyLis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	srv, err := server.New(server.Config{
+		Datastore:         datastore,
+		Listeners:         []net.Listener{lis},
+		ReadOnlyListeners: []net.Listener{readOnlyLis},
+	})
+	require.NoError(t, err)
+
+	done := make(chan error, 1)
+
+	go func() {
+		done <- srv.Run(context.Background())
+	}()
+
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		assert.NoError(t, srv.Shutdown(ctx))
+		assert.NoError(t, <-done)
+	})
+
+	testcases := map[string]struct {
+		lis        net.Listener
+		wantStatus int
+	}{
+		"read-write": {
+			lis:        lis,
+			wantStatus: http.StatusOK,
+		},
+		"read-only": {
+			lis:        readOnlyLis,
+			wantStatus: http.StatusForbidden,
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			req, err := http.NewRequestWithContext(context.Background(), http.MethodPost,
+				"http://"+tc.lis.Addr().String()+"/stores", strings.NewReader(`{"name":"`+name+`"}`))
+			require.NoError(t, err)
+
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+
+			defer resp.Body.Close()
+
+			assert.Equal(t, tc.wantStatus, resp.StatusCode)
+		})
+	}
+}
+
+// memoryStore returns a datastore holding the MAAS store, with the latest
+// model and a machine user:1 can view through a group and its pool.
+func memoryStore(t *testing.T) storage.OpenFGADatastore {
+	t.Helper()
+
+	ctx := context.Background()
+
+	datastore := memory.New()
+	t.Cleanup(datastore.Close)
+
+	_, err := datastore.CreateStore(ctx, &openfgav1.Store{Id: migrations.StoreID, Name: "maas"})
+	require.NoError(t, err)
+
+	versions := model.Versions()
+
+	m, err := model.Load(versions[len(versions)-1])
+	require.NoError(t, err)
+
+	m.Id = ulid.Make().String()
+	require.NoError(t, datastore.WriteAuthorizationModel(ctx, migrations.StoreID, m))
+
+	require.NoError(t, datastore.Write(ctx, migrations.StoreID, nil, storage.Writes{
+		{User: "user:1", Relation: "member", Object: "group:1"},
+		{User: "group:1#member", Relation: "can_view_machines", Object: "maas:0"},
+		{User: "maas:0", Relation: "parent", Object: "pool:1"},
+		{User: "pool:1", Relation: "pool", Object: "machine:1"},
+	}))
+
+	return datastore
+}
+
+func check(t *testing.T, srv *servertest.Server) (int, map[string]any) {
+	t.Helper()
+
+	body := `{"tuple_key":{"user":"user:1","relation":"can_view","object":"machine:1"}}`
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost,
+		srv.URL+"/stores/"+migrations.StoreID+"/check", strings.NewReader(body))
+	require.NoError(t, err)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	var payload map[string]any
+	require.NoError(t, json.Unmarshal(data, &payload), string(data))
+
+	return resp.StatusCode, payload
+}
+
+func TestServerResolutionLimits(t *testing.T) {
+	testcases := map[string]struct {
+		cfg         server.Config
+		wantPayload map[string]any
+		wantType    string
+		wantStatus  int
+	}{
+		"defaults": {
+			wantStatus:  http.StatusOK,
+			wantPayload: map[string]any{"allowed": true},
+		},
+		"depth exceeded": {
+			cfg:        server.Config{MaxResolutionDepth: 2},
+			wantStatus: http.StatusUnprocessableEntity,
+			wantPayload: map[string]any{
+				"kind": "Error",
+				"code": float64(http.StatusUnprocessableEntity),
+			},
+			wantType: server.ResolutionLimitExceeded,
+		},
+		"throttled but resolved": {
+			cfg:         server.Config{DispatchThrottlingThreshold: 1},
+			wantStatus:  http.StatusOK,
+			wantPayload: map[string]any{"allowed": true},
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			tc.cfg.Datastore = memoryStore(t)
+
+			status, payload := check(t, servertest.Serve(t, tc.cfg))
+
+			assert.Equal(t, tc.wantStatus, status, payload)
+
+			for key, want := range tc.wantPayload {
+				assert.Equal(t, want, payload[key], key)
+			}
+
+			if tc.wantType != "" {
+				details, _ := payload["details"].([]any)
+				require.Len(t, details, 1, payload)
+				assert.Equal(t, tc.wantType, details[0].(map[string]any)["type"])
+			}
+		})
+	}
+}
+
+func TestServerRequestID(t *testing.T) {
+	datastore := memory.New()
+	t.Cleanup(datastore.Close)
+
+	srv := servertest.Serve(t, server.Config{Datastore: datastore})
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet,
+		srv.URL+"/stores/unknown/access?object=machine:1", nil)
+	require.NoError(t, err)
+	req.Header.Set(server.RequestIDHeader, "regiond-1234")
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.Equal(t, "regiond-1234", resp.Header.Get(server.RequestIDHeader))
+
+	var payload map[string]any
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&payload))
+	assert.Equal(t, "Error", payload["kind"])
+	assert.Equal(t, float64(http.StatusNotFound), payload["code"])
+}
@@ -0,0 +1,127 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package servertest runs the maas-openfga server in-process, so tests can
+// exercise the HTTP API without building and executing the binary.
+package servertest
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/openfga/openfga/pkg/logger"
+	"maas.io/core/src/maasopenfga/internal/migrator"
+	"maas.io/core/src/maasopenfga/internal/server"
+)
+
+// DSNEnv names the environment variable holding the URL of the database
+// to run the server against. It must hold the MAAS schema, e.g. a copy of
+// the maas_test template database of the Python tests.
+const DSNEnv = "MAAS_OPENFGA_TEST_DSN"
+
+const (
+	migrationTimeout = 5 * time.Minute
+	shutdownTimeout  = 10 * time.Second
+)
+
+// Server is a server started by Start.
+type Server struct {
+	*server.Server
+	// URL is the base URL of the HTTP API.
+	URL string
+}
+
+// Start applies the migrations to the database named by DSNEnv and serves
+// the API on a loopback port until the test ends. The test is skipped when
+// DSNEnv is not set.
+func Start(t testing.TB) *Server {
+	t.Helper()
+
+	appDSN := os.Getenv(DSNEnv)
+	if appDSN == "" {
+		t.Skipf("%s is not set", DSNEnv)
+	}
+
+	dsn, err := withSearchPath(appDSN, "openfga")
+	if err != nil {
+		t.Fatalf("invalid %s: %v", DSNEnv, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), migrationTimeout)
+	defer cancel()
+
+	if err := migrator.Up(ctx, dsn, appDSN, logger.NewNoopLogger()); err != nil {
+		t.Fatalf("failed to apply migrations: %v", err)
+	}
+
+	return Serve(t, server.Config{DSN: dsn})
+}
+
+// Serve serves the API described by cfg on a loopback port until the test
+// ends, e.g. with an in-memory Datastore. cfg.Listeners is overridden.
+func Serve(t testing.TB, cfg server.Config) *Server {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+
+	cfg.Listeners = []net.Listener{lis}
+
+	srv, err := server.New(cfg)
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+
+	done := make(chan error, 1)
+
+	go func() {
+		done <- srv.Run(context.Background())
+	}()
+
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+
+		if err := srv.Shutdown(ctx); err != nil {
+			t.Errorf("failed to shut the server down: %v", err)
+		}
+
+		if err := <-done; err != nil {
+			t.Errorf("server failure: %v", err)
+		}
+	})
+
+	return &Server{Server: srv, URL: "http://" + lis.Addr().String()}
+}
+
+// withSearchPath returns the URL dsn with search_path set to schema.
+func withSearchPath(dsn, schema string) (string, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return "", err
+	}
+
+	query := u.Query()
+	query.Set("search_path", schema)
+	u.RawQuery = query.Encode()
+
+	return u.String(), nil
+}
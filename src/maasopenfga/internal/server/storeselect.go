@@ -0,0 +1,91 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package server
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	maaserrors "maas.io/core/src/maasgocommon/errors"
+)
+
+// StoreHeader selects the store of a request by name or ID, overriding the
+// one in the path.
+const StoreHeader = "MAAS-Store"
+
+const storesPrefix = "/stores/"
+
+// storeResolver is implemented by *stores.Resolver.
+type storeResolver interface {
+	Resolve(ctx context.Context, name string) (string, error)
+}
+
+// withStoreSelection lets clients address stores by name, in the path or
+// with the store header, by rewriting the path to use the store ID before
+// it reaches the OpenFGA API.
+func withStoreSelection(mux *runtime.ServeMux, resolver storeResolver, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rest, ok := strings.CutPrefix(r.URL.Path, storesPrefix)
+		if !ok || rest == "" {
+			// Not addressed to a store, e.g. listing stores or metrics.
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		store, path, hasPath := strings.Cut(rest, "/")
+		if header := r.Header.Get(StoreHeader); header != "" {
+			store = header
+		}
+
+		id, err := resolver.Resolve(r.Context(), store)
+		if err != nil {
+			runtime.HTTPError(r.Context(), mux, &runtime.JSONPb{}, w, r,
+				status.Error(storeErrorCode(err), err.Error()))
+
+			return
+		}
+
+		rewritten := storesPrefix + id
+		if hasPath {
+			rewritten += "/" + path
+		}
+
+		if rewritten != r.URL.Path {
+			r = r.Clone(r.Context())
+			r.URL.Path = rewritten
+			r.URL.RawPath = ""
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func storeErrorCode(err error) codes.Code {
+	switch maaserrors.KindOf(err) {
+	case maaserrors.NotFound:
+		return codes.NotFound
+	case maaserrors.Invalid:
+		return codes.InvalidArgument
+	case maaserrors.Unavailable:
+		return codes.Unavailable
+	default:
+		return codes.Internal
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/stretchr/testify/assert"
+	maaserrors "maas.io/core/src/maasgocommon/errors"
+)
+
+const tenantStoreID = "01JZ5V0W8Y3K6N3M2Q4R5S6T7V"
+
+type fakeResolver map[string]string
+
+func (f fakeResolver) Resolve(_ context.Context, name string) (string, error) {
+	if id, ok := f[name]; ok {
+		return id, nil
+	}
+
+	return "", maaserrors.Errorf(maaserrors.NotFound, "store %q not found", name)
+}
+
+func TestWithStoreSelection(t *testing.T) {
+	testcases := map[string]struct {
+		path       string
+		header     string
+		wantStatus int
+		wantPath   string
+	}{
+		"name in path": {
+			path:       "/stores/tenant/check",
+			wantStatus: http.StatusOK,
+			wantPath:   "/stores/" + tenantStoreID + "/check",
+		},
+		"ID in path": {
+			path:       "/stores/" + tenantStoreID + "/check",
+			wantStatus: http.StatusOK,
+			wantPath:   "/stores/" + tenantStoreID + "/check",
+		},
+		"header overrides path": {
+			path:       "/stores/maas/check",
+			header:     "tenant",
+			wantStatus: http.StatusOK,
+			wantPath:   "/stores/" + tenantStoreID + "/check",
+		},
+		"store only": {
+			path:       "/stores/tenant",
+			wantStatus: http.StatusOK,
+			wantPath:   "/stores/" + tenantStoreID,
+		},
+		"not a store": {
+			path:       "/metrics",
+			header:     "tenant",
+			wantStatus: http.StatusOK,
+			wantPath:   "/metrics",
+		},
+		"list stores": {
+			path:       "/stores",
+			wantStatus: http.StatusOK,
+			wantPath:   "/stores",
+		},
+		"unknown store": {
+			path:       "/stores/other/check",
+			wantStatus: http.StatusNotFound,
+		},
+		"unknown store in header": {
+			path:       "/stores/tenant/check",
+			header:     "other",
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	resolver := fakeResolver{
+		"maas":        "00000000000000000000000000",
+		"tenant":      tenantStoreID,
+		tenantStoreID: tenantStoreID,
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			var gotPath string
+
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotPath = r.URL.Path
+			})
+
+			req := httptest.NewRequest(http.MethodPost, tc.path, nil)
+			if tc.header != "" {
+				req.Header.Set(StoreHeader, tc.header)
+			}
+
+			rec := httptest.NewRecorder()
+
+			withStoreSelection(runtime.NewServeMux(), resolver, next).ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.wantStatus, rec.Code)
+			assert.Equal(t, tc.wantPath, gotPath)
+		})
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package stores
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/oklog/ulid/v2"
+	"maas.io/core/src/maasgocommon/clock"
+	maaserrors "maas.io/core/src/maasgocommon/errors"
+	"maas.io/core/src/maasopenfga/internal/migrations"
+)
+
+const defaultCacheTTL = time.Minute
+
+// Resolver maps store names to IDs. Resolved names are cached, since every
+// API request naming a store is resolved.
+type Resolver struct {
+	lookup func(ctx context.Context, name string) (string, error)
+	clock  clock.Clock
+	ttl    time.Duration
+
+	mu    sync.Mutex
+	cache map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	id        string
+	expiresAt time.Time
+}
+
+// ResolverOption allows to set additional Resolver options
+type ResolverOption func(*Resolver)
+
+// WithCacheTTL sets how long resolved names are cached (default: 1m)
+func WithCacheTTL(ttl time.Duration) ResolverOption {
+	return func(r *Resolver) {
+		r.ttl = ttl
+	}
+}
+
+// WithClock sets the clock used to expire cached names (default: the
+// system clock)
+func WithClock(clk clock.Clock) ResolverOption {
+	return func(r *Resolver) {
+		r.clock = clk
+	}
+}
+
+// NewResolver returns a Resolver looking tenant stores up in db.
+func NewResolver(db *sql.DB, options ...ResolverOption) *Resolver {
+	return newResolver(func(ctx context.Context, name string) (string, error) {
+		stmt, args, err := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+			Select("store").
+			From(migrations.TenantStoresTable).
+			Where(sq.Eq{"name": name}).
+			ToSql()
+		if err != nil {
+			return "", err
+		}
+
+		var id string
+
+		err = db.QueryRowContext(ctx, stmt, args...).Scan(&id)
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", maaserrors.Errorf(maaserrors.NotFound, "store %q not found", name)
+		}
+
+		return id, err
+	}, options...)
+}
+
+// NewDefaultResolver returns a Resolver only knowing the MAAS store, for
+// servers without tenant stores (e.g. read-only mirrors).
+func NewDefaultResolver(options ...ResolverOption) *Resolver {
+	return newResolver(func(_ context.Context, name string) (string, error) {
+		return "", maaserrors.Errorf(maaserrors.NotFound, "store %q not found", name)
+	}, options...)
+}
+
+func newResolver(lookup func(ctx context.Context, name string) (string, error),
+	options ...ResolverOption) *Resolver {
+	r := &Resolver{
+		lookup: lookup,
+		clock:  clock.New(),
+		ttl:    defaultCacheTTL,
+		cache:  make(map[string]cacheEntry),
+	}
+
+	for _, opt := range options {
+		opt(r)
+	}
+
+	return r
+}
+
+// Resolve returns the ID of the store named name. Store IDs are returned
+// as they are, so that clients may use either.
+func (r *Resolver) Resolve(ctx context.Context, name string) (string, error) {
+	if _, err := ulid.ParseStrict(name); err == nil {
+		return name, nil
+	}
+
+	if name == DefaultName {
+		return migrations.StoreID, nil
+	}
+
+	if !namePattern.MatchString(name) {
+		return "", maaserrors.Errorf(maaserrors.Invalid, "invalid store %q", name)
+	}
+
+	now := r.clock.Now()
+
+	r.mu.Lock()
+	entry, ok := r.cache[name]
+	r.mu.Unlock()
+
+	if ok && now.Before(entry.expiresAt) {
+		return entry.id, nil
+	}
+
+	id, err := r.lookup(ctx, name)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve store: %w", err)
+	}
+
+	r.mu.Lock()
+	r.cache[name] = cacheEntry{id: id, expiresAt: now.Add(r.ttl)}
+	r.mu.Unlock()
+
+	return id, nil
+}
@@ -0,0 +1,232 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package stores provisions the stores holding the authorization data of
+// tenants, next to the MAAS store, and keeps their model up to date.
+//
+// Every store is known by a name, which clients may use in place of its ID.
+// The MAAS store is always named DefaultName.
+package stores
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"regexp"
+	"slices"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/oklog/ulid/v2"
+	maaserrors "maas.io/core/src/maasgocommon/errors"
+	"maas.io/core/src/maasopenfga/internal/migrations"
+	authzmodel "maas.io/core/src/maasopenfga/internal/model"
+	"maas.io/core/src/maasopenfga/internal/modelupgrade"
+)
+
+// DefaultName is the name of the MAAS store.
+const DefaultName = "maas"
+
+var namePattern = regexp.MustCompile(`^[a-z][a-z0-9-]{0,62}$`)
+
+// Store is a store and the version of the MAAS model installed in it.
+type Store struct {
+	ID           string
+	Name         string
+	ModelVersion string
+	CreatedAt    time.Time
+}
+
+// queryer is implemented by *sql.DB and *sql.Tx.
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// ValidateName checks that name may be used for a tenant store: lowercase
+// letters, digits and dashes, starting with a letter, at most 63 characters.
+// Store IDs are ULIDs, which start with a digit, so names never look like
+// IDs.
+func ValidateName(name string) error {
+	if !namePattern.MatchString(name) {
+		return maaserrors.Errorf(maaserrors.Invalid, "invalid store name %q: expected lowercase "+
+			"letters, digits and dashes, starting with a letter (at most 63 characters)", name)
+	}
+
+	if name == DefaultName {
+		return maaserrors.Errorf(maaserrors.Conflict, "store name %q is reserved for the MAAS store", name)
+	}
+
+	return nil
+}
+
+// Create provisions a tenant store named name with the latest MAAS model.
+func Create(ctx context.Context, tx *sql.Tx, name string) (*Store, error) {
+	if err := ValidateName(name); err != nil {
+		return nil, err
+	}
+
+	builder := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	store := &Store{
+		ID:           ulid.Make().String(),
+		Name:         name,
+		ModelVersion: latestVersion(),
+	}
+
+	stmt, args, err := builder.
+		Insert("openfga.store").
+		Columns("id", "name", "created_at", "updated_at").
+		Values(store.ID, name, sq.Expr("NOW()"), sq.Expr("NOW()")).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.ExecContext(ctx, stmt, args...); err != nil {
+		return nil, fmt.Errorf("failed to create store: %w", err)
+	}
+
+	if err := installModel(ctx, tx, store.ID, store.ModelVersion); err != nil {
+		return nil, err
+	}
+
+	stmt, args, err = builder.
+		Insert(migrations.TenantStoresTable).
+		Columns("store", "name", "model_version", "created_at", "updated_at").
+		Values(store.ID, name, store.ModelVersion, sq.Expr("NOW()"), sq.Expr("NOW()")).
+		Suffix("RETURNING created_at").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.QueryRowContext(ctx, stmt, args...).Scan(&store.CreatedAt)
+	if maaserrors.KindOf(err) == maaserrors.Conflict {
+		return nil, maaserrors.Errorf(maaserrors.Conflict, "store %q already exists", name)
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to record store: %w", err)
+	}
+
+	return store, nil
+}
+
+// List returns the tenant stores, by name.
+func List(ctx context.Context, q queryer) ([]Store, error) {
+	stmt, args, err := sq.Select("store", "name", "model_version", "created_at").
+		From(migrations.TenantStoresTable).
+		OrderBy("name").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := q.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stores: %w", err)
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("failed to close rows: %v", err)
+		}
+	}()
+
+	var stores []Store
+
+	for rows.Next() {
+		var s Store
+		if err := rows.Scan(&s.ID, &s.Name, &s.ModelVersion, &s.CreatedAt); err != nil {
+			return nil, err
+		}
+
+		stores = append(stores, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list stores: %w", err)
+	}
+
+	return stores, nil
+}
+
+// Upgrade installs the latest MAAS model in the tenant stores still using
+// an older one, and returns them. Tuples are translated by the model
+// upgrades of the versions in between.
+func Upgrade(ctx context.Context, tx *sql.Tx) ([]Store, error) {
+	stores, err := List(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	latest := latestVersion()
+	versions := authzmodel.Versions()
+
+	var upgraded []Store
+
+	for _, s := range stores {
+		current := slices.Index(versions, s.ModelVersion)
+		if current >= slices.Index(versions, latest) {
+			continue
+		}
+
+		for _, upgrade := range migrations.ModelUpgrades() {
+			if slices.Index(versions, upgrade.Version) <= current {
+				continue
+			}
+
+			if err := upgrade.Mapping.Apply(ctx, tx, s.ID); err != nil {
+				return nil, fmt.Errorf("failed to upgrade store %q: %w", s.Name, err)
+			}
+		}
+
+		if err := installModel(ctx, tx, s.ID, latest); err != nil {
+			return nil, fmt.Errorf("failed to upgrade store %q: %w", s.Name, err)
+		}
+
+		stmt, args, err := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+			Update(migrations.TenantStoresTable).
+			Set("model_version", latest).
+			Set("updated_at", sq.Expr("NOW()")).
+			Where(sq.Eq{"store": s.ID}).
+			ToSql()
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := tx.ExecContext(ctx, stmt, args...); err != nil {
+			return nil, fmt.Errorf("failed to upgrade store %q: %w", s.Name, err)
+		}
+
+		s.ModelVersion = latest
+		upgraded = append(upgraded, s)
+	}
+
+	return upgraded, nil
+}
+
+// installModel installs the given model version in store. Model IDs are
+// ULIDs, so the new model is the latest one for OpenFGA.
+func installModel(ctx context.Context, tx *sql.Tx, store, version string) error {
+	if err := modelupgrade.Install(ctx, tx, store, version, ulid.Make().String()); err != nil {
+		return fmt.Errorf("failed to install authorization model %s: %w", version, err)
+	}
+
+	return nil
+}
+
+func latestVersion() string {
+	versions := authzmodel.Versions()
+	return versions[len(versions)-1]
+}
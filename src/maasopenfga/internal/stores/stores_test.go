@@ -0,0 +1,126 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package stores
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maas.io/core/src/maasgocommon/clock"
+	maaserrors "maas.io/core/src/maasgocommon/errors"
+	"maas.io/core/src/maasopenfga/internal/migrations"
+)
+
+func TestValidateName(t *testing.T) {
+	testcases := map[string]struct {
+		name     string
+		wantKind maaserrors.Kind
+		wantErr  bool
+	}{
+		"valid":         {name: "tenant-1"},
+		"longest":       {name: "t" + strings.Repeat("a", 62)},
+		"too long":      {name: "t" + strings.Repeat("a", 63), wantErr: true, wantKind: maaserrors.Invalid},
+		"empty":         {name: "", wantErr: true, wantKind: maaserrors.Invalid},
+		"uppercase":     {name: "Tenant", wantErr: true, wantKind: maaserrors.Invalid},
+		"leading digit": {name: "1tenant", wantErr: true, wantKind: maaserrors.Invalid},
+		"slash":         {name: "tenant/1", wantErr: true, wantKind: maaserrors.Invalid},
+		"reserved":      {name: DefaultName, wantErr: true, wantKind: maaserrors.Conflict},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			err := ValidateName(tc.name)
+			if !tc.wantErr {
+				assert.NoError(t, err)
+				return
+			}
+
+			require.Error(t, err)
+			assert.Equal(t, tc.wantKind, maaserrors.KindOf(err))
+		})
+	}
+}
+
+func TestResolver(t *testing.T) {
+	const tenantID = "01JZ5V0W8Y3K6N3M2Q4R5S6T7V"
+
+	var lookups int
+
+	clk := clock.NewFake(time.Unix(0, 0))
+	r := newResolver(func(ctx context.Context, name string) (string, error) {
+		lookups++
+
+		if name != "tenant" {
+			return "", maaserrors.Errorf(maaserrors.NotFound, "store %q not found", name)
+		}
+
+		return tenantID, nil
+	}, WithClock(clk), WithCacheTTL(time.Minute))
+
+	ctx := context.Background()
+
+	id, err := r.Resolve(ctx, DefaultName)
+	require.NoError(t, err)
+	assert.Equal(t, migrations.StoreID, id)
+
+	id, err = r.Resolve(ctx, tenantID)
+	require.NoError(t, err)
+	assert.Equal(t, tenantID, id)
+	assert.Zero(t, lookups, "IDs and the MAAS store are resolved without lookup")
+
+	for range 2 {
+		id, err = r.Resolve(ctx, "tenant")
+		require.NoError(t, err)
+		assert.Equal(t, tenantID, id)
+	}
+
+	assert.Equal(t, 1, lookups, "names are cached")
+
+	clk.Advance(time.Minute)
+
+	_, err = r.Resolve(ctx, "tenant")
+	require.NoError(t, err)
+	assert.Equal(t, 2, lookups, "cached names expire")
+
+	_, err = r.Resolve(ctx, "other")
+	assert.Equal(t, maaserrors.NotFound, maaserrors.KindOf(err))
+
+	_, err = r.Resolve(ctx, "../other")
+	assert.Equal(t, maaserrors.Invalid, maaserrors.KindOf(err))
+	assert.Equal(t, 3, lookups)
+}
+
+func TestDefaultResolver(t *testing.T) {
+	const tenantID = "01JZ5V0W8Y3K6N3M2Q4R5S6T7V"
+
+	r := NewDefaultResolver()
+	ctx := context.Background()
+
+	id, err := r.Resolve(ctx, DefaultName)
+	require.NoError(t, err)
+	assert.Equal(t, migrations.StoreID, id)
+
+	id, err = r.Resolve(ctx, tenantID)
+	require.NoError(t, err)
+	assert.Equal(t, tenantID, id)
+
+	_, err = r.Resolve(ctx, "tenant")
+	assert.Equal(t, maaserrors.NotFound, maaserrors.KindOf(err))
+}
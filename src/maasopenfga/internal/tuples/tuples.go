@@ -0,0 +1,260 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package tuples writes tuples straight to the OpenFGA datastore tables,
+// recording every change in the changelog as OpenFGA itself does, so that
+// ReadChanges and the change stream see them.
+package tuples
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/oklog/ulid/v2"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/openfga/openfga/pkg/storage/sqlcommon"
+	tupleUtils "github.com/openfga/openfga/pkg/tuple"
+)
+
+func psql() sq.StatementBuilderType {
+	return sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+}
+
+// Write writes key to the store, replacing the existing tuple if any.
+func Write(ctx context.Context, tx *sql.Tx, storeID string, key *openfgav1.TupleKey) error {
+	objectType, objectID := tupleUtils.SplitObject(key.GetObject())
+
+	conditionName, conditionContext, err := sqlcommon.MarshalRelationshipCondition(key.GetCondition())
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+
+	stmt, args, err := psql().
+		Insert("openfga.tuple").
+		Columns("store", "object_type", "object_id", "relation", "_user", "user_type",
+			"condition_name", "condition_context", "ulid", "inserted_at").
+		Values(storeID, objectType, objectID, key.GetRelation(), key.GetUser(),
+			tupleUtils.GetUserTypeFromUser(key.GetUser()), conditionName, conditionContext,
+			ulid.Make().String(), now).
+		Suffix("ON CONFLICT (store, object_type, object_id, relation, _user) DO UPDATE SET " +
+			"user_type = EXCLUDED.user_type, condition_name = EXCLUDED.condition_name, " +
+			"condition_context = EXCLUDED.condition_context, ulid = EXCLUDED.ulid, " +
+			"inserted_at = EXCLUDED.inserted_at").
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, stmt, args...); err != nil {
+		return fmt.Errorf("failed to write tuple: %w", err)
+	}
+
+	return recordChange(ctx, tx, storeID, key, openfgav1.TupleOperation_TUPLE_OPERATION_WRITE, now)
+}
+
+// WriteBatch writes keys to the store with a single statement and returns
+// how many were added or changed. Tuples already stored as is are left
+// untouched and not recorded in the changelog, so writing the same batch
+// twice is a no-op.
+func WriteBatch(ctx context.Context, tx *sql.Tx, storeID string, keys []*openfgav1.TupleKey) (int, error) {
+	if len(keys) == 0 {
+		return 0, nil
+	}
+
+	now := time.Now().UTC()
+	insert := psql().
+		Insert("openfga.tuple").
+		Columns("store", "object_type", "object_id", "relation", "_user", "user_type",
+			"condition_name", "condition_context", "ulid", "inserted_at")
+
+	// A statement can't update the same row twice.
+	byID := make(map[string]*openfgav1.TupleKey, len(keys))
+
+	for _, key := range keys {
+		id := tupleUtils.TupleKeyToString(key)
+		if _, ok := byID[id]; ok {
+			continue
+		}
+
+		byID[id] = key
+
+		objectType, objectID := tupleUtils.SplitObject(key.GetObject())
+
+		conditionName, conditionContext, err := sqlcommon.MarshalRelationshipCondition(key.GetCondition())
+		if err != nil {
+			return 0, err
+		}
+
+		insert = insert.Values(storeID, objectType, objectID, key.GetRelation(), key.GetUser(),
+			tupleUtils.GetUserTypeFromUser(key.GetUser()), conditionName, conditionContext,
+			ulid.Make().String(), now)
+	}
+
+	stmt, args, err := insert.
+		Suffix("ON CONFLICT (store, object_type, object_id, relation, _user) DO UPDATE SET " +
+			"condition_name = EXCLUDED.condition_name, " +
+			"condition_context = EXCLUDED.condition_context, ulid = EXCLUDED.ulid, " +
+			"inserted_at = EXCLUDED.inserted_at " +
+			"WHERE tuple.condition_name IS DISTINCT FROM EXCLUDED.condition_name " +
+			"OR tuple.condition_context IS DISTINCT FROM EXCLUDED.condition_context " +
+			"RETURNING object_type, object_id, relation, _user").
+		ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := tx.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to write tuples: %w", err)
+	}
+
+	var written []*openfgav1.TupleKey
+
+	for rows.Next() {
+		var objectType, objectID, relation, user string
+		if err := rows.Scan(&objectType, &objectID, &relation, &user); err != nil {
+			return 0, errors.Join(err, rows.Close())
+		}
+
+		id := tupleUtils.TupleKeyToString(tupleUtils.NewTupleKey(
+			tupleUtils.BuildObject(objectType, objectID), relation, user))
+		written = append(written, byID[id])
+	}
+
+	if err := errors.Join(rows.Err(), rows.Close()); err != nil {
+		return 0, fmt.Errorf("failed to write tuples: %w", err)
+	}
+
+	for _, key := range written {
+		err := recordChange(ctx, tx, storeID, key, openfgav1.TupleOperation_TUPLE_OPERATION_WRITE, now)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return len(written), nil
+}
+
+// Delete deletes key from the store and reports whether it existed.
+func Delete(ctx context.Context, tx *sql.Tx, storeID string, key *openfgav1.TupleKey) (bool, error) {
+	objectType, objectID := tupleUtils.SplitObject(key.GetObject())
+
+	stmt, args, err := psql().
+		Delete("openfga.tuple").
+		Where(sq.Eq{
+			"store":       storeID,
+			"object_type": objectType,
+			"object_id":   objectID,
+			"relation":    key.GetRelation(),
+			"_user":       key.GetUser(),
+		}).
+		ToSql()
+	if err != nil {
+		return false, err
+	}
+
+	res, err := tx.ExecContext(ctx, stmt, args...)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete tuple: %w", err)
+	}
+
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return false, err
+	}
+
+	err = recordChange(ctx, tx, storeID, key, openfgav1.TupleOperation_TUPLE_OPERATION_DELETE,
+		time.Now().UTC())
+
+	return err == nil, err
+}
+
+// DeleteAll deletes every tuple of the store.
+func DeleteAll(ctx context.Context, tx *sql.Tx, storeID string) error {
+	stmt, args, err := psql().
+		Delete("openfga.tuple").
+		Where(sq.Eq{"store": storeID}).
+		Suffix("RETURNING object_type, object_id, relation, _user").
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	rows, err := tx.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return fmt.Errorf("failed to delete tuples: %w", err)
+	}
+
+	var keys []*openfgav1.TupleKey
+
+	for rows.Next() {
+		var objectType, objectID, relation, user string
+		if err := rows.Scan(&objectType, &objectID, &relation, &user); err != nil {
+			return errors.Join(err, rows.Close())
+		}
+
+		keys = append(keys, tupleUtils.NewTupleKey(
+			tupleUtils.BuildObject(objectType, objectID), relation, user))
+	}
+
+	if err := errors.Join(rows.Err(), rows.Close()); err != nil {
+		return fmt.Errorf("failed to delete tuples: %w", err)
+	}
+
+	now := time.Now().UTC()
+
+	for _, key := range keys {
+		err := recordChange(ctx, tx, storeID, key, openfgav1.TupleOperation_TUPLE_OPERATION_DELETE, now)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// recordChange adds a changelog entry, so that clients watching changes
+// of the store are notified.
+func recordChange(ctx context.Context, tx *sql.Tx, storeID string, key *openfgav1.TupleKey,
+	operation openfgav1.TupleOperation, at time.Time) error {
+	objectType, objectID := tupleUtils.SplitObject(key.GetObject())
+
+	conditionName, conditionContext, err := sqlcommon.MarshalRelationshipCondition(key.GetCondition())
+	if err != nil {
+		return err
+	}
+
+	stmt, args, err := psql().
+		Insert("openfga.changelog").
+		Columns("store", "object_type", "object_id", "relation", "_user",
+			"condition_name", "condition_context", "operation", "ulid", "inserted_at").
+		Values(storeID, objectType, objectID, key.GetRelation(), key.GetUser(),
+			conditionName, conditionContext, int(operation), ulid.Make().String(), at).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, stmt, args...); err != nil {
+		return fmt.Errorf("failed to record change: %w", err)
+	}
+
+	return nil
+}
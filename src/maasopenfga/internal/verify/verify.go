@@ -0,0 +1,407 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+// Package verify checks the invariants of the OpenFGA datastore that
+// maas-openfga silently depends on, for diagnostics:
+//
+//   - the MAAS store exists, with its fixed ID, and isn't deleted;
+//   - every authorization model is stored in exactly one row, as OpenFGA
+//     no longer reads models split into one row per type;
+//   - every row holds a protobuf that deserializes, and whose ID matches
+//     the authorization_model_id of the row;
+//   - the latest model is valid;
+//   - every tuple references types and relations of the latest model.
+package verify
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	tupleUtils "github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+	"google.golang.org/protobuf/proto"
+)
+
+// Names of the checks.
+const (
+	CheckStore         = "store"
+	CheckModelRows     = "model_rows"
+	CheckModelProtobuf = "model_protobuf"
+	CheckModelID       = "model_id"
+	CheckModelValid    = "model_valid"
+	CheckTuples        = "tuples"
+)
+
+// maxExamples is the number of tuples quoted for every tuple problem.
+const maxExamples = 3
+
+// State is what is checked, as read from the database.
+type State struct {
+	// StoreExists is false if the store row is missing.
+	StoreExists bool
+	// StoreDeleted is true if the store row is soft-deleted.
+	StoreDeleted bool
+	// Models are the authorization model rows of the store.
+	Models []Model
+	// Tuples are the tuples of the store.
+	Tuples []Tuple
+}
+
+// Model is an authorization model row.
+type Model struct {
+	// ID is the authorization_model_id of the row.
+	ID string
+	// Protobuf is the serialized model, nil if the column is NULL.
+	Protobuf []byte
+}
+
+// Tuple is a tuple of the store.
+type Tuple struct {
+	User     string
+	Relation string
+	Object   string
+}
+
+// Check is the outcome of a check.
+type Check struct {
+	Name     string   `json:"name"`
+	Passed   bool     `json:"passed"`
+	Problems []string `json:"problems,omitempty"`
+}
+
+// Report is the outcome of all checks. Checks depending on a failed one
+// still run on what is usable, e.g. tuples are checked against the latest
+// model that deserializes.
+type Report struct {
+	StoreID string `json:"store_id"`
+	// ModelID is the ID of the model tuples were checked against.
+	ModelID string  `json:"model_id,omitempty"`
+	Models  int     `json:"models"`
+	Tuples  int     `json:"tuples"`
+	Passed  bool    `json:"passed"`
+	Checks  []Check `json:"checks"`
+}
+
+// Failed returns the names of the failed checks.
+func (r *Report) Failed() []string {
+	var failed []string
+
+	for _, c := range r.Checks {
+		if !c.Passed {
+			failed = append(failed, c.Name)
+		}
+	}
+
+	return failed
+}
+
+// WriteJSON writes the report as indented JSON.
+func WriteJSON(w io.Writer, report *Report) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(report)
+}
+
+// queryer is implemented by *sql.DB and *sql.Tx.
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// Collect reads the store, its models and its tuples from the openfga
+// schema.
+func Collect(ctx context.Context, db queryer, storeID string) (*State, error) {
+	builder := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+
+	var state State
+
+	stmt, args, err := builder.
+		Select("deleted_at IS NOT NULL").
+		From("openfga.store").
+		Where(sq.Eq{"id": storeID}).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.QueryRowContext(ctx, stmt, args...).Scan(&state.StoreDeleted)
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+	case err != nil:
+		return nil, fmt.Errorf("failed to read store: %w", err)
+	default:
+		state.StoreExists = true
+	}
+
+	stmt, args, err = builder.
+		Select("authorization_model_id", "serialized_protobuf").
+		From("openfga.authorization_model").
+		Where(sq.Eq{"store": storeID}).
+		OrderBy("authorization_model_id").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	err = query(ctx, db, stmt, args, func(rows *sql.Rows) error {
+		var m Model
+
+		if err := rows.Scan(&m.ID, &m.Protobuf); err != nil {
+			return err
+		}
+
+		state.Models = append(state.Models, m)
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read authorization models: %w", err)
+	}
+
+	stmt, args, err = builder.
+		Select("_user", "relation", "object_type", "object_id").
+		From("openfga.tuple").
+		Where(sq.Eq{"store": storeID}).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	err = query(ctx, db, stmt, args, func(rows *sql.Rows) error {
+		var (
+			t                    Tuple
+			objectType, objectID string
+		)
+
+		if err := rows.Scan(&t.User, &t.Relation, &objectType, &objectID); err != nil {
+			return err
+		}
+
+		t.Object = tupleUtils.BuildObject(objectType, objectID)
+		state.Tuples = append(state.Tuples, t)
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tuples: %w", err)
+	}
+
+	return &state, nil
+}
+
+func query(ctx context.Context, db queryer, stmt string, args []any, scan func(*sql.Rows) error) error {
+	rows, err := db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return err
+	}
+
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return errors.Join(err, rows.Close())
+		}
+	}
+
+	return errors.Join(rows.Err(), rows.Close())
+}
+
+// Verify checks the state of the store storeID.
+func Verify(ctx context.Context, storeID string, state *State) *Report {
+	report := &Report{
+		StoreID: storeID,
+		Models:  len(state.Models),
+		Tuples:  len(state.Tuples),
+	}
+
+	store := Check{Name: CheckStore}
+
+	switch {
+	case !state.StoreExists:
+		store.Problems = append(store.Problems, fmt.Sprintf("store %q does not exist", storeID))
+	case state.StoreDeleted:
+		store.Problems = append(store.Problems, fmt.Sprintf("store %q is deleted", storeID))
+	}
+
+	rows := Check{Name: CheckModelRows}
+	protobuf := Check{Name: CheckModelProtobuf}
+	ids := Check{Name: CheckModelID}
+
+	if len(state.Models) == 0 {
+		rows.Problems = append(rows.Problems, "no authorization model")
+	}
+
+	count := map[string]int{}
+
+	var latest *openfgav1.AuthorizationModel
+
+	for _, m := range state.Models {
+		count[m.ID]++
+		if count[m.ID] == 2 {
+			rows.Problems = append(rows.Problems,
+				fmt.Sprintf("authorization model %q is stored in several rows", m.ID))
+		}
+
+		var model openfgav1.AuthorizationModel
+		if err := proto.Unmarshal(m.Protobuf, &model); err != nil || m.Protobuf == nil {
+			protobuf.Problems = append(protobuf.Problems,
+				fmt.Sprintf("authorization model %q can't be deserialized", m.ID))
+
+			continue
+		}
+
+		if model.GetId() != m.ID {
+			ids.Problems = append(ids.Problems,
+				fmt.Sprintf("authorization model %q has ID %q in its protobuf", m.ID, model.GetId()))
+		}
+
+		// OpenFGA serves the model with the greatest ID.
+		if latest == nil || m.ID >= latest.GetId() {
+			latest = &model
+			latest.Id = m.ID
+		}
+	}
+
+	valid := Check{Name: CheckModelValid}
+	tuples := Check{Name: CheckTuples}
+
+	if latest == nil {
+		valid.Problems = append(valid.Problems, "no usable authorization model")
+		tuples.Problems = append(tuples.Problems, "no usable authorization model to check tuples against")
+	} else {
+		report.ModelID = latest.GetId()
+
+		if _, err := typesystem.NewAndValidate(ctx, latest); err != nil {
+			valid.Problems = append(valid.Problems,
+				fmt.Sprintf("authorization model %q is invalid: %v", latest.GetId(), err))
+		}
+
+		tuples.Problems = checkTuples(latest, state.Tuples)
+	}
+
+	report.Checks = []Check{store, rows, protobuf, ids, valid, tuples}
+
+	for i := range report.Checks {
+		report.Checks[i].Passed = len(report.Checks[i].Problems) == 0
+	}
+
+	report.Passed = len(report.Failed()) == 0
+
+	return report
+}
+
+// checkTuples returns a problem for every way tuples don't match the
+// model, with the number of tuples affected and a few examples.
+func checkTuples(model *openfgav1.AuthorizationModel, all []Tuple) []string {
+	types := map[string]*openfgav1.TypeDefinition{}
+	for _, typeDef := range model.GetTypeDefinitions() {
+		types[typeDef.GetType()] = typeDef
+	}
+
+	type finding struct {
+		examples []string
+		count    int
+	}
+
+	findings := map[string]*finding{}
+
+	for _, t := range all {
+		problem := checkTuple(types, t)
+		if problem == "" {
+			continue
+		}
+
+		f, ok := findings[problem]
+		if !ok {
+			f = &finding{}
+			findings[problem] = f
+		}
+
+		f.count++
+		if len(f.examples) < maxExamples {
+			f.examples = append(f.examples, fmt.Sprintf("%s %s %s", t.User, t.Relation, t.Object))
+		}
+	}
+
+	problems := make([]string, 0, len(findings))
+	for problem, f := range findings {
+		problems = append(problems, fmt.Sprintf("%s (tuples: %d, e.g. %s)",
+			problem, f.count, strings.Join(f.examples, ", ")))
+	}
+
+	slices.Sort(problems)
+
+	return problems
+}
+
+// checkTuple returns why the tuple doesn't match the model, or an empty
+// string if it does.
+func checkTuple(types map[string]*openfgav1.TypeDefinition, t Tuple) string {
+	objectType, _ := tupleUtils.SplitObject(t.Object)
+	userObject, userRelation := tupleUtils.SplitObjectRelation(t.User)
+	userType, userID := tupleUtils.SplitObject(userObject)
+
+	typeDef, ok := types[objectType]
+	if !ok {
+		return fmt.Sprintf("unknown object type %q", objectType)
+	}
+
+	if _, ok := typeDef.GetRelations()[t.Relation]; !ok {
+		return fmt.Sprintf("type %q has no relation %q", objectType, t.Relation)
+	}
+
+	userTypeDef, ok := types[userType]
+	if !ok {
+		return fmt.Sprintf("unknown user type %q", userType)
+	}
+
+	if userRelation != "" {
+		if _, ok := userTypeDef.GetRelations()[userRelation]; !ok {
+			return fmt.Sprintf("type %q has no relation %q", userType, userRelation)
+		}
+	}
+
+	wildcard := userID == tupleUtils.Wildcard
+
+	for _, allowed := range typeDef.GetMetadata().GetRelations()[t.Relation].GetDirectlyRelatedUserTypes() {
+		if allowed.GetType() != userType {
+			continue
+		}
+
+		if wildcard && allowed.GetWildcard() != nil {
+			return ""
+		}
+
+		if !wildcard && allowed.GetWildcard() == nil && allowed.GetRelation() == userRelation {
+			return ""
+		}
+	}
+
+	user := userType
+	if wildcard {
+		user += ":*"
+	} else if userRelation != "" {
+		user += "#" + userRelation
+	}
+
+	return fmt.Sprintf("relation %q of type %q can't be granted to %q", t.Relation, objectType, user)
+}
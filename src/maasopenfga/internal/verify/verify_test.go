@@ -0,0 +1,182 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package verify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	authzmodel "maas.io/core/src/maasopenfga/internal/model"
+)
+
+const (
+	storeID = "00000000000000000000000000"
+	modelID = "00000000000000000000000004"
+)
+
+func modelRow(t *testing.T, id string) Model {
+	t.Helper()
+
+	versions := authzmodel.Versions()
+
+	model, err := authzmodel.Load(versions[len(versions)-1])
+	require.NoError(t, err)
+
+	model.Id = id
+
+	data, err := proto.Marshal(model)
+	require.NoError(t, err)
+
+	return Model{ID: id, Protobuf: data}
+}
+
+func healthy(t *testing.T) *State {
+	return &State{
+		StoreExists: true,
+		Models:      []Model{modelRow(t, storeID), modelRow(t, modelID)},
+		Tuples: []Tuple{
+			{User: "user:1", Relation: "member", Object: "group:1"},
+			{User: "group:1#member", Relation: "can_edit_machines", Object: "maas:0"},
+			{User: "maas:0", Relation: "parent", Object: "pool:1"},
+		},
+	}
+}
+
+func problems(report *Report) map[string][]string {
+	out := map[string][]string{}
+
+	for _, c := range report.Checks {
+		if !c.Passed {
+			out[c.Name] = c.Problems
+		}
+	}
+
+	return out
+}
+
+func TestVerify(t *testing.T) {
+	testcases := map[string]struct {
+		state func(*State)
+		want  map[string][]string
+	}{
+		"healthy": {
+			state: func(*State) {},
+			want:  map[string][]string{},
+		},
+		"missing store": {
+			state: func(s *State) { s.StoreExists = false },
+			want: map[string][]string{
+				CheckStore: {`store "00000000000000000000000000" does not exist`},
+			},
+		},
+		"deleted store": {
+			state: func(s *State) { s.StoreDeleted = true },
+			want: map[string][]string{
+				CheckStore: {`store "00000000000000000000000000" is deleted`},
+			},
+		},
+		"model split in rows": {
+			state: func(s *State) { s.Models = append(s.Models, modelRow(t, modelID)) },
+			want: map[string][]string{
+				CheckModelRows: {`authorization model "00000000000000000000000004" is stored in several rows`},
+			},
+		},
+		"corrupt protobuf": {
+			state: func(s *State) { s.Models[0].Protobuf = []byte("corrupt") },
+			want: map[string][]string{
+				CheckModelProtobuf: {`authorization model "00000000000000000000000000" can't be deserialized`},
+			},
+		},
+		"mismatched ID": {
+			state: func(s *State) { s.Models[1].ID = "00000000000000000000000005" },
+			want: map[string][]string{
+				CheckModelID: {`authorization model "00000000000000000000000005" has ID ` +
+					`"00000000000000000000000004" in its protobuf`},
+			},
+		},
+		"no model": {
+			state: func(s *State) { s.Models = nil },
+			want: map[string][]string{
+				CheckModelRows:  {"no authorization model"},
+				CheckModelValid: {"no usable authorization model"},
+				CheckTuples:     {"no usable authorization model to check tuples against"},
+			},
+		},
+		"bad tuples": {
+			state: func(s *State) {
+				s.Tuples = append(s.Tuples,
+					Tuple{User: "user:1", Relation: "member", Object: "team:1"},
+					Tuple{User: "user:1", Relation: "owner", Object: "group:1"},
+					Tuple{User: "user:1", Relation: "can_edit_machines", Object: "maas:0"},
+					Tuple{User: "user:2", Relation: "can_edit_machines", Object: "maas:0"},
+					Tuple{User: "user:*", Relation: "member", Object: "group:1"},
+					Tuple{User: "robot:1", Relation: "member", Object: "group:1"},
+				)
+			},
+			want: map[string][]string{
+				CheckTuples: {
+					`relation "can_edit_machines" of type "maas" can't be granted to "user" (tuples: 2, ` +
+						`e.g. user:1 can_edit_machines maas:0, user:2 can_edit_machines maas:0)`,
+					`relation "member" of type "group" can't be granted to "user:*" (tuples: 1, ` +
+						`e.g. user:* member group:1)`,
+					`type "group" has no relation "owner" (tuples: 1, e.g. user:1 owner group:1)`,
+					`unknown object type "team" (tuples: 1, e.g. user:1 member team:1)`,
+					`unknown user type "robot" (tuples: 1, e.g. robot:1 member group:1)`,
+				},
+			},
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			state := healthy(t)
+			tc.state(state)
+
+			report := Verify(context.Background(), storeID, state)
+
+			assert.Equal(t, tc.want, problems(report))
+			assert.Equal(t, len(tc.want) == 0, report.Passed)
+			assert.Len(t, report.Failed(), len(tc.want))
+		})
+	}
+}
+
+func TestVerifyLatestModel(t *testing.T) {
+	report := Verify(context.Background(), storeID, healthy(t))
+
+	assert.Equal(t, modelID, report.ModelID)
+	assert.Equal(t, 2, report.Models)
+	assert.Equal(t, 3, report.Tuples)
+}
+
+func TestWriteJSON(t *testing.T) {
+	state := healthy(t)
+	state.StoreExists = false
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteJSON(&buf, Verify(context.Background(), storeID, state)))
+
+	var got map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+
+	assert.Equal(t, false, got["passed"])
+	assert.Equal(t, modelID, got["model_id"])
+	assert.Len(t, got["checks"], 6)
+}
@@ -0,0 +1,216 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package gateway routes region HTTP requests to handlers guarded by
+// OpenFGA checks.
+//
+// Every route is registered with the Permission it requires, and a
+// Permission can only be made by Require, or by Public for the few routes
+// that must be reachable without credentials. An endpoint can't be added
+// without deciding who may call it: there is no registration method
+// without a Permission, and the zero Permission is refused.
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	maaserrors "maas.io/core/src/maasgocommon/errors"
+	"maas.io/core/src/maasopenfga/pkg/openfgaclient"
+)
+
+// ErrUnauthenticated is returned by an Authenticator when a request
+// carries no valid credentials.
+var ErrUnauthenticated = errors.New("request is not authenticated")
+
+// Authenticator returns the OpenFGA user making a request, e.g. "user:1".
+type Authenticator func(r *http.Request) (string, error)
+
+// ObjectResolver returns the OpenFGA object a request is about, e.g.
+// "pool:3". An error wrapping a maaserrors kind is answered with the
+// matching status, e.g. 404 for NotFound.
+type ObjectResolver func(r *http.Request) (string, error)
+
+// Object resolves to the same object for every request, e.g. "maas:0" for
+// routes acting on the whole MAAS.
+func Object(object string) ObjectResolver {
+	return func(*http.Request) (string, error) {
+		return object, nil
+	}
+}
+
+// PathObject resolves to the object of the given type whose ID is the
+// wildcard name of the route pattern, e.g. PathObject("pool", "id") for
+// "GET /pools/{id}".
+func PathObject(objectType, name string) ObjectResolver {
+	return func(r *http.Request) (string, error) {
+		id := r.PathValue(name)
+		if id == "" {
+			return "", maaserrors.Errorf(maaserrors.Invalid, "missing %s in path", name)
+		}
+
+		return objectType + ":" + id, nil
+	}
+}
+
+// Permission is the authorization a route requires.
+type Permission struct {
+	object   ObjectResolver
+	relation string
+	public   bool
+}
+
+// Require allows requests from users having relation with the object the
+// request is resolved to.
+func Require(relation string, object ObjectResolver) Permission {
+	if relation == "" || object == nil {
+		panic("gateway: Require needs a relation and an object resolver")
+	}
+
+	return Permission{relation: relation, object: object}
+}
+
+// Public allows every request, authenticated or not. It's meant for the
+// likes of health checks, and makes such routes stand out in reviews.
+func Public() Permission {
+	return Permission{public: true}
+}
+
+func (p Permission) valid() bool {
+	return p.public || (p.relation != "" && p.object != nil)
+}
+
+// Route describes a registered route, e.g. to audit what they require.
+type Route struct {
+	Pattern  string
+	Relation string
+	Public   bool
+}
+
+type userKey struct{}
+
+// User returns the OpenFGA user a request was authenticated as, empty for
+// unauthenticated requests to public routes.
+func User(ctx context.Context) string {
+	user, _ := ctx.Value(userKey{}).(string)
+	return user
+}
+
+// Router is an http.Handler dispatching requests to the handlers of the
+// routes they match, once they passed the route Permission.
+type Router struct {
+	api          openfgaclient.API
+	mux          *http.ServeMux
+	authenticate Authenticator
+	routes       []Route
+	mu           sync.Mutex
+}
+
+// New returns a Router authenticating requests with authenticate and
+// checking them against api.
+func New(api openfgaclient.API, authenticate Authenticator) *Router {
+	return &Router{
+		api:          api,
+		authenticate: authenticate,
+		mux:          http.NewServeMux(),
+	}
+}
+
+// Handle registers h for pattern, in http.ServeMux syntax, guarded by perm.
+// Like http.ServeMux, it panics on invalid or conflicting patterns, and on
+// the zero Permission.
+func (rt *Router) Handle(pattern string, perm Permission, h http.Handler) {
+	if !perm.valid() {
+		panic(fmt.Sprintf("gateway: no permission for route %q", pattern))
+	}
+
+	rt.mux.Handle(pattern, rt.guard(perm, h))
+
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	rt.routes = append(rt.routes, Route{Pattern: pattern, Relation: perm.relation, Public: perm.public})
+}
+
+// HandleFunc registers h for pattern, see Handle.
+func (rt *Router) HandleFunc(pattern string, perm Permission, h http.HandlerFunc) {
+	rt.Handle(pattern, perm, h)
+}
+
+// Routes returns the registered routes, sorted by pattern.
+func (rt *Router) Routes() []Route {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	routes := append([]Route(nil), rt.routes...)
+	slices.SortFunc(routes, func(a, b Route) int { return strings.Compare(a.Pattern, b.Pattern) })
+
+	return routes
+}
+
+// ServeHTTP implements http.Handler.
+func (rt *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rt.mux.ServeHTTP(w, r)
+}
+
+func (rt *Router) guard(perm Permission, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, err := rt.authenticate(r)
+
+		switch {
+		case err == nil:
+			r = r.WithContext(context.WithValue(r.Context(), userKey{}, user))
+		case perm.public && errors.Is(err, ErrUnauthenticated):
+			// Public routes serve anonymous requests too.
+		case errors.Is(err, ErrUnauthenticated):
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		default:
+			http.Error(w, err.Error(), maaserrors.HTTPStatus(err))
+			return
+		}
+
+		if perm.public {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		object, err := perm.object(r)
+		if err != nil {
+			http.Error(w, err.Error(), maaserrors.HTTPStatus(err))
+			return
+		}
+
+		allowed, err := rt.api.Check(r.Context(), openfgaclient.Tuple{
+			User: user, Relation: perm.relation, Object: object,
+		})
+		if err != nil {
+			http.Error(w, "authorization check failed", maaserrors.HTTPStatus(err))
+			return
+		}
+
+		if !allowed {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+
+		h.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,189 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package gateway
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	maaserrors "maas.io/core/src/maasgocommon/errors"
+	"maas.io/core/src/maasopenfga/pkg/openfgaclient"
+)
+
+// headerAuth authenticates requests by their X-User header.
+func headerAuth(r *http.Request) (string, error) {
+	user := r.Header.Get("X-User")
+	if user == "" {
+		return "", ErrUnauthenticated
+	}
+
+	return user, nil
+}
+
+func newRouter(api openfgaclient.API) *Router {
+	rt := New(api, headerAuth)
+
+	rt.HandleFunc("GET /pools/{id}", Require("can_view_machines", PathObject("pool", "id")),
+		func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(User(r.Context())))
+		})
+	rt.HandleFunc("POST /config", Require("can_edit_configurations", Object("maas:0")),
+		func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		})
+	rt.HandleFunc("GET /health", Public(), func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok " + User(r.Context())))
+	})
+
+	return rt
+}
+
+func TestRouter(t *testing.T) {
+	api := openfgaclient.NewFake(
+		openfgaclient.Tuple{User: "user:1", Relation: "can_view_machines", Object: "pool:2"},
+		openfgaclient.Tuple{User: "user:1", Relation: "can_edit_configurations", Object: "maas:0"},
+	)
+	rt := newRouter(api)
+
+	testcases := map[string]struct {
+		method string
+		path   string
+		user   string
+		body   string
+		status int
+	}{
+		"allowed": {
+			method: http.MethodGet, path: "/pools/2", user: "user:1",
+			status: http.StatusOK, body: "user:1",
+		},
+		"denied": {
+			method: http.MethodGet, path: "/pools/3", user: "user:1",
+			status: http.StatusForbidden,
+		},
+		"other user": {
+			method: http.MethodGet, path: "/pools/2", user: "user:2",
+			status: http.StatusForbidden,
+		},
+		"unauthenticated": {
+			method: http.MethodGet, path: "/pools/2",
+			status: http.StatusUnauthorized,
+		},
+		"static object": {
+			method: http.MethodPost, path: "/config", user: "user:1",
+			status: http.StatusNoContent,
+		},
+		"public anonymous": {
+			method: http.MethodGet, path: "/health",
+			status: http.StatusOK, body: "ok ",
+		},
+		"public authenticated": {
+			method: http.MethodGet, path: "/health", user: "user:2",
+			status: http.StatusOK, body: "ok user:2",
+		},
+		"unknown route": {
+			method: http.MethodGet, path: "/machines", user: "user:1",
+			status: http.StatusNotFound,
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.path, nil)
+			if tc.user != "" {
+				req.Header.Set("X-User", tc.user)
+			}
+
+			rec := httptest.NewRecorder()
+			rt.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.status, rec.Code)
+
+			if tc.body != "" {
+				assert.Equal(t, tc.body, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestRouterCheckError(t *testing.T) {
+	api := openfgaclient.NewFake()
+	api.Err = maaserrors.New(maaserrors.Unavailable, "maas-openfga is down")
+	rt := newRouter(api)
+
+	req := httptest.NewRequest(http.MethodGet, "/pools/2", nil)
+	req.Header.Set("X-User", "user:1")
+
+	rec := httptest.NewRecorder()
+	rt.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
+
+func TestRouterAuthenticatorError(t *testing.T) {
+	rt := New(openfgaclient.NewFake(), func(*http.Request) (string, error) {
+		return "", errors.New("session store unavailable")
+	})
+	rt.HandleFunc("GET /health", Public(), func(http.ResponseWriter, *http.Request) {})
+
+	rec := httptest.NewRecorder()
+	rt.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code,
+		"only missing credentials are let through on public routes")
+}
+
+func TestRouterResolverError(t *testing.T) {
+	rt := New(openfgaclient.NewFake(), headerAuth)
+	rt.HandleFunc("GET /machines/{id}", Require("can_view_machines", func(*http.Request) (string, error) {
+		return "", maaserrors.New(maaserrors.NotFound, "no such machine")
+	}), func(http.ResponseWriter, *http.Request) {})
+
+	req := httptest.NewRequest(http.MethodGet, "/machines/abc", nil)
+	req.Header.Set("X-User", "user:1")
+
+	rec := httptest.NewRecorder()
+	rt.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestHandleRejectsZeroPermission(t *testing.T) {
+	rt := New(openfgaclient.NewFake(), headerAuth)
+
+	assert.Panics(t, func() {
+		rt.HandleFunc("GET /pools", Permission{}, func(http.ResponseWriter, *http.Request) {})
+	})
+	assert.Empty(t, rt.Routes())
+}
+
+func TestRequireRejectsIncompletePermission(t *testing.T) {
+	assert.Panics(t, func() { Require("", Object("maas:0")) })
+	assert.Panics(t, func() { Require("can_view_machines", nil) })
+}
+
+func TestRoutes(t *testing.T) {
+	rt := newRouter(openfgaclient.NewFake())
+
+	require.Equal(t, []Route{
+		{Pattern: "GET /health", Public: true},
+		{Pattern: "GET /pools/{id}", Relation: "can_view_machines"},
+		{Pattern: "POST /config", Relation: "can_edit_configurations"},
+	}, rt.Routes())
+}
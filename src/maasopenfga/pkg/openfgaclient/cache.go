@@ -0,0 +1,93 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package openfgaclient
+
+import (
+	"sync"
+	"time"
+
+	"maas.io/core/src/maasgocommon/clock"
+)
+
+type cachedCheck struct {
+	expiresAt time.Time
+	allowed   bool
+}
+
+// checkCache holds the results of checks for a fixed time.
+type checkCache struct {
+	clock   clock.Clock
+	entries map[Tuple]cachedCheck
+	ttl     time.Duration
+	size    int
+	mu      sync.Mutex
+}
+
+func newCheckCache(clk clock.Clock, ttl time.Duration, size int) *checkCache {
+	return &checkCache{
+		clock:   clk,
+		entries: make(map[Tuple]cachedCheck),
+		ttl:     ttl,
+		size:    size,
+	}
+}
+
+func (c *checkCache) get(t Tuple) (allowed, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[t]
+	if !ok {
+		return false, false
+	}
+
+	if !c.clock.Now().Before(entry.expiresAt) {
+		delete(c.entries, t)
+		return false, false
+	}
+
+	return entry.allowed, true
+}
+
+// set caches the result of a check. When the cache is full, expired
+// results are dropped first, and all of them if none has expired.
+func (c *checkCache) set(t Tuple, allowed bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.clock.Now()
+
+	if len(c.entries) >= c.size {
+		for key, entry := range c.entries {
+			if !now.Before(entry.expiresAt) {
+				delete(c.entries, key)
+			}
+		}
+
+		if len(c.entries) >= c.size {
+			clear(c.entries)
+		}
+	}
+
+	c.entries[t] = cachedCheck{expiresAt: now.Add(c.ttl), allowed: allowed}
+}
+
+func (c *checkCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	clear(c.entries)
+}
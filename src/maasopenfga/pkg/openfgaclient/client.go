@@ -0,0 +1,568 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package openfgaclient calls the OpenFGA HTTP API served by maas-openfga,
+// by default over the unix socket regiond uses.
+//
+// Requests are retried with backoff while maas-openfga is unavailable, and
+// connections to it are kept open and reused. Check results may be cached
+// for a short while, see WithCheckCache. Fake implements the same API in
+// memory for tests.
+package openfgaclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+	"maas.io/core/src/maasgocommon/clock"
+	maaserrors "maas.io/core/src/maasgocommon/errors"
+	"maas.io/core/src/maasgocommon/retry"
+	"maas.io/core/src/maasopenfga/internal/migrations"
+)
+
+const (
+	defaultTimeout     = 30 * time.Second
+	defaultMaxConns    = 4
+	defaultMaxAttempts = 3
+	defaultCacheSize   = 10000
+	readPageSize       = 100
+	maxErrorSize       = 4096
+	requestIDHeader    = "X-Request-Id"
+)
+
+// unmarshal tolerates fields added to the API by newer servers.
+var unmarshal = protojson.UnmarshalOptions{DiscardUnknown: true}
+
+// Tuple is a relation between a user and an object, e.g. user:1 member
+// group:2.
+type Tuple struct {
+	User     string
+	Relation string
+	Object   string
+}
+
+func (t Tuple) String() string {
+	return t.User + " " + t.Relation + " " + t.Object
+}
+
+func (t Tuple) key() *openfgav1.TupleKey {
+	return &openfgav1.TupleKey{User: t.User, Relation: t.Relation, Object: t.Object}
+}
+
+// API is the part of the OpenFGA API used by MAAS services, implemented by
+// Client and Fake.
+type API interface {
+	// Check reports whether user has relation with object, taking the
+	// contextual tuples into account as if they were stored.
+	Check(ctx context.Context, tuple Tuple, contextual ...Tuple) (bool, error)
+	// BatchCheck checks several tuples at once, the results are in the
+	// order of the checks. The contextual tuples apply to every check.
+	BatchCheck(ctx context.Context, checks []Tuple, contextual ...Tuple) ([]bool, error)
+	// ListObjects returns the objects of the given type that user has
+	// relation with, taking the contextual tuples into account.
+	ListObjects(ctx context.Context, user, relation, objectType string, contextual ...Tuple) ([]string, error)
+	// Write writes and deletes tuples in a single transaction. Writing a
+	// stored tuple or deleting a missing one is not an error.
+	Write(ctx context.Context, writes, deletes []Tuple) error
+	// Read returns the stored tuples matching filter. Empty fields match
+	// anything and an object made of a type only, e.g. "pool:", matches
+	// all the objects of the type.
+	Read(ctx context.Context, filter Tuple) ([]Tuple, error)
+	// Access returns the users having each of the relations with object,
+	// sorted, resolving group memberships and inherited permissions. All
+	// the relations of the type of object are returned if none is given.
+	Access(ctx context.Context, object string, relations ...string) (map[string][]string, error)
+}
+
+// Error is an error answered by maas-openfga.
+type Error struct {
+	// Code identifies the error, e.g. "resolution_limit_exceeded" for
+	// checks that must be considered denied.
+	Code    string
+	Message string
+	// RequestID is the ID the server logged the request with.
+	RequestID  string
+	StatusCode int
+}
+
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("request failed with status %d", e.StatusCode)
+	if e.Message != "" {
+		msg = e.Code + ": " + e.Message
+	}
+
+	if e.RequestID != "" {
+		msg += " (request " + e.RequestID + ")"
+	}
+
+	return msg
+}
+
+// DefaultSocketPath returns the unix socket regiond uses to reach
+// maas-openfga, which can be overridden with MAAS_OPENFGA_HTTP_SOCKET_PATH.
+func DefaultSocketPath() string {
+	socketPath := os.Getenv("MAAS_OPENFGA_HTTP_SOCKET_PATH")
+
+	if socketPath == "" {
+		// Deb installation
+		socketPath = "/var/lib/maas/openfga-http.sock"
+	}
+
+	return socketPath
+}
+
+// Option allows to set additional Client options
+type Option func(*Client)
+
+// Client calls maas-openfga. It is safe for concurrent use.
+type Client struct {
+	httpClient  *http.Client
+	clock       clock.Clock
+	cache       *checkCache
+	socketPath  string
+	baseURL     string
+	storeID     string
+	timeout     time.Duration
+	cacheTTL    time.Duration
+	maxConns    int
+	maxAttempts int
+}
+
+// New returns a client of the MAAS store, reached over the default unix
+// socket.
+func New(options ...Option) *Client {
+	c := &Client{
+		clock:       clock.New(),
+		socketPath:  DefaultSocketPath(),
+		storeID:     migrations.StoreID,
+		timeout:     defaultTimeout,
+		maxConns:    defaultMaxConns,
+		maxAttempts: defaultMaxAttempts,
+	}
+
+	for _, opt := range options {
+		opt(c)
+	}
+
+	transport := &http.Transport{
+		MaxConnsPerHost:     c.maxConns,
+		MaxIdleConnsPerHost: c.maxConns,
+		IdleConnTimeout:     90 * time.Second,
+	}
+
+	if c.baseURL == "" {
+		// Host is ignored, connections always go to the unix socket.
+		c.baseURL = "http://maas-openfga"
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", c.socketPath)
+		}
+	}
+
+	c.httpClient = &http.Client{Timeout: c.timeout, Transport: transport}
+
+	if c.cacheTTL > 0 {
+		c.cache = newCheckCache(c.clock, c.cacheTTL, defaultCacheSize)
+	}
+
+	return c
+}
+
+// WithSocketPath sets the unix socket of maas-openfga (default:
+// DefaultSocketPath())
+func WithSocketPath(path string) Option {
+	return func(c *Client) {
+		if path != "" {
+			c.socketPath = path
+		}
+	}
+}
+
+// WithBaseURL reaches maas-openfga over TCP at the given URL, e.g.
+// http://10.0.0.1:8080, instead of the unix socket
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		c.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+// WithStore sets the ID of the store (default: the MAAS store)
+func WithStore(storeID string) Option {
+	return func(c *Client) {
+		if storeID != "" {
+			c.storeID = storeID
+		}
+	}
+}
+
+// WithMaxConns sets the maximum number of connections to maas-openfga
+// (default: 4)
+func WithMaxConns(n int) Option {
+	return func(c *Client) {
+		if n > 0 {
+			c.maxConns = n
+		}
+	}
+}
+
+// WithTimeout sets the timeout of each attempt of a request (default: 30s)
+func WithTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		if d > 0 {
+			c.timeout = d
+		}
+	}
+}
+
+// WithMaxAttempts sets how many times requests are attempted while
+// maas-openfga is unavailable (default: 3)
+func WithMaxAttempts(n int) Option {
+	return func(c *Client) {
+		if n > 0 {
+			c.maxAttempts = n
+		}
+	}
+}
+
+// WithCheckCache caches the results of Check for ttl. Permissions changed
+// by other clients may take up to ttl to apply, the cache is cleared when
+// the client writes tuples. Checks with contextual tuples are not cached.
+func WithCheckCache(ttl time.Duration) Option {
+	return func(c *Client) {
+		c.cacheTTL = ttl
+	}
+}
+
+// WithClock sets the clock used to expire cached results and wait between
+// attempts (default: the system clock)
+func WithClock(clk clock.Clock) Option {
+	return func(c *Client) {
+		c.clock = clk
+	}
+}
+
+// Check implements API.
+func (c *Client) Check(ctx context.Context, tuple Tuple, contextual ...Tuple) (bool, error) {
+	if err := validateContextual(contextual); err != nil {
+		return false, err
+	}
+
+	cacheable := c.cache != nil && len(contextual) == 0
+	if cacheable {
+		if allowed, ok := c.cache.get(tuple); ok {
+			return allowed, nil
+		}
+	}
+
+	req := &openfgav1.CheckRequest{
+		TupleKey: &openfgav1.CheckRequestTupleKey{
+			User: tuple.User, Relation: tuple.Relation, Object: tuple.Object,
+		},
+	}
+
+	req.ContextualTuples = contextualKeys(contextual)
+
+	var resp openfgav1.CheckResponse
+	if err := c.post(ctx, "/check", req, &resp); err != nil {
+		return false, err
+	}
+
+	if cacheable {
+		c.cache.set(tuple, resp.GetAllowed())
+	}
+
+	return resp.GetAllowed(), nil
+}
+
+// BatchCheck implements API.
+func (c *Client) BatchCheck(ctx context.Context, checks []Tuple, contextual ...Tuple) ([]bool, error) {
+	if len(checks) == 0 {
+		return nil, nil
+	}
+
+	if err := validateContextual(contextual); err != nil {
+		return nil, err
+	}
+
+	req := &openfgav1.BatchCheckRequest{}
+
+	for i, t := range checks {
+		req.Checks = append(req.Checks, &openfgav1.BatchCheckItem{
+			TupleKey: &openfgav1.CheckRequestTupleKey{
+				User: t.User, Relation: t.Relation, Object: t.Object,
+			},
+			ContextualTuples: contextualKeys(contextual),
+			CorrelationId:    strconv.Itoa(i),
+		})
+	}
+
+	var resp openfgav1.BatchCheckResponse
+	if err := c.post(ctx, "/batch-check", req, &resp); err != nil {
+		return nil, err
+	}
+
+	allowed := make([]bool, len(checks))
+
+	for i, t := range checks {
+		result, ok := resp.GetResult()[strconv.Itoa(i)]
+		if !ok {
+			return nil, fmt.Errorf("no result for check of %s", t)
+		}
+
+		if e := result.GetError(); e != nil {
+			return nil, fmt.Errorf("failed to check %s: %s", t, e.GetMessage())
+		}
+
+		allowed[i] = result.GetAllowed()
+	}
+
+	return allowed, nil
+}
+
+// ListObjects implements API.
+func (c *Client) ListObjects(ctx context.Context, user, relation, objectType string,
+	contextual ...Tuple,
+) ([]string, error) {
+	if err := validateContextual(contextual); err != nil {
+		return nil, err
+	}
+
+	req := &openfgav1.ListObjectsRequest{
+		User:             user,
+		Relation:         relation,
+		Type:             objectType,
+		ContextualTuples: contextualKeys(contextual),
+	}
+
+	var resp openfgav1.ListObjectsResponse
+	if err := c.post(ctx, "/list-objects", req, &resp); err != nil {
+		return nil, err
+	}
+
+	return resp.GetObjects(), nil
+}
+
+// Write implements API. The number of tuples written at once is limited by
+// the server, 100 by default.
+func (c *Client) Write(ctx context.Context, writes, deletes []Tuple) error {
+	if len(writes) == 0 && len(deletes) == 0 {
+		return nil
+	}
+
+	// Duplicates and missing tuples are ignored, which makes writes safe
+	// to retry.
+	req := &openfgav1.WriteRequest{}
+
+	if len(writes) > 0 {
+		req.Writes = &openfgav1.WriteRequestWrites{TupleKeys: keys(writes), OnDuplicate: "ignore"}
+	}
+
+	if len(deletes) > 0 {
+		req.Deletes = &openfgav1.WriteRequestDeletes{OnMissing: "ignore"}
+
+		for _, t := range deletes {
+			req.Deletes.TupleKeys = append(req.Deletes.TupleKeys, &openfgav1.TupleKeyWithoutCondition{
+				User: t.User, Relation: t.Relation, Object: t.Object,
+			})
+		}
+	}
+
+	if c.cache != nil {
+		defer c.cache.clear()
+	}
+
+	var resp openfgav1.WriteResponse
+
+	return c.post(ctx, "/write", req, &resp)
+}
+
+// Read implements API, following continuation tokens.
+func (c *Client) Read(ctx context.Context, filter Tuple) ([]Tuple, error) {
+	req := &openfgav1.ReadRequest{PageSize: wrapperspb.Int32(readPageSize)}
+
+	if filter != (Tuple{}) {
+		req.TupleKey = &openfgav1.ReadRequestTupleKey{
+			User: filter.User, Relation: filter.Relation, Object: filter.Object,
+		}
+	}
+
+	var tuples []Tuple
+
+	for {
+		var resp openfgav1.ReadResponse
+		if err := c.post(ctx, "/read", req, &resp); err != nil {
+			return nil, err
+		}
+
+		for _, t := range resp.GetTuples() {
+			key := t.GetKey()
+			tuples = append(tuples, Tuple{
+				User: key.GetUser(), Relation: key.GetRelation(), Object: key.GetObject(),
+			})
+		}
+
+		if resp.GetContinuationToken() == "" {
+			return tuples, nil
+		}
+
+		req.ContinuationToken = resp.GetContinuationToken()
+	}
+}
+
+// Access implements API.
+func (c *Client) Access(ctx context.Context, object string, relations ...string) (map[string][]string, error) {
+	query := url.Values{"object": {object}}
+	if len(relations) > 0 {
+		query["relation"] = relations
+	}
+
+	var resp struct {
+		Relations map[string][]string `json:"relations"`
+	}
+
+	if err := c.call(ctx, http.MethodGet, "/access?"+query.Encode(), nil, func(body []byte) error {
+		return json.Unmarshal(body, &resp)
+	}); err != nil {
+		return nil, err
+	}
+
+	return resp.Relations, nil
+}
+
+// post calls an endpoint of the store, e.g. "/check", retrying while
+// maas-openfga is unavailable.
+func (c *Client) post(ctx context.Context, path string, in, out proto.Message) error {
+	data, err := protojson.Marshal(in)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	return c.call(ctx, http.MethodPost, path, data, func(body []byte) error {
+		return unmarshal.Unmarshal(body, out)
+	})
+}
+
+// call sends a request with the body data to an endpoint of the store and
+// decodes the response with decode, retrying while maas-openfga is
+// unavailable.
+func (c *Client) call(ctx context.Context, method, path string, data []byte,
+	decode func([]byte) error,
+) error {
+	endpoint := c.baseURL + "/stores/" + url.PathEscape(c.storeID) + path
+
+	return retry.Do(ctx, func(ctx context.Context) error {
+		return c.do(ctx, method, endpoint, data, decode)
+	},
+		retry.WithMaxAttempts(c.maxAttempts),
+		retry.WithInitialInterval(100*time.Millisecond),
+		retry.WithMaxInterval(2*time.Second),
+		retry.WithRetryIf(maaserrors.Retryable),
+		retry.WithClock(c.clock))
+}
+
+func (c *Client) do(ctx context.Context, method, endpoint string, data []byte,
+	decode func([]byte) error,
+) error {
+	var body io.Reader
+	if data != nil {
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return retry.Permanent(fmt.Errorf("failed to create request: %w", err))
+	}
+
+	if data != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+
+	defer resp.Body.Close() //nolint:errcheck // nothing to do with the error
+
+	if resp.StatusCode != http.StatusOK {
+		return statusError(resp)
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if err := decode(respBody); err != nil {
+		return retry.Permanent(fmt.Errorf("failed to decode response: %w", err))
+	}
+
+	return nil
+}
+
+// statusError extracts the OpenFGA error of a response, classified by its
+// status code.
+func statusError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorSize)) //nolint:errcheck // best effort
+
+	e := &Error{StatusCode: resp.StatusCode, RequestID: resp.Header.Get(requestIDHeader)}
+
+	// The error envelope of the MAAS API, whose detail identifies the error.
+	var payload struct {
+		Details []struct {
+			Type    string `json:"type"`
+			Message string `json:"message"`
+		} `json:"details"`
+	}
+
+	if err := json.Unmarshal(body, &payload); err == nil && len(payload.Details) > 0 {
+		e.Code, e.Message = payload.Details[0].Type, payload.Details[0].Message
+	}
+
+	return maaserrors.Wrap(maaserrors.FromHTTPStatus(resp.StatusCode), e)
+}
+
+func keys(tuples []Tuple) []*openfgav1.TupleKey {
+	keys := make([]*openfgav1.TupleKey, 0, len(tuples))
+	for _, t := range tuples {
+		keys = append(keys, t.key())
+	}
+
+	return keys
+}
+
+// contextualKeys returns the contextual tuples of a request, nil if there
+// are none.
+func contextualKeys(tuples []Tuple) *openfgav1.ContextualTupleKeys {
+	if len(tuples) == 0 {
+		return nil
+	}
+
+	return &openfgav1.ContextualTupleKeys{TupleKeys: keys(tuples)}
+}
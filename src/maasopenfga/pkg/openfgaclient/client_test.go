@@ -0,0 +1,473 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package openfgaclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maas.io/core/src/maasgocommon/clock"
+	maaserrors "maas.io/core/src/maasgocommon/errors"
+)
+
+// handler answers requests to path with the given JSON body, recording the
+// request bodies.
+type handler struct {
+	t        *testing.T
+	path     string
+	response string
+	requests []map[string]any
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	assert.Equal(h.t, http.MethodPost, r.Method)
+	assert.Equal(h.t, "/stores/store"+h.path, r.URL.Path)
+
+	body, err := io.ReadAll(r.Body)
+	require.NoError(h.t, err)
+
+	var req map[string]any
+	require.NoError(h.t, json.Unmarshal(body, &req))
+
+	h.requests = append(h.requests, req)
+
+	_, _ = io.WriteString(w, h.response) //nolint:errcheck // test
+}
+
+func newTestClient(t *testing.T, h http.Handler, options ...Option) *Client {
+	t.Helper()
+
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+
+	return New(append([]Option{WithBaseURL(srv.URL), WithStore("store")}, options...)...)
+}
+
+func TestCheck(t *testing.T) {
+	h := &handler{t: t, path: "/check", response: `{"allowed": true}`}
+	c := newTestClient(t, h)
+
+	allowed, err := c.Check(context.Background(), Tuple{"user:1", "can_edit_machines", "maas:0"},
+		Tuple{"user:1", "member", "group:1"})
+	require.NoError(t, err)
+	assert.True(t, allowed)
+
+	assert.Equal(t, []map[string]any{{
+		"tuple_key": map[string]any{"user": "user:1", "relation": "can_edit_machines", "object": "maas:0"},
+		"contextual_tuples": map[string]any{"tuple_keys": []any{
+			map[string]any{"user": "user:1", "relation": "member", "object": "group:1"},
+		}},
+	}}, h.requests)
+}
+
+func TestCheckInvalidContextual(t *testing.T) {
+	h := &handler{t: t, path: "/check", response: `{"allowed": true}`}
+	c := newTestClient(t, h)
+	tuple := Tuple{"user:1", "can_deploy", "machine:1"}
+
+	tests := map[string][]Tuple{
+		"missing id":       {{"pool:", "pool", "machine:1"}},
+		"missing type":     {{"pool:1", "pool", "1"}},
+		"missing relation": {{"pool:1", "", "machine:1"}},
+		"empty userset":    {{"group:1#", "member", "group:2"}},
+		"too many":         make([]Tuple, MaxContextualTuples+1),
+	}
+
+	for name, contextual := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := c.Check(context.Background(), tuple, contextual...)
+			assert.Equal(t, maaserrors.Invalid, maaserrors.KindOf(err))
+		})
+	}
+
+	assert.Empty(t, h.requests)
+}
+
+func TestContextual(t *testing.T) {
+	tuples := NewContextual().
+		Member(1, 2).
+		MachineInPool(3, 4).
+		MachineTagged(3, 5).
+		Add(GroupMembers(2), "can_edit_machines", Pool(4)).
+		Tuples()
+
+	assert.Equal(t, []Tuple{
+		{"user:1", "member", "group:2"},
+		{"pool:4", "pool", "machine:3"},
+		{"tag:5", "tag", "machine:3"},
+		{"group:2#member", "can_edit_machines", "pool:4"},
+	}, tuples)
+	assert.NoError(t, validateContextual(tuples))
+}
+
+func TestCheckCache(t *testing.T) {
+	clk := clock.NewFake(time.Now())
+	h := &handler{t: t, path: "/check", response: `{"allowed": true}`}
+	c := newTestClient(t, h, WithCheckCache(time.Minute), WithClock(clk))
+
+	tuple := Tuple{"user:1", "can_edit_machines", "maas:0"}
+
+	for range 2 {
+		allowed, err := c.Check(context.Background(), tuple)
+		require.NoError(t, err)
+		assert.True(t, allowed)
+	}
+
+	assert.Len(t, h.requests, 1)
+
+	// Checks with contextual tuples always reach the server.
+	_, err := c.Check(context.Background(), tuple, Tuple{"user:1", "member", "group:1"})
+	require.NoError(t, err)
+	assert.Len(t, h.requests, 2)
+
+	clk.Advance(time.Minute)
+
+	_, err = c.Check(context.Background(), tuple)
+	require.NoError(t, err)
+	assert.Len(t, h.requests, 3)
+}
+
+func TestCheckCacheClearedOnWrite(t *testing.T) {
+	mux := http.NewServeMux()
+
+	var checks atomic.Int32
+
+	mux.HandleFunc("/stores/store/check", func(w http.ResponseWriter, _ *http.Request) {
+		checks.Add(1)
+		_, _ = io.WriteString(w, `{"allowed": false}`) //nolint:errcheck // test
+	})
+	mux.HandleFunc("/stores/store/write", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, `{}`) //nolint:errcheck // test
+	})
+
+	c := newTestClient(t, mux, WithCheckCache(time.Minute))
+	tuple := Tuple{"user:1", "member", "group:1"}
+
+	_, err := c.Check(context.Background(), tuple)
+	require.NoError(t, err)
+
+	require.NoError(t, c.Write(context.Background(), []Tuple{tuple}, nil))
+
+	_, err = c.Check(context.Background(), tuple)
+	require.NoError(t, err)
+
+	assert.Equal(t, int32(2), checks.Load())
+}
+
+func TestBatchCheck(t *testing.T) {
+	h := &handler{t: t, path: "/batch-check", response: `{"result": {
+		"0": {"allowed": true},
+		"1": {"allowed": false}
+	}}`}
+	c := newTestClient(t, h)
+
+	allowed, err := c.BatchCheck(context.Background(), []Tuple{
+		{"user:1", "can_view_machines", "pool:1"},
+		{"user:1", "can_view_machines", "pool:2"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []bool{true, false}, allowed)
+
+	checks, ok := h.requests[0]["checks"].([]any)
+	require.True(t, ok)
+	assert.Len(t, checks, 2)
+}
+
+func TestBatchCheckContextual(t *testing.T) {
+	h := &handler{t: t, path: "/batch-check", response: `{"result": {"0": {"allowed": true}}}`}
+	c := newTestClient(t, h)
+
+	_, err := c.BatchCheck(context.Background(), []Tuple{{"user:1", "can_deploy", "machine:1"}},
+		NewContextual().MachineInPool(1, 2).Tuples()...)
+	require.NoError(t, err)
+
+	assert.Equal(t, []any{map[string]any{
+		"tuple_key": map[string]any{"user": "user:1", "relation": "can_deploy", "object": "machine:1"},
+		"contextual_tuples": map[string]any{"tuple_keys": []any{
+			map[string]any{"user": "pool:2", "relation": "pool", "object": "machine:1"},
+		}},
+		"correlation_id": "0",
+	}}, h.requests[0]["checks"])
+}
+
+func TestBatchCheckError(t *testing.T) {
+	h := &handler{t: t, path: "/batch-check", response: `{"result": {
+		"0": {"error": {"message": "type 'pools' not found"}}
+	}}`}
+	c := newTestClient(t, h)
+
+	_, err := c.BatchCheck(context.Background(), []Tuple{{"user:1", "can_view_machines", "pools:1"}})
+	assert.ErrorContains(t, err, "type 'pools' not found")
+}
+
+func TestListObjects(t *testing.T) {
+	h := &handler{t: t, path: "/list-objects", response: `{"objects": ["pool:1", "pool:2"]}`}
+	c := newTestClient(t, h)
+
+	objects, err := c.ListObjects(context.Background(), "user:1", "can_view_machines", "pool")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"pool:1", "pool:2"}, objects)
+
+	assert.Equal(t, []map[string]any{{
+		"user": "user:1", "relation": "can_view_machines", "type": "pool",
+	}}, h.requests)
+}
+
+func TestListObjectsContextual(t *testing.T) {
+	h := &handler{t: t, path: "/list-objects", response: `{"objects": ["machine:1"]}`}
+	c := newTestClient(t, h)
+
+	_, err := c.ListObjects(context.Background(), "user:1", "can_deploy", "machine",
+		NewContextual().MachineInPool(1, 2).Tuples()...)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]any{"tuple_keys": []any{
+		map[string]any{"user": "pool:2", "relation": "pool", "object": "machine:1"},
+	}}, h.requests[0]["contextual_tuples"])
+
+	_, err = c.ListObjects(context.Background(), "user:1", "can_deploy", "machine", Tuple{})
+	assert.Equal(t, maaserrors.Invalid, maaserrors.KindOf(err))
+	assert.Len(t, h.requests, 1)
+}
+
+func TestWrite(t *testing.T) {
+	h := &handler{t: t, path: "/write", response: `{}`}
+	c := newTestClient(t, h)
+
+	err := c.Write(context.Background(),
+		[]Tuple{{"user:1", "member", "group:1"}},
+		[]Tuple{{"user:1", "member", "group:2"}})
+	require.NoError(t, err)
+
+	assert.Equal(t, []map[string]any{{
+		"writes": map[string]any{
+			"tuple_keys":   []any{map[string]any{"user": "user:1", "relation": "member", "object": "group:1"}},
+			"on_duplicate": "ignore",
+		},
+		"deletes": map[string]any{
+			"tuple_keys": []any{map[string]any{"user": "user:1", "relation": "member", "object": "group:2"}},
+			"on_missing": "ignore",
+		},
+	}}, h.requests)
+
+	// Nothing to write.
+	require.NoError(t, c.Write(context.Background(), nil, nil))
+	assert.Len(t, h.requests, 1)
+}
+
+func TestRead(t *testing.T) {
+	mux := http.NewServeMux()
+
+	var requests []map[string]any
+
+	mux.HandleFunc("/stores/store/read", func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]any
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		requests = append(requests, req)
+
+		if req["continuation_token"] == nil {
+			//nolint:errcheck // test
+			_, _ = io.WriteString(w, `{"tuples": [{"key": {"user": "maas:0", "relation": "parent", "object": "pool:1"}}],
+				"continuation_token": "next"}`)
+
+			return
+		}
+
+		//nolint:errcheck // test
+		_, _ = io.WriteString(w, `{"tuples": [{"key": {"user": "maas:0", "relation": "parent", "object": "pool:2"}}]}`)
+	})
+
+	c := newTestClient(t, mux)
+
+	tuples, err := c.Read(context.Background(), Tuple{Relation: "parent", Object: "pool:"})
+	require.NoError(t, err)
+	assert.Equal(t, []Tuple{
+		{"maas:0", "parent", "pool:1"},
+		{"maas:0", "parent", "pool:2"},
+	}, tuples)
+
+	require.Len(t, requests, 2)
+	assert.Equal(t, map[string]any{"relation": "parent", "object": "pool:"}, requests[0]["tuple_key"])
+	assert.Equal(t, "next", requests[1]["continuation_token"])
+}
+
+func TestAccess(t *testing.T) {
+	mux := http.NewServeMux()
+
+	var queries []url.Values
+
+	mux.HandleFunc("GET /stores/store/access", func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.Query())
+
+		//nolint:errcheck // test
+		_, _ = io.WriteString(w, `{"object": "pool:1", "authorization_model_id": "model",
+			"relations": {"can_deploy_machines": ["user:1", "user:2"], "can_edit_machines": []}}`)
+	})
+
+	c := newTestClient(t, mux)
+
+	access, err := c.Access(context.Background(), "pool:1", "can_deploy_machines", "can_edit_machines")
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		"can_deploy_machines": {"user:1", "user:2"},
+		"can_edit_machines":   {},
+	}, access)
+
+	_, err = c.Access(context.Background(), "pool:1")
+	require.NoError(t, err)
+
+	assert.Equal(t, []url.Values{
+		{"object": {"pool:1"}, "relation": {"can_deploy_machines", "can_edit_machines"}},
+		{"object": {"pool:1"}},
+	}, queries)
+}
+
+func TestRetry(t *testing.T) {
+	var attempts atomic.Int32
+
+	h := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if attempts.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		_, _ = io.WriteString(w, `{"allowed": true}`) //nolint:errcheck // test
+	})
+
+	c := newTestClient(t, h)
+
+	allowed, err := c.Check(context.Background(), Tuple{"user:1", "member", "group:1"})
+	require.NoError(t, err)
+	assert.True(t, allowed)
+	assert.Equal(t, int32(2), attempts.Load())
+}
+
+func TestInvalidNotRetried(t *testing.T) {
+	var attempts atomic.Int32
+
+	h := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		attempts.Add(1)
+		w.Header().Set("X-Request-Id", "req-1")
+		w.WriteHeader(http.StatusBadRequest)
+		//nolint:errcheck // test
+		_, _ = io.WriteString(w, `{"kind": "Error", "code": 400, "message": "Bad request.", `+
+			`"details": [{"type": "invalid_argument", "message": "invalid relation"}]}`)
+	})
+
+	c := newTestClient(t, h)
+
+	_, err := c.Check(context.Background(), Tuple{"user:1", "bogus", "group:1"})
+
+	var apiErr *Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, &Error{
+		Code:       "invalid_argument",
+		Message:    "invalid relation",
+		RequestID:  "req-1",
+		StatusCode: http.StatusBadRequest,
+	}, apiErr)
+	assert.Equal(t, int32(1), attempts.Load())
+}
+
+func TestUnixSocket(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "openfga.sock")
+
+	l, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+
+	srv := &http.Server{
+		Handler:           &handler{t: t, path: "/check", response: `{"allowed": true}`},
+		ReadHeaderTimeout: time.Second,
+	}
+
+	go func() { _ = srv.Serve(l) }() //nolint:errcheck // closed by Close
+
+	t.Cleanup(func() { _ = srv.Close() }) //nolint:errcheck // test
+
+	c := New(WithSocketPath(socketPath), WithStore("store"))
+
+	allowed, err := c.Check(context.Background(), Tuple{"user:1", "member", "group:1"})
+	require.NoError(t, err)
+	assert.True(t, allowed)
+}
+
+func TestFake(t *testing.T) {
+	ctx := context.Background()
+	f := NewFake(Tuple{"user:1", "member", "group:1"}, Tuple{"maas:0", "parent", "pool:1"})
+
+	allowed, err := f.Check(ctx, Tuple{"user:1", "member", "group:1"})
+	require.NoError(t, err)
+	assert.True(t, allowed)
+
+	allowed, err = f.Check(ctx, Tuple{"user:2", "member", "group:1"}, Tuple{"user:2", "member", "group:1"})
+	require.NoError(t, err)
+	assert.True(t, allowed)
+
+	require.NoError(t, f.Write(ctx,
+		[]Tuple{{"maas:0", "parent", "pool:2"}},
+		[]Tuple{{"user:1", "member", "group:1"}}))
+
+	results, err := f.BatchCheck(ctx, []Tuple{{"user:1", "member", "group:1"}, {"maas:0", "parent", "pool:2"}})
+	require.NoError(t, err)
+	assert.Equal(t, []bool{false, true}, results)
+
+	objects, err := f.ListObjects(ctx, "maas:0", "parent", "pool")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"pool:1", "pool:2"}, objects)
+
+	objects, err = f.ListObjects(ctx, "maas:0", "parent", "pool", Tuple{"maas:0", "parent", "pool:3"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"pool:1", "pool:2", "pool:3"}, objects)
+
+	// Contextual tuples are not stored.
+	assert.Equal(t, []Tuple{{"maas:0", "parent", "pool:1"}, {"maas:0", "parent", "pool:2"}}, f.Tuples())
+
+	require.NoError(t, f.Write(ctx, []Tuple{
+		{"user:1", "member", "group:1"},
+		{"user:2", "member", "group:1"},
+		{"group:1#member", "can_deploy_machines", "pool:1"},
+		{"user:2", "can_deploy_machines", "pool:1"},
+	}, nil))
+
+	access, err := f.Access(ctx, "pool:1")
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		"can_deploy_machines": {"user:1", "user:2"},
+		"parent":              {"maas:0"},
+	}, access)
+
+	access, err = f.Access(ctx, "pool:1", "can_edit_machines")
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{"can_edit_machines": {}}, access)
+
+	f.Err = errors.New("boom")
+
+	_, err = f.Read(ctx, Tuple{})
+	assert.EqualError(t, err, "boom")
+}
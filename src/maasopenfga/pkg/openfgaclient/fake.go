@@ -0,0 +1,228 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package openfgaclient
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Fake is an in-memory API for tests. Relations are not evaluated against
+// an authorization model: a check is allowed if the tuple is stored as is
+// or is one of the contextual tuples, which are validated as Client does.
+type Fake struct {
+	tuples map[Tuple]struct{}
+	// Err, if set, is returned by every call.
+	Err error
+	mu  sync.Mutex
+}
+
+var _ API = (*Fake)(nil)
+
+// NewFake returns a Fake storing tuples.
+func NewFake(tuples ...Tuple) *Fake {
+	f := &Fake{tuples: make(map[Tuple]struct{})}
+	for _, t := range tuples {
+		f.tuples[t] = struct{}{}
+	}
+
+	return f
+}
+
+// Check implements API.
+func (f *Fake) Check(_ context.Context, tuple Tuple, contextual ...Tuple) (bool, error) {
+	if err := validateContextual(contextual); err != nil {
+		return false, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return false, f.Err
+	}
+
+	_, ok := f.tuples[tuple]
+
+	return ok || slices.Contains(contextual, tuple), nil
+}
+
+// BatchCheck implements API.
+func (f *Fake) BatchCheck(ctx context.Context, checks []Tuple, contextual ...Tuple) ([]bool, error) {
+	allowed := make([]bool, 0, len(checks))
+
+	for _, t := range checks {
+		ok, err := f.Check(ctx, t, contextual...)
+		if err != nil {
+			return nil, err
+		}
+
+		allowed = append(allowed, ok)
+	}
+
+	return allowed, nil
+}
+
+// ListObjects implements API, returning sorted objects.
+func (f *Fake) ListObjects(ctx context.Context, user, relation, objectType string,
+	contextual ...Tuple,
+) ([]string, error) {
+	if err := validateContextual(contextual); err != nil {
+		return nil, err
+	}
+
+	filter := Tuple{User: user, Relation: relation, Object: objectType + ":"}
+
+	tuples, err := f.Read(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var objects []string
+	for _, t := range append(tuples, contextual...) {
+		if matches(filter, t) {
+			objects = append(objects, t.Object)
+		}
+	}
+
+	slices.Sort(objects)
+
+	return slices.Compact(objects), nil
+}
+
+// Access implements API. Usersets, e.g. group:1#member, are resolved to
+// the users stored with their relation, and relations default to those of
+// the tuples stored on object.
+func (f *Fake) Access(_ context.Context, object string, relations ...string) (map[string][]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return nil, f.Err
+	}
+
+	if len(relations) == 0 {
+		for _, t := range f.read(Tuple{Object: object}) {
+			relations = append(relations, t.Relation)
+		}
+	}
+
+	access := make(map[string][]string, len(relations))
+
+	for _, relation := range relations {
+		users := f.users(object, relation, map[string]bool{})
+		slices.Sort(users)
+		access[relation] = slices.Compact(users)
+	}
+
+	return access, nil
+}
+
+// users returns the users having relation with object, following usersets
+// not visited yet. f.mu must be held.
+func (f *Fake) users(object, relation string, visited map[string]bool) []string {
+	users := []string{}
+
+	for _, t := range f.read(Tuple{Relation: relation, Object: object}) {
+		userset, userRelation, ok := strings.Cut(t.User, "#")
+		if !ok {
+			users = append(users, t.User)
+			continue
+		}
+
+		if !visited[t.User] {
+			visited[t.User] = true
+			users = append(users, f.users(userset, userRelation, visited)...)
+		}
+	}
+
+	return users
+}
+
+// Write implements API.
+func (f *Fake) Write(_ context.Context, writes, deletes []Tuple) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return f.Err
+	}
+
+	for _, t := range writes {
+		f.tuples[t] = struct{}{}
+	}
+
+	for _, t := range deletes {
+		delete(f.tuples, t)
+	}
+
+	return nil
+}
+
+// Read implements API, returning tuples sorted by object, relation and
+// user.
+func (f *Fake) Read(_ context.Context, filter Tuple) ([]Tuple, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return nil, f.Err
+	}
+
+	return f.read(filter), nil
+}
+
+// Tuples returns all the stored tuples, sorted as by Read.
+func (f *Fake) Tuples() []Tuple {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.read(Tuple{})
+}
+
+func (f *Fake) read(filter Tuple) []Tuple {
+	var tuples []Tuple
+
+	for t := range f.tuples {
+		if matches(filter, t) {
+			tuples = append(tuples, t)
+		}
+	}
+
+	slices.SortFunc(tuples, func(a, b Tuple) int {
+		return strings.Compare(a.Object+"#"+a.Relation+"@"+a.User, b.Object+"#"+b.Relation+"@"+b.User)
+	})
+
+	return tuples
+}
+
+func matches(filter, t Tuple) bool {
+	if filter.User != "" && filter.User != t.User {
+		return false
+	}
+
+	if filter.Relation != "" && filter.Relation != t.Relation {
+		return false
+	}
+
+	if objectType, ok := strings.CutSuffix(filter.Object, ":"); ok {
+		return strings.HasPrefix(t.Object, objectType+":")
+	}
+
+	return filter.Object == "" || filter.Object == t.Object
+}
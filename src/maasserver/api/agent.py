@@ -1,10 +1,55 @@
 # Copyright 2023-2026 Canonical Ltd.  This software is licensed under the
 # GNU Affero General Public License version 3 (see the file LICENSE).
 
+import base64
+
+from maascommon.utils.delta import ContentStore, make_delta
 from maasserver.api.support import internal_method, OperationsHandler
 from maasserver.dhcp import generate_dhcp_configuration
 from maasserver.models.node import RackController
 
+# Configurations recently sent to agents, to send deltas against.
+CONFIG_STORE = ContentStore(max_bytes=64 * 1024 * 1024)
+
+# Query parameters prefix an agent uses to report the hash of the
+# configuration it has for a key, e.g. base_dhcpd=<sha256>.
+BASE_PARAM_PREFIX = "base_"
+
+
+def with_deltas(config, bases):
+    """Replace the base64 values of `config` with deltas where possible.
+
+    `bases` maps configuration keys to the hash of the content the agent
+    has. Keys whose base is unknown, or which don't get smaller as a delta,
+    are sent in full. The agent checks the result against `hashes` and asks
+    for the full configuration on mismatch.
+    """
+    hashes = {}
+    deltas = {}
+    for key, value in config.items():
+        data = base64.b64decode(value)
+        hashes[key] = CONFIG_STORE.put(data)
+
+        base_hash = bases.get(key)
+        if base_hash is None:
+            continue
+        base = CONFIG_STORE.get(base_hash)
+        if base is None:
+            continue
+        delta = make_delta(base, data)
+        if len(delta) >= len(data):
+            continue
+        deltas[key] = {
+            "base": base_hash,
+            "delta": base64.b64encode(delta).decode("utf-8"),
+        }
+
+    for key in deltas:
+        config[key] = ""
+    config["hashes"] = hashes
+    config["deltas"] = deltas
+    return config
+
 
 class AgentConfigHandler(OperationsHandler):
     """
@@ -38,4 +83,9 @@ class AgentConfigHandler(OperationsHandler):
         if service_name == "dhcp":
             agent = RackController.objects.get(system_id=system_id)
             config = generate_dhcp_configuration(agent)
-            return config
+            bases = {
+                name.removeprefix(BASE_PARAM_PREFIX): value
+                for name, value in request.GET.items()
+                if name.startswith(BASE_PARAM_PREFIX)
+            }
+            return with_deltas(config, bases)
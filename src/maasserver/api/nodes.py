@@ -45,7 +45,10 @@ from maasserver.exceptions import (
 from maasserver.forms import BulkNodeSetZoneForm
 from maasserver.forms.ephemeral import TestForm
 from maasserver.models import Domain, Filesystem, Interface, Node, OwnerData
-from maasserver.models.nodeprobeddetails import get_single_probed_details
+from maasserver.models.nodeprobeddetails import (
+    get_single_probed_details,
+    get_switch_ports,
+)
 from maasserver.models.scriptset import get_status_from_qs
 from maasserver.node_constraint_filter_forms import ReadNodesForm
 from maasserver.permissions import NodePermission
@@ -568,6 +571,40 @@ class NodeHandler(OperationsHandler):
             content_type="application/bson",
         )
 
+    @operation(idempotent=True)
+    def switch_ports(self, request, system_id):
+        """@description-title Get switch ports
+        @description Returns the switch ports the interfaces of the node
+        are plugged into, as advertised over LLDP during the last
+        commissioning.
+
+        Each entry names the interface of the node, and describes the
+        switch and the port on the other end of the link. The switch
+        ``system_id`` is set when its chassis ID is the MAC address of an
+        interface known to MAAS.
+
+        @param (string) "{system_id}" [required=true] The node's system_id.
+
+        @success (http-status-code) "200" 200
+
+        @success (json) "success-content" A JSON list of switch ports.
+        @success-example "success-content" [exkey=switch-ports] placeholder
+        text
+
+        @error (http-status-code) "403" 403
+        @error (content) "no-perms" The user does not have permission to see
+        the node details.
+        @error-example "no-perms"
+            Forbidden
+
+        @error (http-status-code) "404" 404
+        @error (content) "not-found" The requested node is not found.
+        @error-example "not-found"
+            No Node matches the given query.
+        """
+        node = self.read(request, system_id)
+        return get_switch_ports(node)
+
     @operation(idempotent=True)
     def power_parameters(self, request, system_id):
         """@description-title Get power parameters
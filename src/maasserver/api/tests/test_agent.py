@@ -0,0 +1,69 @@
+# Copyright 2026 Canonical Ltd.  This software is licensed under the
+# GNU Affero General Public License version 3 (see the file LICENSE).
+
+import base64
+
+from maascommon.utils.delta import apply_delta, content_hash, ContentStore
+from maasserver.api import agent as agent_module
+from maasserver.api.agent import with_deltas
+from maastesting.testcase import MAASTestCase
+
+
+def b64(data: bytes) -> str:
+    return base64.b64encode(data).decode("utf-8")
+
+
+class TestWithDeltas(MAASTestCase):
+    def setUp(self):
+        super().setUp()
+        self.patch(agent_module, "CONFIG_STORE", ContentStore(1024 * 1024))
+
+    def test_sends_full_config_without_base(self):
+        config = {"dhcpd": b64(b"subnet {}\n"), "dhcpd_interfaces": b64(b"")}
+
+        result = with_deltas(dict(config), {})
+
+        self.assertEqual(result["dhcpd"], config["dhcpd"])
+        self.assertEqual(
+            result["hashes"],
+            {
+                "dhcpd": content_hash(b"subnet {}\n"),
+                "dhcpd_interfaces": content_hash(b""),
+            },
+        )
+        self.assertEqual(result["deltas"], {})
+
+    def test_sends_delta_against_known_base(self):
+        base = b"".join(b"host h%d {}\n" % i for i in range(100))
+        target = base + b"host new {}\n"
+        with_deltas({"dhcpd": b64(base)}, {})
+
+        result = with_deltas(
+            {"dhcpd": b64(target)}, {"dhcpd": content_hash(base)}
+        )
+
+        self.assertEqual(result["dhcpd"], "")
+        self.assertEqual(result["hashes"], {"dhcpd": content_hash(target)})
+        delta = result["deltas"]["dhcpd"]
+        self.assertEqual(delta["base"], content_hash(base))
+        self.assertEqual(
+            apply_delta(base, base64.b64decode(delta["delta"])), target
+        )
+
+    def test_sends_full_config_for_unknown_base(self):
+        result = with_deltas(
+            {"dhcpd": b64(b"subnet {}\n")}, {"dhcpd": content_hash(b"gone")}
+        )
+
+        self.assertEqual(result["dhcpd"], b64(b"subnet {}\n"))
+        self.assertEqual(result["deltas"], {})
+
+    def test_sends_full_config_when_delta_is_larger(self):
+        with_deltas({"dhcpd": b64(b"a\n")}, {})
+
+        result = with_deltas(
+            {"dhcpd": b64(b"b\n")}, {"dhcpd": content_hash(b"a\n")}
+        )
+
+        self.assertEqual(result["dhcpd"], b64(b"b\n"))
+        self.assertEqual(result["deltas"], {})
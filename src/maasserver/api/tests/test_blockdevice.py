@@ -1198,7 +1198,7 @@ class TestBlockDeviceAPIOpenFGAIntegration(
     OpenFGAMockMixin, APITestCase.ForUser
 ):
     def test_read_calls_openfga(self):
-        self.openfga_client.can_view_machines_in_pool.return_value = True
+        self.openfga_client.can_view_machine.return_value = True
 
         machine_owner = factory.make_User()
         node = factory.make_Node(owner=machine_owner)
@@ -1210,12 +1210,12 @@ class TestBlockDeviceAPIOpenFGAIntegration(
         self.assertEqual(
             http.client.OK, response.status_code, response.content
         )
-        self.openfga_client.can_view_machines_in_pool.assert_called_once_with(
-            self.user, node.pool.id
+        self.openfga_client.can_view_machine.assert_called_once_with(
+            self.user, node.id
         )
 
     def test_create_physicalblockdevice_calls_openfga(self):
-        self.openfga_client.can_edit_machines_in_pool.return_value = True
+        self.openfga_client.can_edit_machine.return_value = True
         node = factory.make_Node(with_boot_disk=False)
         uri = get_blockdevices_uri(node)
         response = self.client.post(
@@ -1232,12 +1232,12 @@ class TestBlockDeviceAPIOpenFGAIntegration(
         self.assertEqual(
             http.client.OK, response.status_code, response.content
         )
-        self.openfga_client.can_edit_machines_in_pool.assert_called_once_with(
-            self.user, node.pool.id
+        self.openfga_client.can_edit_machine.assert_called_once_with(
+            self.user, node.id
         )
 
     def test_format_calls_openfga(self):
-        self.openfga_client.can_edit_machines_in_pool.return_value = True
+        self.openfga_client.can_edit_machine.return_value = True
         node = factory.make_Node(status=NODE_STATUS.READY)
         block_device = factory.make_VirtualBlockDevice(node=node)
         fstype = factory.pick_filesystem_type()
@@ -1249,12 +1249,12 @@ class TestBlockDeviceAPIOpenFGAIntegration(
         self.assertEqual(
             http.client.OK, response.status_code, response.content
         )
-        self.openfga_client.can_edit_machines_in_pool.assert_called_once_with(
-            self.user, node.pool.id
+        self.openfga_client.can_edit_machine.assert_called_once_with(
+            self.user, node.id
         )
 
     def test_unformat_calls_openfga(self):
-        self.openfga_client.can_edit_machines_in_pool.return_value = True
+        self.openfga_client.can_edit_machine.return_value = True
         node = factory.make_Node(status=NODE_STATUS.READY)
         block_device = factory.make_VirtualBlockDevice(node=node)
         factory.make_Filesystem(block_device=block_device)
@@ -1263,12 +1263,12 @@ class TestBlockDeviceAPIOpenFGAIntegration(
         self.assertEqual(
             http.client.OK, response.status_code, response.content
         )
-        self.openfga_client.can_edit_machines_in_pool.assert_called_once_with(
-            self.user, node.pool.id
+        self.openfga_client.can_edit_machine.assert_called_once_with(
+            self.user, node.id
         )
 
     def test_mount_calls_openfga(self):
-        self.openfga_client.can_edit_machines_in_pool.return_value = True
+        self.openfga_client.can_edit_machine.return_value = True
         node = factory.make_Node(status=NODE_STATUS.READY)
         block_device = factory.make_VirtualBlockDevice(node=node)
         factory.make_Filesystem(block_device=block_device)
@@ -1287,6 +1287,6 @@ class TestBlockDeviceAPIOpenFGAIntegration(
         self.assertEqual(
             http.client.OK, response.status_code, response.content
         )
-        self.openfga_client.can_edit_machines_in_pool.assert_called_once_with(
-            self.user, node.pool.id
+        self.openfga_client.can_edit_machine.assert_called_once_with(
+            self.user, node.id
         )
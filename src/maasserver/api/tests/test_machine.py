@@ -4286,7 +4286,7 @@ class TestMachineHandlerOpenFGAIntegration(
         )
 
         p = factory.make_ResourcePool()
-        self.openfga_client.can_edit_machines_in_pool.return_value = True
+        self.openfga_client.can_edit_machine.return_value = True
 
         machine = factory.make_Node(
             status=NODE_STATUS.READY,
@@ -4310,7 +4310,7 @@ class TestMachineHandlerOpenFGAIntegration(
         )
 
         p = factory.make_ResourcePool()
-        self.openfga_client.can_edit_machines_in_pool.return_value = False
+        self.openfga_client.can_edit_machine.return_value = False
 
         machine = factory.make_Node(
             status=NODE_STATUS.READY,
@@ -3730,6 +3730,31 @@ class TestMachinesAPIOpenFGAIntegration(OpenFGAMockMixin, APITestCase.ForUser):
             self.user
         )
 
+    def test_read_filters_machines_by_tag(self):
+        pool = factory.make_ResourcePool()
+        tag = factory.make_Tag(definition="")
+        tagged = factory.make_Node(pool=pool)
+        tagged.tags.add(tag)
+        factory.make_Node(pool=pool)
+
+        self.openfga_client.list_pools_with_view_machines_access.return_value = []
+        self.openfga_client.list_pools_with_view_available_machines_access.return_value = []
+        self.openfga_client.list_pools_with_edit_machines_access.return_value = []
+        self.openfga_client.list_tags_with_view_available_machines_access.return_value = [
+            tag.id
+        ]
+
+        response = self.client.get(self.machines_url)
+
+        self.assertEqual(response.status_code, http.client.OK)
+        response_data = json.loads(
+            response.content.decode(settings.DEFAULT_CHARSET)
+        )
+        self.assertEqual(
+            [tagged.system_id],
+            [machine["system_id"] for machine in response_data],
+        )
+
     def test_read_filters_machines_owned_by_other_users(self):
         p1 = factory.make_ResourcePool()
         p2 = factory.make_ResourcePool()
@@ -513,6 +513,59 @@ class TestGetDetails(APITestCase.ForUser):
         self.assertEqual(http.client.FORBIDDEN, response.status_code)
 
 
+
+class TestGetSwitchPorts(APITestCase.ForUser):
+    """Tests for /api/2.0/nodes/<node>/?op=switch_ports."""
+
+    def get_switch_ports(self, node):
+        url = reverse("node_handler", args=[node.system_id])
+        return self.client.get(url, {"op": "switch_ports"})
+
+    def test_GET_returns_empty_list_without_lldp_capture(self):
+        node = factory.make_Node()
+        response = self.get_switch_ports(node)
+        self.assertEqual(http.client.OK, response.status_code)
+        self.assertEqual([], json_load_bytes(response.content))
+
+    def test_GET_returns_switch_ports(self):
+        node = factory.make_Node(with_empty_script_sets=True)
+        interface = factory.make_Interface(node=node, name="eth0")
+        script_set = node.current_commissioning_script_set
+        script_result = script_set.find_script_result(
+            script_name=LLDP_OUTPUT_NAME
+        )
+        script_result.store_result(
+            exit_status=0,
+            stdout=b"""\
+<lldp label="LLDP neighbors">
+  <interface label="Interface" name="eth0" via="LLDP">
+    <chassis label="Chassis">
+      <id label="ChassisID" type="local">tor-sw-01</id>
+    </chassis>
+    <port label="Port">
+      <id label="PortID" type="ifname">Ethernet12</id>
+    </port>
+  </interface>
+</lldp>
+""",
+        )
+        response = self.get_switch_ports(node)
+        self.assertEqual(http.client.OK, response.status_code)
+        [port] = json_load_bytes(response.content)
+        self.assertEqual(interface.id, port["interface_id"])
+        self.assertEqual("tor-sw-01", port["switch"]["chassis_id"])
+        self.assertEqual("Ethernet12", port["port"]["id"])
+
+    def test_GET_returns_not_found_when_node_does_not_exist(self):
+        url = reverse("node_handler", args=["does-not-exist"])
+        response = self.client.get(url, {"op": "switch_ports"})
+        self.assertEqual(http.client.NOT_FOUND, response.status_code)
+
+    def test_GET_returns_forbidden_for_non_owned_nodes(self):
+        node = factory.make_Node(owner=factory.make_User())
+        response = self.get_switch_ports(node)
+        self.assertEqual(http.client.FORBIDDEN, response.status_code)
+
 class TestPowerParameters(APITestCase.ForUser):
     def get_node_uri(self, node):
         """Get the API URI for `node`."""
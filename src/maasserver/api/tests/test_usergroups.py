@@ -855,6 +855,167 @@ class TestUserGroupAPI(APITestCase.ForUser):
             response.content,
         )
 
+    # grant_pools_entitlement
+    def test_grant_pools_entitlement_requires_admin(self):
+        group = factory.make_Usergroup()
+        pool = factory.make_ResourcePool()
+
+        response = self.client.post(
+            reverse("usergroup_handler", args=[group.id]),
+            {
+                "op": "grant_pools_entitlement",
+                "entitlement": "can_view_machines",
+                "all_pools": True,
+            },
+        )
+        self.assertEqual(
+            http.client.FORBIDDEN, response.status_code, response.content
+        )
+        self.assertIsNone(
+            get_openfga_tuple(
+                f"group:{group.id}#member",
+                "can_view_machines",
+                "pool",
+                str(pool.id),
+            )
+        )
+
+    def test_grant_pools_entitlement_by_name(self):
+        self.become_admin()
+        group = factory.make_Usergroup()
+        lab_a = factory.make_ResourcePool(name="lab-a")
+        lab_b = factory.make_ResourcePool(name="lab-b")
+        prod = factory.make_ResourcePool(name="prod")
+        factory.make_Entitlement(
+            group=group,
+            resource_type="pool",
+            resource_id=lab_a.id,
+            entitlement="can_deploy_machines",
+        )
+
+        response = self.client.post(
+            reverse("usergroup_handler", args=[group.id]),
+            {
+                "op": "grant_pools_entitlement",
+                "entitlement": "can_deploy_machines",
+                "name": "lab-*",
+            },
+        )
+        self.assertEqual(
+            http.client.OK, response.status_code, response.content
+        )
+        parsed = _parse(response)
+        self.assertEqual(parsed["matched"], 2)
+        self.assertEqual(parsed["granted"], [lab_b.id])
+        self.assertEqual(parsed["already_granted"], [lab_a.id])
+        self.assertIsNotNone(
+            get_openfga_tuple(
+                f"group:{group.id}#member",
+                "can_deploy_machines",
+                "pool",
+                str(lab_b.id),
+            )
+        )
+        self.assertIsNone(
+            get_openfga_tuple(
+                f"group:{group.id}#member",
+                "can_deploy_machines",
+                "pool",
+                str(prod.id),
+            )
+        )
+
+    def test_grant_pools_entitlement_by_tag(self):
+        self.become_admin()
+        group = factory.make_Usergroup()
+        tag = factory.make_Tag(definition="")
+        tagged_pool = factory.make_ResourcePool()
+        other_pool = factory.make_ResourcePool()
+        machine = factory.make_Machine(pool=tagged_pool)
+        machine.tags.add(tag)
+        factory.make_Machine(pool=other_pool)
+
+        response = self.client.post(
+            reverse("usergroup_handler", args=[group.id]),
+            {
+                "op": "grant_pools_entitlement",
+                "entitlement": "can_view_machines",
+                "tag": tag.name,
+            },
+        )
+        self.assertEqual(
+            http.client.OK, response.status_code, response.content
+        )
+        parsed = _parse(response)
+        self.assertEqual(parsed["matched"], 1)
+        self.assertEqual(parsed["granted"], [tagged_pool.id])
+        self.assertIsNotNone(
+            get_openfga_tuple(
+                f"group:{group.id}#member",
+                "can_view_machines",
+                "pool",
+                str(tagged_pool.id),
+            )
+        )
+        self.assertIsNone(
+            get_openfga_tuple(
+                f"group:{group.id}#member",
+                "can_view_machines",
+                "pool",
+                str(other_pool.id),
+            )
+        )
+
+    def test_grant_pools_entitlement_invalid_selector(self):
+        self.become_admin()
+        group = factory.make_Usergroup()
+
+        for selector in ({}, {"name": "lab-*", "tag": "gpu"}):
+            response = self.client.post(
+                reverse("usergroup_handler", args=[group.id]),
+                {
+                    "op": "grant_pools_entitlement",
+                    "entitlement": "can_view_machines",
+                    **selector,
+                },
+            )
+            self.assertEqual(
+                http.client.BAD_REQUEST,
+                response.status_code,
+                response.content,
+            )
+
+    def test_grant_pools_entitlement_invalid_entitlement(self):
+        self.become_admin()
+        group = factory.make_Usergroup()
+
+        response = self.client.post(
+            reverse("usergroup_handler", args=[group.id]),
+            {
+                "op": "grant_pools_entitlement",
+                "entitlement": "can_edit_identities",
+                "all_pools": True,
+            },
+        )
+        self.assertEqual(
+            http.client.BAD_REQUEST, response.status_code, response.content
+        )
+
+    def test_grant_pools_entitlement_group_not_found(self):
+        self.become_admin()
+
+        response = self.client.post(
+            reverse("usergroup_handler", args=[99999]),
+            {
+                "op": "grant_pools_entitlement",
+                "entitlement": "can_view_machines",
+                "all_pools": True,
+            },
+        )
+        self.assertEqual(
+            http.client.NOT_FOUND, response.status_code, response.content
+        )
+
 
 class TestUserGroupsOpenFGAIntegration(OpenFGAMockMixin, APITestCase.ForUser):
     def _create_group_as_admin(self, name, description="test"):
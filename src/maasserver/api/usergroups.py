@@ -3,6 +3,9 @@
 
 """API handlers: `UserGroup`."""
 
+from fnmatch import fnmatchcase
+
+from formencode.validators import StringBool
 from piston3.utils import rc
 
 from maasserver.api.support import (
@@ -10,6 +13,7 @@ from maasserver.api.support import (
     operation,
     OperationsHandler,
 )
+from maasserver.api.utils import get_optional_param
 from maasserver.exceptions import (
     MAASAPIBadRequest,
     MAASAPINotFound,
@@ -18,8 +22,13 @@ from maasserver.exceptions import (
 from maasserver.models import ResourcePool
 from maasserver.sqlalchemy import service_layer
 from maasservicelayer.builders.usergroups import UserGroupBuilder
+from maasservicelayer.db.filters import QuerySpec
+from maasservicelayer.db.repositories.resource_pools import (
+    ResourcePoolClauseFactory,
+)
 from maasservicelayer.services.openfga_tuples import (
     EntitlementsBuilderFactory,
+    PoolTupleBuilderFactory,
     UndefinedEntitlementError,
 )
 from maasservicelayer.services.usergroups import (
@@ -310,6 +319,84 @@ class UserGroupHandler(OperationsHandler):
         )
         return rc.ALL_OK
 
+    @operation(idempotent=False)
+    @check_permission("can_edit_identities")
+    def grant_pools_entitlement(self, request, id):
+        """@description Grants a pool entitlement to a user group on every
+        pool matching a selector, in one transaction.
+
+        Exactly one of ``name``, ``tag`` and ``all_pools`` must be given.
+
+        @param (url-string) "{id}" [required=true] A group ID.
+        @param (string) "entitlement" [required=true] The pool entitlement
+            name.
+        @param (string) "name" [required=false] Shell-style pattern matched
+            against the pool names (e.g. 'lab-*').
+        @param (string) "tag" [required=false] Name of a tag; selects the
+            pools holding at least one machine with the tag.
+        @param (boolean) "all_pools" [required=false] Whether to select
+            every pool.
+
+        @success (http-status-code) "server_success" 200
+        @success (json) "content_success" A JSON object with the number of
+            pools matched, and the IDs of the pools newly granted and of
+            those already granted.
+
+        @error (http-status-code) "400" 400
+        @error (content) "badrequest" Invalid request parameters.
+        @error (http-status-code) "404" 404
+        @error (content) "notfound" The group is not found.
+        """
+        group = service_layer.services.usergroups.get_by_id(int(id))
+        if group is None:
+            raise MAASAPINotFound(f"UserGroup with id {id} not found.")
+
+        entitlement = request.data.get("entitlement")
+        if not entitlement:
+            raise MAASAPIBadRequest("entitlement is required.")
+        is_valid, error_message = (
+            PoolTupleBuilderFactory.validate_entitlement(entitlement)
+        )
+        if not is_valid:
+            raise MAASAPIBadRequest(error_message)
+
+        name = request.data.get("name")
+        tag = request.data.get("tag")
+        all_pools = get_optional_param(
+            request.data, "all_pools", default=False, validator=StringBool
+        )
+        if [name is not None, tag is not None, all_pools].count(True) != 1:
+            raise MAASAPIBadRequest(
+                "Exactly one of name, tag and all_pools must be given."
+            )
+
+        if tag is not None:
+            query = QuerySpec(
+                where=ResourcePoolClauseFactory.with_machine_tag(tag)
+            )
+        else:
+            query = QuerySpec()
+        pools = service_layer.services.resource_pools.get_many(query)
+        pool_ids = [
+            pool.id
+            for pool in pools
+            if name is None or fnmatchcase(pool.name, name)
+        ]
+
+        granted = (
+            service_layer.services.openfga_tuples.grant_pools_entitlement(
+                int(id), entitlement, pool_ids
+            )
+        )
+        return {
+            "entitlement": entitlement,
+            "matched": len(pool_ids),
+            "granted": granted,
+            "already_granted": [
+                pool_id for pool_id in pool_ids if pool_id not in granted
+            ],
+        }
+
     @operation(idempotent=False)
     @check_permission("can_edit_identities")
     def remove_entitlement(self, request, id):
@@ -324,9 +324,7 @@ class MAASAuthorizationBackend(ModelBackend):
         return (
             machine.owner_id is None
             or machine.owner_id == user.id
-            or get_openfga_client().can_view_machines_in_pool(
-                user, machine.pool_id
-            )
+            or get_openfga_client().can_view_machine(user, machine.id)
         )
 
     def _can_edit(
@@ -343,8 +341,8 @@ class MAASAuthorizationBackend(ModelBackend):
                 or can_admin
             )
             return (editable and can_edit) or can_admin
-        return editable or get_openfga_client().can_edit_machines_in_pool(
-            user, machine.pool_id
+        return editable or get_openfga_client().can_edit_machine(
+            user, machine.id
         )
 
     def _can_admin(self, rbac_enabled, user, machine, admin_pools):
@@ -353,9 +351,7 @@ class MAASAuthorizationBackend(ModelBackend):
             return get_openfga_client().can_edit_machines(user)
         if rbac_enabled:
             return machine.pool_id in admin_pools
-        return get_openfga_client().can_edit_machines_in_pool(
-            user, machine.pool_id
-        )
+        return get_openfga_client().can_edit_machine(user, machine.id)
 
     def _perm_resource_pool(self, user, perm, rbac, visible_pools, obj=None):
         # `create` permissions is called without an `obj`.
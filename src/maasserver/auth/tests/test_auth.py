@@ -20,6 +20,7 @@ from maasserver.rbac import ALL_RESOURCES, FakeRBACClient, rbac
 from maasserver.secrets import SecretManager
 from maasserver.testing.factory import factory
 from maasserver.testing.fixtures import OpenFGAMock
+from maasserver.testing.openfga import OpenFGAClientMock
 from maasserver.testing.testcase import MAASServerTestCase
 from metadataserver.nodeinituser import get_node_init_user
 
@@ -88,6 +89,9 @@ class OpenFGAMockMixin:
     def setUp(self):
         super().setUp()
         self.openfga_client = MagicMock()
+        # Tags grant no permission unless a test says otherwise.
+        for method in OpenFGAClientMock.LIST_TAGS:
+            getattr(self.openfga_client, method).return_value = []
         self.useFixture(OpenFGAMock(client=self.openfga_client))
 
 
@@ -1635,7 +1639,7 @@ class TestMAASAuthorizationBackendInterfaceOpenFGAIntegration(
             self.assertTrue(backend.has_perm(user, perm, interface))
 
     def test_user_can_view_if_can_view_machines(self):
-        self.openfga_client.can_view_machines_in_pool.return_value = True
+        self.openfga_client.can_view_machine.return_value = True
 
         backend = MAASAuthorizationBackend()
         user = factory.make_User()
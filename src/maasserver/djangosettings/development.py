@@ -13,6 +13,7 @@ from maasserver.djangosettings import (
     import_settings,
     settings,
 )
+from maastesting.pgserver import ExternalCluster, get_test_pg_dsn
 
 # We expect the following settings to be overridden. They are mentioned here
 # to silence lint warnings: import_settings() below will actually re-set it
@@ -75,6 +76,12 @@ DATABASES = {
     }
 }
 
+# Tests may run against a PostgreSQL server they don't manage.
+if test_pg_dsn := get_test_pg_dsn():
+    DATABASES["default"].update(
+        ExternalCluster(test_pg_dsn).django_settings()
+    )
+
 INTERNAL_IPS = ("127.0.0.1", "::1")
 
 # Make all nodes' metadata visible.  This is not safe; do not enable it
@@ -277,6 +277,8 @@ MIDDLEWARE = (
     "maasserver.middleware.AccessMiddleware",
     # Extract the tracing header from the request if present
     "maasserver.middleware.TracingMiddleware",
+    # Track the time budget of the request if given
+    "maasserver.middleware.DeadlineMiddleware",
     # Sets X-Frame-Options header to SAMEORIGIN.
     "django.middleware.clickjacking.XFrameOptionsMiddleware",
 )
@@ -41,7 +41,7 @@ class Command(BaseCommand):
             action="store",
             dest="openfga_path",
             default="/usr/sbin/",
-            help=("The path to the openfga migrator binaries."),
+            help=("The path to the maas-openfga binary."),
         )
 
     @classmethod
@@ -261,7 +261,9 @@ class Command(BaseCommand):
             cursor.execute("CREATE SCHEMA IF NOT EXISTS openfga;")
 
         cmd = [
-            get_path(openfga_path + "/maas-openfga-migrator"),
+            get_path(openfga_path + "/maas-openfga"),
+            "migrate",
+            "datastore",
             uri,
         ]
 
@@ -276,7 +278,9 @@ class Command(BaseCommand):
     def _openfga_app_migration(self, openfga_path, uri):
         print("Running OpenFGA model migrations:")
         cmd = [
-            get_path(openfga_path + "/maas-openfga-app-migrator"),
+            get_path(openfga_path + "/maas-openfga"),
+            "migrate",
+            "app",
             uri,
         ]
 
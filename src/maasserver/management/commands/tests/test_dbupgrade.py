@@ -10,6 +10,7 @@ from subprocess import PIPE, Popen, STDOUT
 from postgresfixture import ClusterFixture
 from testtools.content import Content, UTF8_TEXT
 
+from maascommon.enums.openfga import OPENFGA_AUTHORIZATION_MODEL_ID
 from maasserver.testing.config import RegionConfigurationFixture
 from maastesting import dev_root
 from maastesting.fixtures import TempDirectory
@@ -161,6 +162,15 @@ class TestDBUpgrade(MAASTestCase):
                     WHERE authorization_model_id = '00000000000000000000000000';
                 """)
                 self.assertIsNotNone(cursor.fetchone())
+                cursor.execute(
+                    """
+                    SELECT authorization_model_id
+                    FROM openfga.authorization_model
+                    WHERE authorization_model_id = %s;
+                """,
+                    [OPENFGA_AUTHORIZATION_MODEL_ID],
+                )
+                self.assertIsNotNone(cursor.fetchone())
 
     def test_dbupgrade_executes_also_django_migrations_if_upgrading_from_older_versions(
         self,
@@ -26,6 +26,12 @@ from django.urls import get_resolver, get_urlconf, reverse
 from django.utils.encoding import force_str
 
 from maascommon.logging.security import ADMIN, AUTHZ_FAIL, SECURITY, USER
+from maascommon.deadline import (
+    parse_request_timeout,
+    REQUEST_DEADLINE,
+    REQUEST_TIMEOUT_HEADER,
+    set_request_timeout,
+)
 from maascommon.tracing import get_trace_id, set_trace_id
 from maasserver import openfga
 from maasserver.authorization import clear_caches
@@ -89,6 +95,27 @@ class TracingMiddleware:
         return response
 
 
+class DeadlineMiddleware:
+    """Track the time budget given by the caller in MAAS-Request-Timeout.
+
+    Downstream calls, like authorization checks, are abandoned once it has
+    been exhausted.
+    """
+
+    def __init__(self, get_response):
+        self.get_response = get_response
+
+    def __call__(self, request):
+        token = set_request_timeout(
+            parse_request_timeout(request.headers.get(REQUEST_TIMEOUT_HEADER))
+        )
+        try:
+            return self.get_response(request)
+        finally:
+            # Threads are reused across requests.
+            REQUEST_DEADLINE.reset(token)
+
+
 def is_public_path(path):
     """Whether a request.path is publicly accessible."""
     return any(path.startswith(prefix) for prefix in PUBLIC_URL_PREFIXES)
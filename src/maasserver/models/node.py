@@ -563,40 +563,46 @@ class BaseNodeManager(Manager, NodeQueriesMixin):
                     | Q(node_type=NODE_TYPE.DEVICE, owner=user)
                 )
         else:
-            view_all_pools = (
-                get_openfga_client().list_pools_with_view_machines_access(user)
-            )
-            visible_pools = get_openfga_client().list_pools_with_view_available_machines_access(
-                user
+            # Machines inherit permissions from both their pool and their
+            # tags.
+            openfga_client = get_openfga_client()
+            view_all = self._in_pools_or_tags(
+                openfga_client.list_pools_with_view_machines_access(user),
+                openfga_client.list_tags_with_view_machines_access(user),
+            )
+            visible = self._in_pools_or_tags(
+                openfga_client.list_pools_with_view_available_machines_access(
+                    user
+                ),
+                openfga_client.list_tags_with_view_available_machines_access(
+                    user
+                ),
             )
             if perm == NodePermission.view:
-                # visible pools: free and own machines.
+                # visible pools and tags: free and own machines.
                 condition = Q(
-                    Q(Q(owner__isnull=True) | Q(owner=user))
-                    & Q(pool_id__in=visible_pools)
+                    Q(Q(owner__isnull=True) | Q(owner=user)) & visible
                 )
-                # view all pools: all machines
-                condition |= Q(pool_id__in=view_all_pools)
+                # view all pools and tags: all machines
+                condition |= view_all
             elif perm == NodePermission.edit:
-                deploy_pools = (
-                    get_openfga_client().list_pool_with_deploy_machines_access(
-                        user
-                    )
+                deploy = self._in_pools_or_tags(
+                    openfga_client.list_pool_with_deploy_machines_access(user),
+                    openfga_client.list_tags_with_deploy_machines_access(user),
                 )
-                # deploy pools: free and own machines.
+                # deploy pools and tags: free and own machines.
                 condition = Q(
-                    Q(Q(owner__isnull=True) | Q(owner=user))
-                    & Q(pool_id__in=deploy_pools)
+                    Q(Q(owner__isnull=True) | Q(owner=user)) & deploy
                 )
             elif perm == NodePermission.admin:
                 # There is no built-in Q object that represents False, but
                 # this one does.
                 condition = Q(pool_id__in=[])
 
-            admin_pools = (
-                get_openfga_client().list_pools_with_edit_machines_access(user)
+            condition |= self._in_pools_or_tags(
+                openfga_client.list_pools_with_edit_machines_access(user),
+                openfga_client.list_tags_with_edit_machines_access(user),
             )
-            condition |= Q(pool_id__in=admin_pools)
             condition = Q(Q(node_type=NODE_TYPE.MACHINE) & condition)
             if get_openfga_client().can_view_devices(user):
                 condition |= Q(
@@ -617,6 +623,17 @@ class BaseNodeManager(Manager, NodeQueriesMixin):
                 )
         return nodes.filter(condition)
 
+    def _in_pools_or_tags(self, pool_ids, tag_ids):
+        """Match the nodes in any of the pools or having any of the tags."""
+        condition = Q(pool_id__in=pool_ids)
+        if tag_ids:
+            condition |= Q(
+                id__in=Node.tags.through.objects.filter(
+                    tag_id__in=tag_ids
+                ).values("node_id")
+            )
+        return condition
+
     def get_nodes(self, user, perm, ids=None, from_nodes=None):
         """Fetch Nodes on which the User_ has the given permission.
 
@@ -17,6 +17,8 @@ if TYPE_CHECKING:
 
 from django.db import connection
 
+from maascommon.fields import normalise_macaddress
+from maasserver.utils.lldp import parse_lldp_neighbours
 from metadataserver.enum import SCRIPT_STATUS
 from provisioningserver.refresh.node_info_scripts import (
     LLDP_OUTPUT_NAME,
@@ -26,6 +28,7 @@ from provisioningserver.refresh.node_info_scripts import (
 __all__ = [
     "get_probed_details",
     "get_single_probed_details",
+    "get_switch_ports",
     "script_output_nsmap",
 ]
 
@@ -105,3 +108,73 @@ def get_probed_details(nodes: list[Node]) -> dict[str, dict[str, bytes]]:
             stdout_decoded = base64.b64decode(stdout)
             ret[system_id][namespace] = stdout_decoded
     return ret
+
+
+def get_switch_ports(node: Node) -> list[dict]:
+    """Return the switch ports the interfaces of the node are plugged into.
+
+    They're read from the LLDP capture of the last commissioning. A switch
+    advertising a MAC address as its chassis ID is also matched to the node
+    owning an interface with that address, if MAAS knows of one.
+
+    :return: A list of ``{"interface": ..., "mac_address": ..., "switch":
+        {...}, "port": {...}, "vlans": [...]}`` entries, one per interface
+        with an LLDP neighbour.
+    """
+    # Avoid circular imports.
+    from maasserver.models.interface import Interface
+
+    lldp = get_single_probed_details(node)["lldp"]
+    if not lldp:
+        return []
+    neighbours = parse_lldp_neighbours(lldp)
+    interfaces = {
+        interface.name: interface
+        for interface in node.current_config.interface_set.all()
+    }
+    chassis_macs = {
+        normalise_macaddress(neighbour.chassis_id)
+        for neighbour in neighbours
+        if neighbour.chassis_id_type == "mac" and neighbour.chassis_id
+    }
+    switches = {
+        interface.mac_address: interface.node_config.node.system_id
+        for interface in Interface.objects.filter(
+            mac_address__in=chassis_macs, node_config__isnull=False
+        )
+        .exclude(node_config__node=node)
+        .select_related("node_config__node")
+    }
+    ports = []
+    for neighbour in neighbours:
+        interface = interfaces.get(neighbour.interface)
+        switch_system_id = None
+        if neighbour.chassis_id_type == "mac" and neighbour.chassis_id:
+            switch_system_id = switches.get(
+                normalise_macaddress(neighbour.chassis_id)
+            )
+        ports.append(
+            {
+                "interface": neighbour.interface,
+                "interface_id": None if interface is None else interface.id,
+                "mac_address": (
+                    None if interface is None else interface.mac_address
+                ),
+                "switch": {
+                    "chassis_id": neighbour.chassis_id,
+                    "chassis_id_type": neighbour.chassis_id_type,
+                    "name": neighbour.system_name,
+                    "management_addresses": list(
+                        neighbour.management_addresses
+                    ),
+                    "system_id": switch_system_id,
+                },
+                "port": {
+                    "id": neighbour.port_id,
+                    "id_type": neighbour.port_id_type,
+                    "description": neighbour.port_description,
+                },
+                "vlans": list(neighbour.vlans),
+            }
+        )
+    return ports
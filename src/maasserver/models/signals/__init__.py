@@ -25,6 +25,7 @@ __all__ = [
     "services",
     "staticipaddress",
     "subnet",
+    "tags",
     "users",
     "vlan",
 ]
@@ -51,6 +52,7 @@ from maasserver.models.signals import (
     services,
     staticipaddress,
     subnet,
+    tags,
     users,
     vlan,
 )
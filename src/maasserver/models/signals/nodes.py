@@ -4,6 +4,7 @@
 """Respond to node changes."""
 
 from django.db.models.signals import (
+    m2m_changed,
     post_delete,
     post_init,
     post_save,
@@ -12,7 +13,7 @@ from django.db.models.signals import (
 )
 
 from maascommon.enums.dns import DnsUpdateAction
-from maasserver.enum import NODE_STATUS
+from maasserver.enum import NODE_STATUS, NODE_TYPE
 from maasserver.models import (
     Controller,
     Device,
@@ -27,6 +28,7 @@ from maasserver.models import (
 from maasserver.models.nodeconfig import create_default_nodeconfig
 from maasserver.models.nodekey import NodeKey
 from maasserver.models.numa import create_default_numanode
+from maasserver.sqlalchemy import service_layer
 from maasserver.utils.signals import SignalsManager
 from provisioningserver.enum import POWER_STATE
 
@@ -231,5 +233,69 @@ for klass in [Node, Machine]:
     signals.watch_fields(release_auto_ips, klass, ["power_state"])
 
 
+def update_openfga_machine_pool(node, old_values, deleted=False):
+    """Keep the pool machines inherit permissions from up to date."""
+    old_pool_id, old_node_type = old_values
+    if deleted or not node.is_machine:
+        if old_node_type == NODE_TYPE.MACHINE:
+            service_layer.services.openfga_tuples.delete_machine(node.id)
+        return
+    service_layer.services.openfga_tuples.set_machine_pool(
+        node.id, node.pool_id
+    )
+    if old_node_type != NODE_TYPE.MACHINE:
+        # The node became a machine, link it to the tags it already has.
+        service_layer.services.openfga_tuples.add_machine_tags(
+            node.id, list(node.tags.values_list("id", flat=True))
+        )
+
+
+for klass in NODE_CLASSES:
+    signals.watch_fields(
+        update_openfga_machine_pool,
+        klass,
+        ["pool_id", "node_type"],
+        delete=True,
+    )
+
+
+def update_openfga_machine_tags(
+    sender, instance, action, reverse, pk_set, **kwargs
+):
+    """Link machines to their tags, so that tag permissions apply to them."""
+    openfga_tuples = service_layer.services.openfga_tuples
+    if not reverse:
+        # `instance` is a node and `pk_set` the ids of the tags.
+        if not instance.is_machine:
+            return
+        if action == "post_add":
+            openfga_tuples.add_machine_tags(instance.id, list(pk_set))
+        elif action == "post_remove":
+            openfga_tuples.remove_machine_tags(instance.id, list(pk_set))
+        elif action == "pre_clear":
+            openfga_tuples.remove_machine_tags(instance.id)
+        return
+
+    # `instance` is a tag and `pk_set` the ids of the nodes.
+    if action == "pre_clear":
+        machines = instance.node_set.filter(node_type=NODE_TYPE.MACHINE)
+    elif action in ("post_add", "post_remove"):
+        machines = Node.objects.filter(
+            id__in=pk_set, node_type=NODE_TYPE.MACHINE
+        )
+    else:
+        return
+    for machine_id in machines.values_list("id", flat=True):
+        if action == "post_add":
+            openfga_tuples.add_machine_tags(machine_id, [instance.id])
+        else:
+            openfga_tuples.remove_machine_tags(machine_id, [instance.id])
+
+
+signals.watch(
+    m2m_changed, update_openfga_machine_tags, sender=Node.tags.through
+)
+
+
 # Enable all signals by default.
 signals.enable()
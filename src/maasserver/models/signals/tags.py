@@ -0,0 +1,31 @@
+# Copyright 2026 Canonical Ltd.  This software is licensed under the
+# GNU Affero General Public License version 3 (see the file LICENSE).
+
+"""Respond to Tag changes."""
+
+from django.db.models.signals import post_delete, post_save
+
+from maasserver.models import Tag
+from maasserver.sqlalchemy import service_layer
+from maasserver.utils.signals import SignalsManager
+from maasservicelayer.builders.openfga_tuple import OpenFGATupleBuilder
+
+signals = SignalsManager()
+
+
+def post_created_tag(sender, instance, created, **kwargs):
+    if created:
+        service_layer.services.openfga_tuples.upsert(
+            OpenFGATupleBuilder.build_tag(str(instance.id))
+        )
+
+
+def post_delete_tag(sender, instance, **kwargs):
+    service_layer.services.openfga_tuples.delete_tag(instance.id)
+
+
+signals.watch(post_save, post_created_tag, sender=Tag)
+signals.watch(post_delete, post_delete_tag, sender=Tag)
+
+# Enable all signals by default.
+signals.enable()
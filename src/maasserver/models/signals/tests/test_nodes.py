@@ -4,6 +4,8 @@
 
 import random
 
+from django.db import connection
+
 from maasserver.enum import (
     IPADDRESS_TYPE,
     NODE_STATUS,
@@ -415,3 +417,77 @@ class TestNodeReleasesAutoIPs(MAASServerTestCase):
             interface__node_config__node=node, alloc_type=IPADDRESS_TYPE.AUTO
         ):
             self.assertIsNotNone(ip.ip)
+
+
+def get_openfga_machine_tuples(machine_id):
+    with connection.cursor() as cursor:
+        cursor.execute(
+            "SELECT _user, relation FROM openfga.tuple "
+            "WHERE object_type = 'machine' AND object_id = %s",
+            [str(machine_id)],
+        )
+        return set(cursor.fetchall())
+
+
+class TestNodeOpenFGATuples(MAASServerTestCase):
+    def test_create_links_machine_to_pool(self):
+        machine = factory.make_Machine()
+        self.assertEqual(
+            {(f"pool:{machine.pool_id}", "pool")},
+            get_openfga_machine_tuples(machine.id),
+        )
+
+    def test_pool_change_updates_tuple(self):
+        machine = factory.make_Machine()
+        pool = factory.make_ResourcePool()
+        machine.pool = pool
+        machine.save()
+        self.assertEqual(
+            {(f"pool:{pool.id}", "pool")},
+            get_openfga_machine_tuples(machine.id),
+        )
+
+    def test_delete_removes_tuples(self):
+        machine = factory.make_Machine()
+        machine.tags.add(factory.make_Tag())
+        machine_id = machine.id
+        machine.delete()
+        self.assertEqual(set(), get_openfga_machine_tuples(machine_id))
+
+    def test_no_tuples_for_devices(self):
+        device = factory.make_Device()
+        device.tags.add(factory.make_Tag())
+        self.assertEqual(set(), get_openfga_machine_tuples(device.id))
+
+    def test_tags_added_and_removed(self):
+        machine = factory.make_Machine()
+        tag1 = factory.make_Tag()
+        tag2 = factory.make_Tag()
+        machine.tags.add(tag1, tag2)
+        machine.tags.remove(tag1)
+        self.assertEqual(
+            {(f"pool:{machine.pool_id}", "pool"), (f"tag:{tag2.id}", "tag")},
+            get_openfga_machine_tuples(machine.id),
+        )
+        machine.tags.clear()
+        self.assertEqual(
+            {(f"pool:{machine.pool_id}", "pool")},
+            get_openfga_machine_tuples(machine.id),
+        )
+
+    def test_machines_added_and_removed_from_tag(self):
+        machine1 = factory.make_Machine()
+        machine2 = factory.make_Machine()
+        tag = factory.make_Tag()
+        tag.node_set.add(machine1, machine2)
+        tag.node_set.remove(machine1)
+        self.assertNotIn(
+            (f"tag:{tag.id}", "tag"), get_openfga_machine_tuples(machine1.id)
+        )
+        self.assertIn(
+            (f"tag:{tag.id}", "tag"), get_openfga_machine_tuples(machine2.id)
+        )
+        tag.node_set.clear()
+        self.assertNotIn(
+            (f"tag:{tag.id}", "tag"), get_openfga_machine_tuples(machine2.id)
+        )
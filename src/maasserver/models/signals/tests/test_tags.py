@@ -0,0 +1,42 @@
+# Copyright 2026 Canonical Ltd.  This software is licensed under the
+# GNU Affero General Public License version 3 (see the file LICENSE).
+
+"""Test the behaviour of tag signals."""
+
+from django.db import connection
+
+from maasserver.testing.factory import factory
+from maasserver.testing.testcase import MAASServerTestCase
+
+
+class TestPostSaveTagSignal(MAASServerTestCase):
+    def test_save_creates_openfga_tuple(self):
+        tag = factory.make_Tag()
+
+        with connection.cursor() as cursor:
+            cursor.execute(
+                "SELECT _user, relation FROM openfga.tuple WHERE object_type = 'tag' AND object_id = '%s'",
+                [tag.id],
+            )
+            openfga_tuple = cursor.fetchone()
+
+        self.assertEqual("maas:0", openfga_tuple[0])
+        self.assertEqual("parent", openfga_tuple[1])
+
+
+class TestPostDeleteTagSignal(MAASServerTestCase):
+    def test_delete_removes_openfga_tuples(self):
+        tag = factory.make_Tag()
+        tag_id = tag.id
+        factory.make_Machine().tags.add(tag)
+
+        tag.delete()
+
+        with connection.cursor() as cursor:
+            cursor.execute(
+                "SELECT object_type, object_id, relation FROM openfga.tuple WHERE _user = 'tag:%s' OR (object_type = 'tag' AND object_id = '%s')",
+                [tag_id, tag_id],
+            )
+            openfga_tuple = cursor.fetchone()
+
+        self.assertIsNone(openfga_tuple)
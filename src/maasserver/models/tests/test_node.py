@@ -13107,7 +13107,7 @@ class TestNodeOpenFGAIntegration(OpenFGAMockMixin, MAASServerTestCase):
         (
             "view_other_denied",
             {
-                "fga_method": "can_view_machines_in_pool",
+                "fga_method": "can_view_machine",
                 "fga_return": False,
                 "owned_by_user": False,
                 "permission": NodePermission.view,
@@ -13117,7 +13117,7 @@ class TestNodeOpenFGAIntegration(OpenFGAMockMixin, MAASServerTestCase):
         (
             "view_owned_allowed",
             {
-                "fga_method": "can_view_machines_in_pool",
+                "fga_method": "can_view_machine",
                 "fga_return": False,
                 "owned_by_user": True,
                 "permission": NodePermission.view,
@@ -13127,7 +13127,7 @@ class TestNodeOpenFGAIntegration(OpenFGAMockMixin, MAASServerTestCase):
         (
             "view_other_allowed_by_fga",
             {
-                "fga_method": "can_view_machines_in_pool",
+                "fga_method": "can_view_machine",
                 "fga_return": True,
                 "owned_by_user": False,
                 "permission": NodePermission.view,
@@ -13138,7 +13138,7 @@ class TestNodeOpenFGAIntegration(OpenFGAMockMixin, MAASServerTestCase):
         (
             "edit_other_denied",
             {
-                "fga_method": "can_edit_machines_in_pool",
+                "fga_method": "can_edit_machine",
                 "fga_return": False,
                 "owned_by_user": False,
                 "permission": NodePermission.edit,
@@ -13148,7 +13148,7 @@ class TestNodeOpenFGAIntegration(OpenFGAMockMixin, MAASServerTestCase):
         (
             "edit_owned_allowed",
             {
-                "fga_method": "can_edit_machines_in_pool",
+                "fga_method": "can_edit_machine",
                 "fga_return": False,
                 "owned_by_user": True,
                 "permission": NodePermission.edit,
@@ -13158,7 +13158,7 @@ class TestNodeOpenFGAIntegration(OpenFGAMockMixin, MAASServerTestCase):
         (
             "edit_other_allowed_by_fga",
             {
-                "fga_method": "can_edit_machines_in_pool",
+                "fga_method": "can_edit_machine",
                 "fga_return": True,
                 "owned_by_user": False,
                 "permission": NodePermission.edit,
@@ -13169,7 +13169,7 @@ class TestNodeOpenFGAIntegration(OpenFGAMockMixin, MAASServerTestCase):
         (
             "admin_other_denied",
             {
-                "fga_method": "can_edit_machines_in_pool",
+                "fga_method": "can_edit_machine",
                 "fga_return": False,
                 "owned_by_user": False,
                 "permission": NodePermission.admin,
@@ -13179,7 +13179,7 @@ class TestNodeOpenFGAIntegration(OpenFGAMockMixin, MAASServerTestCase):
         (
             "admin_owned_denied",
             {
-                "fga_method": "can_edit_machines_in_pool",
+                "fga_method": "can_edit_machine",
                 "fga_return": False,
                 "owned_by_user": True,
                 "permission": NodePermission.admin,
@@ -13189,7 +13189,7 @@ class TestNodeOpenFGAIntegration(OpenFGAMockMixin, MAASServerTestCase):
         (
             "admin_allowed_by_fga",
             {
-                "fga_method": "can_edit_machines_in_pool",
+                "fga_method": "can_edit_machine",
                 "fga_return": True,
                 "owned_by_user": False,
                 "permission": NodePermission.admin,
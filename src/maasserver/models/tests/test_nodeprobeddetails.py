@@ -6,6 +6,7 @@
 from maasserver.models.nodeprobeddetails import (
     get_probed_details,
     get_single_probed_details,
+    get_switch_ports,
     script_output_nsmap,
 )
 from maasserver.testing.factory import factory
@@ -100,3 +101,86 @@ class TestNodeDetail(MAASServerTestCase):
             # returned by get_probed_details.
             self.make_script_set_and_results(node, "new")
         self.assertDictEqual(expected, get_probed_details(nodes))
+
+
+LLDP_OUTPUT = b"""\
+<?xml version="1.0" encoding="UTF-8"?>
+<lldp label="LLDP neighbors">
+  <interface label="Interface" name="%(name)s" via="LLDP">
+    <chassis label="Chassis">
+      <id label="ChassisID" type="mac">%(chassis)s</id>
+      <name label="SysName">tor-sw-01</name>
+      <mgmt-ip label="MgmtIP">10.0.0.2</mgmt-ip>
+    </chassis>
+    <port label="Port">
+      <id label="PortID" type="ifname">Ethernet12</id>
+      <descr label="PortDescr">rack 4 u12</descr>
+    </port>
+    <vlan label="VLAN" vlan-id="100" pvid="yes">v100</vlan>
+  </interface>
+</lldp>
+"""
+
+
+class TestGetSwitchPorts(MAASServerTestCase):
+    def store_lldp(self, node, name, chassis):
+        script_set = node.current_commissioning_script_set
+        script_result = script_set.find_script_result(
+            script_name=LLDP_OUTPUT_NAME
+        )
+        script_result.store_result(
+            exit_status=0,
+            stdout=LLDP_OUTPUT
+            % {b"name": name.encode(), b"chassis": chassis.encode()},
+        )
+
+    def test_returns_nothing_without_lldp_capture(self):
+        node = factory.make_Node()
+        self.assertEqual([], get_switch_ports(node))
+
+    def test_returns_switch_ports(self):
+        node = factory.make_Node(with_empty_script_sets=True)
+        interface = factory.make_Interface(node=node, name="eth0")
+        chassis = factory.make_mac_address()
+        self.store_lldp(node, "eth0", chassis)
+        self.assertEqual(
+            [
+                {
+                    "interface": "eth0",
+                    "interface_id": interface.id,
+                    "mac_address": interface.mac_address,
+                    "switch": {
+                        "chassis_id": chassis,
+                        "chassis_id_type": "mac",
+                        "name": "tor-sw-01",
+                        "management_addresses": ["10.0.0.2"],
+                        "system_id": None,
+                    },
+                    "port": {
+                        "id": "Ethernet12",
+                        "id_type": "ifname",
+                        "description": "rack 4 u12",
+                    },
+                    "vlans": [100],
+                }
+            ],
+            get_switch_ports(node),
+        )
+
+    def test_matches_switch_by_chassis_mac(self):
+        node = factory.make_Node(with_empty_script_sets=True)
+        factory.make_Interface(node=node, name="eth0")
+        switch = factory.make_Node()
+        switch_interface = factory.make_Interface(node=switch)
+        self.store_lldp(node, "eth0", switch_interface.mac_address.upper())
+        [port] = get_switch_ports(node)
+        self.assertEqual(switch.system_id, port["switch"]["system_id"])
+
+    def test_reports_unknown_interfaces(self):
+        node = factory.make_Node(with_empty_script_sets=True)
+        self.store_lldp(node, "eth9", factory.make_mac_address())
+        [port] = get_switch_ports(node)
+        self.assertEqual("eth9", port["interface"])
+        self.assertIsNone(port["interface_id"])
+        self.assertIsNone(port["mac_address"])
+
@@ -12,6 +12,8 @@ class OpenFGAClientMock(SyncOpenFGAClient):
         "can_edit_machines",
         "can_edit_machines_in_pool",
         "can_view_machines_in_pool",
+        "can_edit_machine",
+        "can_view_machine",
         "can_edit_global_entities",
         "can_view_controllers",
         "can_edit_controllers",
@@ -33,6 +35,8 @@ class OpenFGAClientMock(SyncOpenFGAClient):
     ALWAYS_ALLOWED = [
         "can_deploy_machines_in_pool",
         "can_view_available_machines_in_pool",
+        "can_deploy_machine",
+        "can_view_available_machine",
         "can_view_global_entities",
     ]
 
@@ -48,6 +52,14 @@ class OpenFGAClientMock(SyncOpenFGAClient):
         "list_pool_with_deploy_machines_access",
     ]
 
+    # Methods returning tags, which grant nothing: pools already do
+    LIST_TAGS = [
+        "list_tags_with_view_machines_access",
+        "list_tags_with_view_available_machines_access",
+        "list_tags_with_deploy_machines_access",
+        "list_tags_with_edit_machines_access",
+    ]
+
     def __init__(self, *args, **kwargs):
         self.client = None
         self._bind_methods()
@@ -77,6 +89,9 @@ class OpenFGAClientMock(SyncOpenFGAClient):
         for method in self.LIST_ALWAYS_ALLOWED:
             setattr(self, method, lambda user: self._get_resource_pools())
 
+        for method in self.LIST_TAGS:
+            setattr(self, method, lambda user: [])
+
     def _get_resource_pools(self) -> list[int]:
         with connection.cursor() as cursor:
             cursor.execute(
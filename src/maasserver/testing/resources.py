@@ -17,6 +17,8 @@ from psycopg2.errorcodes import DUPLICATE_DATABASE
 from psycopg2.errors import ObjectInUse
 from testresources import TestResourceManager
 
+from maastesting.pgserver import ExternalCluster, get_test_pg_dsn
+
 here = Path(__file__).parent
 
 
@@ -59,6 +61,10 @@ def connect_no_transaction(cluster):
 
 
 def create_postgres_cluster():
+    dsn = get_test_pg_dsn()
+    if dsn:
+        return ExternalCluster(dsn)
+
     cluster = ClusterFixture("db", preserve=True)
     cluster.create()
     postgres_path = Path(cluster.datadir)
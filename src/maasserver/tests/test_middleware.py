@@ -11,6 +11,7 @@ from django.contrib.auth.models import AnonymousUser
 from django.core.exceptions import PermissionDenied, ValidationError
 from django.http import HttpResponse
 
+from maascommon.deadline import get_remaining_time
 from maascommon.logging.security import AUTHZ_FAIL, SECURITY
 from maascommon.tracing import set_trace_id
 from maasserver import middleware as middleware_module
@@ -20,6 +21,7 @@ from maasserver.middleware import (
     APIRPCErrorsMiddleware,
     AuthorizationCacheMiddleware,
     CSRFHelperMiddleware,
+    DeadlineMiddleware,
     DebuggingLoggerMiddleware,
     ExceptionMiddleware,
     ExternalAuthInfoMiddleware,
@@ -52,6 +54,32 @@ class TestIsPublicPath(MAASServerTestCase):
         self.assertFalse(is_public_path("/MAAS/"))
 
 
+class TestDeadlineMiddleware(MAASServerTestCase):
+    def process_request(self, request):
+        remaining = []
+
+        def get_response(_):
+            remaining.append(get_remaining_time())
+            return HttpResponse(status=200)
+
+        DeadlineMiddleware(get_response)(request)
+        return remaining[0]
+
+    def test_deadline_is_taken_from_request(self):
+        request = factory.make_fake_request(
+            "/", headers={"MAAS-Request-Timeout": "5000"}
+        )
+        remaining = self.process_request(request)
+        self.assertGreater(remaining, 4)
+        self.assertLessEqual(remaining, 5)
+        # The deadline does not leak to the next request on the thread.
+        self.assertIsNone(get_remaining_time())
+
+    def test_no_deadline(self):
+        request = factory.make_fake_request("/")
+        self.assertIsNone(self.process_request(request))
+
+
 class TestTracingMiddleware(MAASServerTestCase):
     def process_request(self, request, response=None):
         def get_response(_):
@@ -0,0 +1,84 @@
+# Copyright 2026 Canonical Ltd.  This software is licensed under the
+# GNU Affero General Public License version 3 (see the file LICENSE).
+
+"""Parse the LLDP neighbours captured during commissioning.
+
+The capture is the XML output of ``lldpctl -f xml``: one ``interface``
+element per local interface that received LLDP frames, describing the
+chassis and the port of the neighbour on that link.
+"""
+
+from dataclasses import dataclass, field
+
+from lxml import etree
+
+
+@dataclass(frozen=True)
+class LLDPNeighbour:
+    """The device at the other end of a link, as it advertises itself."""
+
+    # Name of the local interface the neighbour was seen on.
+    interface: str
+    chassis_id: str | None = None
+    # How chassis_id is to be read, e.g. "mac" or "local".
+    chassis_id_type: str | None = None
+    system_name: str | None = None
+    management_addresses: tuple[str, ...] = field(default_factory=tuple)
+    port_id: str | None = None
+    # How port_id is to be read, e.g. "ifname" or "mac".
+    port_id_type: str | None = None
+    port_description: str | None = None
+    vlans: tuple[int, ...] = field(default_factory=tuple)
+
+
+def _text(element, path):
+    text = element.findtext(path)
+    if text is None:
+        return None
+    return text.strip() or None
+
+
+def _vlans(element):
+    vlans = []
+    for vlan in element.findall("vlan"):
+        try:
+            vlans.append(int(vlan.get("vlan-id")))
+        except (TypeError, ValueError):
+            continue
+    return tuple(vlans)
+
+
+def parse_lldp_neighbours(xml: bytes) -> list[LLDPNeighbour]:
+    """Return the neighbours found in an ``lldpctl -f xml`` capture.
+
+    Interfaces without a name are skipped, as there's no telling which
+    link they describe.
+    """
+    root = etree.fromstring(xml.strip())
+    neighbours = []
+    for interface in root.findall("interface"):
+        name = interface.get("name")
+        if not name:
+            continue
+        chassis_id = interface.find("chassis/id")
+        port_id = interface.find("port/id")
+        neighbours.append(
+            LLDPNeighbour(
+                interface=name,
+                chassis_id=_text(interface, "chassis/id"),
+                chassis_id_type=(
+                    None if chassis_id is None else chassis_id.get("type")
+                ),
+                system_name=_text(interface, "chassis/name"),
+                management_addresses=tuple(
+                    address.text.strip()
+                    for address in interface.findall("chassis/mgmt-ip")
+                    if address.text and address.text.strip()
+                ),
+                port_id=_text(interface, "port/id"),
+                port_id_type=None if port_id is None else port_id.get("type"),
+                port_description=_text(interface, "port/descr"),
+                vlans=_vlans(interface),
+            )
+        )
+    return neighbours
@@ -0,0 +1,93 @@
+# Copyright 2026 Canonical Ltd.  This software is licensed under the
+# GNU Affero General Public License version 3 (see the file LICENSE).
+
+from textwrap import dedent
+
+from maasserver.utils.lldp import LLDPNeighbour, parse_lldp_neighbours
+from maastesting.testcase import MAASTestCase
+
+LLDP_OUTPUT = dedent(
+    """\
+    <?xml version="1.0" encoding="UTF-8"?>
+    <lldp label="LLDP neighbors">
+      <interface label="Interface" name="eth0" via="LLDP" rid="1">
+        <chassis label="Chassis">
+          <id label="ChassisID" type="mac">00:1c:73:aa:bb:cc</id>
+          <name label="SysName">tor-sw-01</name>
+          <descr label="SysDescr">Arista Networks EOS</descr>
+          <mgmt-ip label="MgmtIP">10.0.0.2</mgmt-ip>
+          <mgmt-ip label="MgmtIP">fd00::2</mgmt-ip>
+          <capability label="Capability" type="Bridge" enabled="on"/>
+        </chassis>
+        <port label="Port">
+          <id label="PortID" type="ifname">Ethernet12</id>
+          <descr label="PortDescr">rack 4 u12</descr>
+        </port>
+        <vlan label="VLAN" vlan-id="100" pvid="yes">v100</vlan>
+        <vlan label="VLAN" vlan-id="200">v200</vlan>
+      </interface>
+      <interface label="Interface" name="eth1" via="LLDP" rid="2">
+        <chassis label="Chassis">
+          <id label="ChassisID" type="local">sw-02</id>
+        </chassis>
+        <port label="Port">
+          <id label="PortID" type="mac">00:1c:73:aa:bb:dd</id>
+        </port>
+      </interface>
+    </lldp>
+    """
+).encode()
+
+
+class TestParseLLDPNeighbours(MAASTestCase):
+    def test_parses_neighbours(self):
+        self.assertEqual(
+            [
+                LLDPNeighbour(
+                    interface="eth0",
+                    chassis_id="00:1c:73:aa:bb:cc",
+                    chassis_id_type="mac",
+                    system_name="tor-sw-01",
+                    management_addresses=("10.0.0.2", "fd00::2"),
+                    port_id="Ethernet12",
+                    port_id_type="ifname",
+                    port_description="rack 4 u12",
+                    vlans=(100, 200),
+                ),
+                LLDPNeighbour(
+                    interface="eth1",
+                    chassis_id="sw-02",
+                    chassis_id_type="local",
+                    port_id="00:1c:73:aa:bb:dd",
+                    port_id_type="mac",
+                ),
+            ],
+            parse_lldp_neighbours(LLDP_OUTPUT),
+        )
+
+    def test_no_neighbours(self):
+        self.assertEqual(
+            [],
+            parse_lldp_neighbours(b'<lldp label="LLDP neighbors"/>'),
+        )
+
+    def test_skips_unnamed_interfaces(self):
+        xml = b"""
+            <lldp label="LLDP neighbors">
+              <interface label="Interface" via="LLDP"/>
+            </lldp>
+        """
+        self.assertEqual([], parse_lldp_neighbours(xml))
+
+    def test_skips_invalid_vlans(self):
+        xml = b"""
+            <lldp label="LLDP neighbors">
+              <interface label="Interface" name="eth0" via="LLDP">
+                <vlan label="VLAN" vlan-id="x"/>
+                <vlan label="VLAN"/>
+                <vlan label="VLAN" vlan-id="10"/>
+              </interface>
+            </lldp>
+        """
+        [neighbour] = parse_lldp_neighbours(xml)
+        self.assertEqual((10,), neighbour.vlans)
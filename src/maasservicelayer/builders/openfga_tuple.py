@@ -34,6 +34,16 @@ class OpenFGATupleBuilder(ResourceBuilder):
             object_type="group",
         )
 
+    @classmethod
+    def build_user_banned(cls, user_id: int) -> "OpenFGATupleBuilder":
+        return OpenFGATupleBuilder(
+            user=f"user:{user_id}",
+            user_type="user",
+            relation="banned",
+            object_id="0",
+            object_type=OpenFGAEntitlementResourceType.MAAS,
+        )
+
     @classmethod
     def build_group_can_edit_machines_in_pool(
         cls, group_id: int, pool_id: str
@@ -82,6 +92,54 @@ class OpenFGATupleBuilder(ResourceBuilder):
             object_type=OpenFGAEntitlementResourceType.POOL,
         )
 
+    @classmethod
+    def build_group_can_edit_machines_with_tag(
+        cls, group_id: int, tag_id: str
+    ) -> "OpenFGATupleBuilder":
+        return OpenFGATupleBuilder(
+            user=f"group:{group_id}#member",
+            user_type="userset",
+            relation="can_edit_machines",
+            object_id=tag_id,
+            object_type=OpenFGAEntitlementResourceType.TAG,
+        )
+
+    @classmethod
+    def build_group_can_view_machines_with_tag(
+        cls, group_id: int, tag_id: str
+    ) -> "OpenFGATupleBuilder":
+        return OpenFGATupleBuilder(
+            user=f"group:{group_id}#member",
+            user_type="userset",
+            relation="can_view_machines",
+            object_id=tag_id,
+            object_type=OpenFGAEntitlementResourceType.TAG,
+        )
+
+    @classmethod
+    def build_group_can_view_available_machines_with_tag(
+        cls, group_id: int, tag_id: str
+    ) -> "OpenFGATupleBuilder":
+        return OpenFGATupleBuilder(
+            user=f"group:{group_id}#member",
+            user_type="userset",
+            relation="can_view_available_machines",
+            object_id=tag_id,
+            object_type=OpenFGAEntitlementResourceType.TAG,
+        )
+
+    @classmethod
+    def build_group_can_deploy_machines_with_tag(
+        cls, group_id: int, tag_id: str
+    ) -> "OpenFGATupleBuilder":
+        return OpenFGATupleBuilder(
+            user=f"group:{group_id}#member",
+            user_type="userset",
+            relation="can_deploy_machines",
+            object_id=tag_id,
+            object_type=OpenFGAEntitlementResourceType.TAG,
+        )
+
     @classmethod
     def build_group_can_edit_machines(
         cls, group_id: int
@@ -331,3 +389,79 @@ class OpenFGATupleBuilder(ResourceBuilder):
             object_id=pool_id,
             object_type=OpenFGAEntitlementResourceType.POOL,
         )
+
+    @classmethod
+    def build_zone(cls, zone_id: str) -> "OpenFGATupleBuilder":
+        return OpenFGATupleBuilder(
+            user="maas:0",
+            user_type="user",
+            relation="parent",
+            object_id=zone_id,
+            object_type=OpenFGAEntitlementResourceType.ZONE,
+        )
+
+    @classmethod
+    def build_fabric(cls, fabric_id: str) -> "OpenFGATupleBuilder":
+        return OpenFGATupleBuilder(
+            user="maas:0",
+            user_type="user",
+            relation="parent",
+            object_id=fabric_id,
+            object_type=OpenFGAEntitlementResourceType.FABRIC,
+        )
+
+    @classmethod
+    def build_vlan(cls, vlan_id: str, fabric_id: str) -> "OpenFGATupleBuilder":
+        return OpenFGATupleBuilder(
+            user=f"fabric:{fabric_id}",
+            user_type="user",
+            relation="fabric",
+            object_id=vlan_id,
+            object_type=OpenFGAEntitlementResourceType.VLAN,
+        )
+
+    @classmethod
+    def build_boot_resource(
+        cls, boot_resource_id: str
+    ) -> "OpenFGATupleBuilder":
+        return OpenFGATupleBuilder(
+            user="maas:0",
+            user_type="user",
+            relation="parent",
+            object_id=boot_resource_id,
+            object_type=OpenFGAEntitlementResourceType.BOOT_RESOURCE,
+        )
+
+    @classmethod
+    def build_tag(cls, tag_id: str) -> "OpenFGATupleBuilder":
+        return OpenFGATupleBuilder(
+            user="maas:0",
+            user_type="user",
+            relation="parent",
+            object_id=tag_id,
+            object_type=OpenFGAEntitlementResourceType.TAG,
+        )
+
+    @classmethod
+    def build_machine_pool(
+        cls, machine_id: str, pool_id: str
+    ) -> "OpenFGATupleBuilder":
+        return OpenFGATupleBuilder(
+            user=f"pool:{pool_id}",
+            user_type="user",
+            relation="pool",
+            object_id=machine_id,
+            object_type=OpenFGAEntitlementResourceType.MACHINE,
+        )
+
+    @classmethod
+    def build_machine_tag(
+        cls, machine_id: str, tag_id: str
+    ) -> "OpenFGATupleBuilder":
+        return OpenFGATupleBuilder(
+            user=f"tag:{tag_id}",
+            user_type="user",
+            relation="tag",
+            object_id=machine_id,
+            object_type=OpenFGAEntitlementResourceType.MACHINE,
+        )
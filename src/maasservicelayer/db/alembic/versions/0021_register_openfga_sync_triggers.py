@@ -0,0 +1,92 @@
+# Copyright 2026 Canonical Ltd.  This software is licensed under the
+# GNU Affero General Public License version 3 (see the file LICENSE).
+
+"""Register triggers notifying maas-openfga of entity changes
+
+Revision ID: 0021
+Revises: 0020
+Create Date: 2026-10-14 09:12:31.204817+00:00
+
+"""
+
+from textwrap import dedent
+from typing import Sequence
+
+from alembic import op
+
+from maasservicelayer.db.alembic.triggers import (
+    register_procedure,
+    register_trigger,
+)
+
+# revision identifiers, used by Alembic.
+revision: str = "0021"
+down_revision: str | None = "0020"
+branch_labels: str | Sequence[str] | None = None
+depends_on: str | Sequence[str] | None = None
+
+# Notifies maas-openfga that the tuples of an entity must be written or
+# deleted. The payload names the OpenFGA type of the entity, e.g.
+# {"type": "pool", "op": "delete", "id": 3}. Notifications are only sent on
+# commit, and only to connected listeners: maas-openfga catches up on
+# changes made while it wasn't listening.
+OPENFGA_SYNC = dedent(
+    """\
+    CREATE OR REPLACE FUNCTION sys_openfga_sync(
+      entity_type text, operation text, entity_id bigint)
+    RETURNS void AS $$
+    BEGIN
+      PERFORM pg_notify('sys_openfga_sync', json_build_object(
+        'type', entity_type, 'op', operation, 'id', entity_id)::text);
+    END;
+    $$ LANGUAGE plpgsql;
+    """
+)
+
+
+def render_openfga_sync_procedure(proc_name, entity_type, operation):
+    """Render a procedure notifying maas-openfga of `operation` on a row of
+    a table holding entities of `entity_type`."""
+    row = "OLD" if operation == "delete" else "NEW"
+    return dedent(
+        f"""\
+        CREATE OR REPLACE FUNCTION {proc_name}()
+        RETURNS trigger AS $$
+        BEGIN
+          PERFORM sys_openfga_sync('{entity_type}', '{operation}', {row}.id);
+          RETURN {row};
+        END;
+        $$ LANGUAGE plpgsql;
+        """
+    )
+
+
+# (table, procedure, entity type, operation). Group memberships are tuples
+# already, only the deletion of a group affects other tuples.
+OPENFGA_SYNC_TRIGGERS = (
+    ("auth_user", "sys_openfga_user_insert", "user", "insert"),
+    ("auth_user", "sys_openfga_user_delete", "user", "delete"),
+    ("maasserver_resourcepool", "sys_openfga_rpool_insert", "pool", "insert"),
+    ("maasserver_resourcepool", "sys_openfga_rpool_delete", "pool", "delete"),
+    (
+        "maasserver_usergroup",
+        "sys_openfga_usergroup_delete",
+        "group",
+        "delete",
+    ),
+)
+
+
+def upgrade() -> None:
+    register_procedure(op, OPENFGA_SYNC)
+    for table, proc_name, entity_type, operation in OPENFGA_SYNC_TRIGGERS:
+        register_procedure(
+            op,
+            render_openfga_sync_procedure(proc_name, entity_type, operation),
+        )
+        register_trigger(op, table, proc_name, operation)
+
+
+def downgrade() -> None:
+    # We do not support migration downgrade
+    pass
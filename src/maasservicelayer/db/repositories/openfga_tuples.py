@@ -1,11 +1,17 @@
 # Copyright 2026 Canonical Ltd.  This software is licensed under the
 # GNU Affero General Public License version 3 (see the file LICENSE).
 
+from typing import Any
+
 from sqlalchemy import delete, select
 from sqlalchemy.dialects.postgresql import insert
 from sqlalchemy.sql.operators import eq
 
-from maascommon.enums.openfga import OPENFGA_STORE_ID
+from maascommon.enums.openfga import (
+    OPENFGA_STORE_ID,
+    OPENFGA_TUPLE_OPERATION_DELETE,
+    OPENFGA_TUPLE_OPERATION_WRITE,
+)
 from maascommon.utils.ulid import generate_ulid
 from maasservicelayer.builders.openfga_tuple import OpenFGATupleBuilder
 from maasservicelayer.db.filters import Clause, ClauseFactory, QuerySpec
@@ -14,7 +20,10 @@ from maasservicelayer.db.mappers.base import (
     CreateOrUpdateResource,
 )
 from maasservicelayer.db.repositories.base import Repository
-from maasservicelayer.db.tables import OpenFGATupleTable
+from maasservicelayer.db.tables import (
+    OpenFGAChangelogTable,
+    OpenFGATupleTable,
+)
 from maasservicelayer.models.base import ResourceBuilder
 from maasservicelayer.models.openfga_tuple import OpenFGATuple
 from maasservicelayer.utils.date import utcnow
@@ -106,10 +115,48 @@ class OpenFGATuplesRepository(Repository):
         result = (await self.execute_stmt(stmt)).one()
         result_dict = result._asdict()
 
+        await self._record_changes(
+            [result_dict], OPENFGA_TUPLE_OPERATION_WRITE
+        )
+
         result_dict["user"] = result_dict.pop("_user")
         return OpenFGATuple(**result_dict)
 
     async def delete_many(self, query: QuerySpec) -> None:
         stmt = delete(OpenFGATupleTable).returning(OpenFGATupleTable)
         stmt = query.enrich_stmt(stmt)
+        result = await self.execute_stmt(stmt)
+        await self._record_changes(
+            [row._asdict() for row in result], OPENFGA_TUPLE_OPERATION_DELETE
+        )
+
+    async def _record_changes(
+        self, tuples: list[dict[str, Any]], operation: int
+    ) -> None:
+        """Add changelog entries, as OpenFGA does for its own writes.
+
+        Tuples are written directly to the database, bypassing OpenFGA.
+        Without these entries, clients watching the change stream would
+        not be notified.
+        """
+        if not tuples:
+            return
+        inserted_at = utcnow()
+        stmt = insert(OpenFGAChangelogTable).values(
+            [
+                {
+                    "store": t["store"],
+                    "object_type": t["object_type"],
+                    "object_id": t["object_id"],
+                    "relation": t["relation"],
+                    "_user": t["_user"],
+                    "condition_name": t["condition_name"],
+                    "condition_context": t["condition_context"],
+                    "operation": operation,
+                    "ulid": generate_ulid(),
+                    "inserted_at": inserted_at,
+                }
+                for t in tuples
+            ]
+        )
         await self.execute_stmt(stmt)
@@ -10,7 +10,12 @@ from sqlalchemy.sql.operators import eq
 from maascommon.enums.node import NodeStatus, NodeTypeEnum
 from maasservicelayer.db.filters import Clause, ClauseFactory, QuerySpec
 from maasservicelayer.db.repositories.base import BaseRepository
-from maasservicelayer.db.tables import NodeTable, ResourcePoolTable
+from maasservicelayer.db.tables import (
+    NodeTable,
+    NodeTagTable,
+    ResourcePoolTable,
+    TagTable,
+)
 from maasservicelayer.models.base import ListResult
 from maasservicelayer.models.resource_pools import (
     ResourcePool,
@@ -23,6 +28,22 @@ class ResourcePoolClauseFactory(ClauseFactory):
     def with_ids(cls, ids: list[int]) -> Clause:
         return Clause(condition=ResourcePoolTable.c.id.in_(ids))
 
+    @classmethod
+    def with_machine_tag(cls, tag_name: str) -> Clause:
+        """Select the pools holding at least one machine with the tag."""
+        return Clause(
+            condition=ResourcePoolTable.c.id.in_(
+                select(NodeTable.c.pool_id)
+                .select_from(NodeTable)
+                .join(NodeTagTable, NodeTagTable.c.node_id == NodeTable.c.id)
+                .join(TagTable, TagTable.c.id == NodeTagTable.c.tag_id)
+                .where(
+                    eq(NodeTable.c.node_type, NodeTypeEnum.MACHINE),
+                    eq(TagTable.c.name, tag_name),
+                )
+            )
+        )
+
 
 class ResourcePoolRepository(BaseRepository[ResourcePool]):
     def get_repository_table(self) -> Table:
@@ -1726,6 +1726,28 @@ OIDCRevokedTokenTable = Table(
     Index("maasserver_oidcrevokedtoken_provider_id_3d1f3f6b", "provider_id"),
 )
 
+OpenFGAChangelogTable = Table(
+    "changelog",
+    METADATA,
+    Column("store", Text, nullable=False),
+    Column("object_type", Text, nullable=False),
+    Column("object_id", Text, nullable=False),
+    Column("relation", Text, nullable=False),
+    Column("_user", Text, nullable=False),
+    Column("operation", Integer, nullable=False),
+    Column("ulid", Text, nullable=False),
+    Column("inserted_at", DateTime(timezone=True), nullable=False),
+    Column("condition_name", Text, nullable=True),
+    Column("condition_context", LargeBinary, nullable=True),
+    PrimaryKeyConstraint(
+        "store",
+        "ulid",
+        "object_type",
+        name="changelog_pkey",
+    ),
+    schema=OPENFGA_SCHEMA,
+)
+
 OpenFGATupleTable = Table(
     "tuple",
     METADATA,
@@ -373,11 +373,20 @@ class ServiceCollectionV3:
         services.notifications = NotificationsService(
             context=context, repository=NotificationsRepository(context)
         )
+        services.openfga_tuples = OpenFGATupleService(
+            context=context,
+            openfga_tuple_repository=OpenFGATuplesRepository(context),
+            cache=cache.get(
+                OpenFGATupleService.__name__,
+                OpenFGATupleService.build_cache_object,
+            ),  # type: ignore
+        )
         services.tags = TagsService(
             context=context,
             repository=TagsRepository(context),
             events_service=services.events,
             temporal_service=services.temporal,
+            openfga_tuples_service=services.openfga_tuples,
         )
         services.scriptresults = ScriptResultsService(
             context=context,
@@ -481,14 +490,6 @@ class ServiceCollectionV3:
                 ZonesService.__name__, ZonesService.build_cache_object
             ),  # type: ignore
         )
-        services.openfga_tuples = OpenFGATupleService(
-            context=context,
-            openfga_tuple_repository=OpenFGATuplesRepository(context),
-            cache=cache.get(
-                OpenFGATupleService.__name__,
-                OpenFGATupleService.build_cache_object,
-            ),  # type: ignore
-        )
         services.resource_pools = ResourcePoolsService(
             context=context,
             resource_pools_repository=ResourcePoolRepository(context),
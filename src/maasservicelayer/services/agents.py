@@ -57,8 +57,11 @@ class AgentsService(BaseService[Agent, AgentsRepository, AgentBuilder]):
         return apiclient
 
     async def get_service_configuration(
-        self, system_id: str, service_name: str
+        self,
+        system_id: str,
+        service_name: str,
+        params: dict[str, str] | None = None,
     ):
         apiclient = await self._get_apiclient()
         path = f"agents/{system_id}/services/{service_name}/config/"
-        return await apiclient.request(method="GET", path=path)
+        return await apiclient.request(method="GET", path=path, params=params)
@@ -90,10 +90,20 @@ class PoolTupleBuilderFactory(BaseEntitlementResourceBuilderFactory):
     }
 
 
+class TagTupleBuilderFactory(BaseEntitlementResourceBuilderFactory):
+    ENTITLEMENTS = {
+        "can_edit_machines": OpenFGATupleBuilder.build_group_can_edit_machines_with_tag,
+        "can_deploy_machines": OpenFGATupleBuilder.build_group_can_deploy_machines_with_tag,
+        "can_view_machines": OpenFGATupleBuilder.build_group_can_view_machines_with_tag,
+        "can_view_available_machines": OpenFGATupleBuilder.build_group_can_view_available_machines_with_tag,
+    }
+
+
 class EntitlementsBuilderFactory:
     FACTORIES = {
         OpenFGAEntitlementResourceType.MAAS: MAASTupleBuilderFactory,
         OpenFGAEntitlementResourceType.POOL: PoolTupleBuilderFactory,
+        OpenFGAEntitlementResourceType.TAG: TagTupleBuilderFactory,
     }
 
     @classmethod
@@ -179,6 +189,105 @@ class OpenFGATupleService(Service):
         )
         await self.delete_many(query)
 
+    async def delete_tag(self, tag_id: int) -> None:
+        # Delete the tag, the entitlements granted on it and the links to
+        # the machines having it.
+        query = QuerySpec(
+            where=OpenFGATuplesClauseFactory.or_clauses(
+                [
+                    OpenFGATuplesClauseFactory.and_clauses(
+                        [
+                            OpenFGATuplesClauseFactory.with_object_type(
+                                "tag"
+                            ),
+                            OpenFGATuplesClauseFactory.with_object_id(
+                                str(tag_id)
+                            ),
+                        ]
+                    ),
+                    OpenFGATuplesClauseFactory.and_clauses(
+                        [
+                            OpenFGATuplesClauseFactory.with_user(
+                                f"tag:{tag_id}"
+                            ),
+                            OpenFGATuplesClauseFactory.with_relation("tag"),
+                            OpenFGATuplesClauseFactory.with_object_type(
+                                "machine"
+                            ),
+                        ]
+                    ),
+                ]
+            )
+        )
+        await self.delete_many(query)
+
+    async def set_machine_pool(
+        self, machine_id: int, pool_id: int | None
+    ) -> None:
+        """Replace the pool the machine inherits permissions from."""
+        query = QuerySpec(
+            where=OpenFGATuplesClauseFactory.and_clauses(
+                [
+                    OpenFGATuplesClauseFactory.with_relation("pool"),
+                    OpenFGATuplesClauseFactory.with_object_type("machine"),
+                    OpenFGATuplesClauseFactory.with_object_id(str(machine_id)),
+                ]
+            )
+        )
+        await self.delete_many(query)
+        if pool_id is not None:
+            await self.upsert(
+                OpenFGATupleBuilder.build_machine_pool(
+                    str(machine_id), str(pool_id)
+                )
+            )
+
+    async def add_machine_tags(
+        self, machine_id: int, tag_ids: list[int]
+    ) -> None:
+        for tag_id in tag_ids:
+            await self.upsert(
+                OpenFGATupleBuilder.build_machine_tag(
+                    str(machine_id), str(tag_id)
+                )
+            )
+
+    async def remove_machine_tags(
+        self, machine_id: int, tag_ids: list[int] | None = None
+    ) -> None:
+        """Unlink the machine from the given tags, or from all of them."""
+        clauses = [
+            OpenFGATuplesClauseFactory.with_relation("tag"),
+            OpenFGATuplesClauseFactory.with_object_type("machine"),
+            OpenFGATuplesClauseFactory.with_object_id(str(machine_id)),
+        ]
+        if tag_ids is not None:
+            if not tag_ids:
+                return
+            clauses.append(
+                OpenFGATuplesClauseFactory.or_clauses(
+                    [
+                        OpenFGATuplesClauseFactory.with_user(f"tag:{tag_id}")
+                        for tag_id in tag_ids
+                    ]
+                )
+            )
+        query = QuerySpec(
+            where=OpenFGATuplesClauseFactory.and_clauses(clauses)
+        )
+        await self.delete_many(query)
+
+    async def delete_machine(self, machine_id: int) -> None:
+        query = QuerySpec(
+            where=OpenFGATuplesClauseFactory.and_clauses(
+                [
+                    OpenFGATuplesClauseFactory.with_object_type("machine"),
+                    OpenFGATuplesClauseFactory.with_object_id(str(machine_id)),
+                ]
+            )
+        )
+        await self.delete_many(query)
+
     async def delete_user(self, user_id: int) -> None:
         query = QuerySpec(
             where=OpenFGATuplesClauseFactory.and_clauses(
@@ -228,6 +337,25 @@ class OpenFGATupleService(Service):
         )
         await self.delete_many(query)
 
+    async def ban_user(self, user_id: int) -> OpenFGATuple:
+        """Revoke every permission of the user, whatever their groups."""
+        return await self.upsert(
+            OpenFGATupleBuilder.build_user_banned(user_id)
+        )
+
+    async def unban_user(self, user_id: int) -> None:
+        query = QuerySpec(
+            where=OpenFGATuplesClauseFactory.and_clauses(
+                [
+                    OpenFGATuplesClauseFactory.with_user(f"user:{user_id}"),
+                    OpenFGATuplesClauseFactory.with_relation("banned"),
+                    OpenFGATuplesClauseFactory.with_object_type("maas"),
+                    OpenFGATuplesClauseFactory.with_object_id("0"),
+                ]
+            )
+        )
+        await self.delete_many(query)
+
     async def list_entitlements(
         self,
         group_id: int,
@@ -265,3 +393,29 @@ class OpenFGATupleService(Service):
             )
         )
         await self.delete_many(query)
+
+    async def grant_pools_entitlement(
+        self,
+        group_id: int,
+        entitlement_name: str,
+        pool_ids: list[int],
+    ) -> list[int]:
+        """Grant the entitlement on each of the pools.
+
+        Returns the IDs of the pools the group wasn't already entitled on,
+        in the order they were given.
+        """
+        factory = PoolTupleBuilderFactory(entitlement_name)
+        existing = {
+            int(t.object_id)
+            for t in await self.list_entitlements(group_id)
+            if t.object_type == OpenFGAEntitlementResourceType.POOL
+            and t.relation == entitlement_name
+        }
+        granted = []
+        for pool_id in pool_ids:
+            if pool_id in existing:
+                continue
+            await self.upsert(factory.build_tuple(group_id, pool_id))
+            granted.append(pool_id)
+        return granted
@@ -8,6 +8,7 @@ from maascommon.workflows.tag import (
     TAG_EVALUATION_WORKFLOW_NAME,
     TagEvaluationParam,
 )
+from maasservicelayer.builders.openfga_tuple import OpenFGATupleBuilder
 from maasservicelayer.builders.tags import TagBuilder
 from maasservicelayer.context import Context
 from maasservicelayer.db.repositories.tags import TagsRepository
@@ -16,6 +17,7 @@ from maasservicelayer.models.base import Unset
 from maasservicelayer.models.tags import Tag
 from maasservicelayer.services.base import BaseService
 from maasservicelayer.services.events import EventsService
+from maasservicelayer.services.openfga_tuples import OpenFGATupleService
 from maasservicelayer.services.temporal import TemporalService
 
 
@@ -28,10 +30,12 @@ class TagsService(BaseService[Tag, TagsRepository, TagBuilder]):
         repository: TagsRepository,
         events_service: EventsService,
         temporal_service: TemporalService,
+        openfga_tuples_service: OpenFGATupleService,
     ):
         super().__init__(context, repository)
         self.events_service = events_service
         self.temporal_service = temporal_service
+        self.openfga_tuples_service = openfga_tuples_service
 
     async def _start_tag_evaluation_wf(self, tag: Tag) -> None:
         if tag.definition != "":
@@ -43,6 +47,9 @@ class TagsService(BaseService[Tag, TagsRepository, TagBuilder]):
 
     @override
     async def post_create_hook(self, resource: Tag) -> None:
+        await self.openfga_tuples_service.upsert(
+            OpenFGATupleBuilder.build_tag(str(resource.id))
+        )
         await self._start_tag_evaluation_wf(resource)
         await self.events_service.record_event(
             event_type=EventTypeEnum.TAG,
@@ -78,6 +85,7 @@ class TagsService(BaseService[Tag, TagsRepository, TagBuilder]):
 
     @override
     async def post_delete_hook(self, resource: Tag) -> None:
+        await self.openfga_tuples_service.delete_tag(resource.id)
         await self.events_service.record_event(
             event_type=EventTypeEnum.TAG,
             event_description=f"Tag '{resource.name}' deleted.",
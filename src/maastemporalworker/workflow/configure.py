@@ -234,6 +234,14 @@ class ConfigureAgentWorkflow:
 
     @workflow_run_with_context
     async def run(self, param: ConfigureAgentParam) -> None:
+        for capability in param.capabilities:
+            if not capability.available:
+                # Services depending on it are disabled by the Agent.
+                workflow.logger.warning(
+                    f"Agent {param.system_id} lacks capability "
+                    f"{capability.name}: {capability.reason}"
+                )
+
         # Agent registers workflows for configuring it's services
         # during Temporal worker pool initialization using WithConfigurator.
         # Make sure that used workflow names are in sync with the Agent.
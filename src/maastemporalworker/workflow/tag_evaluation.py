@@ -28,14 +28,18 @@ from datetime import timedelta
 from functools import reduce
 
 from sqlalchemy import text
+from sqlalchemy.ext.asyncio import AsyncConnection
 import structlog
 from temporalio import workflow
 from temporalio.common import RetryPolicy
 
+from maascommon.enums.node import NodeTypeEnum
 from maascommon.workflows.tag import (
     TAG_EVALUATION_WORKFLOW_NAME,
     TagEvaluationParam,
 )
+from maasservicelayer.context import Context
+from maasservicelayer.services import ServiceCollectionV3
 from maastemporalworker.workflow.activity import ActivityBase
 from maastemporalworker.workflow.utils import (
     activity_defn_with_context,
@@ -238,22 +242,50 @@ insert_rows_cte AS (
         FROM node_tag_action_cte WHERE action = 'insert'
     RETURNING node_id, tag_id
 )
-SELECT 'deleted', count(1) FROM delete_rows_cte
-UNION
-SELECT 'inserted', count(1) FROM insert_rows_cte
-UNION
-SELECT 'processed', COALESCE(count(id), 0) FROM batch_nodes_cte
-UNION
-SELECT 'pointer', max(id) FROM batch_nodes_cte
+/* the node-tag pairs deleted and inserted, telling whether the node is a
+   machine, followed by the number of nodes processed and the last one
+*/
+SELECT
+    'deleted'
+    , drc.node_id
+    , drc.tag_id
+    , mn.node_type = {NodeTypeEnum.MACHINE}
+FROM delete_rows_cte drc
+INNER JOIN maasserver_node mn
+    ON mn.id = drc.node_id
+UNION ALL
+SELECT
+    'inserted'
+    , irc.node_id
+    , irc.tag_id
+    , mn.node_type = {NodeTypeEnum.MACHINE}
+FROM insert_rows_cte irc
+INNER JOIN maasserver_node mn
+    ON mn.id = irc.node_id
+UNION ALL
+SELECT 'processed', count(id), max(id), NULL FROM batch_nodes_cte
 ;
                 """
                 cursor_result = await tx.execute(text(stmt))
-                output = {k: v for k, v in cursor_result.all()}
-
-                processed_nodes = output.pop("processed")
-                pointer = output.pop("pointer")
+                output = {"inserted": 0, "deleted": 0}
+                machine_tags = {"inserted": [], "deleted": []}
+                rows = cursor_result.all()
+                for action, node_id, tag_id, is_machine in rows:
+                    if action == "processed":
+                        processed_nodes, pointer = node_id, tag_id
+                        continue
+                    output[action] += 1
+                    if is_machine:
+                        machine_tags[action].append(node_id)
                 outputs.append(output)
 
+                await self._update_machine_tags(
+                    tx,
+                    param.tag_id,
+                    machine_tags["inserted"],
+                    machine_tags["deleted"],
+                )
+
         result = TagEvaluationResult(
             **reduce(
                 lambda a, b: {k: a[k] + b[k] for k in a.keys()},
@@ -262,3 +294,29 @@ SELECT 'pointer', max(id) FROM batch_nodes_cte
         )
 
         return result
+
+    async def _update_machine_tags(
+        self,
+        tx: AsyncConnection,
+        tag_id: int,
+        tagged_machine_ids: list[int],
+        untagged_machine_ids: list[int],
+    ) -> None:
+        """Link machines to the tag in the transaction that tagged them.
+
+        The node-tag pairs are written with raw SQL, which the signals
+        keeping the OpenFGA tuples up to date don't see.
+        """
+        if not tagged_machine_ids and not untagged_machine_ids:
+            return
+        services = await ServiceCollectionV3.produce(
+            context=Context(connection=tx), cache=self.services_cache
+        )
+        for machine_id in tagged_machine_ids:
+            await services.openfga_tuples.add_machine_tags(
+                machine_id, [tag_id]
+            )
+        for machine_id in untagged_machine_ids:
+            await services.openfga_tuples.remove_machine_tags(
+                machine_id, [tag_id]
+            )
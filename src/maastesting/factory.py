@@ -814,11 +814,12 @@ class Factory:
         include_server_identifier: bool = False,
         server_ip: str = "127.1.1.1",
         include_end_option: bool = True,
+        extra_options: bytes = b"",
     ) -> bytes:
         """Returns a [possibly invalid] DHCP packet."""
         if transaction_id is None:
             transaction_id = self.make_bytes(size=4)
-        options = b""
+        options = extra_options
         if include_server_identifier:
             # 0x36 == 54 (Server Identifier option)
             ip_bytes = int(IPAddress(server_ip).value).to_bytes(4, "big")
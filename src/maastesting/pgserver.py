@@ -0,0 +1,169 @@
+# Copyright 2026 Canonical Ltd.  This software is licensed under the
+# GNU Affero General Public License version 3 (see the file LICENSE).
+
+"""Run the tests against a PostgreSQL server they don't manage.
+
+CI environments often provide PostgreSQL as a service container, where
+starting a cluster with pg_ctl is wasteful or impossible. Setting
+`MAAS_TEST_PG_DSN` to the DSN of such a server makes the tests use it in
+place of the local "db" cluster: test databases are created and dropped
+on that server, which is otherwise left alone.
+
+The DSN may be a URL or a libpq keyword/value string. Its database, if
+any, is the one used to create and drop the others, "postgres" otherwise.
+"""
+
+from contextlib import closing, contextmanager
+import os
+import subprocess
+
+from fixtures import Fixture
+import psycopg2
+from psycopg2 import sql
+from psycopg2.extensions import parse_dsn
+
+TEST_PG_DSN_ENV = "MAAS_TEST_PG_DSN"
+
+# Key of the advisory lock serialising the creation of test databases, the
+# counterpart of the lock file of a local cluster.
+LOCK_KEY = 0x4D414153  # "MAAS"
+
+
+def get_test_pg_dsn() -> str | None:
+    """Return the DSN of the external test server, if one is set."""
+    return os.environ.get(TEST_PG_DSN_ENV) or None
+
+
+class AdvisoryLock:
+    """Lock shared by every process using the same server.
+
+    Mirrors the interface of the postgresfixture cluster lock: `exclusive`
+    and `shared` are context managers.
+    """
+
+    def __init__(self, cluster: "ExternalCluster"):
+        self.cluster = cluster
+
+    @property
+    def exclusive(self):
+        return self._hold("pg_advisory_lock", "pg_advisory_unlock")
+
+    @property
+    def shared(self):
+        return self._hold(
+            "pg_advisory_lock_shared", "pg_advisory_unlock_shared"
+        )
+
+    @contextmanager
+    def _hold(self, lock, unlock):
+        # The lock belongs to the session, which is kept open while it's
+        # held. Closing it releases the lock even if unlocking fails.
+        with closing(self.cluster.connect()) as conn:
+            with conn.cursor() as cursor:
+                cursor.execute(f"SELECT {lock}(%s)", [LOCK_KEY])
+            try:
+                yield
+            finally:
+                with conn.cursor() as cursor:
+                    cursor.execute(f"SELECT {unlock}(%s)", [LOCK_KEY])
+
+
+class ExternalCluster(Fixture):
+    """A PostgreSQL server used in place of a postgresfixture cluster.
+
+    It provides the parts of the `ClusterFixture` interface the tests use.
+    Setting it up only checks that the server can be reached, and cleaning
+    it up doesn't stop it.
+    """
+
+    def __init__(self, dsn: str):
+        super().__init__()
+        params = parse_dsn(dsn)
+        self.host = params.get("host", "localhost")
+        self.port = int(params["port"]) if "port" in params else None
+        self.user = params.get("user")
+        self.password = params.get("password")
+        self.dbname = params.get("dbname", "postgres")
+        self.lock = AdvisoryLock(self)
+
+    @property
+    def datadir(self) -> str:
+        """The host of the server.
+
+        Like the data directory of a local cluster, it's the HOST of the
+        Django databases stored on this server.
+        """
+        return self.host
+
+    def _setUp(self):
+        # Fail early, rather than in the middle of the first test.
+        with closing(self.connect()):
+            pass
+
+    def create(self):
+        """Nothing to do, the server already exists."""
+
+    def connect(self, database=None, autocommit=True):
+        """Connect to `database`, the maintenance database by default."""
+        conn = psycopg2.connect(
+            dbname=database or self.dbname,
+            host=self.host,
+            port=self.port,
+            user=self.user,
+            password=self.password,
+        )
+        conn.autocommit = autocommit
+        return conn
+
+    @property
+    def databases(self) -> list[str]:
+        """The names of the databases on the server."""
+        with closing(self.connect()) as conn, conn.cursor() as cursor:
+            cursor.execute("SELECT datname FROM pg_catalog.pg_database")
+            return [name for (name,) in cursor.fetchall()]
+
+    def createdb(self, name):
+        """Create the database `name`, unless it exists already."""
+        if name not in self.databases:
+            with closing(self.connect()) as conn, conn.cursor() as cursor:
+                cursor.execute(
+                    sql.SQL("CREATE DATABASE {}").format(sql.Identifier(name))
+                )
+
+    def dropdb(self, name):
+        """Drop the database `name`, if it exists."""
+        with closing(self.connect()) as conn, conn.cursor() as cursor:
+            cursor.execute(
+                sql.SQL("DROP DATABASE IF EXISTS {}").format(
+                    sql.Identifier(name)
+                )
+            )
+
+    def environ(self) -> dict[str, str]:
+        """The libpq environment variables pointing at the server."""
+        values = {
+            "PGHOST": self.host,
+            "PGPORT": self.port,
+            "PGUSER": self.user,
+            "PGPASSWORD": self.password,
+        }
+        return {
+            name: str(value)
+            for name, value in values.items()
+            if value is not None
+        }
+
+    def execute(self, *command, **options):
+        """Run a client command, such as psql, against the server."""
+        env = options.pop("env", os.environ).copy()
+        env.update(self.environ())
+        subprocess.check_call(command, env=env, **options)
+
+    def django_settings(self) -> dict[str, str | None]:
+        """The settings of a Django database on the server."""
+        return {
+            "HOST": self.host,
+            "PORT": None if self.port is None else str(self.port),
+            "USER": self.user,
+            "PASSWORD": self.password,
+        }
@@ -125,6 +125,31 @@ class DHCP:
                 raise InvalidDHCPPacket("Truncated DHCP option value.")
             yield option_code, option_value
 
+    @property
+    def vendor_class_identifier(self) -> Optional[str]:
+        """Returns the DHCP vendor class identifier option.
+
+        Clients use it to tell their vendor and type, e.g. "MSFT 5.0" or
+        "android-dhcp-14".
+
+        :return: str
+        """
+        value = self.options.get(60, None)
+        if value is not None:
+            return value.decode("ascii", errors="replace")
+        return None
+
+    @property
+    def parameter_request_list(self) -> tuple[int, ...]:
+        """Returns the options requested by the client, in order.
+
+        The list differs between operating systems and devices, which makes
+        it a fingerprint of the client.
+
+        :return: tuple of option codes
+        """
+        return tuple(self.options.get(55, b""))
+
     @property
     def server_identifier(self) -> Optional[IPAddress]:
         """Returns the DHCP server identifier option.
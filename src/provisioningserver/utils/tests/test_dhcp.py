@@ -55,3 +55,27 @@ class TestDHCP(MAASTestCase):
         dhcp = DHCP(packet)
         self.assertTrue(dhcp.valid)
         self.assertIsNone(dhcp.server_identifier)
+
+    def test_returns_vendor_class_identifier_if_included(self):
+        packet = factory.make_dhcp_packet(
+            extra_options=b"\x3c\x0fandroid-dhcp-14"
+        )
+        dhcp = DHCP(packet)
+        self.assertTrue(dhcp.valid)
+        self.assertEqual("android-dhcp-14", dhcp.vendor_class_identifier)
+
+    def test_vendor_class_identifier_none_if_not_included(self):
+        dhcp = DHCP(factory.make_dhcp_packet())
+        self.assertIsNone(dhcp.vendor_class_identifier)
+
+    def test_returns_parameter_request_list(self):
+        packet = factory.make_dhcp_packet(
+            extra_options=b"\x37\x04\x01\x03\x06\x96"
+        )
+        dhcp = DHCP(packet)
+        self.assertTrue(dhcp.valid)
+        self.assertEqual((1, 3, 6, 150), dhcp.parameter_request_list)
+
+    def test_parameter_request_list_empty_if_not_included(self):
+        dhcp = DHCP(factory.make_dhcp_packet())
+        self.assertEqual((), dhcp.parameter_request_list)
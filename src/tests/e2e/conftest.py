@@ -61,7 +61,7 @@ def openfga_server(tmpdir, project_root_path, openfga_socket_path, db):
 
     env["SNAP_DATA"] = str(tmpdir)
 
-    pid = subprocess.Popen(binary_path, env=env)
+    pid = subprocess.Popen([binary_path, "serve"], env=env)
 
     timeout = timedelta(seconds=30)
     start_time = time.monotonic()
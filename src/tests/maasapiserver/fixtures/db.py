@@ -15,16 +15,29 @@ from maasapiserver.common.middlewares.db import TransactionMiddleware
 from maasapiserver.settings import Config, DatabaseConfig
 from maasservicelayer.db import Database
 from maasservicelayer.db.tables import METADATA
+from maastesting.pgserver import ExternalCluster
 from maastesting.pytest.database import cluster_stash
 
 
+def _database_config(cluster, name: str) -> DatabaseConfig:
+    if isinstance(cluster, ExternalCluster):
+        return DatabaseConfig(
+            name,
+            host=cluster.host,
+            port=cluster.port,
+            username=cluster.user,
+            password=cluster.password,
+        )
+    return DatabaseConfig(name, host=abspath("db/"))
+
+
 @pytest.fixture
 def test_config(
-    request: pytest.FixtureRequest, ensuremaasdb: str
+    request: pytest.FixtureRequest, pytestconfig, ensuremaasdb: str
 ) -> Iterator[Config]:
     """Set up the test config schema."""
     yield Config(
-        db=DatabaseConfig(ensuremaasdb, host=abspath("db/")),
+        db=_database_config(pytestconfig.stash[cluster_stash], ensuremaasdb),
         debug_queries=request.config.getoption("sqlalchemy_debug"),
         debug=True,
     )
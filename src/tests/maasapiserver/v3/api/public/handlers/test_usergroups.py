@@ -10,6 +10,7 @@ import pytest
 from maasapiserver.common.api.models.responses.errors import ErrorBodyResponse
 from maasapiserver.v3.api.public.models.requests.entitlements import (
     EntitlementRequest,
+    PoolsEntitlementRequest,
 )
 from maasapiserver.v3.api.public.models.requests.usergroup_members import (
     UserGroupMemberRequest,
@@ -20,6 +21,7 @@ from maasapiserver.v3.api.public.models.requests.usergroups import (
 from maasapiserver.v3.api.public.models.responses.entitlements import (
     EntitlementResponse,
     EntitlementsListResponse,
+    PoolsEntitlementResponse,
 )
 from maasapiserver.v3.api.public.models.responses.usergroup_members import (
     UserGroupMembersListResponse,
@@ -40,12 +42,14 @@ from maasservicelayer.exceptions.constants import (
 )
 from maasservicelayer.models.base import ListResult
 from maasservicelayer.models.openfga_tuple import OpenFGATuple
+from maasservicelayer.models.resource_pools import ResourcePool
 from maasservicelayer.models.usergroup_members import UserGroupMember
 from maasservicelayer.models.usergroups import UserGroup
 from maasservicelayer.models.users import User
 from maasservicelayer.services import ServiceCollectionV3, UsersService
 from maasservicelayer.services.openfga_tuples import OpenFGATupleService
 from maasservicelayer.services.resource_pools import ResourcePoolsService
+from maasservicelayer.services.tags import TagsService
 from maasservicelayer.services.usergroups import (
     UserAlreadyInGroup,
     UserGroupNotFound,
@@ -65,6 +69,17 @@ TEST_GROUP = UserGroup(
     updated=utcnow(),
 )
 
+TEST_POOLS = [
+    ResourcePool(
+        id=pool_id,
+        name=name,
+        description="",
+        created=utcnow(),
+        updated=utcnow(),
+    )
+    for pool_id, name in [(0, "default"), (1, "lab-a"), (2, "lab-b")]
+]
+
 TEST_USER = User(
     id=10,
     username="user1",
@@ -101,6 +116,10 @@ class TestUserGroupsApi(ApiCommonTests):
             Endpoint(method="POST", path=f"{self.BASE_PATH}/1/members"),
             Endpoint(method="DELETE", path=f"{self.BASE_PATH}/1/members/10"),
             Endpoint(method="POST", path=f"{self.BASE_PATH}/1/entitlements"),
+            Endpoint(
+                method="POST",
+                path=f"{self.BASE_PATH}/1/entitlements:grant_pools",
+            ),
             Endpoint(
                 method="DELETE",
                 path=f"{self.BASE_PATH}/1/entitlements"
@@ -588,6 +607,62 @@ class TestUserGroupsApi(ApiCommonTests):
         assert result.resource_id == 5
         assert result.entitlement == "can_edit_machines"
 
+    async def test_add_entitlement_tag(
+        self,
+        services_mock: ServiceCollectionV3,
+        mocked_api_client_admin: AsyncClient,
+    ) -> None:
+        entitlement_request = EntitlementRequest(
+            resource_type="tag",
+            resource_id=3,
+            entitlement="can_deploy_machines",
+        )
+        services_mock.usergroups = Mock(UserGroupsService)
+        services_mock.usergroups.get_by_id.return_value = TEST_GROUP
+        services_mock.tags = Mock(TagsService)
+        services_mock.tags.get_by_id = AsyncMock(return_value=Mock())
+        services_mock.openfga_tuples = Mock(OpenFGATupleService)
+        services_mock.openfga_tuples.upsert = AsyncMock(
+            return_value=OpenFGATuple(
+                object_type="tag",
+                object_id="3",
+                relation="can_deploy_machines",
+                user="group:1#member",
+                user_type="userset",
+            )
+        )
+
+        response = await mocked_api_client_admin.post(
+            f"{self.BASE_PATH}/{TEST_GROUP.id}/entitlements",
+            json=jsonable_encoder(entitlement_request),
+        )
+        assert response.status_code == 200
+        result = EntitlementResponse(**response.json())
+        assert result.resource_type == "tag"
+        assert result.resource_id == 3
+        assert result.entitlement == "can_deploy_machines"
+
+    async def test_add_entitlement_tag_not_found(
+        self,
+        services_mock: ServiceCollectionV3,
+        mocked_api_client_admin: AsyncClient,
+    ) -> None:
+        entitlement_request = EntitlementRequest(
+            resource_type="tag",
+            resource_id=999,
+            entitlement="can_deploy_machines",
+        )
+        services_mock.usergroups = Mock(UserGroupsService)
+        services_mock.usergroups.get_by_id.return_value = TEST_GROUP
+        services_mock.tags = Mock(TagsService)
+        services_mock.tags.get_by_id = AsyncMock(return_value=None)
+
+        response = await mocked_api_client_admin.post(
+            f"{self.BASE_PATH}/{TEST_GROUP.id}/entitlements",
+            json=jsonable_encoder(entitlement_request),
+        )
+        assert response.status_code == 404
+
     async def test_add_entitlement_group_not_found(
         self,
         services_mock: ServiceCollectionV3,
@@ -689,6 +764,164 @@ class TestUserGroupsApi(ApiCommonTests):
         error_response = ErrorBodyResponse(**response.json())
         assert error_response.code == 400
 
+    # POST /groups/{group_id}/entitlements:grant_pools
+    async def test_grant_pools_entitlement_by_name(
+        self,
+        services_mock: ServiceCollectionV3,
+        mocked_api_client_admin: AsyncClient,
+    ) -> None:
+        entitlement_request = PoolsEntitlementRequest(
+            entitlement="can_deploy_machines", name="lab-*"
+        )
+        services_mock.usergroups = Mock(UserGroupsService)
+        services_mock.usergroups.get_by_id.return_value = TEST_GROUP
+        services_mock.resource_pools = Mock(ResourcePoolsService)
+        services_mock.resource_pools.get_many = AsyncMock(
+            return_value=TEST_POOLS
+        )
+        services_mock.openfga_tuples = Mock(OpenFGATupleService)
+        services_mock.openfga_tuples.grant_pools_entitlement = AsyncMock(
+            return_value=[2]
+        )
+
+        response = await mocked_api_client_admin.post(
+            f"{self.BASE_PATH}/{TEST_GROUP.id}/entitlements:grant_pools",
+            json=jsonable_encoder(entitlement_request),
+        )
+        assert response.status_code == 200
+        result = PoolsEntitlementResponse(**response.json())
+        assert result.entitlement == "can_deploy_machines"
+        assert result.matched == 2
+        assert result.granted == [2]
+        assert result.already_granted == [1]
+        grant = services_mock.openfga_tuples.grant_pools_entitlement
+        grant.assert_called_once_with(
+            TEST_GROUP.id, "can_deploy_machines", [1, 2]
+        )
+
+    async def test_grant_pools_entitlement_all_pools(
+        self,
+        services_mock: ServiceCollectionV3,
+        mocked_api_client_admin: AsyncClient,
+    ) -> None:
+        entitlement_request = PoolsEntitlementRequest(
+            entitlement="can_view_machines", all_pools=True
+        )
+        services_mock.usergroups = Mock(UserGroupsService)
+        services_mock.usergroups.get_by_id.return_value = TEST_GROUP
+        services_mock.resource_pools = Mock(ResourcePoolsService)
+        services_mock.resource_pools.get_many = AsyncMock(
+            return_value=TEST_POOLS
+        )
+        services_mock.openfga_tuples = Mock(OpenFGATupleService)
+        services_mock.openfga_tuples.grant_pools_entitlement = AsyncMock(
+            return_value=[0, 1, 2]
+        )
+
+        response = await mocked_api_client_admin.post(
+            f"{self.BASE_PATH}/{TEST_GROUP.id}/entitlements:grant_pools",
+            json=jsonable_encoder(entitlement_request),
+        )
+        assert response.status_code == 200
+        result = PoolsEntitlementResponse(**response.json())
+        assert result.matched == 3
+        assert result.granted == [0, 1, 2]
+        assert result.already_granted == []
+
+    async def test_grant_pools_entitlement_by_tag(
+        self,
+        services_mock: ServiceCollectionV3,
+        mocked_api_client_admin: AsyncClient,
+    ) -> None:
+        entitlement_request = PoolsEntitlementRequest(
+            entitlement="can_view_machines", tag="gpu"
+        )
+        services_mock.usergroups = Mock(UserGroupsService)
+        services_mock.usergroups.get_by_id.return_value = TEST_GROUP
+        services_mock.resource_pools = Mock(ResourcePoolsService)
+        services_mock.resource_pools.get_many = AsyncMock(
+            return_value=[TEST_POOLS[2]]
+        )
+        services_mock.openfga_tuples = Mock(OpenFGATupleService)
+        services_mock.openfga_tuples.grant_pools_entitlement = AsyncMock(
+            return_value=[2]
+        )
+
+        response = await mocked_api_client_admin.post(
+            f"{self.BASE_PATH}/{TEST_GROUP.id}/entitlements:grant_pools",
+            json=jsonable_encoder(entitlement_request),
+        )
+        assert response.status_code == 200
+        result = PoolsEntitlementResponse(**response.json())
+        assert result.matched == 1
+        assert result.granted == [2]
+        assert result.already_granted == []
+        services_mock.resource_pools.get_many.assert_called_once()
+        grant = services_mock.openfga_tuples.grant_pools_entitlement
+        grant.assert_called_once_with(
+            TEST_GROUP.id, "can_view_machines", [2]
+        )
+
+    @pytest.mark.parametrize(
+        "selector",
+        [
+            {},
+            {"name": "lab-*", "all_pools": True},
+            {"name": "lab-*", "tag": "gpu"},
+            {"tag": "gpu", "all_pools": True},
+        ],
+    )
+    async def test_grant_pools_entitlement_invalid_selector(
+        self,
+        services_mock: ServiceCollectionV3,
+        mocked_api_client_admin: AsyncClient,
+        selector: dict,
+    ) -> None:
+        services_mock.usergroups = Mock(UserGroupsService)
+        services_mock.usergroups.get_by_id.return_value = TEST_GROUP
+        services_mock.openfga_tuples = Mock(OpenFGATupleService)
+
+        response = await mocked_api_client_admin.post(
+            f"{self.BASE_PATH}/{TEST_GROUP.id}/entitlements:grant_pools",
+            json={"entitlement": "can_view_machines", **selector},
+        )
+        assert response.status_code == 400
+        grant = services_mock.openfga_tuples.grant_pools_entitlement
+        grant.assert_not_called()
+
+    async def test_grant_pools_entitlement_invalid_entitlement(
+        self,
+        services_mock: ServiceCollectionV3,
+        mocked_api_client_admin: AsyncClient,
+    ) -> None:
+        services_mock.usergroups = Mock(UserGroupsService)
+        services_mock.usergroups.get_by_id.return_value = TEST_GROUP
+        services_mock.openfga_tuples = Mock(OpenFGATupleService)
+
+        response = await mocked_api_client_admin.post(
+            f"{self.BASE_PATH}/{TEST_GROUP.id}/entitlements:grant_pools",
+            json={"entitlement": "can_edit_identities", "all_pools": True},
+        )
+        assert response.status_code == 400
+        error_response = ErrorBodyResponse(**response.json())
+        assert error_response.code == 400
+        grant = services_mock.openfga_tuples.grant_pools_entitlement
+        grant.assert_not_called()
+
+    async def test_grant_pools_entitlement_group_not_found(
+        self,
+        services_mock: ServiceCollectionV3,
+        mocked_api_client_admin: AsyncClient,
+    ) -> None:
+        services_mock.usergroups = Mock(UserGroupsService)
+        services_mock.usergroups.get_by_id.return_value = None
+
+        response = await mocked_api_client_admin.post(
+            f"{self.BASE_PATH}/999/entitlements:grant_pools",
+            json={"entitlement": "can_view_machines", "all_pools": True},
+        )
+        assert response.status_code == 404
+
     # DELETE /groups/{group_id}/entitlements
     async def test_remove_entitlement_maas(
         self,
@@ -72,21 +72,39 @@ class StubOpenFGAServer:
     def __init__(self):
         self.allowed = True
         self.last_payload = None
+        self.last_headers = None
         self.status_code = 200
+        self.error_response = None
         self.list_objects_response = {"objects": []}
+        self.access_response = {"relations": {}}
+        self.last_query = None
+
+    def error(self):
+        if self.error_response is None:
+            return web.Response(status=self.status_code)
+        return web.json_response(self.error_response, status=self.status_code)
 
     async def check_handler(self, request):
         self.last_payload = await request.json()
+        self.last_headers = request.headers
         if self.status_code != 200:
-            return web.Response(status=self.status_code)
+            return self.error()
         return web.json_response({"allowed": self.allowed, "resolution": ""})
 
     async def list_objects_handler(self, request):
         self.last_payload = await request.json()
+        self.last_headers = request.headers
         if self.status_code != 200:
-            return web.Response(status=self.status_code)
+            return self.error()
         return web.json_response(self.list_objects_response)
 
+    async def access_handler(self, request):
+        self.last_query = list(request.query.items())
+        self.last_headers = request.headers
+        if self.status_code != 200:
+            return self.error()
+        return web.json_response(self.access_response)
+
 
 @pytest.fixture
 async def stub_openfga_server(tmp_path: Path):
@@ -101,6 +119,9 @@ async def stub_openfga_server(tmp_path: Path):
         f"/stores/{OPENFGA_STORE_ID}/list-objects",
         handler_store.list_objects_handler,
     )
+    app.router.add_get(
+        f"/stores/{OPENFGA_STORE_ID}/access", handler_store.access_handler
+    )
 
     runner = web.AppRunner(app)
     await runner.setup()
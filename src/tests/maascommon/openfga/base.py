@@ -1,6 +1,18 @@
 #  Copyright 2026 Canonical Ltd.  This software is licensed under the
 #  GNU Affero General Public License version 3 (see the file LICENSE).
 
+RESOLUTION_LIMIT_EXCEEDED_RESPONSE = {
+    "kind": "Error",
+    "code": 422,
+    "message": "The request exceeded the resolution limits.",
+    "details": [
+        {
+            "type": "resolution_limit_exceeded",
+            "message": "resolution too complex",
+        }
+    ],
+}
+
 PERMISSION_METHODS = [
     ("can_edit_machines", ("u1",), "can_edit_machines", "maas:0"),
     (
@@ -27,6 +39,30 @@ PERMISSION_METHODS = [
         "can_view_available_machines",
         "pool:p1",
     ),
+    (
+        "can_edit_machine",
+        ("u1", "m1"),
+        "can_edit",
+        "machine:m1",
+    ),
+    (
+        "can_deploy_machine",
+        ("u1", "m1"),
+        "can_deploy",
+        "machine:m1",
+    ),
+    (
+        "can_view_machine",
+        ("u1", "m1"),
+        "can_view",
+        "machine:m1",
+    ),
+    (
+        "can_view_available_machine",
+        ("u1", "m1"),
+        "can_view_available",
+        "machine:m1",
+    ),
     (
         "can_edit_global_entities",
         ("u1",),
@@ -63,4 +99,11 @@ LIST_METHODS = [
     ),
     ("list_pool_with_deploy_machines_access", "can_deploy_machines"),
     ("list_pools_with_edit_machines_access", "can_edit_machines"),
+    ("list_tags_with_view_machines_access", "can_view_machines"),
+    (
+        "list_tags_with_view_available_machines_access",
+        "can_view_available_machines",
+    ),
+    ("list_tags_with_deploy_machines_access", "can_deploy_machines"),
+    ("list_tags_with_edit_machines_access", "can_edit_machines"),
 ]
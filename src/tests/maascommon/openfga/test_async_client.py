@@ -4,8 +4,23 @@
 import httpx
 import pytest
 
+from maascommon.deadline import (
+    REQUEST_DEADLINE,
+    REQUEST_TIMEOUT_HEADER,
+    set_request_timeout,
+)
 from maascommon.openfga.async_client import OpenFGAClient
-from tests.maascommon.openfga.base import LIST_METHODS, PERMISSION_METHODS
+from maascommon.openfga.base import (
+    OpenFGADeadlineExceeded,
+    OpenFGAEntitlementResourceType,
+    OpenFGARequestPriority,
+    REQUEST_PRIORITY_HEADER,
+)
+from tests.maascommon.openfga.base import (
+    LIST_METHODS,
+    PERMISSION_METHODS,
+    RESOLUTION_LIMIT_EXCEEDED_RESPONSE,
+)
 
 
 @pytest.mark.asyncio
@@ -44,6 +59,33 @@ class TestOpenFGAClient:
         assert server.last_payload["relation"] == rel
         assert server.last_payload["type"] == "pool"
 
+    async def test_list_users_with_access(self, client, stub_openfga_server):
+        server, _ = stub_openfga_server
+        server.access_response = {
+            "object": "pool:1",
+            "relations": {
+                "can_deploy_machines": ["user:1", "user:2"],
+                "can_edit_machines": [],
+            },
+        }
+
+        result = await client.list_users_with_access(
+            OpenFGAEntitlementResourceType.POOL,
+            1,
+            "can_deploy_machines",
+            "can_edit_machines",
+        )
+
+        assert result == {
+            "can_deploy_machines": [1, 2],
+            "can_edit_machines": [],
+        }
+        assert server.last_query == [
+            ("object", "pool:1"),
+            ("relation", "can_deploy_machines"),
+            ("relation", "can_edit_machines"),
+        ]
+
     @pytest.mark.parametrize("status", [403, 500])
     async def test_async_raises_for_status(
         self, client, stub_openfga_server, status
@@ -57,6 +99,94 @@ class TestOpenFGAClient:
         with pytest.raises(httpx.HTTPStatusError):
             await client.list_pools_with_view_machines_access(1)
 
+    async def test_resolution_limit_exceeded_denies(
+        self, client, stub_openfga_server
+    ):
+        server, _ = stub_openfga_server
+        server.status_code = 422
+        server.error_response = RESOLUTION_LIMIT_EXCEEDED_RESPONSE
+
+        assert not await client.can_edit_machines(1)
+
+    @pytest.mark.parametrize("method, rel", LIST_METHODS)
+    async def test_resolution_limit_exceeded_lists_nothing(
+        self, client, stub_openfga_server, method, rel
+    ):
+        server, _ = stub_openfga_server
+        server.status_code = 422
+        server.error_response = RESOLUTION_LIMIT_EXCEEDED_RESPONSE
+
+        assert await getattr(client, method)(1) == []
+
+    async def test_resolution_limit_exceeded_lists_no_users(
+        self, client, stub_openfga_server
+    ):
+        server, _ = stub_openfga_server
+        server.status_code = 422
+        server.error_response = RESOLUTION_LIMIT_EXCEEDED_RESPONSE
+
+        result = await client.list_users_with_access(
+            OpenFGAEntitlementResourceType.POOL, 1, "can_deploy_machines"
+        )
+
+        assert result == {}
+
+    async def test_other_unprocessable_raises(
+        self, client, stub_openfga_server
+    ):
+        server, _ = stub_openfga_server
+        server.status_code = 422
+
+        with pytest.raises(httpx.HTTPStatusError):
+            await client.can_edit_machines(1)
+
+        with pytest.raises(httpx.HTTPStatusError):
+            await client.list_pools_with_view_machines_access(1)
+
+    async def test_passes_remaining_time(self, client, stub_openfga_server):
+        server, _ = stub_openfga_server
+        token = set_request_timeout(5)
+        try:
+            await client.can_edit_machines(1)
+        finally:
+            REQUEST_DEADLINE.reset(token)
+        assert 4000 < int(server.last_headers[REQUEST_TIMEOUT_HEADER]) <= 5000
+
+    async def test_no_deadline_no_header(self, client, stub_openfga_server):
+        server, _ = stub_openfga_server
+        await client.can_edit_machines(1)
+        assert REQUEST_TIMEOUT_HEADER not in server.last_headers
+
+    async def test_interactive_priority_by_default(
+        self, client, stub_openfga_server
+    ):
+        server, _ = stub_openfga_server
+        await client.can_edit_machines(1)
+        assert server.last_headers[REQUEST_PRIORITY_HEADER] == "interactive"
+
+    async def test_batch_priority(self, stub_openfga_server):
+        server, socket_path = stub_openfga_server
+        client = OpenFGAClient(
+            unix_socket=socket_path, priority=OpenFGARequestPriority.BATCH
+        )
+        try:
+            await client.list_pools_with_view_machines_access(1)
+        finally:
+            await client.close()
+        assert server.last_headers[REQUEST_PRIORITY_HEADER] == "batch"
+
+    async def test_deadline_exceeded(self, client, stub_openfga_server):
+        server, _ = stub_openfga_server
+        token = set_request_timeout(0)
+        try:
+            with pytest.raises(OpenFGADeadlineExceeded):
+                await client.can_edit_machines(1)
+            with pytest.raises(OpenFGADeadlineExceeded):
+                await client.list_pools_with_view_machines_access(1)
+        finally:
+            REQUEST_DEADLINE.reset(token)
+        assert server.last_payload is None
+
     async def test_async_client_closes_properly(self):
         client = OpenFGAClient()
         await client.close()
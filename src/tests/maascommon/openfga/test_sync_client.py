@@ -6,8 +6,23 @@ import asyncio
 import httpx
 import pytest
 
+from maascommon.deadline import (
+    REQUEST_DEADLINE,
+    REQUEST_TIMEOUT_HEADER,
+    set_request_timeout,
+)
+from maascommon.openfga.base import (
+    OpenFGADeadlineExceeded,
+    OpenFGAEntitlementResourceType,
+    OpenFGARequestPriority,
+    REQUEST_PRIORITY_HEADER,
+)
 from maascommon.openfga.sync_client import SyncOpenFGAClient
-from tests.maascommon.openfga.base import LIST_METHODS, PERMISSION_METHODS
+from tests.maascommon.openfga.base import (
+    LIST_METHODS,
+    PERMISSION_METHODS,
+    RESOLUTION_LIMIT_EXCEEDED_RESPONSE,
+)
 
 
 @pytest.mark.asyncio
@@ -56,6 +71,22 @@ class TestSyncOpenFGAClient:
         assert server.last_payload["relation"] == rel
         assert server.last_payload["user"] == "user:admin"
 
+    async def test_list_users_with_access(self, client, stub_openfga_server):
+        server, _ = stub_openfga_server
+        server.access_response = {
+            "object": "maas:0",
+            "relations": {"can_edit_identities": ["user:3"]},
+        }
+
+        result = await asyncio.to_thread(
+            client.list_users_with_access,
+            OpenFGAEntitlementResourceType.MAAS,
+            0,
+        )
+
+        assert result == {"can_edit_identities": [3]}
+        assert server.last_query == [("object", "maas:0")]
+
     @pytest.mark.parametrize("status", [401, 404, 503])
     async def test_sync_raises_for_status(
         self, client, stub_openfga_server, status
@@ -70,6 +101,111 @@ class TestSyncOpenFGAClient:
 
         assert excinfo.value.response.status_code == status
 
+    async def test_resolution_limit_exceeded_denies(
+        self, client, stub_openfga_server
+    ):
+        server, _ = stub_openfga_server
+        server.status_code = 422
+        server.error_response = RESOLUTION_LIMIT_EXCEEDED_RESPONSE
+
+        assert not await asyncio.to_thread(
+            client.can_edit_machines, self.MockUser("tester")
+        )
+
+    @pytest.mark.parametrize("method, rel", LIST_METHODS)
+    async def test_resolution_limit_exceeded_lists_nothing(
+        self, client, stub_openfga_server, method, rel
+    ):
+        server, _ = stub_openfga_server
+        server.status_code = 422
+        server.error_response = RESOLUTION_LIMIT_EXCEEDED_RESPONSE
+        method = getattr(client, method)
+
+        assert await asyncio.to_thread(method, self.MockUser("tester")) == []
+
+    async def test_resolution_limit_exceeded_lists_no_users(
+        self, client, stub_openfga_server
+    ):
+        server, _ = stub_openfga_server
+        server.status_code = 422
+        server.error_response = RESOLUTION_LIMIT_EXCEEDED_RESPONSE
+
+        result = await asyncio.to_thread(
+            client.list_users_with_access,
+            OpenFGAEntitlementResourceType.MAAS,
+            0,
+        )
+
+        assert result == {}
+
+    async def test_other_unprocessable_raises(
+        self, client, stub_openfga_server
+    ):
+        server, _ = stub_openfga_server
+        server.status_code = 422
+        server.error_response = {
+            "kind": "Error",
+            "code": 422,
+            "message": "Invalid request.",
+            "details": [{"type": "invalid_argument", "message": ""}],
+        }
+
+        with pytest.raises(httpx.HTTPStatusError):
+            await asyncio.to_thread(
+                client.can_edit_machines, self.MockUser("tester")
+            )
+
+        with pytest.raises(httpx.HTTPStatusError):
+            await asyncio.to_thread(
+                client.list_pools_with_view_machines_access,
+                self.MockUser("tester"),
+            )
+
+    async def test_passes_remaining_time(self, client, stub_openfga_server):
+        server, _ = stub_openfga_server
+        token = set_request_timeout(5)
+        try:
+            await asyncio.to_thread(
+                client.can_edit_machines, self.MockUser("tester")
+            )
+        finally:
+            REQUEST_DEADLINE.reset(token)
+        assert 4000 < int(server.last_headers[REQUEST_TIMEOUT_HEADER]) <= 5000
+
+    async def test_interactive_priority_by_default(
+        self, client, stub_openfga_server
+    ):
+        server, _ = stub_openfga_server
+        await asyncio.to_thread(
+            client.can_edit_machines, self.MockUser("tester")
+        )
+        assert server.last_headers[REQUEST_PRIORITY_HEADER] == "interactive"
+
+    async def test_batch_priority(self, stub_openfga_server):
+        server, socket_path = stub_openfga_server
+        client = SyncOpenFGAClient(
+            unix_socket=socket_path, priority=OpenFGARequestPriority.BATCH
+        )
+        try:
+            await asyncio.to_thread(
+                client.can_edit_machines, self.MockUser("tester")
+            )
+        finally:
+            client.close()
+        assert server.last_headers[REQUEST_PRIORITY_HEADER] == "batch"
+
+    async def test_deadline_exceeded(self, client, stub_openfga_server):
+        server, _ = stub_openfga_server
+        token = set_request_timeout(0)
+        try:
+            with pytest.raises(OpenFGADeadlineExceeded):
+                await asyncio.to_thread(
+                    client.can_edit_machines, self.MockUser("tester")
+                )
+        finally:
+            REQUEST_DEADLINE.reset(token)
+        assert server.last_payload is None
+
     async def test_client_closes_properly(self):
         client = SyncOpenFGAClient()
         client.close()
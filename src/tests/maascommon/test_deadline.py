@@ -0,0 +1,54 @@
+# Copyright 2026 Canonical Ltd.  This software is licensed under the
+# GNU Affero General Public License version 3 (see the file LICENSE).
+
+import pytest
+
+from maascommon.deadline import (
+    format_request_timeout,
+    get_remaining_time,
+    parse_request_timeout,
+    REQUEST_DEADLINE,
+    set_request_timeout,
+)
+
+
+class TestDeadline:
+    def test_no_deadline_by_default(self):
+        assert get_remaining_time() is None
+
+    def test_set_request_timeout(self):
+        token = set_request_timeout(10)
+        try:
+            remaining = get_remaining_time()
+            assert remaining is not None
+            assert 9 < remaining <= 10
+        finally:
+            REQUEST_DEADLINE.reset(token)
+        assert get_remaining_time() is None
+
+    def test_expired_deadline_is_negative(self):
+        token = set_request_timeout(-1)
+        try:
+            assert get_remaining_time() < 0
+        finally:
+            REQUEST_DEADLINE.reset(token)
+
+    @pytest.mark.parametrize(
+        "value, expected",
+        [
+            (None, None),
+            ("", None),
+            ("1500", 1.5),
+            ("0", 0),
+            ("-1", None),
+            ("soon", None),
+        ],
+    )
+    def test_parse_request_timeout(self, value, expected):
+        assert parse_request_timeout(value) == expected
+
+    @pytest.mark.parametrize(
+        "timeout, expected", [(1.5, "1500"), (0.0004, "0"), (-2, "0")]
+    )
+    def test_format_request_timeout(self, timeout, expected):
+        assert format_request_timeout(timeout) == expected
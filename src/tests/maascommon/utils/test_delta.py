@@ -0,0 +1,99 @@
+#  Copyright 2026 Canonical Ltd.  This software is licensed under the
+#  GNU Affero General Public License version 3 (see the file LICENSE).
+
+import pytest
+
+from maascommon.utils.delta import (
+    apply_delta,
+    content_hash,
+    ContentStore,
+    DeltaError,
+    make_delta,
+    OP_COPY,
+    OP_INSERT,
+)
+
+
+def _hosts(count: int) -> bytes:
+    return b"".join(
+        b"host h%d {\n  hardware ethernet 00:16:3e:00:%02x:%02x;\n"
+        b"  fixed-address 10.0.%d.%d;\n}\n"
+        % (i, i // 256, i % 256, i // 256, i % 256)
+        for i in range(count)
+    )
+
+
+class TestDelta:
+    @pytest.mark.parametrize(
+        "base,target",
+        [
+            (b"", b""),
+            (b"", b"new\n"),
+            (b"old\n", b""),
+            (b"a\nb\nc\n", b"a\nb\nc\n"),
+            (b"a\nb\nc\n", b"a\nx\nc\n"),
+            (b"a\nb\nc", b"a\nb\nc\nd"),
+            (b"\x00\xff\x01", b"\xff\x00\x01\x02"),
+        ],
+    )
+    def test_round_trip(self, base: bytes, target: bytes) -> None:
+        assert apply_delta(base, make_delta(base, target)) == target
+
+    def test_small_change_to_large_config(self) -> None:
+        base = _hosts(10000)
+        target = base.replace(b"h5000 {", b"renamed {") + _hosts(1)
+        delta = make_delta(base, target)
+        assert apply_delta(base, delta) == target
+        assert len(delta) < 200
+
+    def test_encoding(self) -> None:
+        delta = make_delta(b"a\nb\n", b"a\nc\n")
+        assert delta == bytes(
+            [4, 4, OP_COPY, 0, 2, OP_INSERT, 2, ord("c"), ord("\n")]
+        )
+
+    def test_varints(self) -> None:
+        base = b"x" * 300
+        delta = make_delta(base, base)
+        # 300 is encoded over two bytes.
+        assert delta == bytes([0xAC, 0x02, 0xAC, 0x02, OP_COPY, 0, 0xAC, 0x02])
+
+    @pytest.mark.parametrize(
+        "delta,message",
+        [
+            (b"", "varint"),
+            (bytes([5, 1]), "base"),
+            (bytes([1, 1, OP_COPY, 0, 2]), "past the end of the base"),
+            (bytes([1, 3, OP_INSERT, 5, 1]), "past the end of the delta"),
+            (bytes([1, 1, 0x7F]), "unknown"),
+            (bytes([1, 2, OP_COPY, 0, 1]), "expected 2"),
+            (bytes([1, 1, OP_COPY, 0, 1, OP_COPY, 0, 1]), "more than"),
+        ],
+    )
+    def test_apply_rejects_malformed(self, delta: bytes, message: str) -> None:
+        with pytest.raises(DeltaError, match=message):
+            apply_delta(b"a", delta)
+
+
+class TestContentStore:
+    def test_put_get(self) -> None:
+        store = ContentStore(max_bytes=10)
+        digest = store.put(b"abc")
+        assert digest == content_hash(b"abc")
+        assert store.get(digest) == b"abc"
+        assert store.get(content_hash(b"other")) is None
+
+    def test_evicts_least_recently_used(self) -> None:
+        store = ContentStore(max_bytes=6)
+        first = store.put(b"aaa")
+        second = store.put(b"bbb")
+        store.get(first)
+        third = store.put(b"ccc")
+        assert store.get(first) == b"aaa"
+        assert store.get(second) is None
+        assert store.get(third) == b"ccc"
+
+    def test_ignores_oversized(self) -> None:
+        store = ContentStore(max_bytes=2)
+        digest = store.put(b"abc")
+        assert store.get(digest) is None
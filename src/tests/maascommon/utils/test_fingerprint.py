@@ -0,0 +1,69 @@
+#  Copyright 2026 Canonical Ltd.  This software is licensed under the
+#  GNU Affero General Public License version 3 (see the file LICENSE).
+
+import pytest
+
+from maascommon.enums.discovery import DeviceTypeEnum
+from maascommon.utils.fingerprint import classify_device, DeviceGuess
+
+
+class TestClassifyDevice:
+    def test_no_evidence(self):
+        assert classify_device() == []
+
+    def test_unknown_values(self):
+        assert (
+            classify_device(
+                vendor_class="MSFT 5.0",
+                parameter_request_list=[1, 3, 6, 15],
+                mdns_service_types=["_ssh._tcp"],
+                mac_organization="Intel Corporate",
+            )
+            == []
+        )
+
+    @pytest.mark.parametrize(
+        "vendor_class, device_type, confidence",
+        [
+            ("android-dhcp-14", DeviceTypeEnum.PHONE, 0.8),
+            (
+                "Cisco Systems, Inc. IP Phone CP-8841",
+                DeviceTypeEnum.PHONE,
+                0.9,
+            ),
+            ("Cisco Systems, Inc.", DeviceTypeEnum.SWITCH, 0.5),
+            ("Hewlett-Packard JetDirect", DeviceTypeEnum.PRINTER, 0.9),
+            ("iDRAC", DeviceTypeEnum.BMC, 0.9),
+            ("CPQRIB3", DeviceTypeEnum.BMC, 0.9),
+            (
+                "onie_vendor:x86_64-accton_as7712_32x-r0",
+                DeviceTypeEnum.SWITCH,
+                0.9,
+            ),
+        ],
+    )
+    def test_vendor_class(self, vendor_class, device_type, confidence):
+        assert classify_device(vendor_class=vendor_class) == [
+            DeviceGuess(device_type, confidence)
+        ]
+
+    def test_mdns_service_types(self):
+        assert classify_device(
+            mdns_service_types=["_http._tcp", "_pdl-datastream._tcp"]
+        ) == [DeviceGuess(DeviceTypeEnum.PRINTER, 0.9)]
+
+    def test_combines_evidence_for_a_type(self):
+        # 1 - (1 - 0.8) * (1 - 0.8) * (1 - 0.6)
+        assert classify_device(
+            mdns_service_types=["_ipp._tcp", "_ipps._tcp"],
+            mac_organization="Seiko Epson Corporation",
+        ) == [DeviceGuess(DeviceTypeEnum.PRINTER, 0.984)]
+
+    def test_orders_by_confidence(self):
+        assert classify_device(
+            vendor_class="Cisco Systems, Inc.",
+            parameter_request_list=[1, 3, 150, 242],
+        ) == [
+            DeviceGuess(DeviceTypeEnum.PHONE, 0.76),
+            DeviceGuess(DeviceTypeEnum.SWITCH, 0.5),
+        ]
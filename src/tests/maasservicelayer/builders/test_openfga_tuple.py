@@ -25,6 +25,15 @@ class TestOpenFGATupleBuilder:
         assert builder.object_id == group_id
         assert builder.object_type == "group"
 
+    def test_build_user_banned(self):
+        builder = OpenFGATupleBuilder.build_user_banned(1)
+
+        assert builder.user == "user:1"
+        assert builder.user_type == "user"
+        assert builder.relation == "banned"
+        assert builder.object_id == "0"
+        assert builder.object_type == "maas"
+
     @pytest.mark.parametrize(
         "method_name, relation",
         [
@@ -50,6 +59,31 @@ class TestOpenFGATupleBuilder:
         assert builder.object_id == pool_id
         assert builder.object_type == "pool"
 
+    @pytest.mark.parametrize(
+        "method_name, relation",
+        [
+            ("build_group_can_edit_machines_with_tag", "can_edit_machines"),
+            ("build_group_can_view_machines_with_tag", "can_view_machines"),
+            (
+                "build_group_can_view_available_machines_with_tag",
+                "can_view_available_machines",
+            ),
+            (
+                "build_group_can_deploy_machines_with_tag",
+                "can_deploy_machines",
+            ),
+        ],
+    )
+    def test_group_tag_scoped_builders(self, method_name, relation):
+        method = getattr(OpenFGATupleBuilder, method_name)
+        builder = method(1, "3")
+
+        assert builder.user == "group:1#member"
+        assert builder.user_type == "userset"
+        assert builder.relation == relation
+        assert builder.object_id == "3"
+        assert builder.object_type == "tag"
+
     @pytest.mark.parametrize(
         "method_name, relation",
         [
@@ -110,3 +144,48 @@ class TestOpenFGATupleBuilder:
         assert builder.relation == "parent"
         assert builder.object_id == pool_id
         assert builder.object_type == "pool"
+
+    @pytest.mark.parametrize(
+        "method_name, object_type",
+        [
+            ("build_zone", "zone"),
+            ("build_fabric", "fabric"),
+            ("build_boot_resource", "boot_resource"),
+            ("build_tag", "tag"),
+        ],
+    )
+    def test_maas_child_builders(self, method_name, object_type):
+        builder = getattr(OpenFGATupleBuilder, method_name)("7")
+
+        assert builder.user == "maas:0"
+        assert builder.user_type == "user"
+        assert builder.relation == "parent"
+        assert builder.object_id == "7"
+        assert builder.object_type == object_type
+
+    def test_build_vlan(self):
+        builder = OpenFGATupleBuilder.build_vlan("5", "2")
+
+        assert builder.user == "fabric:2"
+        assert builder.user_type == "user"
+        assert builder.relation == "fabric"
+        assert builder.object_id == "5"
+        assert builder.object_type == "vlan"
+
+    def test_build_machine_pool(self):
+        builder = OpenFGATupleBuilder.build_machine_pool("4", "2")
+
+        assert builder.user == "pool:2"
+        assert builder.user_type == "user"
+        assert builder.relation == "pool"
+        assert builder.object_id == "4"
+        assert builder.object_type == "machine"
+
+    def test_build_machine_tag(self):
+        builder = OpenFGATupleBuilder.build_machine_tag("4", "3")
+
+        assert builder.user == "tag:3"
+        assert builder.user_type == "user"
+        assert builder.relation == "tag"
+        assert builder.object_id == "4"
+        assert builder.object_type == "machine"
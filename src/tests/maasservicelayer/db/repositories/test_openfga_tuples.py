@@ -6,7 +6,11 @@ from operator import eq
 import pytest
 from sqlalchemy.ext.asyncio import AsyncConnection
 
-from maascommon.enums.openfga import OPENFGA_STORE_ID
+from maascommon.enums.openfga import (
+    OPENFGA_STORE_ID,
+    OPENFGA_TUPLE_OPERATION_DELETE,
+    OPENFGA_TUPLE_OPERATION_WRITE,
+)
 from maasservicelayer.builders.openfga_tuple import OpenFGATupleBuilder
 from maasservicelayer.context import Context
 from maasservicelayer.db.filters import QuerySpec
@@ -14,7 +18,10 @@ from maasservicelayer.db.repositories.openfga_tuples import (
     OpenFGATuplesClauseFactory,
     OpenFGATuplesRepository,
 )
-from maasservicelayer.db.tables import OpenFGATupleTable
+from maasservicelayer.db.tables import (
+    OpenFGAChangelogTable,
+    OpenFGATupleTable,
+)
 from tests.fixtures.factories.openfga_tuples import create_openfga_tuple
 from tests.maasapiserver.fixtures.db import Fixture
 from tests.utils.ulid import is_ulid
@@ -85,6 +92,15 @@ class TestOpenFGATuplesRepository:
         assert tuple_dict["condition_name"] is None
         assert tuple_dict["condition_context"] is None
 
+        [change] = await fixture.get(OpenFGAChangelogTable.fullname)
+        assert change["_user"] == "user:alice"
+        assert change["relation"] == "member"
+        assert change["object_type"] == "group"
+        assert change["object_id"] == "admins"
+        assert change["store"] == OPENFGA_STORE_ID
+        assert change["operation"] == OPENFGA_TUPLE_OPERATION_WRITE
+        assert is_ulid(change["ulid"]) is True
+
     async def test_upsert_replaces_tuple(
         self, db_connection: AsyncConnection, fixture: Fixture
     ) -> None:
@@ -144,3 +160,13 @@ class TestOpenFGATuplesRepository:
             eq(OpenFGATupleTable.c.relation, "member"),
         )
         assert len(tuples) == 0
+
+        changes = await fixture.get(OpenFGAChangelogTable.fullname)
+        assert sorted(change["_user"] for change in changes) == [
+            "user:alice",
+            "user:bob",
+        ]
+        assert all(
+            change["operation"] == OPENFGA_TUPLE_OPERATION_DELETE
+            for change in changes
+        )
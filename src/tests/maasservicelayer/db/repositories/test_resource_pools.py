@@ -28,6 +28,10 @@ from tests.fixtures.factories.resource_pools import (
     create_n_test_resource_pools,
     create_test_resource_pool,
 )
+from tests.fixtures.factories.tag import (
+    create_test_tag_entry,
+    create_test_tag_node_relationship,
+)
 from tests.maasapiserver.fixtures.db import Fixture
 from tests.maasservicelayer.db.repositories.base import RepositoryCommonTests
 
@@ -117,6 +121,33 @@ class TestResourcePoolRepository(RepositoryCommonTests[ResourcePool]):
             for resource_pool in retrieved_resource_pools.items
         )
 
+    async def test_list_with_machine_tag(
+        self, repository_instance: ResourcePoolRepository, fixture: Fixture
+    ) -> None:
+        tag = await create_test_tag_entry(fixture, name="gpu", definition="")
+        tagged_pool = await create_test_resource_pool(fixture, name="tagged")
+        other_pool = await create_test_resource_pool(fixture, name="other")
+        machine = await create_test_machine_entry(
+            fixture, pool_id=tagged_pool.id
+        )
+        await create_test_tag_node_relationship(
+            fixture, node_id=machine["id"], tag_id=tag["id"]
+        )
+        # Only machines select their pool.
+        device = await create_test_device_entry(
+            fixture, pool_id=other_pool.id
+        )
+        await create_test_tag_node_relationship(
+            fixture, node_id=device["id"], tag_id=tag["id"]
+        )
+
+        pools = await repository_instance.get_many(
+            query=QuerySpec(
+                where=ResourcePoolClauseFactory.with_machine_tag("gpu")
+            )
+        )
+        assert [pool.id for pool in pools] == [tagged_pool.id]
+
     async def test_update_duplicated_name(
         self, repository_instance: ResourcePoolRepository, fixture: Fixture
     ) -> None:
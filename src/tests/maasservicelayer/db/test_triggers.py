@@ -12,15 +12,20 @@ class TestTriggersUsed:
     """Tests relating to those triggers the MAAS application uses."""
 
     triggers_system = {
+        "auth_user_sys_openfga_user_delete",
+        "auth_user_sys_openfga_user_insert",
         "rbacsync_sys_rbac_sync",
         "regionrackrpcconnection_sys_core_rpc_delete",
         "regionrackrpcconnection_sys_core_rpc_insert",
+        "resourcepool_sys_openfga_rpool_delete",
+        "resourcepool_sys_openfga_rpool_insert",
         "resourcepool_sys_rbac_rpool_delete",
         "resourcepool_sys_rbac_rpool_insert",
         "resourcepool_sys_rbac_rpool_update",
         "subnet_sys_proxy_subnet_delete",
         "subnet_sys_proxy_subnet_insert",
         "subnet_sys_proxy_subnet_update",
+        "usergroup_sys_openfga_usergroup_delete",
     }
 
     triggers_websocket = {
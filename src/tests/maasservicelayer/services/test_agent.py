@@ -48,7 +48,29 @@ class TestAgentsService:
             system_id="agent", service_name="foo"
         )
         api_client.request.assert_called_with(
-            method="GET", path="agents/agent/services/foo/config/"
+            method="GET", path="agents/agent/services/foo/config/", params=None
+        )
+
+    async def test_get_service_configuration_params(self) -> None:
+        agents_service = AgentsService(
+            context=Context(),
+            repository=Mock(AgentsRepository),
+            configurations_service=Mock(ConfigurationsService),
+            users_service=Mock(UsersService),
+        )
+
+        api_client = AsyncMock()
+        agents_service._apiclient = api_client
+
+        await agents_service.get_service_configuration(
+            system_id="agent",
+            service_name="dhcp",
+            params={"base_dhcpd": "abc"},
+        )
+        api_client.request.assert_called_with(
+            method="GET",
+            path="agents/agent/services/dhcp/config/",
+            params={"base_dhcpd": "abc"},
         )
 
     async def test_get_apiclient(self) -> None:
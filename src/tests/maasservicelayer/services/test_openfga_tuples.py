@@ -20,6 +20,7 @@ from maasservicelayer.services.openfga_tuples import (
     MAASTupleBuilderFactory,
     OpenFGAServiceCache,
     PoolTupleBuilderFactory,
+    TagTupleBuilderFactory,
     UndefinedEntitlementError,
 )
 from tests.fixtures.factories.openfga_tuples import create_openfga_tuple
@@ -174,6 +175,162 @@ class TestIntegrationOpenFGAService:
         )
         assert len(retrieved_tuple) == 0
 
+    async def test_ban_user(
+        self, fixture: Fixture, services: ServiceCollectionV3
+    ):
+        await services.openfga_tuples.ban_user(1)
+        retrieved_tuple = await fixture.get(
+            OpenFGATupleTable.fullname,
+            and_(
+                eq(OpenFGATupleTable.c.object_type, "maas"),
+                eq(OpenFGATupleTable.c.object_id, "0"),
+                eq(OpenFGATupleTable.c._user, "user:1"),
+            ),
+        )
+        assert len(retrieved_tuple) == 1
+        assert retrieved_tuple[0]["relation"] == "banned"
+
+    async def test_unban_user(
+        self, fixture: Fixture, services: ServiceCollectionV3
+    ):
+        await create_openfga_tuple(
+            fixture, "user:1", "user", "banned", "maas", "0"
+        )
+        await create_openfga_tuple(
+            fixture, "user:1", "user", "member", "group", "2000"
+        )
+        await services.openfga_tuples.unban_user(1)
+        retrieved_tuples = await fixture.get(
+            OpenFGATupleTable.fullname,
+            eq(OpenFGATupleTable.c._user, "user:1"),
+        )
+        assert len(retrieved_tuples) == 1
+        assert retrieved_tuples[0]["relation"] == "member"
+
+    async def test_delete_tag(
+        self, fixture: Fixture, services: ServiceCollectionV3
+    ):
+        await create_openfga_tuple(
+            fixture, "maas:0", "user", "parent", "tag", "3"
+        )
+        await create_openfga_tuple(
+            fixture,
+            "group:1#member",
+            "userset",
+            "can_deploy_machines",
+            "tag",
+            "3",
+        )
+        await create_openfga_tuple(
+            fixture, "tag:3", "user", "tag", "machine", "10"
+        )
+        await create_openfga_tuple(
+            fixture, "tag:4", "user", "tag", "machine", "10"
+        )
+        await services.openfga_tuples.delete_tag(3)
+        retrieved_tuples = await fixture.get(
+            OpenFGATupleTable.fullname,
+            and_(
+                eq(OpenFGATupleTable.c.object_type, "tag"),
+                eq(OpenFGATupleTable.c.object_id, "3"),
+            ),
+        )
+        assert len(retrieved_tuples) == 0
+        retrieved_tuples = await fixture.get(
+            OpenFGATupleTable.fullname,
+            eq(OpenFGATupleTable.c.object_type, "machine"),
+        )
+        assert [t["_user"] for t in retrieved_tuples] == ["tag:4"]
+
+    async def test_set_machine_pool(
+        self, fixture: Fixture, services: ServiceCollectionV3
+    ):
+        await create_openfga_tuple(
+            fixture, "pool:1", "user", "pool", "machine", "10"
+        )
+        await services.openfga_tuples.set_machine_pool(10, 2)
+        retrieved_tuples = await fixture.get(
+            OpenFGATupleTable.fullname,
+            eq(OpenFGATupleTable.c.object_type, "machine"),
+        )
+        assert len(retrieved_tuples) == 1
+        assert retrieved_tuples[0]["_user"] == "pool:2"
+        assert retrieved_tuples[0]["relation"] == "pool"
+        assert retrieved_tuples[0]["object_id"] == "10"
+
+    async def test_add_and_remove_machine_tags(
+        self, fixture: Fixture, services: ServiceCollectionV3
+    ):
+        await services.openfga_tuples.add_machine_tags(10, [1, 2, 3])
+        await services.openfga_tuples.remove_machine_tags(10, [1, 3])
+        retrieved_tuples = await fixture.get(
+            OpenFGATupleTable.fullname,
+            eq(OpenFGATupleTable.c.object_type, "machine"),
+        )
+        assert [t["_user"] for t in retrieved_tuples] == ["tag:2"]
+
+        await services.openfga_tuples.remove_machine_tags(10)
+        retrieved_tuples = await fixture.get(
+            OpenFGATupleTable.fullname,
+            eq(OpenFGATupleTable.c.object_type, "machine"),
+        )
+        assert retrieved_tuples == []
+
+    async def test_grant_pools_entitlement(
+        self, fixture: Fixture, services: ServiceCollectionV3
+    ):
+        await create_openfga_tuple(
+            fixture,
+            "group:1#member",
+            "userset",
+            "can_view_machines",
+            "pool",
+            "1",
+        )
+        await create_openfga_tuple(
+            fixture,
+            "group:1#member",
+            "userset",
+            "can_edit_machines",
+            "pool",
+            "2",
+        )
+
+        granted = await services.openfga_tuples.grant_pools_entitlement(
+            1, "can_view_machines", [1, 2, 3]
+        )
+
+        assert granted == [2, 3]
+        tuples = await services.openfga_tuples.list_entitlements(1)
+        assert sorted(
+            (t.relation, t.object_id)
+            for t in tuples
+            if t.relation == "can_view_machines"
+        ) == [
+            ("can_view_machines", "1"),
+            ("can_view_machines", "2"),
+            ("can_view_machines", "3"),
+        ]
+
+    async def test_delete_machine(
+        self, fixture: Fixture, services: ServiceCollectionV3
+    ):
+        await create_openfga_tuple(
+            fixture, "pool:1", "user", "pool", "machine", "10"
+        )
+        await create_openfga_tuple(
+            fixture, "tag:1", "user", "tag", "machine", "10"
+        )
+        await create_openfga_tuple(
+            fixture, "tag:1", "user", "tag", "machine", "11"
+        )
+        await services.openfga_tuples.delete_machine(10)
+        retrieved_tuples = await fixture.get(
+            OpenFGATupleTable.fullname,
+            eq(OpenFGATupleTable.c.object_type, "machine"),
+        )
+        assert [t["object_id"] for t in retrieved_tuples] == ["11"]
+
 
 @pytest.mark.asyncio
 class TestOpenFGAService:
@@ -336,6 +493,25 @@ class TestPoolTupleBuilderFactory:
         assert "not defined" in error
 
 
+class TestTagTupleBuilderFactory:
+    @pytest.mark.parametrize(
+        "entitlement_name",
+        list(TagTupleBuilderFactory.ENTITLEMENTS.keys()),
+    )
+    def test_build_all_tag_entitlements(self, entitlement_name: str) -> None:
+        factory = TagTupleBuilderFactory(entitlement_name)
+        builder = factory.build_tuple(10, 3)
+        assert builder.user == "group:10#member"
+        assert builder.user_type == "userset"
+        assert builder.relation == entitlement_name
+        assert builder.object_type == "tag"
+        assert builder.object_id == "3"
+
+    def test_rejects_undefined_entitlement(self) -> None:
+        with pytest.raises(UndefinedEntitlementError, match="not defined"):
+            TagTupleBuilderFactory("can_edit_identities")
+
+
 class TestEntitlementsBuilderFactory:
     def test_get_factory_maas(self) -> None:
         factory = EntitlementsBuilderFactory.get_factory(
@@ -349,6 +525,12 @@ class TestEntitlementsBuilderFactory:
         )
         assert isinstance(factory, PoolTupleBuilderFactory)
 
+    def test_get_factory_tag(self) -> None:
+        factory = EntitlementsBuilderFactory.get_factory(
+            "can_deploy_machines", "tag"
+        )
+        assert isinstance(factory, TagTupleBuilderFactory)
+
     def test_get_factory_builds_maas_tuple(self) -> None:
         factory = EntitlementsBuilderFactory.get_factory(
             "can_edit_machines", "maas"
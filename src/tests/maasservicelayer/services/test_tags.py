@@ -10,12 +10,14 @@ from maascommon.workflows.tag import (
     TAG_EVALUATION_WORKFLOW_NAME,
     TagEvaluationParam,
 )
+from maasservicelayer.builders.openfga_tuple import OpenFGATupleBuilder
 from maasservicelayer.builders.tags import TagBuilder
 from maasservicelayer.context import Context
 from maasservicelayer.db.repositories.tags import TagsRepository
 from maasservicelayer.exceptions.catalog import ValidationException
 from maasservicelayer.models.tags import Tag
 from maasservicelayer.services.events import EventsService
+from maasservicelayer.services.openfga_tuples import OpenFGATupleService
 from maasservicelayer.services.tags import TagsService
 from maasservicelayer.services.temporal import TemporalService
 from tests.maasservicelayer.services.base import ServiceCommonTests
@@ -45,6 +47,7 @@ class TestTagsServiceCommon(ServiceCommonTests):
             repository=Mock(TagsRepository),
             events_service=Mock(EventsService),
             temporal_service=Mock(TemporalService),
+            openfga_tuples_service=Mock(OpenFGATupleService),
         )
 
     @pytest.fixture
@@ -82,15 +85,24 @@ class TestTagsService:
     def temporal_mock(self) -> Mock:
         return Mock(TemporalService)
 
+    @pytest.fixture
+    def openfga_tuples_service(self) -> Mock:
+        return Mock(OpenFGATupleService)
+
     @pytest.fixture
     def tags_service(
-        self, tags_repository: Mock, temporal_mock: Mock, events_service: Mock
+        self,
+        tags_repository: Mock,
+        temporal_mock: Mock,
+        events_service: Mock,
+        openfga_tuples_service: Mock,
     ) -> TagsService:
         return TagsService(
             context=Context(),
             repository=tags_repository,
             events_service=events_service,
             temporal_service=temporal_mock,
+            openfga_tuples_service=openfga_tuples_service,
         )
 
     @pytest.mark.parametrize(
@@ -147,6 +159,7 @@ class TestTagsService:
         tags_repository: Mock,
         temporal_mock: Mock,
         events_service: Mock,
+        openfga_tuples_service: Mock,
         tags_service: TagsService,
     ) -> None:
         tags_repository.create.return_value = MANUAL_TAG
@@ -164,6 +177,9 @@ class TestTagsService:
             event_type=EventTypeEnum.TAG,
             event_description=f"Tag '{MANUAL_TAG.name}' created.",
         )
+        openfga_tuples_service.upsert.assert_called_once_with(
+            OpenFGATupleBuilder.build_tag(str(MANUAL_TAG.id))
+        )
 
     async def test_update_automatic_tag_definition(
         self,
@@ -263,6 +279,7 @@ class TestTagsService:
         self,
         tags_repository: Mock,
         events_service: Mock,
+        openfga_tuples_service: Mock,
         tags_service: TagsService,
     ) -> None:
         tags_repository.get_by_id.return_value = AUTOMATIC_TAG
@@ -278,6 +295,9 @@ class TestTagsService:
             event_type=EventTypeEnum.TAG,
             event_description=f"Tag '{AUTOMATIC_TAG.name}' deleted.",
         )
+        openfga_tuples_service.delete_tag.assert_called_once_with(
+            AUTOMATIC_TAG.id
+        )
 
     async def test_evaluate_tag(
         self,
@@ -16,7 +16,11 @@ from temporalio.testing import WorkflowEnvironment
 from temporalio.worker import Worker
 
 from maasservicelayer.db import Database
-from maasservicelayer.db.tables import NodeTagTable, TagTable
+from maasservicelayer.db.tables import (
+    NodeTagTable,
+    OpenFGATupleTable,
+    TagTable,
+)
 from maasservicelayer.models.bmc import Bmc
 from maasservicelayer.models.users import User
 from maasservicelayer.services import CacheForServices
@@ -263,6 +267,23 @@ class TestTagEvaluationActivities:
         rows = cursor_result.all()
         return rows
 
+    async def _retrieve_machine_tag_tuples(
+        self, db_connection: AsyncConnection
+    ):
+        """Retrieve the machine-tag pairs of the OpenFGA tuples."""
+        tuples_query = select(
+            OpenFGATupleTable.c.object_id, OpenFGATupleTable.c._user
+        ).where(
+            OpenFGATupleTable.c.object_type == "machine",
+            OpenFGATupleTable.c.relation == "tag",
+        )
+
+        cursor_result = await db_connection.execute(tuples_query)
+        return {
+            (int(object_id), int(user.removeprefix("tag:")))
+            for object_id, user in cursor_result.all()
+        }
+
     @pytest.mark.parametrize("batch_size", [1000, 2, 1])
     async def test_tag_evaluation_activity_no_nodes(
         self,
@@ -453,3 +474,43 @@ class TestTagEvaluationActivities:
             (machine_1["id"], tag_02.id),
             (machine_5["id"], tag_03["id"]),
         }
+
+    async def test_tag_evaluation_activity_updates_openfga_tuples(
+        self,
+        db: Database,
+        db_connection: AsyncConnection,
+        fixture: Fixture,
+        _machine_entries_for_tag_evaluation_tests,
+    ):
+        """
+        Test that machines tagged and untagged by the tag evaluation activity
+        are linked to the tag in OpenFGA, so that tag permissions apply to
+        them.
+        """
+        tag_evaluation_activity = TagEvaluationActivity(
+            db,
+            CacheForServices(),
+            temporal_client=Mock(Client),
+            connection=db_connection,
+        )
+        machine_1, machine_2, _, _, _ = (
+            _machine_entries_for_tag_evaluation_tests
+        )
+
+        tag = await create_test_tag_entry(
+            fixture, name="tag_01", definition="//node"
+        )
+        await tag_evaluation_activity.evaluate_tag(
+            TagEvaluationParam(tag["id"], tag["definition"])
+        )
+        assert await self._retrieve_machine_tag_tuples(db_connection) == {
+            (machine_1["id"], tag["id"]),
+            (machine_2["id"], tag["id"]),
+        }
+
+        await tag_evaluation_activity.evaluate_tag(
+            TagEvaluationParam(tag["id"], '//vendor[text()="Vendor X"]')
+        )
+        assert await self._retrieve_machine_tag_tuples(db_connection) == {
+            (machine_1["id"], tag["id"]),
+        }